@@ -2,11 +2,26 @@
 package main
 
 import (
+	"bufio"
 	"bytes"
+	"context"
+	"encoding/json"
+	stderrors "errors"
+	"fmt"
 	"io"
+	"net/http"
+	"net/http/httptest"
 	"os"
+	"path/filepath"
 	"strings"
+	"sync/atomic"
 	"testing"
+	"time"
+
+	"github.com/valpere/DataScrapexter/internal/config"
+	"github.com/valpere/DataScrapexter/internal/errors"
+	"github.com/valpere/DataScrapexter/internal/pipeline"
+	"github.com/valpere/DataScrapexter/internal/scraper"
 )
 
 func TestCLIVersion(t *testing.T) {
@@ -64,3 +79,1144 @@ func captureOutput(f func()) string {
 
 	return out
 }
+
+func TestExplainConfig(t *testing.T) {
+	configFile := filepath.Join(t.TempDir(), "config.yaml")
+	content := `name: explain_test
+base_url: https://example.com
+headers:
+  Authorization: Bearer secret-token
+fields:
+  - name: title
+    selector: h1
+    type: text
+output:
+  format: json
+  file: out.json
+`
+	if err := os.WriteFile(configFile, []byte(content), 0644); err != nil {
+		t.Fatalf("failed to write config: %v", err)
+	}
+
+	explained, err := explainConfig(configFile)
+	if err != nil {
+		t.Fatalf("explainConfig failed: %v", err)
+	}
+
+	if !strings.Contains(explained, "name: explain_test") {
+		t.Errorf("explain output should contain the config name, got: %s", explained)
+	}
+	if strings.Contains(explained, "secret-token") {
+		t.Errorf("explain output should redact secrets, got: %s", explained)
+	}
+
+	// Session flags are part of the effective configuration
+	args := os.Args
+	os.Args = []string{"datascrapexter", "run", "--explain", "--record-session", "recorded", configFile}
+	explained, err = explainConfig(configFile)
+	os.Args = args
+	if err != nil {
+		t.Fatalf("explainConfig failed: %v", err)
+	}
+	if !strings.Contains(explained, "record_session: recorded") {
+		t.Errorf("explain output should include --record-session, got: %s", explained)
+	}
+
+	// So is the --select subset, which validate checks the same way
+	os.Args = []string{"datascrapexter", "run", "--explain", "--select", "missing", configFile}
+	_, explainErr := explainConfig(configFile)
+	validateErr := executeValidation(configFile, false)
+	os.Args = args
+	if explainErr == nil || validateErr == nil || !strings.Contains(validateErr.Error(), "unknown field(s) missing") {
+		t.Errorf("explain and validate should both reject an unknown --select field, got %v and %v", explainErr, validateErr)
+	}
+
+	if got := positionalArg([]string{"--explain", configFile}); got != configFile {
+		t.Errorf("positionalArg = %q, want %q", got, configFile)
+	}
+	if got := positionalArg([]string{"--replay-session", "session", configFile}); got != configFile {
+		t.Errorf("positionalArg should skip flag values, got %q", got)
+	}
+}
+
+func TestExportSchema(t *testing.T) {
+	dir := t.TempDir()
+	configFile := filepath.Join(dir, "config.yaml")
+	content := `name: products
+base_url: https://example.com
+fields:
+  - name: title
+    selector: h1
+    type: text
+    required: true
+    validate:
+      min_length: 3
+  - name: tags
+    selector: .tag
+    type: list
+  - name: price
+    selector: .price
+    type: text
+    required: true
+    output_type: number
+  - name: url
+    selector: a
+    type: attr
+    attribute: href
+  - name: data
+    selector: script#data
+    type: text
+    transform:
+      - type: json_parse
+output:
+  format: json
+  file: out.json
+`
+	if err := os.WriteFile(configFile, []byte(content), 0644); err != nil {
+		t.Fatalf("failed to write config: %v", err)
+	}
+
+	printed, err := exportSchema(configFile, "")
+	if err != nil {
+		t.Fatalf("exportSchema failed: %v", err)
+	}
+	var schema struct {
+		Schema     string                            `json:"$schema"`
+		Type       string                            `json:"type"`
+		Properties map[string]map[string]interface{} `json:"properties"`
+		Required   []string                          `json:"required"`
+	}
+	if err := json.Unmarshal([]byte(printed), &schema); err != nil {
+		t.Fatalf("schema is not JSON: %v\n%s", err, printed)
+	}
+
+	if schema.Schema != recordSchemaURI || schema.Type != "object" {
+		t.Errorf("got $schema %q type %q", schema.Schema, schema.Type)
+	}
+	if strings.Join(schema.Required, ",") != "title,price" {
+		t.Errorf("required = %v, want [title price]", schema.Required)
+	}
+	if title := schema.Properties["title"]; title["type"] != "string" || title["minLength"] != float64(3) {
+		t.Errorf("title schema = %v", title)
+	}
+	if tags := schema.Properties["tags"]; tags["type"] != "array" || tags["items"].(map[string]interface{})["type"] != "string" {
+		t.Errorf("tags schema = %v", tags)
+	}
+	if price := schema.Properties["price"]; price["type"] != "number" {
+		t.Errorf("price schema = %v", price)
+	}
+	if url := schema.Properties["url"]; url["type"] != "string" {
+		t.Errorf("url schema = %v", url)
+	}
+	if data := schema.Properties["data"]; len(data) != 0 {
+		t.Errorf("json_parse field should accept any value, got %v", data)
+	}
+
+	out := filepath.Join(dir, "schema.json")
+	if _, err := exportSchema(configFile, out); err != nil {
+		t.Fatalf("exportSchema to file failed: %v", err)
+	}
+	written, err := os.ReadFile(out)
+	if err != nil {
+		t.Fatalf("schema file not written: %v", err)
+	}
+	if string(written) != printed {
+		t.Errorf("written schema differs from printed schema")
+	}
+}
+
+func TestListProxies(t *testing.T) {
+	configFile := filepath.Join(t.TempDir(), "config.yaml")
+	content := `name: proxy_test
+base_url: https://example.com
+proxy:
+  enabled: true
+  rotation: weighted
+  providers:
+    - name: primary
+      type: http
+      host: proxy1.example.com
+      port: 8080
+      username: user
+      password: hunter2
+      weight: 3
+      enabled: true
+    - name: backup
+      type: socks5
+      host: proxy2.example.com
+      port: 1080
+      enabled: false
+fields:
+  - name: title
+    selector: h1
+    type: text
+output:
+  format: json
+  file: out.json
+`
+	if err := os.WriteFile(configFile, []byte(content), 0644); err != nil {
+		t.Fatalf("failed to write config: %v", err)
+	}
+
+	listed, err := listProxies(configFile)
+	if err != nil {
+		t.Fatalf("listProxies failed: %v", err)
+	}
+
+	for _, want := range []string{"Proxy rotation: weighted", "Pool: 1 of 2 providers loaded", "http://proxy1.example.com:8080", "weight=3", "backup", "(disabled)"} {
+		if !strings.Contains(listed, want) {
+			t.Errorf("list output should contain %q, got:\n%s", want, listed)
+		}
+	}
+	if strings.Contains(listed, "hunter2") {
+		t.Errorf("list output should not contain proxy credentials, got:\n%s", listed)
+	}
+
+	// An unknown strategy is reported before any scraping happens
+	bad := strings.Replace(content, "rotation: weighted", "rotation: fastest", 1)
+	if err := os.WriteFile(configFile, []byte(bad), 0644); err != nil {
+		t.Fatalf("failed to write config: %v", err)
+	}
+	if _, err := listProxies(configFile); err == nil || !strings.Contains(err.Error(), "rotation strategy") {
+		t.Errorf("expected invalid rotation strategy error, got %v", err)
+	}
+}
+
+func TestParseMergeArgs(t *testing.T) {
+	opts, err := parseMergeArgs([]string{"a.json", "b.jsonl", "-o", "merged.csv", "--dedupe-fields", "url, sku"})
+	if err != nil {
+		t.Fatalf("parseMergeArgs failed: %v", err)
+	}
+	if len(opts.inputs) != 2 || opts.output != "merged.csv" || !opts.dedup {
+		t.Errorf("unexpected options: %+v", opts)
+	}
+	if strings.Join(opts.dedupField, ",") != "url,sku" {
+		t.Errorf("dedupe fields = %v, want [url sku]", opts.dedupField)
+	}
+
+	opts, err = parseMergeArgs([]string{"a.json", "-o", "merged.json", "--dedupe-dry-run", "report.json"})
+	if err != nil {
+		t.Fatalf("parseMergeArgs failed: %v", err)
+	}
+	if !opts.dedup || opts.dryRun != "report.json" {
+		t.Errorf("--dedupe-dry-run should enable dedupe with a report, got %+v", opts)
+	}
+
+	opts, err = parseMergeArgs([]string{"a.json", "-o", "merged.json", "--dedupe-url", "link"})
+	if err != nil {
+		t.Fatalf("parseMergeArgs failed: %v", err)
+	}
+	if !opts.dedup || opts.dedupURL != "link" {
+		t.Errorf("--dedupe-url should enable dedupe on the field, got %+v", opts)
+	}
+
+	for _, args := range [][]string{
+		{"a.json"},
+		{"a.json", "-o", "merged.json", "--dedupe-url", "link", "--dedupe-fields", "sku"},
+		{"a.json", "-o", "merged.json", "--dedupe-dry-run"},
+		{"-o", "merged.json"},
+		{"a.json", "-o"},
+		{"a.json", "-o", "merged.json", "--bogus"},
+	} {
+		if _, err := parseMergeArgs(args); err == nil {
+			t.Errorf("parseMergeArgs(%q) succeeded, want an error", args)
+		}
+	}
+}
+
+func TestSelectFields(t *testing.T) {
+	fields := []config.Field{
+		{Name: "title", Selector: "h1", Type: "text", Required: true},
+		{Name: "price", Selector: ".price", Type: "text"},
+		{Name: "sku", Selector: ".sku", Type: "text", Required: true},
+	}
+
+	selected, err := selectFields(fields, []string{"sku", "price"})
+	if err != nil {
+		t.Fatalf("selectFields failed: %v", err)
+	}
+	if len(selected) != 2 || selected[0].Name != "price" || selected[1].Name != "sku" || !selected[1].Required {
+		t.Errorf("selected = %+v, want price then required sku", selected)
+	}
+
+	_, err = selectFields(fields, []string{"title", "brand", "rating"})
+	if err == nil {
+		t.Fatal("expected an error for unknown fields")
+	}
+	for _, want := range []string{"brand, rating", "available fields: title, price, sku"} {
+		if !strings.Contains(err.Error(), want) {
+			t.Errorf("error %q should contain %q", err, want)
+		}
+	}
+
+	if got := positionalArg([]string{"--select", "title", "--select", "price", "config.yaml"}); got != "config.yaml" {
+		t.Errorf("positionalArg() = %q, want config.yaml", got)
+	}
+}
+
+func TestScrapeURLs(t *testing.T) {
+	urls := []string{"https://a.test", "https://b.test", "https://c.test"}
+	scrape := func(failing ...string) (scrapeFunc, *[]string) {
+		var calls []string
+		return func(ctx context.Context, url string, fields []scraper.FieldConfig) (*scraper.Result, error) {
+			calls = append(calls, url)
+			for _, f := range failing {
+				if f == url {
+					return nil, fmt.Errorf("fetch %s: 503", url)
+				}
+			}
+			return &scraper.Result{Success: true, Data: map[string]interface{}{"title": "page"}}, nil
+		}, &calls
+	}
+	policy := func(mode string, rate float64) errors.FailurePolicy {
+		return errors.FailurePolicy{Mode: mode, MaxErrorRate: rate}
+	}
+
+	t.Run("tags every record", func(t *testing.T) {
+		fn, _ := scrape()
+		records, err := scrapeURLs(context.Background(), fn, urls, true, nil, runOptions{policy: policy("partial", 0.3), status: io.Discard})
+		if err != nil {
+			t.Fatalf("unexpected error: %v", err)
+		}
+		if len(records) != 3 || records[1][sourceURLKey] != "https://b.test" {
+			t.Errorf("records = %v", records)
+		}
+	})
+
+	t.Run("base url is untagged", func(t *testing.T) {
+		fn, _ := scrape()
+		records, _ := scrapeURLs(context.Background(), fn, urls[:1], false, nil, runOptions{policy: policy("partial", 0.3), status: io.Discard})
+		if _, ok := records[0][sourceURLKey]; ok {
+			t.Errorf("record tagged without urls: %v", records[0])
+		}
+	})
+
+	t.Run("stop", func(t *testing.T) {
+		fn, calls := scrape("https://b.test")
+		records, err := scrapeURLs(context.Background(), fn, urls, true, nil, runOptions{policy: policy("stop", 0.3), status: io.Discard})
+		if err == nil || records != nil {
+			t.Fatalf("records = %v, err = %v; want failure", records, err)
+		}
+		if len(*calls) != 2 {
+			t.Errorf("scraped %v, want to stop after the failure", *calls)
+		}
+	})
+
+	t.Run("continue", func(t *testing.T) {
+		fn, _ := scrape("https://a.test", "https://b.test")
+		var status bytes.Buffer
+		records, err := scrapeURLs(context.Background(), fn, urls, true, nil, runOptions{policy: policy("continue", 0.3), status: &status})
+		if err != nil || len(records) != 1 {
+			t.Fatalf("records = %v, err = %v", records, err)
+		}
+		if !strings.Contains(status.String(), "Skipping https://a.test") {
+			t.Errorf("status = %q, want skipped URL reported", status.String())
+		}
+	})
+
+	t.Run("partial within rate", func(t *testing.T) {
+		fn, _ := scrape("https://c.test")
+		records, err := scrapeURLs(context.Background(), fn, urls, true, nil, runOptions{policy: policy("partial", 0.5), status: io.Discard})
+		if err != nil || len(records) != 2 {
+			t.Fatalf("records = %v, err = %v", records, err)
+		}
+	})
+
+	t.Run("partial above rate", func(t *testing.T) {
+		fn, _ := scrape("https://c.test")
+		records, err := scrapeURLs(context.Background(), fn, urls, true, nil, runOptions{policy: policy("partial", 0.3), status: io.Discard})
+		if err == nil || len(records) != 2 {
+			t.Fatalf("records = %v, err = %v; want records and an error", records, err)
+		}
+	})
+
+	t.Run("all failed", func(t *testing.T) {
+		fn, _ := scrape(urls...)
+		records, err := scrapeURLs(context.Background(), fn, urls, true, nil, runOptions{policy: policy("continue", 0.3), status: io.Discard})
+		if err == nil || records != nil {
+			t.Fatalf("records = %v, err = %v; want failure", records, err)
+		}
+	})
+}
+
+func TestCollectStats(t *testing.T) {
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		if r.URL.Path == "/missing" {
+			http.Error(w, "down", http.StatusInternalServerError)
+			return
+		}
+		fmt.Fprint(w, "<html><body><h1>Stats</h1></body></html>")
+	}))
+	defer server.Close()
+
+	configFile := filepath.Join(t.TempDir(), "config.yaml")
+	content := fmt.Sprintf(`name: stats_test
+base_url: %[1]s/
+urls:
+  - %[1]s/
+  - %[1]s/missing
+rate_limit: 10ms
+failure_policy:
+  mode: continue
+fields:
+  - name: title
+    selector: h1
+    type: text
+output:
+  format: json
+  file: out.json
+`, server.URL)
+	if err := os.WriteFile(configFile, []byte(content), 0644); err != nil {
+		t.Fatalf("failed to write config: %v", err)
+	}
+
+	stats, err := collectStats(configFile, io.Discard)
+	if err != nil {
+		t.Fatalf("collectStats failed: %v", err)
+	}
+	if stats.URLs != 2 || stats.Records != 1 {
+		t.Errorf("URLs = %d, records = %d; want 2 and 1", stats.URLs, stats.Records)
+	}
+	if stats.Recovery.Operations != 2 || stats.Recovery.Failed != 1 || len(stats.Recovery.TopErrors) == 0 {
+		t.Errorf("unexpected recovery stats: %+v", stats.Recovery)
+	}
+	if len(stats.CircuitBreakers) == 0 {
+		t.Error("expected the fetch circuit breaker in the stats")
+	}
+
+	text, err := formatStats(stats, false)
+	if err != nil {
+		t.Fatalf("formatStats failed: %v", err)
+	}
+	for _, want := range []string{"URLs: 2, records: 1", "Recovery: 2 operations", "Top errors:", "Circuit breakers:"} {
+		if !strings.Contains(text, want) {
+			t.Errorf("stats output should contain %q, got:\n%s", want, text)
+		}
+	}
+
+	encoded, err := formatStats(stats, true)
+	if err != nil {
+		t.Fatalf("formatStats failed: %v", err)
+	}
+	var decoded map[string]interface{}
+	if err := json.Unmarshal([]byte(encoded), &decoded); err != nil {
+		t.Fatalf("stats JSON does not parse: %v", err)
+	}
+	if decoded["records"] != 1.0 {
+		t.Errorf("stats JSON records = %v, want 1", decoded["records"])
+	}
+}
+
+func TestRunWithMaxDuration(t *testing.T) {
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		if r.URL.Path == "/slow" {
+			select {
+			case <-r.Context().Done():
+			case <-time.After(5 * time.Second):
+			}
+			return
+		}
+		fmt.Fprintf(w, "<html><body><h1>%s</h1></body></html>", r.URL.Path)
+	}))
+	defer server.Close()
+
+	writeConfig := func(dir, savePartial string) (string, string) {
+		outFile := filepath.Join(dir, "out.json")
+		configFile := filepath.Join(dir, "config.yaml")
+		content := fmt.Sprintf(`name: deadline_test
+base_url: %[1]s/
+urls:
+  - %[1]s/a
+  - %[1]s/b
+  - %[1]s/slow
+  - %[1]s/c
+rate_limit: 10ms
+failure_policy:
+  mode: continue
+  save_partial_results: %[2]s
+fields:
+  - name: title
+    selector: h1
+    type: text
+output:
+  format: json
+  file: %[3]s
+`, server.URL, savePartial, outFile)
+		if err := os.WriteFile(configFile, []byte(content), 0644); err != nil {
+			t.Fatalf("failed to write config: %v", err)
+		}
+		return configFile, outFile
+	}
+
+	t.Run("partial results written", func(t *testing.T) {
+		configFile, outFile := writeConfig(t.TempDir(), "true")
+		start := time.Now()
+		err := runWithMaxDuration(configFile, 500*time.Millisecond, false, false, io.Discard)
+		if elapsed := time.Since(start); elapsed > 3*time.Second {
+			t.Errorf("run took %s, want it stopped near the 500ms budget", elapsed)
+		}
+		if !stderrors.Is(err, errors.ErrMaxDuration) || !stderrors.Is(err, context.DeadlineExceeded) {
+			t.Fatalf("err = %v, want ErrMaxDuration wrapping the deadline", err)
+		}
+		if code := errorService.GetExitCode(err); code != 11 {
+			t.Errorf("exit code = %d, want 11", code)
+		}
+		if !strings.Contains(errorService.FormatErrorForCLI(err), "Run Timeout") {
+			t.Errorf("CLI message should report a run timeout, got:\n%s", errorService.FormatErrorForCLI(err))
+		}
+
+		data, err := os.ReadFile(outFile)
+		if err != nil {
+			t.Fatalf("partial results were not written: %v", err)
+		}
+		var records []map[string]interface{}
+		if err := json.Unmarshal(data, &records); err != nil {
+			t.Fatalf("output does not parse: %v", err)
+		}
+		if len(records) != 2 || records[0]["title"] != "/a" || records[1]["title"] != "/b" {
+			t.Errorf("want the two records scraped before the deadline, got %v", records)
+		}
+	})
+
+	t.Run("save_partial_results false", func(t *testing.T) {
+		configFile, outFile := writeConfig(t.TempDir(), "false")
+		err := runWithMaxDuration(configFile, 500*time.Millisecond, false, false, io.Discard)
+		if !stderrors.Is(err, errors.ErrMaxDuration) {
+			t.Fatalf("err = %v, want ErrMaxDuration", err)
+		}
+		if _, statErr := os.Stat(outFile); !os.IsNotExist(statErr) {
+			t.Errorf("output should not be written, stat error = %v", statErr)
+		}
+	})
+
+	if _, err := parseMaxDuration("soon"); err == nil {
+		t.Error("expected an error for an invalid --max-duration")
+	}
+	if d, err := parseMaxDuration("10m"); err != nil || d != 10*time.Minute {
+		t.Errorf("parseMaxDuration(10m) = %s, %v", d, err)
+	}
+}
+
+func TestStdoutStreamsRecords(t *testing.T) {
+	// The last URL is held back until the first record has been read from stdout
+	released := make(chan struct{})
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		if r.URL.Path == "/b" {
+			select {
+			case <-released:
+			case <-time.After(5 * time.Second):
+			}
+		}
+		fmt.Fprintf(w, "<html><body><h1>%s</h1></body></html>", r.URL.Path)
+	}))
+	defer server.Close()
+
+	configFile := filepath.Join(t.TempDir(), "config.yaml")
+	content := fmt.Sprintf(`name: stream_test
+base_url: %[1]s/
+urls:
+  - %[1]s/a
+  - %[1]s/b
+rate_limit: 10ms
+fields:
+  - name: title
+    selector: h1
+    type: text
+output:
+  format: jsonl
+  file: unused.jsonl
+`, server.URL)
+	if err := os.WriteFile(configFile, []byte(content), 0644); err != nil {
+		t.Fatalf("failed to write config: %v", err)
+	}
+
+	old := os.Stdout
+	r, w, _ := os.Pipe()
+	os.Stdout = w
+	defer func() { os.Stdout = old }()
+
+	done := make(chan error, 1)
+	go func() {
+		err := executeScrapingOperation(context.Background(), configFile, false, true, io.Discard)
+		w.Close()
+		done <- err
+	}()
+
+	lines := make(chan string)
+	go func() {
+		scanner := bufio.NewScanner(r)
+		for scanner.Scan() {
+			lines <- scanner.Text()
+		}
+		close(lines)
+	}()
+
+	select {
+	case first := <-lines:
+		if !strings.Contains(first, `"title":"/a"`) {
+			t.Errorf("first record = %s, want the record of /a", first)
+		}
+	case <-time.After(3 * time.Second):
+		t.Fatal("no record on stdout before the last URL was served")
+	}
+	close(released)
+
+	var rest []string
+	for line := range lines {
+		rest = append(rest, line)
+	}
+	if err := <-done; err != nil {
+		t.Fatalf("run failed: %v", err)
+	}
+	if len(rest) != 1 || !strings.Contains(rest[0], `"title":"/b"`) {
+		t.Errorf("remaining records = %v, want the record of /b", rest)
+	}
+}
+
+func TestStdoutStreamNotRetried(t *testing.T) {
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		if r.URL.Path == "/b" {
+			http.NotFound(w, r)
+			return
+		}
+		fmt.Fprintf(w, "<html><body><h1>%s</h1></body></html>", r.URL.Path)
+	}))
+	defer server.Close()
+
+	configFile := filepath.Join(t.TempDir(), "config.yaml")
+	content := fmt.Sprintf(`name: stream_retry_test
+base_url: %[1]s/
+urls:
+  - %[1]s/a
+  - %[1]s/b
+rate_limit: 10ms
+failure_policy:
+  mode: stop
+fields:
+  - name: title
+    selector: h1
+    type: text
+output:
+  format: jsonl
+  file: unused.jsonl
+`, server.URL)
+	if err := os.WriteFile(configFile, []byte(content), 0644); err != nil {
+		t.Fatalf("failed to write config: %v", err)
+	}
+
+	var err error
+	out := captureOutput(func() {
+		err = executeScrapingOperation(context.Background(), configFile, false, true, io.Discard)
+	})
+	// The record of /a went out before /b failed; retrying would write it again
+	if !errors.IsPermanent(err) {
+		t.Errorf("err = %v, want a permanent error so the run is not retried", err)
+	}
+	if lines := strings.Split(strings.TrimSpace(out), "\n"); len(lines) != 1 || !strings.Contains(lines[0], `"title":"/a"`) {
+		t.Errorf("stdout = %q, want the record of /a once", out)
+	}
+}
+
+func TestJSONLFileStreamsRecords(t *testing.T) {
+	// The last URL is held back until the first record has reached the file
+	released := make(chan struct{})
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		switch r.URL.Path {
+		case "/b":
+			select {
+			case <-released:
+			case <-time.After(5 * time.Second):
+			}
+		case "/missing":
+			http.NotFound(w, r)
+			return
+		}
+		fmt.Fprintf(w, "<html><body><h1>%s</h1></body></html>", r.URL.Path)
+	}))
+	defer server.Close()
+
+	dir := t.TempDir()
+	outFile := filepath.Join(dir, "out", "records.jsonl")
+	writeConfig := func(paths ...string) string {
+		var urls strings.Builder
+		for _, path := range paths {
+			fmt.Fprintf(&urls, "  - %s%s\n", server.URL, path)
+		}
+		configFile := filepath.Join(dir, "config.yaml")
+		content := fmt.Sprintf(`name: jsonl_stream_test
+base_url: %s/
+urls:
+%srate_limit: 10ms
+failure_policy:
+  mode: stop
+fields:
+  - name: title
+    selector: h1
+    type: text
+output:
+  format: jsonl
+  file: %s
+`, server.URL, urls.String(), outFile)
+		if err := os.WriteFile(configFile, []byte(content), 0644); err != nil {
+			t.Fatalf("failed to write config: %v", err)
+		}
+		return configFile
+	}
+
+	configFile := writeConfig("/a", "/b")
+	done := make(chan error, 1)
+	go func() {
+		done <- executeScrapingOperation(context.Background(), configFile, false, false, io.Discard)
+	}()
+	deadline := time.Now().Add(3 * time.Second)
+	for {
+		if data, _ := os.ReadFile(outFile + ".tmp"); strings.Contains(string(data), `"title":"/a"`) {
+			break
+		}
+		if time.Now().After(deadline) {
+			close(released)
+			<-done
+			t.Fatal("no record in the output file before the last URL was served")
+		}
+		time.Sleep(10 * time.Millisecond)
+	}
+	close(released)
+	if err := <-done; err != nil {
+		t.Fatalf("run failed: %v", err)
+	}
+
+	data, err := os.ReadFile(outFile)
+	if err != nil {
+		t.Fatalf("output was not written: %v", err)
+	}
+	lines := strings.Split(strings.TrimSpace(string(data)), "\n")
+	if len(lines) != 2 || !strings.Contains(lines[0], `"title":"/a"`) || !strings.Contains(lines[1], `"title":"/b"`) {
+		t.Errorf("output = %q, want the records of /a and /b", data)
+	}
+	if _, err := os.Stat(outFile + ".tmp"); !os.IsNotExist(err) {
+		t.Errorf("partial file should be gone, stat error = %v", err)
+	}
+
+	// A failed run leaves the last output in place
+	configFile = writeConfig("/c", "/missing")
+	if err := executeScrapingOperation(context.Background(), configFile, false, false, io.Discard); err == nil {
+		t.Fatal("expected the missing URL to fail the run")
+	}
+	if kept, _ := os.ReadFile(outFile); string(kept) != string(data) {
+		t.Errorf("output after a failed run = %q, want %q", kept, data)
+	}
+	if _, err := os.Stat(outFile + ".tmp"); !os.IsNotExist(err) {
+		t.Errorf("partial file should be removed, stat error = %v", err)
+	}
+}
+
+func TestDryRun(t *testing.T) {
+	var requests atomic.Int32
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		requests.Add(1)
+		fmt.Fprint(w, `<html><body><h1>  Spring
+			catalog  </h1><p class="intro">`+strings.Repeat("long text ", 20)+`</p></body></html>`)
+	}))
+	defer server.Close()
+
+	dir := t.TempDir()
+	configFile := filepath.Join(dir, "config.yaml")
+	outFile := filepath.Join(dir, "out.json")
+	content := fmt.Sprintf(`name: dry_run_test
+base_url: %[1]s/
+urls:
+  - %[1]s/
+  - %[1]s/other
+fields:
+  - name: title
+    selector: h1
+    type: text
+    required: true
+  - name: intro
+    selector: p.intro
+    type: text
+  - name: price
+    selector: .price
+    type: text
+    required: true
+output:
+  format: json
+  file: %[2]s
+`, server.URL, outFile)
+	if err := os.WriteFile(configFile, []byte(content), 0644); err != nil {
+		t.Fatalf("failed to write config: %v", err)
+	}
+
+	report, err := dryRun(configFile)
+	if err == nil || !strings.Contains(err.Error(), "1 required field(s)") {
+		t.Errorf("dryRun error = %v, want the empty required field reported", err)
+	}
+	for _, want := range []string{
+		"Dry run: " + server.URL + "/ (3 fields)",
+		`"Spring catalog"`,
+		`"` + strings.Repeat("long text ", 6) + `..."`,
+		".price",
+		"(empty)  REQUIRED FIELD EMPTY",
+	} {
+		if !strings.Contains(report, want) {
+			t.Errorf("report should contain %q, got:\n%s", want, report)
+		}
+	}
+	if got := requests.Load(); got != 1 {
+		t.Errorf("dry run made %d requests, want only base_url", got)
+	}
+	if _, err := os.Stat(outFile); !os.IsNotExist(err) {
+		t.Error("dry run should not write the output file")
+	}
+}
+
+// Run with -race: the workers share the engine, its limiter and the checkpoint
+func TestScrapeURLsConcurrent(t *testing.T) {
+	urls := make([]string, 20)
+	for i := range urls {
+		urls[i] = fmt.Sprintf("https://shop.test/p/%d", i)
+	}
+	policy := errors.FailurePolicy{Mode: errors.FailureModeContinue}
+
+	t.Run("bounded workers, records in url order", func(t *testing.T) {
+		var active, peak atomic.Int32
+		scrape := func(ctx context.Context, url string, fields []scraper.FieldConfig) (*scraper.Result, error) {
+			current := active.Add(1)
+			defer active.Add(-1)
+			for {
+				p := peak.Load()
+				if current <= p || peak.CompareAndSwap(p, current) {
+					break
+				}
+			}
+			time.Sleep(time.Duration(len(url)%3) * time.Millisecond)
+			return &scraper.Result{Success: true, Data: map[string]interface{}{"page": url}}, nil
+		}
+		checkpoint, err := scraper.OpenCheckpoint(filepath.Join(t.TempDir(), "run.checkpoint"), false)
+		if err != nil {
+			t.Fatal(err)
+		}
+		checkpoint.Interval = 0
+
+		records, err := scrapeURLs(context.Background(), scrape, urls, true, nil, runOptions{policy: policy, concurrency: 4, checkpoint: checkpoint, status: io.Discard})
+		if err != nil {
+			t.Fatalf("unexpected error: %v", err)
+		}
+		if got := peak.Load(); got < 2 || got > 4 {
+			t.Errorf("peak concurrency = %d, want 2 to 4", got)
+		}
+		if len(records) != len(urls) {
+			t.Fatalf("got %d records, want %d", len(records), len(urls))
+		}
+		for i, record := range records {
+			if record["page"] != urls[i] {
+				t.Errorf("record %d is %v, want %s", i, record["page"], urls[i])
+			}
+		}
+		if len(checkpoint.Done) != len(urls) {
+			t.Errorf("checkpoint done = %d URLs, want %d", len(checkpoint.Done), len(urls))
+		}
+	})
+
+	t.Run("cancellation stops every worker", func(t *testing.T) {
+		ctx, cancel := context.WithCancel(context.Background())
+		var started atomic.Int32
+		scrape := func(ctx context.Context, url string, fields []scraper.FieldConfig) (*scraper.Result, error) {
+			n := started.Add(1)
+			if n < 6 {
+				return &scraper.Result{Success: true, Data: map[string]interface{}{}}, nil
+			}
+			if n == 6 {
+				cancel()
+			}
+			<-ctx.Done()
+			return nil, ctx.Err()
+		}
+		records, err := scrapeURLs(ctx, scrape, urls, true, nil, runOptions{policy: policy, concurrency: 3, status: io.Discard})
+		if err == nil || !strings.Contains(err.Error(), "interrupted") {
+			t.Errorf("err = %v, want an interrupted error", err)
+		}
+		if len(records) < 5 {
+			t.Errorf("got %d records, want the 5 scraped before cancellation", len(records))
+		}
+		// Only scrapes already running on the other two workers may start late
+		if got := started.Load(); got > 8 {
+			t.Errorf("%d URLs started, want at most 8", got)
+		}
+	})
+
+	t.Run("engine shared by workers", func(t *testing.T) {
+		server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+			fmt.Fprintf(w, "<html><body><h1>%s</h1></body></html>", r.URL.Path)
+		}))
+		defer server.Close()
+
+		engine, err := scraper.NewEngine(&scraper.Config{
+			UserAgents: []string{"a", "b"},
+			RateLimit:  time.Millisecond,
+			BurstSize:  4,
+		})
+		if err != nil {
+			t.Fatal(err)
+		}
+		pages := make([]string, 12)
+		for i := range pages {
+			pages[i] = fmt.Sprintf("%s/p/%d", server.URL, i)
+		}
+		fields := []scraper.FieldConfig{{Name: "title", Selector: "h1", Type: "text"}}
+
+		records, err := scrapeURLs(context.Background(), engine.Scrape, pages, true, fields, runOptions{policy: policy, concurrency: 4, status: io.Discard})
+		if err != nil {
+			t.Fatalf("unexpected error: %v", err)
+		}
+		for i, record := range records {
+			if record["title"] != fmt.Sprintf("/p/%d", i) {
+				t.Errorf("record %d title = %v", i, record["title"])
+			}
+		}
+	})
+}
+
+func TestScrapeRunPagination(t *testing.T) {
+	// Three pages, each linking to the next with a Link header and a cursor token
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		page := r.URL.Query().Get("page") + r.URL.Query().Get("after")
+		if page == "" {
+			page = "1"
+		}
+		next := map[string]string{"1": "2", "2": "3"}[page]
+		if next != "" {
+			w.Header().Set("Link", fmt.Sprintf(`<%s/?page=%s>; rel="next"`, "http://"+r.Host, next))
+		}
+		fmt.Fprintf(w, `<html><body><h1>Page %s</h1><a class="next" data-token="%s">more</a></body></html>`, page, next)
+	}))
+	defer server.Close()
+
+	policy := errors.FailurePolicy{Mode: errors.FailureModeContinue}
+	fields := []config.Field{{Name: "title", Selector: "h1", Type: "text"}}
+
+	for _, pagination := range []*config.PaginationConfig{
+		{Type: "link_header", MaxPages: 5},
+		{Type: "cursor", MaxPages: 5, CursorSelector: "a.next", CursorAttr: "data-token", CursorParam: "after"},
+	} {
+		t.Run(pagination.Type, func(t *testing.T) {
+			cfg := &config.ScraperConfig{Name: "paged", BaseURL: server.URL + "/", Fields: fields, Pagination: pagination}
+			engine, err := scraper.NewEngine(convertToEngineConfig(cfg))
+			if err != nil {
+				t.Fatal(err)
+			}
+
+			records, err := scrapeRun(context.Background(), cfg, engine, []string{cfg.BaseURL}, false, convertToFieldConfigs(fields), runOptions{policy: policy, status: io.Discard})
+			if err != nil {
+				t.Fatalf("unexpected error: %v", err)
+			}
+			var titles []string
+			for _, record := range records {
+				titles = append(titles, fmt.Sprint(record["title"]))
+			}
+			if strings.Join(titles, ", ") != "Page 1, Page 2, Page 3" {
+				t.Errorf("titles = %v, want one record per page", titles)
+			}
+		})
+	}
+}
+
+func TestScrapePaginatedURLs(t *testing.T) {
+	urls := []string{"https://a.test", "https://b.test"}
+	paginate := func(ctx context.Context, url string, fields []scraper.FieldConfig) (*scraper.PaginationResult, error) {
+		if url == "https://b.test" {
+			return &scraper.PaginationResult{Errors: []string{"Page 1 failed: 503"}}, nil
+		}
+		return &scraper.PaginationResult{Pages: []scraper.ScrapingResult{
+			{URL: url + "/1", Success: true, Data: map[string]interface{}{"n": 1}},
+			{URL: url + "/2", Success: true, Data: map[string]interface{}{"n": 2}},
+		}, Success: true}, nil
+	}
+	checkpoint, err := scraper.OpenCheckpoint(filepath.Join(t.TempDir(), "run.checkpoint"), false)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	var status bytes.Buffer
+	policy := errors.FailurePolicy{Mode: errors.FailureModeContinue}
+	records, err := scrapePaginatedURLs(context.Background(), paginate, urls, true, nil, runOptions{policy: policy, checkpoint: checkpoint, status: &status})
+	if err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	if len(records) != 2 || records[1][sourceURLKey] != "https://a.test/2" {
+		t.Errorf("records = %v, want each page tagged with its URL", records)
+	}
+	if !strings.Contains(status.String(), "Skipping https://b.test: Page 1 failed: 503") {
+		t.Errorf("status = %q, want the URL without pages skipped", status.String())
+	}
+	if !checkpoint.IsDone("https://a.test") || checkpoint.IsDone("https://b.test") || len(checkpoint.Records) != 2 {
+		t.Errorf("checkpoint done = %v with %d records", checkpoint.Done, len(checkpoint.Records))
+	}
+}
+
+func TestScrapeURLsSkipped(t *testing.T) {
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		if r.URL.Path == "/robots.txt" {
+			fmt.Fprint(w, "User-agent: *\nDisallow: /private\n")
+			return
+		}
+		fmt.Fprintf(w, "<html><body><h1>%s</h1></body></html>", r.URL.Path)
+	}))
+	defer server.Close()
+
+	engine, err := scraper.NewEngine(&scraper.Config{RespectRobotsTxt: true, MaxPagesPerHost: 2})
+	if err != nil {
+		t.Fatal(err)
+	}
+	urls := []string{server.URL + "/a", server.URL + "/private/b", server.URL + "/c", server.URL + "/d"}
+	fields := []scraper.FieldConfig{{Name: "title", Selector: "h1", Type: "text"}}
+
+	// Under the stop policy a skipped URL must not end the run
+	var status bytes.Buffer
+	policy := errors.FailurePolicy{Mode: errors.FailureModeStop}
+	records, err := scrapeURLs(context.Background(), engine.Scrape, urls, true, fields, runOptions{policy: policy, status: &status})
+	if err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	if len(records) != 2 {
+		t.Errorf("got %d records, want /a and /c", len(records))
+	}
+	if !strings.Contains(status.String(), "Skipped 2 URLs: 1 disallowed by robots.txt, 1 over max_pages_per_host") {
+		t.Errorf("status = %q, want skipped URLs counted by reason", status.String())
+	}
+}
+
+func TestScrapeURLsCheckpoint(t *testing.T) {
+	urls := []string{"https://a.test", "https://b.test", "https://c.test", "https://d.test"}
+	path := filepath.Join(t.TempDir(), "run.checkpoint")
+	policy := errors.FailurePolicy{Mode: errors.FailureModeContinue}
+	page := func(url string) *scraper.Result {
+		return &scraper.Result{Success: true, Data: map[string]interface{}{"page": url}}
+	}
+
+	// The first run is interrupted while scraping the third URL
+	checkpoint, err := scraper.OpenCheckpoint(path, true)
+	if err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	checkpoint.Interval = 0
+	ctx, interrupt := context.WithCancel(context.Background())
+	crash := func(ctx context.Context, url string, fields []scraper.FieldConfig) (*scraper.Result, error) {
+		if url == "https://c.test" {
+			interrupt()
+			return nil, ctx.Err()
+		}
+		return page(url), nil
+	}
+	if _, err := scrapeURLs(ctx, crash, urls, true, nil, runOptions{policy: policy, checkpoint: checkpoint, status: io.Discard}); err == nil {
+		t.Error("an interrupted run should return an error")
+	}
+
+	// The second run scrapes only what the first did not finish
+	checkpoint, err = scraper.OpenCheckpoint(path, true)
+	if err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	if !checkpoint.Resumed() || len(checkpoint.Done) != 2 {
+		t.Fatalf("checkpoint done = %v, want the first two URLs", checkpoint.Done)
+	}
+	var scraped []string
+	resume := func(ctx context.Context, url string, fields []scraper.FieldConfig) (*scraper.Result, error) {
+		scraped = append(scraped, url)
+		return page(url), nil
+	}
+	records, err := scrapeURLs(context.Background(), resume, urls, true, nil, runOptions{policy: policy, checkpoint: checkpoint, status: io.Discard})
+	if err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	if fmt.Sprint(scraped) != "[https://c.test https://d.test]" {
+		t.Errorf("resumed run scraped %v", scraped)
+	}
+	var pages []interface{}
+	for _, record := range records {
+		pages = append(pages, record["page"])
+	}
+	if fmt.Sprint(pages) != fmt.Sprint(urls) {
+		t.Errorf("records = %v, want one per URL in order", pages)
+	}
+
+	// Once the output is written the checkpoint goes, and --no-resume ignores one
+	finishCheckpoint(checkpoint, nil, io.Discard)
+	if _, err := os.Stat(path); !os.IsNotExist(err) {
+		t.Errorf("checkpoint still present after a finished run: %v", err)
+	}
+	os.WriteFile(path, []byte(`{"done":["https://a.test"]}`), 0644)
+	if checkpoint, err := scraper.OpenCheckpoint(path, false); err != nil || checkpoint.Resumed() {
+		t.Errorf("no-resume checkpoint resumed = %v, err = %v", checkpoint != nil && checkpoint.Resumed(), err)
+	}
+}
+
+func TestScrapeURLsCheckpointDeduplication(t *testing.T) {
+	urls := []string{"https://a.test", "https://b.test", "https://c.test"}
+	path := filepath.Join(t.TempDir(), "run.checkpoint")
+	skus := map[string]string{"https://a.test": "A1", "https://b.test": "B1", "https://c.test": "A1"}
+	page := func(url string) *scraper.Result {
+		return &scraper.Result{Success: true, Data: map[string]interface{}{"sku": skus[url]}}
+	}
+	open := func() runOptions {
+		checkpoint, err := scraper.OpenCheckpoint(path, true)
+		if err != nil {
+			t.Fatalf("unexpected error: %v", err)
+		}
+		checkpoint.Interval = 0
+		dedup := newRunDeduplicator(&config.DeduplicationConfig{Method: "field", Fields: []string{"sku"}})
+		if err := checkpoint.TrackDeduplicator(dedup); err != nil {
+			t.Fatalf("unexpected error: %v", err)
+		}
+		return runOptions{policy: errors.FailurePolicy{Mode: errors.FailureModeContinue}, checkpoint: checkpoint, dedup: dedup, status: io.Discard}
+	}
+
+	// The first run is interrupted before c.test, which repeats a.test's SKU
+	ctx, interrupt := context.WithCancel(context.Background())
+	crash := func(ctx context.Context, url string, fields []scraper.FieldConfig) (*scraper.Result, error) {
+		if url == "https://c.test" {
+			interrupt()
+			return nil, ctx.Err()
+		}
+		return page(url), nil
+	}
+	scrapeURLs(ctx, crash, urls, false, nil, open())
+
+	// The resumed run still knows A1 and drops the repeat
+	resume := func(ctx context.Context, url string, fields []scraper.FieldConfig) (*scraper.Result, error) {
+		return page(url), nil
+	}
+	records, err := scrapeURLs(context.Background(), resume, urls, false, nil, open())
+	if err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	if len(records) != 2 || records[0]["sku"] != "A1" || records[1]["sku"] != "B1" {
+		t.Errorf("records = %v, want A1 and B1 once each", records)
+	}
+}
+
+func TestMarkChanges(t *testing.T) {
+	path := filepath.Join(t.TempDir(), "changes.json")
+	run := func(records ...map[string]interface{}) ([]map[string]interface{}, string) {
+		changes, err := pipeline.OpenChangeDetector(path, "url", true)
+		if err != nil {
+			t.Fatalf("unexpected error: %v", err)
+		}
+		var status bytes.Buffer
+		marked, err := markChanges(changes, records, &status)
+		if err != nil {
+			t.Fatalf("unexpected error: %v", err)
+		}
+		saveChanges(changes, &status)
+		return marked, status.String()
+	}
+
+	run(map[string]interface{}{"url": "https://a.test", "title": "A"}, map[string]interface{}{"url": "https://b.test", "title": "B"})
+	marked, status := run(map[string]interface{}{"url": "https://a.test", "title": "A"}, map[string]interface{}{"url": "https://b.test", "title": "B2"})
+	if len(marked) != 1 || marked[0]["url"] != "https://b.test" || marked[0][pipeline.ChangeField] != pipeline.ChangeModified {
+		t.Errorf("second run = %v, want only b.test marked modified", marked)
+	}
+	if !strings.Contains(status, "0 new, 1 modified, 1 unchanged") {
+		t.Errorf("status = %q, want the change counts", status)
+	}
+}
@@ -276,6 +276,54 @@ func (bm *BrowserManager) FetchHTML(ctx context.Context, url string) (string, er
 	return html, nil
 }
 
+// Navigate loads url, waiting for WaitForElement and WaitDelay, so the page
+// can be worked on before its HTML is read with CurrentHTML
+func (bm *BrowserManager) Navigate(ctx context.Context, url string) error {
+	if !bm.IsEnabled() {
+		return fmt.Errorf("browser automation is not enabled")
+	}
+	if err := bm.client.Navigate(ctx, url); err != nil {
+		return fmt.Errorf("navigation failed: %w", err)
+	}
+	return nil
+}
+
+// CurrentHTML returns the HTML of the page as currently rendered
+func (bm *BrowserManager) CurrentHTML(ctx context.Context) (string, error) {
+	if !bm.IsEnabled() {
+		return "", fmt.Errorf("browser automation is not enabled")
+	}
+	html, err := bm.client.GetHTML(ctx)
+	if err != nil {
+		return "", fmt.Errorf("HTML extraction failed: %w", err)
+	}
+	return html, nil
+}
+
+// ScrollToBottom scrolls the page to the bottom and then, as after navigation,
+// waits for WaitForElement and WaitDelay so content loaded by the scroll renders
+func (bm *BrowserManager) ScrollToBottom(ctx context.Context) error {
+	if !bm.IsEnabled() {
+		return fmt.Errorf("browser automation is not enabled")
+	}
+	if _, err := bm.client.ExecuteScript(ctx, "window.scrollTo(0, document.body.scrollHeight)"); err != nil {
+		return fmt.Errorf("scroll failed: %w", err)
+	}
+	if bm.config.WaitForElement != "" {
+		if err := bm.WaitForElement(ctx, bm.config.WaitForElement); err != nil {
+			return err
+		}
+	}
+	if bm.config.WaitDelay > 0 {
+		select {
+		case <-time.After(bm.config.WaitDelay):
+		case <-ctx.Done():
+			return ctx.Err()
+		}
+	}
+	return nil
+}
+
 // ExecuteJavaScript executes JavaScript in the browser
 func (bm *BrowserManager) ExecuteJavaScript(ctx context.Context, script string) (*interface{}, error) {
 	if !bm.IsEnabled() {
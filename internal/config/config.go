@@ -7,6 +7,7 @@ import (
 	"encoding/hex"
 	"fmt"
 	"os"
+	"regexp"
 	"sync"
 	"sync/atomic"
 	"time"
@@ -22,15 +23,49 @@ type ScraperConfig struct {
 	URLs       []string          `yaml:"urls,omitempty" json:"urls,omitempty"`
 	UserAgents []string          `yaml:"user_agents,omitempty" json:"user_agents,omitempty"`
 	RateLimit  string            `yaml:"rate_limit,omitempty" json:"rate_limit,omitempty"`
+	HostRateLimits map[string]HostRateLimitConfig `yaml:"host_rate_limits,omitempty" json:"host_rate_limits,omitempty"` // Per-host rate limits, by host or host:port; rate_limit applies elsewhere
 	Timeout    string            `yaml:"timeout,omitempty" json:"timeout,omitempty"`
 	MaxRetries              int               `yaml:"max_retries,omitempty" json:"max_retries,omitempty"`
+	DNSRetries              int               `yaml:"dns_retries,omitempty" json:"dns_retries,omitempty"` // Retries for hosts that do not resolve (default 0)
+	RetryJitter             float64           `yaml:"retry_jitter,omitempty" json:"retry_jitter,omitempty"` // Randomize retry delays by up to this fraction (0-1) either way
+	MaxPagesPerHost         int               `yaml:"max_pages_per_host,omitempty" json:"max_pages_per_host,omitempty"` // Cap on pages scraped from any one host (0 = no cap)
 	Retries                 int               `yaml:"retries,omitempty" json:"retries,omitempty"` // Added missing field
 	ErrorThreshold          int               `yaml:"error_threshold,omitempty" json:"error_threshold,omitempty"`          // Maximum errors per batch before stopping
 	ErrorThresholdPercent   float64           `yaml:"error_threshold_percent,omitempty" json:"error_threshold_percent,omitempty"` // Error rate threshold (0-100)
 	StopOnErrorThreshold    bool              `yaml:"stop_on_error_threshold,omitempty" json:"stop_on_error_threshold,omitempty"` // Whether to stop processing when threshold is exceeded
-	Headers                 map[string]string `yaml:"headers,omitempty" json:"headers,omitempty"`
+	FailurePolicy           *FailurePolicyConfig `yaml:"failure_policy,omitempty" json:"failure_policy,omitempty"`      // How failed URLs of a multi-URL run are treated
+	Checkpoint              string            `yaml:"checkpoint,omitempty" json:"checkpoint,omitempty"`                 // File saving run progress; an interrupted run resumes from it
+	ChangeDetection         *ChangeDetectionConfig `yaml:"change_detection,omitempty" json:"change_detection,omitempty"` // Mark records new, modified or unchanged since the last run
+	Deduplication           *DeduplicationConfig `yaml:"deduplication,omitempty" json:"deduplication,omitempty"`       // Drop records that repeat an earlier record of the run
+	Concurrency             int               `yaml:"concurrency,omitempty" json:"concurrency,omitempty"`               // Workers scraping urls at once, sharing rate_limit (default 1)
+	Headers                 map[string]HeaderValues `yaml:"headers,omitempty" json:"headers,omitempty"` // A list value sends one header line per entry
 	Cookies    map[string]string `yaml:"cookies,omitempty" json:"cookies,omitempty"`
+	RespectCrawlDelay       bool              `yaml:"respect_crawl_delay,omitempty" json:"respect_crawl_delay,omitempty"` // Slow down to robots.txt Crawl-delay per host
+	RespectRobotsTxt        bool              `yaml:"respect_robots_txt,omitempty" json:"respect_robots_txt,omitempty"`   // Skip URLs robots.txt disallows and honor its Crawl-delay
+	NormalizeText           *bool             `yaml:"normalize_text,omitempty" json:"normalize_text,omitempty"`         // Normalize unicode and whitespace in text fields (default true)
+	HiddenContent           *HiddenContentConfig `yaml:"hidden_content,omitempty" json:"hidden_content,omitempty"`      // Include or drop noscript/template/script/style content in extraction
+	LazyImages              *LazyImageConfig  `yaml:"lazy_images,omitempty" json:"lazy_images,omitempty"`               // Attribute precedence for src/srcset attr fields (data-src before src)
+	CanonicalURL            *CanonicalURLConfig `yaml:"canonical_url,omitempty" json:"canonical_url,omitempty"`         // Key pages by canonical URL when following pagination
+	MalformedHTML           *MalformedHTMLConfig `yaml:"malformed_html,omitempty" json:"malformed_html,omitempty"`      // Warn about (and optionally re-fetch) broken or truncated pages
+	StrictMode              bool              `yaml:"strict_mode,omitempty" json:"strict_mode,omitempty"`               // Drop fields whose value output_type cannot coerce
+	RetryOnEmpty            bool              `yaml:"retry_on_empty,omitempty" json:"retry_on_empty,omitempty"`         // Re-fetch pages where the watched fields all missed
+	RetryOnEmptyFields      []string          `yaml:"retry_on_empty_fields,omitempty" json:"retry_on_empty_fields,omitempty"` // Fields watched by retry_on_empty (default: required fields)
+	RetryOnBodyMatch        []string          `yaml:"retry_on_body_match,omitempty" json:"retry_on_body_match,omitempty"`       // Regexes marking a 200 body as transient ("try again later"); retried
+	NoRetryOnBodyMatch      []string          `yaml:"no_retry_on_body_match,omitempty" json:"no_retry_on_body_match,omitempty"` // Regexes marking a 200 body as a permanent failure; not retried
+	WarmupURLs              []string          `yaml:"warmup_urls,omitempty" json:"warmup_urls,omitempty"`               // Fetched in order before scraping to pick up session cookies
+	CookieJar               bool              `yaml:"cookie_jar,omitempty" json:"cookie_jar,omitempty"`                 // Keep every Set-Cookie and send cookies back (implied by warmup_urls)
+	CookieJarFile           string            `yaml:"cookie_jar_file,omitempty" json:"cookie_jar_file,omitempty"`       // Load the cookie jar from this file and save it after the run (implies cookie_jar)
+	DisableCompression      bool              `yaml:"disable_compression,omitempty" json:"disable_compression,omitempty"` // Don't request or decode gzip/deflate/brotli response bodies
+	Method                  string            `yaml:"method,omitempty" json:"method,omitempty"`                       // HTTP method pages are requested with (default GET)
+	Body                    interface{}       `yaml:"body,omitempty" json:"body,omitempty"`                           // Request body; ${page} is replaced by the page number
+	BodyType                string            `yaml:"body_type,omitempty" json:"body_type,omitempty"`                 // form, json or raw (default json for a map body, raw for a string)
+	URLRequests             map[string]RequestConfig `yaml:"url_requests,omitempty" json:"url_requests,omitempty"` // Method and body for particular URLs, replacing method/body/body_type
 	Proxy      *ProxyConfig      `yaml:"proxy,omitempty" json:"proxy,omitempty"`
+	Transport  *TransportConfig  `yaml:"transport,omitempty" json:"transport,omitempty"`
+	Debug      *DebugConfig      `yaml:"debug,omitempty" json:"debug,omitempty"`
+	TLS        *TLSFingerprintConfig `yaml:"tls,omitempty" json:"tls,omitempty"`
+	HeaderOrder *HeaderOrderConfig   `yaml:"header_order,omitempty" json:"header_order,omitempty"`
+	BlockAbort *BlockAbortConfig     `yaml:"block_abort,omitempty" json:"block_abort,omitempty"`
 	Browser    *BrowserConfig    `yaml:"browser,omitempty" json:"browser,omitempty"`
 	Fields     []Field           `yaml:"fields" json:"fields"`
 	Pagination *PaginationConfig `yaml:"pagination,omitempty" json:"pagination,omitempty"`
@@ -46,6 +81,69 @@ type Field struct {
 	Attribute string          `yaml:"attribute,omitempty" json:"attribute,omitempty"`
 	Default   interface{}     `yaml:"default,omitempty" json:"default,omitempty"`
 	Transform []TransformRule `yaml:"transform,omitempty" json:"transform,omitempty"`
+	Sources   []FieldSource   `yaml:"sources,omitempty" json:"sources,omitempty"` // Ordered fallbacks; first non-empty result wins
+	Path      string          `yaml:"path,omitempty" json:"path,omitempty"`       // JSON path for embedded_json fields, e.g. props.pageProps.product.price
+	Columns   []string        `yaml:"columns,omitempty" json:"columns,omitempty"` // Row keys for table fields in column order (default: header cells)
+
+	// SelectorType is how selector is read: css (default) or xpath, e.g.
+	// //div[@id='price']/following-sibling::span
+	SelectorType string `yaml:"selector_type,omitempty" json:"selector_type,omitempty"`
+
+	// Selectors are fallbacks for A/B tested markup, tried in order after selector
+	// (which may then be left out); the first that yields a non-empty value is used
+	Selectors []string `yaml:"selectors,omitempty" json:"selectors,omitempty"`
+
+	// Source is shorthand for a single entry in Sources, e.g. source: jsonld with path
+	// and jsonld_type; it takes its selector, attribute and path from the field
+	Source     string `yaml:"source,omitempty" json:"source,omitempty"`
+	JSONLDType string `yaml:"jsonld_type,omitempty" json:"jsonld_type,omitempty"` // @type filter for type jsonld or microdata, or source jsonld, e.g. Product
+
+	// ExtractTimeout bounds extraction of this field (e.g. "2s"); on timeout the field is missing.
+	// Fields with regex sources default to 5s.
+	ExtractTimeout string `yaml:"extract_timeout,omitempty" json:"extract_timeout,omitempty"`
+
+	// Validate checks the value after default substitution and transforms. A failing
+	// value falls back to Default on optional fields and fails required ones.
+	Validate *FieldValidation `yaml:"validate,omitempty" json:"validate,omitempty"`
+
+	// OutputType coerces the final value to number, integer or boolean so JSON output
+	// carries typed values; a value that does not convert is kept unless strict_mode is set
+	OutputType string `yaml:"output_type,omitempty" json:"output_type,omitempty"`
+}
+
+// FieldValidation constrains a field's value. Fields are processed in the order
+// extract, default-if-empty, transform, validate; BeforeTransform moves the
+// check ahead of the transforms.
+type FieldValidation struct {
+	Pattern         string   `yaml:"pattern,omitempty" json:"pattern,omitempty"`
+	MinLength       int      `yaml:"min_length,omitempty" json:"min_length,omitempty"`
+	MaxLength       int      `yaml:"max_length,omitempty" json:"max_length,omitempty"`
+	Options         []string `yaml:"options,omitempty" json:"options,omitempty"`
+	BeforeTransform bool     `yaml:"before_transform,omitempty" json:"before_transform,omitempty"`
+}
+
+// FieldSources returns the field's source list, expanding the source shorthand
+func (f Field) FieldSources() []FieldSource {
+	if f.Source == "" {
+		return f.Sources
+	}
+	return []FieldSource{{
+		Type:       f.Source,
+		Selector:   f.Selector,
+		Attribute:  f.Attribute,
+		Path:       f.Path,
+		JSONLDType: f.JSONLDType,
+	}}
+}
+
+// FieldSource is one extraction method in a field's fallback list
+type FieldSource struct {
+	Type       string `yaml:"type" json:"type"` // css, attr, html, header, jsonld, regex
+	Selector   string `yaml:"selector,omitempty" json:"selector,omitempty"`
+	Attribute  string `yaml:"attribute,omitempty" json:"attribute,omitempty"`
+	Path       string `yaml:"path,omitempty" json:"path,omitempty"`               // JSON-LD path, e.g. offers.price or offers[0].price
+	Pattern    string `yaml:"pattern,omitempty" json:"pattern,omitempty"`         // Regex over page HTML; first group if any
+	JSONLDType string `yaml:"jsonld_type,omitempty" json:"jsonld_type,omitempty"` // Only match JSON-LD objects of this @type
 }
 
 // FieldConfig is an alias for Field to maintain backward compatibility
@@ -58,13 +156,82 @@ type PaginationConfig struct {
 	MaxPages   int    `yaml:"max_pages,omitempty" json:"max_pages,omitempty"`
 	URLPattern string `yaml:"url_pattern,omitempty" json:"url_pattern,omitempty"`
 	StartPage  int    `yaml:"start_page,omitempty" json:"start_page,omitempty"`
+
+	// Type sitemap scrapes the pages listed by SitemapURL (a sitemap or sitemap
+	// index, plain or gzip) up to MaxPages, keeping those matching the URLFilter
+	// glob, where * matches any run of characters
+	SitemapURL string `yaml:"sitemap_url,omitempty" json:"sitemap_url,omitempty"`
+	URLFilter  string `yaml:"url_filter,omitempty" json:"url_filter,omitempty"`
+
+	// Type infinite_scroll needs the browser: it scrolls the page to the bottom
+	// up to MaxPages times, waiting ScrollPause (default 1s) after each, and
+	// scrapes the ItemSelector items each scroll adds
+	ItemSelector string `yaml:"item_selector,omitempty" json:"item_selector,omitempty"`
+	ScrollPause  string `yaml:"scroll_pause,omitempty" json:"scroll_pause,omitempty"`
+
+	// Type cursor reads the next page's token with CursorSelector, a CSS selector
+	// (its text, or the CursorAttr attribute) or a JSON path starting with "$",
+	// and sends it as the CursorParam query parameter (default cursor)
+	CursorSelector string `yaml:"cursor_selector,omitempty" json:"cursor_selector,omitempty"`
+	CursorAttr     string `yaml:"cursor_attr,omitempty" json:"cursor_attr,omitempty"`
+	CursorParam    string `yaml:"cursor_param,omitempty" json:"cursor_param,omitempty"`
 }
 
 // OutputConfig represents output configuration
 type OutputConfig struct {
 	Format        string `yaml:"format" json:"format"`
-	File          string `yaml:"file" json:"file"`
+	File          string `yaml:"file" json:"file"` // May contain {name}, {host}, {date[:layout]}, {timestamp[:layout]}
 	EnableMetrics bool   `yaml:"enable_metrics,omitempty" json:"enable_metrics,omitempty"`
+
+	// CSV header handling: Columns fixes the header and streams records (extra fields
+	// are dropped); CSVUnion buffers every record to use the union of all keys
+	Columns  []string `yaml:"columns,omitempty" json:"columns,omitempty"`
+	CSVUnion bool     `yaml:"csv_union,omitempty" json:"csv_union,omitempty"`
+
+	// NestedEncoding controls map and slice values in CSV cells: json (default), flatten or drop
+	NestedEncoding string `yaml:"nested_encoding,omitempty" json:"nested_encoding,omitempty"`
+
+	// CSV sets the delimiter, quoting and header column order of CSV output
+	CSV *CSVOptions `yaml:"csv,omitempty" json:"csv,omitempty"`
+
+	// BOM prefixes CSV, JSON and JSON Lines files with a UTF-8 byte order mark;
+	// LineEnding is lf (default) or crlf. Other formats reject both.
+	BOM        bool   `yaml:"bom,omitempty" json:"bom,omitempty"`
+	LineEnding string `yaml:"line_ending,omitempty" json:"line_ending,omitempty"`
+
+	// ParquetSchema sets Parquet column types (string, int64, double, boolean) by
+	// column name; other columns take the type inferred from their values
+	ParquetSchema map[string]string `yaml:"parquet_schema,omitempty" json:"parquet_schema,omitempty"`
+
+	// SheetName names the worksheet of xlsx output (default Sheet1)
+	SheetName string `yaml:"sheet_name,omitempty" json:"sheet_name,omitempty"`
+
+	// QualityGate fails the run after the output is written when the data falls below it
+	QualityGate *QualityGateConfig `yaml:"quality_gate,omitempty" json:"quality_gate,omitempty"`
+
+	// PartitionBy writes one file per value of this field, substituting the value for
+	// {partition} in File; records without it go to PartitionDefault (default "unpartitioned")
+	PartitionBy      string `yaml:"partition_by,omitempty" json:"partition_by,omitempty"`
+	PartitionDefault string `yaml:"partition_default,omitempty" json:"partition_default,omitempty"`
+
+	// Outputs writes the same records to several sinks, each with its own format,
+	// file and format options. It replaces Format and File; QualityGate stays
+	// here and checks the run once.
+	Outputs []OutputConfig `yaml:"outputs,omitempty" json:"outputs,omitempty"`
+}
+
+// Sinks returns the outputs records are written to: Outputs when set, otherwise o itself
+func (o OutputConfig) Sinks() []OutputConfig {
+	if len(o.Outputs) > 0 {
+		return o.Outputs
+	}
+	return []OutputConfig{o}
+}
+
+// QualityGateConfig sets the minimum data quality a run must reach to succeed
+type QualityGateConfig struct {
+	MinRecords int                `yaml:"min_records,omitempty" json:"min_records,omitempty"` // Fewest records a run may produce
+	MinFill    map[string]float64 `yaml:"min_fill,omitempty" json:"min_fill,omitempty"`       // Lowest fill rate (0-1) per field
 }
 
 // ProxyConfig represents proxy configuration
@@ -74,6 +241,7 @@ type ProxyConfig struct {
 	HealthCheck      bool            `yaml:"health_check,omitempty" json:"health_check,omitempty"`
 	HealthCheckURL   string          `yaml:"health_check_url,omitempty" json:"health_check_url,omitempty"`
 	HealthCheckRate  string          `yaml:"health_check_rate,omitempty" json:"health_check_rate,omitempty"`
+	HealthCheckMaxInterval string    `yaml:"health_check_max_interval,omitempty" json:"health_check_max_interval,omitempty"` // Backoff cap for proxies failing health checks (default 1h)
 	Timeout          string          `yaml:"timeout,omitempty" json:"timeout,omitempty"`
 	MaxRetries       int             `yaml:"max_retries,omitempty" json:"max_retries,omitempty"`
 	RetryDelay       string          `yaml:"retry_delay,omitempty" json:"retry_delay,omitempty"`
@@ -81,6 +249,11 @@ type ProxyConfig struct {
 	FailureThreshold int             `yaml:"failure_threshold,omitempty" json:"failure_threshold,omitempty"`
 	RecoveryTime     string          `yaml:"recovery_time,omitempty" json:"recovery_time,omitempty"`
 	TLS              *TLSConfig      `yaml:"tls,omitempty" json:"tls,omitempty"`
+	Affinity         string          `yaml:"proxy_affinity,omitempty" json:"proxy_affinity,omitempty"`       // "worker" pins a proxy per worker
+	AffinityRequests int             `yaml:"affinity_requests,omitempty" json:"affinity_requests,omitempty"` // Requests before a pinned worker rotates
+	LogSelection     bool            `yaml:"log_selection,omitempty" json:"log_selection,omitempty"`         // Log the proxy that served each URL
+	IncludeInMeta    bool            `yaml:"include_in_meta,omitempty" json:"include_in_meta,omitempty"`     // Record it under _meta in each record
+	HostGroups       map[string]string `yaml:"host_groups,omitempty" json:"host_groups,omitempty"`           // Host pattern to provider group, e.g. "*.shop.com": residential
 
 	// Legacy support for single proxy URL
 	URL      string `yaml:"url,omitempty" json:"url,omitempty"`
@@ -88,6 +261,125 @@ type ProxyConfig struct {
 	Password string `yaml:"password,omitempty" json:"password,omitempty"`
 }
 
+// TransportConfig holds per-phase connection deadlines, distinct from the overall request timeout
+type TransportConfig struct {
+	DialTimeout           string `yaml:"dial_timeout,omitempty" json:"dial_timeout,omitempty"`
+	TLSHandshakeTimeout   string `yaml:"tls_handshake_timeout,omitempty" json:"tls_handshake_timeout,omitempty"`
+	ResponseHeaderTimeout string `yaml:"response_header_timeout,omitempty" json:"response_header_timeout,omitempty"`
+	MaxTotalConnections   int    `yaml:"max_total_connections,omitempty" json:"max_total_connections,omitempty"` // Cap on open connections across all hosts; extra dials queue
+}
+
+// DebugConfig holds options for diagnosing failed scrapes
+type DebugConfig struct {
+	SaveFailedBodies string `yaml:"save_failed_bodies,omitempty" json:"save_failed_bodies,omitempty"` // Directory for raw bodies of failed pages
+	RecordSession    string `yaml:"record_session,omitempty" json:"record_session,omitempty"`         // Directory to save every request and response to
+	ReplaySession    string `yaml:"replay_session,omitempty" json:"replay_session,omitempty"`         // Recorded directory to serve responses from instead of the network
+}
+
+// TLSFingerprintConfig controls the TLS ClientHello presented to target sites
+type TLSFingerprintConfig struct {
+	Mimic string `yaml:"mimic,omitempty" json:"mimic,omitempty"` // chrome, firefox or safari (needs a -tags utls build)
+}
+
+// HeaderOrderConfig sends request headers in a browser-like order and casing
+type HeaderOrderConfig struct {
+	Profile string   `yaml:"profile,omitempty" json:"profile,omitempty"` // chrome, firefox or safari (default: tls.mimic)
+	Order   []string `yaml:"order,omitempty" json:"order,omitempty"`     // Explicit wire order, spelled with the casing to send
+}
+
+// HiddenContentConfig chooses which unrendered content extraction sees; unset
+// entries default to including noscript and excluding template, script and style
+type HiddenContentConfig struct {
+	Noscript *bool `yaml:"noscript,omitempty" json:"noscript,omitempty"`
+	Template *bool `yaml:"template,omitempty" json:"template,omitempty"`
+	Script   *bool `yaml:"script,omitempty" json:"script,omitempty"`
+	Style    *bool `yaml:"style,omitempty" json:"style,omitempty"`
+}
+
+// LazyImageConfig lists the attributes attr fields try, in order, before src or
+// srcset; empty lists use the engine defaults (data-src, data-original, ...)
+type LazyImageConfig struct {
+	Disabled bool     `yaml:"disabled,omitempty" json:"disabled,omitempty"`
+	Src      []string `yaml:"src,omitempty" json:"src,omitempty"`
+	Srcset   []string `yaml:"srcset,omitempty" json:"srcset,omitempty"`
+}
+
+// FailurePolicyConfig sets how a run over several urls treats URLs that fail:
+// stop fails the run at the first one, continue skips them, and partial (the
+// default) skips them but fails the run when more than max_error_rate failed
+type FailurePolicyConfig struct {
+	Mode               string   `yaml:"mode,omitempty" json:"mode,omitempty"`
+	MaxErrorRate       *float64 `yaml:"max_error_rate,omitempty" json:"max_error_rate,omitempty"`             // Fraction of URLs, 0-1 (default 0.3)
+	SavePartialResults *bool    `yaml:"save_partial_results,omitempty" json:"save_partial_results,omitempty"` // Write what a run stopped by Ctrl-C or --max-duration scraped (default true)
+}
+
+// CanonicalURLConfig sets how page URLs are canonicalized for the visited set:
+// strip_params are dropped from the query ("utm_*" matches a prefix; empty uses
+// the built-in tracking list) and <link rel="canonical"> is honored unless ignored
+type CanonicalURLConfig struct {
+	StripParams         []string `yaml:"strip_params,omitempty" json:"strip_params,omitempty"`
+	IgnoreLinkCanonical bool     `yaml:"ignore_link_canonical,omitempty" json:"ignore_link_canonical,omitempty"`
+}
+
+// MalformedHTMLConfig flags pages with more than max_unbalanced_tags unclosed or
+// stray tags (default 50), or that stop before </html>; retry re-fetches them
+type MalformedHTMLConfig struct {
+	MaxUnbalancedTags int  `yaml:"max_unbalanced_tags,omitempty" json:"max_unbalanced_tags,omitempty"`
+	Retry             bool `yaml:"retry,omitempty" json:"retry,omitempty"`
+}
+
+// CSVOptions shapes CSV output. Columns come first in the header in the order
+// given, with an empty cell where a record lacks one; columns not listed follow
+// alphabetically. Unlike output.columns, no field is dropped; setting both is
+// an error.
+type CSVOptions struct {
+	Delimiter string   `yaml:"delimiter,omitempty" json:"delimiter,omitempty"` // A single character, e.g. ";" or "\t" (default ",")
+	QuoteAll  bool     `yaml:"quote_all,omitempty" json:"quote_all,omitempty"` // Quote every field, not only those that need it
+	Columns   []string `yaml:"columns,omitempty" json:"columns,omitempty"`
+}
+
+// HostRateLimitConfig gives one host its own pace; every host already has a
+// separate token bucket, so this only changes the rate, not the sharing
+type HostRateLimitConfig struct {
+	RateLimit string `yaml:"rate_limit" json:"rate_limit"`                       // Least time between requests, e.g. "1s"
+	BurstSize int    `yaml:"burst_size,omitempty" json:"burst_size,omitempty"` // Requests allowed back to back (default: the global burst)
+}
+
+// RequestConfig is the method and body one of url_requests is fetched with.
+// A form body is a map of fields or an encoded string, a json body any value
+// (a string is sent as written) and a raw body a string.
+type RequestConfig struct {
+	Method   string      `yaml:"method,omitempty" json:"method,omitempty"`
+	Body     interface{} `yaml:"body,omitempty" json:"body,omitempty"`
+	BodyType string      `yaml:"body_type,omitempty" json:"body_type,omitempty"`
+}
+
+// ChangeDetectionConfig compares each record with the previous run's record
+// that had the same key_field value, by content hash kept in state_file, and
+// marks it under _change as new, modified or unchanged
+type ChangeDetectionConfig struct {
+	StateFile     string `yaml:"state_file" json:"state_file"`
+	KeyField      string `yaml:"key_field" json:"key_field"`
+	SkipUnchanged bool   `yaml:"skip_unchanged,omitempty" json:"skip_unchanged,omitempty"` // Leave unchanged records out of the output
+}
+
+// DeduplicationConfig drops the records of a run that repeat an earlier one.
+// With a checkpoint, what has been seen is saved with it, so a resumed run still
+// drops repeats of records scraped before the restart.
+type DeduplicationConfig struct {
+	Method              string   `yaml:"method,omitempty" json:"method,omitempty"`                             // hash (default), field, url or similarity
+	Fields              []string `yaml:"fields,omitempty" json:"fields,omitempty"`                             // Fields compared by the field, url and similarity methods
+	Threshold           float64  `yaml:"threshold,omitempty" json:"threshold,omitempty"`                       // Similarity score (0-1] at which records are duplicates
+	SimilarityAlgorithm string   `yaml:"similarity_algorithm,omitempty" json:"similarity_algorithm,omitempty"` // jaccard_exact (default), token_cosine or levenshtein
+	CacheSize           int      `yaml:"cache_size,omitempty" json:"cache_size,omitempty"`                     // Records remembered; 0 remembers all
+}
+
+// BlockAbortConfig drops a host for the rest of the run after repeated block responses (HTTP 403/429)
+type BlockAbortConfig struct {
+	Threshold int    `yaml:"threshold" json:"threshold"`               // Block responses that abandon the host
+	Window    string `yaml:"window,omitempty" json:"window,omitempty"` // Only count blocks this recent (e.g. "10m"); empty counts the whole run
+}
+
 // TLSConfig defines TLS/SSL configuration
 type TLSConfig struct {
 	// InsecureSkipVerify controls whether certificate verification is skipped.
@@ -119,6 +411,7 @@ type ProxyProvider struct {
 	Password string `yaml:"password,omitempty" json:"password,omitempty"`
 	Weight   int    `yaml:"weight,omitempty" json:"weight,omitempty"`
 	Enabled  bool   `yaml:"enabled" json:"enabled"`
+	Group    string `yaml:"group,omitempty" json:"group,omitempty"` // Group named by proxy.host_groups
 }
 
 // TransformRule represents a data transformation rule
@@ -146,29 +439,21 @@ type BrowserConfig struct {
 	DisableJS      bool   `yaml:"disable_js" json:"disable_js"`
 }
 
-// LoadFromFile loads configuration from a YAML file
+// LoadFromFile loads configuration from a YAML file, expanding ${VAR} and
+// ${VAR:-default} references from the environment
 func LoadFromFile(filename string) (*ScraperConfig, error) {
 	data, err := os.ReadFile(filename)
 	if err != nil {
 		return nil, fmt.Errorf("failed to read config file: %w", err)
 	}
 
-	var config ScraperConfig
-	if err := yaml.Unmarshal(data, &config); err != nil {
-		return nil, fmt.Errorf("failed to parse YAML: %w", err)
-	}
-
-	return &config, nil
+	return parseConfig(data)
 }
 
-// LoadFromBytes loads configuration from YAML bytes
+// LoadFromBytes loads configuration from YAML bytes, expanding ${VAR} and
+// ${VAR:-default} references from the environment
 func LoadFromBytes(data []byte) (*ScraperConfig, error) {
-	var config ScraperConfig
-	if err := yaml.Unmarshal(data, &config); err != nil {
-		return nil, fmt.Errorf("failed to parse YAML: %w", err)
-	}
-
-	return &config, nil
+	return parseConfig(data)
 }
 
 // SimpleValidate provides basic validation (kept for backward compatibility)
@@ -190,16 +475,27 @@ func (c *ScraperConfig) SimpleValidate() error {
 		if field.Name == "" {
 			return fmt.Errorf("field %d: name is required", i)
 		}
+		if field.Validate != nil && field.Validate.Pattern != "" {
+			if _, err := regexp.Compile(field.Validate.Pattern); err != nil {
+				return fmt.Errorf("field %d: invalid validate pattern: %w", i, err)
+			}
+		}
+		if len(field.FieldSources()) > 0 {
+			continue
+		}
 		if field.Selector == "" {
 			return fmt.Errorf("field %d: selector is required", i)
 		}
 		if field.Type == "" {
 			return fmt.Errorf("field %d: type is required", i)
 		}
+		if field.SelectorType != "" && field.SelectorType != "css" && field.SelectorType != "xpath" {
+			return fmt.Errorf("field %d: invalid selector_type %s", i, field.SelectorType)
+		}
 
 		// Validate field types
 		validTypes := map[string]bool{
-			"text": true, "html": true, "attr": true, "list": true,
+			"text": true, "html": true, "attr": true, "list": true, "header": true, "embedded_json": true, "table": true, "jsonld": true, "microdata": true,
 		}
 		if !validTypes[field.Type] {
 			return fmt.Errorf("field %d: invalid type %s", i, field.Type)
@@ -212,19 +508,27 @@ func (c *ScraperConfig) SimpleValidate() error {
 	}
 
 	// Validate output
-	if c.Output.Format == "" {
-		c.Output.Format = "json" // Default format
-	}
-
 	validFormats := map[string]bool{
-		"json": true, "csv": true, "yaml": true,
+		"json": true, "jsonl": true, "jsonlines": true, "csv": true, "yaml": true, "parquet": true, "xlsx": true,
 	}
-	if !validFormats[c.Output.Format] {
-		return fmt.Errorf("invalid output format: %s", c.Output.Format)
+	if len(c.Output.Outputs) == 0 {
+		if c.Output.Format == "" {
+			c.Output.Format = "json" // Default format
+		}
+		if !validFormats[c.Output.Format] {
+			return fmt.Errorf("invalid output format: %s", c.Output.Format)
+		}
+		if c.Output.File == "" {
+			c.Output.File = "output" + formatExtension(c.Output.Format) // Default filename
+		}
 	}
-
-	if c.Output.File == "" {
-		c.Output.File = "output." + c.Output.Format // Default filename
+	for i, sink := range c.Output.Outputs {
+		if !validFormats[sink.Format] {
+			return fmt.Errorf("output %d: invalid output format: %s", i, sink.Format)
+		}
+		if sink.File == "" {
+			return fmt.Errorf("output %d: file is required", i)
+		}
 	}
 
 	// Validate error threshold configuration
@@ -238,6 +542,16 @@ func (c *ScraperConfig) SimpleValidate() error {
 	return nil
 }
 
+// formatExtension returns the file extension of the default output file for format
+func formatExtension(format string) string {
+	switch format {
+	case "jsonl", "jsonlines":
+		return ".jsonl"
+	default:
+		return "." + format
+	}
+}
+
 // ConfigCache provides thread-safe configuration caching with efficient LRU eviction
 type ConfigCache struct {
 	cache         map[string]*CachedConfig
@@ -809,6 +1123,97 @@ func GenerateTemplate(templateType string) *ScraperConfig {
 			},
 			RateLimit: "3s",
 		}
+	case "forum":
+		return &ScraperConfig{
+			Name:    "forum_scraper",
+			BaseURL: "https://example-forum.com/threads",
+			Fields: []Field{
+				{
+					Name:     "thread_title",
+					Selector: "h1, .thread-title",
+					Type:     "text",
+					Required: true,
+				},
+				{
+					Name:     "author",
+					Selector: ".post .author, .username",
+					Type:     "text",
+					Required: false,
+				},
+				{
+					Name:     "post_body",
+					Selector: ".post-body, .message-content",
+					Type:     "text",
+					Required: true,
+				},
+				{
+					Name:      "timestamp",
+					Selector:  ".post time, time",
+					Type:      "attr",
+					Attribute: "datetime",
+					Required:  false,
+				},
+				{
+					Name:     "reply_count",
+					Selector: ".reply-count, .replies",
+					Type:     "text",
+					Required: false,
+				},
+			},
+			Output: OutputConfig{
+				Format: "json",
+				File:   "threads.json",
+			},
+			RateLimit: "3s",
+		}
+	case "realestate":
+		return &ScraperConfig{
+			Name:    "realestate_scraper",
+			BaseURL: "https://example-realty.com/listings",
+			Fields: []Field{
+				{
+					Name:     "address",
+					Selector: ".listing-address, address",
+					Type:     "text",
+					Required: true,
+				},
+				{
+					Name:     "price",
+					Selector: ".listing-price, .price",
+					Type:     "text",
+					Required: true,
+				},
+				{
+					Name:     "beds",
+					Selector: ".beds, .bedrooms",
+					Type:     "text",
+					Required: false,
+				},
+				{
+					Name:     "baths",
+					Selector: ".baths, .bathrooms",
+					Type:     "text",
+					Required: false,
+				},
+				{
+					Name:     "sqft",
+					Selector: ".sqft, .square-feet",
+					Type:     "text",
+					Required: false,
+				},
+				{
+					Name:     "listing_agent",
+					Selector: ".listing-agent, .agent-name",
+					Type:     "text",
+					Required: false,
+				},
+			},
+			Output: OutputConfig{
+				Format: "json",
+				File:   "listings.json",
+			},
+			RateLimit: "3s",
+		}
 	default: // basic
 		return &ScraperConfig{
 			Name:    "basic_scraper",
@@ -1342,7 +1747,7 @@ func NewConfigBuilder() *ConfigBuilder {
 	return &ConfigBuilder{
 		config: &ScraperConfig{
 			Fields:  make([]Field, 0),
-			Headers: make(map[string]string),
+			Headers: make(map[string]HeaderValues),
 			Cookies: make(map[string]string),
 		},
 	}
@@ -1401,7 +1806,7 @@ func (cb *ConfigBuilder) WithMaxRetries(retries int) *ConfigBuilder {
 
 // WithHeader adds a header
 func (cb *ConfigBuilder) WithHeader(key, value string) *ConfigBuilder {
-	cb.config.Headers[key] = value
+	cb.config.Headers[key] = append(cb.config.Headers[key], value)
 	return cb
 }
 
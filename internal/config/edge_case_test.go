@@ -264,6 +264,8 @@ func TestGenerateTemplateEdgeCases(t *testing.T) {
 		{"basic type", "basic", false},
 		{"ecommerce type", "ecommerce", false},
 		{"news type", "news", false},
+		{"forum type", "forum", false},
+		{"realestate type", "realestate", false},
 	}
 
 	for _, tt := range tests {
@@ -502,8 +504,7 @@ output:
   format: json
   file: output.json
 `,
-			expectedVal: "${UNDEFINED_VAR}", // Environment variables not expanded by YAML parser
-			expectError: false,
+			expectError: true, // No default to fall back on
 		},
 		{
 			name: "empty environment variable",
@@ -521,7 +522,7 @@ output:
   format: json
   file: output.json
 `,
-			expectedVal: "${EMPTY_VAR}", // Environment variables not expanded by YAML parser
+			expectedVal: "", // Set but empty expands to the empty string
 			expectError: false,
 		},
 		{
@@ -540,7 +541,7 @@ output:
   format: json
   file: output.json
 `,
-			expectedVal: "${SPECIAL_VAR}", // Environment variables not expanded by YAML parser
+			expectedVal: "https://example.com/path?param=value&other=123#fragment",
 			expectError: false,
 		},
 		{
@@ -561,7 +562,7 @@ output:
   format: json
   file: output.json
 `,
-			expectedVal: "${PROTOCOL}://${HOST}:${PORT}", // Environment variables not expanded by YAML parser
+			expectedVal: "https://example.com:8080",
 			expectError: false,
 		},
 	}
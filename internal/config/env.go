@@ -0,0 +1,90 @@
+// internal/config/env.go
+package config
+
+import (
+	"fmt"
+	"os"
+	"regexp"
+	"strings"
+
+	"gopkg.in/yaml.v3"
+)
+
+// envReferenceRegex matches $$, ${NAME} and ${NAME:-default}
+var envReferenceRegex = regexp.MustCompile(`\$\$|\$\{([A-Za-z_][A-Za-z0-9_]*)(?::-([^}]*))?\}`)
+
+// expandEnv replaces ${NAME} with the value of environment variable NAME and
+// ${NAME:-default} with that value, or default when NAME is unset or empty.
+// $$ stands for a literal $; any other $ is left as written, so regex anchors
+// and replacement groups like $1 pass through. An unset NAME with no default
+// is an error.
+func expandEnv(s string, lookup func(string) (string, bool)) (string, error) {
+	if !strings.Contains(s, "$") {
+		return s, nil
+	}
+
+	var missing []string
+	expanded := envReferenceRegex.ReplaceAllStringFunc(s, func(ref string) string {
+		if ref == "$$" {
+			return "$"
+		}
+		match := envReferenceRegex.FindStringSubmatch(ref)
+		name, hasDefault := match[1], strings.Contains(ref, ":-")
+		if value, ok := lookup(name); ok && (value != "" || !hasDefault) {
+			return value
+		}
+		if hasDefault {
+			return match[2]
+		}
+		missing = append(missing, name)
+		return ref
+	})
+	if len(missing) > 0 {
+		return "", fmt.Errorf("environment variable %s is not set", strings.Join(missing, ", "))
+	}
+	return expanded, nil
+}
+
+// expandEnvNode expands environment references in every scalar under node.
+// Expanding parsed scalars rather than raw bytes keeps values containing YAML
+// syntax (a password with ": " or "#") from changing the document's structure,
+// and leaves comments alone. Plain scalars are re-typed after expansion, so
+// max_pages: ${MAX_PAGES} still decodes as a number.
+func expandEnvNode(node *yaml.Node, lookup func(string) (string, bool)) error {
+	if node.Kind == yaml.ScalarNode {
+		expanded, err := expandEnv(node.Value, lookup)
+		if err != nil {
+			return fmt.Errorf("line %d: %w", node.Line, err)
+		}
+		if expanded != node.Value {
+			node.Value = expanded
+			if node.Style&(yaml.TaggedStyle|yaml.SingleQuotedStyle|yaml.DoubleQuotedStyle|yaml.LiteralStyle|yaml.FoldedStyle) == 0 {
+				node.Tag = "" // resolved again from the new value
+			}
+		}
+		return nil
+	}
+	for _, child := range node.Content {
+		if err := expandEnvNode(child, lookup); err != nil {
+			return err
+		}
+	}
+	return nil
+}
+
+// parseConfig decodes YAML into a ScraperConfig, expanding environment references
+func parseConfig(data []byte) (*ScraperConfig, error) {
+	var root yaml.Node
+	if err := yaml.Unmarshal(data, &root); err != nil {
+		return nil, fmt.Errorf("failed to parse YAML: %w", err)
+	}
+	if err := expandEnvNode(&root, os.LookupEnv); err != nil {
+		return nil, fmt.Errorf("configuration error: %w", err)
+	}
+
+	var config ScraperConfig
+	if err := root.Decode(&config); err != nil {
+		return nil, fmt.Errorf("failed to parse YAML: %w", err)
+	}
+	return &config, nil
+}
@@ -0,0 +1,68 @@
+// internal/config/headers.go
+package config
+
+import (
+	"encoding/json"
+	"fmt"
+	"strings"
+
+	"gopkg.in/yaml.v3"
+)
+
+// HeaderValues holds the values of one request header. In YAML and JSON it is
+// either a single string or a list; each list entry is sent as its own header line.
+type HeaderValues []string
+
+// UnmarshalYAML accepts a scalar or a sequence of scalars
+func (h *HeaderValues) UnmarshalYAML(node *yaml.Node) error {
+	switch node.Kind {
+	case yaml.ScalarNode:
+		*h = HeaderValues{node.Value}
+		return nil
+	case yaml.SequenceNode:
+		var values []string
+		if err := node.Decode(&values); err != nil {
+			return err
+		}
+		*h = values
+		return nil
+	default:
+		return fmt.Errorf("line %d: header value must be a string or a list of strings", node.Line)
+	}
+}
+
+// MarshalYAML writes a single value as a plain string so round-tripped configs stay unchanged
+func (h HeaderValues) MarshalYAML() (interface{}, error) {
+	if len(h) == 1 {
+		return h[0], nil
+	}
+	return []string(h), nil
+}
+
+// UnmarshalJSON accepts a string or an array of strings
+func (h *HeaderValues) UnmarshalJSON(data []byte) error {
+	var single string
+	if err := json.Unmarshal(data, &single); err == nil {
+		*h = HeaderValues{single}
+		return nil
+	}
+	var values []string
+	if err := json.Unmarshal(data, &values); err != nil {
+		return fmt.Errorf("header value must be a string or a list of strings")
+	}
+	*h = values
+	return nil
+}
+
+// MarshalJSON writes a single value as a plain string
+func (h HeaderValues) MarshalJSON() ([]byte, error) {
+	if len(h) == 1 {
+		return json.Marshal(h[0])
+	}
+	return json.Marshal([]string(h))
+}
+
+// String joins the values the way a single header line would carry them
+func (h HeaderValues) String() string {
+	return strings.Join(h, ", ")
+}
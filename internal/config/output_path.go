@@ -0,0 +1,127 @@
+// internal/config/output_path.go
+package config
+
+import (
+	"fmt"
+	"net/url"
+	"regexp"
+	"strings"
+	"time"
+)
+
+// Default layouts for date placeholders in output.file
+const (
+	DefaultOutputDateLayout      = "2006-01-02"
+	DefaultOutputTimestampLayout = "20060102-150405"
+)
+
+// outputPlaceholderRegex matches {name} or {name:argument} in output.file
+var outputPlaceholderRegex = regexp.MustCompile(`\{(\w+)(?::([^{}]*))?\}`)
+
+// pathUnsafeReplacer keeps substituted values inside a single path segment
+var pathUnsafeReplacer = strings.NewReplacer("/", "_", "\\", "_", ":", "_")
+
+// PartitionPlaceholder marks where output.partition_by values go in output.file
+const PartitionPlaceholder = "{partition}"
+
+// DefaultPartition names the bucket for records that lack the partition_by field
+const DefaultPartition = "unpartitioned"
+
+// OutputPathVars holds the run-time values substituted into output.file
+type OutputPathVars struct {
+	Name string    // {name}: the scraper config name
+	Host string    // {host}: host of the target URL
+	Time time.Time // {date[:layout]} and {timestamp[:layout]}: run start time
+}
+
+// OutputPathVars returns the placeholder values for a run of this config starting at now
+func (c *ScraperConfig) OutputPathVars(now time.Time) OutputPathVars {
+	vars := OutputPathVars{Name: c.Name, Time: now}
+	if u, err := url.Parse(c.BaseURL); err == nil {
+		vars.Host = u.Host
+	}
+	return vars
+}
+
+// ExpandOutputPath replaces {name}, {host}, {date[:layout]} and {timestamp[:layout]}
+// in file. Layouts use Go time format; unknown placeholders are left unchanged.
+// {name} and {host} are kept to one path segment; a layout that formats to a
+// ".." segment is an error, so no placeholder can lead out of a directory.
+func ExpandOutputPath(file string, vars OutputPathVars) (string, error) {
+	var err error
+	expanded := outputPlaceholderRegex.ReplaceAllStringFunc(file, func(placeholder string) string {
+		match := outputPlaceholderRegex.FindStringSubmatch(placeholder)
+		name, layout := match[1], match[2]
+
+		switch name {
+		case "name":
+			return pathSegment(vars.Name)
+		case "host":
+			return pathSegment(vars.Host)
+		case "date", "timestamp":
+			value := formatPathTime(name, layout, vars.Time)
+			if hasParentSegment(value) && err == nil {
+				err = fmt.Errorf("placeholder %s expands to a parent directory reference", placeholder)
+			}
+			return value
+		default:
+			return placeholder
+		}
+	})
+	if err != nil {
+		return "", err
+	}
+	return expanded, nil
+}
+
+// formatPathTime formats t for the {date} or {timestamp} placeholder, using its default layout when layout is empty
+func formatPathTime(name, layout string, t time.Time) string {
+	if layout == "" {
+		layout = DefaultOutputDateLayout
+		if name == "timestamp" {
+			layout = DefaultOutputTimestampLayout
+		}
+	}
+	return t.Format(layout)
+}
+
+// ExpandPartitionPath substitutes a partition value for {partition} in file. The
+// value is kept to one path segment; empty, "." and ".." become "_".
+func ExpandPartitionPath(file, value string) string {
+	return strings.ReplaceAll(file, PartitionPlaceholder, pathSegment(value))
+}
+
+// pathSegment makes value safe to use as a single path segment
+func pathSegment(value string) string {
+	value = pathUnsafeReplacer.Replace(value)
+	if value == "" || value == "." || value == ".." {
+		value = "_"
+	}
+	return value
+}
+
+// hasParentSegment reports whether path has a ".." segment, with either separator
+func hasParentSegment(path string) bool {
+	for _, segment := range strings.FieldsFunc(path, func(r rune) bool { return r == '/' || r == '\\' }) {
+		if segment == ".." {
+			return true
+		}
+	}
+	return false
+}
+
+// validateOutputPlaceholders reports placeholders in file that ExpandOutputPath does not know
+func validateOutputPlaceholders(file string) error {
+	for _, match := range outputPlaceholderRegex.FindAllStringSubmatch(file, -1) {
+		switch match[1] {
+		case "date", "timestamp":
+			if hasParentSegment(formatPathTime(match[1], match[2], time.Now())) {
+				return fmt.Errorf("placeholder %s expands to a parent directory reference", match[0])
+			}
+		case "name", "host", "partition":
+		default:
+			return fmt.Errorf("unknown placeholder {%s}; valid placeholders: {name}, {host}, {date}, {timestamp}, {partition}", match[1])
+		}
+	}
+	return nil
+}
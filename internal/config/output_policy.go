@@ -0,0 +1,224 @@
+// internal/config/output_policy.go
+package config
+
+import (
+	"errors"
+	"fmt"
+	"io/fs"
+	"os"
+	"path/filepath"
+	"strings"
+)
+
+// DefaultOutputFormat is the format used when output.format is empty
+const DefaultOutputFormat = "json"
+
+// MetricsFileSuffix is appended to an output file to name its metrics summary
+const MetricsFileSuffix = ".metrics.json"
+
+// OutputPolicy restricts where scraper configs may send their output. It is set
+// programmatically by the embedding application (e.g. a multi-tenant server) and
+// is never read from a scraper config, so tenants cannot widen it.
+type OutputPolicy struct {
+	// BaseDir confines every file and directory a run writes to this directory
+	// tree: output files, metrics summaries, the checkpoint, the cookie jar, the
+	// change detection state, debug directories and the browser profile.
+	// Relative paths are resolved against it. Empty means no path restriction.
+	BaseDir string
+
+	// AllowedFormats lists the output formats (destination types) that may be
+	// used. Empty means every format accepted by regular validation is allowed.
+	AllowedFormats []string
+}
+
+// policyDestination is a path in the config that the policy confines
+type policyDestination struct {
+	field   string
+	path    *string
+	rewrite bool // Replace the configured path with the resolved one
+	sink    bool // An output file, which no other output may resolve to
+}
+
+// ValidateWithPolicy runs the regular validation and additionally rejects
+// output destinations not permitted by policy. A nil policy behaves like Validate.
+//
+// When the config passes, every destination is rewritten to the absolute,
+// symlink-free path that was checked, so no writer resolves it against the
+// working directory instead. Output placeholders must already be expanded;
+// {partition} is checked as a single path segment.
+func (sc *ScraperConfig) ValidateWithPolicy(policy *OutputPolicy) error {
+	result := sc.ValidateWithDetails()
+
+	var resolved map[*string]string
+	if policy != nil {
+		resolved = policy.validate(sc, result)
+	}
+
+	if len(result.Errors) > 0 {
+		return sc.formatValidationError(result)
+	}
+
+	for path, target := range resolved {
+		*path = target
+	}
+	return nil
+}
+
+// ResolveOutputPath returns the absolute path file is written to under policy,
+// with symlinks in its existing part evaluated. It fails when that path is
+// outside BaseDir.
+func (p *OutputPolicy) ResolveOutputPath(file string) (string, error) {
+	if p == nil || p.BaseDir == "" {
+		return file, nil
+	}
+
+	base, err := filepath.Abs(p.BaseDir)
+	if err != nil {
+		return "", fmt.Errorf("invalid output base directory: %w", err)
+	}
+	if base, err = evalExistingSymlinks(base); err != nil {
+		return "", fmt.Errorf("invalid output base directory: %w", err)
+	}
+
+	target := file
+	if !filepath.IsAbs(target) {
+		target = filepath.Join(base, target)
+	}
+	if target, err = evalExistingSymlinks(filepath.Clean(target)); err != nil {
+		return "", fmt.Errorf("failed to resolve output path %s: %w", file, err)
+	}
+
+	rel, err := filepath.Rel(base, target)
+	if err != nil || rel == ".." || strings.HasPrefix(rel, ".."+string(filepath.Separator)) {
+		return "", fmt.Errorf("output path %s is outside allowed directory %s", file, base)
+	}
+
+	return target, nil
+}
+
+// evalExistingSymlinks evaluates the symlinks in the longest existing prefix of
+// the clean absolute path and joins the rest of path, which does not exist yet,
+// to the result. A dangling symlink is an error, since writing through it
+// would create its target.
+func evalExistingSymlinks(path string) (string, error) {
+	existing, rest := path, ""
+	for {
+		resolved, err := filepath.EvalSymlinks(existing)
+		if err == nil {
+			return filepath.Join(resolved, rest), nil
+		}
+		if !errors.Is(err, fs.ErrNotExist) {
+			return "", err
+		}
+		if _, lerr := os.Lstat(existing); lerr == nil {
+			return "", fmt.Errorf("%s is a symlink to a missing target", existing)
+		}
+		parent := filepath.Dir(existing)
+		if parent == existing {
+			return path, nil
+		}
+		rest = filepath.Join(filepath.Base(existing), rest)
+		existing = parent
+	}
+}
+
+// validate appends policy violations for sc to result and returns the resolved
+// path of each destination to rewrite
+func (p *OutputPolicy) validate(sc *ScraperConfig, result *ValidationResult) map[*string]string {
+	sinks, sinkPrefix := []*OutputConfig{&sc.Output}, func(int) string { return "output" }
+	if len(sc.Output.Outputs) > 0 {
+		sinks = sinks[:0]
+		for i := range sc.Output.Outputs {
+			sinks = append(sinks, &sc.Output.Outputs[i])
+		}
+		sinkPrefix = func(i int) string { return fmt.Sprintf("output.outputs[%d]", i) }
+	}
+
+	var destinations []policyDestination
+	for i, sink := range sinks {
+		prefix := sinkPrefix(i)
+		// An empty format falls back to the default writer, which must be allowed too
+		format := sink.Format
+		if format == "" {
+			format = DefaultOutputFormat
+		}
+		if len(p.AllowedFormats) > 0 && !contains(p.AllowedFormats, format) {
+			result.Errors = append(result.Errors, ValidationError{
+				Field:   prefix + ".format",
+				Value:   format,
+				Message: fmt.Sprintf("Output format not permitted. Allowed formats: %s", strings.Join(p.AllowedFormats, ", ")),
+			})
+		}
+
+		destinations = append(destinations, policyDestination{prefix + ".file", &sink.File, true, true})
+		if sink.EnableMetrics && sink.File != "" {
+			metricsFile := sink.File + MetricsFileSuffix
+			destinations = append(destinations, policyDestination{prefix + ".enable_metrics", &metricsFile, false, false})
+		}
+	}
+
+	destinations = append(destinations,
+		policyDestination{"checkpoint", &sc.Checkpoint, true, false},
+		policyDestination{"cookie_jar_file", &sc.CookieJarFile, true, false},
+	)
+	if sc.ChangeDetection != nil {
+		destinations = append(destinations, policyDestination{"change_detection.state_file", &sc.ChangeDetection.StateFile, true, false})
+	}
+	if sc.Debug != nil {
+		destinations = append(destinations,
+			policyDestination{"debug.save_failed_bodies", &sc.Debug.SaveFailedBodies, true, false},
+			policyDestination{"debug.record_session", &sc.Debug.RecordSession, true, false},
+			policyDestination{"debug.replay_session", &sc.Debug.ReplaySession, true, false},
+		)
+	}
+	if sc.Browser != nil {
+		destinations = append(destinations, policyDestination{"browser.user_data_dir", &sc.Browser.UserDataDir, true, false})
+	}
+
+	resolved := make(map[*string]string)
+	sinkFiles := make(map[string]bool)
+	for _, dest := range destinations {
+		if *dest.path == "" {
+			continue
+		}
+		target, err := p.ResolveOutputPath(*dest.path)
+		if err != nil {
+			result.Errors = append(result.Errors, ValidationError{
+				Field:   dest.field,
+				Value:   *dest.path,
+				Message: fmt.Sprintf("Output destination not permitted: %s", err.Error()),
+			})
+			continue
+		}
+		// Outputs checked against their resolved paths can still meet through a symlink
+		if dest.sink {
+			if sinkFiles[target] {
+				result.Errors = append(result.Errors, ValidationError{
+					Field:   dest.field,
+					Value:   *dest.path,
+					Message: fmt.Sprintf("Each output must write to a different file; %s is already written", target),
+				})
+			}
+			sinkFiles[target] = true
+		}
+		if dest.rewrite {
+			resolved[dest.path] = target
+		}
+	}
+	return resolved
+}
+
+// EnsureOutputDir creates the directory file is written in when it does not
+// exist. Under a policy the directory is checked again afterwards, so a symlink
+// created in the meantime cannot lead the write outside BaseDir.
+func (p *OutputPolicy) EnsureOutputDir(file string) error {
+	dir := filepath.Dir(file)
+	if dir == "." {
+		return nil
+	}
+	if err := os.MkdirAll(dir, 0755); err != nil {
+		return err
+	}
+	_, err := p.ResolveOutputPath(file)
+	return err
+}
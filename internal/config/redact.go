@@ -0,0 +1,198 @@
+// internal/config/redact.go
+package config
+
+import (
+	"fmt"
+	"net/http"
+	"net/url"
+	"regexp"
+	"strings"
+
+	"gopkg.in/yaml.v3"
+)
+
+// RedactedValue replaces secrets in redacted configurations
+const RedactedValue = "[REDACTED]"
+
+// sensitiveHeaderMarkers identify header names whose values are treated as secrets
+var sensitiveHeaderMarkers = []string{"authorization", "cookie", "token", "secret", "api-key", "apikey", "password"}
+
+// jsonStringField matches a "key": value pair in JSON text, the value being a
+// string or a bare token such as a number or ${page}
+var jsonStringField = regexp.MustCompile(`"((?:[^"\\]|\\.)*)"(\s*:\s*)("(?:[^"\\]|\\.)*"|[^\s,{}\[\]"]+)`)
+
+// Redacted returns a deep copy of the configuration with credentials, cookies,
+// sensitive header values and the credential fields of form and JSON request
+// bodies replaced by RedactedValue and passwords in URLs masked, safe for
+// printing or logging
+func (sc *ScraperConfig) Redacted() (*ScraperConfig, error) {
+	data, err := yaml.Marshal(sc)
+	if err != nil {
+		return nil, fmt.Errorf("failed to copy configuration: %w", err)
+	}
+
+	var redacted ScraperConfig
+	if err := yaml.Unmarshal(data, &redacted); err != nil {
+		return nil, fmt.Errorf("failed to copy configuration: %w", err)
+	}
+
+	// Target URLs may carry credentials as user info, like proxy URLs
+	redacted.BaseURL = redactURLPassword(redacted.BaseURL)
+	for _, urls := range [][]string{redacted.URLs, redacted.WarmupURLs} {
+		for i := range urls {
+			urls[i] = redactURLPassword(urls[i])
+		}
+	}
+	if redacted.Pagination != nil {
+		redacted.Pagination.SitemapURL = redactURLPassword(redacted.Pagination.SitemapURL)
+	}
+
+	for name := range redacted.Headers {
+		if IsSensitiveHeader(name) {
+			redacted.Headers[name] = HeaderValues{RedactedValue}
+		}
+	}
+	for name := range redacted.Cookies {
+		redacted.Cookies[name] = RedactedValue
+	}
+
+	// Login forms and API bodies carry credentials under the same names as headers
+	redacted.Body = redactBody(redacted.Body, redacted.BodyType)
+	for target, request := range redacted.URLRequests {
+		request.Body = redactBody(request.Body, request.BodyType)
+		redacted.URLRequests[target] = request
+	}
+
+	if p := redacted.Proxy; p != nil {
+		if p.Password != "" {
+			p.Password = RedactedValue
+		}
+		p.URL = redactURLPassword(p.URL)
+		p.HealthCheckURL = redactURLPassword(p.HealthCheckURL)
+		for i := range p.Providers {
+			if p.Providers[i].Password != "" {
+				p.Providers[i].Password = RedactedValue
+			}
+		}
+	}
+
+	return &redacted, nil
+}
+
+// IsSensitiveHeader reports whether a header value should be hidden
+func IsSensitiveHeader(name string) bool {
+	lower := strings.ToLower(name)
+	for _, marker := range sensitiveHeaderMarkers {
+		if strings.Contains(lower, marker) {
+			return true
+		}
+	}
+	return false
+}
+
+// isSensitiveField reports whether a form or JSON body field holds a secret,
+// by the header rules with _ read as -, so api_key matches like Api-Key
+func isSensitiveField(name string) bool {
+	return IsSensitiveHeader(strings.ReplaceAll(name, "_", "-"))
+}
+
+// redactBody hides the values of sensitive fields in a request body of
+// bodyType, defaulting as validateRequest does. Raw bodies are left as written.
+func redactBody(body interface{}, bodyType string) interface{} {
+	text, isString := body.(string)
+	switch strings.ToLower(bodyType) {
+	case "form":
+		if isString {
+			return redactFormText(text)
+		}
+		return redactFields(body)
+	case "json", "":
+		if !isString {
+			return redactFields(body)
+		}
+		if bodyType == "" {
+			return body // A string body defaults to raw
+		}
+		// The text may not parse until ${page} is filled in, so fields are
+		// replaced in place rather than decoded
+		return jsonStringField.ReplaceAllStringFunc(text, func(field string) string {
+			match := jsonStringField.FindStringSubmatch(field)
+			if !isSensitiveField(match[1]) {
+				return field
+			}
+			return `"` + match[1] + `"` + match[2] + `"` + RedactedValue + `"`
+		})
+	default:
+		return body
+	}
+}
+
+// redactFields replaces the values of sensitive keys in maps nested anywhere in value
+func redactFields(value interface{}) interface{} {
+	switch v := value.(type) {
+	case map[string]interface{}:
+		for key, field := range v {
+			if isSensitiveField(key) {
+				v[key] = RedactedValue
+			} else {
+				v[key] = redactFields(field)
+			}
+		}
+	case map[interface{}]interface{}:
+		for key, field := range v {
+			if name, ok := key.(string); ok && isSensitiveField(name) {
+				v[key] = RedactedValue
+			} else {
+				v[key] = redactFields(field)
+			}
+		}
+	case []interface{}:
+		for i := range v {
+			v[i] = redactFields(v[i])
+		}
+	}
+	return value
+}
+
+// redactFormText hides sensitive fields of an encoded form body, keeping the
+// rest as written so placeholders such as ${page} survive
+func redactFormText(text string) string {
+	pairs := strings.Split(text, "&")
+	for i, pair := range pairs {
+		key, _, found := strings.Cut(pair, "=")
+		name, err := url.QueryUnescape(key)
+		if err != nil {
+			name = key
+		}
+		if found && isSensitiveField(name) {
+			pairs[i] = key + "=" + url.QueryEscape(RedactedValue)
+		}
+	}
+	return strings.Join(pairs, "&")
+}
+
+// RedactedHeaders returns a copy of header with the values of sensitive
+// headers, such as Cookie and Proxy-Authorization, replaced by RedactedValue
+func RedactedHeaders(header http.Header) http.Header {
+	redacted := header.Clone()
+	for name, values := range redacted {
+		if IsSensitiveHeader(name) {
+			for i := range values {
+				values[i] = RedactedValue
+			}
+		}
+	}
+	return redacted
+}
+
+// redactURLPassword hides the password in a URL's user info, if any
+func redactURLPassword(raw string) string {
+	if raw == "" {
+		return raw
+	}
+	u, err := url.Parse(raw)
+	if err != nil {
+		return raw
+	}
+	return u.Redacted()
+}
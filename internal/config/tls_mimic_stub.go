@@ -0,0 +1,8 @@
+//go:build !utls
+
+// internal/config/tls_mimic_stub.go
+package config
+
+// tlsMimicSupported reports whether this binary can honor tls.mimic; it needs
+// a build with -tags utls
+const tlsMimicSupported = false
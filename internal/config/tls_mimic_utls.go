@@ -0,0 +1,7 @@
+//go:build utls
+
+// internal/config/tls_mimic_utls.go
+package config
+
+// tlsMimicSupported reports whether this binary can honor tls.mimic
+const tlsMimicSupported = true
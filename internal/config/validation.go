@@ -2,13 +2,23 @@
 package config
 
 import (
+	"encoding/json"
 	"fmt"
 	"net/url"
+	"path/filepath"
 	"regexp"
+	"slices"
 	"strings"
 	"time"
+	"unicode/utf8"
+
+	"github.com/antchfx/xpath"
+	"github.com/valpere/DataScrapexter/internal/pipeline"
 )
 
+// headerNameRegex matches a valid HTTP header field name (RFC 7230 token)
+var headerNameRegex = regexp.MustCompile("^[!#$%&'*+.^_`|~0-9A-Za-z-]+$")
+
 // ValidationError represents a detailed validation error
 type ValidationError struct {
 	Field   string `json:"field"`
@@ -37,6 +47,8 @@ func (sc *ScraperConfig) Validate() error {
 
 	// Validate URL
 	sc.validateURL(result)
+	sc.validateWarmupURLs(result)
+	sc.validatePagination(result)
 
 	// Validate fields configuration
 	sc.validateFields(result)
@@ -46,6 +58,7 @@ func (sc *ScraperConfig) Validate() error {
 
 	// Validate engine settings
 	sc.validateEngineSettings(result)
+	sc.validateProxy(result)
 
 	if len(result.Errors) > 0 {
 		return sc.formatValidationError(result)
@@ -120,6 +133,82 @@ func (sc *ScraperConfig) validateURL(result *ValidationResult) {
 	}
 }
 
+// validateWarmupURLs checks that each warmup URL is an absolute http(s) URL
+func (sc *ScraperConfig) validateWarmupURLs(result *ValidationResult) {
+	for i, warmupURL := range sc.WarmupURLs {
+		parsedURL, err := url.Parse(warmupURL)
+		if err != nil || (parsedURL.Scheme != "http" && parsedURL.Scheme != "https") || parsedURL.Host == "" {
+			result.Errors = append(result.Errors, ValidationError{
+				Field:   fmt.Sprintf("warmup_urls[%d]", i),
+				Value:   warmupURL,
+				Message: "Warmup URL must be an absolute http:// or https:// URL",
+			})
+		}
+	}
+}
+
+// validatePagination checks that sitemap pagination names an absolute http(s)
+// sitemap URL, that infinite_scroll pagination has the browser and an item
+// selector, and that cursor pagination has a cursor selector
+func (sc *ScraperConfig) validatePagination(result *ValidationResult) {
+	p := sc.Pagination
+	if p == nil {
+		return
+	}
+	switch p.Type {
+	case "sitemap":
+		parsedURL, err := url.Parse(p.SitemapURL)
+		if err != nil || (parsedURL.Scheme != "http" && parsedURL.Scheme != "https") || parsedURL.Host == "" {
+			result.Errors = append(result.Errors, ValidationError{
+				Field:   "pagination.sitemap_url",
+				Value:   p.SitemapURL,
+				Message: "Sitemap pagination requires an absolute http:// or https:// sitemap URL",
+			})
+		}
+	case "infinite_scroll":
+		if sc.Browser == nil || !sc.Browser.Enabled {
+			result.Errors = append(result.Errors, ValidationError{
+				Field:   "pagination.type",
+				Value:   p.Type,
+				Message: "Infinite scroll pagination requires browser.enabled",
+			})
+		}
+		if p.ItemSelector == "" {
+			result.Errors = append(result.Errors, ValidationError{
+				Field:   "pagination.item_selector",
+				Value:   "",
+				Message: "Infinite scroll pagination requires an item selector",
+			})
+		}
+		if p.ScrollPause != "" {
+			if pause, err := time.ParseDuration(p.ScrollPause); err != nil || pause <= 0 {
+				result.Errors = append(result.Errors, ValidationError{
+					Field:   "pagination.scroll_pause",
+					Value:   p.ScrollPause,
+					Message: "scroll_pause must be a positive duration (e.g., '500ms', '2s')",
+				})
+			}
+		}
+	case "cursor":
+		if p.CursorSelector == "" {
+			result.Errors = append(result.Errors, ValidationError{
+				Field:   "pagination.cursor_selector",
+				Value:   "",
+				Message: "Cursor pagination requires a cursor selector",
+			})
+		}
+	default:
+		return
+	}
+	if p.MaxPages < 0 {
+		result.Errors = append(result.Errors, ValidationError{
+			Field:   "pagination.max_pages",
+			Value:   fmt.Sprintf("%d", p.MaxPages),
+			Message: "max_pages cannot be negative",
+		})
+	}
+}
+
 // validateFields checks field configurations
 func (sc *ScraperConfig) validateFields(result *ValidationResult) {
 	fieldNames := make(map[string]bool)
@@ -146,26 +235,93 @@ func (sc *ScraperConfig) validateFields(result *ValidationResult) {
 		}
 		fieldNames[field.Name] = true
 
+		if field.Validate != nil {
+			sc.validateFieldValidation(field.Validate, fieldPrefix, result)
+		}
+
+		switch field.SelectorType {
+		case "", "css":
+		case "xpath":
+			if field.Type == "header" {
+				result.Errors = append(result.Errors, ValidationError{
+					Field:   fmt.Sprintf("%s.selector_type", fieldPrefix),
+					Value:   field.SelectorType,
+					Message: "Header fields name a response header, not an XPath expression",
+				})
+			}
+		default:
+			result.Errors = append(result.Errors, ValidationError{
+				Field:   fmt.Sprintf("%s.selector_type", fieldPrefix),
+				Value:   field.SelectorType,
+				Message: "Selector type must be css or xpath",
+			})
+		}
+
+		switch field.OutputType {
+		case "", "number", "integer", "boolean":
+		default:
+			result.Errors = append(result.Errors, ValidationError{
+				Field:   fmt.Sprintf("%s.output_type", fieldPrefix),
+				Value:   field.OutputType,
+				Message: "Output type must be number, integer or boolean",
+			})
+		}
+
+		if field.ExtractTimeout != "" {
+			if timeout, err := time.ParseDuration(field.ExtractTimeout); err != nil || timeout <= 0 {
+				result.Errors = append(result.Errors, ValidationError{
+					Field:   fmt.Sprintf("%s.extract_timeout", fieldPrefix),
+					Value:   field.ExtractTimeout,
+					Message: "Extraction timeout must be a positive duration such as 2s",
+				})
+			}
+		}
+
+		// Fields with sources are defined entirely by their source list
+		if len(field.FieldSources()) > 0 {
+			sc.validateFieldSources(field, fieldPrefix, result)
+			sc.validateFieldTransforms(field, fieldPrefix, result)
+			continue
+		}
+
 		// Validate selector
-		if field.Selector == "" {
+		if field.Selector == "" && len(field.Selectors) == 0 {
+			message := "CSS selector is required"
+			if field.SelectorType == "xpath" {
+				message = "XPath selector is required"
+			}
+			if field.Type == "header" {
+				message = "Header name is required in selector for 'header' type fields"
+			}
+			if field.Type == "jsonld" {
+				message = "JSON-LD path is required in selector for 'jsonld' type fields"
+			}
+			if field.Type == "microdata" {
+				message = "Microdata path is required in selector for 'microdata' type fields ($ for whole items)"
+			}
 			result.Errors = append(result.Errors, ValidationError{
 				Field:   fmt.Sprintf("%s.selector", fieldPrefix),
 				Value:   "",
-				Message: "CSS selector is required",
+				Message: message,
 			})
-		} else {
-			// Basic CSS selector validation
-			if err := validateCSSSelector(field.Selector); err != nil {
+		} else if field.Selector != "" {
+			validateFieldSelector(field, fmt.Sprintf("%s.selector", fieldPrefix), field.Selector, result)
+		}
+		for j, selector := range field.Selectors {
+			selectorField := fmt.Sprintf("%s.selectors[%d]", fieldPrefix, j)
+			if selector == "" {
 				result.Errors = append(result.Errors, ValidationError{
-					Field:   fmt.Sprintf("%s.selector", fieldPrefix),
-					Value:   field.Selector,
-					Message: fmt.Sprintf("Invalid CSS selector: %s", err.Error()),
+					Field:   selectorField,
+					Value:   "",
+					Message: "Fallback selector cannot be empty",
 				})
+				continue
 			}
+			validateFieldSelector(field, selectorField, selector, result)
 		}
 
 		// Validate field type
-		validTypes := []string{"text", "attr", "html", "array", "list", "int", "float", "bool"}
+		validTypes := []string{"text", "attr", "html", "array", "list", "int", "float", "bool", "header", "embedded_json", "table", "jsonld", "microdata"}
 		if !contains(validTypes, field.Type) {
 			result.Errors = append(result.Errors, ValidationError{
 				Field:   fmt.Sprintf("%s.type", fieldPrefix),
@@ -183,9 +339,179 @@ func (sc *ScraperConfig) validateFields(result *ValidationResult) {
 			})
 		}
 
+		if field.JSONLDType != "" && field.Type != "jsonld" && field.Type != "microdata" {
+			result.Errors = append(result.Errors, ValidationError{
+				Field:   fmt.Sprintf("%s.jsonld_type", fieldPrefix),
+				Value:   field.JSONLDType,
+				Message: "'jsonld_type' only applies to 'jsonld' and 'microdata' fields and 'jsonld' sources",
+			})
+		}
+
+		// Validate JSON path for embedded_json type
+		if field.Type == "embedded_json" && field.Path == "" {
+			result.Errors = append(result.Errors, ValidationError{
+				Field:   fmt.Sprintf("%s.path", fieldPrefix),
+				Value:   "",
+				Message: "JSON path is required for 'embedded_json' type fields (use '$' for the whole blob)",
+			})
+		}
+
+		// Table rows are maps, which transforms and output_type do not apply to
+		if field.Type == "table" && (len(field.Transform) > 0 || field.OutputType != "") {
+			result.Errors = append(result.Errors, ValidationError{
+				Field:   fmt.Sprintf("%s.type", fieldPrefix),
+				Value:   field.Type,
+				Message: "Transforms and output_type cannot be used with 'table' type fields",
+			})
+		}
+		if len(field.Columns) > 0 && field.Type != "table" {
+			result.Errors = append(result.Errors, ValidationError{
+				Field:   fmt.Sprintf("%s.columns", fieldPrefix),
+				Value:   strings.Join(field.Columns, ", "),
+				Message: "Columns can only be set on 'table' type fields",
+			})
+		}
+
 		// Validate transforms if present
 		sc.validateFieldTransforms(field, fieldPrefix, result)
 	}
+
+	for i, name := range sc.RetryOnEmptyFields {
+		if !fieldNames[name] {
+			result.Errors = append(result.Errors, ValidationError{
+				Field:   fmt.Sprintf("retry_on_empty_fields[%d]", i),
+				Value:   name,
+				Message: fmt.Sprintf("Unknown field: %s", name),
+			})
+		}
+	}
+}
+
+// validateFieldSelector checks one of a field's selectors: a header name for
+// header fields, else an XPath or CSS selector; jsonld and microdata paths are not checked
+func validateFieldSelector(field FieldConfig, selectorField, selector string, result *ValidationResult) {
+	if field.Type == "header" {
+		// Header names are matched case-insensitively, so only the token syntax is checked
+		if !headerNameRegex.MatchString(selector) {
+			result.Errors = append(result.Errors, ValidationError{
+				Field:   selectorField,
+				Value:   selector,
+				Message: "Invalid HTTP header name",
+			})
+		}
+	} else if field.SelectorType == "xpath" {
+		if _, err := xpath.Compile(selector); err != nil {
+			result.Errors = append(result.Errors, ValidationError{
+				Field:   selectorField,
+				Value:   selector,
+				Message: fmt.Sprintf("Invalid XPath expression: %s", err.Error()),
+			})
+		}
+	} else if field.Type != "jsonld" && field.Type != "microdata" { // Their selectors are item paths
+		// Basic CSS selector validation
+		if err := validateCSSSelector(selector); err != nil {
+			result.Errors = append(result.Errors, ValidationError{
+				Field:   selectorField,
+				Value:   selector,
+				Message: fmt.Sprintf("Invalid CSS selector: %s", err.Error()),
+			})
+		}
+	}
+}
+
+// validateFieldSources checks the ordered fallback sources of a field
+func (sc *ScraperConfig) validateFieldSources(field FieldConfig, fieldPrefix string, result *ValidationResult) {
+	validSourceTypes := []string{"css", "attr", "html", "header", "jsonld", "regex"}
+
+	if field.Source != "" && len(field.Sources) > 0 {
+		result.Errors = append(result.Errors, ValidationError{
+			Field:   fmt.Sprintf("%s.source", fieldPrefix),
+			Value:   field.Source,
+			Message: "Use either 'source' or 'sources', not both",
+		})
+		return
+	}
+
+	for i, source := range field.FieldSources() {
+		sourcePrefix := fmt.Sprintf("%s.sources[%d]", fieldPrefix, i)
+		if field.Source != "" {
+			// Shorthand sources take their settings from the field itself
+			sourcePrefix = fieldPrefix
+		}
+
+		if !contains(validSourceTypes, source.Type) {
+			result.Errors = append(result.Errors, ValidationError{
+				Field:   fmt.Sprintf("%s.type", sourcePrefix),
+				Value:   source.Type,
+				Message: fmt.Sprintf("Invalid source type. Valid types: %s", strings.Join(validSourceTypes, ", ")),
+			})
+			continue
+		}
+
+		if source.JSONLDType != "" && source.Type != "jsonld" {
+			result.Errors = append(result.Errors, ValidationError{
+				Field:   fmt.Sprintf("%s.jsonld_type", sourcePrefix),
+				Value:   source.JSONLDType,
+				Message: "'jsonld_type' only applies to 'jsonld' sources",
+			})
+		}
+
+		var missing string
+		switch source.Type {
+		case "css", "html", "header":
+			if source.Selector == "" {
+				missing = "selector"
+			}
+		case "attr":
+			if source.Selector == "" {
+				missing = "selector"
+			} else if source.Attribute == "" {
+				missing = "attribute"
+			}
+		case "jsonld":
+			if source.Path == "" {
+				missing = "path"
+			}
+		case "regex":
+			if source.Pattern == "" {
+				missing = "pattern"
+			} else if _, err := regexp.Compile(source.Pattern); err != nil {
+				result.Errors = append(result.Errors, ValidationError{
+					Field:   fmt.Sprintf("%s.pattern", sourcePrefix),
+					Value:   source.Pattern,
+					Message: fmt.Sprintf("Invalid regex pattern: %s", err.Error()),
+				})
+			}
+		}
+
+		if missing != "" {
+			result.Errors = append(result.Errors, ValidationError{
+				Field:   fmt.Sprintf("%s.%s", sourcePrefix, missing),
+				Value:   "",
+				Message: fmt.Sprintf("'%s' is required for '%s' sources", missing, source.Type),
+			})
+		}
+	}
+}
+
+// validateFieldValidation checks a field's validate block
+func (sc *ScraperConfig) validateFieldValidation(v *FieldValidation, fieldPrefix string, result *ValidationResult) {
+	if v.Pattern != "" {
+		if _, err := regexp.Compile(v.Pattern); err != nil {
+			result.Errors = append(result.Errors, ValidationError{
+				Field:   fmt.Sprintf("%s.validate.pattern", fieldPrefix),
+				Value:   v.Pattern,
+				Message: fmt.Sprintf("Invalid regex pattern: %s", err.Error()),
+			})
+		}
+	}
+	if v.MinLength < 0 || v.MaxLength < 0 || (v.MaxLength > 0 && v.MinLength > v.MaxLength) {
+		result.Errors = append(result.Errors, ValidationError{
+			Field:   fmt.Sprintf("%s.validate", fieldPrefix),
+			Value:   fmt.Sprintf("min_length=%d max_length=%d", v.MinLength, v.MaxLength),
+			Message: "Lengths must be non-negative and min_length must not exceed max_length",
+		})
+	}
 }
 
 // validateFieldTransforms checks field transformation rules
@@ -221,32 +547,248 @@ func (sc *ScraperConfig) validateFieldTransforms(field FieldConfig, fieldPrefix
 				}
 			}
 		}
+
+		// json_parse yields structured data, which no later rule can take
+		if transform.Type == "json_parse" && i != len(field.Transform)-1 {
+			result.Errors = append(result.Errors, ValidationError{
+				Field:   fmt.Sprintf("%s.type", transformPrefix),
+				Value:   transform.Type,
+				Message: "json_parse must be the last transform of a field",
+			})
+		}
+
+		// Validate date transforms
+		if transform.Type == "date" && transform.Params != nil {
+			if name, ok := transform.Params["timezone"].(string); ok && name != "" {
+				if _, err := time.LoadLocation(name); err != nil {
+					result.Errors = append(result.Errors, ValidationError{
+						Field:   fmt.Sprintf("%s.params.timezone", transformPrefix),
+						Value:   name,
+						Message: fmt.Sprintf("Invalid timezone: %s", err.Error()),
+					})
+				}
+			}
+		}
 	}
 }
 
 // validateOutput checks output configuration
 func (sc *ScraperConfig) validateOutput(result *ValidationResult) {
-	if sc.Output.Format == "" {
+	if len(sc.Output.Outputs) == 0 {
+		validateOutputSink(sc.Output, "output", result)
+	} else {
+		if sc.Output.Format != "" || sc.Output.File != "" {
+			result.Errors = append(result.Errors, ValidationError{
+				Field:   "output.outputs",
+				Value:   fmt.Sprintf("%d outputs", len(sc.Output.Outputs)),
+				Message: "output.format and output.file cannot be combined with output.outputs",
+			})
+		}
+		files := make(map[string]bool)
+		for i, sink := range sc.Output.Outputs {
+			prefix := fmt.Sprintf("output.outputs[%d]", i)
+			validateOutputSink(sink, prefix, result)
+			if len(sink.Outputs) > 0 || sink.QualityGate != nil {
+				result.Errors = append(result.Errors, ValidationError{
+					Field:   prefix,
+					Value:   sink.File,
+					Message: "Outputs cannot nest outputs or set quality_gate; set quality_gate on output",
+				})
+			}
+			// Spellings of the same path, like out.json and ./out.json, are the same file
+			file := filepath.Clean(sink.File)
+			if sink.File != "" && files[file] {
+				result.Errors = append(result.Errors, ValidationError{
+					Field:   prefix + ".file",
+					Value:   sink.File,
+					Message: "Each output must write to a different file",
+				})
+			}
+			files[file] = true
+		}
+	}
+
+	if gate := sc.Output.QualityGate; gate != nil {
+		if gate.MinRecords < 0 {
+			result.Errors = append(result.Errors, ValidationError{
+				Field:   "output.quality_gate.min_records",
+				Value:   fmt.Sprintf("%d", gate.MinRecords),
+				Message: "Minimum records cannot be negative",
+			})
+		}
+		for field, fill := range gate.MinFill {
+			if fill < 0 || fill > 1 {
+				result.Errors = append(result.Errors, ValidationError{
+					Field:   fmt.Sprintf("output.quality_gate.min_fill.%s", field),
+					Value:   fmt.Sprintf("%g", fill),
+					Message: "Minimum fill rate must be between 0 and 1",
+				})
+			}
+		}
+	}
+}
+
+// validateOutputSink checks the format, file and format options of one output;
+// prefix is "output" or the output's position in output.outputs
+func validateOutputSink(out OutputConfig, prefix string, result *ValidationResult) {
+	if out.Format == "" {
 		result.Errors = append(result.Errors, ValidationError{
-			Field:   "output.format",
+			Field:   prefix + ".format",
 			Value:   "",
 			Message: "Output format is required",
 		})
 		return
 	}
 
-	validFormats := []string{"json", "csv", "yaml"}
-	if !contains(validFormats, sc.Output.Format) {
+	validFormats := []string{"json", "jsonl", "jsonlines", "csv", "yaml", "parquet", "xlsx"}
+	if !contains(validFormats, out.Format) {
 		result.Errors = append(result.Errors, ValidationError{
-			Field:   "output.format",
-			Value:   sc.Output.Format,
+			Field:   prefix + ".format",
+			Value:   out.Format,
 			Message: fmt.Sprintf("Invalid output format. Valid formats: %s", strings.Join(validFormats, ", ")),
 		})
 	}
 
-	if sc.Output.File == "" {
+	if out.File == "" {
 		result.Warnings = append(result.Warnings,
 			"No output file specified, results will be written to stdout")
+	} else if err := validateOutputPlaceholders(out.File); err != nil {
+		result.Errors = append(result.Errors, ValidationError{
+			Field:   prefix + ".file",
+			Value:   out.File,
+			Message: err.Error(),
+		})
+	}
+
+	hasPartition := strings.Contains(out.File, PartitionPlaceholder)
+	if out.PartitionBy != "" && !hasPartition {
+		result.Errors = append(result.Errors, ValidationError{
+			Field:   prefix + ".file",
+			Value:   out.File,
+			Message: prefix + ".partition_by requires a {partition} placeholder in " + prefix + ".file",
+		})
+	} else if out.PartitionBy == "" && hasPartition {
+		result.Errors = append(result.Errors, ValidationError{
+			Field:   prefix + ".file",
+			Value:   out.File,
+			Message: "{partition} placeholder requires " + prefix + ".partition_by",
+		})
+	}
+
+	if len(out.Columns) > 0 && out.CSVUnion {
+		result.Errors = append(result.Errors, ValidationError{
+			Field:   prefix + ".csv_union",
+			Value:   "true",
+			Message: prefix + ".columns and " + prefix + ".csv_union are mutually exclusive",
+		})
+	}
+
+	if out.LineEnding != "" && out.LineEnding != "lf" && out.LineEnding != "crlf" {
+		result.Errors = append(result.Errors, ValidationError{
+			Field:   prefix + ".line_ending",
+			Value:   out.LineEnding,
+			Message: "Invalid line ending. Valid line endings: lf, crlf",
+		})
+	}
+
+	// Binary formats have no lines to end and no text to mark
+	textFormats := []string{"json", "jsonl", "jsonlines", "csv"}
+	if contains(validFormats, out.Format) && !contains(textFormats, out.Format) {
+		if out.BOM {
+			result.Errors = append(result.Errors, ValidationError{
+				Field:   prefix + ".bom",
+				Value:   "true",
+				Message: fmt.Sprintf("bom is not supported for %s output. Formats supporting it: %s", out.Format, strings.Join(textFormats, ", ")),
+			})
+		}
+		if out.LineEnding != "" {
+			result.Errors = append(result.Errors, ValidationError{
+				Field:   prefix + ".line_ending",
+				Value:   out.LineEnding,
+				Message: fmt.Sprintf("line_ending is not supported for %s output. Formats supporting it: %s", out.Format, strings.Join(textFormats, ", ")),
+			})
+		}
+	}
+
+	if out.NestedEncoding != "" {
+		validEncodings := []string{"json", "flatten", "drop"}
+		if !contains(validEncodings, out.NestedEncoding) {
+			result.Errors = append(result.Errors, ValidationError{
+				Field:   prefix + ".nested_encoding",
+				Value:   out.NestedEncoding,
+				Message: fmt.Sprintf("Invalid nested encoding. Valid encodings: %s", strings.Join(validEncodings, ", ")),
+			})
+		}
+	}
+
+	validParquetTypes := []string{"string", "int64", "double", "boolean"}
+	for column, columnType := range out.ParquetSchema {
+		if !contains(validParquetTypes, columnType) {
+			result.Errors = append(result.Errors, ValidationError{
+				Field:   prefix + ".parquet_schema." + column,
+				Value:   columnType,
+				Message: fmt.Sprintf("Invalid Parquet type. Valid types: %s", strings.Join(validParquetTypes, ", ")),
+			})
+		}
+	}
+
+	// Excel's limits on worksheet names
+	if name := out.SheetName; name != "" && (utf8.RuneCountInString(name) > 31 ||
+		strings.ContainsAny(name, `:\/?*[]`) || strings.HasPrefix(name, "'") || strings.HasSuffix(name, "'")) {
+		result.Errors = append(result.Errors, ValidationError{
+			Field:   prefix + ".sheet_name",
+			Value:   name,
+			Message: "Invalid sheet name: at most 31 characters, none of : \\ / ? * [ ], and no leading or trailing apostrophe",
+		})
+	}
+
+	seen := make(map[string]bool)
+	for i, column := range out.Columns {
+		if column == "" || seen[column] {
+			result.Errors = append(result.Errors, ValidationError{
+				Field:   fmt.Sprintf("%s.columns[%d]", prefix, i),
+				Value:   column,
+				Message: "Column names must be non-empty and unique",
+			})
+		}
+		seen[column] = true
+	}
+
+	if out.CSV != nil {
+		validateCSVOptions(*out.CSV, prefix+".csv", result)
+		// Both fix the header, one dropping unlisted fields and one keeping them
+		if len(out.Columns) > 0 && len(out.CSV.Columns) > 0 {
+			result.Errors = append(result.Errors, ValidationError{
+				Field:   prefix + ".csv.columns",
+				Value:   strings.Join(out.CSV.Columns, ", "),
+				Message: prefix + ".columns and " + prefix + ".csv.columns are mutually exclusive",
+			})
+		}
+	}
+}
+
+// validateCSVOptions checks that the delimiter is one character and the column
+// order names each column once
+func validateCSVOptions(csv CSVOptions, prefix string, result *ValidationResult) {
+	if csv.Delimiter != "" && (utf8.RuneCountInString(csv.Delimiter) != 1 || !utf8.ValidString(csv.Delimiter) ||
+		strings.ContainsAny(csv.Delimiter, "\"\r\n")) {
+		result.Errors = append(result.Errors, ValidationError{
+			Field:   prefix + ".delimiter",
+			Value:   csv.Delimiter,
+			Message: "Delimiter must be a single character other than a quote or line break",
+		})
+	}
+
+	seen := make(map[string]bool)
+	for i, column := range csv.Columns {
+		if column == "" || seen[column] {
+			result.Errors = append(result.Errors, ValidationError{
+				Field:   fmt.Sprintf("%s.columns[%d]", prefix, i),
+				Value:   column,
+				Message: "Column names must be non-empty and unique",
+			})
+		}
+		seen[column] = true
 	}
 }
 
@@ -272,6 +814,24 @@ func (sc *ScraperConfig) validateEngineSettings(result *ValidationResult) {
 		}
 	}
 
+	for host, limit := range sc.HostRateLimits {
+		field := fmt.Sprintf("host_rate_limits[%s]", host)
+		if duration, err := time.ParseDuration(limit.RateLimit); err != nil || duration <= 0 {
+			result.Errors = append(result.Errors, ValidationError{
+				Field:   field + ".rate_limit",
+				Value:   limit.RateLimit,
+				Message: "Host rate limit must be a positive duration such as 1s",
+			})
+		}
+		if limit.BurstSize < 0 {
+			result.Errors = append(result.Errors, ValidationError{
+				Field:   field + ".burst_size",
+				Value:   fmt.Sprintf("%d", limit.BurstSize),
+				Message: "Burst size cannot be negative",
+			})
+		}
+	}
+
 	// Validate Timeout if provided
 	if sc.Timeout != "" {
 		if duration, err := time.ParseDuration(sc.Timeout); err != nil {
@@ -292,6 +852,95 @@ func (sc *ScraperConfig) validateEngineSettings(result *ValidationResult) {
 		}
 	}
 
+	// Validate transport deadlines if provided
+	if sc.Transport != nil {
+		transportTimeouts := []struct {
+			field string
+			value string
+		}{
+			{"transport.dial_timeout", sc.Transport.DialTimeout},
+			{"transport.tls_handshake_timeout", sc.Transport.TLSHandshakeTimeout},
+			{"transport.response_header_timeout", sc.Transport.ResponseHeaderTimeout},
+		}
+		for _, t := range transportTimeouts {
+			if t.value == "" {
+				continue
+			}
+			if duration, err := time.ParseDuration(t.value); err != nil {
+				result.Errors = append(result.Errors, ValidationError{
+					Field:   t.field,
+					Value:   t.value,
+					Message: fmt.Sprintf("Invalid timeout format: %s", err.Error()),
+				})
+			} else if duration < 0 {
+				result.Errors = append(result.Errors, ValidationError{
+					Field:   t.field,
+					Value:   t.value,
+					Message: "Timeout cannot be negative",
+				})
+			}
+		}
+
+		if sc.Transport.MaxTotalConnections < 0 {
+			result.Errors = append(result.Errors, ValidationError{
+				Field:   "transport.max_total_connections",
+				Value:   fmt.Sprintf("%d", sc.Transport.MaxTotalConnections),
+				Message: "Connection cap cannot be negative",
+			})
+		}
+	}
+
+	if sc.TLS != nil && sc.TLS.Mimic != "" {
+		validProfiles := []string{"chrome", "firefox", "safari"}
+		if !contains(validProfiles, sc.TLS.Mimic) {
+			result.Errors = append(result.Errors, ValidationError{
+				Field:   "tls.mimic",
+				Value:   sc.TLS.Mimic,
+				Message: fmt.Sprintf("Invalid TLS mimic profile. Valid profiles: %s", strings.Join(validProfiles, ", ")),
+			})
+		} else if !tlsMimicSupported {
+			result.Warnings = append(result.Warnings,
+				"tls.mimic has no effect in this build: it needs a build with -tags utls, and runs with it set fail")
+		}
+	}
+
+	if sc.HeaderOrder != nil && len(sc.HeaderOrder.Order) == 0 {
+		validProfiles := []string{"chrome", "firefox", "safari"}
+		switch {
+		case sc.HeaderOrder.Profile != "" && !contains(validProfiles, sc.HeaderOrder.Profile):
+			result.Errors = append(result.Errors, ValidationError{
+				Field:   "header_order.profile",
+				Value:   sc.HeaderOrder.Profile,
+				Message: fmt.Sprintf("Invalid header order profile. Valid profiles: %s", strings.Join(validProfiles, ", ")),
+			})
+		case sc.HeaderOrder.Profile == "" && (sc.TLS == nil || sc.TLS.Mimic == ""):
+			result.Errors = append(result.Errors, ValidationError{
+				Field:   "header_order",
+				Value:   "",
+				Message: "Set header_order.profile, header_order.order, or tls.mimic",
+			})
+		}
+	}
+
+	if sc.BlockAbort != nil {
+		if sc.BlockAbort.Threshold <= 0 {
+			result.Errors = append(result.Errors, ValidationError{
+				Field:   "block_abort.threshold",
+				Value:   fmt.Sprintf("%d", sc.BlockAbort.Threshold),
+				Message: "Block abort threshold must be positive",
+			})
+		}
+		if sc.BlockAbort.Window != "" {
+			if window, err := time.ParseDuration(sc.BlockAbort.Window); err != nil || window <= 0 {
+				result.Errors = append(result.Errors, ValidationError{
+					Field:   "block_abort.window",
+					Value:   sc.BlockAbort.Window,
+					Message: "Block abort window must be a positive duration (e.g. 10m)",
+				})
+			}
+		}
+	}
+
 	// Validate Retries
 	if sc.Retries < 0 {
 		result.Errors = append(result.Errors, ValidationError{
@@ -309,6 +958,189 @@ func (sc *ScraperConfig) validateEngineSettings(result *ValidationResult) {
 			Message: "Max retries cannot be negative",
 		})
 	}
+
+	if sc.Concurrency < 0 || sc.Concurrency > 1000 {
+		result.Errors = append(result.Errors, ValidationError{
+			Field:   "concurrency",
+			Value:   fmt.Sprintf("%d", sc.Concurrency),
+			Message: "Concurrency must be between 0 and 1000",
+		})
+	}
+
+	if sc.MaxPagesPerHost < 0 {
+		result.Errors = append(result.Errors, ValidationError{
+			Field:   "max_pages_per_host",
+			Value:   fmt.Sprintf("%d", sc.MaxPagesPerHost),
+			Message: "Max pages per host cannot be negative",
+		})
+	}
+
+	if sc.DNSRetries < 0 {
+		result.Errors = append(result.Errors, ValidationError{
+			Field:   "dns_retries",
+			Value:   fmt.Sprintf("%d", sc.DNSRetries),
+			Message: "DNS retries cannot be negative",
+		})
+	}
+
+	if sc.RetryJitter < 0 || sc.RetryJitter > 1 {
+		result.Errors = append(result.Errors, ValidationError{
+			Field:   "retry_jitter",
+			Value:   fmt.Sprintf("%g", sc.RetryJitter),
+			Message: "Retry jitter must be between 0 and 1",
+		})
+	}
+
+	if sc.LazyImages != nil {
+		for _, attr := range append(append([]string{}, sc.LazyImages.Src...), sc.LazyImages.Srcset...) {
+			if strings.TrimSpace(attr) == "" {
+				result.Errors = append(result.Errors, ValidationError{
+					Field:   "lazy_images",
+					Value:   attr,
+					Message: "Lazy image attribute names cannot be empty",
+				})
+			}
+		}
+	}
+
+	if sc.MalformedHTML != nil && sc.MalformedHTML.MaxUnbalancedTags < 0 {
+		result.Errors = append(result.Errors, ValidationError{
+			Field:   "malformed_html.max_unbalanced_tags",
+			Value:   fmt.Sprintf("%d", sc.MalformedHTML.MaxUnbalancedTags),
+			Message: "Max unbalanced tags cannot be negative",
+		})
+	}
+
+	sc.validateRequest("", RequestConfig{Method: sc.Method, Body: sc.Body, BodyType: sc.BodyType}, result)
+	for pageURL, request := range sc.URLRequests {
+		sc.validateRequest(fmt.Sprintf("url_requests[%s].", pageURL), request, result)
+	}
+
+	if cd := sc.ChangeDetection; cd != nil {
+		if strings.TrimSpace(cd.StateFile) == "" {
+			result.Errors = append(result.Errors, ValidationError{
+				Field:   "change_detection.state_file",
+				Value:   cd.StateFile,
+				Message: "Change detection needs a state file",
+			})
+		}
+		if strings.TrimSpace(cd.KeyField) == "" {
+			result.Errors = append(result.Errors, ValidationError{
+				Field:   "change_detection.key_field",
+				Value:   cd.KeyField,
+				Message: "Change detection needs the field that identifies a record across runs",
+			})
+		}
+	}
+
+	if dd := sc.Deduplication; dd != nil {
+		switch dd.Method {
+		case "", "hash", "field", "url", "similarity":
+		default:
+			result.Errors = append(result.Errors, ValidationError{
+				Field:   "deduplication.method",
+				Value:   dd.Method,
+				Message: "Deduplication method must be hash, field, url or similarity",
+			})
+		}
+		if dd.Threshold < 0 || dd.Threshold > 1 {
+			result.Errors = append(result.Errors, ValidationError{
+				Field:   "deduplication.threshold",
+				Value:   fmt.Sprintf("%g", dd.Threshold),
+				Message: "Similarity threshold must be between 0 and 1",
+			})
+		}
+		if dd.SimilarityAlgorithm != "" && !slices.Contains(pipeline.ValidSimilarityAlgorithms(), dd.SimilarityAlgorithm) {
+			result.Errors = append(result.Errors, ValidationError{
+				Field:   "deduplication.similarity_algorithm",
+				Value:   dd.SimilarityAlgorithm,
+				Message: fmt.Sprintf("Similarity algorithm must be one of %s", strings.Join(pipeline.ValidSimilarityAlgorithms(), ", ")),
+			})
+		}
+		if dd.CacheSize < 0 {
+			result.Errors = append(result.Errors, ValidationError{
+				Field:   "deduplication.cache_size",
+				Value:   fmt.Sprintf("%d", dd.CacheSize),
+				Message: "Cache size cannot be negative",
+			})
+		}
+	}
+
+	if sc.CanonicalURL != nil {
+		for _, param := range sc.CanonicalURL.StripParams {
+			if strings.TrimSpace(param) == "" || param == "*" {
+				result.Errors = append(result.Errors, ValidationError{
+					Field:   "canonical_url.strip_params",
+					Value:   param,
+					Message: "Strip params must name a parameter or a prefix ending in *",
+				})
+			}
+		}
+	}
+
+	if fp := sc.FailurePolicy; fp != nil {
+		switch fp.Mode {
+		case "", "stop", "continue", "partial":
+		default:
+			result.Errors = append(result.Errors, ValidationError{
+				Field:   "failure_policy.mode",
+				Value:   fp.Mode,
+				Message: "Failure policy mode must be stop, continue or partial",
+			})
+		}
+		if fp.MaxErrorRate != nil && (*fp.MaxErrorRate < 0 || *fp.MaxErrorRate > 1) {
+			result.Errors = append(result.Errors, ValidationError{
+				Field:   "failure_policy.max_error_rate",
+				Value:   fmt.Sprintf("%g", *fp.MaxErrorRate),
+				Message: "Max error rate must be between 0 and 1",
+			})
+		}
+	}
+
+	if sc.Debug != nil && sc.Debug.RecordSession != "" && sc.Debug.ReplaySession != "" {
+		result.Errors = append(result.Errors, ValidationError{
+			Field:   "debug.replay_session",
+			Value:   sc.Debug.ReplaySession,
+			Message: "A session cannot be recorded and replayed in the same run",
+		})
+	}
+
+	bodyMatches := []struct {
+		option   string
+		patterns []string
+	}{
+		{"retry_on_body_match", sc.RetryOnBodyMatch},
+		{"no_retry_on_body_match", sc.NoRetryOnBodyMatch},
+	}
+	for _, bm := range bodyMatches {
+		option := bm.option
+		for i, pattern := range bm.patterns {
+			if _, err := regexp.Compile(pattern); err != nil {
+				result.Errors = append(result.Errors, ValidationError{
+					Field:   fmt.Sprintf("%s[%d]", option, i),
+					Value:   pattern,
+					Message: fmt.Sprintf("Invalid regex pattern: %s", err.Error()),
+				})
+			}
+		}
+	}
+}
+
+// validateProxy checks the proxy providers' types
+func (sc *ScraperConfig) validateProxy(result *ValidationResult) {
+	if sc.Proxy == nil {
+		return
+	}
+	validTypes := []string{"http", "https", "socks5", "socks5h"}
+	for i, provider := range sc.Proxy.Providers {
+		if !contains(validTypes, provider.Type) {
+			result.Errors = append(result.Errors, ValidationError{
+				Field:   fmt.Sprintf("proxy.providers[%d].type", i),
+				Value:   provider.Type,
+				Message: fmt.Sprintf("Unsupported proxy type. Valid types: %s", strings.Join(validTypes, ", ")),
+			})
+		}
+	}
 }
 
 // validateCSSSelector performs basic CSS selector validation
@@ -382,9 +1214,12 @@ func (sc *ScraperConfig) ValidateWithDetails() *ValidationResult {
 
 	sc.validateBasicFields(result)
 	sc.validateURL(result)
+	sc.validateWarmupURLs(result)
+	sc.validatePagination(result)
 	sc.validateFields(result)
 	sc.validateOutput(result)
 	sc.validateEngineSettings(result)
+	sc.validateProxy(result)
 
 	result.Valid = len(result.Errors) == 0
 	return result
@@ -442,6 +1277,89 @@ func (sc *ScraperConfig) GetValidationSuggestions(result *ValidationResult) []st
 }
 
 // Helper function to check if slice contains string
+// validateRequest checks a page request's method and body: a body is only
+// allowed for POST, PUT, PATCH and DELETE, and must suit its body_type
+func (sc *ScraperConfig) validateRequest(prefix string, request RequestConfig, result *ValidationResult) {
+	method := strings.ToUpper(request.Method)
+	if method == "" {
+		method = "GET"
+	}
+	if !contains([]string{"GET", "HEAD", "POST", "PUT", "PATCH", "DELETE"}, method) {
+		result.Errors = append(result.Errors, ValidationError{
+			Field:   prefix + "method",
+			Value:   request.Method,
+			Message: "Method must be GET, HEAD, POST, PUT, PATCH or DELETE",
+		})
+		return
+	}
+	if method != "GET" && sc.Browser != nil && sc.Browser.Enabled {
+		result.Errors = append(result.Errors, ValidationError{
+			Field:   prefix + "method",
+			Value:   request.Method,
+			Message: "Browser fetching only sends GET requests",
+		})
+	}
+
+	if request.Body == nil {
+		if request.BodyType != "" {
+			result.Errors = append(result.Errors, ValidationError{
+				Field:   prefix + "body_type",
+				Value:   request.BodyType,
+				Message: "Body type is set without a body",
+			})
+		}
+		return
+	}
+	if !contains([]string{"POST", "PUT", "PATCH", "DELETE"}, method) {
+		result.Errors = append(result.Errors, ValidationError{
+			Field:   prefix + "body",
+			Value:   method,
+			Message: fmt.Sprintf("A %s request cannot have a body; use POST, PUT, PATCH or DELETE", method),
+		})
+		return
+	}
+
+	text, isString := request.Body.(string)
+	bodyType := strings.ToLower(request.BodyType)
+	if bodyType == "" {
+		bodyType = "json"
+		if isString {
+			bodyType = "raw"
+		}
+	}
+	message := ""
+	switch bodyType {
+	case "json":
+		if isString && !json.Valid([]byte(strings.ReplaceAll(text, "${page}", "1"))) {
+			message = "JSON body is not valid JSON"
+		}
+	case "form":
+		switch request.Body.(type) {
+		case string, map[string]interface{}, map[interface{}]interface{}:
+		default:
+			message = "Form body must be a map of fields or an encoded string"
+		}
+	case "raw":
+		if !isString {
+			message = "Raw body must be a string"
+		}
+	default:
+		result.Errors = append(result.Errors, ValidationError{
+			Field:   prefix + "body_type",
+			Value:   request.BodyType,
+			Message: "Body type must be form, json or raw",
+		})
+		return
+	}
+	if message != "" {
+		result.Errors = append(result.Errors, ValidationError{
+			Field:   prefix + "body",
+			Value:   bodyType,
+			Message: message,
+		})
+	}
+}
+
 func contains(slice []string, item string) bool {
 	for _, s := range slice {
 		if s == item {
@@ -0,0 +1,106 @@
+// internal/errors/api_fallback.go
+package errors
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+	"io"
+	"net/http"
+	"net/url"
+	"strings"
+	"time"
+)
+
+// DefaultAPIFallbackTimeout bounds an api_fallback request when APITimeout is unset
+const DefaultAPIFallbackTimeout = 10 * time.Second
+
+// maxAPIFallbackBody caps the API response read by an api_fallback
+const maxAPIFallbackBody = 10 << 20
+
+type pageURLKey struct{}
+
+// WithPageURL records the URL an operation is fetching, so an api_fallback
+// configured for it can fill {url}, {host} and {path} in its api_url
+func WithPageURL(ctx context.Context, pageURL string) context.Context {
+	return context.WithValue(ctx, pageURLKey{}, pageURL)
+}
+
+// pageURLFromContext returns the URL recorded by WithPageURL, or ""
+func pageURLFromContext(ctx context.Context) string {
+	pageURL, _ := ctx.Value(pageURLKey{}).(string)
+	return pageURL
+}
+
+// executeAPIFallback fetches the operation's data from a JSON API instead: a GET
+// of config.APIURL, with the placeholders filled from the page URL, sending
+// config.Headers through the client set by WithHTTPClient. The decoded body is the fallback result. Any status other
+// than 2xx is an error, so a fallback chain moves on to its next step.
+func (s *Service) executeAPIFallback(ctx context.Context, operationName string, config FallbackConfig) (interface{}, error) {
+	if config.APIURL == "" {
+		return nil, fmt.Errorf("no api_url configured for api_fallback of operation: %s", operationName)
+	}
+	endpoint, err := apiFallbackURL(config.APIURL, pageURLFromContext(ctx))
+	if err != nil {
+		return nil, err
+	}
+
+	timeout := config.APITimeout
+	if timeout <= 0 {
+		timeout = DefaultAPIFallbackTimeout
+	}
+	ctx, cancel := context.WithTimeout(ctx, timeout)
+	defer cancel()
+
+	req, err := http.NewRequestWithContext(ctx, http.MethodGet, endpoint, nil)
+	if err != nil {
+		return nil, fmt.Errorf("api_fallback request: %w", err)
+	}
+	for key, values := range config.Headers {
+		for _, value := range values {
+			req.Header.Add(key, value)
+		}
+	}
+	if req.Header.Get("Accept") == "" {
+		req.Header.Set("Accept", "application/json")
+	}
+
+	client := s.httpClient
+	if client == nil {
+		client = http.DefaultClient
+	}
+	resp, err := client.Do(req)
+	if err != nil {
+		return nil, fmt.Errorf("api_fallback request to %s failed: %w", endpoint, err)
+	}
+	defer resp.Body.Close()
+	if resp.StatusCode < 200 || resp.StatusCode > 299 {
+		return nil, fmt.Errorf("api_fallback request to %s failed: HTTP %d", endpoint, resp.StatusCode)
+	}
+
+	var data interface{}
+	if err := json.NewDecoder(io.LimitReader(resp.Body, maxAPIFallbackBody)).Decode(&data); err != nil {
+		return nil, fmt.Errorf("api_fallback response from %s is not JSON: %w", endpoint, err)
+	}
+	return data, nil
+}
+
+// apiFallbackURL fills {url} (query-escaped), {host} and {path} in template
+// from pageURL
+func apiFallbackURL(template, pageURL string) (string, error) {
+	if !strings.Contains(template, "{") {
+		return template, nil
+	}
+	if pageURL == "" {
+		return "", fmt.Errorf("api_url %s needs the page URL, which the operation did not record", template)
+	}
+	u, err := url.Parse(pageURL)
+	if err != nil {
+		return "", fmt.Errorf("api_fallback: invalid page URL %q: %w", pageURL, err)
+	}
+	return strings.NewReplacer(
+		"{url}", url.QueryEscape(pageURL),
+		"{host}", u.Host,
+		"{path}", u.EscapedPath(),
+	).Replace(template), nil
+}
@@ -0,0 +1,50 @@
+// internal/errors/classified.go
+package errors
+
+import stderrors "errors"
+
+// RetryableError marks a failure the retry loop should retry whatever its message
+// says, such as a 200 response whose body asks the client to try again later
+type RetryableError struct {
+	Err error
+}
+
+func (e *RetryableError) Error() string { return e.Err.Error() }
+func (e *RetryableError) Unwrap() error { return e.Err }
+
+// PermanentError marks a failure no retry will fix, even when its message looks
+// transient; the retry loop gives up after the first attempt
+type PermanentError struct {
+	Err error
+}
+
+func (e *PermanentError) Error() string { return e.Err.Error() }
+func (e *PermanentError) Unwrap() error { return e.Err }
+
+// Retryable wraps err so ExecuteWithRecovery retries it; nil stays nil
+func Retryable(err error) error {
+	if err == nil {
+		return nil
+	}
+	return &RetryableError{Err: err}
+}
+
+// Permanent wraps err so ExecuteWithRecovery does not retry it; nil stays nil
+func Permanent(err error) error {
+	if err == nil {
+		return nil
+	}
+	return &PermanentError{Err: err}
+}
+
+// IsPermanent reports whether err, or an error it wraps, was marked Permanent
+func IsPermanent(err error) bool {
+	var permanent *PermanentError
+	return stderrors.As(err, &permanent)
+}
+
+// isMarkedRetryable reports whether err, or an error it wraps, was marked Retryable
+func isMarkedRetryable(err error) bool {
+	var retryable *RetryableError
+	return stderrors.As(err, &retryable)
+}
@@ -0,0 +1,164 @@
+// internal/errors/metrics.go
+package errors
+
+import (
+	"context"
+	stderrors "errors"
+	"regexp"
+	"sort"
+	"strings"
+	"sync"
+)
+
+// maxTrackedErrors bounds the distinct errors counted; the rest share one entry
+const maxTrackedErrors = 100
+
+const otherErrorsKey = "(other errors)"
+
+// ErrorMetrics summarizes the outcomes of ExecuteWithRecovery since the service was created
+type ErrorMetrics struct {
+	Operations int `json:"operations"` // Calls to ExecuteWithRecovery
+	Succeeded  int `json:"succeeded"`  // Succeeded on the first attempt
+	Retried    int `json:"retried"`    // Succeeded after one or more failed attempts
+	Fallback   int `json:"fallback"`   // Served by a fallback
+	Failed     int `json:"failed"`     // Failed with no fallback
+
+	// Fallbacks counts fallback results by RecoveryResult.FallbackType
+	Fallbacks map[string]int `json:"fallbacks,omitempty"`
+
+	// RecoveryRate is the share of operations that hit an error and still
+	// succeeded, by retry or fallback; zero when none hit an error
+	RecoveryRate float64 `json:"recovery_rate"`
+
+	// TopErrors lists failed attempts by error, most frequent first
+	TopErrors []ErrorCount `json:"top_errors,omitempty"`
+
+	// Categories counts failed attempts by ErrorCategory
+	Categories map[string]int `json:"categories,omitempty"`
+}
+
+// ErrorCount is how many failed attempts ended with one error
+type ErrorCount struct {
+	Error string `json:"error"`
+	Count int    `json:"count"`
+}
+
+// recoveryMetrics accumulates ErrorMetrics
+type recoveryMetrics struct {
+	mu         sync.Mutex
+	metrics    ErrorMetrics
+	fallbacks  map[string]int
+	errors     map[string]int
+	categories map[string]int
+}
+
+func newRecoveryMetrics() *recoveryMetrics {
+	return &recoveryMetrics{
+		fallbacks:  make(map[string]int),
+		errors:     make(map[string]int),
+		categories: make(map[string]int),
+	}
+}
+
+// recordError counts one failed attempt
+func (m *recoveryMetrics) recordError(err error) {
+	key, category := errorKey(err), ErrorCategory(err)
+	m.mu.Lock()
+	defer m.mu.Unlock()
+
+	m.categories[category]++
+	if _, ok := m.errors[key]; !ok && len(m.errors) >= maxTrackedErrors {
+		key = otherErrorsKey
+	}
+	m.errors[key]++
+}
+
+// recordResult counts the outcome of one ExecuteWithRecovery call
+func (m *recoveryMetrics) recordResult(result *RecoveryResult) {
+	m.mu.Lock()
+	defer m.mu.Unlock()
+
+	m.metrics.Operations++
+	switch {
+	case result.UsedFallback:
+		m.metrics.Fallback++
+		m.fallbacks[result.FallbackType]++
+	case result.Success && result.AttemptCount > 1:
+		m.metrics.Retried++
+	case result.Success:
+		m.metrics.Succeeded++
+	default:
+		m.metrics.Failed++
+	}
+}
+
+// snapshot returns the metrics with the rate and top errors filled in
+func (m *recoveryMetrics) snapshot() ErrorMetrics {
+	m.mu.Lock()
+	defer m.mu.Unlock()
+
+	metrics := m.metrics
+	if recovered := metrics.Retried + metrics.Fallback; recovered > 0 {
+		metrics.RecoveryRate = float64(recovered) / float64(recovered+metrics.Failed)
+	}
+	if len(m.fallbacks) > 0 {
+		metrics.Fallbacks = make(map[string]int, len(m.fallbacks))
+		for fallbackType, n := range m.fallbacks {
+			metrics.Fallbacks[fallbackType] = n
+		}
+	}
+	if len(m.categories) > 0 {
+		metrics.Categories = make(map[string]int, len(m.categories))
+		for category, n := range m.categories {
+			metrics.Categories[category] = n
+		}
+	}
+	for err, n := range m.errors {
+		metrics.TopErrors = append(metrics.TopErrors, ErrorCount{Error: err, Count: n})
+	}
+	sort.Slice(metrics.TopErrors, func(i, j int) bool {
+		a, b := metrics.TopErrors[i], metrics.TopErrors[j]
+		if a.Count != b.Count {
+			return a.Count > b.Count
+		}
+		return a.Error < b.Error
+	})
+	return metrics
+}
+
+// errorKey groups errors for counting: network errors by kind, the rest by
+// the first line of their message
+func errorKey(err error) string {
+	if kind := ClassifyNetworkError(err); kind != NetworkErrorNone {
+		return kind.String() + " error"
+	}
+	msg, _, _ := strings.Cut(err.Error(), "\n")
+	return msg
+}
+
+// httpStatusPattern finds the status code in errors such as "HTTP error 503: ..."
+var httpStatusPattern = regexp.MustCompile(`\bHTTP (?:error )?([1-5])\d\d\b`)
+
+// ErrorCategory sorts an error into a small fixed set, for labels that must
+// not grow with the variety of error messages: dns, connection, timeout,
+// canceled, http_4xx, http_5xx (by the first status digit) or other
+func ErrorCategory(err error) string {
+	switch {
+	case stderrors.Is(err, context.DeadlineExceeded): // Before the network check, which counts it as a connection timeout
+		return "timeout"
+	case stderrors.Is(err, context.Canceled):
+		return "canceled"
+	}
+	if kind := ClassifyNetworkError(err); kind != NetworkErrorNone {
+		return kind.String()
+	}
+	if match := httpStatusPattern.FindStringSubmatch(err.Error()); match != nil {
+		return "http_" + match[1] + "xx"
+	}
+	return "other"
+}
+
+// GetErrorMetrics returns the retry, fallback and failure counts of ExecuteWithRecovery
+func (s *Service) GetErrorMetrics() ErrorMetrics {
+	return s.metrics.snapshot()
+}
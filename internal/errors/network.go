@@ -0,0 +1,57 @@
+// internal/errors/network.go
+package errors
+
+import (
+	stderrors "errors"
+	"net"
+	"syscall"
+)
+
+// NetworkErrorKind separates network failures by how likely a retry is to help
+type NetworkErrorKind int
+
+const (
+	NetworkErrorNone       NetworkErrorKind = iota // Not a recognised network error
+	NetworkErrorDNS                                // The host name does not resolve; usually permanent
+	NetworkErrorConnection                         // Reset, refused or timed out connections; usually transient
+)
+
+func (k NetworkErrorKind) String() string {
+	switch k {
+	case NetworkErrorDNS:
+		return "dns"
+	case NetworkErrorConnection:
+		return "connection"
+	default:
+		return "none"
+	}
+}
+
+// ClassifyNetworkError inspects the error chain for *net.DNSError and connection
+// errnos. DNS lookups that timed out or failed temporarily count as connection
+// errors, since only a missing host is worth giving up on.
+func ClassifyNetworkError(err error) NetworkErrorKind {
+	if err == nil {
+		return NetworkErrorNone
+	}
+
+	var dnsErr *net.DNSError
+	if stderrors.As(err, &dnsErr) {
+		if dnsErr.IsTimeout || dnsErr.IsTemporary {
+			return NetworkErrorConnection
+		}
+		return NetworkErrorDNS
+	}
+
+	for _, errno := range []syscall.Errno{syscall.ECONNRESET, syscall.ECONNREFUSED, syscall.ECONNABORTED, syscall.EPIPE} {
+		if stderrors.Is(err, errno) {
+			return NetworkErrorConnection
+		}
+	}
+
+	var netErr net.Error
+	if stderrors.As(err, &netErr) && netErr.Timeout() {
+		return NetworkErrorConnection
+	}
+	return NetworkErrorNone
+}
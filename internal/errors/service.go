@@ -3,7 +3,10 @@ package errors
 
 import (
 	"context"
+	stderrors "errors"
 	"fmt"
+	"math/rand/v2"
+	"net/http"
 	"strings"
 	"sync"
 	"time"
@@ -15,13 +18,29 @@ const (
 	DefaultCircuitBreakerResetTimeout = 60 * time.Second // Default: try again after 60 seconds
 )
 
+// ErrAuth marks failures to establish a session (cookies, tokens) with the target site
+var ErrAuth = stderrors.New("authentication failed")
+
+// ErrQualityGate marks runs whose data fell below output.quality_gate
+var ErrQualityGate = stderrors.New("data quality gate failed")
+
+// ErrPolicy marks URLs skipped because the site's crawl policy (robots.txt) excludes them
+var ErrPolicy = stderrors.New("excluded by crawl policy")
+
+// ErrMaxDuration marks runs stopped because they used up their time budget
+// (--max-duration); it counts as a timeout
+var ErrMaxDuration = stderrors.New("run timeout: max duration reached")
+
 // Service provides comprehensive error recovery capabilities
 type Service struct {
 	retryConfig      RetryConfig
 	failurePolicy    FailurePolicy
 	messageHandler   *MessageHandler
 	circuitBreakers  map[string]*CircuitBreaker
+	breakerConfigs   map[string]CircuitBreakerConfig // Explicit configs, also applied to scoped operations
 	fallbackRegistry *FallbackRegistry
+	metrics          *recoveryMetrics
+	httpClient       *http.Client // Sends api_fallback requests; nil uses http.DefaultClient
 	mu               sync.RWMutex
 }
 
@@ -31,6 +50,67 @@ type RetryConfig struct {
 	BaseDelay     time.Duration `yaml:"base_delay" json:"base_delay"`
 	BackoffFactor float64       `yaml:"backoff_factor" json:"backoff_factor"`
 	MaxDelay      time.Duration `yaml:"max_delay" json:"max_delay"`
+	// DNSRetries caps retries for host names that do not resolve, below MaxRetries;
+	// zero fails them on the first attempt
+	DNSRetries int `yaml:"dns_retries" json:"dns_retries"`
+	// Jitter (0-1) randomizes each delay by up to that fraction either way, so
+	// workers that failed together do not retry together. It is applied after the
+	// MaxDelay cap, so a jittered delay can exceed MaxDelay by up to Jitter*MaxDelay.
+	// Zero keeps the delays deterministic.
+	Jitter float64 `yaml:"jitter" json:"jitter"`
+}
+
+// ExecuteOption adjusts the retry behavior of a single Execute* call
+type ExecuteOption func(*RetryConfig)
+
+// WithRetryConfig overrides the service retry settings for one call.
+// Zero fields keep the service value; use WithMaxRetries(0) to disable retries.
+func WithRetryConfig(override RetryConfig) ExecuteOption {
+	return func(rc *RetryConfig) {
+		if override.MaxRetries > 0 {
+			rc.MaxRetries = override.MaxRetries
+		}
+		if override.BaseDelay > 0 {
+			rc.BaseDelay = override.BaseDelay
+		}
+		if override.BackoffFactor > 0 {
+			rc.BackoffFactor = override.BackoffFactor
+		}
+		if override.MaxDelay > 0 {
+			rc.MaxDelay = override.MaxDelay
+		}
+		if override.Jitter > 0 {
+			rc.Jitter = override.Jitter
+		}
+	}
+}
+
+// WithMaxRetries sets the retry count for one call
+func WithMaxRetries(maxRetries int) ExecuteOption {
+	return func(rc *RetryConfig) {
+		rc.MaxRetries = maxRetries
+	}
+}
+
+// WithMaxDelay caps the backoff delay for one call
+func WithMaxDelay(maxDelay time.Duration) ExecuteOption {
+	return func(rc *RetryConfig) {
+		rc.MaxDelay = maxDelay
+	}
+}
+
+// WithDNSRetries sets how often one call retries a host name that does not resolve
+func WithDNSRetries(dnsRetries int) ExecuteOption {
+	return func(rc *RetryConfig) {
+		rc.DNSRetries = dnsRetries
+	}
+}
+
+// WithJitter sets the delay jitter for one call
+func WithJitter(jitter float64) ExecuteOption {
+	return func(rc *RetryConfig) {
+		rc.Jitter = jitter
+	}
 }
 
 // FailurePolicy defines failure handling
@@ -40,6 +120,29 @@ type FailurePolicy struct {
 	SavePartialResults bool    `yaml:"save_partial_results" json:"save_partial_results"`
 }
 
+// Failure policy modes for runs over several items
+const (
+	FailureModeStop     = "stop"     // The first failure fails the run
+	FailureModeContinue = "continue" // Failures are skipped and reported
+	FailureModePartial  = "partial"  // Failures are skipped; the run fails above MaxErrorRate
+)
+
+// Exceeded reports whether failed of total items breaks the policy: any failure
+// in stop mode, a failure rate above MaxErrorRate in partial mode, never in continue
+func (p FailurePolicy) Exceeded(failed, total int) bool {
+	if failed == 0 || total == 0 {
+		return false
+	}
+	switch p.Mode {
+	case FailureModeStop:
+		return true
+	case FailureModeContinue:
+		return false
+	default:
+		return float64(failed)/float64(total) > p.MaxErrorRate
+	}
+}
+
 // MessageHandler converts technical errors to user-friendly messages
 type MessageHandler struct {
 	showTechnical bool
@@ -54,6 +157,17 @@ const (
 	CircuitHalfOpen
 )
 
+func (s CircuitBreakerState) String() string {
+	switch s {
+	case CircuitOpen:
+		return "open"
+	case CircuitHalfOpen:
+		return "half-open"
+	default:
+		return "closed"
+	}
+}
+
 // CircuitBreaker implements circuit breaker pattern for error recovery
 type CircuitBreaker struct {
 	name            string
@@ -83,6 +197,21 @@ const (
 	FallbackDegrade
 )
 
+func (fs FallbackStrategy) String() string {
+	switch fs {
+	case FallbackCached:
+		return "cached"
+	case FallbackDefault:
+		return "default"
+	case FallbackAlternative:
+		return "alternative"
+	case FallbackDegrade:
+		return "degrade"
+	default:
+		return "none"
+	}
+}
+
 // FallbackConfig configures fallback behavior
 type FallbackConfig struct {
 	Strategy     FallbackStrategy       `yaml:"strategy" json:"strategy"`
@@ -90,6 +219,27 @@ type FallbackConfig struct {
 	DefaultValue interface{}            `yaml:"default_value" json:"default_value"`
 	Alternative  string                 `yaml:"alternative" json:"alternative"`
 	Degraded     map[string]interface{} `yaml:"degraded" json:"degraded"`
+
+	// APIURL is the endpoint the api_fallback alternative GETs; {url}, {host}
+	// and {path} are filled from the page URL recorded with WithPageURL.
+	// APITimeout bounds the request (default DefaultAPIFallbackTimeout) and
+	// Headers are sent with it.
+	APIURL     string        `yaml:"api_url,omitempty" json:"api_url,omitempty"`
+	APITimeout time.Duration `yaml:"api_timeout,omitempty" json:"api_timeout,omitempty"`
+	Headers    http.Header   `yaml:"-" json:"-"`
+
+	// Chain lists fallbacks tried in order until one succeeds, e.g. cached, then
+	// an alternative, then degraded. When set, the fields above are unused.
+	Chain []FallbackConfig `yaml:"chain,omitempty" json:"chain,omitempty"`
+}
+
+// label names the fallback for RecoveryResult.FallbackType, including the
+// alternative so chains with several alternatives stay distinguishable
+func (fc FallbackConfig) label() string {
+	if fc.Strategy == FallbackAlternative && fc.Alternative != "" {
+		return fc.Strategy.String() + ":" + fc.Alternative
+	}
+	return fc.Strategy.String()
 }
 
 // FallbackRegistry manages fallback strategies for different operations
@@ -116,6 +266,14 @@ type RecoveryResult struct {
 	Result        interface{}
 }
 
+// RetryResult reports the outcome of ExecuteWithRetryResult
+type RetryResult struct {
+	Attempts   int           // Number of times the operation was invoked
+	LastDelay  time.Duration // Delay before the final attempt (zero if it ran first time)
+	TotalDelay time.Duration // Total time spent waiting between attempts
+	Err        error         // Final error, nil on success
+}
+
 // NewService creates a new comprehensive error recovery service
 func NewService() *Service {
 	return &Service{
@@ -132,10 +290,30 @@ func NewService() *Service {
 		},
 		messageHandler:   &MessageHandler{showTechnical: false},
 		circuitBreakers:  make(map[string]*CircuitBreaker),
+		breakerConfigs:   make(map[string]CircuitBreakerConfig),
 		fallbackRegistry: NewFallbackRegistry(),
+		metrics:          newRecoveryMetrics(),
 	}
 }
 
+// ScopedOperation names an operation instance, e.g. fetch_document:example.com.
+// Scoped operations get their own circuit breaker and cached fallback, but use the
+// circuit breaker and fallback configured for the unscoped operation name.
+func ScopedOperation(operation, scope string) string {
+	if scope == "" {
+		return operation
+	}
+	return operation + ":" + scope
+}
+
+// baseOperation strips the scope added by ScopedOperation
+func baseOperation(operationName string) string {
+	if i := strings.Index(operationName, ":"); i >= 0 {
+		return operationName[:i]
+	}
+	return operationName
+}
+
 // NewFallbackRegistry creates a new fallback registry
 func NewFallbackRegistry() *FallbackRegistry {
 	return &FallbackRegistry{
@@ -144,51 +322,85 @@ func NewFallbackRegistry() *FallbackRegistry {
 	}
 }
 
+// FailurePolicy returns how runs over several items treat failed items
+func (s *Service) FailurePolicy() FailurePolicy {
+	return s.failurePolicy
+}
+
+// WithFailurePolicy replaces the failure policy
+func (s *Service) WithFailurePolicy(policy FailurePolicy) *Service {
+	s.failurePolicy = policy
+	return s
+}
+
+// WithHTTPClient sets the client api_fallback requests are sent with, so they
+// share the caller's transport, proxy and TLS settings
+func (s *Service) WithHTTPClient(client *http.Client) *Service {
+	s.httpClient = client
+	return s
+}
+
 // WithVerbose enables technical error details
 func (s *Service) WithVerbose(verbose bool) *Service {
 	s.messageHandler.showTechnical = verbose
 	return s
 }
 
-// ExecuteWithRetry adds retry logic to existing functions
-func (s *Service) ExecuteWithRetry(ctx context.Context, operation func() error, operationName string) error {
+// ExecuteWithRetry adds retry logic to existing functions. Backoff state lives in
+// the call, so each operation starts again from the base delay.
+func (s *Service) ExecuteWithRetry(ctx context.Context, operation func() error, operationName string, opts ...ExecuteOption) error {
+	return s.ExecuteWithRetryResult(ctx, operation, operationName, opts...).Err
+}
+
+// ExecuteWithRetryResult executes an operation with retry logic and reports how
+// many attempts were made and how long the service waited between them
+func (s *Service) ExecuteWithRetryResult(ctx context.Context, operation func() error, operationName string, opts ...ExecuteOption) *RetryResult {
+	retryConfig := s.retryConfigFor(opts)
+	result := &RetryResult{}
 	var lastErr error
 
-	for attempt := 0; attempt <= s.retryConfig.MaxRetries; attempt++ {
+	for attempt := 0; attempt <= retryConfig.MaxRetries; attempt++ {
+		result.Attempts++
 		err := operation()
 		if err == nil {
-			return nil
+			return result
 		}
 
 		lastErr = err
 
 		// Check if should retry
-		if !s.shouldRetry(err, attempt) {
+		if !retryConfig.retryable(err, attempt) {
 			break
 		}
 
 		// Calculate delay
-		delay := s.calculateDelay(attempt)
+		delay := retryConfig.delay(attempt)
 
 		select {
 		case <-ctx.Done():
-			return ctx.Err()
+			result.Err = ctx.Err()
+			return result
 		case <-time.After(delay):
+			result.LastDelay = delay
+			result.TotalDelay += delay
 			continue
 		}
 	}
 
-	return fmt.Errorf("operation %s failed after %d attempts: %w", operationName, s.retryConfig.MaxRetries+1, lastErr)
+	result.Err = fmt.Errorf("operation %s failed after %d attempts: %w", operationName, result.Attempts, lastErr)
+	return result
 }
 
 // ExecuteWithRecovery executes an operation with comprehensive error recovery
-func (s *Service) ExecuteWithRecovery(ctx context.Context, operationName string, operation func() (interface{}, error)) *RecoveryResult {
+func (s *Service) ExecuteWithRecovery(ctx context.Context, operationName string, operation func() (interface{}, error), opts ...ExecuteOption) *RecoveryResult {
+	retryConfig := s.retryConfigFor(opts)
 	startTime := time.Now()
 	result := &RecoveryResult{
 		Success:      false,
 		UsedFallback: false,
 		AttemptCount: 0,
 	}
+	defer s.metrics.recordResult(result)
 
 	// Check circuit breaker first
 	circuitBreaker := s.getOrCreateCircuitBreaker(operationName)
@@ -197,10 +409,10 @@ func (s *Service) ExecuteWithRecovery(ctx context.Context, operationName string,
 		result.RecoveryTime = time.Since(startTime)
 
 		// Try fallback
-		if fallbackResult, err := s.executeFallback(operationName); err == nil {
+		if fallbackResult, step, err := s.executeFallback(ctx, operationName); err == nil {
 			result.Success = true
 			result.UsedFallback = true
-			result.FallbackType = "circuit_breaker_fallback"
+			result.FallbackType = fallbackType("circuit_breaker_fallback", step)
 			result.Result = fallbackResult
 		}
 		return result
@@ -208,7 +420,7 @@ func (s *Service) ExecuteWithRecovery(ctx context.Context, operationName string,
 
 	// Execute with retry logic
 	var lastErr error
-	for attempt := 0; attempt <= s.retryConfig.MaxRetries; attempt++ {
+	for attempt := 0; attempt <= retryConfig.MaxRetries; attempt++ {
 		result.AttemptCount++
 
 		data, err := operation()
@@ -225,14 +437,15 @@ func (s *Service) ExecuteWithRecovery(ctx context.Context, operationName string,
 
 		lastErr = err
 		circuitBreaker.RecordFailure()
+		s.metrics.recordError(err)
 
 		// Check if should retry
-		if !s.shouldRetry(err, attempt) {
+		if !retryConfig.retryable(err, attempt) {
 			break
 		}
 
 		// Calculate delay
-		delay := s.calculateDelay(attempt)
+		delay := retryConfig.delay(attempt)
 
 		select {
 		case <-ctx.Done():
@@ -246,10 +459,10 @@ func (s *Service) ExecuteWithRecovery(ctx context.Context, operationName string,
 
 	// All retries failed, try fallback
 	result.OriginalError = lastErr
-	if fallbackResult, err := s.executeFallback(operationName); err == nil {
+	if fallbackResult, step, err := s.executeFallback(ctx, operationName); err == nil {
 		result.Success = true
 		result.UsedFallback = true
-		result.FallbackType = "retry_exhausted_fallback"
+		result.FallbackType = fallbackType("retry_exhausted_fallback", step)
 		result.Result = fallbackResult
 	}
 
@@ -266,11 +479,18 @@ func (s *Service) getOrCreateCircuitBreaker(operationName string) *CircuitBreake
 		return cb
 	}
 
-	// Create new circuit breaker with default config
+	// Create new circuit breaker, inheriting the unscoped operation's config if any
+	config, configured := s.breakerConfigs[baseOperation(operationName)]
+	if !configured {
+		config = CircuitBreakerConfig{
+			MaxFailures:  DefaultCircuitBreakerMaxFailures,
+			ResetTimeout: DefaultCircuitBreakerResetTimeout,
+		}
+	}
 	cb := &CircuitBreaker{
 		name:         operationName,
-		maxFailures:  DefaultCircuitBreakerMaxFailures,
-		resetTimeout: DefaultCircuitBreakerResetTimeout,
+		maxFailures:  config.MaxFailures,
+		resetTimeout: config.ResetTimeout,
 		state:        CircuitClosed,
 	}
 
@@ -291,6 +511,7 @@ func (s *Service) ConfigureCircuitBreaker(operationName string, config CircuitBr
 	}
 
 	s.circuitBreakers[operationName] = cb
+	s.breakerConfigs[operationName] = config
 }
 
 // ConfigureFallback configures fallback strategy for specific operation
@@ -300,16 +521,47 @@ func (s *Service) ConfigureFallback(operationName string, config FallbackConfig)
 	s.fallbackRegistry.strategies[operationName] = config
 }
 
-// executeFallback attempts to execute fallback strategy
-func (s *Service) executeFallback(operationName string) (interface{}, error) {
+// fallbackType is the RecoveryResult.FallbackType for a fallback run after trigger;
+// step names the chain link that produced the result, or is empty without a chain
+func fallbackType(trigger, step string) string {
+	if step == "" {
+		return trigger
+	}
+	return trigger + "/" + step
+}
+
+// executeFallback attempts to execute the operation's fallback. For a chain it
+// tries each link in order and also returns the label of the one that succeeded.
+func (s *Service) executeFallback(ctx context.Context, operationName string) (interface{}, string, error) {
 	s.fallbackRegistry.mu.RLock()
 	config, exists := s.fallbackRegistry.strategies[operationName]
+	if !exists {
+		config, exists = s.fallbackRegistry.strategies[baseOperation(operationName)]
+	}
 	s.fallbackRegistry.mu.RUnlock()
 
 	if !exists {
 		config = FallbackConfig{Strategy: FallbackNone}
 	}
 
+	if len(config.Chain) == 0 {
+		data, err := s.runFallback(ctx, operationName, config)
+		return data, "", err
+	}
+
+	var errs []string
+	for _, step := range config.Chain {
+		data, err := s.runFallback(ctx, operationName, step)
+		if err == nil {
+			return data, step.label(), nil
+		}
+		errs = append(errs, fmt.Sprintf("%s: %v", step.label(), err))
+	}
+	return nil, "", fmt.Errorf("fallback chain exhausted for operation %s: %s", operationName, strings.Join(errs, "; "))
+}
+
+// runFallback executes a single fallback strategy
+func (s *Service) runFallback(ctx context.Context, operationName string, config FallbackConfig) (interface{}, error) {
 	switch config.Strategy {
 	case FallbackCached:
 		return s.getCachedResult(operationName, config.CacheTimeout)
@@ -320,9 +572,7 @@ func (s *Service) executeFallback(operationName string) (interface{}, error) {
 		return nil, fmt.Errorf("no default value configured for operation: %s", operationName)
 	case FallbackAlternative:
 		if config.Alternative != "" {
-			// Execute alternative operation - this is a placeholder that should be
-			// replaced with actual alternative logic in production implementations
-			return s.executeAlternativeOperation(operationName, config.Alternative)
+			return s.executeAlternativeOperation(ctx, operationName, config)
 		}
 		return nil, fmt.Errorf("no alternative configured for operation: %s", operationName)
 	case FallbackDegrade:
@@ -363,16 +613,12 @@ func (s *Service) getCachedResult(operationName string, maxAge time.Duration) (i
 	return cached.Data, nil
 }
 
-// executeAlternativeOperation executes an alternative operation strategy
-// This is a framework method that should be extended for specific use cases
-func (s *Service) executeAlternativeOperation(operationName, alternative string) (interface{}, error) {
-	// This method provides a framework for alternative operation execution
-	// In production implementations, this should be extended to:
-	// 1. Route to alternative endpoints/services
-	// 2. Use different scraping methods (e.g., mobile version, API instead of HTML)
-	// 3. Switch to backup data sources
-	// 4. Apply different extraction strategies
-
+// executeAlternativeOperation executes an alternative operation strategy.
+// api_fallback fetches the data from a JSON API; the other alternatives are a
+// framework to be extended for specific use cases (mobile versions, backup
+// data sources, other extraction strategies).
+func (s *Service) executeAlternativeOperation(ctx context.Context, operationName string, config FallbackConfig) (interface{}, error) {
+	alternative := config.Alternative
 	switch alternative {
 	case "mobile_version":
 		return map[string]interface{}{
@@ -381,11 +627,7 @@ func (s *Service) executeAlternativeOperation(operationName, alternative string)
 			"operation": operationName,
 		}, nil
 	case "api_fallback":
-		return map[string]interface{}{
-			"source":    "api_fallback",
-			"message":   "Using API as fallback to HTML scraping",
-			"operation": operationName,
-		}, nil
+		return s.executeAPIFallback(ctx, operationName, config)
 	case "cached_alternative":
 		// Try to get alternative cached data
 		return s.getCachedResult(alternative+"_"+operationName, time.Hour)
@@ -399,15 +641,45 @@ func (s *Service) executeAlternativeOperation(operationName, alternative string)
 	}
 }
 
+// retryConfigFor applies per-call options to a copy of the service retry config
+func (s *Service) retryConfigFor(opts []ExecuteOption) RetryConfig {
+	retryConfig := s.retryConfig
+	for _, opt := range opts {
+		opt(&retryConfig)
+	}
+	return retryConfig
+}
+
 // shouldRetry determines if error is retryable
 func (s *Service) shouldRetry(err error, attempt int) bool {
-	if attempt >= s.retryConfig.MaxRetries {
+	return s.retryConfig.retryable(err, attempt)
+}
+
+// retryable reports whether the attempt that failed with err should be followed
+// by another. Errors marked Permanent or Retryable decide for themselves;
+// unresolvable hosts get DNSRetries instead of MaxRetries.
+func (rc RetryConfig) retryable(err error, attempt int) bool {
+	if IsPermanent(err) {
 		return false
 	}
+	if isMarkedRetryable(err) {
+		return attempt < rc.MaxRetries
+	}
+	switch ClassifyNetworkError(err) {
+	case NetworkErrorDNS:
+		return attempt < rc.DNSRetries && attempt < rc.MaxRetries
+	case NetworkErrorConnection:
+		return attempt < rc.MaxRetries
+	}
+	return attempt < rc.MaxRetries && isRetryableError(err)
+}
 
+// isRetryableError reports whether the message of err looks transient; typed
+// network errors are classified by ClassifyNetworkError first
+func isRetryableError(err error) bool {
 	errStr := strings.ToLower(err.Error())
 	retryableErrors := []string{
-		"timeout", "connection refused", "no such host",
+		"timeout", "connection refused", "connection reset",
 		"500", "502", "503", "504", "429",
 		"temporary", "service unavailable",
 	}
@@ -421,13 +693,29 @@ func (s *Service) shouldRetry(err error, attempt int) bool {
 	return false
 }
 
-// calculateDelay computes exponential backoff delay
-func (s *Service) calculateDelay(attempt int) time.Duration {
-	delay := time.Duration(float64(s.retryConfig.BaseDelay) * pow(s.retryConfig.BackoffFactor, float64(attempt)))
-	if delay > s.retryConfig.MaxDelay {
-		delay = s.retryConfig.MaxDelay
+// delay computes the exponential backoff before the retry following attempt
+func (rc RetryConfig) delay(attempt int) time.Duration {
+	delay := time.Duration(float64(rc.BaseDelay) * pow(rc.BackoffFactor, float64(attempt)))
+	if delay > rc.MaxDelay {
+		delay = rc.MaxDelay
+	}
+	return jitterDelay(delay, rc.Jitter)
+}
+
+// jitterRand returns a random float in [0, 1); tests replace it
+var jitterRand = rand.Float64
+
+// jitterDelay scales delay by a random factor in [1-jitter, 1+jitter]; jitter
+// is clamped to 0-1
+func jitterDelay(delay time.Duration, jitter float64) time.Duration {
+	if jitter <= 0 || delay <= 0 {
+		return delay
 	}
-	return delay
+	if jitter > 1 {
+		jitter = 1
+	}
+	factor := 1 + jitter*(2*jitterRand()-1)
+	return time.Duration(float64(delay) * factor)
 }
 
 // GetUserFriendlyError converts technical errors to user-friendly messages
@@ -436,6 +724,46 @@ func (s *Service) GetUserFriendlyError(err error) (title, message string, sugges
 		return "", "", nil
 	}
 
+	if stderrors.Is(err, ErrAuth) {
+		return "Authentication Failed",
+			"Could not establish a session with the website.",
+			[]string{
+				"Check that the warmup URLs are reachable in a browser",
+				"The site may require a login or block automated clients",
+				"Try a different user agent or proxy",
+			}
+	}
+
+	if stderrors.Is(err, ErrPolicy) {
+		return "Blocked by Crawl Policy",
+			"The website's robots.txt does not allow scraping this URL, so it was not requested.",
+			[]string{
+				"Check the site's robots.txt for the paths that are allowed",
+				"Ask the site owner for permission or use an official API",
+				"robots.txt is only enforced when respect_robots_txt is set",
+			}
+	}
+
+	if stderrors.Is(err, ErrQualityGate) {
+		return "Data Quality Gate Failed",
+			"The scraped data is below the configured quality bar; the output was still written.",
+			[]string{
+				"Check whether the site layout changed and selectors no longer match",
+				"Inspect the output file or enable output.enable_metrics for per-field fill rates",
+				"Adjust output.quality_gate if the thresholds are too strict",
+			}
+	}
+
+	if stderrors.Is(err, ErrMaxDuration) {
+		return "Run Timeout",
+			"The run used up its time budget and stopped; records scraped so far were saved unless failure_policy.save_partial_results is false.",
+			[]string{
+				"Raise --max-duration if the run needs more time",
+				"Set concurrency to scrape several URLs at once",
+				"Use a checkpoint so the next run resumes where this one stopped",
+			}
+	}
+
 	errStr := strings.ToLower(err.Error())
 
 	// Network errors
@@ -517,6 +845,18 @@ func (s *Service) GetExitCode(err error) int {
 	if err == nil {
 		return 0
 	}
+	if stderrors.Is(err, ErrAuth) {
+		return 8 // Authentication error
+	}
+	if stderrors.Is(err, ErrQualityGate) {
+		return 9 // Data quality error
+	}
+	if stderrors.Is(err, ErrPolicy) {
+		return 10 // Crawl policy error
+	}
+	if stderrors.Is(err, ErrMaxDuration) {
+		return 11 // Run time budget used up
+	}
 
 	errStr := strings.ToLower(err.Error())
 
@@ -618,6 +958,18 @@ func (cb *CircuitBreaker) RecordFailure() {
 	}
 }
 
+// Reset closes the breaker and forgets every recorded failure, so it takes a
+// fresh maxFailures failures to open again. Resetting twice is harmless.
+func (cb *CircuitBreaker) Reset() {
+	cb.mu.Lock()
+	defer cb.mu.Unlock()
+
+	cb.state = CircuitClosed
+	cb.failures = 0
+	cb.lastFailureTime = time.Time{}
+	cb.nextAttemptTime = time.Time{}
+}
+
 // GetState returns current circuit breaker state
 func (cb *CircuitBreaker) GetState() CircuitBreakerState {
 	cb.mu.RLock()
@@ -655,24 +1007,43 @@ func (s *Service) GetCircuitBreakerStats() map[string]interface{} {
 	return stats
 }
 
-// ResetCircuitBreaker manually resets a circuit breaker
-func (s *Service) ResetCircuitBreaker(operationName string) error {
-	s.mu.Lock()
-	defer s.mu.Unlock()
+// CircuitBreakerStates returns the state of every circuit breaker by operation
+func (s *Service) CircuitBreakerStates() map[string]CircuitBreakerState {
+	s.mu.RLock()
+	defer s.mu.RUnlock()
+
+	states := make(map[string]CircuitBreakerState, len(s.circuitBreakers))
+	for name, cb := range s.circuitBreakers {
+		states[name] = cb.GetState()
+	}
+	return states
+}
 
+// ResetCircuitBreaker manually resets a circuit breaker, clearing its failure history
+func (s *Service) ResetCircuitBreaker(operationName string) error {
+	s.mu.RLock()
 	cb, exists := s.circuitBreakers[operationName]
+	s.mu.RUnlock()
 	if !exists {
 		return fmt.Errorf("circuit breaker not found for operation: %s", operationName)
 	}
 
-	cb.mu.Lock()
-	cb.failures = 0
-	cb.state = CircuitClosed
-	cb.mu.Unlock()
-
+	cb.Reset()
 	return nil
 }
 
+// ResetAll closes every circuit breaker the service has created, clearing their
+// failure counts, and returns how many there were
+func (s *Service) ResetAll() int {
+	s.mu.RLock()
+	defer s.mu.RUnlock()
+
+	for _, cb := range s.circuitBreakers {
+		cb.Reset()
+	}
+	return len(s.circuitBreakers)
+}
+
 // ClearCache clears all cached fallback results
 func (s *Service) ClearCache() {
 	s.fallbackRegistry.mu.Lock()
@@ -4,7 +4,14 @@ package errors
 import (
 	"context"
 	"fmt"
+	"io"
+	"net"
+	"net/http"
+	"net/http/httptest"
+	"net/url"
+	"os"
 	"strings"
+	"syscall"
 	"testing"
 	"time"
 )
@@ -98,6 +105,44 @@ func TestService_ExecuteWithRecovery_FallbackUsed(t *testing.T) {
 	}
 }
 
+func TestService_ExecuteWithRecovery_FallbackChain(t *testing.T) {
+	service := NewService()
+	ctx := context.Background()
+
+	// Nothing is cached yet, so the chain moves on to the mobile alternative
+	service.ConfigureFallback("chain_test", FallbackConfig{
+		Chain: []FallbackConfig{
+			{Strategy: FallbackCached},
+			{Strategy: FallbackAlternative, Alternative: "mobile_version"},
+			{Strategy: FallbackDegrade},
+		},
+	})
+
+	operation := func() (interface{}, error) {
+		return nil, fmt.Errorf("persistent error")
+	}
+
+	result := service.ExecuteWithRecovery(ctx, "chain_test", operation, WithMaxRetries(0))
+	if !result.Success || !result.UsedFallback {
+		t.Fatalf("Expected operation to succeed via the fallback chain, got %+v", result)
+	}
+	if result.FallbackType != "retry_exhausted_fallback/alternative:mobile_version" {
+		t.Errorf("Expected the alternative step to be recorded, got %s", result.FallbackType)
+	}
+	if data, _ := result.Result.(map[string]interface{}); data["source"] != "mobile_fallback" {
+		t.Errorf("Expected the mobile fallback result, got %v", result.Result)
+	}
+
+	// A chain where every step fails leaves the operation failed
+	service.ConfigureFallback("exhausted_chain", FallbackConfig{
+		Chain: []FallbackConfig{{Strategy: FallbackCached}, {Strategy: FallbackDefault}},
+	})
+	result = service.ExecuteWithRecovery(ctx, "exhausted_chain", operation, WithMaxRetries(0))
+	if result.Success || result.UsedFallback {
+		t.Errorf("Expected an exhausted chain to fail, got %+v", result)
+	}
+}
+
 func TestService_ExecuteWithRecovery_CachedFallback(t *testing.T) {
 	service := NewService()
 	ctx := context.Background()
@@ -380,6 +425,36 @@ func TestService_ResetCircuitBreaker(t *testing.T) {
 	}
 }
 
+func TestService_ResetAll(t *testing.T) {
+	service := NewService()
+	ctx := context.Background()
+
+	failOperation := func() (interface{}, error) {
+		return nil, fmt.Errorf("error")
+	}
+	for _, name := range []string{"reset_all_a", "reset_all_b"} {
+		service.ConfigureCircuitBreaker(name, CircuitBreakerConfig{MaxFailures: 1, ResetTimeout: time.Hour})
+		service.ExecuteWithRecovery(ctx, name, failOperation, WithMaxRetries(0))
+	}
+
+	if reset := service.ResetAll(); reset != 2 {
+		t.Errorf("Expected 2 circuit breakers reset, got %d", reset)
+	}
+	for name, stats := range service.GetCircuitBreakerStats() {
+		s := stats.(map[string]interface{})
+		if s["state"] != CircuitClosed || s["failures"] != 0 {
+			t.Errorf("Expected %s closed with no failures, got %v", name, s)
+		}
+	}
+
+	successOperation := func() (interface{}, error) {
+		return "success", nil
+	}
+	if result := service.ExecuteWithRecovery(ctx, "reset_all_a", successOperation); !result.Success {
+		t.Error("Expected operation to succeed after resetting all circuit breakers")
+	}
+}
+
 func TestService_ContextCancellation(t *testing.T) {
 	service := NewService()
 	ctx, cancel := context.WithTimeout(context.Background(), 50*time.Millisecond)
@@ -424,7 +499,7 @@ func TestService_AlternativeOperation_Strategies(t *testing.T) {
 			name:        "api_fallback",
 			operation:   "test_op",
 			alternative: "api_fallback",
-			expectError: false,
+			expectError: true, // No api_url configured
 		},
 		{
 			name:        "cached_alternative",
@@ -442,7 +517,7 @@ func TestService_AlternativeOperation_Strategies(t *testing.T) {
 
 	for _, tc := range testCases {
 		t.Run(tc.name, func(t *testing.T) {
-			result, err := service.executeAlternativeOperation(tc.operation, tc.alternative)
+			result, err := service.executeAlternativeOperation(context.Background(), tc.operation, FallbackConfig{Alternative: tc.alternative})
 
 			if tc.expectError && err == nil {
 				t.Error("Expected error but got none")
@@ -457,6 +532,118 @@ func TestService_AlternativeOperation_Strategies(t *testing.T) {
 	}
 }
 
+func TestService_APIFallback(t *testing.T) {
+	var gotPath, gotQuery, gotAuth, gotAccept string
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		switch r.URL.Path {
+		case "/missing":
+			http.NotFound(w, r)
+			return
+		case "/slow":
+			time.Sleep(200 * time.Millisecond)
+		}
+		gotPath, gotQuery = r.URL.Path, r.URL.Query().Get("page")
+		gotAuth, gotAccept = r.Header.Get("Authorization"), r.Header.Get("Accept")
+		w.Header().Set("Content-Type", "application/json")
+		fmt.Fprint(w, `{"title":"From the API","price":9.5}`)
+	}))
+	defer server.Close()
+
+	service := NewService()
+	pageURL := "https://shop.example.com/items/42?color=red"
+	ctx := WithPageURL(context.Background(), pageURL)
+	operation := func() (interface{}, error) {
+		return nil, fmt.Errorf("persistent error")
+	}
+
+	service.ConfigureFallback("api_test", FallbackConfig{
+		Strategy:    FallbackAlternative,
+		Alternative: "api_fallback",
+		APIURL:      server.URL + "/api{path}?page={url}",
+		Headers:     http.Header{"Authorization": {"Bearer token"}},
+	})
+	result := service.ExecuteWithRecovery(ctx, "api_test", operation, WithMaxRetries(0))
+	if !result.Success || !result.UsedFallback {
+		t.Fatalf("Expected the API fallback to succeed, got %+v", result)
+	}
+	data, _ := result.Result.(map[string]interface{})
+	if data["title"] != "From the API" || data["price"] != 9.5 {
+		t.Errorf("Expected the decoded API response, got %v", result.Result)
+	}
+	if gotPath != "/api/items/42" || gotQuery != pageURL {
+		t.Errorf("Expected {path} and {url} filled from the page URL, got path %q and page %q", gotPath, gotQuery)
+	}
+	if gotAuth != "Bearer token" || gotAccept != "application/json" {
+		t.Errorf("Expected the configured headers and a JSON Accept, got %q and %q", gotAuth, gotAccept)
+	}
+
+	// A 404 fails the step, so a chain moves on to the next one
+	service.ConfigureFallback("api_missing", FallbackConfig{
+		Chain: []FallbackConfig{
+			{Strategy: FallbackAlternative, Alternative: "api_fallback", APIURL: server.URL + "/missing"},
+			{Strategy: FallbackDefault, DefaultValue: "default"},
+		},
+	})
+	result = service.ExecuteWithRecovery(ctx, "api_missing", operation, WithMaxRetries(0))
+	if !result.Success || result.Result != "default" {
+		t.Errorf("Expected the chain to fall through a 404 to the default, got %+v", result)
+	}
+
+	// A slow API is abandoned after api_timeout
+	service.ConfigureFallback("api_slow", FallbackConfig{
+		Strategy:    FallbackAlternative,
+		Alternative: "api_fallback",
+		APIURL:      server.URL + "/slow",
+		APITimeout:  20 * time.Millisecond,
+	})
+	result = service.ExecuteWithRecovery(ctx, "api_slow", operation, WithMaxRetries(0))
+	if result.Success || result.UsedFallback {
+		t.Errorf("Expected the API fallback to time out, got %+v", result)
+	}
+
+	// Placeholders need the page URL
+	if _, err := apiFallbackURL("https://api.example.com/?u={url}", ""); err == nil {
+		t.Error("Expected an error for a templated api_url without a page URL")
+	}
+	if got, _ := apiFallbackURL("https://api.example.com/{host}", pageURL); got != "https://api.example.com/shop.example.com" {
+		t.Errorf("Expected {host} filled from the page URL, got %s", got)
+	}
+}
+
+// roundTripFunc adapts a function to http.RoundTripper
+type roundTripFunc func(*http.Request) (*http.Response, error)
+
+func (f roundTripFunc) RoundTrip(req *http.Request) (*http.Response, error) { return f(req) }
+
+func TestService_APIFallbackUsesHTTPClient(t *testing.T) {
+	var sent []string
+	client := &http.Client{Transport: roundTripFunc(func(req *http.Request) (*http.Response, error) {
+		sent = append(sent, req.URL.String())
+		return &http.Response{
+			StatusCode: http.StatusOK,
+			Header:     http.Header{"Content-Type": {"application/json"}},
+			Body:       io.NopCloser(strings.NewReader(`{"title":"Through the client"}`)),
+			Request:    req,
+		}, nil
+	})}
+
+	service := NewService().WithHTTPClient(client)
+	service.ConfigureFallback("api_client", FallbackConfig{
+		Strategy:    FallbackAlternative,
+		Alternative: "api_fallback",
+		APIURL:      "https://api.example.invalid/items",
+	})
+	result := service.ExecuteWithRecovery(context.Background(), "api_client", func() (interface{}, error) {
+		return nil, fmt.Errorf("persistent error")
+	}, WithMaxRetries(0))
+	if !result.Success || len(sent) != 1 || sent[0] != "https://api.example.invalid/items" {
+		t.Fatalf("Expected the API fallback to go through the injected client, got %+v after %v", result, sent)
+	}
+	if data, _ := result.Result.(map[string]interface{}); data["title"] != "Through the client" {
+		t.Errorf("Expected the client's response, got %v", result.Result)
+	}
+}
+
 func TestService_DefaultCircuitBreakerConfiguration(t *testing.T) {
 	service := NewService()
 
@@ -551,3 +738,357 @@ func BenchmarkService_ExecuteWithRecovery_WithFallback(b *testing.B) {
 		service.ExecuteWithRecovery(ctx, "bench_fallback", operation)
 	}
 }
+
+func TestService_ExecuteWithRetryResult(t *testing.T) {
+	service := NewService()
+	service.retryConfig.BaseDelay = time.Millisecond
+	service.retryConfig.MaxDelay = 10 * time.Millisecond
+	ctx := context.Background()
+
+	calls := 0
+	result := service.ExecuteWithRetryResult(ctx, func() error {
+		calls++
+		if calls < 3 {
+			return fmt.Errorf("temporary error")
+		}
+		return nil
+	}, "retry_count_test")
+
+	if result.Err != nil {
+		t.Fatalf("Expected success, got %v", result.Err)
+	}
+	if result.Attempts != 3 {
+		t.Errorf("Expected 3 attempts, got %d", result.Attempts)
+	}
+	if result.LastDelay != 2*time.Millisecond {
+		t.Errorf("Expected last delay 2ms, got %v", result.LastDelay)
+	}
+	if result.TotalDelay != 3*time.Millisecond {
+		t.Errorf("Expected total delay 3ms, got %v", result.TotalDelay)
+	}
+
+	// Non-retryable errors stop after the first attempt
+	result = service.ExecuteWithRetryResult(ctx, func() error {
+		return fmt.Errorf("invalid configuration")
+	}, "non_retryable_test")
+
+	if result.Err == nil || result.Attempts != 1 {
+		t.Errorf("Expected single failed attempt, got %d attempts, err %v", result.Attempts, result.Err)
+	}
+	if !strings.Contains(result.Err.Error(), "after 1 attempts") {
+		t.Errorf("Expected error to report actual attempt count, got %v", result.Err)
+	}
+}
+
+func TestService_ScopedOperationsAreIndependent(t *testing.T) {
+	service := NewService()
+	service.retryConfig.BaseDelay = time.Millisecond
+	service.retryConfig.MaxDelay = 10 * time.Millisecond
+	ctx := context.Background()
+
+	// Config for the unscoped name applies to every host-scoped breaker
+	service.ConfigureCircuitBreaker("fetch", CircuitBreakerConfig{MaxFailures: 2, ResetTimeout: time.Hour})
+
+	badHost := ScopedOperation("fetch", "bad.example.com")
+	goodHost := ScopedOperation("fetch", "good.example.com")
+
+	failures := service.ExecuteWithRecovery(ctx, badHost, func() (interface{}, error) {
+		return nil, fmt.Errorf("503 service unavailable")
+	})
+	if failures.Success || failures.AttemptCount != 4 {
+		t.Fatalf("Expected failing host to exhaust 4 attempts, got %+v", failures)
+	}
+
+	blocked := service.ExecuteWithRecovery(ctx, badHost, func() (interface{}, error) {
+		return "ok", nil
+	})
+	if blocked.Success || !strings.Contains(blocked.OriginalError.Error(), "circuit breaker is open") {
+		t.Errorf("Expected breaker for failing host to be open, got %+v", blocked)
+	}
+
+	healthy := service.ExecuteWithRecovery(ctx, goodHost, func() (interface{}, error) {
+		return "ok", nil
+	})
+	if !healthy.Success || healthy.AttemptCount != 1 {
+		t.Errorf("Expected healthy host to succeed first time, got %+v", healthy)
+	}
+
+	stats := service.GetCircuitBreakerStats()
+	if state := stats[goodHost].(map[string]interface{})["state"]; state != CircuitClosed {
+		t.Errorf("Expected healthy host breaker closed, got %v", state)
+	}
+	if maxFailures := stats[badHost].(map[string]interface{})["max_failures"]; maxFailures != 2 {
+		t.Errorf("Expected scoped breaker to inherit max_failures 2, got %v", maxFailures)
+	}
+}
+
+func TestService_PerCallRetryOverrides(t *testing.T) {
+	service := NewService()
+	service.retryConfig.BaseDelay = time.Millisecond
+	service.retryConfig.MaxDelay = 10 * time.Millisecond
+	ctx := context.Background()
+
+	failing := func() error { return fmt.Errorf("503 service unavailable") }
+
+	// Service default: 3 retries, backoff 1ms, 2ms, 4ms
+	result := service.ExecuteWithRetryResult(ctx, failing, "batch")
+	if result.Attempts != 4 || result.TotalDelay != 7*time.Millisecond {
+		t.Errorf("Expected 4 attempts and 7ms total delay, got %d attempts and %v", result.Attempts, result.TotalDelay)
+	}
+
+	// Interactive call: cap the delay and retry less
+	result = service.ExecuteWithRetryResult(ctx, failing, "interactive", WithMaxRetries(2), WithMaxDelay(time.Millisecond))
+	if result.Attempts != 3 || result.TotalDelay != 2*time.Millisecond {
+		t.Errorf("Expected 3 attempts and 2ms total delay, got %d attempts and %v", result.Attempts, result.TotalDelay)
+	}
+
+	// Zero fields in WithRetryConfig keep the service value
+	result = service.ExecuteWithRetryResult(ctx, failing, "partial", WithRetryConfig(RetryConfig{MaxRetries: 1}))
+	if result.Attempts != 2 || result.LastDelay != time.Millisecond {
+		t.Errorf("Expected 2 attempts with 1ms delay, got %d attempts and %v", result.Attempts, result.LastDelay)
+	}
+
+	recovery := service.ExecuteWithRecovery(ctx, "no_retry", func() (interface{}, error) {
+		return nil, fmt.Errorf("503 service unavailable")
+	}, WithMaxRetries(0))
+	if recovery.AttemptCount != 1 {
+		t.Errorf("Expected a single attempt with WithMaxRetries(0), got %d", recovery.AttemptCount)
+	}
+
+	// Overrides never leak into the service config
+	if service.retryConfig.MaxRetries != 3 || service.retryConfig.MaxDelay != 10*time.Millisecond {
+		t.Errorf("Per-call options modified service config: %+v", service.retryConfig)
+	}
+}
+
+func TestService_ResetClearsFailureHistory(t *testing.T) {
+	service := NewService()
+	service.ConfigureCircuitBreaker("history_test", CircuitBreakerConfig{
+		MaxFailures:  3,
+		ResetTimeout: time.Hour,
+	})
+	cb := service.getOrCreateCircuitBreaker("history_test")
+	other := service.getOrCreateCircuitBreaker("other_test")
+
+	for i := 0; i < 3; i++ {
+		cb.RecordFailure()
+	}
+	if cb.GetState() != CircuitOpen {
+		t.Fatalf("Expected breaker to open after 3 failures, got %v", cb.GetState())
+	}
+
+	// Resetting twice behaves like resetting once
+	for i := 0; i < 2; i++ {
+		if err := service.ResetCircuitBreaker("history_test"); err != nil {
+			t.Fatalf("Reset failed: %v", err)
+		}
+	}
+	if stats := cb.GetStats(); !stats["last_failure_time"].(time.Time).IsZero() || !stats["next_attempt_time"].(time.Time).IsZero() {
+		t.Errorf("Expected reset to clear failure timestamps, got %v", stats)
+	}
+
+	// A reset breaker needs a fresh MaxFailures worth of failures to re-open
+	cb.RecordFailure()
+	cb.RecordFailure()
+	if cb.GetState() != CircuitClosed {
+		t.Errorf("Expected breaker to stay closed after 2 fresh failures, got %v", cb.GetState())
+	}
+	cb.RecordFailure()
+	if cb.GetState() != CircuitOpen {
+		t.Errorf("Expected breaker to re-open after 3 fresh failures, got %v", cb.GetState())
+	}
+
+	for i := 0; i < DefaultCircuitBreakerMaxFailures; i++ {
+		other.RecordFailure()
+	}
+	service.ResetAll()
+	if cb.GetState() != CircuitClosed || other.GetState() != CircuitClosed {
+		t.Errorf("Expected ResetAll to close every breaker, got %v and %v", cb.GetState(), other.GetState())
+	}
+}
+
+func TestClassifyNetworkError(t *testing.T) {
+	testCases := []struct {
+		name string
+		err  error
+		want NetworkErrorKind
+	}{
+		{"missing host", &net.DNSError{Err: "no such host", Name: "shop.exmaple.com", IsNotFound: true}, NetworkErrorDNS},
+		{"wrapped missing host", fmt.Errorf("HTTP request failed: %w", &url.Error{Op: "Get", URL: "https://shop.exmaple.com", Err: &net.OpError{Op: "dial", Err: &net.DNSError{Err: "no such host", IsNotFound: true}}}), NetworkErrorDNS},
+		{"dns timeout", &net.DNSError{Err: "i/o timeout", IsTimeout: true}, NetworkErrorConnection},
+		{"connection reset", fmt.Errorf("HTTP request failed: %w", &net.OpError{Op: "read", Err: os.NewSyscallError("read", syscall.ECONNRESET)}), NetworkErrorConnection},
+		{"connection refused", &net.OpError{Op: "dial", Err: syscall.ECONNREFUSED}, NetworkErrorConnection},
+		{"message only", fmt.Errorf("no such host"), NetworkErrorNone},
+		{"nil", nil, NetworkErrorNone},
+	}
+
+	for _, tc := range testCases {
+		t.Run(tc.name, func(t *testing.T) {
+			if got := ClassifyNetworkError(tc.err); got != tc.want {
+				t.Errorf("ClassifyNetworkError() = %v, want %v", got, tc.want)
+			}
+		})
+	}
+}
+
+func TestService_DNSErrorsUseDNSRetries(t *testing.T) {
+	service := NewService()
+	fast := WithRetryConfig(RetryConfig{MaxRetries: 3, BaseDelay: time.Millisecond, MaxDelay: time.Millisecond})
+	dnsErr := &net.DNSError{Err: "no such host", Name: "shop.exmaple.com", IsNotFound: true}
+	resetErr := &net.OpError{Op: "read", Err: syscall.ECONNRESET}
+
+	result := service.ExecuteWithRetryResult(context.Background(), func() error { return dnsErr }, "fetch", fast)
+	if result.Attempts != 1 {
+		t.Errorf("Expected a missing host to fail on the first attempt, got %d attempts", result.Attempts)
+	}
+
+	result = service.ExecuteWithRetryResult(context.Background(), func() error { return dnsErr }, "fetch", fast, WithDNSRetries(1))
+	if result.Attempts != 2 {
+		t.Errorf("Expected dns_retries 1 to allow one retry, got %d attempts", result.Attempts)
+	}
+
+	result = service.ExecuteWithRetryResult(context.Background(), func() error { return resetErr }, "fetch", fast)
+	if result.Attempts != 4 {
+		t.Errorf("Expected connection resets to use the full retry budget, got %d attempts", result.Attempts)
+	}
+
+	recovery := service.ExecuteWithRecovery(context.Background(), "fetch_dns", func() (interface{}, error) { return nil, dnsErr }, fast)
+	if recovery.AttemptCount != 1 {
+		t.Errorf("Expected recovery to give up on a missing host at once, got %d attempts", recovery.AttemptCount)
+	}
+}
+
+func TestService_MarkedErrorsOverrideMessage(t *testing.T) {
+	service := NewService()
+	fast := WithRetryConfig(RetryConfig{MaxRetries: 3, BaseDelay: time.Millisecond, MaxDelay: time.Millisecond})
+
+	// "503" would normally be retried, but Permanent says otherwise
+	permanent := Permanent(fmt.Errorf("page says 503 forever"))
+	result := service.ExecuteWithRetryResult(context.Background(), func() error { return permanent }, "fetch", fast)
+	if result.Attempts != 1 {
+		t.Errorf("Expected a permanent error to fail on the first attempt, got %d attempts", result.Attempts)
+	}
+
+	// A plain message is not retried unless marked Retryable
+	retryable := Retryable(fmt.Errorf("please come back later"))
+	result = service.ExecuteWithRetryResult(context.Background(), func() error { return retryable }, "fetch", fast)
+	if result.Attempts != 4 {
+		t.Errorf("Expected a retryable error to use the full retry budget, got %d attempts", result.Attempts)
+	}
+
+	if !IsPermanent(fmt.Errorf("wrapped: %w", permanent)) || IsPermanent(retryable) {
+		t.Error("IsPermanent should see through wrapping and only match Permanent errors")
+	}
+	if Retryable(nil) != nil || Permanent(nil) != nil {
+		t.Error("Marking a nil error should return nil")
+	}
+}
+
+func TestService_ErrorMetrics(t *testing.T) {
+	service := NewService()
+	ctx := context.Background()
+	fast := WithRetryConfig(RetryConfig{MaxRetries: 2, BaseDelay: time.Millisecond, MaxDelay: time.Millisecond})
+
+	// First-attempt success
+	service.ExecuteWithRecovery(ctx, "ok", func() (interface{}, error) { return "data", nil }, fast)
+
+	// Success after one timeout
+	calls := 0
+	service.ExecuteWithRecovery(ctx, "flaky", func() (interface{}, error) {
+		calls++
+		if calls == 1 {
+			return nil, fmt.Errorf("request timeout")
+		}
+		return "data", nil
+	}, fast)
+
+	// Permanent failure, once with a fallback and once without
+	notFound := func() (interface{}, error) { return nil, fmt.Errorf("404 not found") }
+	service.ConfigureFallback("with_fallback", FallbackConfig{Strategy: FallbackDefault, DefaultValue: "default"})
+	service.ExecuteWithRecovery(ctx, "with_fallback", notFound, fast)
+	service.ExecuteWithRecovery(ctx, "without_fallback", notFound, fast)
+
+	metrics := service.GetErrorMetrics()
+	if metrics.Operations != 4 || metrics.Succeeded != 1 || metrics.Retried != 1 || metrics.Fallback != 1 || metrics.Failed != 1 {
+		t.Errorf("Unexpected outcome counts: %+v", metrics)
+	}
+	if metrics.Fallbacks["retry_exhausted_fallback"] != 1 {
+		t.Errorf("Expected the default fallback to be counted, got %v", metrics.Fallbacks)
+	}
+	if want := 2.0 / 3.0; metrics.RecoveryRate != want {
+		t.Errorf("Expected recovery rate %.3f, got %.3f", want, metrics.RecoveryRate)
+	}
+	if len(metrics.TopErrors) != 2 || metrics.TopErrors[0] != (ErrorCount{Error: "404 not found", Count: 2}) {
+		t.Errorf("Expected the 404 to lead the top errors, got %v", metrics.TopErrors)
+	}
+	if metrics.Categories["other"] != 3 {
+		t.Errorf("Expected 3 uncategorized errors, got %v", metrics.Categories)
+	}
+	if CircuitOpen.String() != "open" {
+		t.Errorf("Expected CircuitOpen to print as open, got %s", CircuitOpen)
+	}
+}
+
+func TestErrorCategory(t *testing.T) {
+	tests := []struct {
+		err  error
+		want string
+	}{
+		{&net.DNSError{Err: "no such host", Name: "nowhere.invalid", IsNotFound: true}, "dns"},
+		{fmt.Errorf("dial: %w", syscall.ECONNREFUSED), "connection"},
+		{fmt.Errorf("fetch: %w", context.DeadlineExceeded), "timeout"},
+		{context.Canceled, "canceled"},
+		{fmt.Errorf("HTTP error 404: 404 Not Found"), "http_4xx"},
+		{fmt.Errorf("HTTP 503: 503 Service Unavailable"), "http_5xx"},
+		{fmt.Errorf("no elements found for selector: h1"), "other"},
+	}
+	for _, tt := range tests {
+		if got := ErrorCategory(tt.err); got != tt.want {
+			t.Errorf("ErrorCategory(%v) = %s, want %s", tt.err, got, tt.want)
+		}
+	}
+}
+
+func TestRetryConfig_Jitter(t *testing.T) {
+	rc := RetryConfig{BaseDelay: 100 * time.Millisecond, BackoffFactor: 2, MaxDelay: time.Second}
+
+	// Without jitter the delays are exact
+	if d := rc.delay(2); d != 400*time.Millisecond {
+		t.Errorf("Expected 400ms without jitter, got %v", d)
+	}
+
+	// With jitter every delay stays within ±Jitter of the capped delay
+	rc.Jitter = 0.5
+	for attempt, base := range []time.Duration{100 * time.Millisecond, 200 * time.Millisecond, 400 * time.Millisecond, 800 * time.Millisecond, time.Second, time.Second} {
+		low, high := base/2, base*3/2
+		seen := make(map[time.Duration]bool)
+		for i := 0; i < 200; i++ {
+			d := rc.delay(attempt)
+			if d < low || d > high {
+				t.Fatalf("attempt %d: delay %v outside [%v, %v]", attempt, d, low, high)
+			}
+			seen[d] = true
+		}
+		if len(seen) < 2 {
+			t.Errorf("attempt %d: expected jittered delays to vary, got %v", attempt, seen)
+		}
+	}
+
+	// The bounds are reached at the extremes of the random source
+	defer func(orig func() float64) { jitterRand = orig }(jitterRand)
+	jitterRand = func() float64 { return 0 }
+	if d := rc.delay(10); d != 500*time.Millisecond {
+		t.Errorf("Expected the low bound of the capped delay, got %v", d)
+	}
+	jitterRand = func() float64 { return 1 }
+	if d := rc.delay(10); d != 1500*time.Millisecond {
+		t.Errorf("Expected the high bound of the capped delay, got %v", d)
+	}
+
+	// Jitter above 1 is clamped so a delay never goes negative
+	rc.Jitter = 3
+	jitterRand = func() float64 { return 0 }
+	if d := rc.delay(0); d != 0 {
+		t.Errorf("Expected clamped jitter to bottom out at zero, got %v", d)
+	}
+}
@@ -0,0 +1,180 @@
+// internal/monitoring/engine_metrics.go
+package monitoring
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"net"
+	"net/http"
+	"time"
+
+	"github.com/prometheus/client_golang/prometheus"
+	"github.com/prometheus/client_golang/prometheus/collectors"
+	"github.com/prometheus/client_golang/prometheus/promhttp"
+	"github.com/valpere/DataScrapexter/internal/scraper"
+)
+
+// Circuit breaker states as reported by the datascrapexter_circuit_breaker_state gauge
+var circuitStateNames = map[int32]string{0: "closed", 1: "open", 2: "half_open"}
+
+// EngineCollector exposes a running engine's own statistics as Prometheus
+// metrics. Values are read from the engine on every scrape, so nothing on the
+// request path has to record them twice. It is separate from MetricsManager,
+// whose series the dashboard's callers record request by request, labelled by
+// job, in the global registry; the engine keeps no such labels. Series names
+// stay clear of MetricsManager's so both can be scraped into one Prometheus.
+type EngineCollector struct {
+	engine *scraper.Engine
+
+	requests       *prometheus.Desc
+	requestLatency *prometheus.Desc
+	operations     *prometheus.Desc
+	errors         *prometheus.Desc
+	fallbacks      *prometheus.Desc
+	breakerState   *prometheus.Desc
+	proxyRequests  *prometheus.Desc
+	proxyAvailable *prometheus.Desc
+	proxyResponse  *prometheus.Desc
+}
+
+// NewEngineCollector returns a collector for engine
+func NewEngineCollector(engine *scraper.Engine) *EngineCollector {
+	name := func(metric string) string { return prometheus.BuildFQName("datascrapexter", "", metric) }
+	return &EngineCollector{
+		engine: engine,
+
+		requests: prometheus.NewDesc(name("page_requests_total"),
+			"Page requests sent, counting every retry.", nil, nil),
+		requestLatency: prometheus.NewDesc(name("page_request_duration_seconds"),
+			"Time taken by page requests.", nil, nil),
+		operations: prometheus.NewDesc(name("fetch_operations_total"),
+			"Page fetches by outcome: succeeded, retried, fallback or failed.", []string{"outcome"}, nil),
+		errors: prometheus.NewDesc(name("fetch_errors_total"),
+			"Failed fetch attempts by error category.", []string{"category"}, nil),
+		fallbacks: prometheus.NewDesc(name("fetch_fallbacks_total"),
+			"Fetches served by a fallback, by fallback type.", []string{"type"}, nil),
+		breakerState: prometheus.NewDesc(name("circuit_breaker_state"),
+			"1 for the state each circuit breaker is in, 0 for the others.", []string{"scope", "name", "state"}, nil),
+		proxyRequests: prometheus.NewDesc(name("proxy_requests_total"),
+			"Requests sent through each proxy by result.", []string{"proxy", "result"}, nil),
+		proxyAvailable: prometheus.NewDesc(name("proxy_available"),
+			"1 while the proxy is in rotation.", []string{"proxy"}, nil),
+		proxyResponse: prometheus.NewDesc(name("proxy_response_seconds"),
+			"Latest response time measured for the proxy.", []string{"proxy"}, nil),
+	}
+}
+
+// Describe implements prometheus.Collector
+func (c *EngineCollector) Describe(ch chan<- *prometheus.Desc) {
+	for _, desc := range []*prometheus.Desc{
+		c.requests, c.requestLatency, c.operations, c.errors, c.fallbacks,
+		c.breakerState, c.proxyRequests, c.proxyAvailable, c.proxyResponse,
+	} {
+		ch <- desc
+	}
+}
+
+// Collect implements prometheus.Collector
+func (c *EngineCollector) Collect(ch chan<- prometheus.Metric) {
+	ch <- prometheus.MustNewConstMetric(c.requests, prometheus.CounterValue, float64(c.engine.GetRequestStats().Requests))
+
+	latency := c.engine.GetRequestLatency()
+	buckets := make(map[float64]uint64, len(latency.Buckets))
+	for i, bound := range latency.Buckets {
+		buckets[bound] = latency.Counts[i]
+	}
+	ch <- prometheus.MustNewConstHistogram(c.requestLatency, latency.Count, latency.Sum, buckets)
+
+	metrics := c.engine.GetErrorMetrics()
+	for outcome, n := range map[string]int{
+		"succeeded": metrics.Succeeded,
+		"retried":   metrics.Retried,
+		"fallback":  metrics.Fallback,
+		"failed":    metrics.Failed,
+	} {
+		ch <- prometheus.MustNewConstMetric(c.operations, prometheus.CounterValue, float64(n), outcome)
+	}
+	for category, n := range metrics.Categories {
+		ch <- prometheus.MustNewConstMetric(c.errors, prometheus.CounterValue, float64(n), category)
+	}
+	for fallbackType, n := range metrics.Fallbacks {
+		ch <- prometheus.MustNewConstMetric(c.fallbacks, prometheus.CounterValue, float64(n), fallbackType)
+	}
+
+	for _, breaker := range c.engine.GetCircuitBreakerStatuses() {
+		for state, stateName := range circuitStateNames {
+			value := 0.0
+			if breaker.State == state {
+				value = 1
+			}
+			ch <- prometheus.MustNewConstMetric(c.breakerState, prometheus.GaugeValue, value, breaker.Scope, breaker.Name, stateName)
+		}
+	}
+
+	for _, p := range c.engine.GetProxyStatusReport() {
+		ch <- prometheus.MustNewConstMetric(c.proxyRequests, prometheus.CounterValue, float64(p.SuccessCount), p.Name, "success")
+		ch <- prometheus.MustNewConstMetric(c.proxyRequests, prometheus.CounterValue, float64(p.TotalFailure), p.Name, "failure")
+		available := 0.0
+		if p.Available {
+			available = 1
+		}
+		ch <- prometheus.MustNewConstMetric(c.proxyAvailable, prometheus.GaugeValue, available, p.Name)
+		ch <- prometheus.MustNewConstMetric(c.proxyResponse, prometheus.GaugeValue, p.ResponseTime.Seconds(), p.Name)
+	}
+}
+
+// EngineMetricsServer serves an engine's metrics at /metrics until Shutdown
+type EngineMetricsServer struct {
+	server   *http.Server
+	listener net.Listener
+	done     chan error
+}
+
+// ServeEngineMetrics starts serving engine's metrics, with the Go runtime and
+// process metrics, at http://addr/metrics. The address is bound before it
+// returns, so a port already in use is reported here rather than lost.
+func ServeEngineMetrics(addr string, engine *scraper.Engine) (*EngineMetricsServer, error) {
+	registry := prometheus.NewRegistry()
+	registry.MustRegister(
+		NewEngineCollector(engine),
+		collectors.NewGoCollector(),
+		collectors.NewProcessCollector(collectors.ProcessCollectorOpts{}),
+	)
+
+	mux := http.NewServeMux()
+	mux.Handle("/metrics", promhttp.HandlerFor(registry, promhttp.HandlerOpts{}))
+
+	listener, err := net.Listen("tcp", addr)
+	if err != nil {
+		return nil, fmt.Errorf("failed to listen for metrics on %s: %w", addr, err)
+	}
+
+	s := &EngineMetricsServer{
+		server:   &http.Server{Handler: mux, ReadHeaderTimeout: 10 * time.Second},
+		listener: listener,
+		done:     make(chan error, 1),
+	}
+	go func() {
+		err := s.server.Serve(listener)
+		if errors.Is(err, http.ErrServerClosed) {
+			err = nil
+		}
+		s.done <- err
+	}()
+	return s, nil
+}
+
+// Addr returns the address the server listens on, with the port chosen when
+// addr asked for port 0
+func (s *EngineMetricsServer) Addr() string {
+	return s.listener.Addr().String()
+}
+
+// Shutdown stops the server, letting a scrape in progress finish until ctx is done
+func (s *EngineMetricsServer) Shutdown(ctx context.Context) error {
+	if err := s.server.Shutdown(ctx); err != nil {
+		return fmt.Errorf("failed to stop metrics server: %w", err)
+	}
+	return <-s.done
+}
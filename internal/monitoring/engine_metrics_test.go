@@ -0,0 +1,76 @@
+// internal/monitoring/engine_metrics_test.go
+package monitoring
+
+import (
+	"context"
+	"io"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/valpere/DataScrapexter/internal/scraper"
+)
+
+func TestServeEngineMetrics(t *testing.T) {
+	site := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		if r.URL.Path == "/missing" {
+			http.NotFound(w, r)
+			return
+		}
+		w.Write([]byte(`<html><body><h1>Hello</h1></body></html>`))
+	}))
+	defer site.Close()
+
+	engine, err := scraper.NewEngine(&scraper.Config{Timeout: 5 * time.Second, MaxRetries: 1})
+	if err != nil {
+		t.Fatalf("Failed to create engine: %v", err)
+	}
+	fields := []scraper.FieldConfig{{Name: "title", Selector: "h1", Type: "text"}}
+	if _, err := engine.Scrape(context.Background(), site.URL+"/", fields); err != nil {
+		t.Fatalf("Scrape failed: %v", err)
+	}
+	if _, err := engine.Scrape(context.Background(), site.URL+"/missing", fields); err == nil {
+		t.Fatal("Expected the missing page to fail")
+	}
+
+	server, err := ServeEngineMetrics("127.0.0.1:0", engine)
+	if err != nil {
+		t.Fatalf("Failed to start metrics server: %v", err)
+	}
+
+	resp, err := http.Get("http://" + server.Addr() + "/metrics")
+	if err != nil {
+		t.Fatalf("GET /metrics failed: %v", err)
+	}
+	body, _ := io.ReadAll(resp.Body)
+	resp.Body.Close()
+	if resp.StatusCode != http.StatusOK {
+		t.Fatalf("Expected 200 from /metrics, got %d", resp.StatusCode)
+	}
+
+	for _, series := range []string{
+		"datascrapexter_page_requests_total ",
+		`datascrapexter_page_request_duration_seconds_bucket{le="+Inf"}`,
+		"datascrapexter_page_request_duration_seconds_count ",
+		`datascrapexter_fetch_operations_total{outcome="succeeded"} 1`,
+		`datascrapexter_fetch_operations_total{outcome="failed"} 1`,
+		`datascrapexter_fetch_errors_total{category="http_4xx"}`,
+		`datascrapexter_circuit_breaker_state{name="` + strings.TrimPrefix(site.URL, "http://") + `",scope="host",state="closed"} 1`,
+		"go_goroutines ",
+	} {
+		if !strings.Contains(string(body), series) {
+			t.Errorf("Expected series %s in:\n%s", series, body)
+		}
+	}
+
+	ctx, cancel := context.WithTimeout(context.Background(), 5*time.Second)
+	defer cancel()
+	if err := server.Shutdown(ctx); err != nil {
+		t.Fatalf("Shutdown failed: %v", err)
+	}
+	if _, err := http.Get("http://" + server.Addr() + "/metrics"); err == nil {
+		t.Error("Expected the metrics server to be stopped")
+	}
+}
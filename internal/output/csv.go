@@ -2,42 +2,321 @@
 package output
 
 import (
+	"bufio"
 	"encoding/csv"
+	"encoding/json"
+	"errors"
 	"fmt"
+	"io"
 	"os"
+	"reflect"
 	"sort"
+	"strconv"
+	"strings"
+	"unicode/utf8"
 )
 
-// CSVWriter writes data in CSV format
+// ErrCSVSchemaMismatch is returned when a streamed record has a field that is not in the CSV header
+var ErrCSVSchemaMismatch = errors.New("record field not in CSV header")
+
+// Nested value encodings for CSV cells (output.nested_encoding)
+const (
+	NestedEncodingJSON    = "json"    // Encode maps and slices as JSON text in the cell (default)
+	NestedEncodingFlatten = "flatten" // Expand into dotted columns such as price.amount or tags.0
+	NestedEncodingDrop    = "drop"    // Omit maps and slices from the row
+)
+
+// CSVWriter writes data in CSV format.
+//
+// A CSV file has a single fixed header, so the writer supports three schema modes:
+//
+//   - Fixed columns (output.columns): the header is the configured column list and
+//     records are streamed as they arrive. Fields not listed are dropped. This is the
+//     only streaming mode that is safe for records with differing keys.
+//   - Buffered union (output.csv_union): every record is held in memory until Close,
+//     then the header is the sorted union of all keys. Memory grows with the result set.
+//   - Default: the header is the sorted union of the keys in the first Write call and
+//     later records are streamed against it. A later record with a key outside that
+//     header fails with ErrCSVSchemaMismatch instead of silently losing data.
+//
+// Outside fixed columns, SetCSVOptions can order the header: the listed columns
+// come first, in the given order and even when no record has them, and the rest
+// follow alphabetically.
 type CSVWriter struct {
 	filename string
-	file     *os.File
-	writer   *csv.Writer
+	file     *os.File // set only when the writer owns its destination
+	out      io.Writer
+	writer   csvRecordWriter
+
+	comma    rune
+	crlf     bool
+	quoteAll bool
+
+	columns       []string
+	columnOrder   []string
+	fixedColumns  bool
+	union         bool
+	buffered      []map[string]interface{}
+	headerWritten bool
+	nested        string
 }
 
-// NewCSVWriter creates a new CSV writer
+// NewCSVWriter creates a new CSV writer that infers its header from the first batch
 func NewCSVWriter(filename string) (*CSVWriter, error) {
+	return NewCSVWriterWithSchema(filename, nil, false)
+}
+
+// NewCSVWriterWithSchema creates a CSV writer with fixed columns or buffered union mode.
+// Fixed columns take precedence when both are given.
+func NewCSVWriterWithSchema(filename string, columns []string, union bool) (*CSVWriter, error) {
 	file, err := os.Create(filename)
 	if err != nil {
 		return nil, err
 	}
 
-	writer := csv.NewWriter(file)
+	writer := NewCSVStreamWriter(file, columns, union)
+	writer.filename = filename
+	writer.file = file
+	return writer, nil
+}
 
+// NewCSVStreamWriter creates a CSV writer over out, e.g. os.Stdout, with the same
+// schema modes as NewCSVWriterWithSchema. Close does not close out.
+func NewCSVStreamWriter(out io.Writer, columns []string, union bool) *CSVWriter {
 	return &CSVWriter{
-		filename: filename,
-		file:     file,
-		writer:   writer,
-	}, nil
+		out:          out,
+		writer:       csv.NewWriter(out),
+		comma:        ',',
+		columns:      columns,
+		fixedColumns: len(columns) > 0,
+		union:        union && len(columns) == 0,
+		nested:       NestedEncodingJSON,
+	}
+}
+
+// SetTextEncoding applies a byte order mark and line ending. It must be called before the first write.
+func (w *CSVWriter) SetTextEncoding(encoding TextEncoding) error {
+	if err := encoding.validate(); err != nil {
+		return err
+	}
+	if w.headerWritten || len(w.buffered) > 0 {
+		return fmt.Errorf("text encoding must be set before writing")
+	}
+	if encoding.BOM {
+		// Nothing has reached the csv.Writer yet, so the BOM lands first in the file
+		if _, err := w.out.Write(utf8BOM); err != nil {
+			return err
+		}
+	}
+	w.crlf = encoding.crlf()
+	w.resetWriter()
+	return nil
+}
+
+// SetCSVOptions applies a delimiter, quoting of every field and a header column
+// order. It must be called before the first write. An empty delimiter keeps the
+// comma.
+func (w *CSVWriter) SetCSVOptions(options CSVOptions) error {
+	comma, err := csvDelimiter(options.Delimiter)
+	if err != nil {
+		return err
+	}
+	if w.headerWritten || len(w.buffered) > 0 {
+		return fmt.Errorf("CSV options must be set before writing")
+	}
+	w.comma = comma
+	w.quoteAll = options.QuoteAll
+	w.columnOrder = options.Columns
+	w.resetWriter()
+	return nil
+}
+
+// csvDelimiter returns the single rune of delimiter, or a comma when it is empty
+func csvDelimiter(delimiter string) (rune, error) {
+	if delimiter == "" {
+		return ',', nil
+	}
+	comma, size := utf8.DecodeRuneInString(delimiter)
+	if size != len(delimiter) || comma == utf8.RuneError || comma == '"' || comma == '\r' || comma == '\n' {
+		return 0, fmt.Errorf("invalid CSV delimiter %q: must be a single character other than a quote or line break", delimiter)
+	}
+	return comma, nil
+}
+
+// resetWriter rebuilds the record writer for the current delimiter, quoting and
+// line ending. Nothing has been written through it yet, so nothing is lost.
+func (w *CSVWriter) resetWriter() {
+	if w.quoteAll {
+		w.writer = &quoteAllWriter{out: bufio.NewWriter(w.out), comma: w.comma, crlf: w.crlf}
+		return
+	}
+	writer := csv.NewWriter(w.out)
+	writer.Comma = w.comma
+	writer.UseCRLF = w.crlf
+	w.writer = writer
 }
 
-// Write writes data to CSV file
+// SetNestedEncoding chooses how map and slice values are written: json, flatten or drop.
+// An empty encoding keeps the default, json.
+func (w *CSVWriter) SetNestedEncoding(encoding string) error {
+	switch encoding {
+	case "":
+		w.nested = NestedEncodingJSON
+	case NestedEncodingJSON, NestedEncodingFlatten, NestedEncodingDrop:
+		w.nested = encoding
+	default:
+		return fmt.Errorf("unsupported nested encoding: %s", encoding)
+	}
+	return nil
+}
+
+// Write writes data to CSV file. In union mode rows are buffered until Close.
 func (w *CSVWriter) Write(data []map[string]interface{}) error {
 	if len(data) == 0 {
 		return nil
 	}
 
-	// Get all unique field names
+	if w.nested != NestedEncodingJSON {
+		data = w.reshapeNested(data)
+	}
+
+	if w.union {
+		w.buffered = append(w.buffered, data...)
+		return nil
+	}
+
+	if !w.headerWritten {
+		if !w.fixedColumns {
+			w.columns = orderColumns(unionKeys(data), w.columnOrder)
+		}
+		if err := w.writer.Write(w.columns); err != nil {
+			return fmt.Errorf("failed to write header: %w", err)
+		}
+		w.headerWritten = true
+	}
+
+	if err := w.writeRows(data); err != nil {
+		return err
+	}
+
+	w.writer.Flush()
+	return w.writer.Error()
+}
+
+// writeRows writes data rows in header order
+func (w *CSVWriter) writeRows(data []map[string]interface{}) error {
+	var known map[string]bool
+	if !w.fixedColumns {
+		known = make(map[string]bool, len(w.columns))
+		for _, column := range w.columns {
+			known[column] = true
+		}
+	}
+
+	for i, row := range data {
+		if known != nil {
+			for field := range row {
+				if !known[field] {
+					return fmt.Errorf("%w: %q in record %d (set output.columns or output.csv_union)", ErrCSVSchemaMismatch, field, i)
+				}
+			}
+		}
+
+		record := make([]string, 0, len(w.columns))
+		for _, field := range w.columns {
+			value := ""
+			if val, exists := row[field]; exists && val != nil {
+				cell, err := formatCSVCell(val)
+				if err != nil {
+					return fmt.Errorf("failed to encode field %q in record %d: %w", field, i, err)
+				}
+				value = cell
+			}
+			record = append(record, value)
+		}
+		if err := w.writer.Write(record); err != nil {
+			return fmt.Errorf("failed to write record: %w", err)
+		}
+	}
+
+	return nil
+}
+
+// formatCSVCell renders a value for a CSV cell, JSON-encoding maps and slices
+func formatCSVCell(val interface{}) (string, error) {
+	if !isNested(val) {
+		return fmt.Sprintf("%v", val), nil
+	}
+	encoded, err := json.Marshal(val)
+	if err != nil {
+		return "", err
+	}
+	return string(encoded), nil
+}
+
+// isNested reports whether val is a map, or a slice or array other than []byte
+func isNested(val interface{}) bool {
+	rv := reflect.ValueOf(val)
+	switch rv.Kind() {
+	case reflect.Map, reflect.Array:
+		return true
+	case reflect.Slice:
+		return rv.Type().Elem().Kind() != reflect.Uint8
+	}
+	return false
+}
+
+// reshapeNested flattens or drops nested values according to the writer's encoding
+func (w *CSVWriter) reshapeNested(data []map[string]interface{}) []map[string]interface{} {
+	reshaped := make([]map[string]interface{}, len(data))
+	for i, row := range data {
+		out := make(map[string]interface{}, len(row))
+		for key, val := range row {
+			switch {
+			case !isNested(val):
+				out[key] = val
+			case w.nested == NestedEncodingFlatten:
+				flattenInto(out, key, reflect.ValueOf(val))
+			}
+		}
+		reshaped[i] = out
+	}
+	return reshaped
+}
+
+// flattenInto writes the leaves of a nested value into out under dotted keys
+func flattenInto(out map[string]interface{}, prefix string, rv reflect.Value) {
+	for rv.Kind() == reflect.Interface || rv.Kind() == reflect.Pointer {
+		if rv.IsNil() {
+			out[prefix] = nil
+			return
+		}
+		rv = rv.Elem()
+	}
+
+	if !rv.IsValid() || !isNested(rv.Interface()) {
+		if rv.IsValid() {
+			out[prefix] = rv.Interface()
+		} else {
+			out[prefix] = nil
+		}
+		return
+	}
+
+	switch rv.Kind() {
+	case reflect.Map:
+		for _, key := range rv.MapKeys() {
+			flattenInto(out, fmt.Sprintf("%s.%v", prefix, key.Interface()), rv.MapIndex(key))
+		}
+	default:
+		for j := 0; j < rv.Len(); j++ {
+			flattenInto(out, prefix+"."+strconv.Itoa(j), rv.Index(j))
+		}
+	}
+}
+
+// unionKeys returns the sorted union of keys across records
+func unionKeys(data []map[string]interface{}) []string {
 	fieldSet := make(map[string]bool)
 	for _, row := range data {
 		for field := range row {
@@ -45,35 +324,80 @@ func (w *CSVWriter) Write(data []map[string]interface{}) error {
 		}
 	}
 
-	// Convert to sorted slice for consistent column order
-	var fields []string
+	fields := make([]string, 0, len(fieldSet))
 	for field := range fieldSet {
 		fields = append(fields, field)
 	}
 	sort.Strings(fields)
+	return fields
+}
 
-	// Write header
-	if err := w.writer.Write(fields); err != nil {
-		return fmt.Errorf("failed to write header: %w", err)
+// orderColumns puts order first and then the other keys, which are sorted
+func orderColumns(keys, order []string) []string {
+	if len(order) == 0 {
+		return keys
+	}
+	columns := append([]string(nil), order...)
+	listed := make(map[string]bool, len(order))
+	for _, column := range order {
+		listed[column] = true
+	}
+	for _, key := range keys {
+		if !listed[key] {
+			columns = append(columns, key)
+		}
 	}
+	return columns
+}
 
-	// Write data rows
-	for _, row := range data {
-		var record []string
-		for _, field := range fields {
-			value := ""
-			if val, exists := row[field]; exists && val != nil {
-				value = fmt.Sprintf("%v", val)
-			}
-			record = append(record, value)
+// csvRecordWriter is implemented by csv.Writer and quoteAllWriter
+type csvRecordWriter interface {
+	Write(record []string) error
+	Flush()
+	Error() error
+}
+
+// quoteAllWriter writes records like csv.Writer but quotes every field, which
+// csv.Writer only does when a field needs it
+type quoteAllWriter struct {
+	out   *bufio.Writer
+	comma rune
+	crlf  bool
+	err   error
+}
+
+func (q *quoteAllWriter) Write(record []string) error {
+	if q.err != nil {
+		return q.err
+	}
+	for i, field := range record {
+		if i > 0 {
+			q.out.WriteRune(q.comma)
 		}
-		if err := w.writer.Write(record); err != nil {
-			return fmt.Errorf("failed to write record: %w", err)
+		field = strings.ReplaceAll(field, `"`, `""`)
+		if q.crlf {
+			field = strings.ReplaceAll(strings.ReplaceAll(field, "\r\n", "\n"), "\n", "\r\n")
 		}
+		q.out.WriteByte('"')
+		q.out.WriteString(field)
+		q.out.WriteByte('"')
+	}
+	if q.crlf {
+		q.out.WriteString("\r\n")
+	} else {
+		q.out.WriteByte('\n')
 	}
+	return nil
+}
 
-	w.writer.Flush()
-	return w.writer.Error()
+func (q *quoteAllWriter) Flush() {
+	if err := q.out.Flush(); err != nil && q.err == nil {
+		q.err = err
+	}
+}
+
+func (q *quoteAllWriter) Error() error {
+	return q.err
 }
 
 // WriteRecord writes a single record to CSV file
@@ -90,8 +414,16 @@ func (w *CSVWriter) Flush() error {
 	return nil
 }
 
-// Close closes the CSV writer
+// Close writes any buffered union-mode rows and closes the CSV writer
 func (w *CSVWriter) Close() error {
+	var writeErr error
+	if w.union && w.writer != nil && len(w.buffered) > 0 {
+		buffered := w.buffered
+		w.buffered = nil
+		w.union = false
+		writeErr = w.Write(buffered)
+	}
+
 	if w.writer != nil {
 		w.writer.Flush()
 		w.writer = nil
@@ -99,7 +431,10 @@ func (w *CSVWriter) Close() error {
 	if w.file != nil {
 		err := w.file.Close()
 		w.file = nil
+		if writeErr != nil {
+			return writeErr
+		}
 		return err
 	}
-	return nil
+	return writeErr
 }
@@ -2,7 +2,9 @@
 package output
 
 import (
+	"errors"
 	"os"
+	"path/filepath"
 	"strings"
 	"testing"
 )
@@ -171,3 +173,200 @@ func TestCSVWriter_Close(t *testing.T) {
 		t.Errorf("multiple close should not return error: %v", err)
 	}
 }
+
+func TestCSVWriter_SchemaModes(t *testing.T) {
+	first := []map[string]interface{}{{"title": "A", "price": 1}}
+	second := []map[string]interface{}{{"title": "B", "rating": 4.5}}
+
+	readLines := func(t *testing.T, filename string) []string {
+		t.Helper()
+		data, err := os.ReadFile(filename)
+		if err != nil {
+			t.Fatalf("failed to read output file: %v", err)
+		}
+		return strings.Split(strings.TrimSpace(string(data)), "\n")
+	}
+
+	t.Run("fixed columns stream", func(t *testing.T) {
+		filename := filepath.Join(t.TempDir(), "fixed.csv")
+		writer, err := NewCSVWriterWithSchema(filename, []string{"title", "rating"}, false)
+		if err != nil {
+			t.Fatalf("failed to create CSV writer: %v", err)
+		}
+		if err := writer.Write(first); err != nil {
+			t.Fatalf("first write failed: %v", err)
+		}
+		if err := writer.Write(second); err != nil {
+			t.Fatalf("second write failed: %v", err)
+		}
+		if err := writer.Close(); err != nil {
+			t.Fatalf("close failed: %v", err)
+		}
+
+		want := []string{"title,rating", "A,", "B,4.5"}
+		if got := readLines(t, filename); strings.Join(got, "|") != strings.Join(want, "|") {
+			t.Errorf("got %v, want %v", got, want)
+		}
+	})
+
+	t.Run("buffered union", func(t *testing.T) {
+		filename := filepath.Join(t.TempDir(), "union.csv")
+		writer, err := NewCSVWriterWithSchema(filename, nil, true)
+		if err != nil {
+			t.Fatalf("failed to create CSV writer: %v", err)
+		}
+		writer.Write(first)
+		writer.Write(second)
+		if err := writer.Close(); err != nil {
+			t.Fatalf("close failed: %v", err)
+		}
+
+		want := []string{"price,rating,title", "1,,A", ",4.5,B"}
+		if got := readLines(t, filename); strings.Join(got, "|") != strings.Join(want, "|") {
+			t.Errorf("got %v, want %v", got, want)
+		}
+	})
+
+	t.Run("inferred header rejects new keys", func(t *testing.T) {
+		filename := filepath.Join(t.TempDir(), "inferred.csv")
+		writer, err := NewCSVWriter(filename)
+		if err != nil {
+			t.Fatalf("failed to create CSV writer: %v", err)
+		}
+		defer writer.Close()
+
+		if err := writer.Write(first); err != nil {
+			t.Fatalf("first write failed: %v", err)
+		}
+		if err := writer.Write(second); !errors.Is(err, ErrCSVSchemaMismatch) {
+			t.Errorf("expected ErrCSVSchemaMismatch, got %v", err)
+		}
+	})
+}
+
+func TestCSVWriter_NestedEncoding(t *testing.T) {
+	data := []map[string]interface{}{{
+		"title": "A",
+		"price": map[string]interface{}{"amount": 9.5, "currency": "EUR"},
+		"tags":  []string{"new", "sale"},
+	}}
+
+	tests := []struct {
+		encoding string
+		want     []string
+	}{
+		{"", []string{"price,tags,title", `"{""amount"":9.5,""currency"":""EUR""}","[""new"",""sale""]",A`}},
+		{NestedEncodingJSON, []string{"price,tags,title", `"{""amount"":9.5,""currency"":""EUR""}","[""new"",""sale""]",A`}},
+		{NestedEncodingFlatten, []string{"price.amount,price.currency,tags.0,tags.1,title", "9.5,EUR,new,sale,A"}},
+		{NestedEncodingDrop, []string{"title", "A"}},
+	}
+
+	for _, tt := range tests {
+		t.Run("encoding "+tt.encoding, func(t *testing.T) {
+			filename := filepath.Join(t.TempDir(), "nested.csv")
+			writer, err := NewCSVWriterWithSchema(filename, nil, false)
+			if err != nil {
+				t.Fatalf("failed to create CSV writer: %v", err)
+			}
+			if err := writer.SetNestedEncoding(tt.encoding); err != nil {
+				t.Fatalf("SetNestedEncoding failed: %v", err)
+			}
+			if err := writer.Write(data); err != nil {
+				t.Fatalf("write failed: %v", err)
+			}
+			if err := writer.Close(); err != nil {
+				t.Fatalf("close failed: %v", err)
+			}
+
+			content, err := os.ReadFile(filename)
+			if err != nil {
+				t.Fatalf("failed to read output file: %v", err)
+			}
+			got := strings.Split(strings.TrimSpace(string(content)), "\n")
+			if strings.Join(got, "|") != strings.Join(tt.want, "|") {
+				t.Errorf("got %v, want %v", got, tt.want)
+			}
+		})
+	}
+
+	writer, err := NewCSVWriterWithSchema(filepath.Join(t.TempDir(), "bad.csv"), nil, false)
+	if err != nil {
+		t.Fatalf("failed to create CSV writer: %v", err)
+	}
+	defer writer.Close()
+	if err := writer.SetNestedEncoding("yaml"); err == nil {
+		t.Error("expected error for unsupported nested encoding")
+	}
+}
+
+func TestCSVWriter_CSVOptions(t *testing.T) {
+	data := []map[string]interface{}{
+		{"title": "A; B", "price": 9.5, "url": "https://example.com/a", "sku": "A1"},
+		{"title": "C", "sku": "C1", "rating": 4},
+	}
+
+	tests := []struct {
+		name    string
+		options CSVOptions
+		union   bool
+		want    []string
+	}{
+		{
+			name:    "semicolon delimiter",
+			options: CSVOptions{Delimiter: ";"},
+			union:   true,
+			want:    []string{"price;rating;sku;title;url", `9.5;;A1;"A; B";https://example.com/a`, ";4;C1;C;"},
+		},
+		{
+			name:    "column order",
+			options: CSVOptions{Columns: []string{"url", "title", "brand"}},
+			union:   true,
+			want:    []string{"url,title,brand,price,rating,sku", "https://example.com/a,A; B,,9.5,,A1", ",C,,,4,C1"},
+		},
+		{
+			name:    "quote all",
+			options: CSVOptions{Delimiter: "\t", QuoteAll: true, Columns: []string{"sku"}},
+			union:   true,
+			want:    []string{"\"sku\"\t\"price\"\t\"rating\"\t\"title\"\t\"url\"", "\"A1\"\t\"9.5\"\t\"\"\t\"A; B\"\t\"https://example.com/a\"", "\"C1\"\t\"\"\t\"4\"\t\"C\"\t\"\""},
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			filename := filepath.Join(t.TempDir(), "options.csv")
+			writer, err := NewCSVWriterWithSchema(filename, nil, tt.union)
+			if err != nil {
+				t.Fatalf("failed to create CSV writer: %v", err)
+			}
+			if err := writer.SetCSVOptions(tt.options); err != nil {
+				t.Fatalf("SetCSVOptions failed: %v", err)
+			}
+			if err := writer.Write(data); err != nil {
+				t.Fatalf("write failed: %v", err)
+			}
+			if err := writer.Close(); err != nil {
+				t.Fatalf("close failed: %v", err)
+			}
+
+			content, err := os.ReadFile(filename)
+			if err != nil {
+				t.Fatalf("failed to read output file: %v", err)
+			}
+			got := strings.Split(strings.TrimRight(string(content), "\n"), "\n")
+			if strings.Join(got, "|") != strings.Join(tt.want, "|") {
+				t.Errorf("got %q, want %q", got, tt.want)
+			}
+		})
+	}
+
+	writer, err := NewCSVWriterWithSchema(filepath.Join(t.TempDir(), "bad.csv"), nil, false)
+	if err != nil {
+		t.Fatalf("failed to create CSV writer: %v", err)
+	}
+	defer writer.Close()
+	for _, delimiter := range []string{";;", `"`, "\n"} {
+		if err := writer.SetCSVOptions(CSVOptions{Delimiter: delimiter}); err == nil {
+			t.Errorf("expected error for delimiter %q", delimiter)
+		}
+	}
+}
@@ -0,0 +1,52 @@
+// internal/output/encoding.go
+package output
+
+import (
+	"bytes"
+	"fmt"
+	"io"
+)
+
+// Line endings for text outputs (output.line_ending)
+const (
+	LineEndingLF   = "lf"   // Unix line endings (default)
+	LineEndingCRLF = "crlf" // Windows line endings, for Excel and other Windows tools
+)
+
+// utf8BOM marks a file as UTF-8 for spreadsheet tools that otherwise assume a legacy code page
+var utf8BOM = []byte{0xEF, 0xBB, 0xBF}
+
+// TextEncoding controls the byte-level layout of text outputs (CSV, JSON, JSON Lines)
+type TextEncoding struct {
+	BOM        bool   // Start the file with a UTF-8 byte order mark
+	LineEnding string // lf (default) or crlf
+}
+
+// validate reports an unsupported line ending
+func (te TextEncoding) validate() error {
+	switch te.LineEnding {
+	case "", LineEndingLF, LineEndingCRLF:
+		return nil
+	default:
+		return fmt.Errorf("unsupported line ending: %s", te.LineEnding)
+	}
+}
+
+// crlf reports whether lines should end in \r\n
+func (te TextEncoding) crlf() bool {
+	return te.LineEnding == LineEndingCRLF
+}
+
+// crlfWriter rewrites \n as \r\n. It is only safe for formats that never emit a
+// bare \n inside a value, such as encoding/json output.
+type crlfWriter struct {
+	w io.Writer
+}
+
+// Write writes p with every \n expanded to \r\n
+func (c crlfWriter) Write(p []byte) (int, error) {
+	if _, err := c.w.Write(bytes.ReplaceAll(p, []byte("\n"), []byte("\r\n"))); err != nil {
+		return 0, err
+	}
+	return len(p), nil
+}
@@ -3,13 +3,15 @@ package output
 
 import (
 	"context"
+	"encoding/json"
 	"fmt"
-	"log"
 	"sort"
 	"strconv"
 	"strings"
 	"time"
+	"unicode/utf8"
 
+	"github.com/valpere/DataScrapexter/internal/utils"
 	"github.com/xuri/excelize/v2"
 )
 
@@ -20,15 +22,17 @@ type Logger interface {
 	Infof(format string, args ...interface{})
 }
 
-// DefaultLogger implements Logger using standard log package
+// DefaultLogger implements Logger with the excel-output component logger
 type DefaultLogger struct{}
 
+var excelLogger = utils.NewComponentLogger("excel-output")
+
 func (l *DefaultLogger) Warnf(format string, args ...interface{}) {
-	log.Printf("[WARN] "+format, args...)
+	excelLogger.Warnf(format, args...)
 }
 
 func (l *DefaultLogger) Infof(format string, args ...interface{}) {
-	log.Printf("[INFO] "+format, args...)
+	excelLogger.Infof(format, args...)
 }
 
 // Excel-specific default limits (can be overridden via ExcelConfig)
@@ -41,9 +45,9 @@ const (
 	// DefaultExcelMaxArrayElements is the default maximum array elements to prevent memory issues
 	// This prevents excessive memory usage when processing large arrays in Excel cells
 	DefaultExcelMaxArrayElements = 1000
-	// DefaultExcelStringBuilderThreshold is the threshold for using strings.Builder for efficiency
-	// Arrays larger than this will use strings.Builder for better performance
-	DefaultExcelStringBuilderThreshold = 100
+	// Auto-sized columns fit their longest cell, within these widths (in characters)
+	excelMinColumnWidth = 8
+	excelMaxColumnWidth = 80
 )
 
 // ExcelWriter implements the Writer interface for Excel output
@@ -52,6 +56,7 @@ type ExcelWriter struct {
 	config    ExcelConfig
 	sheetName string
 	headers   []string
+	widths    []int // Longest cell text per column, for auto-sizing
 	row       int
 	records   []map[string]interface{}
 }
@@ -155,7 +160,10 @@ func NewExcelWriter(config ExcelConfig) (*ExcelWriter, error) {
 	// Create or rename the default sheet
 	defaultSheet := file.GetSheetName(0)
 	if defaultSheet != config.SheetName {
-		file.SetSheetName(defaultSheet, config.SheetName)
+		if err := file.SetSheetName(defaultSheet, config.SheetName); err != nil {
+			file.Close()
+			return nil, fmt.Errorf("invalid sheet name %q: %w", config.SheetName, err)
+		}
 	}
 
 	writer := &ExcelWriter{
@@ -219,8 +227,17 @@ func (w *ExcelWriter) Flush() error {
 	return w.flush()
 }
 
-// Close closes the Excel writer and saves the file
+// Close saves the file and releases the workbook, even when saving fails
 func (w *ExcelWriter) Close() error {
+	err := w.save()
+	if closeErr := w.file.Close(); err == nil {
+		err = closeErr
+	}
+	return err
+}
+
+// save writes out the buffered records and saves the workbook
+func (w *ExcelWriter) save() error {
 	// Flush any remaining records
 	if err := w.flush(); err != nil {
 		return err
@@ -231,7 +248,6 @@ func (w *ExcelWriter) Close() error {
 		return err
 	}
 
-	// Save the file
 	return w.file.SaveAs(w.config.FilePath)
 }
 
@@ -246,13 +262,10 @@ func (w *ExcelWriter) flush() error {
 		return nil
 	}
 
-	// Extract headers if not already done
-	if w.headers == nil {
-		w.extractHeaders()
-		if w.config.IncludeHeaders {
-			if err := w.writeHeaders(); err != nil {
-				return err
-			}
+	// Keys not seen before add columns, so the header becomes the union of all keys
+	if w.addHeaders() && w.config.IncludeHeaders {
+		if err := w.writeHeaders(); err != nil {
+			return err
 		}
 	}
 
@@ -267,36 +280,44 @@ func (w *ExcelWriter) flush() error {
 	return nil
 }
 
-// extractHeaders extracts all unique column headers from records
-func (w *ExcelWriter) extractHeaders() {
-	headerSet := make(map[string]bool)
+// addHeaders appends the buffered records' new keys, sorted, after the existing
+// columns and reports whether the header changed. The first call also adds the
+// index column if requested.
+func (w *ExcelWriter) addHeaders() bool {
+	known := make(map[string]bool, len(w.headers))
+	for _, header := range w.headers {
+		known[header] = true
+	}
 
+	var added []string
 	for _, record := range w.records {
 		for key := range record {
-			headerSet[key] = true
+			if !known[key] {
+				known[key] = true
+				added = append(added, key)
+			}
 		}
 	}
-
-	// Convert to sorted slice for consistent order
-	w.headers = make([]string, 0, len(headerSet))
-	for header := range headerSet {
-		w.headers = append(w.headers, header)
+	if w.headers != nil && len(added) == 0 {
+		return false
 	}
-	sort.Strings(w.headers)
+	sort.Strings(added)
 
-	// Add index column if requested
-	if w.config.CreateIndex {
-		w.headers = append([]string{"Index"}, w.headers...)
+	if w.headers == nil && w.config.CreateIndex {
+		w.headers = []string{"Index"}
 	}
+	w.headers = append(w.headers, added...)
+	return true
 }
 
-// writeHeaders writes the header row
+// writeHeaders writes the header row, the first row of the current sheet
 func (w *ExcelWriter) writeHeaders() error {
 	for col, header := range w.headers {
-		cell := columnName(col+1) + strconv.Itoa(w.row)
+		cell := columnName(col+1) + "1"
 		if err := w.file.SetCellValue(w.sheetName, cell, header); err != nil {
 			return err
 		}
+		w.fitColumn(col, header)
 
 		// Apply header style
 		if err := w.applyHeaderStyle(cell); err != nil {
@@ -304,7 +325,9 @@ func (w *ExcelWriter) writeHeaders() error {
 		}
 	}
 
-	w.row++
+	if w.row == 1 {
+		w.row++
+	}
 	return nil
 }
 
@@ -312,7 +335,9 @@ func (w *ExcelWriter) writeHeaders() error {
 func (w *ExcelWriter) writeRecord(record map[string]interface{}) error {
 	// Check if we need to create a new sheet (row limit reached)
 	if w.row > w.config.MaxSheetRows {
-		return w.createNewSheet()
+		if err := w.createNewSheet(); err != nil {
+			return err
+		}
 	}
 
 	for col, header := range w.headers {
@@ -334,6 +359,11 @@ func (w *ExcelWriter) writeRecord(record map[string]interface{}) error {
 		if err := w.file.SetCellValue(w.sheetName, cell, processedValue); err != nil {
 			return err
 		}
+		if t, ok := processedValue.(time.Time); ok {
+			w.fitColumn(col, t.Format(time.DateTime))
+		} else {
+			w.fitColumn(col, fmt.Sprintf("%v", processedValue))
+		}
 
 		// Apply data style
 		if err := w.applyDataStyle(cell, value); err != nil {
@@ -351,20 +381,21 @@ func (w *ExcelWriter) processValue(value interface{}) interface{} {
 		return ""
 	}
 
+	// Maps and slices go into the cell as JSON text
+	if arr, ok := value.([]interface{}); ok {
+		value = w.truncateArray(arr)
+	}
+	if isNested(value) {
+		encoded, err := json.Marshal(value)
+		if err != nil {
+			return fmt.Sprintf("%v", value)
+		}
+		value = string(encoded)
+	}
+
 	switch v := value.(type) {
 	case time.Time:
 		return v
-	case []interface{}:
-		// Convert arrays to comma-separated strings with configurable limits
-		truncatedArray := w.truncateArray(v)
-		return w.arrayToString(truncatedArray)
-	case map[string]interface{}:
-		// Convert objects to JSON-like strings
-		var parts []string
-		for key, val := range v {
-			parts = append(parts, fmt.Sprintf("%s: %v", key, val))
-		}
-		return "{" + strings.Join(parts, ", ") + "}"
 	case string:
 		// Handle very long strings using configurable limit
 		maxLength := w.config.MaxCellLength
@@ -530,35 +561,27 @@ func (w *ExcelWriter) truncateArray(arr []interface{}) []interface{} {
 	return arr
 }
 
-// arrayToString converts an array to a comma-separated string efficiently
-func (w *ExcelWriter) arrayToString(arr []interface{}) string {
-	// Use efficient string builder for large arrays
-	if len(arr) > DefaultExcelStringBuilderThreshold {
-		var builder strings.Builder
-		builder.Grow(len(arr) * 10) // Pre-allocate space estimation
-		for i, item := range arr {
-			if i > 0 {
-				builder.WriteString(", ")
-			}
-			builder.WriteString(fmt.Sprintf("%v", item))
-		}
-		return builder.String()
-	} else {
-		// Use slice approach for smaller arrays
-		var parts []string
-		for _, item := range arr {
-			parts = append(parts, fmt.Sprintf("%v", item))
+// fitColumn records the width of text in column col, for auto-sizing
+func (w *ExcelWriter) fitColumn(col int, text string) {
+	for len(w.widths) <= col {
+		w.widths = append(w.widths, 0)
+	}
+	for _, line := range strings.Split(text, "\n") {
+		if n := utf8.RuneCountInString(line); n > w.widths[col] {
+			w.widths[col] = n
 		}
-		return strings.Join(parts, ", ")
 	}
 }
 
 // applyFinalFormatting applies final formatting to the worksheet
 func (w *ExcelWriter) applyFinalFormatting() error {
-	// Set column widths
+	// Size columns to their longest cell unless a width is configured
 	for col, header := range w.headers {
 		colName := columnName(col + 1)
-		width := 15.0 // Default width
+		width := float64(excelMinColumnWidth)
+		if col < len(w.widths) {
+			width = float64(min(max(w.widths[col]+2, excelMinColumnWidth), excelMaxColumnWidth))
+		}
 
 		if w.config.ColumnWidths != nil {
 			if customWidth, exists := w.config.ColumnWidths[header]; exists {
@@ -582,13 +605,14 @@ func (w *ExcelWriter) applyFinalFormatting() error {
 		}
 	}
 
-	// Freeze pane
+	// Freeze the header row so it stays visible while scrolling
 	if w.config.FreezePane && w.config.IncludeHeaders {
 		if err := w.file.SetPanes(w.sheetName, &excelize.Panes{
-			Freeze: true,
-			Split:  false,
-			XSplit: 1,
-			YSplit: 1,
+			Freeze:      true,
+			Split:       false,
+			YSplit:      1,
+			TopLeftCell: "A2",
+			ActivePane:  "bottomLeft",
 		}); err != nil {
 			return err
 		}
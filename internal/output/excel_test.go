@@ -0,0 +1,91 @@
+// internal/output/excel_test.go
+package output
+
+import (
+	"path/filepath"
+	"reflect"
+	"testing"
+
+	"github.com/valpere/DataScrapexter/internal/config"
+	"github.com/xuri/excelize/v2"
+)
+
+func TestManagerXLSXOutput(t *testing.T) {
+	filename := filepath.Join(t.TempDir(), "products.xlsx")
+	manager, err := NewManager(&config.OutputConfig{Format: "xlsx", File: filename, SheetName: "Products"})
+	if err != nil {
+		t.Fatal(err)
+	}
+	records := []map[string]interface{}{
+		{"title": "Desk Lamp", "price": map[string]interface{}{"amount": 19.5, "currency": "EUR"}},
+		{"title": "Chair", "tags": []interface{}{"office", "seating"}, "url": "https://shop.test/products/chair-with-a-long-name"},
+	}
+	if err := manager.Write(records); err != nil {
+		t.Fatalf("Write() error = %v", err)
+	}
+
+	file, err := excelize.OpenFile(filename)
+	if err != nil {
+		t.Fatalf("OpenFile() error = %v", err)
+	}
+	defer file.Close()
+
+	if sheets := file.GetSheetList(); !reflect.DeepEqual(sheets, []string{"Products"}) {
+		t.Fatalf("sheets = %v, want [Products]", sheets)
+	}
+	rows, err := file.GetRows("Products")
+	if err != nil {
+		t.Fatal(err)
+	}
+	want := [][]string{
+		{"price", "tags", "title", "url"},
+		{`{"amount":19.5,"currency":"EUR"}`, "", "Desk Lamp"},
+		{"", `["office","seating"]`, "Chair", "https://shop.test/products/chair-with-a-long-name"},
+	}
+	if !reflect.DeepEqual(rows, want) {
+		t.Errorf("rows =\n%q\nwant\n%q", rows, want)
+	}
+
+	panes, err := file.GetPanes("Products")
+	if err != nil {
+		t.Fatal(err)
+	}
+	if !panes.Freeze || panes.YSplit != 1 || panes.XSplit != 0 {
+		t.Errorf("panes = %+v, want the header row frozen", panes)
+	}
+
+	urlWidth, _ := file.GetColWidth("Products", "D")
+	titleWidth, _ := file.GetColWidth("Products", "C")
+	if urlWidth <= titleWidth || titleWidth < excelMinColumnWidth {
+		t.Errorf("column widths url = %v, title = %v; want them sized to their contents", urlWidth, titleWidth)
+	}
+}
+
+func TestExcelWriterHeaderUnionAcrossFlushes(t *testing.T) {
+	filename := filepath.Join(t.TempDir(), "out.xlsx")
+	writer, err := NewExcelWriter(ExcelConfig{FilePath: filename, IncludeHeaders: true, BufferSize: 1})
+	if err != nil {
+		t.Fatal(err)
+	}
+	writer.Write([]map[string]interface{}{{"title": "Lamp"}, {"title": "Desk", "sku": "D-1"}})
+	if err := writer.Close(); err != nil {
+		t.Fatalf("Close() error = %v", err)
+	}
+
+	file, err := excelize.OpenFile(filename)
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer file.Close()
+	rows, _ := file.GetRows("Sheet1")
+	want := [][]string{{"title", "sku"}, {"Lamp"}, {"Desk", "D-1"}}
+	if !reflect.DeepEqual(rows, want) {
+		t.Errorf("rows = %q, want %q", rows, want)
+	}
+}
+
+func TestNewExcelWriterInvalidSheetName(t *testing.T) {
+	if _, err := NewExcelWriter(ExcelConfig{FilePath: filepath.Join(t.TempDir(), "out.xlsx"), SheetName: "a/b"}); err == nil {
+		t.Error("expected an error for a sheet name containing /")
+	}
+}
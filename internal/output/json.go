@@ -3,6 +3,8 @@ package output
 
 import (
 	"encoding/json"
+	"fmt"
+	"io"
 	"os"
 )
 
@@ -10,6 +12,8 @@ import (
 type JSONWriter struct {
 	filename string
 	file     *os.File
+	out      io.Writer // file, or a line-ending translator over it
+	written  bool
 }
 
 // NewJSONWriter creates a new JSON writer
@@ -22,19 +26,41 @@ func NewJSONWriter(filename string) (*JSONWriter, error) {
 	return &JSONWriter{
 		filename: filename,
 		file:     file,
+		out:      file,
 	}, nil
 }
 
+// SetTextEncoding applies a byte order mark and line ending. It must be called before the first write.
+func (w *JSONWriter) SetTextEncoding(encoding TextEncoding) error {
+	if err := encoding.validate(); err != nil {
+		return err
+	}
+	if w.written {
+		return fmt.Errorf("text encoding must be set before writing")
+	}
+	if encoding.BOM {
+		if _, err := w.file.Write(utf8BOM); err != nil {
+			return err
+		}
+	}
+	if encoding.crlf() {
+		w.out = crlfWriter{w: w.file}
+	}
+	return nil
+}
+
 // Write writes data to JSON file
 func (w *JSONWriter) Write(data []map[string]interface{}) error {
-	encoder := json.NewEncoder(w.file)
+	w.written = true
+	encoder := json.NewEncoder(w.out)
 	encoder.SetIndent("", "  ")
 	return encoder.Encode(data)
 }
 
 // WriteRecord writes a single record to JSON file
 func (w *JSONWriter) WriteRecord(record map[string]interface{}) error {
-	encoder := json.NewEncoder(w.file)
+	w.written = true
+	encoder := json.NewEncoder(w.out)
 	encoder.SetIndent("", "  ")
 	return encoder.Encode(record)
 }
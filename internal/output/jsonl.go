@@ -0,0 +1,82 @@
+// internal/output/jsonl.go
+package output
+
+import (
+	"encoding/json"
+	"fmt"
+	"io"
+	"os"
+)
+
+// JSONLWriter writes one compact JSON object per line. Each record reaches the
+// underlying writer as soon as it is written, so a reader on the other end of a
+// pipe sees records while the scrape is still running.
+type JSONLWriter struct {
+	file    *os.File // set only when the writer owns its destination
+	out     io.Writer
+	encoder *json.Encoder
+	written bool
+}
+
+// NewJSONLWriter creates a JSON Lines writer for filename
+func NewJSONLWriter(filename string) (*JSONLWriter, error) {
+	file, err := os.Create(filename)
+	if err != nil {
+		return nil, err
+	}
+
+	writer := NewJSONLStreamWriter(file)
+	writer.file = file
+	return writer, nil
+}
+
+// NewJSONLStreamWriter creates a JSON Lines writer over out, e.g. os.Stdout.
+// Close does not close out.
+func NewJSONLStreamWriter(out io.Writer) *JSONLWriter {
+	return &JSONLWriter{out: out, encoder: json.NewEncoder(out)}
+}
+
+// SetTextEncoding applies a byte order mark and line ending. It must be called before the first write.
+func (w *JSONLWriter) SetTextEncoding(encoding TextEncoding) error {
+	if err := encoding.validate(); err != nil {
+		return err
+	}
+	if w.written {
+		return fmt.Errorf("text encoding must be set before writing")
+	}
+	if encoding.BOM {
+		if _, err := w.out.Write(utf8BOM); err != nil {
+			return err
+		}
+	}
+	if encoding.crlf() {
+		w.encoder = json.NewEncoder(crlfWriter{w: w.out})
+	}
+	return nil
+}
+
+// Write writes each record on its own line
+func (w *JSONLWriter) Write(data []map[string]interface{}) error {
+	for _, record := range data {
+		if err := w.WriteRecord(record); err != nil {
+			return err
+		}
+	}
+	return nil
+}
+
+// WriteRecord writes a single record as one line
+func (w *JSONLWriter) WriteRecord(record map[string]interface{}) error {
+	w.written = true
+	return w.encoder.Encode(record)
+}
+
+// Close closes the destination file if the writer created it
+func (w *JSONLWriter) Close() error {
+	if w.file != nil {
+		err := w.file.Close()
+		w.file = nil
+		return err
+	}
+	return nil
+}
@@ -0,0 +1,33 @@
+// internal/output/jsonl_test.go
+package output
+
+import (
+	"bytes"
+	"strings"
+	"testing"
+)
+
+func TestJSONLStreamWriter(t *testing.T) {
+	var buf bytes.Buffer
+	writer := NewJSONLStreamWriter(&buf)
+
+	if err := writer.WriteRecord(map[string]interface{}{"title": "First"}); err != nil {
+		t.Fatalf("failed to write record: %v", err)
+	}
+	// Each record must be on the stream before the next one is scraped
+	if got := buf.String(); got != "{\"title\":\"First\"}\n" {
+		t.Errorf("expected first record flushed as one line, got %q", got)
+	}
+
+	if err := writer.Write([]map[string]interface{}{{"title": "Second"}, {"title": "Third"}}); err != nil {
+		t.Fatalf("failed to write records: %v", err)
+	}
+	if err := writer.Close(); err != nil {
+		t.Fatalf("failed to close writer: %v", err)
+	}
+
+	lines := strings.Split(strings.TrimSuffix(buf.String(), "\n"), "\n")
+	if len(lines) != 3 || lines[2] != `{"title":"Third"}` {
+		t.Errorf("expected 3 compact lines, got %q", lines)
+	}
+}
@@ -11,22 +11,59 @@ import (
 type Manager struct {
 	config        *Config
 	formatOptions *FormatOptions
+	sinks         []*Manager           // One per output.outputs entry; config is unused when set
+	policy        *config.OutputPolicy // Confines the files of partitions, which are only named at write time
 }
 
 // NewManager creates a new output manager
 func NewManager(cfg *config.OutputConfig) (*Manager, error) {
+	return NewManagerWithPolicy(cfg, nil)
+}
+
+// NewManagerWithPolicy creates an output manager whose partition files must
+// resolve within policy. cfg should have passed ValidateWithPolicy; nil policy
+// behaves like NewManager.
+func NewManagerWithPolicy(cfg *config.OutputConfig, policy *config.OutputPolicy) (*Manager, error) {
 	if cfg == nil {
 		return nil, fmt.Errorf("output configuration is required")
 	}
 
+	if len(cfg.Outputs) > 0 {
+		m := &Manager{config: &Config{}, formatOptions: &FormatOptions{}, policy: policy}
+		for i := range cfg.Outputs {
+			sink, err := NewManagerWithPolicy(&cfg.Outputs[i], policy)
+			if err != nil {
+				return nil, fmt.Errorf("output %d: %w", i, err)
+			}
+			m.sinks = append(m.sinks, sink)
+		}
+		return m, nil
+	}
+
 	config := &Config{
-		Format: OutputFormat(cfg.Format),
-		File:   cfg.File,
+		Format:           OutputFormat(cfg.Format),
+		File:             cfg.File,
+		EnableMetrics:    cfg.EnableMetrics,
+		Columns:          cfg.Columns,
+		CSVUnion:         cfg.CSVUnion,
+		NestedEncoding:   cfg.NestedEncoding,
+		BOM:              cfg.BOM,
+		LineEnding:       cfg.LineEnding,
+		ParquetSchema:    cfg.ParquetSchema,
+		SheetName:        cfg.SheetName,
+		PartitionBy:      cfg.PartitionBy,
+		PartitionDefault: cfg.PartitionDefault,
+	}
+
+	options := &FormatOptions{}
+	if cfg.CSV != nil {
+		options.CSV = CSVOptions{Delimiter: cfg.CSV.Delimiter, QuoteAll: cfg.CSV.QuoteAll, Columns: cfg.CSV.Columns}
 	}
 
 	return &Manager{
 		config:        config,
-		formatOptions: &FormatOptions{},
+		formatOptions: options,
+		policy:        policy,
 	}, nil
 }
 
@@ -50,9 +87,45 @@ func NewManagerWithOptions(cfg *Config, options *FormatOptions) (*Manager, error
 func (m *Manager) GetWriter() (Writer, error) {
 	switch m.config.Format {
 	case FormatJSON:
-		return NewJSONWriter(m.config.File)
+		writer, err := NewJSONWriter(m.config.File)
+		if err != nil {
+			return nil, err
+		}
+		if err := writer.SetTextEncoding(m.textEncoding()); err != nil {
+			writer.Close()
+			return nil, err
+		}
+		return writer, nil
+	case FormatJSONL, FormatJSONLines:
+		// Records go straight to the file one line at a time
+		writer, err := NewJSONLWriter(m.config.File)
+		if err != nil {
+			return nil, err
+		}
+		if err := writer.SetTextEncoding(m.textEncoding()); err != nil {
+			writer.Close()
+			return nil, err
+		}
+		return writer, nil
 	case FormatCSV:
-		return NewCSVWriter(m.config.File)
+		writer, err := NewCSVWriterWithSchema(m.config.File, m.config.Columns, m.config.CSVUnion)
+		if err != nil {
+			return nil, err
+		}
+		if err := m.configureCSV(writer); err != nil {
+			writer.Close()
+			return nil, err
+		}
+		return writer, nil
+	case FormatParquet:
+		return NewParquetWriter(m.config.File, m.config.ParquetSchema)
+	case FormatExcel, FormatXLSX:
+		return NewExcelWriter(ExcelConfig{
+			FilePath:       m.config.File,
+			SheetName:      m.config.SheetName,
+			IncludeHeaders: true,
+			FreezePane:     true,
+		})
 	case FormatPostgreSQL:
 		return m.createPostgreSQLWriter()
 	case FormatSQLite:
@@ -62,15 +135,64 @@ func (m *Manager) GetWriter() (Writer, error) {
 	}
 }
 
+// configureCSV applies the nested encoding, CSV options and text encoding to writer
+func (m *Manager) configureCSV(writer *CSVWriter) error {
+	if err := writer.SetNestedEncoding(m.config.NestedEncoding); err != nil {
+		return err
+	}
+	if err := writer.SetCSVOptions(m.formatOptions.CSV); err != nil {
+		return err
+	}
+	return writer.SetTextEncoding(m.textEncoding())
+}
+
+// textEncoding returns the BOM and line ending settings for text writers
+func (m *Manager) textEncoding() TextEncoding {
+	return TextEncoding{BOM: m.config.BOM, LineEnding: m.config.LineEnding}
+}
+
 // Write writes data using the configured format
 func (m *Manager) Write(data []map[string]interface{}) error {
+	if len(m.sinks) > 0 {
+		return m.writeSinks(data)
+	}
+	if m.config.PartitionBy != "" {
+		return m.writePartitions(data)
+	}
+
 	writer, err := m.GetWriter()
 	if err != nil {
 		return fmt.Errorf("failed to get writer: %w", err)
 	}
-	defer writer.Close()
 
-	return writer.Write(data)
+	if err := writer.Write(data); err != nil {
+		writer.Close()
+		return err
+	}
+
+	// Buffering writers (e.g. CSV union mode) emit their data on Close
+	if err := writer.Close(); err != nil {
+		return fmt.Errorf("failed to finalize output: %w", err)
+	}
+
+	// Metrics summary sits next to file-based outputs only
+	if m.config.EnableMetrics && m.config.File != "" {
+		if err := WriteMetricsFile(m.config.File, data); err != nil {
+			return fmt.Errorf("failed to write output metrics: %w", err)
+		}
+	}
+
+	return nil
+}
+
+// writeSinks writes data to every output in turn, stopping at the first failure
+func (m *Manager) writeSinks(data []map[string]interface{}) error {
+	for _, sink := range m.sinks {
+		if err := sink.Write(data); err != nil {
+			return fmt.Errorf("output %s (%s): %w", sink.config.File, sink.config.Format, err)
+		}
+	}
+	return nil
 }
 
 // WriteResults writes scraping results using the configured format
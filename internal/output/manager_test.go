@@ -2,6 +2,11 @@
 package output
 
 import (
+	"bytes"
+	"encoding/json"
+	"os"
+	"path/filepath"
+	"strings"
 	"testing"
 
 	"github.com/valpere/DataScrapexter/internal/config"
@@ -115,3 +120,182 @@ func TestManagerWrite(t *testing.T) {
 		t.Errorf("failed to write data: %v", err)
 	}
 }
+
+func TestManagerWriteMultipleOutputs(t *testing.T) {
+	dir := t.TempDir()
+	manager, err := NewManager(&config.OutputConfig{Outputs: []config.OutputConfig{
+		{Format: "json", File: filepath.Join(dir, "items.json")},
+		{Format: "csv", File: filepath.Join(dir, "items.csv"), Columns: []string{"title"}},
+	}})
+	if err != nil {
+		t.Fatalf("failed to create manager: %v", err)
+	}
+
+	data := []map[string]interface{}{{"title": "TV", "price": 499}}
+	if err := manager.Write(data); err != nil {
+		t.Fatalf("write failed: %v", err)
+	}
+
+	var records []map[string]interface{}
+	content, err := os.ReadFile(filepath.Join(dir, "items.json"))
+	if err != nil {
+		t.Fatalf("failed to read JSON output: %v", err)
+	}
+	if err := json.Unmarshal(content, &records); err != nil || len(records) != 1 || records[0]["price"] != 499.0 {
+		t.Errorf("unexpected JSON output %s (%v)", content, err)
+	}
+
+	content, err = os.ReadFile(filepath.Join(dir, "items.csv"))
+	if err != nil {
+		t.Fatalf("failed to read CSV output: %v", err)
+	}
+	if string(content) != "title\nTV\n" {
+		t.Errorf("CSV output = %q, want %q", content, "title\nTV\n")
+	}
+
+	// A failing output is named in the error
+	manager, err = NewManager(&config.OutputConfig{Outputs: []config.OutputConfig{
+		{Format: "json", File: filepath.Join(dir, "ok.json")},
+		{Format: "xls", File: filepath.Join(dir, "bad.xls")},
+	}})
+	if err != nil {
+		t.Fatalf("failed to create manager: %v", err)
+	}
+	if err := manager.Write(data); err == nil || !strings.Contains(err.Error(), "bad.xls") {
+		t.Errorf("expected error naming the failing output, got %v", err)
+	}
+}
+
+func TestManagerWriteJSONLines(t *testing.T) {
+	for _, format := range []string{"jsonl", "jsonlines"} {
+		t.Run(format, func(t *testing.T) {
+			file := filepath.Join(t.TempDir(), "items.jsonl")
+			manager, err := NewManager(&config.OutputConfig{Format: format, File: file})
+			if err != nil {
+				t.Fatalf("failed to create manager: %v", err)
+			}
+
+			data := []map[string]interface{}{
+				{"title": "TV", "specs": map[string]interface{}{"size": 55, "ports": []string{"hdmi", "usb"}}},
+				{"title": "Radio"},
+			}
+			if err := manager.Write(data); err != nil {
+				t.Fatalf("write failed: %v", err)
+			}
+
+			content, err := os.ReadFile(file)
+			if err != nil {
+				t.Fatalf("failed to read output: %v", err)
+			}
+			lines := strings.Split(strings.TrimSuffix(string(content), "\n"), "\n")
+			if len(lines) != 2 {
+				t.Fatalf("expected one line per record, got %q", content)
+			}
+			var record map[string]interface{}
+			if err := json.Unmarshal([]byte(lines[0]), &record); err != nil {
+				t.Fatalf("line 1 is not a JSON object: %v", err)
+			}
+			specs, ok := record["specs"].(map[string]interface{})
+			if !ok || specs["size"] != 55.0 || len(specs["ports"].([]interface{})) != 2 {
+				t.Errorf("nested map not preserved: %s", lines[0])
+			}
+		})
+	}
+
+	if ext := FormatJSONLines.GetFileExtension(); ext != ".jsonl" {
+		t.Errorf("jsonlines extension = %q, want .jsonl", ext)
+	}
+}
+
+func TestManagerWriteTextEncoding(t *testing.T) {
+	data := []map[string]interface{}{{"name": "Crème brûlée", "price": 7}}
+
+	tests := []struct {
+		name       string
+		format     string
+		bom        bool
+		lineEnding string
+		want       string
+	}{
+		{"csv default", "csv", false, "", "name,price\nCrème brûlée,7\n"},
+		{"csv bom crlf", "csv", true, "crlf", "\xEF\xBB\xBFname,price\r\nCrème brûlée,7\r\n"},
+		{"json bom crlf", "json", true, "crlf", "\xEF\xBB\xBF[\r\n  {\r\n    \"name\": \"Crème brûlée\",\r\n    \"price\": 7\r\n  }\r\n]\r\n"},
+		{"jsonl bom crlf", "jsonl", true, "crlf", "\xEF\xBB\xBF{\"name\":\"Crème brûlée\",\"price\":7}\r\n"},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			filename := filepath.Join(t.TempDir(), "out."+tt.format)
+			manager, err := NewManager(&config.OutputConfig{
+				Format:     tt.format,
+				File:       filename,
+				BOM:        tt.bom,
+				LineEnding: tt.lineEnding,
+			})
+			if err != nil {
+				t.Fatalf("failed to create manager: %v", err)
+			}
+			if err := manager.Write(data); err != nil {
+				t.Fatalf("write failed: %v", err)
+			}
+
+			content, err := os.ReadFile(filename)
+			if err != nil {
+				t.Fatalf("failed to read output: %v", err)
+			}
+			if string(content) != tt.want {
+				t.Errorf("got %q, want %q", content, tt.want)
+			}
+		})
+	}
+
+	manager, err := NewManager(&config.OutputConfig{Format: "csv", File: filepath.Join(t.TempDir(), "bad.csv"), LineEnding: "cr"})
+	if err != nil {
+		t.Fatalf("failed to create manager: %v", err)
+	}
+	if _, err := manager.GetWriter(); err == nil {
+		t.Error("expected error for unsupported line ending")
+	}
+}
+
+func TestManagerStreamWriter(t *testing.T) {
+	data := []map[string]interface{}{
+		{"name": "Lamp", "price": 25},
+		{"name": "Desk, oak", "price": 180},
+	}
+
+	tests := []struct {
+		name   string
+		output config.OutputConfig
+		want   string
+	}{
+		{"json as lines", config.OutputConfig{Format: "json"}, "{\"name\":\"Lamp\",\"price\":25}\n{\"name\":\"Desk, oak\",\"price\":180}\n"},
+		{"csv with header", config.OutputConfig{Format: "csv"}, "name,price\nLamp,25\n\"Desk, oak\",180\n"},
+		{"csv columns", config.OutputConfig{Format: "csv", Columns: []string{"price"}}, "price\n25\n180\n"},
+		{"yaml sequence", config.OutputConfig{Format: "yaml"}, "- name: Lamp\n  price: 25\n- name: Desk, oak\n  price: 180\n"},
+		{"first of several outputs", config.OutputConfig{Outputs: []config.OutputConfig{{Format: "csv", File: "a.csv"}, {Format: "json", File: "a.json"}}}, "name,price\nLamp,25\n\"Desk, oak\",180\n"},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			manager, err := NewManager(&tt.output)
+			if err != nil {
+				t.Fatalf("NewManager failed: %v", err)
+			}
+			var out bytes.Buffer
+			writer, err := manager.StreamWriter(&out)
+			if err != nil {
+				t.Fatalf("StreamWriter failed: %v", err)
+			}
+			if err := writer.Write(data); err != nil {
+				t.Fatalf("Write failed: %v", err)
+			}
+			if err := writer.Close(); err != nil {
+				t.Fatalf("Close failed: %v", err)
+			}
+			if out.String() != tt.want {
+				t.Errorf("output = %q, want %q", out.String(), tt.want)
+			}
+		})
+	}
+}
@@ -0,0 +1,198 @@
+// internal/output/merge.go
+package output
+
+import (
+	"bufio"
+	"context"
+	"encoding/csv"
+	"encoding/json"
+	"fmt"
+	"io"
+	"os"
+	"path/filepath"
+	"strings"
+)
+
+// Deduplicator drops records it has already seen by returning nil;
+// pipeline.RecordDeduplicator satisfies it
+type Deduplicator interface {
+	Deduplicate(ctx context.Context, record map[string]interface{}) (map[string]interface{}, error)
+}
+
+// MergeStats reports what a merge read and wrote
+type MergeStats struct {
+	Read       int
+	Written    int
+	Duplicates int
+}
+
+// mergeFormat picks the file format from the file extension
+func mergeFormat(filename string) (string, error) {
+	switch strings.ToLower(filepath.Ext(filename)) {
+	case ".json":
+		return "json", nil
+	case ".jsonl", ".ndjson":
+		return "jsonl", nil
+	case ".csv":
+		return "csv", nil
+	default:
+		return "", fmt.Errorf("cannot merge %s: unsupported extension (want .json, .jsonl, .ndjson or .csv)", filename)
+	}
+}
+
+// ReadRecords reads the records of a JSON, JSON Lines or CSV output file.
+// A JSON file may hold an array of records or a single record; CSV values are strings.
+func ReadRecords(filename string) ([]map[string]interface{}, error) {
+	format, err := mergeFormat(filename)
+	if err != nil {
+		return nil, err
+	}
+
+	file, err := os.Open(filename)
+	if err != nil {
+		return nil, err
+	}
+	defer file.Close()
+
+	// Files written with output.bom start with a byte order mark
+	reader := bufio.NewReader(file)
+	if bom, err := reader.Peek(len(utf8BOM)); err == nil && string(bom) == string(utf8BOM) {
+		reader.Discard(len(utf8BOM))
+	}
+
+	var records []map[string]interface{}
+	switch format {
+	case "json":
+		records, err = readJSONRecords(reader)
+	case "jsonl":
+		records, err = readJSONLRecords(reader)
+	case "csv":
+		records, err = readCSVRecords(reader)
+	}
+	if err != nil {
+		return nil, fmt.Errorf("failed to read %s: %w", filename, err)
+	}
+	return records, nil
+}
+
+// readJSONRecords reads an array of objects or a single object
+func readJSONRecords(r io.Reader) ([]map[string]interface{}, error) {
+	var raw json.RawMessage
+	if err := json.NewDecoder(r).Decode(&raw); err != nil {
+		return nil, err
+	}
+
+	var records []map[string]interface{}
+	if err := json.Unmarshal(raw, &records); err == nil {
+		return records, nil
+	}
+	var record map[string]interface{}
+	if err := json.Unmarshal(raw, &record); err != nil {
+		return nil, fmt.Errorf("expected an array of objects or an object")
+	}
+	return []map[string]interface{}{record}, nil
+}
+
+// readJSONLRecords reads one object per line, skipping blank lines
+func readJSONLRecords(r io.Reader) ([]map[string]interface{}, error) {
+	var records []map[string]interface{}
+	decoder := json.NewDecoder(r)
+	for {
+		var record map[string]interface{}
+		if err := decoder.Decode(&record); err == io.EOF {
+			return records, nil
+		} else if err != nil {
+			return nil, fmt.Errorf("record %d: %w", len(records)+1, err)
+		}
+		records = append(records, record)
+	}
+}
+
+// readCSVRecords reads rows keyed by the header; empty cells are left out so
+// they stay empty when merged with files that lack the column
+func readCSVRecords(r io.Reader) ([]map[string]interface{}, error) {
+	reader := csv.NewReader(r)
+	reader.FieldsPerRecord = -1
+	rows, err := reader.ReadAll()
+	if err != nil {
+		return nil, err
+	}
+	if len(rows) == 0 {
+		return nil, nil
+	}
+
+	header := rows[0]
+	records := make([]map[string]interface{}, 0, len(rows)-1)
+	for _, row := range rows[1:] {
+		record := make(map[string]interface{}, len(header))
+		for i, value := range row {
+			if i < len(header) && value != "" {
+				record[header[i]] = value
+			}
+		}
+		records = append(records, record)
+	}
+	return records, nil
+}
+
+// MergeFiles reads every input in order and writes the combined records to
+// outputFile with the existing writers, in the format of its extension. CSV
+// output uses the union of all columns. A non-nil dedup drops repeated records.
+func MergeFiles(ctx context.Context, inputs []string, outputFile string, dedup Deduplicator) (*MergeStats, error) {
+	format, err := mergeFormat(outputFile)
+	if err != nil {
+		return nil, err
+	}
+
+	stats := &MergeStats{}
+	var merged []map[string]interface{}
+	for _, input := range inputs {
+		records, err := ReadRecords(input)
+		if err != nil {
+			return nil, err
+		}
+		stats.Read += len(records)
+
+		for _, record := range records {
+			if dedup != nil {
+				record, err = dedup.Deduplicate(ctx, record)
+				if err != nil {
+					return nil, fmt.Errorf("failed to deduplicate %s: %w", input, err)
+				}
+				if record == nil {
+					stats.Duplicates++
+					continue
+				}
+			}
+			merged = append(merged, record)
+		}
+	}
+
+	var writer Writer
+	switch format {
+	case "json":
+		writer, err = NewJSONWriter(outputFile)
+	case "jsonl":
+		writer, err = NewJSONLWriter(outputFile)
+	case "csv":
+		writer, err = NewCSVWriterWithSchema(outputFile, nil, true)
+	}
+	if err != nil {
+		return nil, err
+	}
+
+	// An empty merge still writes a valid JSON array
+	if merged == nil {
+		merged = []map[string]interface{}{}
+	}
+	if err := writer.Write(merged); err != nil {
+		writer.Close()
+		return nil, fmt.Errorf("failed to write %s: %w", outputFile, err)
+	}
+	if err := writer.Close(); err != nil {
+		return nil, fmt.Errorf("failed to finalize %s: %w", outputFile, err)
+	}
+
+	stats.Written = len(merged)
+	return stats, nil
+}
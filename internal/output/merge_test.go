@@ -0,0 +1,84 @@
+// internal/output/merge_test.go
+package output
+
+import (
+	"context"
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+
+	"github.com/valpere/DataScrapexter/internal/pipeline"
+)
+
+func TestMergeFiles(t *testing.T) {
+	dir := t.TempDir()
+	write := func(name, content string) string {
+		path := filepath.Join(dir, name)
+		if err := os.WriteFile(path, []byte(content), 0644); err != nil {
+			t.Fatalf("failed to write %s: %v", name, err)
+		}
+		return path
+	}
+
+	inputs := []string{
+		write("a.json", `[{"url": "https://a.example", "title": "A"}, {"url": "https://b.example", "title": "B"}]`),
+		write("b.jsonl", "{\"url\": \"https://b.example\", \"title\": \"B\"}\n\n{\"url\": \"https://c.example\", \"price\": \"9\"}\n"),
+		write("c.csv", "\xEF\xBB\xBFurl,title\nhttps://d.example,D\nhttps://a.example,A again\n"),
+	}
+
+	t.Run("csv output uses the union of columns", func(t *testing.T) {
+		out := filepath.Join(dir, "merged.csv")
+		stats, err := MergeFiles(context.Background(), inputs, out, nil)
+		if err != nil {
+			t.Fatalf("merge failed: %v", err)
+		}
+		if stats.Read != 6 || stats.Written != 6 {
+			t.Errorf("expected 6 records read and written, got %+v", stats)
+		}
+
+		content, _ := os.ReadFile(out)
+		lines := strings.Split(strings.TrimSpace(string(content)), "\n")
+		if lines[0] != "price,title,url" || len(lines) != 7 {
+			t.Errorf("expected one merged header and 6 rows, got:\n%s", content)
+		}
+	})
+
+	t.Run("hash dedupe drops identical records", func(t *testing.T) {
+		out := filepath.Join(dir, "merged.jsonl")
+		stats, err := MergeFiles(context.Background(), inputs, out, &pipeline.RecordDeduplicator{Method: "hash"})
+		if err != nil {
+			t.Fatalf("merge failed: %v", err)
+		}
+		if stats.Written != 5 || stats.Duplicates != 1 {
+			t.Errorf("expected 5 written and 1 duplicate, got %+v", stats)
+		}
+
+		records, err := ReadRecords(out)
+		if err != nil || len(records) != 5 {
+			t.Errorf("expected 5 JSONL records back, got %d (%v)", len(records), err)
+		}
+	})
+
+	t.Run("field dedupe keys on the given fields", func(t *testing.T) {
+		out := filepath.Join(dir, "merged.json")
+		stats, err := MergeFiles(context.Background(), inputs, out, &pipeline.RecordDeduplicator{Method: "field", Fields: []string{"url"}})
+		if err != nil {
+			t.Fatalf("merge failed: %v", err)
+		}
+		if stats.Written != 4 || stats.Duplicates != 2 {
+			t.Errorf("expected 4 written and 2 duplicates, got %+v", stats)
+		}
+
+		records, err := ReadRecords(out)
+		if err != nil || len(records) != 4 || records[3]["url"] != "https://d.example" {
+			t.Errorf("expected 4 records in input order, got %v (%v)", records, err)
+		}
+	})
+
+	t.Run("unsupported extension", func(t *testing.T) {
+		if _, err := MergeFiles(context.Background(), inputs, filepath.Join(dir, "merged.xml"), nil); err == nil {
+			t.Error("expected an error for an unsupported output extension")
+		}
+	})
+}
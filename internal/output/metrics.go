@@ -0,0 +1,160 @@
+// internal/output/metrics.go
+package output
+
+import (
+	"encoding/json"
+	"fmt"
+	"os"
+	"sort"
+	"strconv"
+	"strings"
+
+	"github.com/valpere/DataScrapexter/internal/config"
+)
+
+// LowCardinalityLimit is the maximum number of distinct values for which
+// per-value counts are reported in field metrics
+const LowCardinalityLimit = 20
+
+// MetricsFileSuffix is appended to the output file name for the metrics summary
+const MetricsFileSuffix = config.MetricsFileSuffix
+
+// DatasetMetrics summarizes data quality for a written dataset
+type DatasetMetrics struct {
+	RecordCount int                      `json:"record_count"`
+	Fields      map[string]*FieldMetrics `json:"fields"`
+}
+
+// FieldMetrics summarizes a single field across all records
+type FieldMetrics struct {
+	Filled         int            `json:"filled"`
+	FillRate       float64        `json:"fill_rate"`
+	DistinctCount  int            `json:"distinct_count"`
+	DistinctValues map[string]int `json:"distinct_values,omitempty"` // Only for low-cardinality fields
+	Numeric        *NumericStats  `json:"numeric,omitempty"`         // Only when every filled value is numeric
+}
+
+// NumericStats holds min/max/mean for numeric fields
+type NumericStats struct {
+	Min  float64 `json:"min"`
+	Max  float64 `json:"max"`
+	Mean float64 `json:"mean"`
+}
+
+// ComputeMetrics builds a per-field summary of the given records
+func ComputeMetrics(data []map[string]interface{}) *DatasetMetrics {
+	metrics := &DatasetMetrics{
+		RecordCount: len(data),
+		Fields:      make(map[string]*FieldMetrics),
+	}
+
+	// Collect field names across all records so sparse fields are reported
+	fieldSet := make(map[string]bool)
+	for _, record := range data {
+		for key := range record {
+			fieldSet[key] = true
+		}
+	}
+	fields := make([]string, 0, len(fieldSet))
+	for key := range fieldSet {
+		fields = append(fields, key)
+	}
+	sort.Strings(fields)
+
+	for _, field := range fields {
+		fm := &FieldMetrics{}
+		distinct := make(map[string]int)
+		numeric := true
+		var sum, min, max float64
+
+		for _, record := range data {
+			value, ok := record[field]
+			if !ok || isEmptyValue(value) {
+				continue
+			}
+			fm.Filled++
+			str := fmt.Sprintf("%v", value)
+			distinct[str]++
+
+			if !numeric {
+				continue
+			}
+			num, ok := toFloat(value)
+			if !ok {
+				numeric = false
+				continue
+			}
+			if fm.Filled == 1 || num < min {
+				min = num
+			}
+			if fm.Filled == 1 || num > max {
+				max = num
+			}
+			sum += num
+		}
+
+		if len(data) > 0 {
+			fm.FillRate = float64(fm.Filled) / float64(len(data))
+		}
+		fm.DistinctCount = len(distinct)
+		if fm.DistinctCount > 0 && fm.DistinctCount <= LowCardinalityLimit {
+			fm.DistinctValues = distinct
+		}
+		if numeric && fm.Filled > 0 {
+			fm.Numeric = &NumericStats{Min: min, Max: max, Mean: sum / float64(fm.Filled)}
+		}
+
+		metrics.Fields[field] = fm
+	}
+
+	return metrics
+}
+
+// WriteMetricsFile writes the metrics summary for data next to outputFile
+func WriteMetricsFile(outputFile string, data []map[string]interface{}) error {
+	file, err := os.Create(outputFile + MetricsFileSuffix)
+	if err != nil {
+		return fmt.Errorf("failed to create metrics file: %w", err)
+	}
+	defer file.Close()
+
+	encoder := json.NewEncoder(file)
+	encoder.SetIndent("", "  ")
+	if err := encoder.Encode(ComputeMetrics(data)); err != nil {
+		return fmt.Errorf("failed to write metrics: %w", err)
+	}
+	return nil
+}
+
+// isEmptyValue reports whether a value counts as missing for fill-rate purposes
+func isEmptyValue(value interface{}) bool {
+	switch v := value.(type) {
+	case nil:
+		return true
+	case string:
+		return strings.TrimSpace(v) == ""
+	case []interface{}:
+		return len(v) == 0
+	case []string:
+		return len(v) == 0
+	}
+	return false
+}
+
+// toFloat converts numeric values, including numeric strings produced by transforms
+func toFloat(value interface{}) (float64, bool) {
+	switch v := value.(type) {
+	case int:
+		return float64(v), true
+	case int64:
+		return float64(v), true
+	case float32:
+		return float64(v), true
+	case float64:
+		return v, true
+	case string:
+		f, err := strconv.ParseFloat(strings.TrimSpace(v), 64)
+		return f, err == nil
+	}
+	return 0, false
+}
@@ -0,0 +1,76 @@
+// internal/output/metrics_test.go
+package output
+
+import (
+	"encoding/json"
+	"os"
+	"path/filepath"
+	"testing"
+
+	"github.com/valpere/DataScrapexter/internal/config"
+)
+
+func TestComputeMetrics(t *testing.T) {
+	data := []map[string]interface{}{
+		{"title": "A", "price": "10.5", "stock": "in"},
+		{"title": "B", "price": 20, "stock": "out"},
+		{"title": "", "price": "30", "stock": "in"},
+		{"title": "D"},
+	}
+
+	metrics := ComputeMetrics(data)
+
+	if metrics.RecordCount != 4 {
+		t.Errorf("expected 4 records, got %d", metrics.RecordCount)
+	}
+
+	title := metrics.Fields["title"]
+	if title.Filled != 3 || title.FillRate != 0.75 {
+		t.Errorf("expected title filled 3 (0.75), got %d (%v)", title.Filled, title.FillRate)
+	}
+	if title.Numeric != nil {
+		t.Error("title should not have numeric stats")
+	}
+
+	price := metrics.Fields["price"]
+	if price.Numeric == nil {
+		t.Fatal("price should have numeric stats")
+	}
+	if price.Numeric.Min != 10.5 || price.Numeric.Max != 30 {
+		t.Errorf("unexpected price min/max: %v/%v", price.Numeric.Min, price.Numeric.Max)
+	}
+	if price.Numeric.Mean != 60.5/3 {
+		t.Errorf("expected price mean %v, got %v", 60.5/3, price.Numeric.Mean)
+	}
+
+	stock := metrics.Fields["stock"]
+	if stock.DistinctCount != 2 || stock.DistinctValues["in"] != 2 {
+		t.Errorf("unexpected stock distinct values: %v", stock.DistinctValues)
+	}
+}
+
+func TestManagerWritesMetricsFile(t *testing.T) {
+	file := filepath.Join(t.TempDir(), "out.json")
+
+	manager, err := NewManager(&config.OutputConfig{Format: "json", File: file, EnableMetrics: true})
+	if err != nil {
+		t.Fatalf("failed to create manager: %v", err)
+	}
+
+	if err := manager.Write([]map[string]interface{}{{"name": "x"}}); err != nil {
+		t.Fatalf("write failed: %v", err)
+	}
+
+	raw, err := os.ReadFile(file + MetricsFileSuffix)
+	if err != nil {
+		t.Fatalf("metrics file not written: %v", err)
+	}
+
+	var metrics DatasetMetrics
+	if err := json.Unmarshal(raw, &metrics); err != nil {
+		t.Fatalf("invalid metrics JSON: %v", err)
+	}
+	if metrics.RecordCount != 1 {
+		t.Errorf("expected record count 1, got %d", metrics.RecordCount)
+	}
+}
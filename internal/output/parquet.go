@@ -0,0 +1,574 @@
+// internal/output/parquet.go
+package output
+
+import (
+	"bufio"
+	"bytes"
+	"encoding/binary"
+	"encoding/json"
+	"fmt"
+	"math"
+	"os"
+	"reflect"
+	"slices"
+	"sort"
+	"strconv"
+	"strings"
+)
+
+// Parquet column types for schema overrides
+const (
+	ParquetString  = "string"
+	ParquetInt64   = "int64"
+	ParquetDouble  = "double"
+	ParquetBoolean = "boolean"
+)
+
+// ValidParquetTypes lists the column types a Parquet schema override may name
+func ValidParquetTypes() []string {
+	return []string{ParquetString, ParquetInt64, ParquetDouble, ParquetBoolean}
+}
+
+// ParquetWriter writes records as a Parquet file. Records are buffered, since the
+// schema is the union of every record's keys: nested maps become dotted columns
+// (price.amount), every column is nullable, and a column's type is inferred from
+// its values unless the schema override names one. Lists are stored as JSON text.
+// Columns named in the schema override are written even when no record has
+// them, so an empty result keeps its declared columns; it has no row group.
+// Close writes the row group and footer; a file that was never closed is not
+// readable.
+//
+// The file is written uncompressed with PLAIN encoding, which every Parquet
+// reader supports. CI reads its output back with pyarrow (see
+// TestParquetGoldenFilesWithPyArrow).
+type ParquetWriter struct {
+	file    *os.File
+	schema  map[string]string
+	records []map[string]interface{}
+}
+
+// NewParquetWriter creates a Parquet writer for filename. schema maps column
+// names to a type from ValidParquetTypes; other columns are inferred.
+func NewParquetWriter(filename string, schema map[string]string) (*ParquetWriter, error) {
+	for column, columnType := range schema {
+		if !slices.Contains(ValidParquetTypes(), columnType) {
+			return nil, fmt.Errorf("parquet schema: column %s has unknown type %q", column, columnType)
+		}
+	}
+	file, err := os.Create(filename)
+	if err != nil {
+		return nil, err
+	}
+	return &ParquetWriter{file: file, schema: schema}, nil
+}
+
+// Write buffers records until Close
+func (w *ParquetWriter) Write(data []map[string]interface{}) error {
+	for _, record := range data {
+		flat := make(map[string]interface{}, len(record))
+		for key, val := range record {
+			flattenMaps(flat, key, reflect.ValueOf(val))
+		}
+		w.records = append(w.records, flat)
+	}
+	return nil
+}
+
+// WriteRecord buffers a single record
+func (w *ParquetWriter) WriteRecord(record map[string]interface{}) error {
+	return w.Write([]map[string]interface{}{record})
+}
+
+// Close writes the buffered records as one row group, then the footer
+func (w *ParquetWriter) Close() error {
+	if w.file == nil {
+		return nil
+	}
+	file := w.file
+	w.file = nil
+
+	out := bufio.NewWriter(file)
+	err := w.encode(out)
+	if err == nil {
+		err = out.Flush()
+	}
+	if closeErr := file.Close(); err == nil {
+		err = closeErr
+	}
+	return err
+}
+
+// flattenMaps writes val into out under prefix, descending into maps with dotted keys
+func flattenMaps(out map[string]interface{}, prefix string, rv reflect.Value) {
+	for rv.Kind() == reflect.Interface || rv.Kind() == reflect.Pointer {
+		if rv.IsNil() {
+			out[prefix] = nil
+			return
+		}
+		rv = rv.Elem()
+	}
+	if !rv.IsValid() {
+		out[prefix] = nil
+		return
+	}
+	if rv.Kind() != reflect.Map {
+		out[prefix] = rv.Interface()
+		return
+	}
+	for _, key := range rv.MapKeys() {
+		flattenMaps(out, fmt.Sprintf("%s.%v", prefix, key.Interface()), rv.MapIndex(key))
+	}
+}
+
+// Parquet format constants (parquet.thrift)
+const (
+	parquetMagic = "PAR1"
+
+	parquetTypeBoolean   = 0
+	parquetTypeInt64     = 2
+	parquetTypeDouble    = 5
+	parquetTypeByteArray = 6
+
+	parquetOptional      = 1 // FieldRepetitionType
+	parquetConvertedUTF8 = 0 // ConvertedType
+	parquetEncodingPlain = 0
+	parquetEncodingRLE   = 3
+	parquetDataPage      = 0 // PageType
+)
+
+// parquetColumn is one column of the row group being written
+type parquetColumn struct {
+	name     string
+	kind     string // One of ValidParquetTypes
+	defined  []bool // Definition level per row: false for null
+	values   bytes.Buffer
+	nonNulls int
+	offset   int64 // File offset of the column's data page
+	size     int64 // Page header plus page bytes
+}
+
+// physicalType returns the Parquet physical type of the column
+func (c *parquetColumn) physicalType() int32 {
+	switch c.kind {
+	case ParquetBoolean:
+		return parquetTypeBoolean
+	case ParquetInt64:
+		return parquetTypeInt64
+	case ParquetDouble:
+		return parquetTypeDouble
+	default:
+		return parquetTypeByteArray
+	}
+}
+
+// encode writes the whole file: magic, one data page per column, footer
+func (w *ParquetWriter) encode(out *bufio.Writer) error {
+	columns, err := w.buildColumns()
+	if err != nil {
+		return err
+	}
+
+	offset := int64(len(parquetMagic))
+	if _, err := out.WriteString(parquetMagic); err != nil {
+		return err
+	}
+	for _, column := range columns {
+		if len(w.records) == 0 {
+			break
+		}
+		page := column.page()
+		header := parquetPageHeader(len(w.records), len(page))
+		column.offset = offset
+		column.size = int64(len(header) + len(page))
+		if _, err := out.Write(header); err != nil {
+			return err
+		}
+		if _, err := out.Write(page); err != nil {
+			return err
+		}
+		offset += column.size
+	}
+
+	footer := parquetFileMetaData(columns, len(w.records))
+	if _, err := out.Write(footer); err != nil {
+		return err
+	}
+	var length [4]byte
+	binary.LittleEndian.PutUint32(length[:], uint32(len(footer)))
+	if _, err := out.Write(length[:]); err != nil {
+		return err
+	}
+	_, err = out.WriteString(parquetMagic)
+	return err
+}
+
+// buildColumns types each column of the union schema and encodes its values
+func (w *ParquetWriter) buildColumns() ([]*parquetColumn, error) {
+	names := unionKeys(w.records)
+	for name := range w.schema {
+		if !slices.Contains(names, name) {
+			names = append(names, name)
+		}
+	}
+	sort.Strings(names)
+	columns := make([]*parquetColumn, len(names))
+	for i, name := range names {
+		column := &parquetColumn{name: name, kind: w.schema[name]}
+		if column.kind == "" {
+			column.kind = inferParquetType(w.records, name)
+		}
+
+		var bits []bool // Boolean values are bit-packed once all are known
+		for row, record := range w.records {
+			val := record[name]
+			if val == nil {
+				column.defined = append(column.defined, false)
+				continue
+			}
+			column.defined = append(column.defined, true)
+			column.nonNulls++
+			if err := column.appendValue(val, &bits); err != nil {
+				return nil, fmt.Errorf("parquet column %s, row %d: %w", name, row, err)
+			}
+		}
+		if column.kind == ParquetBoolean {
+			column.values.Write(packBits(bits))
+		}
+		columns[i] = column
+	}
+	return columns, nil
+}
+
+// appendValue PLAIN-encodes val into the column's values
+func (c *parquetColumn) appendValue(val interface{}, bits *[]bool) error {
+	var buf [8]byte
+	switch c.kind {
+	case ParquetBoolean:
+		b, err := parquetBool(val)
+		if err != nil {
+			return err
+		}
+		*bits = append(*bits, b)
+	case ParquetInt64:
+		n, err := parquetInt64(val)
+		if err != nil {
+			return err
+		}
+		binary.LittleEndian.PutUint64(buf[:], uint64(n))
+		c.values.Write(buf[:])
+	case ParquetDouble:
+		f, err := parquetDouble(val)
+		if err != nil {
+			return err
+		}
+		binary.LittleEndian.PutUint64(buf[:], math.Float64bits(f))
+		c.values.Write(buf[:])
+	default:
+		s, err := parquetString(val)
+		if err != nil {
+			return err
+		}
+		binary.LittleEndian.PutUint32(buf[:4], uint32(len(s)))
+		c.values.Write(buf[:4])
+		c.values.WriteString(s)
+	}
+	return nil
+}
+
+// page returns the data page body: definition levels, then the values
+func (c *parquetColumn) page() []byte {
+	levels := encodeDefinitionLevels(c.defined)
+	page := make([]byte, 4, 4+len(levels)+c.values.Len())
+	binary.LittleEndian.PutUint32(page, uint32(len(levels)))
+	page = append(page, levels...)
+	return append(page, c.values.Bytes()...)
+}
+
+// inferParquetType picks the narrowest type holding every value of a column:
+// boolean, int64, double, or string for anything else
+func inferParquetType(records []map[string]interface{}, name string) string {
+	allBool, allInt, allNumber, seen := true, true, true, false
+	for _, record := range records {
+		val := record[name]
+		if val == nil {
+			continue
+		}
+		seen = true
+		switch val.(type) {
+		case bool:
+			allInt, allNumber = false, false
+		case int, int8, int16, int32, int64, uint, uint8, uint16, uint32, uint64:
+			allBool = false
+		case float32, float64:
+			allBool, allInt = false, false
+		default:
+			return ParquetString
+		}
+	}
+	switch {
+	case !seen:
+		return ParquetString
+	case allBool:
+		return ParquetBoolean
+	case allInt:
+		return ParquetInt64
+	case allNumber:
+		return ParquetDouble
+	}
+	return ParquetString
+}
+
+func parquetBool(val interface{}) (bool, error) {
+	switch v := val.(type) {
+	case bool:
+		return v, nil
+	case string:
+		return strconv.ParseBool(strings.TrimSpace(v))
+	}
+	return false, fmt.Errorf("%v is not a boolean", val)
+}
+
+func parquetInt64(val interface{}) (int64, error) {
+	rv := reflect.ValueOf(val)
+	switch rv.Kind() {
+	case reflect.Int, reflect.Int8, reflect.Int16, reflect.Int32, reflect.Int64:
+		return rv.Int(), nil
+	case reflect.Uint, reflect.Uint8, reflect.Uint16, reflect.Uint32, reflect.Uint64:
+		return int64(rv.Uint()), nil
+	case reflect.Float32, reflect.Float64:
+		if f := rv.Float(); f == math.Trunc(f) && math.Abs(f) < 1<<63 {
+			return int64(f), nil
+		}
+	case reflect.String:
+		return strconv.ParseInt(strings.TrimSpace(rv.String()), 10, 64)
+	}
+	return 0, fmt.Errorf("%v is not an int64", val)
+}
+
+func parquetDouble(val interface{}) (float64, error) {
+	rv := reflect.ValueOf(val)
+	switch rv.Kind() {
+	case reflect.Int, reflect.Int8, reflect.Int16, reflect.Int32, reflect.Int64:
+		return float64(rv.Int()), nil
+	case reflect.Uint, reflect.Uint8, reflect.Uint16, reflect.Uint32, reflect.Uint64:
+		return float64(rv.Uint()), nil
+	case reflect.Float32, reflect.Float64:
+		return rv.Float(), nil
+	case reflect.String:
+		return strconv.ParseFloat(strings.TrimSpace(rv.String()), 64)
+	}
+	return 0, fmt.Errorf("%v is not a double", val)
+}
+
+// parquetString renders a value as column text, JSON-encoding lists
+func parquetString(val interface{}) (string, error) {
+	switch v := val.(type) {
+	case string:
+		return v, nil
+	case []byte:
+		return string(v), nil
+	}
+	if isNested(val) {
+		encoded, err := json.Marshal(val)
+		if err != nil {
+			return "", err
+		}
+		return string(encoded), nil
+	}
+	return fmt.Sprintf("%v", val), nil
+}
+
+// packBits packs booleans LSB first, the PLAIN encoding of BOOLEAN
+func packBits(bits []bool) []byte {
+	packed := make([]byte, (len(bits)+7)/8)
+	for i, b := range bits {
+		if b {
+			packed[i/8] |= 1 << (i % 8)
+		}
+	}
+	return packed
+}
+
+// encodeDefinitionLevels writes 0/1 levels as RLE runs of the RLE/bit-packing
+// hybrid encoding, with a bit width of 1
+func encodeDefinitionLevels(defined []bool) []byte {
+	var out []byte
+	for i := 0; i < len(defined); {
+		run := 1
+		for i+run < len(defined) && defined[i+run] == defined[i] {
+			run++
+		}
+		out = binary.AppendUvarint(out, uint64(run)<<1)
+		if defined[i] {
+			out = append(out, 1)
+		} else {
+			out = append(out, 0)
+		}
+		i += run
+	}
+	return out
+}
+
+// parquetPageHeader encodes the PageHeader of an uncompressed data page
+func parquetPageHeader(rows, pageSize int) []byte {
+	t := &thriftCompactWriter{}
+	t.i32Field(1, parquetDataPage)
+	t.i32Field(2, int32(pageSize))
+	t.i32Field(3, int32(pageSize))
+	t.structField(5, func() { // DataPageHeader
+		t.i32Field(1, int32(rows))
+		t.i32Field(2, parquetEncodingPlain)
+		t.i32Field(3, parquetEncodingRLE)
+		t.i32Field(4, parquetEncodingRLE)
+	})
+	t.stop()
+	return t.buf.Bytes()
+}
+
+// parquetFileMetaData encodes the footer describing the schema and row group
+func parquetFileMetaData(columns []*parquetColumn, rows int) []byte {
+	t := &thriftCompactWriter{}
+	t.i32Field(1, 1) // version
+	t.listField(2, thriftStruct, len(columns)+1, func(i int) {
+		if i == 0 {
+			t.binaryField(4, "schema")
+			t.i32Field(5, int32(len(columns)))
+			t.stop()
+			return
+		}
+		column := columns[i-1]
+		t.i32Field(1, column.physicalType())
+		t.i32Field(3, parquetOptional)
+		t.binaryField(4, column.name)
+		if column.kind == ParquetString {
+			t.i32Field(6, parquetConvertedUTF8)
+		}
+		t.stop()
+	})
+	t.i64Field(3, int64(rows))
+
+	var total int64
+	for _, column := range columns {
+		total += column.size
+	}
+	rowGroups := 0
+	if len(columns) > 0 && rows > 0 {
+		rowGroups = 1
+	}
+	t.listField(4, thriftStruct, rowGroups, func(int) {
+		t.listField(1, thriftStruct, len(columns), func(i int) { // ColumnChunk
+			column := columns[i]
+			t.i64Field(2, column.offset)
+			t.structField(3, func() { // ColumnMetaData
+				t.i32Field(1, column.physicalType())
+				t.listField(2, thriftI32, 2, func(j int) {
+					t.varint(int64([]int32{parquetEncodingPlain, parquetEncodingRLE}[j]))
+				})
+				t.listField(3, thriftBinary, 1, func(int) { t.binary(column.name) })
+				t.i32Field(4, 0) // UNCOMPRESSED
+				t.i64Field(5, int64(rows))
+				t.i64Field(6, column.size)
+				t.i64Field(7, column.size)
+				t.i64Field(9, column.offset)
+			})
+			t.stop()
+		})
+		t.i64Field(2, total)
+		t.i64Field(3, int64(rows))
+		t.stop()
+	})
+	t.binaryField(6, "DataScrapexter")
+	t.stop()
+	return t.buf.Bytes()
+}
+
+// Thrift compact protocol type ids
+const (
+	thriftI32    = 5
+	thriftI64    = 6
+	thriftBinary = 8
+	thriftList   = 9
+	thriftStruct = 12
+)
+
+// thriftCompactWriter encodes the Thrift compact protocol, just enough for Parquet metadata
+type thriftCompactWriter struct {
+	buf     bytes.Buffer
+	lastIDs []int16 // Field id last written, per open struct
+	lastID  int16
+}
+
+func (t *thriftCompactWriter) fieldHeader(id int16, typ byte) {
+	if delta := id - t.lastID; delta > 0 && delta <= 15 {
+		t.buf.WriteByte(byte(delta)<<4 | typ)
+	} else {
+		t.buf.WriteByte(typ)
+		t.varint(int64(id))
+	}
+	t.lastID = id
+}
+
+// varint writes a zigzag varint, the compact encoding of i16, i32 and i64
+func (t *thriftCompactWriter) varint(n int64) {
+	t.buf.Write(binary.AppendUvarint(nil, uint64((n<<1)^(n>>63))))
+}
+
+func (t *thriftCompactWriter) binary(s string) {
+	t.buf.Write(binary.AppendUvarint(nil, uint64(len(s))))
+	t.buf.WriteString(s)
+}
+
+func (t *thriftCompactWriter) i32Field(id int16, n int32) {
+	t.fieldHeader(id, thriftI32)
+	t.varint(int64(n))
+}
+
+func (t *thriftCompactWriter) i64Field(id int16, n int64) {
+	t.fieldHeader(id, thriftI64)
+	t.varint(n)
+}
+
+func (t *thriftCompactWriter) binaryField(id int16, s string) {
+	t.fieldHeader(id, thriftBinary)
+	t.binary(s)
+}
+
+// structField writes a nested struct; body writes its fields, and the stop is added
+func (t *thriftCompactWriter) structField(id int16, body func()) {
+	t.fieldHeader(id, thriftStruct)
+	t.begin()
+	body()
+	t.stop()
+}
+
+// listField writes a list; elem writes element i, and struct elements write their own stop
+func (t *thriftCompactWriter) listField(id int16, elemType byte, n int, elem func(i int)) {
+	t.fieldHeader(id, thriftList)
+	if n < 15 {
+		t.buf.WriteByte(byte(n)<<4 | elemType)
+	} else {
+		t.buf.WriteByte(0xf0 | elemType)
+		t.buf.Write(binary.AppendUvarint(nil, uint64(n)))
+	}
+	for i := 0; i < n; i++ {
+		if elemType == thriftStruct {
+			t.begin()
+		}
+		elem(i)
+	}
+}
+
+// begin opens a struct, whose field ids count from zero again
+func (t *thriftCompactWriter) begin() {
+	t.lastIDs = append(t.lastIDs, t.lastID)
+	t.lastID = 0
+}
+
+// stop ends the current struct
+func (t *thriftCompactWriter) stop() {
+	t.buf.WriteByte(0)
+	if n := len(t.lastIDs); n > 0 {
+		t.lastID = t.lastIDs[n-1]
+		t.lastIDs = t.lastIDs[:n-1]
+	}
+}
@@ -0,0 +1,350 @@
+// internal/output/parquet_test.go
+package output
+
+import (
+	"bytes"
+	"encoding/binary"
+	"encoding/json"
+	"flag"
+	"math"
+	"os"
+	"os/exec"
+	"path/filepath"
+	"reflect"
+	"strings"
+	"testing"
+
+	"github.com/valpere/DataScrapexter/internal/config"
+)
+
+// thriftReader decodes the Thrift compact protocol into maps of field id to value
+type thriftReader struct {
+	data []byte
+	pos  int
+}
+
+func (r *thriftReader) uvarint() uint64 {
+	n, size := binary.Uvarint(r.data[r.pos:])
+	r.pos += size
+	return n
+}
+
+func (r *thriftReader) value(typ byte) interface{} {
+	switch typ {
+	case 1, 2:
+		return typ == 1
+	case 5, 6:
+		n := r.uvarint()
+		return int64(n>>1) ^ -int64(n&1)
+	case 8:
+		n := int(r.uvarint())
+		s := string(r.data[r.pos : r.pos+n])
+		r.pos += n
+		return s
+	case 9:
+		header := r.data[r.pos]
+		r.pos++
+		size, elemType := int(header>>4), header&0x0f
+		if size == 15 {
+			size = int(r.uvarint())
+		}
+		list := make([]interface{}, size)
+		for i := range list {
+			list[i] = r.value(elemType)
+		}
+		return list
+	case 12:
+		return r.structValue()
+	}
+	panic("unexpected thrift type")
+}
+
+func (r *thriftReader) structValue() map[int16]interface{} {
+	fields := make(map[int16]interface{})
+	var last int16
+	for {
+		header := r.data[r.pos]
+		r.pos++
+		if header == 0 {
+			return fields
+		}
+		id := last + int16(header>>4)
+		if header>>4 == 0 {
+			n := r.uvarint()
+			id = int16(int64(n>>1) ^ -int64(n&1))
+		}
+		fields[id] = r.value(header & 0x0f)
+		last = id
+	}
+}
+
+// readParquetFooter checks the magic bytes and returns the decoded FileMetaData
+func readParquetFooter(t *testing.T, data []byte) map[int16]interface{} {
+	t.Helper()
+	if !bytes.HasPrefix(data, []byte("PAR1")) || !bytes.HasSuffix(data, []byte("PAR1")) {
+		t.Fatal("file does not start and end with PAR1")
+	}
+	length := int(binary.LittleEndian.Uint32(data[len(data)-8:]))
+	footer := data[len(data)-8-length : len(data)-8]
+	return (&thriftReader{data: footer}).structValue()
+}
+
+func TestParquetWriter(t *testing.T) {
+	filename := filepath.Join(t.TempDir(), "products.parquet")
+	writer, err := NewParquetWriter(filename, map[string]string{"sku": ParquetString})
+	if err != nil {
+		t.Fatalf("NewParquetWriter() error = %v", err)
+	}
+	records := []map[string]interface{}{
+		{"title": "Lamp", "price": map[string]interface{}{"amount": 19.5, "currency": "EUR"}, "stock": 3, "sku": 1001, "tags": []string{"home"}},
+		{"title": "Desk", "price": map[string]interface{}{"amount": 120.0}, "in_stock": true, "sku": 1002},
+		{"title": "Chair", "stock": int64(0), "in_stock": false},
+	}
+	if err := writer.Write(records); err != nil {
+		t.Fatalf("Write() error = %v", err)
+	}
+	if err := writer.Close(); err != nil {
+		t.Fatalf("Close() error = %v", err)
+	}
+
+	data, err := os.ReadFile(filename)
+	if err != nil {
+		t.Fatal(err)
+	}
+	meta := readParquetFooter(t, data)
+	if rows := meta[3].(int64); rows != 3 {
+		t.Errorf("num_rows = %d, want 3", rows)
+	}
+
+	wantTypes := map[string]int64{
+		"in_stock":       parquetTypeBoolean,
+		"price.amount":   parquetTypeDouble,
+		"price.currency": parquetTypeByteArray,
+		"sku":            parquetTypeByteArray, // overridden from int64
+		"stock":          parquetTypeInt64,
+		"tags":           parquetTypeByteArray,
+		"title":          parquetTypeByteArray,
+	}
+	schema := meta[2].([]interface{})
+	if root := schema[0].(map[int16]interface{}); root[5].(int64) != int64(len(wantTypes)) {
+		t.Errorf("root num_children = %v, want %d", root[5], len(wantTypes))
+	}
+	var names []string
+	for _, element := range schema[1:] {
+		element := element.(map[int16]interface{})
+		name := element[4].(string)
+		names = append(names, name)
+		if element[1].(int64) != wantTypes[name] {
+			t.Errorf("column %s type = %v, want %d", name, element[1], wantTypes[name])
+		}
+		if element[3].(int64) != parquetOptional {
+			t.Errorf("column %s is not optional", name)
+		}
+	}
+	if got := strings.Join(names, ","); got != "in_stock,price.amount,price.currency,sku,stock,tags,title" {
+		t.Errorf("columns = %s", got)
+	}
+
+	// Read the price.amount page back: rows 0 and 1 are set, row 2 is null
+	rowGroup := meta[4].([]interface{})[0].(map[int16]interface{})
+	chunk := rowGroup[1].([]interface{})[1].(map[int16]interface{})
+	offset := chunk[3].(map[int16]interface{})[9].(int64)
+	page := &thriftReader{data: data[offset:]}
+	header := page.structValue()
+	if header[5].(map[int16]interface{})[1].(int64) != 3 {
+		t.Errorf("page num_values = %v, want 3", header[5])
+	}
+	body := data[int(offset)+page.pos:]
+	levelsLen := int(binary.LittleEndian.Uint32(body))
+	if levels := body[4 : 4+levelsLen]; !bytes.Equal(levels, []byte{4, 1, 2, 0}) {
+		t.Errorf("definition levels = %v, want runs of two set and one null", levels)
+	}
+	values := body[4+levelsLen:]
+	for i, want := range []float64{19.5, 120} {
+		if got := math.Float64frombits(binary.LittleEndian.Uint64(values[i*8:])); got != want {
+			t.Errorf("price.amount[%d] = %v, want %v", i, got, want)
+		}
+	}
+}
+
+var updateParquet = flag.Bool("update-parquet", false, "rewrite the Parquet golden files in testdata")
+
+// parquetFixtures are written to testdata/<name>.parquet. The golden files are
+// read back by pyarrow in TestParquetGoldenFilesWithPyArrow, so a change to
+// the encoding is checked by an independent reader before they are updated.
+var parquetFixtures = []struct {
+	name    string
+	schema  map[string]string
+	records []map[string]interface{}
+}{
+	{
+		name:   "products",
+		schema: map[string]string{"sku": ParquetString},
+		records: []map[string]interface{}{
+			{"title": "Lamp", "price": map[string]interface{}{"amount": 19.5, "currency": "EUR"}, "stock": 3, "sku": 1001, "tags": []string{"home"}},
+			{"title": "Desk", "price": map[string]interface{}{"amount": 120.0}, "in_stock": true, "sku": 1002},
+			{"title": "Chair", "stock": int64(0), "in_stock": false},
+		},
+	},
+	{
+		name:   "empty",
+		schema: map[string]string{"title": ParquetString, "price": ParquetDouble},
+	},
+}
+
+func writeParquetFixture(t *testing.T, filename string, schema map[string]string, records []map[string]interface{}) []byte {
+	t.Helper()
+	writer, err := NewParquetWriter(filename, schema)
+	if err != nil {
+		t.Fatalf("NewParquetWriter() error = %v", err)
+	}
+	if err := writer.Write(records); err != nil {
+		t.Fatalf("Write() error = %v", err)
+	}
+	if err := writer.Close(); err != nil {
+		t.Fatalf("Close() error = %v", err)
+	}
+	data, err := os.ReadFile(filename)
+	if err != nil {
+		t.Fatal(err)
+	}
+	return data
+}
+
+func TestParquetWriterGoldenFiles(t *testing.T) {
+	for _, fixture := range parquetFixtures {
+		t.Run(fixture.name, func(t *testing.T) {
+			data := writeParquetFixture(t, filepath.Join(t.TempDir(), "out.parquet"), fixture.schema, fixture.records)
+			golden := filepath.Join("testdata", fixture.name+".parquet")
+			if *updateParquet {
+				if err := os.WriteFile(golden, data, 0644); err != nil {
+					t.Fatal(err)
+				}
+			}
+			want, err := os.ReadFile(golden)
+			if err != nil {
+				t.Fatalf("missing golden file, run with -update-parquet: %v", err)
+			}
+			if !bytes.Equal(data, want) {
+				t.Errorf("output differs from %s; check it with testdata/read_parquet.py and run with -update-parquet", golden)
+			}
+		})
+	}
+}
+
+func TestParquetWriterEmpty(t *testing.T) {
+	data := writeParquetFixture(t, filepath.Join(t.TempDir(), "empty.parquet"), map[string]string{"title": ParquetString, "price": ParquetDouble}, nil)
+	meta := readParquetFooter(t, data)
+	if rows := meta[3].(int64); rows != 0 {
+		t.Errorf("num_rows = %d, want 0", rows)
+	}
+	if groups := meta[4].([]interface{}); len(groups) != 0 {
+		t.Errorf("row groups = %d, want none", len(groups))
+	}
+	var names []string
+	for _, element := range meta[2].([]interface{})[1:] {
+		names = append(names, element.(map[int16]interface{})[4].(string))
+	}
+	if got := strings.Join(names, ","); got != "price,title" {
+		t.Errorf("columns = %s, want the schema override's", got)
+	}
+
+	// Without a schema there are no columns either
+	data = writeParquetFixture(t, filepath.Join(t.TempDir(), "none.parquet"), nil, nil)
+	if schema := readParquetFooter(t, data)[2].([]interface{}); len(schema) != 1 {
+		t.Errorf("schema elements = %d, want only the root", len(schema))
+	}
+}
+
+var requirePyArrow = flag.Bool("require-pyarrow", false, "fail instead of skipping the Parquet checks when pyarrow is missing")
+
+// TestParquetGoldenFilesWithPyArrow reads the golden files with pyarrow. It is
+// skipped where python3 with pyarrow is not installed, unless -require-pyarrow
+// is set, as it is in CI, so the writer is always checked by a real reader there.
+func TestParquetGoldenFilesWithPyArrow(t *testing.T) {
+	if err := exec.Command("python3", "-c", "import pyarrow.parquet").Run(); err != nil {
+		if *requirePyArrow {
+			t.Fatalf("python3 with pyarrow is required: %v", err)
+		}
+		t.Skip("python3 with pyarrow is not available")
+	}
+
+	tests := map[string]struct {
+		columns map[string]string
+		rows    []map[string]interface{}
+	}{
+		"products": {
+			columns: map[string]string{"in_stock": "bool", "price.amount": "double", "price.currency": "string",
+				"sku": "string", "stock": "int64", "tags": "string", "title": "string"},
+			rows: []map[string]interface{}{
+				{"in_stock": nil, "price.amount": 19.5, "price.currency": "EUR", "sku": "1001", "stock": 3.0, "tags": `["home"]`, "title": "Lamp"},
+				{"in_stock": true, "price.amount": 120.0, "price.currency": nil, "sku": "1002", "stock": nil, "tags": nil, "title": "Desk"},
+				{"in_stock": false, "price.amount": nil, "price.currency": nil, "sku": nil, "stock": 0.0, "tags": nil, "title": "Chair"},
+			},
+		},
+		"empty": {
+			columns: map[string]string{"price": "double", "title": "string"},
+			rows:    []map[string]interface{}{},
+		},
+	}
+	for name, want := range tests {
+		t.Run(name, func(t *testing.T) {
+			out, err := exec.Command("python3", filepath.Join("testdata", "read_parquet.py"), filepath.Join("testdata", name+".parquet")).Output()
+			if err != nil {
+				t.Fatalf("pyarrow failed to read %s.parquet: %v", name, err)
+			}
+			var got struct {
+				Columns map[string]string        `json:"columns"`
+				Rows    []map[string]interface{} `json:"rows"`
+			}
+			if err := json.Unmarshal(out, &got); err != nil {
+				t.Fatalf("unexpected reader output %s: %v", out, err)
+			}
+			if !reflect.DeepEqual(got.Columns, want.columns) {
+				t.Errorf("columns = %v, want %v", got.Columns, want.columns)
+			}
+			if !reflect.DeepEqual(got.Rows, want.rows) {
+				t.Errorf("rows = %v, want %v", got.Rows, want.rows)
+			}
+		})
+	}
+}
+
+func TestParquetWriterSchemaErrors(t *testing.T) {
+	dir := t.TempDir()
+	if _, err := NewParquetWriter(filepath.Join(dir, "a.parquet"), map[string]string{"price": "decimal"}); err == nil {
+		t.Error("expected an error for an unknown column type")
+	}
+
+	writer, err := NewParquetWriter(filepath.Join(dir, "b.parquet"), map[string]string{"price": ParquetDouble})
+	if err != nil {
+		t.Fatal(err)
+	}
+	writer.Write([]map[string]interface{}{{"price": "call for price"}})
+	if err := writer.Close(); err == nil || !strings.Contains(err.Error(), "column price") {
+		t.Errorf("Close() error = %v, want a conversion error naming the column", err)
+	}
+}
+
+func TestManagerParquetWriter(t *testing.T) {
+	filename := filepath.Join(t.TempDir(), "out.parquet")
+	manager, err := NewManager(&config.OutputConfig{Format: "parquet", File: filename})
+	if err != nil {
+		t.Fatal(err)
+	}
+	writer, err := manager.GetWriter()
+	if err != nil {
+		t.Fatalf("GetWriter() error = %v", err)
+	}
+	if _, ok := writer.(*ParquetWriter); !ok {
+		t.Fatalf("GetWriter() = %T, want *ParquetWriter", writer)
+	}
+	writer.Write([]map[string]interface{}{{"title": "Lamp"}})
+	if err := writer.Close(); err != nil {
+		t.Fatal(err)
+	}
+	data, _ := os.ReadFile(filename)
+	if rows := readParquetFooter(t, data)[3].(int64); rows != 1 {
+		t.Errorf("num_rows = %d, want 1", rows)
+	}
+}
@@ -0,0 +1,74 @@
+// internal/output/partition.go
+package output
+
+import (
+	"fmt"
+
+	"github.com/valpere/DataScrapexter/internal/config"
+)
+
+// Partition is the share of records routed to one output
+type Partition struct {
+	Value   string
+	Records []map[string]interface{}
+}
+
+// PartitionRecords groups data by the value of field, in order of first appearance.
+// Records where the field is missing, nil or empty go to defaultValue.
+func PartitionRecords(data []map[string]interface{}, field, defaultValue string) []Partition {
+	if defaultValue == "" {
+		defaultValue = config.DefaultPartition
+	}
+
+	var partitions []Partition
+	index := make(map[string]int)
+	for _, record := range data {
+		value := defaultValue
+		if raw, ok := record[field]; ok && raw != nil {
+			if s := fmt.Sprint(raw); s != "" {
+				value = s
+			}
+		}
+
+		i, ok := index[value]
+		if !ok {
+			i = len(partitions)
+			index[value] = i
+			partitions = append(partitions, Partition{Value: value})
+		}
+		partitions[i].Records = append(partitions[i].Records, record)
+	}
+	return partitions
+}
+
+// writePartitions writes each partition with its own writer, at the path
+// File gives once the partition value replaces {partition}. Values that make
+// the same path, like "a/b" and "a_b", share one file rather than overwrite it.
+func (m *Manager) writePartitions(data []map[string]interface{}) error {
+	var files []Partition
+	index := make(map[string]int)
+	for _, partition := range PartitionRecords(data, m.config.PartitionBy, m.config.PartitionDefault) {
+		file := config.ExpandPartitionPath(m.config.File, partition.Value)
+		if i, ok := index[file]; ok {
+			files[i].Records = append(files[i].Records, partition.Records...)
+			continue
+		}
+		index[file] = len(files)
+		files = append(files, partition)
+	}
+
+	for _, partition := range files {
+		cfg := *m.config
+		cfg.PartitionBy = ""
+		cfg.File = config.ExpandPartitionPath(m.config.File, partition.Value)
+		if err := m.policy.EnsureOutputDir(cfg.File); err != nil {
+			return fmt.Errorf("failed to create output directory for partition %s: %w", partition.Value, err)
+		}
+
+		child := &Manager{config: &cfg, formatOptions: m.formatOptions, policy: m.policy}
+		if err := child.Write(partition.Records); err != nil {
+			return fmt.Errorf("partition %s: %w", partition.Value, err)
+		}
+	}
+	return nil
+}
@@ -0,0 +1,103 @@
+// internal/output/partition_test.go
+package output
+
+import (
+	"os"
+	"path/filepath"
+	"testing"
+
+	"github.com/valpere/DataScrapexter/internal/config"
+)
+
+func TestPartitionRecords(t *testing.T) {
+	data := []map[string]interface{}{
+		{"title": "TV", "category": "electronics"},
+		{"title": "Novel", "category": "books"},
+		{"title": "Radio", "category": "electronics"},
+		{"title": "Mystery"},
+		{"title": "Blank", "category": ""},
+	}
+
+	partitions := PartitionRecords(data, "category", "")
+	if len(partitions) != 3 {
+		t.Fatalf("expected 3 partitions, got %d", len(partitions))
+	}
+	want := []struct {
+		value string
+		count int
+	}{{"electronics", 2}, {"books", 1}, {config.DefaultPartition, 2}}
+	for i, w := range want {
+		if partitions[i].Value != w.value || len(partitions[i].Records) != w.count {
+			t.Errorf("partition %d: got %s with %d records, want %s with %d",
+				i, partitions[i].Value, len(partitions[i].Records), w.value, w.count)
+		}
+	}
+}
+
+func TestManagerWritePartitions(t *testing.T) {
+	dir := t.TempDir()
+	manager, err := NewManager(&config.OutputConfig{
+		Format:           "json",
+		File:             filepath.Join(dir, "{partition}", "items.json"),
+		PartitionBy:      "category",
+		PartitionDefault: "other",
+	})
+	if err != nil {
+		t.Fatalf("NewManager() error = %v", err)
+	}
+
+	data := []map[string]interface{}{
+		{"title": "TV", "category": "electronics"},
+		{"title": "Novel", "category": "books"},
+		{"title": "Radio", "category": "electronics"},
+		{"title": "Mystery"},
+		{"title": "Escape", "category": "../etc"},
+		{"title": "Lookalike", "category": ".._etc"},
+	}
+	if err := manager.Write(data); err != nil {
+		t.Fatalf("Write() error = %v", err)
+	}
+
+	for value, count := range map[string]int{"electronics": 2, "books": 1, "other": 1, ".._etc": 2} {
+		records, err := ReadRecords(filepath.Join(dir, value, "items.json"))
+		if err != nil {
+			t.Errorf("partition %s: %v", value, err)
+			continue
+		}
+		if len(records) != count {
+			t.Errorf("partition %s: expected %d records, got %d", value, count, len(records))
+		}
+	}
+	if _, err := os.Stat(filepath.Join(dir, "{partition}")); !os.IsNotExist(err) {
+		t.Error("expected no literal {partition} directory")
+	}
+}
+
+func TestManagerWritePartitionsPolicy(t *testing.T) {
+	base := t.TempDir()
+	outside := t.TempDir()
+	// A partition directory that is a symlink must not lead the write out of the base directory
+	if err := os.Symlink(outside, filepath.Join(base, "linked")); err != nil {
+		t.Fatalf("Failed to create symlink: %v", err)
+	}
+
+	policy := &config.OutputPolicy{BaseDir: base}
+	manager, err := NewManagerWithPolicy(&config.OutputConfig{
+		Format:      "json",
+		File:        filepath.Join(base, "{partition}", "items.json"),
+		PartitionBy: "category",
+	}, policy)
+	if err != nil {
+		t.Fatalf("NewManagerWithPolicy() error = %v", err)
+	}
+
+	if err := manager.Write([]map[string]interface{}{{"title": "TV", "category": "books"}}); err != nil {
+		t.Fatalf("Write() error = %v", err)
+	}
+	if err := manager.Write([]map[string]interface{}{{"title": "TV", "category": "linked"}}); err == nil {
+		t.Error("Expected a partition resolving outside the base directory to fail")
+	}
+	if _, err := os.Stat(filepath.Join(outside, "items.json")); err == nil {
+		t.Error("Partition was written outside the base directory")
+	}
+}
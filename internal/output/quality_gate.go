@@ -0,0 +1,65 @@
+// internal/output/quality_gate.go
+package output
+
+import (
+	"fmt"
+	"sort"
+	"strings"
+
+	"github.com/valpere/DataScrapexter/internal/config"
+)
+
+// QualityReport is the outcome of checking a dataset against a quality gate
+type QualityReport struct {
+	Passed   bool
+	Failures []string // One line per failed check, in a stable order
+	Metrics  *DatasetMetrics
+}
+
+// CheckQualityGate evaluates data against gate. A nil gate always passes.
+// Fields named in min_fill that never appear in data count as 0% filled.
+func CheckQualityGate(gate *config.QualityGateConfig, data []map[string]interface{}) *QualityReport {
+	report := &QualityReport{Passed: true, Metrics: ComputeMetrics(data)}
+	if gate == nil {
+		return report
+	}
+
+	if report.Metrics.RecordCount < gate.MinRecords {
+		report.Failures = append(report.Failures,
+			fmt.Sprintf("records: got %d, want at least %d", report.Metrics.RecordCount, gate.MinRecords))
+	}
+
+	fields := make([]string, 0, len(gate.MinFill))
+	for field := range gate.MinFill {
+		fields = append(fields, field)
+	}
+	sort.Strings(fields)
+
+	for _, field := range fields {
+		var fill float64
+		if fm, ok := report.Metrics.Fields[field]; ok {
+			fill = fm.FillRate
+		}
+		if fill < gate.MinFill[field] {
+			report.Failures = append(report.Failures,
+				fmt.Sprintf("fill rate of %s: got %.1f%%, want at least %.1f%%", field, fill*100, gate.MinFill[field]*100))
+		}
+	}
+
+	report.Passed = len(report.Failures) == 0
+	return report
+}
+
+// String renders the report for the terminal
+func (r *QualityReport) String() string {
+	var b strings.Builder
+	if r.Passed {
+		fmt.Fprintf(&b, "Quality gate passed (%d records)\n", r.Metrics.RecordCount)
+		return b.String()
+	}
+	fmt.Fprintf(&b, "Quality gate failed (%d records):\n", r.Metrics.RecordCount)
+	for _, failure := range r.Failures {
+		fmt.Fprintf(&b, "  - %s\n", failure)
+	}
+	return b.String()
+}
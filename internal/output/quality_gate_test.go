@@ -0,0 +1,44 @@
+// internal/output/quality_gate_test.go
+package output
+
+import (
+	"strings"
+	"testing"
+
+	"github.com/valpere/DataScrapexter/internal/config"
+)
+
+func TestCheckQualityGate(t *testing.T) {
+	data := []map[string]interface{}{
+		{"title": "A", "price": "10"},
+		{"title": "B", "price": ""},
+		{"title": "C"},
+		{"title": "D", "price": "12"},
+	}
+
+	if report := CheckQualityGate(nil, data); !report.Passed {
+		t.Errorf("expected nil gate to pass, got %v", report.Failures)
+	}
+
+	gate := &config.QualityGateConfig{MinRecords: 4, MinFill: map[string]float64{"title": 1, "price": 0.5}}
+	if report := CheckQualityGate(gate, data); !report.Passed {
+		t.Errorf("expected gate at the boundary to pass, got %v", report.Failures)
+	}
+
+	gate = &config.QualityGateConfig{MinRecords: 5, MinFill: map[string]float64{"price": 0.9, "sku": 0.1}}
+	report := CheckQualityGate(gate, data)
+	if report.Passed {
+		t.Fatal("expected gate to fail")
+	}
+	want := []string{
+		"records: got 4, want at least 5",
+		"fill rate of price: got 50.0%, want at least 90.0%",
+		"fill rate of sku: got 0.0%, want at least 10.0%",
+	}
+	if strings.Join(report.Failures, "|") != strings.Join(want, "|") {
+		t.Errorf("unexpected failures:\n got %q\nwant %q", report.Failures, want)
+	}
+	if !strings.Contains(report.String(), "Quality gate failed (4 records)") {
+		t.Errorf("unexpected report: %s", report.String())
+	}
+}
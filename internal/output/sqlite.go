@@ -5,7 +5,6 @@ import (
 	"database/sql"
 	"encoding/json"
 	"fmt"
-	"log"
 	"os"
 	"path/filepath"
 	"strconv"
@@ -13,8 +12,11 @@ import (
 	"time"
 
 	_ "github.com/mattn/go-sqlite3" // SQLite driver
+	"github.com/valpere/DataScrapexter/internal/utils"
 )
 
+var sqliteLogger = utils.NewComponentLogger("sqlite-output")
+
 // SQLite connection parameter constants
 const (
 	// Connection string parameters
@@ -463,7 +465,7 @@ func (w *SQLiteWriter) Close() error {
 		// Only optimize database if explicitly configured to do so
 		if w.config.OptimizeOnClose {
 			if err := w.performDatabaseOptimization(); err != nil {
-				log.Printf("Warning: Database optimization failed: %v", err)
+				sqliteLogger.Warnf("Database optimization failed: %v", err)
 			}
 		}
 
@@ -520,7 +522,7 @@ func (w *SQLiteWriter) performDatabaseOptimization() error {
 	// This is non-blocking and more suitable for production environments
 	if _, err := w.db.Exec("PRAGMA incremental_vacuum"); err != nil {
 		// If incremental_vacuum fails, log but don't fail the close operation
-		log.Printf("Warning: PRAGMA incremental_vacuum failed: %v", err)
+		sqliteLogger.Warnf("PRAGMA incremental_vacuum failed: %v", err)
 
 		// Note: A full VACUUM fallback is not implemented here because:
 		// 1. SQLite doesn't support VACUUM timeouts, making it potentially blocking
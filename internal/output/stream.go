@@ -0,0 +1,61 @@
+// internal/output/stream.go
+package output
+
+import (
+	"fmt"
+	"io"
+
+	"gopkg.in/yaml.v3"
+)
+
+// StreamWriter returns a writer for the configured format over out, for results
+// piped to another program instead of written to the output file. JSON is written
+// as JSON Lines so each record can be read as it arrives; CSV gets its header row
+// and the configured columns and encodings; YAML is one sequence with an item per
+// record. With several outputs the first one's format is used. Close does not close out.
+func (m *Manager) StreamWriter(out io.Writer) (Writer, error) {
+	if len(m.sinks) > 0 {
+		return m.sinks[0].StreamWriter(out)
+	}
+
+	switch m.config.Format {
+	case FormatJSON, FormatJSONL, FormatJSONLines, "":
+		writer := NewJSONLStreamWriter(out)
+		if err := writer.SetTextEncoding(m.textEncoding()); err != nil {
+			return nil, err
+		}
+		return writer, nil
+	case FormatCSV:
+		writer := NewCSVStreamWriter(out, m.config.Columns, m.config.CSVUnion)
+		if err := m.configureCSV(writer); err != nil {
+			return nil, err
+		}
+		return writer, nil
+	case FormatYAML:
+		return &yamlStreamWriter{out: out}, nil
+	default:
+		return nil, fmt.Errorf("output format %s cannot be streamed", m.config.Format)
+	}
+}
+
+// yamlStreamWriter writes each record as one item of a top-level YAML sequence
+type yamlStreamWriter struct {
+	out io.Writer
+}
+
+func (w *yamlStreamWriter) Write(data []map[string]interface{}) error {
+	for _, record := range data {
+		item, err := yaml.Marshal([]map[string]interface{}{record})
+		if err != nil {
+			return fmt.Errorf("failed to encode record as YAML: %w", err)
+		}
+		if _, err := w.out.Write(item); err != nil {
+			return err
+		}
+	}
+	return nil
+}
+
+func (w *yamlStreamWriter) Close() error {
+	return nil
+}
@@ -0,0 +1,15 @@
+"""Print a Parquet file as JSON using pyarrow, for checking ParquetWriter output
+against an independent reader: {"columns": {name: arrow type}, "rows": [...]}.
+
+Usage: python3 read_parquet.py FILE
+"""
+import json
+import sys
+
+import pyarrow.parquet as pq
+
+table = pq.read_table(sys.argv[1])
+print(json.dumps({
+    "columns": {field.name: str(field.type) for field in table.schema},
+    "rows": table.to_pylist(),
+}))
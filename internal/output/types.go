@@ -13,11 +13,14 @@ type OutputFormat string
 
 const (
 	FormatJSON       OutputFormat = "json"
+	FormatJSONL      OutputFormat = "jsonl"
+	FormatJSONLines  OutputFormat = "jsonlines" // Alias of FormatJSONL
 	FormatCSV        OutputFormat = "csv"
 	FormatXML        OutputFormat = "xml"
 	FormatYAML       OutputFormat = "yaml"
 	FormatTSV        OutputFormat = "tsv"
 	FormatExcel      OutputFormat = "excel"
+	FormatXLSX       OutputFormat = "xlsx" // Alias of FormatExcel
 	FormatParquet    OutputFormat = "parquet"
 	FormatPostgreSQL OutputFormat = "postgresql"
 	FormatSQLite     OutputFormat = "sqlite"
@@ -46,7 +49,7 @@ const (
 
 // ValidOutputFormats returns all valid output format values
 func ValidOutputFormats() []OutputFormat {
-	return []OutputFormat{FormatJSON, FormatCSV, FormatXML, FormatYAML, FormatTSV, FormatExcel, FormatParquet, FormatPostgreSQL, FormatSQLite}
+	return []OutputFormat{FormatJSON, FormatJSONL, FormatJSONLines, FormatCSV, FormatXML, FormatYAML, FormatTSV, FormatExcel, FormatXLSX, FormatParquet, FormatPostgreSQL, FormatSQLite}
 }
 
 // ValidConflictStrategies returns all valid conflict strategy values
@@ -363,6 +366,8 @@ func (of OutputFormat) GetFileExtension() string {
 	switch of {
 	case FormatJSON:
 		return ".json"
+	case FormatJSONL, FormatJSONLines:
+		return ".jsonl"
 	case FormatCSV:
 		return ".csv"
 	case FormatXML:
@@ -371,7 +376,7 @@ func (of OutputFormat) GetFileExtension() string {
 		return ".yaml"
 	case FormatTSV:
 		return ".tsv"
-	case FormatExcel:
+	case FormatExcel, FormatXLSX:
 		return ".xlsx"
 	case FormatParquet:
 		return ".parquet"
@@ -385,6 +390,8 @@ func (of OutputFormat) GetMimeType() string {
 	switch of {
 	case FormatJSON:
 		return "application/json"
+	case FormatJSONL, FormatJSONLines:
+		return "application/x-ndjson"
 	case FormatCSV:
 		return "text/csv"
 	case FormatXML:
@@ -393,7 +400,7 @@ func (of OutputFormat) GetMimeType() string {
 		return "application/yaml"
 	case FormatTSV:
 		return "text/tab-separated-values"
-	case FormatExcel:
+	case FormatExcel, FormatXLSX:
 		return "application/vnd.openxmlformats-officedocument.spreadsheetml.sheet"
 	case FormatParquet:
 		return "application/octet-stream"
@@ -409,6 +416,27 @@ type Config struct {
 	Options  map[string]string `yaml:"options,omitempty" json:"options,omitempty"`
 	Append   bool              `yaml:"append,omitempty" json:"append,omitempty"`
 	Template string            `yaml:"template,omitempty" json:"template,omitempty"`
+
+	// EnableMetrics writes a <file>.metrics.json data-quality summary alongside the output
+	EnableMetrics bool `yaml:"enable_metrics,omitempty" json:"enable_metrics,omitempty"`
+
+	// Columns fixes the CSV header and column order so records can be streamed
+	Columns []string `yaml:"columns,omitempty" json:"columns,omitempty"`
+	// CSVUnion buffers all records and uses the sorted union of their keys as the CSV header
+	CSVUnion bool `yaml:"csv_union,omitempty" json:"csv_union,omitempty"`
+	// NestedEncoding controls map and slice values in CSV cells: json (default), flatten or drop
+	NestedEncoding string `yaml:"nested_encoding,omitempty" json:"nested_encoding,omitempty"`
+	// BOM and LineEnding shape text outputs (CSV, JSON) for Windows tools such as Excel
+	BOM        bool   `yaml:"bom,omitempty" json:"bom,omitempty"`
+	LineEnding string `yaml:"line_ending,omitempty" json:"line_ending,omitempty"`
+	// ParquetSchema overrides inferred Parquet column types by column name
+	ParquetSchema map[string]string `yaml:"parquet_schema,omitempty" json:"parquet_schema,omitempty"`
+	// SheetName names the XLSX worksheet (default Sheet1)
+	SheetName string `yaml:"sheet_name,omitempty" json:"sheet_name,omitempty"`
+	// PartitionBy splits records into one output per value of this field; File holds
+	// a {partition} placeholder and records without the field go to PartitionDefault
+	PartitionBy      string `yaml:"partition_by,omitempty" json:"partition_by,omitempty"`
+	PartitionDefault string `yaml:"partition_default,omitempty" json:"partition_default,omitempty"`
 }
 
 // Writer defines the interface for output writers without conflicting
@@ -462,10 +490,13 @@ type JSONOptions struct {
 	EscapeHTML bool   `yaml:"escape_html,omitempty" json:"escape_html,omitempty"`
 }
 
-// CSVOptions defines CSV-specific options
+// CSVOptions defines CSV-specific options. Columns orders the header (other
+// columns follow alphabetically); QuoteAll quotes every field, not only those
+// that need it.
 type CSVOptions struct {
 	Delimiter string   `yaml:"delimiter,omitempty" json:"delimiter,omitempty"`
 	Quote     string   `yaml:"quote,omitempty" json:"quote,omitempty"`
+	QuoteAll  bool     `yaml:"quote_all,omitempty" json:"quote_all,omitempty"`
 	Header    bool     `yaml:"header" json:"header"`
 	Columns   []string `yaml:"columns,omitempty" json:"columns,omitempty"`
 	SkipEmpty bool     `yaml:"skip_empty,omitempty" json:"skip_empty,omitempty"`
@@ -503,6 +534,7 @@ var SupportedFormats = []string{
 	"txt",
 	"html",
 	"jsonl",      // JSON Lines
+	"jsonlines",  // JSON Lines
 	"postgresql", // PostgreSQL database
 	"sqlite",     // SQLite database
 }
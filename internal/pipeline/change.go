@@ -0,0 +1,179 @@
+// internal/pipeline/change.go
+package pipeline
+
+import (
+	"encoding/json"
+	"fmt"
+	"os"
+	"path/filepath"
+	"strings"
+	"sync"
+	"time"
+)
+
+// ChangeField is the record key ChangeDetector marks with ChangeNew,
+// ChangeModified or ChangeUnchanged
+const ChangeField = "_change"
+
+// Change markers set under ChangeField
+const (
+	ChangeNew       = "new"
+	ChangeModified  = "modified"
+	ChangeUnchanged = "unchanged"
+)
+
+// ChangeDetector compares each record with the one that had the same key in
+// the previous run, using content hashes kept in a state file. Keys starting
+// with an underscore (metadata such as _meta) are left out of the hash. A
+// record without the key field is keyed by its hash, so it is either new or
+// unchanged. Save writes the hashes for the next run to compare with.
+type ChangeDetector struct {
+	KeyField      string
+	SkipUnchanged bool // Mark returns nil for unchanged records
+
+	path     string
+	mu       sync.Mutex
+	previous map[string]string // Key to content hash, from the state file
+	current  map[string]string
+	counts   map[string]int
+}
+
+// changeState is the state file written by Save
+type changeState struct {
+	KeyField string            `json:"key_field"`
+	Hashes   map[string]string `json:"hashes"`
+	SavedAt  time.Time         `json:"saved_at"`
+}
+
+// OpenChangeDetector loads the hashes saved at path, if any. A state file
+// saved with a different key field is ignored, so every record reads as new.
+func OpenChangeDetector(path, keyField string, skipUnchanged bool) (*ChangeDetector, error) {
+	cd := &ChangeDetector{
+		KeyField:      keyField,
+		SkipUnchanged: skipUnchanged,
+		path:          path,
+		previous:      make(map[string]string),
+		current:       make(map[string]string),
+		counts:        make(map[string]int),
+	}
+
+	data, err := os.ReadFile(path)
+	if os.IsNotExist(err) {
+		return cd, nil
+	}
+	if err != nil {
+		return nil, fmt.Errorf("failed to read change state: %w", err)
+	}
+	var state changeState
+	if err := json.Unmarshal(data, &state); err != nil {
+		return nil, fmt.Errorf("change state %s is corrupt (remove it to start over): %w", path, err)
+	}
+	if state.KeyField == keyField && state.Hashes != nil {
+		cd.previous = state.Hashes
+	}
+	return cd, nil
+}
+
+// Mark returns a copy of record with ChangeField set, or nil for an unchanged
+// record when SkipUnchanged is set
+func (cd *ChangeDetector) Mark(record map[string]interface{}) (map[string]interface{}, error) {
+	content := make(map[string]interface{}, len(record))
+	for key, value := range record {
+		if !strings.HasPrefix(key, "_") {
+			content[key] = value
+		}
+	}
+	hash, err := contentHash(content)
+	if err != nil {
+		return nil, err
+	}
+	key, err := cd.recordKey(record, hash)
+	if err != nil {
+		return nil, err
+	}
+
+	cd.mu.Lock()
+	defer cd.mu.Unlock()
+	change := ChangeNew
+	if previous, ok := cd.previous[key]; ok {
+		change = ChangeModified
+		if previous == hash {
+			change = ChangeUnchanged
+		}
+	}
+	cd.current[key] = hash
+	cd.counts[change]++
+
+	if change == ChangeUnchanged && cd.SkipUnchanged {
+		return nil, nil
+	}
+	marked := make(map[string]interface{}, len(record)+1)
+	for k, v := range record {
+		marked[k] = v
+	}
+	marked[ChangeField] = change
+	return marked, nil
+}
+
+// recordKey identifies record across runs by its KeyField value, or by hash
+// when it has none
+func (cd *ChangeDetector) recordKey(record map[string]interface{}, hash string) (string, error) {
+	value, ok := record[cd.KeyField]
+	if !ok || value == nil || value == "" {
+		return "hash:" + hash, nil
+	}
+	if s, ok := value.(string); ok {
+		return s, nil
+	}
+	encoded, err := json.Marshal(value)
+	if err != nil {
+		return "", fmt.Errorf("failed to encode key field %s: %w", cd.KeyField, err)
+	}
+	return string(encoded), nil
+}
+
+// Counts returns how many records were marked with each change marker
+func (cd *ChangeDetector) Counts() map[string]int {
+	cd.mu.Lock()
+	defer cd.mu.Unlock()
+	counts := make(map[string]int, len(cd.counts))
+	for change, n := range cd.counts {
+		counts[change] = n
+	}
+	return counts
+}
+
+// Save writes the hashes of the records marked in this run over the saved ones.
+// Records this run did not reach, say on a page that failed, keep their
+// previous hash. The file is replaced by rename, so a failed write leaves the
+// previous state intact.
+func (cd *ChangeDetector) Save() error {
+	cd.mu.Lock()
+	hashes := make(map[string]string, len(cd.previous)+len(cd.current))
+	for key, hash := range cd.previous {
+		hashes[key] = hash
+	}
+	for key, hash := range cd.current {
+		hashes[key] = hash
+	}
+	cd.mu.Unlock()
+
+	data, err := json.MarshalIndent(changeState{KeyField: cd.KeyField, Hashes: hashes, SavedAt: time.Now()}, "", "  ")
+	if err != nil {
+		return fmt.Errorf("failed to encode change state: %w", err)
+	}
+	if dir := filepath.Dir(cd.path); dir != "." {
+		if err := os.MkdirAll(dir, 0755); err != nil {
+			return fmt.Errorf("failed to create change state directory: %w", err)
+		}
+	}
+	tmp := cd.path + ".tmp"
+	if err := os.WriteFile(tmp, data, 0644); err != nil {
+		return fmt.Errorf("failed to write change state: %w", err)
+	}
+	if err := os.Rename(tmp, cd.path); err != nil {
+		os.Remove(tmp)
+		return fmt.Errorf("failed to write change state: %w", err)
+	}
+	return nil
+}
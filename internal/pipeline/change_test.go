@@ -0,0 +1,91 @@
+// internal/pipeline/change_test.go
+package pipeline
+
+import (
+	"os"
+	"path/filepath"
+	"testing"
+)
+
+func TestChangeDetector(t *testing.T) {
+	state := filepath.Join(t.TempDir(), "state", "changes.json")
+
+	first, err := OpenChangeDetector(state, "url", false)
+	if err != nil {
+		t.Fatalf("OpenChangeDetector: %v", err)
+	}
+	for _, record := range []map[string]interface{}{
+		{"url": "https://example.com/a", "price": "10"},
+		{"url": "https://example.com/b", "price": "20"},
+		{"url": "https://example.com/c", "price": "30"},
+	} {
+		marked, err := first.Mark(record)
+		if err != nil {
+			t.Fatalf("Mark: %v", err)
+		}
+		if marked[ChangeField] != ChangeNew {
+			t.Errorf("Expected every record of the first run to be new, got %v", marked[ChangeField])
+		}
+	}
+	if err := first.Save(); err != nil {
+		t.Fatalf("Save: %v", err)
+	}
+
+	second, err := OpenChangeDetector(state, "url", false)
+	if err != nil {
+		t.Fatalf("OpenChangeDetector: %v", err)
+	}
+	tests := []struct {
+		record map[string]interface{}
+		want   string
+	}{
+		// Metadata keys do not count as changes
+		{map[string]interface{}{"url": "https://example.com/a", "price": "10", "_meta": map[string]interface{}{"proxy": "p1"}}, ChangeUnchanged},
+		{map[string]interface{}{"url": "https://example.com/b", "price": "25"}, ChangeModified},
+		{map[string]interface{}{"url": "https://example.com/d", "price": "40"}, ChangeNew},
+	}
+	for _, tt := range tests {
+		marked, err := second.Mark(tt.record)
+		if err != nil {
+			t.Fatalf("Mark: %v", err)
+		}
+		if marked[ChangeField] != tt.want {
+			t.Errorf("Record %v: expected %s, got %v", tt.record["url"], tt.want, marked[ChangeField])
+		}
+		if _, ok := tt.record[ChangeField]; ok {
+			t.Error("Mark should not modify the record it is given")
+		}
+	}
+	counts := second.Counts()
+	if counts[ChangeNew] != 1 || counts[ChangeModified] != 1 || counts[ChangeUnchanged] != 1 {
+		t.Errorf("Unexpected counts: %v", counts)
+	}
+
+	// Skipping unchanged records still compares against the saved run
+	skipping, err := OpenChangeDetector(state, "url", true)
+	if err != nil {
+		t.Fatalf("OpenChangeDetector: %v", err)
+	}
+	if marked, _ := skipping.Mark(map[string]interface{}{"url": "https://example.com/c", "price": "30"}); marked != nil {
+		t.Errorf("Expected an unchanged record to be skipped, got %v", marked)
+	}
+
+	// A different key field starts over
+	rekeyed, err := OpenChangeDetector(state, "sku", false)
+	if err != nil {
+		t.Fatalf("OpenChangeDetector: %v", err)
+	}
+	if marked, _ := rekeyed.Mark(map[string]interface{}{"url": "https://example.com/a", "price": "10"}); marked[ChangeField] != ChangeNew {
+		t.Errorf("Expected state saved under another key field to be ignored, got %v", marked[ChangeField])
+	}
+}
+
+func TestChangeDetectorCorruptState(t *testing.T) {
+	state := filepath.Join(t.TempDir(), "changes.json")
+	if err := os.WriteFile(state, []byte("{not json"), 0644); err != nil {
+		t.Fatal(err)
+	}
+	if _, err := OpenChangeDetector(state, "url", false); err == nil {
+		t.Error("Expected an error for a corrupt state file")
+	}
+}
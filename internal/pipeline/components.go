@@ -3,8 +3,15 @@ package pipeline
 
 import (
 	"context"
+	"crypto/sha256"
+	"encoding/hex"
+	"encoding/json"
 	"fmt"
+	"slices"
+	"sync"
 	"time"
+
+	"github.com/valpere/DataScrapexter/internal/utils"
 )
 
 // DataExtractor handles data extraction from raw content
@@ -169,19 +176,62 @@ func (dv *DataValidator) validateField(rule ValidationRule, value interface{}) e
 	return nil
 }
 
-// RecordDeduplicator handles duplicate detection and removal
+// DuplicateField is the record key DryRun uses to annotate a would-be duplicate
+const DuplicateField = "_duplicate"
+
+// RecordDeduplicator handles duplicate detection and removal. Deduplicate returns
+// nil for a record it has already seen. It is safe for concurrent use.
 type RecordDeduplicator struct {
-	Method    string   `yaml:"method" json:"method"`                           // "hash", "field", "similarity"
+	Method    string   `yaml:"method" json:"method"`                           // "hash", "field", "url", "similarity"
 	Fields    []string `yaml:"fields,omitempty" json:"fields,omitempty"`       // Fields to use for deduplication
 	Threshold float64  `yaml:"threshold,omitempty" json:"threshold,omitempty"` // Similarity threshold
 	CacheSize int      `yaml:"cache_size" json:"cache_size"`                   // Size of deduplication cache
 
+	// SimilarityAlgorithm scores records for the similarity method: jaccard_exact
+	// (default), token_cosine or levenshtein
+	SimilarityAlgorithm string `yaml:"similarity_algorithm,omitempty" json:"similarity_algorithm,omitempty"`
+
+	// StripParams are the query parameters the url method drops before comparing;
+	// empty uses utils.DefaultStripParams
+	StripParams []string `yaml:"strip_params,omitempty" json:"strip_params,omitempty"`
+
+	// DryRun keeps duplicates, annotating them under DuplicateField and listing
+	// them in Report, so a configuration can be checked before it drops data
+	DryRun bool `yaml:"dry_run,omitempty" json:"dry_run,omitempty"`
+
+	mu          sync.Mutex
 	seenHashes  map[string]bool
-	seenRecords []map[string]interface{}
+	seenOrder   []string                          // oldest first, for evicting once CacheSize is reached
+	seenRecords map[string]map[string]interface{} // first record per hash, kept in dry run only
+	similar     []map[string]interface{}          // compared values of kept records, oldest first, for the similarity method
+	matches     []DuplicateMatch
+}
+
+// contentHash is the SHA-256 of value as canonical JSON, so map key order
+// does not matter
+func contentHash(value interface{}) (string, error) {
+	encoded, err := json.Marshal(value)
+	if err != nil {
+		return "", fmt.Errorf("failed to hash record: %w", err)
+	}
+	sum := sha256.Sum256(encoded)
+	return hex.EncodeToString(sum[:]), nil
+}
+
+// DuplicateMatch describes a record that the deduplicator would have dropped
+type DuplicateMatch struct {
+	Method  string                 `json:"method"`
+	Key     interface{}            `json:"key"`   // Field values for the field and similarity methods, the content hash for hash
+	Score   float64                `json:"score"` // 1 for exact key matches, the similarity score otherwise
+	Record  map[string]interface{} `json:"record"`
+	Matched map[string]interface{} `json:"matched"` // The earlier record it duplicates
 }
 
 // Deduplicate removes or marks duplicate records
 func (rd *RecordDeduplicator) Deduplicate(ctx context.Context, data map[string]interface{}) (map[string]interface{}, error) {
+	rd.mu.Lock()
+	defer rd.mu.Unlock()
+
 	if rd.seenHashes == nil {
 		rd.seenHashes = make(map[string]bool)
 	}
@@ -191,6 +241,8 @@ func (rd *RecordDeduplicator) Deduplicate(ctx context.Context, data map[string]i
 		return rd.deduplicateByHash(data)
 	case "field":
 		return rd.deduplicateByField(data)
+	case "url":
+		return rd.deduplicateByURL(data)
 	case "similarity":
 		return rd.deduplicateBySimilarity(data)
 	default:
@@ -198,70 +250,241 @@ func (rd *RecordDeduplicator) Deduplicate(ctx context.Context, data map[string]i
 	}
 }
 
-// deduplicateByHash performs hash-based duplicate detection and removal.
-//
-// Currently passes data through unchanged as hash-based deduplication is not yet implemented.
-// This method is designed to identify duplicate records by generating cryptographic hashes
-// of record content and maintaining a hash registry for comparison.
-//
-// Future implementation will support:
-//   - SHA256 hash generation for entire records or specified fields
-//   - In-memory hash set with configurable size limits
-//   - Persistent hash storage for cross-session deduplication
-//   - Configurable hash collision handling
+// DeduplicateAll returns the records Deduplicate keeps, in order; in dry run
+// duplicates come back annotated. A record that cannot be compared is kept.
+func (rd *RecordDeduplicator) DeduplicateAll(ctx context.Context, records []map[string]interface{}) []map[string]interface{} {
+	kept := make([]map[string]interface{}, 0, len(records))
+	for _, record := range records {
+		result, err := rd.Deduplicate(ctx, record)
+		if err != nil {
+			result = record
+		}
+		if result != nil {
+			kept = append(kept, result)
+		}
+	}
+	return kept
+}
+
+// deduplicateByHash drops records whose full content matches an earlier record.
+// Records are hashed as canonical JSON, so key order does not matter.
 func (rd *RecordDeduplicator) deduplicateByHash(data map[string]interface{}) (map[string]interface{}, error) {
-	// NOTE: Hash-based deduplication not yet implemented.
-	// Future implementation would:
-	// 1. Generate SHA256 hash of entire record or specified fields
-	// 2. Maintain hash set in memory or persistent storage
-	// 3. Skip records with duplicate hashes
-	// Currently passes all data through unchanged.
-	return data, nil
+	return rd.filterSeen(data, data, nil)
 }
 
-// deduplicateByField performs field-based duplicate detection using specific field combinations.
-//
-// Currently passes data through unchanged as field-based deduplication is not yet implemented.
-// This method is designed to identify duplicate records by comparing values from specified
-// fields such as URLs, IDs, titles, or other unique identifiers.
-//
-// Future implementation will support:
-//   - Configurable field selection for uniqueness checking
-//   - Composite field combinations (e.g., URL + title)
-//   - Field value normalization and preprocessing
-//   - Memory-efficient field value storage with LRU eviction
+// deduplicateByField drops records whose values for Fields match an earlier
+// record, e.g. the same URL with a different title. A record missing a field
+// compares as null for it. Without Fields it behaves like the hash method.
 func (rd *RecordDeduplicator) deduplicateByField(data map[string]interface{}) (map[string]interface{}, error) {
-	// NOTE: Field-based deduplication not yet implemented.
-	// Future implementation would:
-	// 1. Extract values from specified fields (e.g., URL, ID, title)
-	// 2. Maintain field value sets in memory or persistent storage
-	// 3. Skip records with duplicate field combinations
-	// Currently passes all data through unchanged.
+	if len(rd.Fields) == 0 {
+		return rd.filterSeen(data, data, nil)
+	}
+	key := make([]interface{}, len(rd.Fields))
+	values := make(map[string]interface{}, len(rd.Fields))
+	for i, field := range rd.Fields {
+		key[i] = data[field]
+		values[field] = data[field]
+	}
+	return rd.filterSeen(data, key, values)
+}
+
+// DefaultURLField is the field the url method compares when Fields is empty
+const DefaultURLField = "url"
+
+// deduplicateByURL drops records whose URL field, Fields[0] or DefaultURLField,
+// has the same canonical form as an earlier record's: tracking parameters,
+// host case, trailing slash and fragment are ignored. Records without the
+// field are kept.
+func (rd *RecordDeduplicator) deduplicateByURL(data map[string]interface{}) (map[string]interface{}, error) {
+	field := DefaultURLField
+	if len(rd.Fields) > 0 {
+		field = rd.Fields[0]
+	}
+	raw, ok := data[field].(string)
+	if !ok || raw == "" {
+		return data, nil
+	}
+
+	stripParams := rd.StripParams
+	if len(stripParams) == 0 {
+		stripParams = utils.DefaultStripParams
+	}
+	canonical := utils.CanonicalizeURL(raw, stripParams)
+	return rd.filterSeen(data, canonical, canonical)
+}
+
+// filterSeen returns nil if key was seen before, otherwise remembers it and returns
+// data. In dry run a repeat is returned annotated instead; shown is the key as
+// reported, or the content hash when nil.
+func (rd *RecordDeduplicator) filterSeen(data map[string]interface{}, key interface{}, shown interface{}) (map[string]interface{}, error) {
+	hash, err := contentHash(key)
+	if err != nil {
+		return nil, err
+	}
+
+	if rd.seenHashes[hash] {
+		if !rd.DryRun {
+			return nil, nil
+		}
+		if shown == nil {
+			shown = hash
+		}
+		return rd.annotateDuplicate(data, shown, rd.seenRecords[hash], 1), nil
+	}
+
+	if rd.CacheSize > 0 && len(rd.seenOrder) >= rd.CacheSize {
+		delete(rd.seenHashes, rd.seenOrder[0])
+		delete(rd.seenRecords, rd.seenOrder[0])
+		rd.seenOrder = rd.seenOrder[1:]
+	}
+	rd.seenHashes[hash] = true
+	rd.seenOrder = append(rd.seenOrder, hash)
+	if rd.DryRun {
+		if rd.seenRecords == nil {
+			rd.seenRecords = make(map[string]map[string]interface{})
+		}
+		rd.seenRecords[hash] = data
+	}
 	return data, nil
 }
 
-// deduplicateBySimilarity performs advanced similarity-based duplicate detection using fuzzy matching.
-//
-// Currently passes data through unchanged as similarity-based deduplication is not yet implemented.
-// This method is designed to identify near-duplicate records using fuzzy string matching algorithms
-// and configurable similarity thresholds for intelligent duplicate detection.
-//
-// Future implementation will support:
-//   - Multiple similarity algorithms (Levenshtein distance, Jaccard similarity, cosine similarity)
-//   - Machine learning techniques for semantic similarity detection
-//   - Configurable similarity thresholds per field type
-//   - Performance-optimized similarity computation with indexing
+// annotateDuplicate records a dry-run match and returns a copy of data carrying it
+func (rd *RecordDeduplicator) annotateDuplicate(data map[string]interface{}, key interface{}, matched map[string]interface{}, score float64) map[string]interface{} {
+	rd.matches = append(rd.matches, DuplicateMatch{
+		Method:  rd.Method,
+		Key:     key,
+		Score:   score,
+		Record:  data,
+		Matched: matched,
+	})
+
+	annotated := make(map[string]interface{}, len(data)+1)
+	for k, v := range data {
+		annotated[k] = v
+	}
+	annotated[DuplicateField] = map[string]interface{}{
+		"method":  rd.Method,
+		"key":     key,
+		"score":   score,
+		"matched": matched,
+	}
+	return annotated
+}
+
+// Report returns the duplicates found so far in dry run, in the order they were seen
+func (rd *RecordDeduplicator) Report() []DuplicateMatch {
+	rd.mu.Lock()
+	defer rd.mu.Unlock()
+	return append([]DuplicateMatch(nil), rd.matches...)
+}
+
+// DeduplicatorState is what a RecordDeduplicator has seen, in a form that
+// survives a restart; dry-run matches are not part of it
+type DeduplicatorState struct {
+	Method  string                   `json:"method"`
+	Fields  []string                 `json:"fields,omitempty"`
+	Seen    []string                 `json:"seen"`              // Record hashes, oldest first
+	Similar []map[string]interface{} `json:"similar,omitempty"` // Compared values kept by the similarity method, oldest first
+}
+
+// State returns what the deduplicator has seen so far
+func (rd *RecordDeduplicator) State() DeduplicatorState {
+	rd.mu.Lock()
+	defer rd.mu.Unlock()
+	return DeduplicatorState{
+		Method: rd.Method,
+		Fields: append([]string(nil), rd.Fields...),
+		Seen:   append([]string(nil), rd.seenOrder...),
+
+		Similar: append([]map[string]interface{}(nil), rd.similar...),
+	}
+}
+
+// Restore adds the records of a saved state to those already seen. A state saved
+// under another method or other fields hashed different keys, so it is rejected.
+func (rd *RecordDeduplicator) Restore(state DeduplicatorState) error {
+	rd.mu.Lock()
+	defer rd.mu.Unlock()
+
+	if state.Method != rd.Method || !slices.Equal(state.Fields, rd.Fields) {
+		return fmt.Errorf("deduplicator state is for method %q on %v, not %q on %v",
+			state.Method, state.Fields, rd.Method, rd.Fields)
+	}
+	if rd.seenHashes == nil {
+		rd.seenHashes = make(map[string]bool)
+	}
+	for _, hash := range state.Seen {
+		if rd.seenHashes[hash] {
+			continue
+		}
+		if rd.CacheSize > 0 && len(rd.seenOrder) >= rd.CacheSize {
+			delete(rd.seenHashes, rd.seenOrder[0])
+			rd.seenOrder = rd.seenOrder[1:]
+		}
+		rd.seenHashes[hash] = true
+		rd.seenOrder = append(rd.seenOrder, hash)
+	}
+	for _, values := range state.Similar {
+		rd.keepSimilar(values)
+	}
+	return nil
+}
+
+// deduplicateBySimilarity drops records that score at least Threshold (default
+// DefaultSimilarityThreshold) against an earlier record under SimilarityAlgorithm.
+// Fields are compared, or every key when Fields is empty. jaccard_exact keeps
+// "iPhone 13" and "iphone 13 " apart; token_cosine and levenshtein ignore case
+// and spacing, so they catch near-duplicate titles. Each record is compared with
+// every kept record, so CacheSize bounds the work as well as the memory.
 func (rd *RecordDeduplicator) deduplicateBySimilarity(data map[string]interface{}) (map[string]interface{}, error) {
-	// NOTE: Similarity-based deduplication not yet implemented.
-	// Future implementation would:
-	// 1. Use fuzzy string matching (Levenshtein distance, Jaccard similarity)
-	// 2. Apply ML techniques for semantic similarity detection
-	// 3. Define similarity thresholds for different field types
-	// 4. Skip records that are too similar to existing ones
-	// Currently passes all data through unchanged.
+	if rd.SimilarityAlgorithm != "" && !slices.Contains(ValidSimilarityAlgorithms(), rd.SimilarityAlgorithm) {
+		return nil, fmt.Errorf("unknown similarity algorithm %q", rd.SimilarityAlgorithm)
+	}
+	threshold := rd.Threshold
+	if threshold <= 0 {
+		threshold = DefaultSimilarityThreshold
+	}
+
+	values := data
+	if len(rd.Fields) > 0 {
+		values = make(map[string]interface{}, len(rd.Fields))
+		for _, field := range rd.Fields {
+			if value, ok := data[field]; ok {
+				values[field] = value
+			}
+		}
+	}
+
+	for _, earlier := range rd.similar {
+		score, err := recordSimilarity(rd.SimilarityAlgorithm, values, earlier, rd.Fields)
+		if err != nil {
+			return nil, err
+		}
+		if score < threshold {
+			continue
+		}
+		if !rd.DryRun {
+			return nil, nil
+		}
+		var key interface{}
+		if len(rd.Fields) > 0 {
+			key = values
+		}
+		return rd.annotateDuplicate(data, key, earlier, score), nil
+	}
+
+	rd.keepSimilar(values)
 	return data, nil
 }
 
+// keepSimilar remembers a record's compared values, evicting the oldest at CacheSize
+func (rd *RecordDeduplicator) keepSimilar(values map[string]interface{}) {
+	if rd.CacheSize > 0 && len(rd.similar) >= rd.CacheSize {
+		rd.similar = rd.similar[1:]
+	}
+	rd.similar = append(rd.similar, values)
+}
+
 // DataEnricher handles data enrichment from external sources
 type DataEnricher struct {
 	Enrichers []Enricher    `yaml:"enrichers" json:"enrichers"`
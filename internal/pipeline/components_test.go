@@ -3,6 +3,7 @@ package pipeline
 
 import (
 	"context"
+	"encoding/json"
 	"fmt"
 	"reflect"
 	"testing"
@@ -316,10 +317,8 @@ func TestRecordDeduplicator_Deduplicate(t *testing.T) {
 		if err != nil {
 			t.Errorf("unexpected error: %v", err)
 		}
-		// Since implementation is pass-through, we expect the record back
-		// In a real implementation, this might return nil or an error
-		if !reflect.DeepEqual(result2, record2) {
-			t.Errorf("duplicate record handling differs from expected, got %v", result2)
+		if result2 != nil {
+			t.Errorf("duplicate record should be dropped, got %v", result2)
 		}
 
 		// Different record should pass through
@@ -365,9 +364,21 @@ func TestRecordDeduplicator_Deduplicate(t *testing.T) {
 		if err != nil {
 			t.Errorf("unexpected error: %v", err)
 		}
-		// Current implementation is pass-through, so record comes back unchanged
-		if !reflect.DeepEqual(result2, record2) {
-			t.Errorf("field-based duplicate detection differs from expected")
+		if result2 != nil {
+			t.Errorf("record with a duplicate url should be dropped, got %v", result2)
+		}
+
+		// Record with a new URL passes even with a repeated title
+		record3 := map[string]interface{}{
+			"title": "Different Title",
+			"url":   "https://example.com/other",
+		}
+		result3, err := deduplicator.Deduplicate(ctx, record3)
+		if err != nil {
+			t.Errorf("unexpected error: %v", err)
+		}
+		if !reflect.DeepEqual(result3, record3) {
+			t.Errorf("record with a new url should pass through")
 		}
 	})
 
@@ -419,6 +430,106 @@ func TestRecordDeduplicator_Deduplicate(t *testing.T) {
 			t.Errorf("empty configuration should pass through")
 		}
 	})
+
+	t.Run("url method deduplication", func(t *testing.T) {
+		deduplicator := &RecordDeduplicator{Method: "url"}
+
+		for _, tt := range []struct {
+			url  string
+			kept bool
+		}{
+			{"https://Example.com/item/1?b=2&a=1", true},
+			{"https://example.com/item/1/?a=1&b=2&utm_source=mail#reviews", false},
+			{"https://example.com/item/2", true},
+			{"https://example.com/item/1?a=1&b=3", true},
+		} {
+			result, err := deduplicator.Deduplicate(ctx, map[string]interface{}{"url": tt.url})
+			if err != nil {
+				t.Fatalf("unexpected error: %v", err)
+			}
+			if (result != nil) != tt.kept {
+				t.Errorf("%s: kept = %v, want %v", tt.url, result != nil, tt.kept)
+			}
+		}
+
+		// Records without the URL field are never treated as duplicates
+		for i := 0; i < 2; i++ {
+			if result, _ := deduplicator.Deduplicate(ctx, map[string]interface{}{"title": "No URL"}); result == nil {
+				t.Error("record without a url should pass through")
+			}
+		}
+	})
+
+	t.Run("dry run annotates duplicates", func(t *testing.T) {
+		deduplicator := &RecordDeduplicator{
+			Method: "field",
+			Fields: []string{"url"},
+			DryRun: true,
+		}
+
+		first := map[string]interface{}{"title": "First", "url": "https://example.com"}
+		second := map[string]interface{}{"title": "Second", "url": "https://example.com"}
+		if _, err := deduplicator.Deduplicate(ctx, first); err != nil {
+			t.Fatalf("unexpected error: %v", err)
+		}
+		result, err := deduplicator.Deduplicate(ctx, second)
+		if err != nil {
+			t.Fatalf("unexpected error: %v", err)
+		}
+		if result == nil {
+			t.Fatal("dry run should keep the duplicate")
+		}
+		if _, ok := second[DuplicateField]; ok {
+			t.Error("dry run should not modify the input record")
+		}
+
+		annotation, ok := result[DuplicateField].(map[string]interface{})
+		if !ok {
+			t.Fatalf("expected %s annotation, got %v", DuplicateField, result)
+		}
+		wantKey := map[string]interface{}{"url": "https://example.com"}
+		if !reflect.DeepEqual(annotation["key"], wantKey) || annotation["score"] != 1.0 {
+			t.Errorf("unexpected annotation: %v", annotation)
+		}
+		if !reflect.DeepEqual(annotation["matched"], first) {
+			t.Errorf("matched = %v, want %v", annotation["matched"], first)
+		}
+
+		report := deduplicator.Report()
+		if len(report) != 1 || report[0].Method != "field" || !reflect.DeepEqual(report[0].Record, second) {
+			t.Errorf("unexpected report: %+v", report)
+		}
+	})
+	t.Run("state survives a restart", func(t *testing.T) {
+		before := &RecordDeduplicator{Method: "field", Fields: []string{"sku"}}
+		if _, err := before.Deduplicate(ctx, map[string]interface{}{"sku": "A1"}); err != nil {
+			t.Fatalf("unexpected error: %v", err)
+		}
+
+		encoded, err := json.Marshal(before.State())
+		if err != nil {
+			t.Fatalf("failed to encode state: %v", err)
+		}
+		var state DeduplicatorState
+		if err := json.Unmarshal(encoded, &state); err != nil {
+			t.Fatalf("failed to decode state: %v", err)
+		}
+
+		after := &RecordDeduplicator{Method: "field", Fields: []string{"sku"}}
+		if err := after.Restore(state); err != nil {
+			t.Fatalf("unexpected error: %v", err)
+		}
+		if result, _ := after.Deduplicate(ctx, map[string]interface{}{"sku": "A1", "title": "Again"}); result != nil {
+			t.Error("record seen before the restart should be dropped")
+		}
+		if result, _ := after.Deduplicate(ctx, map[string]interface{}{"sku": "B2"}); result == nil {
+			t.Error("new record should pass through")
+		}
+
+		if err := (&RecordDeduplicator{Method: "hash"}).Restore(state); err == nil {
+			t.Error("expected state of another method to be rejected")
+		}
+	})
 }
 
 func TestDataEnricher_Enrich(t *testing.T) {
@@ -0,0 +1,153 @@
+// internal/pipeline/currency.go
+package pipeline
+
+import (
+	"fmt"
+	"strconv"
+	"strings"
+	"sync"
+)
+
+// RateProvider supplies currency exchange rates for the convert_currency transform
+type RateProvider interface {
+	// Rate returns how many units of `to` one unit of `from` is worth
+	Rate(from, to string) (float64, error)
+}
+
+// StaticRateProvider resolves rates from a fixed table of values relative to a
+// common base currency (e.g. {"USD": 1, "EUR": 0.92, "GBP": 0.79})
+type StaticRateProvider map[string]float64
+
+// Rate returns the cross rate between two currencies in the table
+func (p StaticRateProvider) Rate(from, to string) (float64, error) {
+	fromRate, ok := p[strings.ToUpper(from)]
+	if !ok || fromRate <= 0 {
+		return 0, fmt.Errorf("no exchange rate for currency %s", from)
+	}
+	toRate, ok := p[strings.ToUpper(to)]
+	if !ok || toRate <= 0 {
+		return 0, fmt.Errorf("no exchange rate for currency %s", to)
+	}
+	return toRate / fromRate, nil
+}
+
+var (
+	rateProviders   = make(map[string]RateProvider)
+	rateProvidersMu sync.RWMutex
+)
+
+// RegisterRateProvider makes a rate provider available to convert_currency
+// transforms via Params["provider"]
+func RegisterRateProvider(name string, provider RateProvider) {
+	rateProvidersMu.Lock()
+	defer rateProvidersMu.Unlock()
+	rateProviders[name] = provider
+}
+
+// getRateProvider returns a registered rate provider by name
+func getRateProvider(name string) (RateProvider, bool) {
+	rateProvidersMu.RLock()
+	defer rateProvidersMu.RUnlock()
+	provider, ok := rateProviders[name]
+	return provider, ok
+}
+
+// convertCurrency converts a numeric price between currencies.
+// Params: from, to (currency codes), rates (static table) or provider (registered
+// name), decimals (default 2) and strict (error on missing rate instead of
+// passing the input through unchanged).
+func (tr *TransformRule) convertCurrency(input string) (string, error) {
+	from := paramString(tr.Params, "from")
+	to := paramString(tr.Params, "to")
+	if from == "" || to == "" {
+		return "", fmt.Errorf("convert_currency requires 'from' and 'to' parameters")
+	}
+
+	numericMatch := currencyNumericRegex.FindString(strings.TrimSpace(input))
+	if numericMatch == "" {
+		return "", fmt.Errorf("no numeric value in %q", input)
+	}
+	cleaned := strings.ReplaceAll(strings.ReplaceAll(numericMatch, " ", ""), ",", "")
+	value, err := strconv.ParseFloat(cleaned, 64)
+	if err != nil {
+		return "", fmt.Errorf("failed to parse amount: %w", err)
+	}
+
+	strict, _ := tr.Params["strict"].(bool)
+
+	provider, err := tr.rateProvider()
+	if err != nil {
+		return "", err
+	}
+
+	rate := 1.0
+	if !strings.EqualFold(from, to) {
+		rate, err = provider.Rate(from, to)
+		if err != nil {
+			if strict {
+				return "", fmt.Errorf("currency conversion failed: %w", err)
+			}
+			return input, nil
+		}
+	}
+
+	decimals := 2
+	if d, ok := tr.Params["decimals"].(int); ok && d >= 0 {
+		decimals = d
+	}
+
+	return strconv.FormatFloat(value*rate, 'f', decimals, 64), nil
+}
+
+// rateProvider resolves the rate source configured for the rule
+func (tr *TransformRule) rateProvider() (RateProvider, error) {
+	if name := paramString(tr.Params, "provider"); name != "" {
+		provider, ok := getRateProvider(name)
+		if !ok {
+			return nil, fmt.Errorf("unknown rate provider: %s", name)
+		}
+		return provider, nil
+	}
+
+	rates := StaticRateProvider{}
+	switch raw := tr.Params["rates"].(type) {
+	case map[string]interface{}:
+		for code, v := range raw {
+			if rate, ok := toRate(v); ok {
+				rates[strings.ToUpper(code)] = rate
+			}
+		}
+	case map[string]float64:
+		for code, rate := range raw {
+			rates[strings.ToUpper(code)] = rate
+		}
+	case nil:
+		return nil, fmt.Errorf("convert_currency requires 'rates' or 'provider' parameter")
+	default:
+		return nil, fmt.Errorf("invalid 'rates' parameter: expected a map of currency codes to rates")
+	}
+	return rates, nil
+}
+
+// paramString returns a string parameter or "" if missing
+func paramString(params map[string]interface{}, key string) string {
+	if params == nil || params[key] == nil {
+		return ""
+	}
+	return fmt.Sprintf("%v", params[key])
+}
+
+// toRate converts a YAML/JSON numeric value to float64
+func toRate(v interface{}) (float64, bool) {
+	switch n := v.(type) {
+	case float64:
+		return n, true
+	case float32:
+		return float64(n), true
+	case int:
+		return float64(n), true
+	case int64:
+		return float64(n), true
+	}
+	return 0, false
+}
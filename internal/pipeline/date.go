@@ -0,0 +1,181 @@
+// internal/pipeline/date.go
+package pipeline
+
+import (
+	"context"
+	"fmt"
+	"regexp"
+	"strconv"
+	"strings"
+	"time"
+)
+
+// DefaultDateLayouts are tried, in order, when a date transform sets no layouts
+var DefaultDateLayouts = []string{
+	time.RFC3339,
+	"2006-01-02T15:04:05",
+	"2006-01-02 15:04:05",
+	time.DateOnly,
+	time.RFC1123Z,
+	time.RFC1123,
+	"Jan 2, 2006",
+	"January 2, 2006",
+	"2 Jan 2006",
+	"2 January 2006",
+	"Jan 2, 2006 3:04 PM",
+	"January 2, 2006 3:04 PM",
+}
+
+// namedDateLayouts lets layouts and format name Go's predefined layouts
+var namedDateLayouts = map[string]string{
+	"RFC3339":     time.RFC3339,
+	"RFC3339Nano": time.RFC3339Nano,
+	"RFC1123":     time.RFC1123,
+	"RFC1123Z":    time.RFC1123Z,
+	"RFC822":      time.RFC822,
+	"RFC822Z":     time.RFC822Z,
+	"RFC850":      time.RFC850,
+	"ANSIC":       time.ANSIC,
+	"DateOnly":    time.DateOnly,
+	"DateTime":    time.DateTime,
+	"Kitchen":     time.Kitchen,
+}
+
+// relativeDateRegex matches "3 hours ago", "a day ago" and "in 2 weeks"
+var relativeDateRegex = regexp.MustCompile(`^(?:(in)\s+)?(a|an|\d+)\s+(second|minute|hour|day|week|month|year)s?(\s+ago)?$`)
+
+// timeNow is the clock relative dates are resolved against
+var timeNow = time.Now
+
+// convertDate parses input with the rule's layouts, or as a relative date such
+// as "2 days ago", and formats it with Format (default RFC3339).
+// Params: layouts (candidate Go layouts, default DefaultDateLayouts) and timezone
+// (IANA name for inputs without an offset, default UTC). An unparseable value
+// passes through unchanged, or is an error for a required field (see WithRequiredField).
+func (tr *TransformRule) convertDate(ctx context.Context, input string) (string, error) {
+	layouts, err := dateLayouts(tr.Params)
+	if err != nil {
+		return "", err
+	}
+	location := time.UTC
+	if name := paramString(tr.Params, "timezone"); name != "" {
+		if location, err = time.LoadLocation(name); err != nil {
+			return "", fmt.Errorf("invalid timezone: %w", err)
+		}
+	}
+
+	parsed, ok := parseRelativeDate(input, timeNow().In(location))
+	if !ok {
+		parsed, ok = parseDate(strings.TrimSpace(input), layouts, location)
+	}
+	if !ok {
+		if isRequiredField(ctx) {
+			return "", fmt.Errorf("no layout matches date %q", input)
+		}
+		return input, nil
+	}
+
+	format := time.RFC3339
+	if tr.Format != "" {
+		format = resolveDateLayout(tr.Format)
+	}
+	return parsed.Format(format), nil
+}
+
+// dateLayouts returns the layouts param as Go layouts, or DefaultDateLayouts
+func dateLayouts(params map[string]interface{}) ([]string, error) {
+	var layouts []string
+	switch raw := params["layouts"].(type) {
+	case nil:
+		return DefaultDateLayouts, nil
+	case string:
+		layouts = []string{raw}
+	case []string:
+		layouts = raw
+	case []interface{}:
+		for _, v := range raw {
+			layout, ok := v.(string)
+			if !ok {
+				return nil, fmt.Errorf("invalid 'layouts' parameter: expected a list of strings")
+			}
+			layouts = append(layouts, layout)
+		}
+	default:
+		return nil, fmt.Errorf("invalid 'layouts' parameter: expected a list of strings")
+	}
+	resolved := make([]string, len(layouts))
+	for i, layout := range layouts {
+		resolved[i] = resolveDateLayout(layout)
+	}
+	return resolved, nil
+}
+
+// resolveDateLayout maps a predefined layout name such as RFC3339 to its layout
+func resolveDateLayout(layout string) string {
+	if named, ok := namedDateLayouts[layout]; ok {
+		return named
+	}
+	return layout
+}
+
+// parseDate tries each layout in turn, reading times without an offset in location
+func parseDate(input string, layouts []string, location *time.Location) (time.Time, bool) {
+	for _, layout := range layouts {
+		if parsed, err := time.ParseInLocation(layout, input, location); err == nil {
+			return parsed, true
+		}
+	}
+	return time.Time{}, false
+}
+
+// parseRelativeDate resolves "now", "today", "yesterday", "tomorrow",
+// "N units ago" and "in N units" against now
+func parseRelativeDate(input string, now time.Time) (time.Time, bool) {
+	text := strings.Join(strings.Fields(strings.ToLower(input)), " ")
+	switch text {
+	case "now", "just now":
+		return now, true
+	case "today":
+		return startOfDay(now), true
+	case "yesterday":
+		return startOfDay(now).AddDate(0, 0, -1), true
+	case "tomorrow":
+		return startOfDay(now).AddDate(0, 0, 1), true
+	}
+
+	match := relativeDateRegex.FindStringSubmatch(text)
+	// Exactly one of "in" and "ago" gives the direction
+	if match == nil || (match[1] == "") == (match[4] == "") {
+		return time.Time{}, false
+	}
+	n := 1
+	if match[2] != "a" && match[2] != "an" {
+		n, _ = strconv.Atoi(match[2])
+	}
+	if match[4] != "" {
+		n = -n
+	}
+
+	switch match[3] {
+	case "second":
+		return now.Add(time.Duration(n) * time.Second), true
+	case "minute":
+		return now.Add(time.Duration(n) * time.Minute), true
+	case "hour":
+		return now.Add(time.Duration(n) * time.Hour), true
+	case "day":
+		return now.AddDate(0, 0, n), true
+	case "week":
+		return now.AddDate(0, 0, 7*n), true
+	case "month":
+		return now.AddDate(0, n, 0), true
+	default: // year
+		return now.AddDate(n, 0, 0), true
+	}
+}
+
+// startOfDay returns midnight at the start of t's day, in t's location
+func startOfDay(t time.Time) time.Time {
+	year, month, day := t.Date()
+	return time.Date(year, month, day, 0, 0, 0, 0, t.Location())
+}
@@ -0,0 +1,96 @@
+// internal/pipeline/json_parse.go
+package pipeline
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+	"regexp"
+	"strconv"
+	"strings"
+)
+
+// pathIndexRegex matches bracketed array indexes such as [0] in a JSON path
+var pathIndexRegex = regexp.MustCompile(`\[(\d+)\]`)
+
+// SplitJSONPath turns "$.props.items[0].name" into [props items 0 name]
+func SplitJSONPath(path string) []string {
+	path = strings.TrimPrefix(strings.TrimSpace(path), "$")
+	path = pathIndexRegex.ReplaceAllString(path, ".$1")
+
+	var keys []string
+	for _, key := range strings.Split(path, ".") {
+		if key != "" {
+			keys = append(keys, key)
+		}
+	}
+	return keys
+}
+
+// LookupJSONPath walks keys through nested maps and arrays. Numeric keys index arrays;
+// other keys applied to an array resolve against its first matching element.
+func LookupJSONPath(value interface{}, keys []string) (interface{}, bool) {
+	if len(keys) == 0 {
+		return value, true
+	}
+
+	switch v := value.(type) {
+	case map[string]interface{}:
+		next, ok := v[keys[0]]
+		if !ok {
+			return nil, false
+		}
+		return LookupJSONPath(next, keys[1:])
+	case []interface{}:
+		if index, err := strconv.Atoi(keys[0]); err == nil {
+			if index < 0 || index >= len(v) {
+				return nil, false
+			}
+			return LookupJSONPath(v[index], keys[1:])
+		}
+		for _, item := range v {
+			if result, ok := LookupJSONPath(item, keys); ok {
+				return result, true
+			}
+		}
+	}
+	return nil, false
+}
+
+// ParseJSON decodes input and returns the value at path, or all of it when path
+// is empty or "$"
+func ParseJSON(input, path string) (interface{}, error) {
+	var data interface{}
+	if err := json.Unmarshal([]byte(strings.TrimSpace(input)), &data); err != nil {
+		return nil, fmt.Errorf("failed to parse JSON: %w", err)
+	}
+	value, ok := LookupJSONPath(data, SplitJSONPath(path))
+	if !ok {
+		return nil, fmt.Errorf("JSON path not found: %s", path)
+	}
+	return value, nil
+}
+
+// ParsesJSON reports whether the list ends with a json_parse rule, so applying
+// it yields structured data rather than a string
+func (tl TransformList) ParsesJSON() bool {
+	return len(tl) > 0 && tl[len(tl)-1].Type == "json_parse"
+}
+
+// ApplyValue applies the rules like Apply, except that a final json_parse rule
+// returns the parsed map, slice or scalar instead of its JSON text
+func (tl TransformList) ApplyValue(ctx context.Context, input string) (interface{}, error) {
+	if !tl.ParsesJSON() {
+		return tl.Apply(ctx, input)
+	}
+	text, err := tl[:len(tl)-1].Apply(ctx, input)
+	if err != nil {
+		return nil, err
+	}
+	rule := tl[len(tl)-1]
+	value, err := ParseJSON(text, paramString(rule.Params, "path"))
+	if err != nil {
+		return nil, fmt.Errorf("transform failed at rule %s: %w", rule.Type, err)
+	}
+	return value, nil
+}
@@ -0,0 +1,72 @@
+// internal/pipeline/json_parse_test.go
+package pipeline
+
+import (
+	"context"
+	"reflect"
+	"testing"
+)
+
+func TestTransformList_ApplyValueJSONParse(t *testing.T) {
+	blob := ` {"sku": "A1", "offers": [{"price": 9.5, "currency": "EUR"}, {"price": 11}], "tags": ["new", "sale"]} `
+
+	tests := []struct {
+		name    string
+		input   string
+		path    interface{}
+		want    interface{}
+		wantErr bool
+	}{
+		{
+			name:  "object",
+			input: `{"sku": "A1", "stock": {"count": 3}}`,
+			want:  map[string]interface{}{"sku": "A1", "stock": map[string]interface{}{"count": float64(3)}},
+		},
+		{
+			name:  "array",
+			input: `[1, "two", {"three": true}]`,
+			want:  []interface{}{float64(1), "two", map[string]interface{}{"three": true}},
+		},
+		{
+			name:  "subtree by path",
+			input: blob,
+			path:  "$.offers[0]",
+			want:  map[string]interface{}{"price": 9.5, "currency": "EUR"},
+		},
+		{
+			name:  "array by path",
+			input: blob,
+			path:  "tags",
+			want:  []interface{}{"new", "sale"},
+		},
+		{name: "missing path", input: blob, path: "$.seller.name", wantErr: true},
+		{name: "invalid JSON", input: `{"sku": `, wantErr: true},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			rule := TransformRule{Type: "json_parse"}
+			if tt.path != nil {
+				rule.Params = map[string]interface{}{"path": tt.path}
+			}
+			got, err := TransformList{{Type: "trim"}, rule}.ApplyValue(context.Background(), tt.input)
+			if (err != nil) != tt.wantErr {
+				t.Fatalf("err = %v, wantErr %v", err, tt.wantErr)
+			}
+			if !tt.wantErr && !reflect.DeepEqual(got, tt.want) {
+				t.Errorf("got %#v, want %#v", got, tt.want)
+			}
+		})
+	}
+}
+
+func TestTransformRule_JSONParseAsText(t *testing.T) {
+	rule := TransformRule{Type: "json_parse", Params: map[string]interface{}{"path": "$.offers"}}
+	got, err := rule.Transform(context.Background(), `{"offers": [ {"price": 9.5} ]}`)
+	if err != nil {
+		t.Fatalf("Transform failed: %v", err)
+	}
+	if want := `[{"price":9.5}]`; got != want {
+		t.Errorf("got %s, want %s", got, want)
+	}
+}
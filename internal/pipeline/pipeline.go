@@ -34,6 +34,9 @@ type PipelineConfig struct {
 	EnableMetrics bool          `yaml:"enable_metrics" json:"enable_metrics"`
 	RetryAttempts int           `yaml:"retry_attempts" json:"retry_attempts"`
 	RetryDelay    time.Duration `yaml:"retry_delay" json:"retry_delay"`
+
+	// StageWorkers overrides WorkerCount per stage in Run (keys are the Stage* names)
+	StageWorkers map[string]int `yaml:"stage_workers,omitempty" json:"stage_workers,omitempty"`
 }
 
 // PipelineMetrics tracks pipeline performance
@@ -186,6 +189,13 @@ func (dp *DataPipeline) Process(ctx context.Context, rawData map[string]interfac
 				Fatal:   false, // Non-fatal error
 			})
 			// Continue with original data if deduplication fails
+		} else if deduplicated == nil {
+			// Duplicates stop here with nil Validated and Enriched so callers can drop them
+			result.Validated = nil
+			result.Metadata.Stage = "duplicate"
+			result.Metadata.Duration = time.Since(startTime)
+			dp.updateMetrics(result)
+			return result, nil
 		} else {
 			result.Validated = deduplicated
 		}
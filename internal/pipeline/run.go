@@ -0,0 +1,156 @@
+// internal/pipeline/run.go
+package pipeline
+
+import (
+	"context"
+	"sync"
+)
+
+// Stage names used by Run and PipelineConfig.StageWorkers
+const (
+	StageExtraction     = "extraction"
+	StageTransformation = "transformation"
+	StageValidation     = "validation"
+	StageEnrichment     = "enrichment"
+	StageDeduplication  = "deduplication"
+	StageOutput         = "output"
+)
+
+// stageFunc processes one record; a nil result drops the record
+type stageFunc func(ctx context.Context, record map[string]interface{}) (map[string]interface{}, error)
+
+// runStage describes one step of the streaming pipeline
+type runStage struct {
+	name    string
+	workers int
+	fatal   bool // a failing record is dropped instead of passed on unchanged
+	fn      stageFunc
+}
+
+// Run streams records through extraction, transformation, validation, enrichment,
+// deduplication and output, skipping components that are not set. Each stage runs
+// its own bounded worker pool (PipelineConfig.StageWorkers, else WorkerCount), so
+// records leave in completion order rather than input order. Deduplication and
+// output hold shared state and always run with a single worker.
+//
+// Records failing extraction, transformation or validation are dropped; enrichment
+// and output failures pass the record on. Failures are reported to the logger.
+// The returned channel is closed once in is closed and drained, or ctx is done.
+func (dp *DataPipeline) Run(ctx context.Context, in <-chan map[string]interface{}) <-chan map[string]interface{} {
+	out := in
+	for _, stage := range dp.runStages() {
+		out = dp.runStage(ctx, stage, out)
+	}
+	return out
+}
+
+// runStages builds the stage list from the configured components
+func (dp *DataPipeline) runStages() []runStage {
+	var stages []runStage
+
+	if dp.Extractor != nil {
+		stages = append(stages, runStage{name: StageExtraction, fatal: true, fn: dp.Extractor.Extract})
+	}
+	if dp.Transformer != nil {
+		stages = append(stages, runStage{name: StageTransformation, fatal: true, fn: dp.Transformer.TransformData})
+	}
+	if dp.Validator != nil {
+		stages = append(stages, runStage{name: StageValidation, fatal: true, fn: dp.Validator.Validate})
+	}
+	if dp.Enricher != nil {
+		stages = append(stages, runStage{name: StageEnrichment, fn: dp.Enricher.Enrich})
+	}
+	if dp.Deduplicator != nil {
+		stages = append(stages, runStage{name: StageDeduplication, workers: 1, fn: dp.Deduplicator.Deduplicate})
+	}
+	if dp.OutputManager != nil {
+		stages = append(stages, runStage{name: StageOutput, workers: 1, fn: func(ctx context.Context, record map[string]interface{}) (map[string]interface{}, error) {
+			return record, dp.OutputManager.Write(ctx, record)
+		}})
+	}
+
+	for i := range stages {
+		if stages[i].workers == 0 {
+			stages[i].workers = dp.stageWorkers(stages[i].name)
+		}
+	}
+
+	return stages
+}
+
+// stageWorkers returns the configured concurrency for a stage
+func (dp *DataPipeline) stageWorkers(name string) int {
+	if workers := dp.Config.StageWorkers[name]; workers > 0 {
+		return workers
+	}
+	if dp.Config.WorkerCount > 0 {
+		return dp.Config.WorkerCount
+	}
+	return 10
+}
+
+// runStage starts the workers for one stage and returns its output channel
+func (dp *DataPipeline) runStage(ctx context.Context, stage runStage, in <-chan map[string]interface{}) <-chan map[string]interface{} {
+	bufferSize := dp.Config.BufferSize
+	if bufferSize < 0 {
+		bufferSize = 0
+	}
+	out := make(chan map[string]interface{}, bufferSize)
+
+	var wg sync.WaitGroup
+	for i := 0; i < stage.workers; i++ {
+		wg.Add(1)
+		go func() {
+			defer wg.Done()
+			for {
+				var record map[string]interface{}
+				var ok bool
+				select {
+				case <-ctx.Done():
+					return
+				case record, ok = <-in:
+					if !ok {
+						return
+					}
+				}
+
+				result, err := dp.callStage(ctx, stage, record)
+				if err != nil {
+					if dp.logger != nil {
+						dp.logger.Warn("pipeline stage failed", "stage", stage.name, "error", err)
+					}
+					if stage.fatal {
+						continue
+					}
+					result = record
+				}
+				if result == nil {
+					continue
+				}
+
+				select {
+				case out <- result:
+				case <-ctx.Done():
+					return
+				}
+			}
+		}()
+	}
+
+	go func() {
+		wg.Wait()
+		close(out)
+	}()
+
+	return out
+}
+
+// callStage runs a stage function under the per-record timeout, if configured
+func (dp *DataPipeline) callStage(ctx context.Context, stage runStage, record map[string]interface{}) (map[string]interface{}, error) {
+	if dp.Config.Timeout > 0 {
+		var cancel context.CancelFunc
+		ctx, cancel = context.WithTimeout(ctx, dp.Config.Timeout)
+		defer cancel()
+	}
+	return stage.fn(ctx, record)
+}
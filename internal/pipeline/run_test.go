@@ -0,0 +1,115 @@
+// internal/pipeline/run_test.go
+package pipeline
+
+import (
+	"context"
+	"sync"
+	"sync/atomic"
+	"testing"
+	"time"
+)
+
+func TestDataPipeline_Run(t *testing.T) {
+	var enriching, maxEnriching int32
+	var written int32
+
+	dp := NewDataPipeline(&PipelineConfig{
+		BufferSize:   4,
+		WorkerCount:  8,
+		Timeout:      time.Second,
+		StageWorkers: map[string]int{StageEnrichment: 2},
+	})
+	dp.SetExtractor(&DataExtractor{})
+	dp.SetValidator(&DataValidator{
+		StrictMode: true,
+		Rules:      []ValidationRule{{Field: "title", Type: "string", Required: true}},
+	})
+	dp.SetEnricher(&DataEnricher{Enrichers: []Enricher{&MockEnricher{
+		name: "tagger",
+		enrichFunc: func(ctx context.Context, data map[string]interface{}) (map[string]interface{}, error) {
+			current := atomic.AddInt32(&enriching, 1)
+			for {
+				seen := atomic.LoadInt32(&maxEnriching)
+				if current <= seen || atomic.CompareAndSwapInt32(&maxEnriching, seen, current) {
+					break
+				}
+			}
+			time.Sleep(5 * time.Millisecond)
+			atomic.AddInt32(&enriching, -1)
+			data["enriched"] = true
+			return data, nil
+		},
+	}}})
+	dp.SetOutputManager(&OutputManager{Outputs: []OutputHandler{&MockOutputHandler{
+		outputType: "mock",
+		writeFunc: func(ctx context.Context, data interface{}) error {
+			atomic.AddInt32(&written, 1)
+			return nil
+		},
+	}}})
+
+	in := make(chan map[string]interface{})
+	go func() {
+		defer close(in)
+		for i := 0; i < 10; i++ {
+			record := map[string]interface{}{"id": i}
+			if i%5 != 0 {
+				record["title"] = "item"
+			}
+			in <- record
+		}
+	}()
+
+	var results []map[string]interface{}
+	for record := range dp.Run(context.Background(), in) {
+		results = append(results, record)
+	}
+
+	// Records 0 and 5 lack the required title and are dropped by validation
+	if len(results) != 8 {
+		t.Fatalf("expected 8 records, got %d", len(results))
+	}
+	for _, record := range results {
+		if record["enriched"] != true {
+			t.Errorf("record %v was not enriched", record["id"])
+		}
+	}
+	if written != 8 {
+		t.Errorf("expected 8 records written to output, got %d", written)
+	}
+	if maxEnriching > 2 {
+		t.Errorf("enrichment exceeded its concurrency bound: %d", maxEnriching)
+	}
+}
+
+func TestDataPipeline_RunCancellation(t *testing.T) {
+	dp := NewDataPipeline(&PipelineConfig{WorkerCount: 2})
+	dp.SetExtractor(&DataExtractor{})
+
+	ctx, cancel := context.WithCancel(context.Background())
+	in := make(chan map[string]interface{})
+	out := dp.Run(ctx, in)
+
+	var wg sync.WaitGroup
+	wg.Add(1)
+	go func() {
+		defer wg.Done()
+		for range out {
+		}
+	}()
+
+	in <- map[string]interface{}{"id": 1}
+	cancel()
+
+	done := make(chan struct{})
+	go func() {
+		wg.Wait()
+		close(done)
+	}()
+
+	select {
+	case <-done:
+	case <-time.After(time.Second):
+		t.Fatal("output channel was not closed after cancellation")
+	}
+}
@@ -0,0 +1,173 @@
+// internal/pipeline/similarity.go
+package pipeline
+
+import (
+	"encoding/json"
+	"fmt"
+	"math"
+	"strings"
+	"unicode"
+)
+
+// Similarity algorithms for the similarity deduplication method
+const (
+	// SimilarityJaccardExact is the share of compared fields whose values are exactly equal
+	SimilarityJaccardExact = "jaccard_exact"
+	// SimilarityTokenCosine averages, per field, the cosine of the values' word counts
+	SimilarityTokenCosine = "token_cosine"
+	// SimilarityLevenshtein averages, per field, one minus the edit distance over the longer length
+	SimilarityLevenshtein = "levenshtein"
+)
+
+// DefaultSimilarityThreshold is the score at which records count as duplicates when Threshold is unset
+const DefaultSimilarityThreshold = 0.9
+
+// ValidSimilarityAlgorithms lists the algorithms SimilarityAlgorithm accepts
+func ValidSimilarityAlgorithms() []string {
+	return []string{SimilarityJaccardExact, SimilarityTokenCosine, SimilarityLevenshtein}
+}
+
+// recordSimilarity scores two records from 0 to 1 over fields, or over the union
+// of their keys when fields is empty. Fields missing from both are skipped.
+func recordSimilarity(algorithm string, a, b map[string]interface{}, fields []string) (float64, error) {
+	if len(fields) == 0 {
+		fields = unionFieldNames(a, b)
+	}
+
+	var fieldScore func(x, y string) float64
+	switch algorithm {
+	case "", SimilarityJaccardExact:
+		fieldScore = func(x, y string) float64 {
+			if x == y {
+				return 1
+			}
+			return 0
+		}
+	case SimilarityTokenCosine:
+		fieldScore = tokenCosine
+	case SimilarityLevenshtein:
+		fieldScore = levenshteinSimilarity
+	default:
+		return 0, fmt.Errorf("unknown similarity algorithm %q (valid: %s)",
+			algorithm, strings.Join(ValidSimilarityAlgorithms(), ", "))
+	}
+
+	total, compared := 0.0, 0
+	for _, field := range fields {
+		x, inA := a[field]
+		y, inB := b[field]
+		if !inA && !inB {
+			continue
+		}
+		compared++
+		if inA && inB {
+			total += fieldScore(similarityText(x), similarityText(y))
+		}
+	}
+	if compared == 0 {
+		return 0, nil
+	}
+	return total / float64(compared), nil
+}
+
+// unionFieldNames returns the keys of a and b, leaving out dry-run annotations
+func unionFieldNames(a, b map[string]interface{}) []string {
+	var fields []string
+	for key := range a {
+		if key != DuplicateField {
+			fields = append(fields, key)
+		}
+	}
+	for key := range b {
+		if _, ok := a[key]; !ok && key != DuplicateField {
+			fields = append(fields, key)
+		}
+	}
+	return fields
+}
+
+// similarityText is the text a value is compared by: strings as they are, other
+// values as JSON
+func similarityText(value interface{}) string {
+	switch v := value.(type) {
+	case nil:
+		return ""
+	case string:
+		return v
+	}
+	encoded, err := json.Marshal(value)
+	if err != nil {
+		return fmt.Sprintf("%v", value)
+	}
+	return string(encoded)
+}
+
+// similarityTokens splits text into lower-case words, ignoring punctuation and spacing
+func similarityTokens(s string) []string {
+	return strings.FieldsFunc(strings.ToLower(s), func(r rune) bool {
+		return !unicode.IsLetter(r) && !unicode.IsDigit(r)
+	})
+}
+
+// tokenCosine is the cosine similarity of the word counts of x and y
+func tokenCosine(x, y string) float64 {
+	xTokens, yTokens := similarityTokens(x), similarityTokens(y)
+	if len(xTokens) == 0 || len(yTokens) == 0 {
+		if len(xTokens) == len(yTokens) {
+			return 1
+		}
+		return 0
+	}
+
+	xCounts := make(map[string]float64, len(xTokens))
+	for _, token := range xTokens {
+		xCounts[token]++
+	}
+	yCounts := make(map[string]float64, len(yTokens))
+	for _, token := range yTokens {
+		yCounts[token]++
+	}
+
+	var dot, xNorm, yNorm float64
+	for token, count := range xCounts {
+		dot += count * yCounts[token]
+		xNorm += count * count
+	}
+	for _, count := range yCounts {
+		yNorm += count * count
+	}
+	return dot / (math.Sqrt(xNorm) * math.Sqrt(yNorm))
+}
+
+// levenshteinSimilarity is 1 minus the edit distance between x and y, after
+// lower-casing and collapsing whitespace, over the length of the longer one
+func levenshteinSimilarity(x, y string) float64 {
+	a := []rune(strings.Join(strings.Fields(strings.ToLower(x)), " "))
+	b := []rune(strings.Join(strings.Fields(strings.ToLower(y)), " "))
+	longest := max(len(a), len(b))
+	if longest == 0 {
+		return 1
+	}
+	return 1 - float64(levenshteinDistance(a, b))/float64(longest)
+}
+
+// levenshteinDistance counts the single-rune edits that turn a into b
+func levenshteinDistance(a, b []rune) int {
+	prev := make([]int, len(b)+1)
+	curr := make([]int, len(b)+1)
+	for j := range prev {
+		prev[j] = j
+	}
+	for i := 1; i <= len(a); i++ {
+		curr[0] = i
+		for j := 1; j <= len(b); j++ {
+			cost := 1
+			if a[i-1] == b[j-1] {
+				cost = 0
+			}
+			curr[j] = min(prev[j]+1, curr[j-1]+1, prev[j-1]+cost)
+		}
+		prev, curr = curr, prev
+	}
+	return prev[len(b)]
+}
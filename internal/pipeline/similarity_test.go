@@ -0,0 +1,166 @@
+// internal/pipeline/similarity_test.go
+package pipeline
+
+import (
+	"context"
+	"math"
+	"testing"
+)
+
+func TestRecordSimilarity(t *testing.T) {
+	tests := []struct {
+		name      string
+		algorithm string
+		a, b      map[string]interface{}
+		fields    []string
+		want      float64
+	}{
+		{
+			name:      "exact values differ on trailing space",
+			algorithm: SimilarityJaccardExact,
+			a:         map[string]interface{}{"title": "iPhone 13", "price": 799},
+			b:         map[string]interface{}{"title": "iPhone 13 ", "price": 799},
+			want:      0.5,
+		},
+		{
+			name: "default algorithm is jaccard_exact",
+			a:    map[string]interface{}{"title": "iPhone 13"},
+			b:    map[string]interface{}{"title": "iphone 13"},
+			want: 0,
+		},
+		{
+			name:      "token cosine ignores case and spacing",
+			algorithm: SimilarityTokenCosine,
+			a:         map[string]interface{}{"title": "iPhone 13"},
+			b:         map[string]interface{}{"title": "  IPHONE   13 "},
+			want:      1,
+		},
+		{
+			name:      "token cosine of partly shared words",
+			algorithm: SimilarityTokenCosine,
+			a:         map[string]interface{}{"title": "apple iphone 13"},
+			b:         map[string]interface{}{"title": "apple iphone 14"},
+			want:      2.0 / 3.0,
+		},
+		{
+			name:      "levenshtein ignores case and spacing",
+			algorithm: SimilarityLevenshtein,
+			a:         map[string]interface{}{"title": "iPhone 13"},
+			b:         map[string]interface{}{"title": "iphone  13 "},
+			want:      1,
+		},
+		{
+			name:      "levenshtein of one edit",
+			algorithm: SimilarityLevenshtein,
+			a:         map[string]interface{}{"title": "iphone 13"},
+			b:         map[string]interface{}{"title": "iphone 14"},
+			want:      1 - 1.0/9.0,
+		},
+		{
+			name:      "only configured fields are compared",
+			algorithm: SimilarityLevenshtein,
+			a:         map[string]interface{}{"title": "Lamp", "url": "/a"},
+			b:         map[string]interface{}{"title": "lamp", "url": "/b"},
+			fields:    []string{"title"},
+			want:      1,
+		},
+		{
+			name:      "field missing from one record scores zero",
+			algorithm: SimilarityTokenCosine,
+			a:         map[string]interface{}{"title": "Lamp", "sku": "L1"},
+			b:         map[string]interface{}{"title": "Lamp"},
+			want:      0.5,
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			got, err := recordSimilarity(tt.algorithm, tt.a, tt.b, tt.fields)
+			if err != nil {
+				t.Fatalf("recordSimilarity() error = %v", err)
+			}
+			if math.Abs(got-tt.want) > 1e-9 {
+				t.Errorf("recordSimilarity() = %v, want %v", got, tt.want)
+			}
+		})
+	}
+
+	if _, err := recordSimilarity("soundex", map[string]interface{}{"a": 1}, map[string]interface{}{"a": 1}, nil); err == nil {
+		t.Error("expected an error for an unknown algorithm")
+	}
+}
+
+func TestSimilarityDeduplicationThresholdSweep(t *testing.T) {
+	ctx := context.Background()
+	titles := []string{
+		"Apple iPhone 13 128GB",
+		"apple iphone 13  128gb", // case and spacing only
+		"Apple iPhone 13 128GB Blue",
+		"Samsung Galaxy S22",
+	}
+
+	// Records kept, out of the four, per algorithm and threshold
+	tests := []struct {
+		algorithm string
+		threshold float64
+		want      int
+	}{
+		{SimilarityJaccardExact, 0.9, 4},
+		{SimilarityTokenCosine, 1.0, 3},
+		{SimilarityTokenCosine, 0.85, 2},
+		{SimilarityTokenCosine, 0.1, 2}, // No words shared with the Samsung title
+		{SimilarityLevenshtein, 1.0, 3},
+		{SimilarityLevenshtein, 0.8, 2},
+		{SimilarityLevenshtein, 0.1, 2},
+	}
+	for _, tt := range tests {
+		deduplicator := &RecordDeduplicator{
+			Method:              "similarity",
+			SimilarityAlgorithm: tt.algorithm,
+			Fields:              []string{"title"},
+			Threshold:           tt.threshold,
+		}
+		kept := 0
+		for _, title := range titles {
+			result, err := deduplicator.Deduplicate(ctx, map[string]interface{}{"title": title})
+			if err != nil {
+				t.Fatalf("%s at %.2f: %v", tt.algorithm, tt.threshold, err)
+			}
+			if result != nil {
+				kept++
+			}
+		}
+		if kept != tt.want {
+			t.Errorf("%s at %.2f kept %d records, want %d", tt.algorithm, tt.threshold, kept, tt.want)
+		}
+	}
+}
+
+func TestSimilarityDeduplicationDryRun(t *testing.T) {
+	ctx := context.Background()
+	deduplicator := &RecordDeduplicator{
+		Method:              "similarity",
+		SimilarityAlgorithm: SimilarityLevenshtein,
+		Fields:              []string{"title"},
+		DryRun:              true,
+	}
+	deduplicator.Deduplicate(ctx, map[string]interface{}{"title": "Desk lamp", "price": 20})
+	result, err := deduplicator.Deduplicate(ctx, map[string]interface{}{"title": "desk lamp ", "price": 22})
+	if err != nil {
+		t.Fatal(err)
+	}
+	if _, ok := result[DuplicateField]; !ok {
+		t.Fatalf("near duplicate should be annotated in dry run, got %v", result)
+	}
+	report := deduplicator.Report()
+	if len(report) != 1 || report[0].Score != 1 || report[0].Matched["title"] != "Desk lamp" {
+		t.Errorf("unexpected report: %+v", report)
+	}
+
+	restarted := &RecordDeduplicator{Method: "similarity", SimilarityAlgorithm: SimilarityLevenshtein, Fields: []string{"title"}}
+	if err := restarted.Restore(deduplicator.State()); err != nil {
+		t.Fatal(err)
+	}
+	if result, _ := restarted.Deduplicate(ctx, map[string]interface{}{"title": "DESK LAMP"}); result != nil {
+		t.Error("records kept before a restart should still match after Restore")
+	}
+}
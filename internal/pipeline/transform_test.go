@@ -4,6 +4,7 @@ package pipeline
 import (
 	"context"
 	"testing"
+	"time"
 )
 
 func TestTransformRule_Transform(t *testing.T) {
@@ -30,6 +31,13 @@ func TestTransformRule_Transform(t *testing.T) {
 			expected:    "hello world test",
 			expectError: false,
 		},
+		{
+			name:        "normalize text",
+			rule:        TransformRule{Type: "normalize_text"},
+			input:       "\u00a0Caf\u0065\u0301\u200bau\u202flait\r\n\n  price ",
+			expected:    "Caf\u00e9 au lait price",
+			expectError: false,
+		},
 		{
 			name:        "lowercase",
 			rule:        TransformRule{Type: "lowercase"},
@@ -241,6 +249,43 @@ func TestValidateTransformRules(t *testing.T) {
 			},
 			expectError: true,
 		},
+		{
+			name: "date with layouts",
+			rules: TransformList{
+				{Type: "date", Format: "DateOnly", Params: map[string]interface{}{"layouts": []interface{}{"Jan 2, 2006"}}},
+			},
+			expectError: false,
+		},
+		{
+			name: "date layouts not strings",
+			rules: TransformList{
+				{Type: "date", Params: map[string]interface{}{"layouts": []interface{}{2006}}},
+			},
+			expectError: true,
+		},
+		{
+			name: "date unknown timezone",
+			rules: TransformList{
+				{Type: "date", Params: map[string]interface{}{"timezone": "Mars/Olympus"}},
+			},
+			expectError: true,
+		},
+		{
+			name: "json_parse last",
+			rules: TransformList{
+				{Type: "trim"},
+				{Type: "json_parse", Params: map[string]interface{}{"path": "$.offers"}},
+			},
+			expectError: false,
+		},
+		{
+			name: "json_parse before another rule",
+			rules: TransformList{
+				{Type: "json_parse"},
+				{Type: "trim"},
+			},
+			expectError: true,
+		},
 	}
 
 	for _, tt := range tests {
@@ -256,3 +301,205 @@ func TestValidateTransformRules(t *testing.T) {
 		})
 	}
 }
+
+func TestConvertCurrency(t *testing.T) {
+	ctx := context.Background()
+	rates := map[string]interface{}{"USD": 1.0, "EUR": 0.5, "GBP": 0.25}
+
+	tests := []struct {
+		name        string
+		params      map[string]interface{}
+		input       string
+		expected    string
+		expectError bool
+	}{
+		{
+			name:     "static rates",
+			params:   map[string]interface{}{"from": "USD", "to": "EUR", "rates": rates},
+			input:    "$1,200.00",
+			expected: "600.00",
+		},
+		{
+			name:     "cross rate",
+			params:   map[string]interface{}{"from": "eur", "to": "gbp", "rates": rates, "decimals": 1},
+			input:    "10",
+			expected: "5.0",
+		},
+		{
+			name:     "missing rate passes through",
+			params:   map[string]interface{}{"from": "USD", "to": "JPY", "rates": rates},
+			input:    "10",
+			expected: "10",
+		},
+		{
+			name:        "missing rate strict",
+			params:      map[string]interface{}{"from": "USD", "to": "JPY", "rates": rates, "strict": true},
+			input:       "10",
+			expectError: true,
+		},
+		{
+			name:     "registered provider",
+			params:   map[string]interface{}{"from": "USD", "to": "CHF", "provider": "test-static"},
+			input:    "4",
+			expected: "3.60",
+		},
+	}
+
+	RegisterRateProvider("test-static", StaticRateProvider{"USD": 1, "CHF": 0.9})
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			rule := TransformRule{Type: "convert_currency", Params: tt.params}
+			result, err := rule.Transform(ctx, tt.input)
+
+			if tt.expectError {
+				if err == nil {
+					t.Errorf("expected error but got none")
+				}
+				return
+			}
+			if err != nil {
+				t.Fatalf("unexpected error: %v", err)
+			}
+			if result != tt.expected {
+				t.Errorf("expected %q, got %q", tt.expected, result)
+			}
+		})
+	}
+}
+
+func TestDateTransform(t *testing.T) {
+	ctx := context.Background()
+	now := time.Date(2024, 3, 10, 15, 30, 0, 0, time.UTC)
+	timeNow = func() time.Time { return now }
+	defer func() { timeNow = time.Now }()
+
+	tests := []struct {
+		name        string
+		format      string
+		params      map[string]interface{}
+		input       string
+		expected    string
+		required    bool // Transformed as a required field
+		expectError bool
+	}{
+		{
+			name:     "ISO-8601 with offset",
+			input:    "2024-02-28T09:15:00+02:00",
+			expected: "2024-02-28T09:15:00+02:00",
+		},
+		{
+			name:     "short month layout",
+			input:    "Jan 2, 2006",
+			expected: "2006-01-02T00:00:00Z",
+		},
+		{
+			name:     "long month layout",
+			input:    " January 15, 2024 ",
+			expected: "2024-01-15T00:00:00Z",
+		},
+		{
+			name:     "first matching configured layout wins",
+			params:   map[string]interface{}{"layouts": []interface{}{"02/01/2006", "01/02/2006"}},
+			input:    "03/04/2024",
+			expected: "2024-04-03T00:00:00Z",
+		},
+		{
+			name:     "named layout and output format",
+			format:   "DateOnly",
+			params:   map[string]interface{}{"layouts": []string{"RFC1123"}},
+			input:    "Mon, 02 Jan 2006 15:04:05 MST",
+			expected: "2006-01-02",
+		},
+		{
+			name:     "custom output format",
+			format:   "2 Jan 2006 15:04",
+			input:    "2024-02-28 09:15:00",
+			expected: "28 Feb 2024 09:15",
+		},
+		{
+			name:     "timezone for inputs without offset",
+			params:   map[string]interface{}{"timezone": "Europe/Kyiv"},
+			input:    "2024-07-01 12:00:00",
+			expected: "2024-07-01T12:00:00+03:00",
+		},
+		{
+			name:     "hours ago",
+			input:    "3 hours ago",
+			expected: "2024-03-10T12:30:00Z",
+		},
+		{
+			name:     "days ago",
+			input:    "2 Days Ago",
+			expected: "2024-03-08T15:30:00Z",
+		},
+		{
+			name:     "a week ago",
+			input:    "a week ago",
+			expected: "2024-03-03T15:30:00Z",
+		},
+		{
+			name:     "in months",
+			input:    "in 2 months",
+			expected: "2024-05-10T15:30:00Z",
+		},
+		{
+			name:     "yesterday",
+			format:   "DateOnly",
+			input:    "Yesterday",
+			expected: "2024-03-09",
+		},
+		{
+			name:     "relative date in timezone",
+			params:   map[string]interface{}{"timezone": "Asia/Tokyo"},
+			input:    "today",
+			expected: "2024-03-11T00:00:00+09:00",
+		},
+		{
+			name:     "unparseable passes through",
+			input:    "sometime last spring",
+			expected: "sometime last spring",
+		},
+		{
+			name:     "both in and ago is not relative",
+			input:    "in 2 days ago",
+			expected: "in 2 days ago",
+		},
+		{
+			name:        "unparseable for a required field",
+			required:    true,
+			input:       "sometime last spring",
+			expectError: true,
+		},
+		{
+			name:     "parseable for a required field",
+			required: true,
+			input:    "Mar 1, 2024",
+			expected: "2024-03-01T00:00:00Z",
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			rule := TransformRule{Type: "date", Format: tt.format, Params: tt.params}
+			ruleCtx := ctx
+			if tt.required {
+				ruleCtx = WithRequiredField(ctx)
+			}
+			result, err := rule.Transform(ruleCtx, tt.input)
+
+			if tt.expectError {
+				if err == nil {
+					t.Errorf("expected error but got none")
+				}
+				return
+			}
+			if err != nil {
+				t.Fatalf("unexpected error: %v", err)
+			}
+			if result != tt.expected {
+				t.Errorf("expected %q, got %q", tt.expected, result)
+			}
+		})
+	}
+}
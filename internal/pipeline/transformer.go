@@ -76,6 +76,9 @@ func ApplyFieldTransforms(ctx context.Context, field TransformField, input inter
 		return input, nil
 	}
 
+	if field.Required {
+		ctx = WithRequiredField(ctx)
+	}
 	result, err := field.Rules.Apply(ctx, inputStr)
 	if err != nil {
 		if field.Required {
@@ -3,14 +3,17 @@ package pipeline
 
 import (
 	"context"
+	"encoding/json"
 	"fmt"
 	"net/url"
 	"regexp"
 	"strconv"
 	"strings"
+	"time"
 
 	"golang.org/x/text/cases"
 	"golang.org/x/text/language"
+	"golang.org/x/text/unicode/norm"
 )
 
 // Pre-compiled regular expressions and text processors for performance
@@ -22,8 +25,18 @@ var (
 	currencyCleanRegex   = regexp.MustCompile(`[^\d.-]`)
 	currencyNumericRegex = regexp.MustCompile(`([+-]?\d{1,}(?:[,\s]\d{3})*(?:\.\d+)?|\d+(?:\.\d+)?)`) // Extract numeric values from currency strings
 	titleCaser           = cases.Title(language.English)                                              // Modern replacement for deprecated strings.Title
+
+	// Invisible spaces that unicode.IsSpace does not cover
+	zeroWidthReplacer = strings.NewReplacer("\u200b", " ", "\u2060", " ", "\ufeff", " ")
 )
 
+// NormalizeText NFC-normalizes s, turns non-breaking and zero-width spaces into
+// regular spaces, and collapses runs of whitespace (including newlines) to one space
+func NormalizeText(s string) string {
+	s = norm.NFC.String(zeroWidthReplacer.Replace(s))
+	return strings.Join(strings.Fields(s), " ")
+}
+
 // TransformRule defines a single transformation rule
 type TransformRule struct {
 	Type        string                 `yaml:"type" json:"type"`
@@ -44,6 +57,21 @@ type TransformField struct {
 	DefaultVal interface{}   `yaml:"default,omitempty" json:"default,omitempty"`
 }
 
+type requiredFieldKey struct{}
+
+// WithRequiredField returns a context for transforming the value of a required
+// field. Transforms that pass an unusable value through unchanged, such as date
+// on an unparseable input, fail under it instead.
+func WithRequiredField(ctx context.Context) context.Context {
+	return context.WithValue(ctx, requiredFieldKey{}, true)
+}
+
+// isRequiredField reports whether ctx was set up by WithRequiredField
+func isRequiredField(ctx context.Context) bool {
+	required, _ := ctx.Value(requiredFieldKey{}).(bool)
+	return required
+}
+
 // Transform applies a single transformation rule
 func (tr *TransformRule) Transform(ctx context.Context, input string) (string, error) {
 	switch tr.Type {
@@ -55,6 +83,8 @@ func (tr *TransformRule) Transform(ctx context.Context, input string) (string, e
 		return strings.ToUpper(input), nil
 	case "normalize_spaces":
 		return spacesRegex.ReplaceAllString(strings.TrimSpace(input), " "), nil
+	case "normalize_text":
+		return NormalizeText(input), nil
 	case "remove_html":
 		return strings.TrimSpace(htmlTagsRegex.ReplaceAllString(input, "")), nil
 	case "regex":
@@ -198,6 +228,24 @@ func (tr *TransformRule) Transform(ctx context.Context, input string) (string, e
 		}
 		return input, nil
 
+	case "convert_currency":
+		return tr.convertCurrency(input)
+
+	case "date":
+		return tr.convertDate(ctx, input)
+
+	case "json_parse":
+		// As text, the parsed value is re-encoded; TransformList.ApplyValue keeps it structured
+		value, err := ParseJSON(input, paramString(tr.Params, "path"))
+		if err != nil {
+			return "", err
+		}
+		encoded, err := json.Marshal(value)
+		if err != nil {
+			return "", fmt.Errorf("failed to encode JSON: %w", err)
+		}
+		return string(encoded), nil
+
 	case "extract_domain":
 		if u, err := url.Parse(input); err == nil && u.Host != "" {
 			return u.Host, nil
@@ -297,7 +345,7 @@ func (tl TransformList) Apply(ctx context.Context, input string) (string, error)
 func ValidateTransformRules(rules TransformList) error {
 	validTypes := map[string]bool{
 		"trim": true, "lowercase": true, "uppercase": true,
-		"normalize_spaces": true, "remove_html": true, "regex": true,
+		"normalize_spaces": true, "normalize_text": true, "remove_html": true, "regex": true,
 		"parse_float": true, "parse_int": true, "extract_numbers": true,
 		"prefix": true, "suffix": true, "replace": true,
 		// Advanced transformations
@@ -305,6 +353,7 @@ func ValidateTransformRules(rules TransformList) error {
 		"reverse": true, "remove_commas": true, "format_currency": true,
 		"extract_domain": true, "extract_filename": true, "capitalize_words": true,
 		"remove_duplicates": true, "pad_left": true, "pad_right": true,
+		"convert_currency": true, "date": true, "json_parse": true,
 	}
 
 	for i, rule := range rules {
@@ -325,6 +374,26 @@ func ValidateTransformRules(rules TransformList) error {
 			if rule.Pattern == "" {
 				return fmt.Errorf("rule %d: pattern is required for transform type %s", i, rule.Type)
 			}
+		case "convert_currency":
+			if rule.Params == nil || rule.Params["from"] == nil || rule.Params["to"] == nil {
+				return fmt.Errorf("rule %d: 'from' and 'to' parameters are required for transform type %s", i, rule.Type)
+			}
+			if rule.Params["rates"] == nil && rule.Params["provider"] == nil {
+				return fmt.Errorf("rule %d: 'rates' or 'provider' parameter is required for transform type %s", i, rule.Type)
+			}
+		case "date":
+			if _, err := dateLayouts(rule.Params); err != nil {
+				return fmt.Errorf("rule %d: %w", i, err)
+			}
+			if name := paramString(rule.Params, "timezone"); name != "" {
+				if _, err := time.LoadLocation(name); err != nil {
+					return fmt.Errorf("rule %d: invalid timezone: %w", i, err)
+				}
+			}
+		case "json_parse":
+			if i != len(rules)-1 {
+				return fmt.Errorf("rule %d: json_parse must be the last rule, as it yields structured data", i)
+			}
 		case "substring", "truncate", "pad_left", "pad_right":
 			if rule.Params == nil {
 				return fmt.Errorf("rule %d: parameters are required for transform type %s", i, rule.Type)
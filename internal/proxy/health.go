@@ -186,6 +186,10 @@ func (pv *ProxyValidator) ValidateConfig(config *ProxyConfig) error {
 		return fmt.Errorf("health check rate cannot be negative")
 	}
 
+	if config.HealthCheckMaxInterval < 0 {
+		return fmt.Errorf("health check max interval cannot be negative")
+	}
+
 	if config.Timeout <= 0 {
 		config.Timeout = 30 * time.Second
 	}
@@ -0,0 +1,74 @@
+// internal/proxy/host_groups.go
+package proxy
+
+import (
+	"fmt"
+	"net/url"
+	"strings"
+)
+
+// matchHostGroup returns the group hostGroups assigns to host. Patterns are exact
+// hosts ("shop.example.com") or wildcards ("*.example.com", matching subdomains
+// only); an exact match wins, then the longest wildcard.
+func matchHostGroup(hostGroups map[string]string, host string) string {
+	host = strings.ToLower(host)
+	if group, ok := hostGroups[host]; ok {
+		return group
+	}
+
+	var best, bestSuffix string
+	for pattern, group := range hostGroups {
+		suffix, ok := strings.CutPrefix(strings.ToLower(pattern), "*")
+		if !ok || !strings.HasPrefix(suffix, ".") || !strings.HasSuffix(host, suffix) {
+			continue
+		}
+		if len(suffix) > len(bestSuffix) || (len(suffix) == len(bestSuffix) && group < best) {
+			best, bestSuffix = group, suffix
+		}
+	}
+	return best
+}
+
+// GroupForURL returns the proxy group host_groups assigns to targetURL's host,
+// or "" when the host is unmapped and the normal rotation applies
+func (pm *ProxyManager) GroupForURL(targetURL string) string {
+	if len(pm.config.HostGroups) == 0 {
+		return ""
+	}
+	u, err := url.Parse(targetURL)
+	if err != nil {
+		return ""
+	}
+	return matchHostGroup(pm.config.HostGroups, u.Hostname())
+}
+
+// GetProxyFromGroup applies the rotation strategy to the proxies of one group.
+// It never falls back to other groups, so a mapped host only uses its own proxies.
+func (pm *ProxyManager) GetProxyFromGroup(group string) (*ProxyInstance, error) {
+	proxy, err := pm.selectProxy(func(p *ProxyInstance) bool {
+		return p.Provider.Group == group
+	})
+	if err != nil {
+		return nil, fmt.Errorf("proxy group %s: %w", group, err)
+	}
+	return proxy, nil
+}
+
+// ValidateHostGroups reports host_groups entries naming a group no enabled provider belongs to
+func ValidateHostGroups(config *ProxyConfig) error {
+	groups := make(map[string]bool)
+	for _, provider := range config.Providers {
+		if provider.Enabled && provider.Group != "" {
+			groups[provider.Group] = true
+		}
+	}
+	for pattern, group := range config.HostGroups {
+		if pattern == "" {
+			return fmt.Errorf("host_groups has an empty host pattern")
+		}
+		if !groups[group] {
+			return fmt.Errorf("host_groups maps %s to group %q, which has no enabled providers", pattern, group)
+		}
+	}
+	return nil
+}
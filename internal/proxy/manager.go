@@ -7,6 +7,7 @@ import (
 	"net/http"
 	"net/url"
 	"sort"
+	"strings"
 	"sync"
 	"time"
 
@@ -20,6 +21,9 @@ const (
 	DefaultHealthCheckURL = "http://httpbin.org/ip"
 )
 
+// DefaultHealthCheckMaxInterval is the longest a failing proxy waits between health checks
+const DefaultHealthCheckMaxInterval = time.Hour
+
 // ProxyManager implements the Manager interface
 type ProxyManager struct {
 	config       *ProxyConfig
@@ -32,6 +36,16 @@ type ProxyManager struct {
 	healthTicker *time.Ticker
 	stopChan     chan struct{}
 	client       *http.Client
+
+	// Worker affinity state: proxy pinned per worker and requests left before rotating
+	affinity   map[int]*workerAffinity
+	affinityMu sync.Mutex
+}
+
+// workerAffinity tracks the proxy pinned to a single worker
+type workerAffinity struct {
+	proxy     *ProxyInstance
+	remaining int
 }
 
 // NewProxyManager creates a new proxy manager
@@ -73,6 +87,7 @@ func NewProxyManager(config *ProxyConfig) *ProxyManager {
 		client:   client,
 		stopChan: make(chan struct{}),
 		rng:      rand.New(rand.NewSource(time.Now().UnixNano())),
+		affinity: make(map[int]*workerAffinity),
 		stats: ManagerStats{
 			ProxyStats: make(map[string]*ProxyInstanceStat),
 		},
@@ -139,8 +154,10 @@ func (pm *ProxyManager) buildProxyURL(provider *ProxyProvider) (*url.URL, error)
 		scheme = "https"
 	case ProxyTypeSOCKS5:
 		scheme = "socks5"
+	case ProxyTypeSOCKS5H:
+		scheme = "socks5h"
 	default:
-		return nil, fmt.Errorf("unsupported proxy type: %s", provider.Type)
+		return nil, fmt.Errorf("unsupported proxy type %q (valid types: http, https, socks5, socks5h)", provider.Type)
 	}
 
 	proxyURL := &url.URL{
@@ -164,6 +181,11 @@ func (pm *ProxyManager) buildProxyURL(provider *ProxyProvider) (*url.URL, error)
 
 // GetProxy returns the next proxy according to rotation strategy
 func (pm *ProxyManager) GetProxy() (*ProxyInstance, error) {
+	return pm.selectProxy(nil)
+}
+
+// selectProxy applies the rotation strategy to the proxies member accepts; nil accepts all
+func (pm *ProxyManager) selectProxy(member func(*ProxyInstance) bool) (*ProxyInstance, error) {
 	if !pm.config.Enabled || len(pm.proxies) == 0 {
 		return nil, nil
 	}
@@ -176,15 +198,17 @@ func (pm *ProxyManager) GetProxy() (*ProxyInstance, error) {
 
 	switch pm.config.Rotation {
 	case RotationRoundRobin:
-		proxy, err = pm.getRoundRobinProxy()
+		proxy, err = pm.getRoundRobinProxy(member)
 	case RotationRandom:
-		proxy, err = pm.getRandomProxy()
+		proxy, err = pm.getRandomProxy(member)
 	case RotationWeighted:
-		proxy, err = pm.getWeightedProxy()
+		proxy, err = pm.getWeightedProxy(member)
 	case RotationHealthy:
-		proxy, err = pm.getHealthyProxy()
+		proxy, err = pm.getHealthyProxy(member)
+	case RotationAdaptiveWeighted:
+		proxy, err = pm.getAdaptiveWeightedProxy(member)
 	default:
-		proxy, err = pm.getRoundRobinProxy()
+		proxy, err = pm.getRoundRobinProxy(member)
 	}
 
 	if err != nil {
@@ -203,8 +227,70 @@ func (pm *ProxyManager) GetProxy() (*ProxyInstance, error) {
 	return proxy, nil
 }
 
+// GetProxyForWorker returns the proxy pinned to workerID when worker affinity is
+// configured. The pinned proxy is reused for AffinityRequests requests, or until
+// it becomes unhealthy, before the worker rotates to the next proxy. That is a
+// different proxy whenever another one is available.
+func (pm *ProxyManager) GetProxyForWorker(workerID int) (*ProxyInstance, error) {
+	if pm.config.Affinity != AffinityWorker {
+		return pm.GetProxy()
+	}
+
+	pm.affinityMu.Lock()
+	defer pm.affinityMu.Unlock()
+
+	var previous *ProxyInstance
+	if entry, ok := pm.affinity[workerID]; ok {
+		previous = entry.proxy
+		if entry.remaining > 0 {
+			entry.proxy.mu.RLock()
+			available := entry.proxy.Status.Available && entry.proxy.Status.FailureCount < pm.config.FailureThreshold
+			entry.proxy.mu.RUnlock()
+
+			if available {
+				entry.remaining--
+				pm.recordProxyUse(entry.proxy)
+				return entry.proxy, nil
+			}
+		}
+	}
+
+	// Rotation shared with other workers could hand back the same proxy
+	proxy, err := pm.selectProxy(func(p *ProxyInstance) bool { return p != previous })
+	if previous != nil && (err != nil || proxy == nil) {
+		proxy, err = pm.GetProxy() // The previous proxy is the only one left
+	}
+	if err != nil || proxy == nil {
+		delete(pm.affinity, workerID)
+		return proxy, err
+	}
+
+	budget := pm.config.AffinityRequests
+	if budget <= 0 {
+		budget = DefaultAffinityRequests
+	}
+	pm.affinity[workerID] = &workerAffinity{proxy: proxy, remaining: budget - 1}
+
+	return proxy, nil
+}
+
+// recordProxyUse updates usage statistics for a reused proxy
+func (pm *ProxyManager) recordProxyUse(proxy *ProxyInstance) {
+	pm.mu.Lock()
+	defer pm.mu.Unlock()
+
+	proxy.mu.Lock()
+	proxy.Status.UseCount++
+	proxy.mu.Unlock()
+	if stat, ok := pm.stats.ProxyStats[proxy.Provider.Name]; ok {
+		stat.UseCount++
+		stat.LastUsed = time.Now()
+	}
+	pm.stats.TotalRequests++
+}
+
 // getRoundRobinProxy returns the next proxy in round-robin order
-func (pm *ProxyManager) getRoundRobinProxy() (*ProxyInstance, error) {
+func (pm *ProxyManager) getRoundRobinProxy(member func(*ProxyInstance) bool) (*ProxyInstance, error) {
 	if len(pm.proxies) == 0 {
 		return nil, fmt.Errorf("no proxies available")
 	}
@@ -214,6 +300,9 @@ func (pm *ProxyManager) getRoundRobinProxy() (*ProxyInstance, error) {
 	for i := 0; i < len(pm.proxies); i++ {
 		index := (startIndex + i) % len(pm.proxies)
 		proxy := pm.proxies[index]
+		if member != nil && !member(proxy) {
+			continue
+		}
 
 		proxy.mu.RLock()
 		available := proxy.Status.Available && proxy.Status.FailureCount < pm.config.FailureThreshold
@@ -229,8 +318,8 @@ func (pm *ProxyManager) getRoundRobinProxy() (*ProxyInstance, error) {
 }
 
 // getRandomProxy returns a random available proxy
-func (pm *ProxyManager) getRandomProxy() (*ProxyInstance, error) {
-	availableProxies := pm.getAvailableProxies()
+func (pm *ProxyManager) getRandomProxy(member func(*ProxyInstance) bool) (*ProxyInstance, error) {
+	availableProxies := pm.getAvailableProxies(member)
 	if len(availableProxies) == 0 {
 		return nil, fmt.Errorf("no healthy proxies available")
 	}
@@ -243,8 +332,8 @@ func (pm *ProxyManager) getRandomProxy() (*ProxyInstance, error) {
 }
 
 // getWeightedProxy returns a proxy based on weighted selection
-func (pm *ProxyManager) getWeightedProxy() (*ProxyInstance, error) {
-	availableProxies := pm.getAvailableProxies()
+func (pm *ProxyManager) getWeightedProxy(member func(*ProxyInstance) bool) (*ProxyInstance, error) {
+	availableProxies := pm.getAvailableProxies(member)
 	if len(availableProxies) == 0 {
 		return nil, fmt.Errorf("no healthy proxies available")
 	}
@@ -284,9 +373,61 @@ func (pm *ProxyManager) getWeightedProxy() (*ProxyInstance, error) {
 	return availableProxies[0], nil
 }
 
+// getAdaptiveWeightedProxy returns a proxy chosen with probability proportional to
+// its weight times its recent success rate, so a degrading proxy gets less traffic
+// before it fails enough to be marked unavailable. A proxy that failed every
+// recent request has weight zero and is skipped; when every proxy is at zero the
+// configured weights are used alone, so requests still go out.
+func (pm *ProxyManager) getAdaptiveWeightedProxy(member func(*ProxyInstance) bool) (*ProxyInstance, error) {
+	availableProxies := pm.getAvailableProxies(member)
+	if len(availableProxies) == 0 {
+		return nil, fmt.Errorf("no healthy proxies available")
+	}
+
+	weights := make([]float64, len(availableProxies))
+	totalWeight := 0.0
+	for i, proxy := range availableProxies {
+		weight := proxy.Provider.Weight
+		if weight <= 0 {
+			weight = 1
+		}
+		proxy.mu.RLock()
+		weights[i] = float64(weight) * proxy.recentSuccessRate()
+		proxy.mu.RUnlock()
+		totalWeight += weights[i]
+	}
+
+	if totalWeight == 0 {
+		return pm.getWeightedProxy(member)
+	}
+
+	pm.rngMu.Lock()
+	random := pm.rng.Float64() * totalWeight
+	pm.rngMu.Unlock()
+
+	currentWeight := 0.0
+	for i, proxy := range availableProxies {
+		if weights[i] == 0 {
+			continue
+		}
+		currentWeight += weights[i]
+		if random < currentWeight {
+			return proxy, nil
+		}
+	}
+
+	// Rounding can leave random just past the last sum
+	for i := len(availableProxies) - 1; i >= 0; i-- {
+		if weights[i] > 0 {
+			return availableProxies[i], nil
+		}
+	}
+	return availableProxies[0], nil
+}
+
 // getHealthyProxy returns the healthiest proxy (lowest response time)
-func (pm *ProxyManager) getHealthyProxy() (*ProxyInstance, error) {
-	availableProxies := pm.getAvailableProxies()
+func (pm *ProxyManager) getHealthyProxy(member func(*ProxyInstance) bool) (*ProxyInstance, error) {
+	availableProxies := pm.getAvailableProxies(member)
 	if len(availableProxies) == 0 {
 		return nil, fmt.Errorf("no healthy proxies available")
 	}
@@ -304,11 +445,14 @@ func (pm *ProxyManager) getHealthyProxy() (*ProxyInstance, error) {
 	return availableProxies[0], nil
 }
 
-// getAvailableProxies returns list of available proxies
-func (pm *ProxyManager) getAvailableProxies() []*ProxyInstance {
+// getAvailableProxies returns the available proxies that member accepts; nil accepts all
+func (pm *ProxyManager) getAvailableProxies(member func(*ProxyInstance) bool) []*ProxyInstance {
 	var available []*ProxyInstance
 
 	for _, proxy := range pm.proxies {
+		if member != nil && !member(proxy) {
+			continue
+		}
 		proxy.mu.RLock()
 		isAvailable := proxy.Status.Available && proxy.Status.FailureCount < pm.config.FailureThreshold
 		lastFailure := proxy.Status.LastFailure
@@ -321,6 +465,7 @@ func (pm *ProxyManager) getAvailableProxies() []*ProxyInstance {
 			if !proxy.Status.Available && time.Since(proxy.Status.LastFailure) > pm.config.RecoveryTime {
 				proxy.Status.Available = true
 				proxy.Status.FailureCount = 0
+				proxy.recent = nil // Recovered proxies start adaptive rotation afresh
 				isAvailable = true
 			}
 			proxy.mu.Unlock()
@@ -343,6 +488,7 @@ func (pm *ProxyManager) ReportSuccess(proxy *ProxyInstance) {
 	proxy.mu.Lock()
 	proxy.Status.LastSuccess = time.Now()
 	proxy.Status.Available = true
+	proxy.recordOutcome(true)
 	proxy.mu.Unlock()
 
 	pm.mu.Lock()
@@ -362,6 +508,7 @@ func (pm *ProxyManager) ReportFailure(proxy *ProxyInstance, err error) {
 	proxy.mu.Lock()
 	proxy.Status.FailureCount++
 	proxy.Status.LastFailure = time.Now()
+	proxy.recordOutcome(false)
 
 	// Mark proxy as unavailable if failure threshold exceeded
 	if proxy.Status.FailureCount >= pm.config.FailureThreshold {
@@ -422,6 +569,73 @@ func (pm *ProxyManager) GetStats() ManagerStats {
 	return pm.stats
 }
 
+// StatusReport returns availability, circuit state, latency and usage for every
+// configured proxy, in configuration order
+func (pm *ProxyManager) StatusReport() []ProxyStatusReport {
+	pm.mu.RLock()
+	defer pm.mu.RUnlock()
+
+	reports := make([]ProxyStatusReport, 0, len(pm.proxies))
+	for _, proxy := range pm.proxies {
+		proxy.mu.RLock()
+		report := ProxyStatusReport{
+			Name:         proxy.Provider.Name,
+			URL:          redactProxyURL(proxy.URL),
+			Type:         proxy.Provider.Type,
+			Weight:       proxy.Provider.Weight,
+			Available:    proxy.Status.Available,
+			FailureCount: proxy.Status.FailureCount,
+			ResponseTime: proxy.Status.ResponseTime,
+			UseCount:     proxy.Status.UseCount,
+			LastSuccess:  proxy.Status.LastSuccess,
+			LastFailure:  proxy.Status.LastFailure,
+			LastChecked:  proxy.Status.LastChecked,
+			NextCheck:    proxy.Status.NextCheck,
+		}
+		proxy.mu.RUnlock()
+
+		switch {
+		case report.Available && report.FailureCount < pm.config.FailureThreshold:
+			report.CircuitState = CircuitStateClosed
+		case time.Since(report.LastFailure) > pm.config.RecoveryTime:
+			report.CircuitState = CircuitStateHalfOpen
+		default:
+			report.CircuitState = CircuitStateOpen
+		}
+
+		if stat, exists := pm.stats.ProxyStats[proxy.Provider.Name]; exists {
+			report.SuccessCount = stat.SuccessCount
+			report.TotalFailure = stat.FailureCount
+			report.SuccessRate = stat.SuccessRate
+			report.LastUsed = stat.LastUsed
+		}
+
+		reports = append(reports, report)
+	}
+
+	return reports
+}
+
+// Rotation returns the active rotation strategy and proxy affinity mode
+func (pm *ProxyManager) Rotation() (RotationStrategy, AffinityMode) {
+	return pm.config.Rotation, pm.config.Affinity
+}
+
+// RedactedURL returns the proxy URL without credentials, safe for logs and output
+func (p *ProxyInstance) RedactedURL() string {
+	return redactProxyURL(p.URL)
+}
+
+// redactProxyURL returns the proxy URL without credentials
+func redactProxyURL(u *url.URL) string {
+	if u == nil {
+		return ""
+	}
+	redacted := *u
+	redacted.User = nil
+	return redacted.String()
+}
+
 // IsEnabled returns whether proxy rotation is enabled
 func (pm *ProxyManager) IsEnabled() bool {
 	if !pm.config.Enabled {
@@ -447,12 +661,18 @@ func (pm *ProxyManager) IsEnabled() bool {
 func (pm *ProxyManager) GetHealthyProxies() []*ProxyInstance {
 	pm.mu.RLock()
 	defer pm.mu.RUnlock()
-	return pm.getAvailableProxies()
+	return pm.getAvailableProxies(nil)
 }
 
-// Start starts the proxy manager
+// Start checks every proxy once when health checks are enabled, so proxies that
+// are down are out of the pool before the first request, then keeps checking
+// them every HealthCheckRate
 func (pm *ProxyManager) Start() error {
-	if pm.config.HealthCheck && pm.config.HealthCheckRate > 0 {
+	if !pm.config.HealthCheck {
+		return nil
+	}
+	pm.runHealthCheck(true)
+	if pm.config.HealthCheckRate > 0 {
 		pm.healthTicker = time.NewTicker(pm.config.HealthCheckRate)
 		go pm.healthCheckLoop()
 	}
@@ -480,14 +700,30 @@ func (pm *ProxyManager) healthCheckLoop() {
 	}
 }
 
-// HealthCheck performs health checks on all proxies
+// healthCheckSummary is the outcome of one health check round
+type healthCheckSummary struct {
+	Checked int      // Proxies probed; those backing off are skipped
+	Healthy int      // Proxies that answered 200
+	Failed  []string // Names of the proxies that failed
+}
+
+// HealthCheck checks every proxy that is due. A proxy failing consecutive checks
+// backs off exponentially, see healthCheckInterval; skipped proxies keep their state.
 func (pm *ProxyManager) HealthCheck() error {
 	if !pm.config.HealthCheck {
 		return nil
 	}
+	pm.runHealthCheck(false)
+	return nil
+}
 
+// runHealthCheck runs one round and logs its summary. A proxy failing a check
+// becomes unavailable after FailureThreshold failures, or at once on the
+// initial round, since nothing is known to work through it yet.
+func (pm *ProxyManager) runHealthCheck(initial bool) healthCheckSummary {
+	now := time.Now()
 	pm.mu.Lock()
-	pm.stats.LastHealthCheck = time.Now()
+	pm.stats.LastHealthCheck = now
 	pm.mu.Unlock()
 
 	checkURL := pm.config.HealthCheckURL
@@ -495,8 +731,16 @@ func (pm *ProxyManager) HealthCheck() error {
 		checkURL = DefaultHealthCheckURL
 	}
 
-	var wg sync.WaitGroup
+	var (
+		wg        sync.WaitGroup
+		summaryMu sync.Mutex
+		summary   healthCheckSummary
+	)
 	for _, proxy := range pm.proxies {
+		if !pm.healthCheckDue(proxy, now) {
+			continue
+		}
+		summary.Checked++
 		wg.Add(1)
 		go func(p *ProxyInstance) {
 			defer wg.Done()
@@ -511,13 +755,17 @@ func (pm *ProxyManager) HealthCheck() error {
 
 			if err != nil {
 				p.Status.FailureCount++
-				if p.Status.FailureCount >= pm.config.FailureThreshold {
+				p.checkFailures++
+				if initial || p.Status.FailureCount >= pm.config.FailureThreshold {
 					p.Status.Available = false
 				}
 			} else {
 				p.Status.Available = true
 				p.Status.FailureCount = 0
+				p.checkFailures = 0
 			}
+			// Scheduled from the round's start so the next tick finds the proxy due
+			p.Status.NextCheck = now.Add(pm.healthCheckInterval(p.checkFailures))
 			p.mu.Unlock()
 
 			pm.mu.Lock()
@@ -526,11 +774,69 @@ func (pm *ProxyManager) HealthCheck() error {
 				stat.Healthy = p.Status.Available
 			}
 			pm.mu.Unlock()
+
+			summaryMu.Lock()
+			if err != nil {
+				summary.Failed = append(summary.Failed, p.Provider.Name)
+				managerLogger.WithField("proxy", p.Provider.Name).Debugf("Proxy %s failed health check: %v", p.RedactedURL(), err)
+			} else {
+				summary.Healthy++
+			}
+			summaryMu.Unlock()
 		}(proxy)
 	}
 
 	wg.Wait()
-	return nil
+	sort.Strings(summary.Failed)
+	pm.logHealthCheck(summary)
+	return summary
+}
+
+// logHealthCheck logs a round's summary, as a warning when a proxy failed
+func (pm *ProxyManager) logHealthCheck(summary healthCheckSummary) {
+	if summary.Checked == 0 {
+		return
+	}
+	logger := managerLogger.WithFields(map[string]interface{}{
+		"checked": summary.Checked,
+		"healthy": summary.Healthy,
+		"failed":  summary.Failed,
+	})
+	if len(summary.Failed) == 0 {
+		logger.Infof("Proxy health check: all %d proxies healthy", summary.Checked)
+		return
+	}
+	logger.Warnf("Proxy health check: %d of %d proxies healthy; failed: %s",
+		summary.Healthy, summary.Checked, strings.Join(summary.Failed, ", "))
+}
+
+// healthCheckDue reports whether proxy should be checked in the round starting at
+// now. Half a round of slack keeps a proxy whose round ran late from slipping a tick.
+func (pm *ProxyManager) healthCheckDue(proxy *ProxyInstance, now time.Time) bool {
+	proxy.mu.RLock()
+	defer proxy.mu.RUnlock()
+	return !proxy.Status.NextCheck.After(now.Add(pm.config.HealthCheckRate / 2))
+}
+
+// healthCheckInterval is HealthCheckRate doubled once per consecutive failed
+// check, capped at HealthCheckMaxInterval
+func (pm *ProxyManager) healthCheckInterval(failures int) time.Duration {
+	maxInterval := pm.config.HealthCheckMaxInterval
+	if maxInterval <= 0 {
+		maxInterval = DefaultHealthCheckMaxInterval
+	}
+	if maxInterval < pm.config.HealthCheckRate {
+		maxInterval = pm.config.HealthCheckRate
+	}
+
+	interval := pm.config.HealthCheckRate
+	for i := 0; i < failures && interval < maxInterval; i++ {
+		interval *= 2
+	}
+	if interval > maxInterval {
+		interval = maxInterval
+	}
+	return interval
 }
 
 // checkProxyHealth checks if a single proxy is healthy
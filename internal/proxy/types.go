@@ -13,7 +13,10 @@ type ProxyType string
 const (
 	ProxyTypeHTTP   ProxyType = "http"
 	ProxyTypeHTTPS  ProxyType = "https"
-	ProxyTypeSOCKS5 ProxyType = "socks5"
+	ProxyTypeSOCKS5 ProxyType = "socks5" // Target names resolved locally
+
+	// ProxyTypeSOCKS5H is SOCKS5 with target names resolved by the proxy
+	ProxyTypeSOCKS5H ProxyType = "socks5h"
 )
 
 // RotationStrategy defines how proxies are rotated
@@ -24,8 +27,26 @@ const (
 	RotationRandom     RotationStrategy = "random"
 	RotationWeighted   RotationStrategy = "weighted"
 	RotationHealthy    RotationStrategy = "healthy"
+
+	// RotationAdaptiveWeighted scales each proxy's weight by its recent success rate
+	RotationAdaptiveWeighted RotationStrategy = "adaptive_weighted"
 )
 
+// AdaptiveWindow is the number of recent requests per proxy that adaptive_weighted
+// rotation takes the success rate over
+const AdaptiveWindow = 20
+
+// AffinityMode defines how proxy selection is pinned between requests
+type AffinityMode string
+
+const (
+	AffinityNone   AffinityMode = ""       // Rotate on every request
+	AffinityWorker AffinityMode = "worker" // Keep a proxy per worker for AffinityRequests requests
+)
+
+// DefaultAffinityRequests is the number of requests a worker keeps its proxy for
+const DefaultAffinityRequests = 10
+
 // ProxyConfig defines proxy configuration
 type ProxyConfig struct {
 	Enabled          bool             `yaml:"enabled" json:"enabled"`
@@ -41,6 +62,18 @@ type ProxyConfig struct {
 	FailureThreshold int              `yaml:"failure_threshold" json:"failure_threshold"`
 	RecoveryTime     time.Duration    `yaml:"recovery_time" json:"recovery_time"`
 	TLS              *TLSConfig       `yaml:"tls,omitempty" json:"tls,omitempty"`
+	Affinity         AffinityMode     `yaml:"proxy_affinity,omitempty" json:"proxy_affinity,omitempty"`
+	AffinityRequests int              `yaml:"affinity_requests,omitempty" json:"affinity_requests,omitempty"`
+
+	// HostGroups routes target hosts (exact or "*.example.com") to the providers
+	// of one group; unmapped hosts use the normal rotation over all providers
+	HostGroups map[string]string `yaml:"host_groups,omitempty" json:"host_groups,omitempty"`
+
+	// HealthCheckMaxInterval caps the health check backoff: each consecutive failed
+	// check doubles a proxy's interval from HealthCheckRate up to this, and a passed
+	// check resets it. Zero means DefaultHealthCheckMaxInterval; set it to
+	// HealthCheckRate to check every proxy on every round.
+	HealthCheckMaxInterval time.Duration `yaml:"health_check_max_interval,omitempty" json:"health_check_max_interval,omitempty"`
 }
 
 // TLSConfig defines TLS/SSL configuration for proxy connections
@@ -79,6 +112,7 @@ type ProxyProvider struct {
 	Password  string    `yaml:"password,omitempty" json:"password,omitempty"`
 	Weight    int       `yaml:"weight,omitempty" json:"weight,omitempty"`
 	Enabled   bool      `yaml:"enabled" json:"enabled"`
+	Group     string    `yaml:"group,omitempty" json:"group,omitempty"` // Named set used by ProxyConfig.HostGroups
 	Whitelist []string  `yaml:"whitelist,omitempty" json:"whitelist,omitempty"`
 	Blacklist []string  `yaml:"blacklist,omitempty" json:"blacklist,omitempty"`
 }
@@ -98,6 +132,7 @@ type ProxyStatus struct {
 	LastFailure  time.Time     `json:"last_failure,omitempty"`
 	LastSuccess  time.Time     `json:"last_success,omitempty"`
 	UseCount     int64         `json:"use_count"`
+	NextCheck    time.Time     `json:"next_check,omitempty"` // When the health checker probes this proxy again
 }
 
 // ProxyInstance represents a runtime proxy instance
@@ -106,6 +141,32 @@ type ProxyInstance struct {
 	URL      *url.URL      `json:"url"`
 	Status   ProxyStatus   `json:"status"`
 	mu       sync.RWMutex  `json:"-"`
+
+	checkFailures int    // Consecutive failed health checks, driving the backoff
+	recent        []bool // Outcomes of the last AdaptiveWindow reported requests, oldest first
+}
+
+// recordOutcome adds a reported request to the proxy's recent window; the caller holds p.mu
+func (p *ProxyInstance) recordOutcome(success bool) {
+	p.recent = append(p.recent, success)
+	if len(p.recent) > AdaptiveWindow {
+		p.recent = p.recent[len(p.recent)-AdaptiveWindow:]
+	}
+}
+
+// recentSuccessRate is the share of successes in the recent window, 1 before any
+// request was reported; the caller holds p.mu
+func (p *ProxyInstance) recentSuccessRate() float64 {
+	if len(p.recent) == 0 {
+		return 1
+	}
+	successes := 0
+	for _, ok := range p.recent {
+		if ok {
+			successes++
+		}
+	}
+	return float64(successes) / float64(len(p.recent))
 }
 
 // Manager defines the proxy management interface
@@ -113,6 +174,16 @@ type Manager interface {
 	// GetProxy returns the next proxy according to rotation strategy
 	GetProxy() (*ProxyInstance, error)
 
+	// GetProxyForWorker returns the proxy pinned to a worker when worker affinity
+	// is configured, rotating only after the affinity request budget is spent
+	GetProxyForWorker(workerID int) (*ProxyInstance, error)
+
+	// GroupForURL returns the host_groups group for a target URL, or "" when unmapped
+	GroupForURL(targetURL string) string
+
+	// GetProxyFromGroup returns the next proxy from one provider group
+	GetProxyFromGroup(group string) (*ProxyInstance, error)
+
 	// ReportSuccess reports successful usage of a proxy
 	ReportSuccess(proxy *ProxyInstance)
 
@@ -122,6 +193,9 @@ type Manager interface {
 	// GetStats returns proxy usage statistics
 	GetStats() ManagerStats
 
+	// StatusReport returns a consolidated status view of every proxy
+	StatusReport() []ProxyStatusReport
+
 	// HealthCheck performs health checks on all proxies
 	HealthCheck() error
 
@@ -166,6 +240,34 @@ type ProxyInstanceStat struct {
 	LastUsed     time.Time     `json:"last_used"`
 }
 
+// Proxy circuit states reported by StatusReport
+const (
+	CircuitStateClosed   = "closed"    // Proxy is in rotation
+	CircuitStateOpen     = "open"      // Proxy exceeded the failure threshold and is cooling down
+	CircuitStateHalfOpen = "half_open" // Recovery time elapsed; proxy will be retried on next selection
+)
+
+// ProxyStatusReport is a consolidated per-proxy view for operators
+type ProxyStatusReport struct {
+	Name         string        `json:"name"`
+	URL          string        `json:"url"`
+	Type         ProxyType     `json:"type"`
+	Weight       int           `json:"weight"`
+	Available    bool          `json:"available"`
+	CircuitState string        `json:"circuit_state"`
+	FailureCount int           `json:"failure_count"`
+	ResponseTime time.Duration `json:"response_time"`
+	UseCount     int64         `json:"use_count"`
+	SuccessCount int64         `json:"success_count"`
+	TotalFailure int64         `json:"total_failures"`
+	SuccessRate  float64       `json:"success_rate"`
+	LastUsed     time.Time     `json:"last_used,omitempty"`
+	LastSuccess  time.Time     `json:"last_success,omitempty"`
+	LastFailure  time.Time     `json:"last_failure,omitempty"`
+	LastChecked  time.Time     `json:"last_checked,omitempty"`
+	NextCheck    time.Time     `json:"next_check,omitempty"`
+}
+
 // HealthChecker defines interface for proxy health checking
 type HealthChecker interface {
 	Check(proxy *ProxyInstance) error
@@ -0,0 +1,65 @@
+// internal/scraper/body_match.go
+package scraper
+
+import (
+	"fmt"
+	"regexp"
+
+	"github.com/valpere/DataScrapexter/internal/errors"
+)
+
+// bodyMatchers turn successful responses into failures by their body, for servers
+// whose status code does not say whether the page really worked. A nil matcher
+// accepts every body.
+type bodyMatchers struct {
+	retry   []*regexp.Regexp // retry_on_body_match: transient pages, fetched again
+	noRetry []*regexp.Regexp // no_retry_on_body_match: permanent pages, failed at once
+}
+
+// newBodyMatchers compiles the configured patterns, or returns nil when there are none
+func newBodyMatchers(retry, noRetry []string) (*bodyMatchers, error) {
+	if len(retry) == 0 && len(noRetry) == 0 {
+		return nil, nil
+	}
+	m := &bodyMatchers{}
+	var err error
+	if m.retry, err = compileBodyPatterns("retry_on_body_match", retry); err != nil {
+		return nil, err
+	}
+	if m.noRetry, err = compileBodyPatterns("no_retry_on_body_match", noRetry); err != nil {
+		return nil, err
+	}
+	return m, nil
+}
+
+func compileBodyPatterns(option string, patterns []string) ([]*regexp.Regexp, error) {
+	compiled := make([]*regexp.Regexp, 0, len(patterns))
+	for i, pattern := range patterns {
+		re, err := regexp.Compile(pattern)
+		if err != nil {
+			return nil, fmt.Errorf("%s[%d]: invalid pattern %q: %w", option, i, pattern, err)
+		}
+		compiled = append(compiled, re)
+	}
+	return compiled, nil
+}
+
+// check returns a Permanent error when body matches a no-retry pattern, a
+// Retryable one when it matches a retry pattern, and nil otherwise. No-retry
+// patterns are checked first, so a page matching both is not fetched again.
+func (m *bodyMatchers) check(body []byte) error {
+	if m == nil {
+		return nil
+	}
+	for _, re := range m.noRetry {
+		if re.Match(body) {
+			return errors.Permanent(fmt.Errorf("response body matches no_retry_on_body_match %q", re.String()))
+		}
+	}
+	for _, re := range m.retry {
+		if re.Match(body) {
+			return errors.Retryable(fmt.Errorf("response body matches retry_on_body_match %q", re.String()))
+		}
+	}
+	return nil
+}
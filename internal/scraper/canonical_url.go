@@ -0,0 +1,100 @@
+// internal/scraper/canonical_url.go
+package scraper
+
+import (
+	"net/url"
+
+	"github.com/PuerkitoBio/goquery"
+	"github.com/valpere/DataScrapexter/internal/utils"
+)
+
+// CanonicalURLConfig controls the canonical form pages are keyed by, so URLs that
+// differ only in tracking parameters, case, trailing slash or fragment count as
+// one page. Canonical URLs are lowercased in scheme and host, drop default ports,
+// the fragment, a trailing slash and StripParams, and sort the remaining query.
+type CanonicalURLConfig struct {
+	// StripParams are removed from the query; a trailing "*" matches a prefix
+	// (e.g. "utm_*"). Empty uses utils.DefaultStripParams.
+	StripParams []string `yaml:"strip_params,omitempty" json:"strip_params,omitempty"`
+
+	// IgnoreLinkCanonical keys pages by their own URL even when they declare
+	// a <link rel="canonical">
+	IgnoreLinkCanonical bool `yaml:"ignore_link_canonical,omitempty" json:"ignore_link_canonical,omitempty"`
+}
+
+func (c *CanonicalURLConfig) stripParams() []string {
+	if c == nil || len(c.StripParams) == 0 {
+		return utils.DefaultStripParams
+	}
+	return c.StripParams
+}
+
+// canonicalURL returns the canonical form of rawURL
+func (c *CanonicalURLConfig) canonicalURL(rawURL string) string {
+	return utils.CanonicalizeURL(rawURL, c.stripParams())
+}
+
+// pageURL returns the canonical URL of a fetched page: its <link rel="canonical">
+// resolved against pageURL when present and allowed, otherwise pageURL itself
+func (c *CanonicalURLConfig) pageURL(pageURL string, doc *goquery.Document) string {
+	if c != nil && !c.IgnoreLinkCanonical && doc != nil {
+		if href, ok := doc.Find(`link[rel="canonical"][href]`).First().Attr("href"); ok {
+			if linked := resolveCanonicalLink(pageURL, href); linked != "" {
+				return c.canonicalURL(linked)
+			}
+		}
+	}
+	return c.canonicalURL(pageURL)
+}
+
+// resolveCanonicalLink resolves href against pageURL, or returns "" unless the result is http(s)
+func resolveCanonicalLink(pageURL, href string) string {
+	base, err := url.Parse(pageURL)
+	if err != nil {
+		return ""
+	}
+	ref, err := url.Parse(href)
+	if err != nil {
+		return ""
+	}
+	resolved := base.ResolveReference(ref)
+	if (resolved.Scheme != "http" && resolved.Scheme != "https") || resolved.Host == "" {
+		return ""
+	}
+	return resolved.String()
+}
+
+// visitedURLs is the visited set of a crawl loop, keyed by canonical URL when
+// canonical_url is configured and by the exact URL otherwise
+type visitedURLs struct {
+	canonical *CanonicalURLConfig
+	seen      map[string]bool
+}
+
+func newVisitedURLs(canonical *CanonicalURLConfig) *visitedURLs {
+	return &visitedURLs{canonical: canonical, seen: make(map[string]bool)}
+}
+
+func (v *visitedURLs) key(rawURL string) string {
+	if v.canonical == nil {
+		return rawURL
+	}
+	return v.canonical.canonicalURL(rawURL)
+}
+
+// visit marks rawURL as visited, reporting false if it already was
+func (v *visitedURLs) visit(rawURL string) bool {
+	key := v.key(rawURL)
+	if v.seen[key] {
+		return false
+	}
+	v.seen[key] = true
+	return true
+}
+
+// mark records another URL a visited page is known by, such as its rel=canonical
+func (v *visitedURLs) mark(rawURL string) {
+	if rawURL != "" {
+		v.seen[v.key(rawURL)] = true
+	}
+}
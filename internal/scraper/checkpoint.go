@@ -0,0 +1,261 @@
+// internal/scraper/checkpoint.go
+package scraper
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+	"os"
+	"path/filepath"
+	"sync"
+	"time"
+
+	"github.com/valpere/DataScrapexter/internal/pipeline"
+)
+
+// DefaultCheckpointInterval is the least time between two checkpoint writes; a
+// crash loses at most the pages finished since the last one
+const DefaultCheckpointInterval = 5 * time.Second
+
+// Checkpoint is the saved progress of a multi-URL run: which URLs finished, the
+// records they produced, what the run's deduplicator had seen, and how far
+// pagination got through the URLs still being scraped. A run that opens an
+// existing checkpoint skips the finished URLs, starts from their records and
+// picks up each paginated URL after its last saved page, so a crash costs only
+// the pages scraped since the last write. URLs that failed are not recorded and
+// are tried again on resume. It is safe for concurrent use.
+type Checkpoint struct {
+	Done       []string                       `json:"done"`                 // URLs scraped successfully, in run order
+	Records    []map[string]interface{}       `json:"records"`              // Records of the Done URLs
+	Dedup      *pipeline.DeduplicatorState    `json:"dedup,omitempty"`      // What the deduplicator had seen of Records
+	Pagination map[string]*PaginationProgress `json:"pagination,omitempty"` // Progress through the pages of unfinished URLs
+	SavedAt    time.Time                      `json:"saved_at"`
+
+	// Interval is the least time between writes; zero writes after every URL
+	// and every page
+	Interval time.Duration `json:"-"`
+
+	mu       sync.Mutex
+	path     string
+	done     map[string]bool
+	dedup    *pipeline.RecordDeduplicator
+	lastSave time.Time
+}
+
+// PaginationProgress is how far ScrapeWithPagination got through the pages of
+// one URL. Pages hold the ScrapingResult of each page as JSON, encoded when the
+// page was scraped, so saving never reads a record that is being changed.
+type PaginationProgress struct {
+	Pages   []json.RawMessage `json:"pages"`             // Pages scraped so far
+	Errors  []string          `json:"errors,omitempty"`  // Pages that failed so far
+	PageNum int               `json:"page_num"`          // Pages attempted, failed ones included
+	URL     string            `json:"url"`               // Last page attempted; pagination goes on from its next link
+	Referer string            `json:"referer,omitempty"` // The page that linked to URL
+}
+
+// OpenCheckpoint returns the checkpoint at path. With resume it carries on from
+// the saved progress, if any; otherwise an existing file is discarded.
+func OpenCheckpoint(path string, resume bool) (*Checkpoint, error) {
+	cp := &Checkpoint{
+		Interval: DefaultCheckpointInterval,
+		path:     path,
+		done:     make(map[string]bool),
+		lastSave: time.Now(),
+	}
+	if !resume {
+		if err := cp.Remove(); err != nil {
+			return nil, err
+		}
+		return cp, nil
+	}
+
+	data, err := os.ReadFile(path)
+	if os.IsNotExist(err) {
+		return cp, nil
+	}
+	if err != nil {
+		return nil, fmt.Errorf("failed to read checkpoint: %w", err)
+	}
+	if err := json.Unmarshal(data, cp); err != nil {
+		return nil, fmt.Errorf("checkpoint %s is corrupt (remove it or run with --no-resume): %w", path, err)
+	}
+	for _, url := range cp.Done {
+		cp.done[url] = true
+	}
+	return cp, nil
+}
+
+// Resumed reports whether the checkpoint carries progress from an earlier run
+func (c *Checkpoint) Resumed() bool {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+	return len(c.Done) > 0 || len(c.Pagination) > 0
+}
+
+// TrackDeduplicator makes CompleteAll pass records through rd and saves rd's
+// state with the checkpoint, first restoring what it had seen when the
+// checkpoint was last written
+func (c *Checkpoint) TrackDeduplicator(rd *pipeline.RecordDeduplicator) error {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+	if c.Dedup != nil {
+		if err := rd.Restore(*c.Dedup); err != nil {
+			return fmt.Errorf("failed to restore checkpoint (run with --no-resume to start over): %w", err)
+		}
+	}
+	c.dedup = rd
+	return nil
+}
+
+// IsDone reports whether url finished in this run or the one being resumed
+func (c *Checkpoint) IsDone(url string) bool {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+	return c.done[url]
+}
+
+// CompleteAll records that url finished with records, all of them at once so a
+// write never holds a URL as done with only some of its pages, and writes the
+// checkpoint when Interval has passed since the last write. With a tracked
+// deduplicator the records are deduplicated first; it returns the ones kept.
+func (c *Checkpoint) CompleteAll(url string, records []map[string]interface{}) ([]map[string]interface{}, error) {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+
+	// Deduplicating under the lock keeps the saved state in step with Records
+	if c.dedup != nil {
+		records = c.dedup.DeduplicateAll(context.Background(), records)
+	}
+	if !c.done[url] {
+		c.done[url] = true
+		c.Done = append(c.Done, url)
+	}
+	c.Records = append(c.Records, records...)
+	delete(c.Pagination, url)
+	if time.Since(c.lastSave) < c.Interval {
+		return records, nil
+	}
+	return records, c.save()
+}
+
+// progress returns a copy of the saved progress through url's pages, or nil
+// when there is none
+func (c *Checkpoint) progress(url string) *PaginationProgress {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+	saved := c.Pagination[url]
+	if saved == nil {
+		return nil
+	}
+	progress := *saved
+	progress.Pages = append([]json.RawMessage(nil), saved.Pages...)
+	progress.Errors = append([]string(nil), saved.Errors...)
+	return &progress
+}
+
+// recordPage adds one page of url's pagination to its progress: page is the
+// result, or nil when the page failed with errMsg. pageNum, pageURL and referer
+// are where pagination stands after it. The checkpoint is written when
+// Interval has passed since the last write.
+func (c *Checkpoint) recordPage(url string, page *ScrapingResult, errMsg string, pageNum int, pageURL, referer string) error {
+	var encoded []byte
+	if page != nil {
+		var err error
+		if encoded, err = json.Marshal(page); err != nil {
+			return fmt.Errorf("failed to encode page for the checkpoint: %w", err)
+		}
+	}
+
+	c.mu.Lock()
+	defer c.mu.Unlock()
+	if c.Pagination == nil {
+		c.Pagination = make(map[string]*PaginationProgress)
+	}
+	progress := c.Pagination[url]
+	if progress == nil {
+		progress = &PaginationProgress{}
+		c.Pagination[url] = progress
+	}
+	if page != nil {
+		progress.Pages = append(progress.Pages, encoded)
+	} else {
+		progress.Errors = append(progress.Errors, errMsg)
+	}
+	progress.PageNum = pageNum
+	progress.URL = pageURL
+	progress.Referer = referer
+	if time.Since(c.lastSave) < c.Interval {
+		return nil
+	}
+	return c.save()
+}
+
+// Results decodes the pages scraped so far
+func (p *PaginationProgress) Results() ([]ScrapingResult, error) {
+	results := make([]ScrapingResult, len(p.Pages))
+	for i, page := range p.Pages {
+		if err := json.Unmarshal(page, &results[i]); err != nil {
+			return nil, fmt.Errorf("checkpoint page %d of %s is corrupt: %w", i+1, p.URL, err)
+		}
+	}
+	return results, nil
+}
+
+// Save writes the checkpoint. The file is replaced by rename, so a crash during
+// the write leaves the previous checkpoint intact.
+func (c *Checkpoint) Save() error {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+	return c.save()
+}
+
+func (c *Checkpoint) save() error {
+	c.SavedAt = time.Now()
+	if c.dedup != nil {
+		state := c.dedup.State()
+		c.Dedup = &state
+	}
+	data, err := json.Marshal(c)
+	if err != nil {
+		return fmt.Errorf("failed to encode checkpoint: %w", err)
+	}
+
+	if dir := filepath.Dir(c.path); dir != "." {
+		if err := os.MkdirAll(dir, 0755); err != nil {
+			return fmt.Errorf("failed to create checkpoint directory: %w", err)
+		}
+	}
+	tmp := c.path + ".tmp"
+	if err := os.WriteFile(tmp, data, 0644); err != nil {
+		return fmt.Errorf("failed to write checkpoint: %w", err)
+	}
+	if err := os.Rename(tmp, c.path); err != nil {
+		os.Remove(tmp)
+		return fmt.Errorf("failed to write checkpoint: %w", err)
+	}
+	c.lastSave = c.SavedAt
+	return nil
+}
+
+// Remove deletes the checkpoint file, once a run's output is safely written
+func (c *Checkpoint) Remove() error {
+	if err := os.Remove(c.path); err != nil && !os.IsNotExist(err) {
+		return fmt.Errorf("failed to remove checkpoint: %w", err)
+	}
+	return nil
+}
+
+type checkpointKey struct{}
+
+// WithCheckpoint returns a context under which ScrapeWithPagination saves its
+// progress through each URL's pages to checkpoint, and resumes a URL from the
+// progress saved there
+func WithCheckpoint(ctx context.Context, checkpoint *Checkpoint) context.Context {
+	return context.WithValue(ctx, checkpointKey{}, checkpoint)
+}
+
+// checkpointFromContext returns the checkpoint stored by WithCheckpoint, if any
+func checkpointFromContext(ctx context.Context) *Checkpoint {
+	checkpoint, _ := ctx.Value(checkpointKey{}).(*Checkpoint)
+	return checkpoint
+}
@@ -0,0 +1,190 @@
+// internal/scraper/checkpoint_test.go
+package scraper
+
+import (
+	"context"
+	"fmt"
+	"net/http"
+	"net/http/httptest"
+	"os"
+	"path/filepath"
+	"reflect"
+	"strconv"
+	"sync"
+	"testing"
+	"time"
+
+	"github.com/valpere/DataScrapexter/internal/pipeline"
+)
+
+func TestCheckpointResume(t *testing.T) {
+	path := filepath.Join(t.TempDir(), "checkpoints", "run.json")
+
+	cp, err := OpenCheckpoint(path, true)
+	if err != nil {
+		t.Fatalf("Failed to open checkpoint: %v", err)
+	}
+	// With no interval every completion is written, holding all records of the URL
+	cp.Interval = 0
+	records := []map[string]interface{}{{"sku": "A1"}, {"sku": "A2"}}
+	if _, err := cp.CompleteAll("https://example.com/a", records); err != nil {
+		t.Fatalf("Failed to complete URL: %v", err)
+	}
+
+	resumed, err := OpenCheckpoint(path, true)
+	if err != nil {
+		t.Fatalf("Failed to reopen checkpoint: %v", err)
+	}
+	if !resumed.Resumed() || !resumed.IsDone("https://example.com/a") || len(resumed.Records) != 2 {
+		t.Errorf("Expected the finished URL and its records, got %v and %v", resumed.Done, resumed.Records)
+	}
+	if resumed.IsDone("https://example.com/b") {
+		t.Error("Expected a URL that never finished to be scraped again")
+	}
+}
+
+func TestCheckpointDeduplicatorState(t *testing.T) {
+	path := filepath.Join(t.TempDir(), "run.json")
+	ctx := context.Background()
+
+	cp, err := OpenCheckpoint(path, true)
+	if err != nil {
+		t.Fatalf("Failed to open checkpoint: %v", err)
+	}
+	cp.Interval = 0
+	if err := cp.TrackDeduplicator(&pipeline.RecordDeduplicator{Method: "hash"}); err != nil {
+		t.Fatalf("Failed to track deduplicator: %v", err)
+	}
+	kept, err := cp.CompleteAll("https://example.com/a", []map[string]interface{}{{"sku": "A1"}, {"sku": "A1"}})
+	if err != nil {
+		t.Fatalf("Failed to complete URL: %v", err)
+	}
+	if len(kept) != 1 {
+		t.Errorf("Expected the repeated record to be dropped, kept %v", kept)
+	}
+
+	// After a restart the records seen before it are still duplicates
+	resumed, err := OpenCheckpoint(path, true)
+	if err != nil {
+		t.Fatalf("Failed to reopen checkpoint: %v", err)
+	}
+	restored := &pipeline.RecordDeduplicator{Method: "hash"}
+	if err := resumed.TrackDeduplicator(restored); err != nil {
+		t.Fatalf("Failed to restore deduplicator: %v", err)
+	}
+	if result, _ := restored.Deduplicate(ctx, map[string]interface{}{"sku": "A1"}); result != nil {
+		t.Error("Expected a record seen before the restart to be dropped")
+	}
+	if result, _ := restored.Deduplicate(ctx, map[string]interface{}{"sku": "B1"}); result == nil {
+		t.Error("Expected a new record to be kept")
+	}
+
+	// State saved under another method cannot be restored
+	if err := resumed.TrackDeduplicator(&pipeline.RecordDeduplicator{Method: "field", Fields: []string{"sku"}}); err == nil {
+		t.Error("Expected deduplicator state for another method to be rejected")
+	}
+}
+
+func TestOpenCheckpointCorrupt(t *testing.T) {
+	path := filepath.Join(t.TempDir(), "run.json")
+	if err := os.WriteFile(path, []byte("{truncated"), 0644); err != nil {
+		t.Fatalf("Failed to write checkpoint: %v", err)
+	}
+	if _, err := OpenCheckpoint(path, true); err == nil {
+		t.Error("Expected error for a corrupt checkpoint")
+	}
+	if _, err := OpenCheckpoint(path, false); err != nil {
+		t.Errorf("Expected --no-resume to discard a corrupt checkpoint, got %v", err)
+	}
+	if _, err := os.Stat(path); !os.IsNotExist(err) {
+		t.Error("Expected the discarded checkpoint to be removed")
+	}
+}
+
+func TestCheckpointResumesPagination(t *testing.T) {
+	// Five pages linked by next buttons; the first run crashes when page 4 is requested
+	var mu sync.Mutex
+	var requests []string
+	crashAt := "4"
+	ctx, crash := context.WithCancel(context.Background())
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		page := r.URL.Query().Get("page")
+		if page == "" {
+			page = "1"
+		}
+		mu.Lock()
+		requests = append(requests, page)
+		mu.Unlock()
+		if page == crashAt {
+			crash()
+		}
+		next := ""
+		if n, _ := strconv.Atoi(page); n < 5 {
+			next = fmt.Sprintf(`<a class="next" href="/list?page=%d">Next</a>`, n+1)
+		}
+		fmt.Fprintf(w, `<html><body><h1>Page %s</h1>%s</body></html>`, page, next)
+	}))
+	defer server.Close()
+
+	newEngine := func() *Engine {
+		engine, err := NewEngine(&Config{
+			Timeout:   10 * time.Second,
+			RateLimit: time.Millisecond,
+			BurstSize: 1,
+			Pagination: &PaginationConfig{
+				Enabled:      true,
+				Type:         PaginationTypeNextButton,
+				NextSelector: "a.next",
+				MaxPages:     10,
+			},
+		})
+		if err != nil {
+			t.Fatalf("Failed to create engine: %v", err)
+		}
+		return engine
+	}
+	fields := []FieldConfig{{Name: "title", Selector: "h1", Type: "text"}}
+	path := filepath.Join(t.TempDir(), "run.json")
+	baseURL := server.URL + "/list"
+
+	checkpoint, err := OpenCheckpoint(path, true)
+	if err != nil {
+		t.Fatalf("Failed to open checkpoint: %v", err)
+	}
+	checkpoint.Interval = 0
+	result, err := newEngine().ScrapeWithPagination(WithCheckpoint(ctx, checkpoint), baseURL, fields)
+	if err != nil {
+		t.Fatalf("Pagination scraping failed: %v", err)
+	}
+	if result.TotalPages != 3 {
+		t.Fatalf("Expected the crash to stop pagination after 3 pages, got %d", result.TotalPages)
+	}
+	// The process dies here, before the URL is completed
+
+	resumed, err := OpenCheckpoint(path, true)
+	if err != nil {
+		t.Fatalf("Failed to reopen checkpoint: %v", err)
+	}
+	if !resumed.Resumed() || resumed.IsDone(baseURL) {
+		t.Fatalf("Expected progress through %s, not a finished URL: %+v", baseURL, resumed.Pagination)
+	}
+	mu.Lock()
+	requests, crashAt = nil, ""
+	mu.Unlock()
+	result, err = newEngine().ScrapeWithPagination(WithCheckpoint(context.Background(), resumed), baseURL, fields)
+	if err != nil {
+		t.Fatalf("Resumed pagination failed: %v", err)
+	}
+
+	var titles []string
+	for _, page := range result.Pages {
+		titles = append(titles, fmt.Sprint(page.Data["title"]))
+	}
+	if want := []string{"Page 1", "Page 2", "Page 3", "Page 4", "Page 5"}; !reflect.DeepEqual(titles, want) {
+		t.Errorf("Expected every page once, got %v", titles)
+	}
+	// Page 3 is fetched again only for its next link; pages 1 and 2 not at all
+	if want := []string{"3", "4", "4", "5", "5"}; !reflect.DeepEqual(requests, want) {
+		t.Errorf("Expected the resumed run to go on after page 3, requested %v", requests)
+	}
+}
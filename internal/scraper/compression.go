@@ -0,0 +1,148 @@
+// internal/scraper/compression.go
+package scraper
+
+import (
+	"bufio"
+	"compress/flate"
+	"compress/gzip"
+	"compress/zlib"
+	"fmt"
+	"io"
+	"net/http"
+	"strings"
+
+	"github.com/andybalholm/brotli"
+)
+
+// acceptEncoding is sent when the request sets no Accept-Encoding of its own
+const acceptEncoding = "gzip, deflate, br"
+
+// withDecompression wraps transport so responses are decoded according to
+// their Content-Encoding whatever Accept-Encoding was sent. Go's transport only
+// decodes gzip, and only when it chose the header itself; a configured
+// Accept-Encoding such as a browser's "gzip, deflate, br" left bodies encoded.
+// With disabled, no compression is requested and bodies are passed through.
+func withDecompression(transport *http.Transport, disabled bool) http.RoundTripper {
+	if disabled {
+		transport.DisableCompression = true
+		return transport
+	}
+	return &decompressTransport{next: transport}
+}
+
+type decompressTransport struct {
+	next http.RoundTripper
+}
+
+func (t *decompressTransport) RoundTrip(req *http.Request) (*http.Response, error) {
+	if req.Header.Get("Accept-Encoding") == "" {
+		req = req.Clone(req.Context())
+		req.Header.Set("Accept-Encoding", acceptEncoding)
+	}
+	resp, err := t.next.RoundTrip(req)
+	if err != nil {
+		return nil, err
+	}
+
+	encodings := contentEncodings(resp.Header.Get("Content-Encoding"))
+	if len(encodings) == 0 || req.Method == http.MethodHead {
+		return resp, nil
+	}
+	for _, encoding := range encodings {
+		if !supportedEncoding(encoding) {
+			return resp, nil // Left as sent; the caller sees Content-Encoding
+		}
+	}
+	resp.Body = &decodedBody{body: resp.Body, encodings: encodings}
+	resp.Header.Del("Content-Encoding")
+	resp.Header.Del("Content-Length")
+	resp.ContentLength = -1
+	resp.Uncompressed = true
+	return resp, nil
+}
+
+// contentEncodings lists the codings of a Content-Encoding header in the order
+// they were applied, leaving out identity
+func contentEncodings(header string) []string {
+	var encodings []string
+	for _, encoding := range strings.Split(header, ",") {
+		encoding = strings.ToLower(strings.TrimSpace(encoding))
+		if encoding != "" && encoding != "identity" {
+			encodings = append(encodings, encoding)
+		}
+	}
+	return encodings
+}
+
+func supportedEncoding(encoding string) bool {
+	switch encoding {
+	case "gzip", "x-gzip", "deflate", "br":
+		return true
+	}
+	return false
+}
+
+// decodedBody undoes the response's codings on first read. An empty body (a
+// 204 or 304 that still names its encoding) is read as empty, not decoded.
+type decodedBody struct {
+	body      io.ReadCloser
+	encodings []string
+	reader    io.Reader
+	err       error
+}
+
+func (b *decodedBody) Read(p []byte) (int, error) {
+	if b.reader == nil && b.err == nil {
+		br := bufio.NewReader(b.body)
+		if _, err := br.Peek(1); err == io.EOF {
+			b.reader = br
+		} else {
+			b.reader, b.err = newDecoder(br, b.encodings)
+		}
+	}
+	if b.err != nil {
+		return 0, b.err
+	}
+	return b.reader.Read(p)
+}
+
+func (b *decodedBody) Close() error {
+	return b.body.Close()
+}
+
+// newDecoder reads r through decoders for encodings, last applied first
+func newDecoder(r io.Reader, encodings []string) (io.Reader, error) {
+	for i := len(encodings) - 1; i >= 0; i-- {
+		switch encodings[i] {
+		case "gzip", "x-gzip":
+			gz, err := gzip.NewReader(r)
+			if err != nil {
+				return nil, fmt.Errorf("failed to decode gzip response: %w", err)
+			}
+			r = gz
+		case "deflate":
+			zr, err := newDeflateReader(r)
+			if err != nil {
+				return nil, fmt.Errorf("failed to decode deflate response: %w", err)
+			}
+			r = zr
+		case "br":
+			r = brotli.NewReader(r)
+		}
+	}
+	return r, nil
+}
+
+// newDeflateReader reads HTTP deflate, which is zlib-wrapped; some servers send
+// a raw deflate stream instead, recognized by the missing zlib header
+func newDeflateReader(r io.Reader) (io.Reader, error) {
+	br := bufio.NewReader(r)
+	header, err := br.Peek(2)
+	if err != nil {
+		return nil, err
+	}
+	if header[0]&0x0f == 8 && (uint16(header[0])<<8|uint16(header[1]))%31 == 0 {
+		return zlib.NewReader(br)
+	}
+	return flate.NewReader(br), nil
+}
@@ -0,0 +1,136 @@
+// internal/scraper/compression_test.go
+package scraper
+
+import (
+	"bytes"
+	"compress/flate"
+	"compress/gzip"
+	"compress/zlib"
+	"context"
+	"io"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/andybalholm/brotli"
+)
+
+const compressedPage = `<html><body><h1>Desk Lamp</h1><span class="price">19.99</span></body></html>`
+
+// compressFixture encodes compressedPage with encoding, as a server would
+func compressFixture(t *testing.T, encoding string) []byte {
+	t.Helper()
+	var buf bytes.Buffer
+	var w io.WriteCloser
+	switch encoding {
+	case "br":
+		w = brotli.NewWriter(&buf)
+	case "gzip":
+		w = gzip.NewWriter(&buf)
+	case "deflate":
+		w = zlib.NewWriter(&buf)
+	case "raw-deflate":
+		w, _ = flate.NewWriter(&buf, flate.DefaultCompression)
+	default:
+		return []byte(compressedPage)
+	}
+	w.Write([]byte(compressedPage))
+	if err := w.Close(); err != nil {
+		t.Fatal(err)
+	}
+	return buf.Bytes()
+}
+
+func TestScrapeDecompressesResponses(t *testing.T) {
+	tests := []struct {
+		name     string
+		encoding string // Content-Encoding sent
+		fixture  string // How the body is encoded
+		headers  map[string][]string
+	}{
+		{name: "brotli", encoding: "br", fixture: "br"},
+		{name: "gzip", encoding: "gzip", fixture: "gzip"},
+		{name: "deflate", encoding: "deflate", fixture: "deflate"},
+		{name: "raw deflate", encoding: "deflate", fixture: "raw-deflate"},
+		{name: "plain", fixture: "plain"},
+		// A browser-like configured header used to turn off Go's own gzip decoding
+		{name: "configured Accept-Encoding", encoding: "br", fixture: "br",
+			headers: map[string][]string{"Accept-Encoding": {"gzip, deflate, br"}}},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			body := compressFixture(t, tt.fixture)
+			var accepted string
+			server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+				accepted = r.Header.Get("Accept-Encoding")
+				if tt.encoding != "" {
+					w.Header().Set("Content-Encoding", tt.encoding)
+				}
+				w.Write(body)
+			}))
+			defer server.Close()
+
+			engine, err := NewEngine(&Config{Timeout: 10 * time.Second, RateLimit: 10 * time.Millisecond, BurstSize: 1, Headers: tt.headers})
+			if err != nil {
+				t.Fatalf("Failed to create engine: %v", err)
+			}
+			result, err := engine.Scrape(context.Background(), server.URL, []FieldConfig{
+				{Name: "title", Selector: "h1", Type: "text", Required: true},
+				{Name: "price", Selector: ".price", Type: "text", Required: true},
+			})
+			if err != nil {
+				t.Fatalf("Scraping failed: %v", err)
+			}
+			if result.Data["title"] != "Desk Lamp" || result.Data["price"] != "19.99" {
+				t.Errorf("data = %v, want the decoded page's fields", result.Data)
+			}
+			if !strings.Contains(accepted, "br") {
+				t.Errorf("Accept-Encoding = %q, want brotli offered", accepted)
+			}
+		})
+	}
+}
+
+func TestScrapeDisableCompression(t *testing.T) {
+	var accepted []string
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		accepted = r.Header.Values("Accept-Encoding")
+		w.Write([]byte(compressedPage))
+	}))
+	defer server.Close()
+
+	engine, err := NewEngine(&Config{Timeout: 10 * time.Second, RateLimit: 10 * time.Millisecond, BurstSize: 1, DisableCompression: true})
+	if err != nil {
+		t.Fatalf("Failed to create engine: %v", err)
+	}
+	result, err := engine.Scrape(context.Background(), server.URL, []FieldConfig{{Name: "title", Selector: "h1", Type: "text"}})
+	if err != nil {
+		t.Fatalf("Scraping failed: %v", err)
+	}
+	if result.Data["title"] != "Desk Lamp" {
+		t.Errorf("title = %v, want Desk Lamp", result.Data["title"])
+	}
+	if len(accepted) != 0 {
+		t.Errorf("Accept-Encoding = %q, want none with disable_compression", accepted)
+	}
+}
+
+func TestDecompressTransportEmptyBody(t *testing.T) {
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		w.Header().Set("Content-Encoding", "br")
+		w.WriteHeader(http.StatusNoContent)
+	}))
+	defer server.Close()
+
+	client := &http.Client{Transport: withDecompression(&http.Transport{}, false)}
+	resp, err := client.Get(server.URL)
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer resp.Body.Close()
+	if _, err := io.ReadAll(resp.Body); err != nil {
+		t.Errorf("reading an empty encoded body: %v", err)
+	}
+}
@@ -0,0 +1,89 @@
+// internal/scraper/conn_limit.go
+package scraper
+
+import (
+	"context"
+	"net"
+	"sync"
+)
+
+// dialFunc matches http.Transport.DialContext
+type dialFunc func(ctx context.Context, network, addr string) (net.Conn, error)
+
+// connLimiter caps open connections across every transport of an engine. A dial
+// past the cap queues until a connection closes or the request context ends.
+type connLimiter struct {
+	slots chan struct{}
+
+	mu        sync.Mutex
+	closeIdle func() // frees idle keep-alive connections when the cap is reached
+}
+
+// newConnLimiter returns a limiter for max connections, or nil when max is not positive
+func newConnLimiter(max int) *connLimiter {
+	if max <= 0 {
+		return nil
+	}
+	return &connLimiter{slots: make(chan struct{}, max)}
+}
+
+// setIdleCloser registers the function used to drop idle connections before queueing
+func (cl *connLimiter) setIdleCloser(closeIdle func()) {
+	cl.mu.Lock()
+	cl.closeIdle = closeIdle
+	cl.mu.Unlock()
+}
+
+// acquire takes a connection slot, waiting if none is free
+func (cl *connLimiter) acquire(ctx context.Context) error {
+	select {
+	case cl.slots <- struct{}{}:
+		return nil
+	default:
+	}
+
+	// Idle keep-alive connections hold slots too; drop them before queueing so
+	// a crawl moving to new hosts is not stuck behind pooled connections
+	cl.mu.Lock()
+	closeIdle := cl.closeIdle
+	cl.mu.Unlock()
+	if closeIdle != nil {
+		closeIdle()
+	}
+
+	select {
+	case cl.slots <- struct{}{}:
+		return nil
+	case <-ctx.Done():
+		return ctx.Err()
+	}
+}
+
+// wrap returns dial limited by the cap; the slot is released when the connection closes
+func (cl *connLimiter) wrap(dial dialFunc) dialFunc {
+	return func(ctx context.Context, network, addr string) (net.Conn, error) {
+		if err := cl.acquire(ctx); err != nil {
+			return nil, err
+		}
+		conn, err := dial(ctx, network, addr)
+		if err != nil {
+			<-cl.slots
+			return nil, err
+		}
+		return &limitedConn{Conn: conn, release: func() { <-cl.slots }}, nil
+	}
+}
+
+// limitedConn releases its limiter slot exactly once on Close
+type limitedConn struct {
+	net.Conn
+	once    sync.Once
+	release func()
+}
+
+// Close closes the connection and frees its slot
+func (c *limitedConn) Close() error {
+	err := c.Conn.Close()
+	c.once.Do(c.release)
+	return err
+}
@@ -0,0 +1,102 @@
+// internal/scraper/conn_limit_test.go
+package scraper
+
+import (
+	"context"
+	"net"
+	"net/http"
+	"net/http/httptest"
+	"sync"
+	"sync/atomic"
+	"testing"
+	"time"
+)
+
+func TestConnLimiterQueuesDials(t *testing.T) {
+	limiter := newConnLimiter(2)
+	var open, peak atomic.Int32
+	dial := limiter.wrap(func(ctx context.Context, network, addr string) (net.Conn, error) {
+		current := open.Add(1)
+		for {
+			p := peak.Load()
+			if current <= p || peak.CompareAndSwap(p, current) {
+				break
+			}
+		}
+		client, server := net.Pipe()
+		server.Close()
+		return client, nil
+	})
+
+	var wg sync.WaitGroup
+	for i := 0; i < 6; i++ {
+		wg.Add(1)
+		go func() {
+			defer wg.Done()
+			conn, err := dial(context.Background(), "tcp", "example.com:80")
+			if err != nil {
+				t.Errorf("dial failed: %v", err)
+				return
+			}
+			time.Sleep(10 * time.Millisecond)
+			open.Add(-1)
+			conn.Close()
+			conn.Close() // a second Close must not release another slot
+		}()
+	}
+	wg.Wait()
+
+	if got := peak.Load(); got > 2 {
+		t.Errorf("peak open connections = %d, want at most 2", got)
+	}
+	if len(limiter.slots) != 0 {
+		t.Errorf("expected all slots released, %d still held", len(limiter.slots))
+	}
+
+	// A full limiter queues until the request context gives up
+	held, err := dial(context.Background(), "tcp", "a:80")
+	if err != nil {
+		t.Fatalf("dial failed: %v", err)
+	}
+	defer held.Close()
+	if _, err := dial(context.Background(), "tcp", "b:80"); err != nil {
+		t.Fatalf("dial failed: %v", err)
+	}
+	ctx, cancel := context.WithTimeout(context.Background(), 20*time.Millisecond)
+	defer cancel()
+	if _, err := dial(ctx, "tcp", "c:80"); err == nil {
+		t.Error("expected queued dial to fail when its context expires")
+	}
+}
+
+func TestScrapeMaxTotalConnectionsAcrossHosts(t *testing.T) {
+	handler := http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		w.Write([]byte("<html><body><h1>ok</h1></body></html>"))
+	})
+	first := httptest.NewServer(handler)
+	defer first.Close()
+	second := httptest.NewServer(handler)
+	defer second.Close()
+
+	engine, err := NewEngine(&Config{
+		Timeout:   5 * time.Second,
+		RateLimit: 10 * time.Millisecond,
+		BurstSize: 1,
+		Transport: &TransportConfig{MaxTotalConnections: 1},
+	})
+	if err != nil {
+		t.Fatalf("Failed to create engine: %v", err)
+	}
+
+	fields := []FieldConfig{{Name: "title", Selector: "h1", Type: "text"}}
+	// The idle connection to the first host must give up its slot for the second
+	for _, url := range []string{first.URL, second.URL, first.URL} {
+		result, err := engine.Scrape(context.Background(), url, fields)
+		if err != nil {
+			t.Fatalf("Scraping %s failed: %v", url, err)
+		}
+		if result.Data["title"] != "ok" {
+			t.Errorf("unexpected data from %s: %v", url, result.Data)
+		}
+	}
+}
@@ -0,0 +1,167 @@
+// internal/scraper/cookie_jar.go
+package scraper
+
+import (
+	"encoding/json"
+	"fmt"
+	"net/http"
+	"net/http/cookiejar"
+	"net/url"
+	"os"
+	"path"
+	"path/filepath"
+	"sync"
+	"time"
+)
+
+// cookieJar is the engine's cookie jar. net/http/cookiejar does the scoping;
+// alongside it every Set-Cookie is kept with the URL that set it, because the
+// standard jar cannot list its cookies. Saving writes those entries out and
+// loading replays them, so restored cookies are scoped exactly as before.
+type cookieJar struct {
+	jar     *cookiejar.Jar
+	file    string // cookie_jar_file; empty keeps cookies for this run only
+	mu      sync.Mutex
+	entries map[string]savedCookie // By domain, path and name, as the jar keys them
+}
+
+// savedCookie is a cookie as stored in cookie_jar_file
+type savedCookie struct {
+	URL      string        `json:"url"` // The URL whose response set it
+	Name     string        `json:"name"`
+	Value    string        `json:"value"`
+	Domain   string        `json:"domain,omitempty"`
+	Path     string        `json:"path,omitempty"`
+	Expires  time.Time     `json:"expires,omitempty"` // Zero for session cookies
+	Secure   bool          `json:"secure,omitempty"`
+	HttpOnly bool          `json:"http_only,omitempty"`
+	SameSite http.SameSite `json:"same_site,omitempty"`
+}
+
+// newCookieJar returns the jar shared by warmup and scrape requests, or nil when
+// none of cookie_jar, cookie_jar_file and warmup URLs are configured and
+// requests stay cookie-less. Cookies saved in cookie_jar_file are loaded.
+func newCookieJar(config *Config) (*cookieJar, error) {
+	if !config.CookieJar && config.CookieJarFile == "" && len(config.WarmupURLs) == 0 {
+		return nil, nil
+	}
+	jar, err := cookiejar.New(nil)
+	if err != nil {
+		return nil, err
+	}
+	cj := &cookieJar{jar: jar, file: config.CookieJarFile, entries: make(map[string]savedCookie)}
+	if cj.file == "" {
+		return cj, nil
+	}
+
+	data, err := os.ReadFile(cj.file)
+	if os.IsNotExist(err) {
+		return cj, nil
+	}
+	if err != nil {
+		return nil, fmt.Errorf("failed to read cookie jar: %w", err)
+	}
+	var saved []savedCookie
+	if err := json.Unmarshal(data, &saved); err != nil {
+		return nil, fmt.Errorf("cookie jar %s is corrupt: %w", cj.file, err)
+	}
+	for _, c := range saved {
+		u, err := url.Parse(c.URL)
+		if err != nil {
+			continue
+		}
+		cj.SetCookies(u, []*http.Cookie{c.cookie()})
+	}
+	return cj, nil
+}
+
+// SetCookies stores cookies set by a response from u
+func (cj *cookieJar) SetCookies(u *url.URL, cookies []*http.Cookie) {
+	cj.jar.SetCookies(u, cookies)
+
+	now := time.Now()
+	cj.mu.Lock()
+	defer cj.mu.Unlock()
+	for _, c := range cookies {
+		entry := savedCookie{
+			URL: u.String(), Name: c.Name, Value: c.Value, Domain: c.Domain, Path: c.Path,
+			Expires: c.Expires, Secure: c.Secure, HttpOnly: c.HttpOnly, SameSite: c.SameSite,
+		}
+		if c.MaxAge > 0 {
+			entry.Expires = now.Add(time.Duration(c.MaxAge) * time.Second)
+		}
+		key := entry.key(u)
+		if c.MaxAge < 0 || (!entry.Expires.IsZero() && !entry.Expires.After(now)) {
+			delete(cj.entries, key) // The site deleted it
+			continue
+		}
+		cj.entries[key] = entry
+	}
+}
+
+// Cookies returns the cookies to send with a request to u
+func (cj *cookieJar) Cookies(u *url.URL) []*http.Cookie {
+	return cj.jar.Cookies(u)
+}
+
+// Save writes the unexpired cookies to cookie_jar_file; it does nothing
+// without one. The file holds session credentials, so only its owner can read it.
+func (cj *cookieJar) Save() error {
+	if cj.file == "" {
+		return nil
+	}
+	now := time.Now()
+	cj.mu.Lock()
+	saved := make([]savedCookie, 0, len(cj.entries))
+	for _, entry := range cj.entries {
+		if entry.Expires.IsZero() || entry.Expires.After(now) {
+			saved = append(saved, entry)
+		}
+	}
+	cj.mu.Unlock()
+
+	data, err := json.MarshalIndent(saved, "", "  ")
+	if err != nil {
+		return fmt.Errorf("failed to encode cookie jar: %w", err)
+	}
+	if dir := filepath.Dir(cj.file); dir != "." {
+		if err := os.MkdirAll(dir, 0755); err != nil {
+			return fmt.Errorf("failed to create cookie jar directory: %w", err)
+		}
+	}
+	tmp := cj.file + ".tmp"
+	if err := os.WriteFile(tmp, data, 0600); err != nil {
+		return fmt.Errorf("failed to write cookie jar: %w", err)
+	}
+	if err := os.Rename(tmp, cj.file); err != nil {
+		os.Remove(tmp)
+		return fmt.Errorf("failed to write cookie jar: %w", err)
+	}
+	return nil
+}
+
+// cookie rebuilds the Set-Cookie the entry was saved from
+func (c savedCookie) cookie() *http.Cookie {
+	return &http.Cookie{
+		Name: c.Name, Value: c.Value, Domain: c.Domain, Path: c.Path,
+		Expires: c.Expires, Secure: c.Secure, HttpOnly: c.HttpOnly, SameSite: c.SameSite,
+	}
+}
+
+// key identifies the cookie the way the jar does: a cookie with the same
+// domain, path and name replaces it
+func (c savedCookie) key(u *url.URL) string {
+	domain := c.Domain
+	if domain == "" {
+		domain = u.Hostname()
+	}
+	cookiePath := c.Path
+	if cookiePath == "" || cookiePath[0] != '/' {
+		// RFC 6265 default-path: the request path up to its last slash
+		cookiePath = "/"
+		if dir := path.Dir(u.Path); u.Path != "" && u.Path[0] == '/' && dir != "/" {
+			cookiePath = dir
+		}
+	}
+	return domain + ";" + cookiePath + ";" + c.Name
+}
@@ -0,0 +1,118 @@
+// internal/scraper/debug.go
+package scraper
+
+import (
+	"context"
+	"fmt"
+	"net/http"
+	"os"
+	"path/filepath"
+	"regexp"
+	"sort"
+	"strings"
+	"time"
+
+	"github.com/valpere/DataScrapexter/internal/config"
+	"github.com/valpere/DataScrapexter/internal/utils"
+)
+
+var debugLogger = utils.NewComponentLogger("scraper-debug")
+
+// maxSnapshotNameLength caps the URL-derived part of snapshot file names
+const maxSnapshotNameLength = 100
+
+// snapshotNameRegex matches characters not allowed in snapshot file names
+var snapshotNameRegex = regexp.MustCompile(`[^a-zA-Z0-9.-]+`)
+
+// DebugConfig holds options for diagnosing failed scrapes
+type DebugConfig struct {
+	// SaveFailedBodies is a directory where the raw response body and request
+	// headers are written whenever a page fails extraction or looks blocked.
+	// Credentials in headers such as Cookie and Authorization are redacted.
+	SaveFailedBodies string `yaml:"save_failed_bodies,omitempty" json:"save_failed_bodies,omitempty"`
+
+	// RecordSession is a directory where every HTTP request (URL, headers, proxy)
+	// and response (status, headers, body) of the run is saved, with credentials
+	// in request headers and the proxy URL redacted
+	RecordSession string `yaml:"record_session,omitempty" json:"record_session,omitempty"`
+
+	// ReplaySession is a directory recorded by RecordSession whose responses are
+	// served instead of the network; browser fetches are not recorded or replayed
+	ReplaySession string `yaml:"replay_session,omitempty" json:"replay_session,omitempty"`
+}
+
+// responseSnapshot captures the raw exchange for a single fetch
+type responseSnapshot struct {
+	Method         string
+	URL            string
+	StatusCode     int
+	RequestHeaders http.Header
+	Body           []byte
+}
+
+// snapshotKey is the context key carrying the snapshot holder for a fetch
+type snapshotKey struct{}
+
+// withSnapshot returns a context that asks the HTTP fetch to record the raw exchange into snap
+func withSnapshot(ctx context.Context, snap *responseSnapshot) context.Context {
+	return context.WithValue(ctx, snapshotKey{}, snap)
+}
+
+// snapshotFromContext returns the snapshot holder installed by withSnapshot, if any
+func snapshotFromContext(ctx context.Context) *responseSnapshot {
+	snap, _ := ctx.Value(snapshotKey{}).(*responseSnapshot)
+	return snap
+}
+
+// isBlockStatus reports whether an HTTP status usually means the scraper was blocked
+func isBlockStatus(statusCode int) bool {
+	return statusCode == http.StatusForbidden || statusCode == http.StatusTooManyRequests
+}
+
+// saveFailedBody writes snap to the configured debug directory, named by URL and timestamp
+func (e *Engine) saveFailedBody(snap *responseSnapshot, reason string) {
+	if e.config.Debug == nil || e.config.Debug.SaveFailedBodies == "" || snap == nil || snap.URL == "" {
+		return
+	}
+
+	dir := e.config.Debug.SaveFailedBodies
+	if err := os.MkdirAll(dir, 0755); err != nil {
+		debugLogger.Warnf("Failed to create debug directory %s: %v", dir, err)
+		return
+	}
+
+	name := strings.Trim(snapshotNameRegex.ReplaceAllString(snap.URL, "_"), "_")
+	if len(name) > maxSnapshotNameLength {
+		name = name[:maxSnapshotNameLength]
+	}
+	path := filepath.Join(dir, fmt.Sprintf("%s_%s.txt", name, time.Now().Format("20060102T150405.000000000")))
+
+	var b strings.Builder
+	fmt.Fprintf(&b, "# Reason: %s\n", reason)
+	fmt.Fprintf(&b, "# Status: %d\n", snap.StatusCode)
+	method := snap.Method
+	if method == "" {
+		method = http.MethodGet
+	}
+	fmt.Fprintf(&b, "%s %s\n", method, snap.URL)
+
+	headers := config.RedactedHeaders(snap.RequestHeaders)
+	keys := make([]string, 0, len(headers))
+	for key := range headers {
+		keys = append(keys, key)
+	}
+	sort.Strings(keys)
+	for _, key := range keys {
+		for _, value := range headers[key] {
+			fmt.Fprintf(&b, "%s: %s\n", key, value)
+		}
+	}
+	b.WriteString("\n")
+	b.Write(snap.Body)
+
+	if err := os.WriteFile(path, []byte(b.String()), 0644); err != nil {
+		debugLogger.Warnf("Failed to save response body for %s: %v", snap.URL, err)
+		return
+	}
+	debugLogger.Infof("Saved failed response body for %s to %s", snap.URL, path)
+}
@@ -0,0 +1,50 @@
+// internal/scraper/embedded_json.go
+package scraper
+
+import (
+	"encoding/json"
+	"fmt"
+	"regexp"
+	"strings"
+
+	"github.com/PuerkitoBio/goquery"
+	"github.com/valpere/DataScrapexter/internal/pipeline"
+)
+
+// jsAssignmentPrefix matches a leading `window.__STATE__ =` style assignment around a JSON literal
+var jsAssignmentPrefix = regexp.MustCompile(`^\s*(?:(?:var|let|const)\s+)?[\w$.]+\s*=\s*`)
+
+// extractEmbeddedJSON parses the JSON held by a script element (e.g. Next.js __NEXT_DATA__)
+// and returns the value at path. Config validation requires a path; "$" returns the whole blob.
+func extractEmbeddedJSON(selection *goquery.Selection, path string) (interface{}, error) {
+	data, err := parseEmbeddedJSON(selection.Text())
+	if err != nil {
+		return nil, err
+	}
+
+	value, ok := pipeline.LookupJSONPath(data, pipeline.SplitJSONPath(path))
+	if !ok {
+		return nil, fmt.Errorf("embedded JSON path not found: %s", path)
+	}
+	return value, nil
+}
+
+// parseEmbeddedJSON decodes a script body that is either plain JSON or a single
+// `name = {...};` assignment of a JSON literal
+func parseEmbeddedJSON(text string) (interface{}, error) {
+	text = strings.TrimSpace(text)
+	if text == "" {
+		return nil, fmt.Errorf("embedded JSON script is empty")
+	}
+
+	var data interface{}
+	if err := json.Unmarshal([]byte(text), &data); err == nil {
+		return data, nil
+	}
+
+	body := strings.TrimSuffix(strings.TrimSpace(jsAssignmentPrefix.ReplaceAllString(text, "")), ";")
+	if err := json.Unmarshal([]byte(body), &data); err != nil {
+		return nil, fmt.Errorf("failed to parse embedded JSON: %w", err)
+	}
+	return data, nil
+}
@@ -0,0 +1,71 @@
+package scraper
+
+import (
+	"context"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+	"time"
+)
+
+// nextJSPage mimics a Next.js page whose data lives only in the __NEXT_DATA__ blob
+const nextJSPage = `<!DOCTYPE html><html><head><title>Widget</title></head>
+<body><div id="__next"></div>
+<script id="__NEXT_DATA__" type="application/json">{"props":{"pageProps":{"product":{"name":"Widget","price":19.99,"tags":["new","sale"],"variants":[{"sku":"W-1"},{"sku":"W-2"}]}}},"page":"/product/[slug]","buildId":"abc123"}</script>
+<script>window.__INITIAL_STATE__ = {"cart":{"count":3}};</script>
+</body></html>`
+
+func TestScrapeEmbeddedJSON(t *testing.T) {
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		w.Write([]byte(nextJSPage))
+	}))
+	defer server.Close()
+
+	engine, err := NewEngine(&Config{Timeout: 10 * time.Second, RateLimit: 10 * time.Millisecond, BurstSize: 1})
+	if err != nil {
+		t.Fatalf("Failed to create engine: %v", err)
+	}
+
+	fields := []FieldConfig{
+		{Name: "name", Type: "embedded_json", Selector: "#__NEXT_DATA__", Path: "props.pageProps.product.name"},
+		{Name: "price", Type: "embedded_json", Selector: "script#__NEXT_DATA__", Path: "$.props.pageProps.product.price"},
+		{Name: "sku", Type: "embedded_json", Selector: "#__NEXT_DATA__", Path: "props.pageProps.product.variants[1].sku"},
+		{Name: "tags", Type: "embedded_json", Selector: "#__NEXT_DATA__", Path: "props.pageProps.product.tags"},
+		{Name: "cart", Type: "embedded_json", Selector: "script:not([id])", Path: "cart.count"},
+		{Name: "state", Type: "embedded_json", Selector: "script:not([id])", Path: "$"},
+		{Name: "missing", Type: "embedded_json", Selector: "#__NEXT_DATA__", Path: "props.pageProps.review"},
+	}
+
+	result, err := engine.Scrape(context.Background(), server.URL, fields)
+	if err != nil {
+		t.Fatalf("Scraping failed: %v", err)
+	}
+
+	expected := map[string]interface{}{
+		"name":  "Widget",
+		"price": 19.99,
+		"sku":   "W-2",
+		"cart":  float64(3),
+	}
+	for name, want := range expected {
+		if got := result.Data[name]; got != want {
+			t.Errorf("%s = %v (%T), want %v", name, got, got, want)
+		}
+	}
+
+	tags, ok := result.Data["tags"].([]interface{})
+	if !ok || len(tags) != 2 || tags[0] != "new" {
+		t.Errorf("tags = %v, want [new sale]", result.Data["tags"])
+	}
+
+	if state, ok := result.Data["state"].(map[string]interface{}); !ok || state["cart"] == nil {
+		t.Errorf("Expected $ to return the whole blob, got %v", result.Data["state"])
+	}
+
+	if _, ok := result.Data["missing"]; ok {
+		t.Error("Expected missing path to produce no value")
+	}
+	if len(result.Errors) != 1 {
+		t.Errorf("Expected one error for the missing path, got %v", result.Errors)
+	}
+}
@@ -2,16 +2,23 @@
 package scraper
 
 import (
+	"bytes"
 	"context"
 	"fmt"
+	"io"
+	"net"
 	"net/http"
+	"net/url"
+	"sort"
 	"strings"
+	"sync"
 	"time"
 
 	"github.com/PuerkitoBio/goquery"
 	"github.com/valpere/DataScrapexter/internal/browser"
 	"github.com/valpere/DataScrapexter/internal/config"
 	"github.com/valpere/DataScrapexter/internal/errors"
+	"github.com/valpere/DataScrapexter/internal/pipeline"
 	"github.com/valpere/DataScrapexter/internal/proxy"
 	"github.com/valpere/DataScrapexter/internal/utils"
 )
@@ -20,20 +27,76 @@ import (
 const (
 	// DefaultMaxConcurrency defines the default maximum number of concurrent operations
 	DefaultMaxConcurrency = 10
+
+	// DefaultEmptyRetries is how often retry_on_empty re-fetches a page when MaxRetries is unset
+	DefaultEmptyRetries = 2
 )
 
+// refererKey is the context key carrying the URL of the page that linked to the
+// one currently being fetched.
+type refererKey struct{}
+
+// WithReferer returns a context that makes the engine send referer as the
+// Referer header. An empty referer leaves the header unset.
+func WithReferer(ctx context.Context, referer string) context.Context {
+	return context.WithValue(ctx, refererKey{}, referer)
+}
+
+// refererFromContext returns the referer stored by WithReferer, if any
+func refererFromContext(ctx context.Context) string {
+	referer, _ := ctx.Value(refererKey{}).(string)
+	return referer
+}
+
+// responseHeadersKey is the context key carrying a holder that the HTTP fetch
+// fills with the response headers, for strategies that paginate on headers.
+type responseHeadersKey struct{}
+
+// withResponseHeaders returns a context asking the HTTP fetch to store response headers in holder
+func withResponseHeaders(ctx context.Context, holder *http.Header) context.Context {
+	return context.WithValue(ctx, responseHeadersKey{}, holder)
+}
+
+// responseHeadersFromContext returns the response headers captured for the
+// current page, or nil if none were captured
+func responseHeadersFromContext(ctx context.Context) http.Header {
+	holder, _ := ctx.Value(responseHeadersKey{}).(*http.Header)
+	if holder == nil {
+		return nil
+	}
+	return *holder
+}
+
+// workerIDKey is the context key carrying the ID of the worker goroutine
+// issuing a request, used for proxy affinity.
+type workerIDKey struct{}
+
+// WithWorkerID returns a context tagged with the ID of the worker issuing requests
+func WithWorkerID(ctx context.Context, workerID int) context.Context {
+	return context.WithValue(ctx, workerIDKey{}, workerID)
+}
+
+// workerIDFromContext returns the worker ID stored by WithWorkerID, if any
+func workerIDFromContext(ctx context.Context) (int, bool) {
+	workerID, ok := ctx.Value(workerIDKey{}).(int)
+	return workerID, ok
+}
+
 // Enhanced Engine struct (existing fields preserved, error service added)
 type Engine struct {
 	// Existing fields preserved
 	httpClient     *http.Client
+	transport      *http.Transport // Base of httpClient's transport, under the session and decompression wrappers
 	userAgentPool  []string
 	currentUAIndex int
+	uaMu           sync.Mutex // Guards currentUAIndex across concurrent scrapes
 	config         *Config
-	rateLimiter    *AdaptiveRateLimiter
+	rateLimiters   *hostRateLimiters // One token bucket per host
 
 	// Enhanced features: error handling, browser automation, and proxy management
 	errorService   *errors.Service
 	browserManager *browser.BrowserManager
+	scroller       pageScroller // The browser, for infinite_scroll pagination
 	proxyManager   proxy.Manager
 	
 	// Performance optimizations
@@ -41,8 +104,41 @@ type Engine struct {
 	copyPool       *utils.Pool[*Result]      // Pool for result copies to reduce allocations
 	perfMetrics    *utils.PerformanceMetrics
 	memManager     *utils.MemoryManager
-	circuitBreaker *utils.CircuitBreaker
+	circuitBreakers *hostCircuitBreakers // One breaker per host so a failing site cannot block others
 	MaxConcurrency int // Maximum number of concurrent operations
+
+	// robots.txt politeness
+	robots    *robotsCache
+	hostPacer *hostPacer
+
+	// dialTLS presents a browser ClientHello when tls.mimic is set
+	dialTLS dialTLSFunc
+
+	// wrapDial decorates every transport's dials: the connection cap and header ordering
+	wrapDial dialWrapper
+
+	// requestStats records achieved request rate, limiter wait and peak concurrency
+	requestStats requestCounters
+
+	// hostBlocks drops hosts that keep answering with block statuses; nil unless block_abort is set
+	hostBlocks *hostBlockTracker
+
+	// hostPages counts pages per host and enforces max_pages_per_host
+	hostPages *hostPageCounter
+
+	// bodyMatch fails successful responses by body; nil unless retry_on_body_match
+	// or no_retry_on_body_match is set
+	bodyMatch *bodyMatchers
+
+	// session records or replays every HTTP exchange; nil unless debug asks for it
+	session *httpSession
+
+	// cookies is the cookie jar; nil unless cookie_jar, cookie_jar_file or warmup_urls is set
+	cookies *cookieJar
+
+	// warmup_urls run once before the first scrape; the outcome is shared by all callers
+	warmupOnce sync.Once
+	warmupErr  error
 }
 
 // Enhanced Result struct (existing fields preserved, error info added)
@@ -57,6 +153,9 @@ type Result struct {
 	Errors    []string `json:"errors,omitempty"`
 	Warnings  []string `json:"warnings,omitempty"`
 	ErrorRate float64  `json:"error_rate,omitempty"`
+
+	// CanonicalURL is the page's canonical URL, set when canonical_url is configured
+	CanonicalURL string `json:"canonical_url,omitempty"`
 }
 
 // Enhanced NewEngine function (existing signature preserved)
@@ -85,27 +184,82 @@ func NewEngine(config *Config) (*Engine, error) {
 		return nil, fmt.Errorf("invalid configuration: %w", err)
 	}
 
+	dialTLS, err := newTLSMimicDialer(config.TLS, config.Transport)
+	if err != nil {
+		return nil, fmt.Errorf("failed to configure TLS fingerprint: %w", err)
+	}
+
+	jar, err := newCookieJar(config)
+	if err != nil {
+		return nil, fmt.Errorf("failed to create cookie jar: %w", err)
+	}
+
+	bodyMatch, err := newBodyMatchers(config.RetryOnBodyMatch, config.NoRetryOnBodyMatch)
+	if err != nil {
+		return nil, fmt.Errorf("invalid configuration: %w", err)
+	}
+
+	session, err := newHTTPSession(config.Debug)
+	if err != nil {
+		return nil, fmt.Errorf("failed to set up session recording: %w", err)
+	}
+
+	order, err := newHeaderOrder(config.HeaderOrder, config.TLS)
+	if err != nil {
+		return nil, fmt.Errorf("failed to configure header order: %w", err)
+	}
+	if order != nil && dialTLS == nil {
+		// Go's own TLS setup may negotiate HTTP/2, where header order is not ours to set
+		dialTLS = newHTTP1DialTLS(config.Transport)
+	}
+
+	var limiter *connLimiter
+	if config.Transport != nil {
+		limiter = newConnLimiter(config.Transport.MaxTotalConnections)
+	}
+	var wrappers []dialWrapper
+	if limiter != nil {
+		wrappers = append(wrappers, limiter.wrap)
+	}
+	if order != nil {
+		wrappers = append(wrappers, order.wrap)
+	}
+	wrapDial := chainDialWrappers(wrappers...)
+
 	// Existing HTTP client setup preserved
+	transport := newTransport(config.Transport, dialTLS, nil, wrapDial)
+	if limiter != nil {
+		limiter.setIdleCloser(transport.CloseIdleConnections)
+	}
 	client := &http.Client{
-		Timeout: config.Timeout,
-		Transport: &http.Transport{
-			MaxIdleConns:        100,
-			MaxIdleConnsPerHost: 10,
-			IdleConnTimeout:     90 * time.Second,
-		},
+		Timeout:   config.Timeout,
+		Transport: session.wrap(withDecompression(transport, config.DisableCompression), nil),
+	}
+	if jar != nil {
+		client.Jar = jar
 	}
 
 	// Enhanced with error service and performance optimizations
 	engine := &Engine{
 		httpClient:     client,
+		transport:      transport,
 		config:         config,
-		errorService:   errors.NewService(),
+		errorService:   errors.NewService().WithHTTPClient(client),
 		MaxConcurrency: config.MaxConcurrency, // Use configured max concurrency
+		robots:         newRobotsCache(),
+		hostPacer:      newHostPacer(),
+		dialTLS:        dialTLS,
+		wrapDial:       wrapDial,
+		hostBlocks:     newHostBlockTracker(config.BlockAbort),
+		hostPages:      newHostPageCounter(config.MaxPagesPerHost),
+		bodyMatch:      bodyMatch,
+		session:        session,
+		cookies:        jar,
 		
 		// Initialize performance optimizations
 		perfMetrics:    utils.NewPerformanceMetrics(),
 		memManager:     utils.NewMemoryManager(100*1024*1024, 30*time.Second), // 100MB, 30s GC interval
-		circuitBreaker: utils.NewCircuitBreaker(5, 60*time.Second), // 5 failures, 60s timeout
+		circuitBreakers: newHostCircuitBreakers(5, 60*time.Second), // 5 failures, 60s timeout
 		
 		resultPool: utils.NewPool[*Result](
 			func() *Result {
@@ -115,17 +269,7 @@ func NewEngine(config *Config) (*Engine, error) {
 					Warnings: make([]string, 0),
 				}
 			},
-			func(result *Result) {
-				// Reset result for reuse
-				for k := range result.Data {
-					delete(result.Data, k)
-				}
-				result.Errors = result.Errors[:0]
-				result.Warnings = result.Warnings[:0]
-				result.Success = false
-				result.Error = nil
-				result.ErrorRate = 0
-			},
+			resetResult, // Reset result for reuse
 		),
 		
 		// Pool for result copies to optimize memory allocation during copying
@@ -147,6 +291,7 @@ func NewEngine(config *Config) (*Engine, error) {
 				result.Success = false
 				result.Error = nil
 				result.ErrorRate = 0
+				result.CanonicalURL = ""
 				result.Timestamp = time.Time{}
 			},
 		),
@@ -176,70 +321,31 @@ func NewEngine(config *Config) (*Engine, error) {
 				config.Browser.Enabled, config.Browser.Headless, config.Browser.Timeout, err)
 		}
 		engine.browserManager = bm
+		if bm.IsEnabled() {
+			engine.scroller = bm
+		}
 	}
 
 	// Setup proxy manager if configured
 	if config.Proxy != nil {
-		// Convert scraper ProxyConfig to proxy package ProxyConfig
-		// Parse rotation strategy
-		rotation, err := ParseRotationStrategy(config.Proxy.Rotation)
+		pm, err := NewProxyManager(config.Proxy)
 		if err != nil {
-			return nil, fmt.Errorf("invalid rotation strategy: %w", err)
-		}
-
-		proxyConfig := &proxy.ProxyConfig{
-			Enabled:          config.Proxy.Enabled,
-			Rotation:         rotation,
-			HealthCheck:      config.Proxy.HealthCheck,
-			HealthCheckURL:   config.Proxy.HealthCheckURL,
-			HealthCheckRate:  config.Proxy.HealthCheckRate,
-			Timeout:          config.Proxy.Timeout,
-			MaxRetries:       config.Proxy.MaxRetries,
-			RetryDelay:       config.Proxy.RetryDelay,
-			FailureThreshold: config.Proxy.FailureThreshold,
-			RecoveryTime:     config.Proxy.RecoveryTime,
-			Providers:        make([]proxy.ProxyProvider, len(config.Proxy.Providers)),
-		}
-
-		// Convert providers
-		for i, provider := range config.Proxy.Providers {
-			proxyConfig.Providers[i] = proxy.ProxyProvider{
-				Name:     provider.Name,
-				Type:     proxy.ProxyType(provider.Type),
-				Host:     provider.Host,
-				Port:     provider.Port,
-				Username: provider.Username,
-				Password: provider.Password,
-				Weight:   provider.Weight,
-				Enabled:  provider.Enabled,
-			}
+			return nil, err
 		}
-
-		// Convert TLS configuration if present
-		if config.Proxy.TLS != nil {
-			proxyConfig.TLS = &proxy.TLSConfig{
-				InsecureSkipVerify: config.Proxy.TLS.InsecureSkipVerify,
-				ServerName:         config.Proxy.TLS.ServerName,
-				RootCAs:            config.Proxy.TLS.RootCAs,
-				ClientCert:         config.Proxy.TLS.ClientCert,
-				ClientKey:          config.Proxy.TLS.ClientKey,
-			}
-		}
-
-		pm := proxy.NewProxyManager(proxyConfig)
 		if err := pm.Start(); err != nil {
 			return nil, fmt.Errorf("failed to start proxy manager: %w", err)
 		}
 		engine.proxyManager = pm
 	}
 
-	// Enhanced rate limiter setup
+	// Enhanced rate limiter setup; each host is limited separately, by
+	// host_rate_limits where it is listed and by the global settings otherwise
+	var rlConfig *RateLimiterConfig
 	if config.RateLimiter != nil || config.RateLimit > 0 {
 		// Validate rate limit duration
 		if config.RateLimit < 0 {
 			return nil, fmt.Errorf("invalid rate limit duration: %v (must be >= 0)", config.RateLimit)
 		}
-		var rlConfig *RateLimiterConfig
 		if config.RateLimiter != nil {
 			rlConfig = config.RateLimiter
 		} else {
@@ -258,8 +364,8 @@ func NewEngine(config *Config) (*Engine, error) {
 				MinChangeThreshold:  DefaultMinChangeThreshold,
 			}
 		}
-		engine.rateLimiter = NewAdaptiveRateLimiter(rlConfig)
 	}
+	engine.rateLimiters = newHostRateLimiters(rlConfig, config.HostRateLimits)
 
 	// Configure error recovery if specified
 	if config.ErrorRecovery != nil && config.ErrorRecovery.Enabled {
@@ -274,28 +380,7 @@ func NewEngine(config *Config) (*Engine, error) {
 
 		// Configure fallbacks
 		for operationName, fbSpec := range config.ErrorRecovery.Fallbacks {
-			var strategy errors.FallbackStrategy
-			switch fbSpec.Strategy {
-			case "cached":
-				strategy = errors.FallbackCached
-			case "default":
-				strategy = errors.FallbackDefault
-			case "alternative":
-				strategy = errors.FallbackAlternative
-			case "degrade":
-				strategy = errors.FallbackDegrade
-			default:
-				strategy = errors.FallbackNone
-			}
-
-			fallbackConfig := errors.FallbackConfig{
-				Strategy:     strategy,
-				CacheTimeout: fbSpec.CacheTimeout,
-				DefaultValue: fbSpec.DefaultValue,
-				Alternative:  fbSpec.Alternative,
-				Degraded:     fbSpec.Degraded,
-			}
-			engine.errorService.ConfigureFallback(operationName, fallbackConfig)
+			engine.errorService.ConfigureFallback(operationName, fbSpec.fallbackConfig(http.Header(config.Headers)))
 		}
 	}
 
@@ -320,10 +405,23 @@ func (e *Engine) Scrape(ctx context.Context, url string, extractors []FieldConfi
 	
 	result.Timestamp = time.Now()
 	
-	// Use circuit breaker to prevent cascading failures
-	circuitErr := e.circuitBreaker.Execute(func() error {
-		return e.performScrapeOperation(ctx, url, extractors, result)
-	})
+	// Abandoned hosts, robots.txt exclusions, hosts at max_pages_per_host and warmup failures abort before
+	// the page is requested; otherwise use the circuit breaker to prevent cascading failures
+	var circuitErr error
+	if host := requestHost(url); e.hostBlocks.isAbandoned(host) {
+		circuitErr = fmt.Errorf("%w: %s", ErrHostAbandoned, host)
+	} else if err := e.checkRobots(ctx, url); err != nil {
+		circuitErr = err
+	} else if !e.hostPages.reserve(host) {
+		circuitErr = fmt.Errorf("%w: %s", ErrHostPageLimit, host)
+	} else {
+		circuitErr = e.warmup(ctx)
+	}
+	if circuitErr == nil {
+		circuitErr = e.circuitBreakers.get(requestHost(url)).Execute(func() error {
+			return e.performScrapeOperation(ctx, url, extractors, result)
+		})
+	}
 	
 	if circuitErr != nil {
 		result.Error = circuitErr
@@ -343,13 +441,67 @@ func (e *Engine) Scrape(ctx context.Context, url string, extractors []FieldConfi
 	return resultCopy, nil
 }
 
-// performScrapeOperation performs the actual scraping operation
+// performScrapeOperation performs the actual scraping operation, re-fetching pages
+// that come back empty when retry_on_empty is set
 func (e *Engine) performScrapeOperation(ctx context.Context, url string, extractors []FieldConfig, result *Result) error {
+	emptyRetries, malformedRetries := 0, 0
+	for attempt := 0; ; attempt++ {
+		missed, malformed, err := e.fetchAndExtract(ctx, url, extractors, result)
+		empty := err == nil && attempt < e.emptyRetryLimit() && e.isEmptyResult(extractors, missed)
+		broken := err == nil && attempt < e.malformedRetryLimit() && malformed && len(missed) > 0
+		if !empty && !broken {
+			if emptyRetries > 0 {
+				result.Warnings = append(result.Warnings,
+					fmt.Sprintf("Re-fetched %d time(s) after an empty result", emptyRetries))
+			}
+			if malformedRetries > 0 {
+				result.Warnings = append(result.Warnings,
+					fmt.Sprintf("Re-fetched %d time(s) after malformed HTML", malformedRetries))
+			}
+			return err
+		}
+
+		if empty {
+			emptyRetries++
+			e.requestStats.recordEmptyRetry()
+		} else {
+			malformedRetries++
+		}
+		resetResult(result)
+	}
+}
+
+// fetchAndExtract fetches url once and extracts fields into result, returning the
+// names of fields that missed and whether malformed_html flagged the page
+func (e *Engine) fetchAndExtract(ctx context.Context, url string, extractors []FieldConfig, result *Result) (map[string]bool, bool, error) {
+	// Capture the raw response so a failed extraction can be diagnosed afterwards,
+	// and so malformed_html can inspect the markup as sent
+	var snap *responseSnapshot
+	if (e.config.Debug != nil && e.config.Debug.SaveFailedBodies != "") || e.config.MalformedHTML != nil {
+		snap = &responseSnapshot{}
+		ctx = withSnapshot(ctx, snap)
+	}
+
+	// Header fields read from the response, so capture headers unless a caller already is
+	if hasHeaderFields(extractors) && ctx.Value(responseHeadersKey{}) == nil {
+		ctx = withResponseHeaders(ctx, new(http.Header))
+	}
+
+	// Record which proxy served the page when _meta should name it
+	var choice *proxyChoice
+	if e.config.Proxy != nil && e.config.Proxy.IncludeInMeta {
+		choice = &proxyChoice{}
+		ctx = withProxyChoice(ctx, choice)
+	}
+
 	// Execute with comprehensive error recovery
-	recoveryResult := e.errorService.ExecuteWithRecovery(ctx, "fetch_document", func() (interface{}, error) {
+	// Scope recovery to the host so breaker and cached fallback state never leak across sites
+	operationName := errors.ScopedOperation("fetch_document", requestHost(url))
+	ctx = errors.WithPageURL(ctx, url) // For an api_fallback's {url}, {host} and {path}
+	recoveryResult := e.errorService.ExecuteWithRecovery(ctx, operationName, func() (interface{}, error) {
 		doc, err := e.fetchDocument(ctx, url)
 		return doc, err
-	})
+	}, errors.WithDNSRetries(e.config.DNSRetries), errors.WithJitter(e.config.RetryJitter))
 
 	if !recoveryResult.Success {
 		result.Error = recoveryResult.OriginalError
@@ -357,7 +509,13 @@ func (e *Engine) performScrapeOperation(ctx context.Context, url string, extract
 		if recoveryResult.UsedFallback {
 			result.Warnings = append(result.Warnings, fmt.Sprintf("Used fallback strategy: %s", recoveryResult.FallbackType))
 		}
-		return fmt.Errorf("failed to fetch document after %d attempts: %w", recoveryResult.AttemptCount, recoveryResult.OriginalError)
+		return nil, false, fmt.Errorf("failed to fetch document after %d attempts: %w", recoveryResult.AttemptCount, recoveryResult.OriginalError)
+	}
+
+	// A fallback such as api_fallback may hand back the fields' data instead of a page
+	if data, ok := recoveryResult.Result.(map[string]interface{}); ok && recoveryResult.UsedFallback {
+		result.Warnings = append(result.Warnings, fmt.Sprintf("Used fallback strategy: %s", recoveryResult.FallbackType))
+		return fallbackFields(data, extractors, result), false, nil
 	}
 
 	var doc *goquery.Document
@@ -366,50 +524,179 @@ func (e *Engine) performScrapeOperation(ctx context.Context, url string, extract
 		err := fmt.Errorf("unexpected result type from document fetch")
 		result.Error = err
 		result.Errors = append(result.Errors, err.Error())
-		return err
+		return nil, false, err
 	}
 
-	// Extract fields with error tracking
-	successCount := 0
+	if meta := proxyMeta(choice); meta != nil {
+		result.Data[MetaField] = meta
+	}
+
+	if e.config.CanonicalURL != nil {
+		result.CanonicalURL = e.config.CanonicalURL.pageURL(url, doc)
+	}
+
+	newHiddenContent(e.config.HiddenContent).expandNoscript(doc)
+
+	missed, successCount, requiredFailed := e.extractFields(ctx, doc, extractors, result)
 	totalFields := len(extractors)
 
+	// Calculate success metrics
+	if totalFields > 0 {
+		result.ErrorRate = float64(totalFields-successCount) / float64(totalFields)
+		result.Success = successCount > 0 // Partial success if any field extracted
+	}
+
+	malformed := e.checkMalformedHTML(snap, result, successCount, totalFields)
+
+	if snap != nil && (requiredFailed || !result.Success) {
+		e.saveFailedBody(snap, "extraction failed")
+	}
+
+	return missed, malformed, nil
+}
+
+// extractFields fills result.Data from doc with error tracking, returning the
+// fields that missed, how many were extracted and whether a required one failed
+func (e *Engine) extractFields(ctx context.Context, doc *goquery.Document, extractors []FieldConfig, result *Result) (map[string]bool, int, bool) {
+	successCount := 0
+	requiredFailed := false
+	missed := make(map[string]bool)
+
 	for _, extractor := range extractors {
-		value, err := e.extractField(doc, extractor)
+		raw, extractErr := e.extractWithTimeout(ctx, doc, responseHeadersFromContext(ctx), extractor)
+		value, usedDefault, err := e.processField(ctx, extractor, raw, extractErr)
+
+		// Extraction errors are reported even when a default fills the field
+		if extractErr != nil {
+			missed[extractor.Name] = true
+			result.Errors = append(result.Errors, fmt.Sprintf("Field '%s': %s", extractor.Name, extractErr.Error()))
+		}
 		if err != nil {
-			errorMsg := fmt.Sprintf("Field '%s': %s", extractor.Name, err.Error())
-			result.Errors = append(result.Errors, errorMsg)
+			missed[extractor.Name] = true
+			if extractor.Required {
+				requiredFailed = true
+			}
+			if err != extractErr {
+				result.Errors = append(result.Errors, fmt.Sprintf("Field '%s': %s", extractor.Name, err.Error()))
+			}
+			continue
+		}
 
-			// Use default value if available and not required
-			if !extractor.Required && extractor.Default != nil {
-				result.Data[extractor.Name] = extractor.Default
-				result.Warnings = append(result.Warnings,
-					fmt.Sprintf("Used default value for field '%s'", extractor.Name))
-				successCount++
+		if usedDefault {
+			result.Warnings = append(result.Warnings,
+				fmt.Sprintf("Used default value for field '%s'", extractor.Name))
+		}
+		result.Data[extractor.Name] = value
+		successCount++
+	}
+	return missed, successCount, requiredFailed
+}
+
+// fallbackFields fills result from data a fallback returned, keyed by field
+// name, and returns the fields it did not supply
+func fallbackFields(data map[string]interface{}, extractors []FieldConfig, result *Result) map[string]bool {
+	missed := make(map[string]bool)
+	for _, extractor := range extractors {
+		value, ok := data[extractor.Name]
+		if !ok || value == nil {
+			missed[extractor.Name] = true
+			continue
+		}
+		result.Data[extractor.Name] = value
+	}
+	if len(extractors) > 0 {
+		result.ErrorRate = float64(len(missed)) / float64(len(extractors))
+		result.Success = len(missed) < len(extractors)
+	}
+	return missed
+}
+
+// emptyRetryLimit returns how many times an empty page is re-fetched
+func (e *Engine) emptyRetryLimit() int {
+	if !e.config.RetryOnEmpty {
+		return 0
+	}
+	if e.config.MaxRetries > 0 {
+		return e.config.MaxRetries
+	}
+	return DefaultEmptyRetries
+}
+
+// malformedRetryLimit returns how many times a malformed page is re-fetched
+func (e *Engine) malformedRetryLimit() int {
+	if e.config.MalformedHTML == nil || !e.config.MalformedHTML.Retry {
+		return 0
+	}
+	if e.config.MaxRetries > 0 {
+		return e.config.MaxRetries
+	}
+	return DefaultEmptyRetries
+}
+
+// isEmptyResult reports whether every field that signals real content missed.
+// Those are RetryOnEmptyFields if set, else the required fields, else all fields.
+func (e *Engine) isEmptyResult(extractors []FieldConfig, missed map[string]bool) bool {
+	watched := e.config.RetryOnEmptyFields
+	if len(watched) == 0 {
+		for _, extractor := range extractors {
+			if extractor.Required {
+				watched = append(watched, extractor.Name)
 			}
-		} else {
-			result.Data[extractor.Name] = value
-			successCount++
+		}
+	}
+	if len(watched) == 0 {
+		for _, extractor := range extractors {
+			watched = append(watched, extractor.Name)
 		}
 	}
 
-	// Calculate success metrics
-	if totalFields > 0 {
-		result.ErrorRate = float64(totalFields-successCount) / float64(totalFields)
-		result.Success = successCount > 0 // Partial success if any field extracted
+	for _, name := range watched {
+		if !missed[name] {
+			return false
+		}
 	}
+	return len(watched) > 0
+}
 
-	return nil
+// resetResult clears a result before it is filled again
+func resetResult(result *Result) {
+	for k := range result.Data {
+		delete(result.Data, k)
+	}
+	result.Errors = result.Errors[:0]
+	result.Warnings = result.Warnings[:0]
+	result.Success = false
+	result.Error = nil
+	result.ErrorRate = 0
+	result.CanonicalURL = ""
 }
 
 // Enhanced fetchDocument method (existing logic preserved, browser automation added)
 func (e *Engine) fetchDocument(ctx context.Context, url string) (*goquery.Document, error) {
-	// Enhanced rate limiting with context support
-	if e.rateLimiter != nil {
-		if err := e.rateLimiter.Wait(ctx); err != nil {
+	// Enhanced rate limiting with context support, paced per host
+	if limiter := e.rateLimiters.get(requestHost(url)); limiter != nil {
+		waitStart := time.Now()
+		err := limiter.Wait(ctx)
+		e.requestStats.recordWait(time.Since(waitStart))
+		if err != nil {
 			return nil, fmt.Errorf("rate limiting failed: %w", err)
 		}
 	}
 
+	// A host can be abandoned while this URL waits or retries
+	if e.hostBlocks.isAbandoned(requestHost(url)) {
+		return nil, ErrHostAbandoned
+	}
+
+	if e.config.RespectCrawlDelay || e.config.RespectRobotsTxt {
+		if err := e.applyCrawlDelay(ctx, url); err != nil {
+			return nil, err
+		}
+	}
+
+	done := e.requestStats.begin()
+	defer done()
+
 	// Use browser automation if enabled
 	if e.browserManager != nil && e.browserManager.IsEnabled() {
 		return e.fetchDocumentWithBrowser(ctx, url)
@@ -426,6 +713,10 @@ func (e *Engine) fetchDocumentWithBrowser(ctx context.Context, url string) (*goq
 		return nil, fmt.Errorf("browser fetch failed: %w", err)
 	}
 
+	if err := e.bodyMatch.check([]byte(html)); err != nil {
+		return nil, err
+	}
+
 	doc, err := goquery.NewDocumentFromReader(strings.NewReader(html))
 	if err != nil {
 		return nil, fmt.Errorf("failed to parse HTML from browser: %w", err)
@@ -436,46 +727,34 @@ func (e *Engine) fetchDocumentWithBrowser(ctx context.Context, url string) (*goq
 
 // fetchDocumentWithHTTP uses HTTP client to fetch the document (existing logic preserved)
 func (e *Engine) fetchDocumentWithHTTP(ctx context.Context, url string) (*goquery.Document, error) {
-	// Get proxy if proxy manager is enabled
-	var proxyInstance *proxy.ProxyInstance
-	if e.proxyManager != nil && e.proxyManager.IsEnabled() {
-		var err error
-		proxyInstance, err = e.proxyManager.GetProxy()
-		if err != nil {
-			return nil, fmt.Errorf("failed to get proxy: %w", err)
-		}
-	}
+	limiter := e.rateLimiters.get(requestHost(url)) // Told how the host responded, for adaptive pacing
 
-	// Create HTTP client with proxy if available
-	client := e.httpClient
-	if proxyInstance != nil {
-		transport := &http.Transport{
-			Proxy: http.ProxyURL(proxyInstance.URL),
-		}
-		client = &http.Client{
-			Transport: transport,
-			Timeout:   e.config.Timeout,
-		}
+	client, proxyInstance, release, err := e.requestClient(ctx, url)
+	if err != nil {
+		return nil, err
 	}
+	defer release()
+	e.recordProxyChoice(ctx, url, proxyInstance)
 
-	// Existing request creation preserved
-	req, err := http.NewRequestWithContext(ctx, "GET", url, nil)
+	req, err := e.newPageRequest(ctx, url)
 	if err != nil {
 		return nil, fmt.Errorf("failed to create request: %w", err)
 	}
 
 	// Existing header setting preserved
 	req.Header.Set("User-Agent", e.getUserAgent())
-	for key, value := range e.config.Headers {
-		req.Header.Set(key, value)
+	// Referer follows the navigation chain; an explicit headers.Referer below overrides it
+	if referer := refererFromContext(ctx); referer != "" {
+		req.Header.Set("Referer", referer)
 	}
+	setConfiguredHeaders(req.Header, e.config.Headers)
 
 	// Execute request with proxy-aware client
 	resp, err := client.Do(req)
 	if err != nil {
 		// Report rate limiter failure for adaptive behavior
-		if e.rateLimiter != nil {
-			e.rateLimiter.ReportError()
+		if limiter != nil {
+			limiter.ReportError()
 		}
 		// Report proxy failure if proxy was used
 		if proxyInstance != nil {
@@ -485,11 +764,43 @@ func (e *Engine) fetchDocumentWithHTTP(ctx context.Context, url string) (*goquer
 	}
 	defer resp.Body.Close()
 
+	if holder, ok := ctx.Value(responseHeadersKey{}).(*http.Header); ok && holder != nil {
+		*holder = resp.Header.Clone()
+	}
+
+	// Keep the raw exchange when debugging failed pages or matching bodies;
+	// goquery parses from the copy
+	var body io.Reader = resp.Body
+	var raw []byte
+	snap := snapshotFromContext(ctx)
+	if snap != nil || e.bodyMatch != nil {
+		raw, err = io.ReadAll(resp.Body)
+		if err != nil {
+			return nil, fmt.Errorf("failed to read response body: %w", err)
+		}
+		body = bytes.NewReader(raw)
+	}
+	if snap != nil {
+		snap.Method = req.Method
+		snap.URL = url
+		snap.StatusCode = resp.StatusCode
+		snap.RequestHeaders = req.Header.Clone()
+		snap.Body = raw
+
+		if isBlockStatus(resp.StatusCode) {
+			e.saveFailedBody(snap, fmt.Sprintf("blocked (HTTP %d)", resp.StatusCode))
+		}
+	}
+
+	if isBlockStatus(resp.StatusCode) {
+		e.recordBlock(url, resp.StatusCode)
+	}
+
 	// Existing status code handling preserved
 	if resp.StatusCode >= 400 {
 		// Report rate limiter failure for adaptive behavior
-		if e.rateLimiter != nil {
-			e.rateLimiter.ReportError()
+		if limiter != nil {
+			limiter.ReportError()
 		}
 		// Report proxy failure for client errors when using proxy
 		if proxyInstance != nil {
@@ -499,9 +810,24 @@ func (e *Engine) fetchDocumentWithHTTP(ctx context.Context, url string) (*goquer
 		return nil, fmt.Errorf("HTTP error %d: %s", resp.StatusCode, resp.Status)
 	}
 
+	// A "try again later" body counts against the server like a 5xx would;
+	// a permanent match is a working server with a page we do not want
+	if err := e.bodyMatch.check(raw); err != nil {
+		if !errors.IsPermanent(err) {
+			if limiter != nil {
+				limiter.ReportError()
+			}
+			if proxyInstance != nil {
+				e.proxyManager.ReportFailure(proxyInstance, err)
+			}
+		}
+		e.saveFailedBody(snap, err.Error())
+		return nil, err
+	}
+
 	// Report success for adaptive rate limiting
-	if e.rateLimiter != nil {
-		e.rateLimiter.ReportSuccess()
+	if limiter != nil {
+		limiter.ReportSuccess()
 	}
 	// Report proxy success if proxy was used
 	if proxyInstance != nil {
@@ -509,7 +835,7 @@ func (e *Engine) fetchDocumentWithHTTP(ctx context.Context, url string) (*goquer
 	}
 
 	// Existing document parsing preserved
-	doc, err := goquery.NewDocumentFromReader(resp.Body)
+	doc, err := goquery.NewDocumentFromReader(body)
 	if err != nil {
 		return nil, fmt.Errorf("failed to parse HTML: %w", err)
 	}
@@ -517,9 +843,147 @@ func (e *Engine) fetchDocumentWithHTTP(ctx context.Context, url string) (*goquer
 	return doc, nil
 }
 
+// requestClient returns the client for a request to url: the engine's own, or
+// one through the proxy picked for url (its host group, else the worker's
+// affinity, else rotation) when proxies are enabled. The proxy is returned for
+// reporting how it did, and release must be called once the response is done.
+func (e *Engine) requestClient(ctx context.Context, url string) (*http.Client, *proxy.ProxyInstance, func(), error) {
+	if e.proxyManager == nil || !e.proxyManager.IsEnabled() {
+		return e.httpClient, nil, func() {}, nil
+	}
+
+	var proxyInstance *proxy.ProxyInstance
+	var err error
+	// Hosts mapped by host_groups only use their group, ahead of worker affinity
+	if group := e.proxyManager.GroupForURL(url); group != "" {
+		proxyInstance, err = e.proxyManager.GetProxyFromGroup(group)
+	} else if workerID, ok := workerIDFromContext(ctx); ok {
+		proxyInstance, err = e.proxyManager.GetProxyForWorker(workerID)
+	} else {
+		proxyInstance, err = e.proxyManager.GetProxy()
+	}
+	if err != nil {
+		return nil, nil, nil, fmt.Errorf("failed to get proxy: %w", err)
+	}
+
+	transport := newTransport(e.config.Transport, e.dialTLS, proxyInstance.URL, e.wrapDial)
+	client := &http.Client{
+		Transport: e.session.wrap(withDecompression(transport, e.config.DisableCompression), proxyInstance.URL),
+		Timeout:   e.config.Timeout,
+		Jar:       e.httpClient.Jar,
+	}
+	// The transport is discarded after the request, so its pooled connections can never be reused
+	return client, proxyInstance, transport.CloseIdleConnections, nil
+}
+
+// newTransport builds the HTTP transport, applying the per-phase deadlines from
+// tc so a stalled dial, handshake or response trips before the overall timeout.
+// dialTLS, if set, replaces the TLS handshake for direct (non-proxied) HTTPS.
+// wrapDial, if set, decorates both dial paths (connection cap, header ordering).
+// A SOCKS5 proxyURL is dialed through rather than set as the transport's proxy,
+// so socks5 can resolve target names locally.
+func newTransport(tc *TransportConfig, dialTLS dialTLSFunc, proxyURL *url.URL, wrapDial dialWrapper) *http.Transport {
+	transport := &http.Transport{
+		MaxIdleConns:        100,
+		MaxIdleConnsPerHost: 10,
+		IdleConnTimeout:     90 * time.Second,
+	}
+	socks := proxyURL != nil && isSOCKSProxy(proxyURL)
+	if proxyURL != nil && !socks {
+		transport.Proxy = http.ProxyURL(proxyURL)
+	}
+	if dialTLS != nil && !socks {
+		transport.DialTLSContext = dialTLS
+	}
+
+	if tc != nil {
+		if tc.DialTimeout > 0 {
+			dialer := &net.Dialer{Timeout: tc.DialTimeout, KeepAlive: 30 * time.Second}
+			transport.DialContext = dialer.DialContext
+		}
+		transport.TLSHandshakeTimeout = tc.TLSHandshakeTimeout
+		transport.ResponseHeaderTimeout = tc.ResponseHeaderTimeout
+	}
+
+	if socks {
+		dial := transport.DialContext
+		if dial == nil {
+			dial = (&net.Dialer{KeepAlive: 30 * time.Second}).DialContext
+		}
+		transport.DialContext = socksDial(proxyURL, dial)
+	}
+
+	if wrapDial != nil {
+		dial := transport.DialContext
+		if dial == nil {
+			dial = (&net.Dialer{KeepAlive: 30 * time.Second}).DialContext
+		}
+		transport.DialContext = wrapDial(dial)
+		if transport.DialTLSContext != nil {
+			transport.DialTLSContext = wrapDial(transport.DialTLSContext)
+		}
+	}
+
+	return transport
+}
+
+// dialWrapper decorates a dial function, e.g. to limit or rewrite connections
+type dialWrapper func(dialFunc) dialFunc
+
+// chainDialWrappers applies wrappers in order, innermost first; nil if there are none
+func chainDialWrappers(wrappers ...dialWrapper) dialWrapper {
+	if len(wrappers) == 0 {
+		return nil
+	}
+	return func(dial dialFunc) dialFunc {
+		for _, wrap := range wrappers {
+			dial = wrap(dial)
+		}
+		return dial
+	}
+}
+
+// fallbackConfig converts the spec, and any chain it holds, to the error service
+// form; headers are the engine's, sent with api_fallback requests
+func (fs FallbackSpec) fallbackConfig(headers http.Header) errors.FallbackConfig {
+	var strategy errors.FallbackStrategy
+	switch fs.Strategy {
+	case "cached":
+		strategy = errors.FallbackCached
+	case "default":
+		strategy = errors.FallbackDefault
+	case "alternative":
+		strategy = errors.FallbackAlternative
+	case "degrade":
+		strategy = errors.FallbackDegrade
+	default:
+		strategy = errors.FallbackNone
+	}
+
+	fallbackConfig := errors.FallbackConfig{
+		Strategy:     strategy,
+		CacheTimeout: fs.CacheTimeout,
+		DefaultValue: fs.DefaultValue,
+		Alternative:  fs.Alternative,
+		Degraded:     fs.Degraded,
+		APIURL:       fs.APIURL,
+		APITimeout:   fs.APITimeout,
+		Headers:      headers,
+	}
+	for _, step := range fs.Chain {
+		fallbackConfig.Chain = append(fallbackConfig.Chain, step.fallbackConfig(headers))
+	}
+	return fallbackConfig
+}
+
 // Enhanced extractField method (existing logic preserved, error handling improved)
 func (e *Engine) extractField(doc *goquery.Document, extractor FieldConfig) (interface{}, error) {
-	selection := doc.Find(extractor.Selector)
+	hidden := newHiddenContent(e.config.HiddenContent)
+	matched, err := selectNodes(doc, extractor.Selector, extractor.SelectorType)
+	if err != nil {
+		return nil, err
+	}
+	selection := hidden.visible(matched)
 	if selection.Length() == 0 {
 		return nil, fmt.Errorf("no elements found for selector: %s", extractor.Selector)
 	}
@@ -527,7 +991,7 @@ func (e *Engine) extractField(doc *goquery.Document, extractor FieldConfig) (int
 	// Existing extraction logic preserved
 	switch extractor.Type {
 	case "text":
-		text := strings.TrimSpace(selection.First().Text())
+		text := e.cleanText(hidden.text(selection.First()))
 		if text == "" && extractor.Required {
 			return nil, fmt.Errorf("required field is empty")
 		}
@@ -537,7 +1001,7 @@ func (e *Engine) extractField(doc *goquery.Document, extractor FieldConfig) (int
 		if extractor.Attribute == "" {
 			return nil, fmt.Errorf("attribute name required for attr type")
 		}
-		attr, exists := selection.First().Attr(extractor.Attribute)
+		attr, exists := e.config.LazyImages.attr(selection.First(), extractor.Attribute)
 		if !exists && extractor.Required {
 			return nil, fmt.Errorf("required attribute '%s' not found", extractor.Attribute)
 		}
@@ -550,18 +1014,77 @@ func (e *Engine) extractField(doc *goquery.Document, extractor FieldConfig) (int
 		}
 		return html, nil
 
+	case "embedded_json":
+		return extractEmbeddedJSON(selection.First(), extractor.Path)
+
 	case "array", "list":
 		var items []string
 		selection.Each(func(i int, s *goquery.Selection) {
-			items = append(items, strings.TrimSpace(s.Text()))
+			items = append(items, e.cleanText(hidden.text(s)))
 		})
 		return items, nil
 
+	case "table":
+		return extractTable(selection, extractor.Columns, func(s *goquery.Selection) string {
+			return e.cleanText(hidden.text(s))
+		})
+
 	default:
 		return nil, fmt.Errorf("unsupported extraction type: %s", extractor.Type)
 	}
 }
 
+// cleanText trims extracted text, normalizing unicode and whitespace unless normalize_text is false
+func (e *Engine) cleanText(text string) string {
+	if e.config.NormalizeText != nil && !*e.config.NormalizeText {
+		return strings.TrimSpace(text)
+	}
+	return pipeline.NormalizeText(text)
+}
+
+// hasHeaderFields reports whether any field is extracted from response headers
+func hasHeaderFields(extractors []FieldConfig) bool {
+	for _, extractor := range extractors {
+		if extractor.Type == "header" {
+			return true
+		}
+		for _, source := range extractor.Sources {
+			if source.Type == SourceHeader {
+				return true
+			}
+		}
+	}
+	return false
+}
+
+// extractHeaderField returns the response header named by the field's selector.
+// Lookup is case-insensitive; repeated headers are joined with ", ".
+func extractHeaderField(headers http.Header, extractor FieldConfig) (interface{}, error) {
+	if headers == nil {
+		return nil, fmt.Errorf("response headers not available for header field")
+	}
+
+	values := headers.Values(extractor.Selector)
+	if len(values) == 0 {
+		return nil, fmt.Errorf("response header not found: %s", extractor.Selector)
+	}
+	// Cookie attributes such as Expires contain commas, so Set-Cookie lines cannot be joined
+	if len(values) > 1 && http.CanonicalHeaderKey(extractor.Selector) == "Set-Cookie" {
+		return append([]string(nil), values...), nil
+	}
+	return strings.Join(values, ", "), nil
+}
+
+// setConfiguredHeaders replaces each configured header on h, sending one line per value
+func setConfiguredHeaders(h http.Header, headers map[string][]string) {
+	for key, values := range headers {
+		h.Del(key)
+		for _, value := range values {
+			h.Add(key, value)
+		}
+	}
+}
+
 // Enhanced getUserAgent method (existing logic preserved)
 func (e *Engine) getUserAgent() string {
 	// Existing user agent rotation logic preserved
@@ -569,6 +1092,8 @@ func (e *Engine) getUserAgent() string {
 		return "DataScrapexter/1.0"
 	}
 
+	e.uaMu.Lock()
+	defer e.uaMu.Unlock()
 	ua := e.userAgentPool[e.currentUAIndex]
 	e.currentUAIndex = (e.currentUAIndex + 1) % len(e.userAgentPool)
 	return ua
@@ -600,6 +1125,15 @@ func (e *Engine) GetUserFriendlyError(err error) (title, message string, suggest
 	return e.errorService.GetUserFriendlyError(err)
 }
 
+// SaveCookies writes the cookie jar to cookie_jar_file so the next run starts
+// with this run's cookies; it does nothing unless cookie_jar_file is set
+func (e *Engine) SaveCookies() error {
+	if e.cookies == nil {
+		return nil
+	}
+	return e.cookies.Save()
+}
+
 // Close closes the scraper engine and releases resources
 func (e *Engine) Close() error {
 	if e.browserManager != nil {
@@ -613,26 +1147,51 @@ func (e *Engine) IsBrowserEnabled() bool {
 	return e.browserManager != nil && e.browserManager.IsEnabled()
 }
 
-// GetRateLimiterStats returns current rate limiter statistics
+// GetRateLimiterStats returns rate limiter statistics combined across hosts
 func (e *Engine) GetRateLimiterStats() *RateLimiterStats {
-	if e.rateLimiter == nil {
+	if e.rateLimiters == nil {
 		return nil
 	}
-	return e.rateLimiter.GetStats()
+	return e.rateLimiters.stats()
+}
+
+// GetHostRateLimiterStats returns the rate limiter statistics for a single host,
+// or nil when the host is not limited or has not been requested yet
+func (e *Engine) GetHostRateLimiterStats(host string) *RateLimiterStats {
+	if e.rateLimiters == nil {
+		return nil
+	}
+	var stats *RateLimiterStats
+	e.rateLimiters.each(func(h string, rl *AdaptiveRateLimiter) {
+		if h == host {
+			stats = rl.GetStats()
+		}
+	})
+	return stats
+}
+
+// GetRequestStats returns the observed request rate, limiter wait and peak in-flight requests
+func (e *Engine) GetRequestStats() RequestStats {
+	stats := e.requestStats.snapshot()
+	stats.HostPages, stats.HostPageSkipped = e.hostPages.snapshot()
+	return stats
+}
+
+// GetRequestLatency returns the histogram of request durations
+func (e *Engine) GetRequestLatency() LatencyHistogram {
+	return e.requestStats.latencySnapshot()
 }
 
 // SetRateLimitStrategy changes the rate limiting strategy
 func (e *Engine) SetRateLimitStrategy(strategy RateLimitStrategy) {
-	if e.rateLimiter != nil {
-		e.rateLimiter.SetStrategy(strategy)
-	}
+	e.rateLimiters.setStrategy(strategy)
 }
 
 // ResetRateLimiter resets rate limiter statistics
 func (e *Engine) ResetRateLimiter() {
-	if e.rateLimiter != nil {
-		e.rateLimiter.Reset()
-	}
+	e.rateLimiters.each(func(_ string, rl *AdaptiveRateLimiter) {
+		rl.Reset()
+	})
 }
 
 // ConfigureErrorRecovery configures error recovery mechanisms
@@ -659,19 +1218,62 @@ func (e *Engine) GetErrorRecoveryStats() map[string]interface{} {
 	return map[string]interface{}{
 		"circuit_breakers": e.errorService.GetCircuitBreakerStats(),
 		"cache":            e.errorService.GetCacheStats(),
+		"errors":           e.errorService.GetErrorMetrics(),
+	}
+}
+
+// GetErrorMetrics returns the retry, fallback and failure counts of the engine's fetches
+func (e *Engine) GetErrorMetrics() errors.ErrorMetrics {
+	if e.errorService == nil {
+		return errors.ErrorMetrics{}
 	}
+	return e.errorService.GetErrorMetrics()
 }
 
 // ResetErrorRecovery resets all error recovery mechanisms
 func (e *Engine) ResetErrorRecovery() {
 	if e.errorService != nil {
 		e.errorService.ClearCache()
-		// Reset circuit breakers by getting their names and resetting each
-		stats := e.errorService.GetCircuitBreakerStats()
-		for name := range stats {
-			e.errorService.ResetCircuitBreaker(name)
+		e.errorService.ResetAll()
+	}
+	if e.circuitBreakers != nil {
+		e.circuitBreakers.resetAll()
+	}
+}
+
+// ResetCircuitBreakers closes the error service's and every host's circuit
+// breakers, keeping cached fallback results, and returns how many were reset
+func (e *Engine) ResetCircuitBreakers() int {
+	reset := 0
+	if e.errorService != nil {
+		reset += e.errorService.ResetAll()
+	}
+	if e.circuitBreakers != nil {
+		reset += e.circuitBreakers.resetAll()
+	}
+	return reset
+}
+
+// WatchConfig resets the engine's circuit breakers whenever watcher reloads a
+// valid configuration, so operations that failed under the old configuration
+// are tried again at once instead of waiting out their breakers
+func (e *Engine) WatchConfig(watcher *config.ConfigWatcher) {
+	watcher.OnChangeWithContext(func(ctx context.Context, cfg *config.ScraperConfig, err error) {
+		if err != nil || cfg == nil {
+			return
+		}
+		if reset := e.ResetCircuitBreakers(); reset > 0 {
+			utils.GetLogger("scraper").Infof("Configuration reloaded; reset %d circuit breaker(s)", reset)
 		}
+	})
+}
+
+// GetProxyStatusReport returns the per-proxy status view, or nil when proxies are not configured
+func (e *Engine) GetProxyStatusReport() []proxy.ProxyStatusReport {
+	if e.proxyManager == nil {
+		return nil
 	}
+	return e.proxyManager.StatusReport()
 }
 
 // ScrapeWithPagination scrapes multiple pages based on pagination configuration
@@ -700,6 +1302,17 @@ func (e *Engine) ScrapeWithPagination(ctx context.Context, baseURL string, extra
 		}, nil
 	}
 
+	if err := e.warmup(ctx); err != nil {
+		return nil, err
+	}
+
+	if e.config.Pagination.Type == PaginationTypeSitemap {
+		return e.scrapeSitemapPages(ctx, extractors)
+	}
+	if e.config.Pagination.Type == PaginationTypeInfiniteScroll {
+		return e.scrapeInfiniteScroll(ctx, baseURL, extractors)
+	}
+
 	// Create pagination manager
 	paginationManager, err := NewPaginationManager(*e.config.Pagination)
 	if err != nil {
@@ -711,15 +1324,52 @@ func (e *Engine) ScrapeWithPagination(ctx context.Context, baseURL string, extra
 	errors := make([]string, 0)
 
 	currentURL := baseURL
-	pageNum := 0 // Start from 0 for offset-based pagination
+	previousURL := "" // Seed page is fetched without a Referer
+	pageNum := 0      // Start from 0 for offset-based pagination
+	firstPage := e.config.Pagination.StartPage // The number ${page} in a request body starts at
+	if firstPage <= 0 {
+		firstPage = 1
+	}
 	maxPages := e.config.Pagination.MaxPages
 	if maxPages <= 0 {
 		maxPages = 10 // Default safety limit
 	}
+	visited := newVisitedURLs(e.config.CanonicalURL)
+
+	// Under WithCheckpoint every page is saved, and a URL with saved progress
+	// goes on from its last page instead of starting again
+	checkpoint := checkpointFromContext(ctx)
+	if checkpoint != nil {
+		if progress := checkpoint.progress(baseURL); progress != nil {
+			if results, err = progress.Results(); err != nil {
+				return nil, err
+			}
+			errors = progress.Errors
+			pageNum = progress.PageNum
+			currentURL = progress.URL
+			previousURL = progress.Referer
+			for _, page := range results {
+				visited.visit(page.URL)
+				visited.mark(page.CanonicalURL)
+			}
+			visited.visit(currentURL)
+		}
+	}
+	recordPage := func(page *ScrapingResult, errorMsg string) {
+		if checkpoint == nil {
+			return
+		}
+		if err := checkpoint.recordPage(baseURL, page, errorMsg, pageNum, currentURL, previousURL); err != nil {
+			utils.GetLogger("scraper").Warnf("Failed to checkpoint page %d of %s: %v", pageNum, baseURL, err)
+		}
+	}
 
 	for pageNum < maxPages {
 		// Handle offset-based pagination separately
 		if e.config.Pagination.Type == PaginationTypeOffset {
+			if pageNum > 0 {
+				previousURL = currentURL
+			}
 			// Calculate the next URL directly using the offset
 			offset := pageNum * e.config.Pagination.PageSize
 			offsetParam := e.config.Pagination.OffsetParam
@@ -732,8 +1382,11 @@ func (e *Engine) ScrapeWithPagination(ctx context.Context, baseURL string, extra
 			}
 			currentURL = fmt.Sprintf("%s?%s=%d&%s=%d", baseURL, offsetParam, offset, limitParam, e.config.Pagination.PageSize)
 		} else if pageNum > 0 {
-			// For other pagination types, fetch the document to determine the next URL
-			doc, err := e.fetchDocument(ctx, currentURL)
+			// For other pagination types, fetch the document to determine the next URL.
+			// Response headers are captured for strategies that paginate on them.
+			var headers http.Header
+			pageCtx := withResponseHeaders(ctx, &headers)
+			doc, err := e.fetchDocument(WithPageNumber(WithReferer(pageCtx, previousURL), firstPage+pageNum-1), currentURL)
 			if err != nil {
 				errorMsg := fmt.Sprintf("Failed to fetch document for pagination on page %d: %v", pageNum+1, err)
 				errors = append(errors, errorMsg)
@@ -741,12 +1394,12 @@ func (e *Engine) ScrapeWithPagination(ctx context.Context, baseURL string, extra
 			}
 
 			// Check if pagination is complete
-			if paginationManager.IsComplete(ctx, currentURL, doc, pageNum) {
+			if paginationManager.IsComplete(pageCtx, currentURL, doc, pageNum) {
 				break
 			}
 
 			// Get next URL
-			nextURL, err := paginationManager.GetNextURL(ctx, currentURL, doc, pageNum)
+			nextURL, err := paginationManager.GetNextURL(pageCtx, currentURL, doc, pageNum)
 			if err != nil {
 				errorMsg := fmt.Sprintf("Failed to get next URL on page %d: %v", pageNum+1, err)
 				errors = append(errors, errorMsg)
@@ -757,11 +1410,17 @@ func (e *Engine) ScrapeWithPagination(ctx context.Context, baseURL string, extra
 				break // No more pages
 			}
 
+			previousURL = currentURL
 			currentURL = nextURL
 		}
 
-		// Scrape current page
-		result, err := e.Scrape(ctx, currentURL, extractors)
+		// A next link back to a page already scraped means the pagination loops
+		if !visited.visit(currentURL) {
+			break
+		}
+
+		// Scrape current page, sending the page that linked to it as Referer
+		result, err := e.Scrape(WithPageNumber(WithReferer(ctx, previousURL), firstPage+pageNum), currentURL, extractors)
 		if err != nil {
 			errorMsg := fmt.Sprintf("Page %d failed: %v", pageNum+1, err)
 			errors = append(errors, errorMsg)
@@ -770,20 +1429,25 @@ func (e *Engine) ScrapeWithPagination(ctx context.Context, baseURL string, extra
 				break
 			}
 			pageNum++
+			recordPage(nil, errorMsg)
 			continue
 		}
 
+		visited.mark(result.CanonicalURL)
+
 		// Convert to ScrapingResult format
 		scrapingResult := ScrapingResult{
-			URL:        currentURL,
-			StatusCode: 200,
-			Data:       result.Data,
-			Success:    result.Success,
-			Errors:     result.Errors,
+			URL:          currentURL,
+			CanonicalURL: result.CanonicalURL,
+			StatusCode:   200,
+			Data:         result.Data,
+			Success:      result.Success,
+			Errors:       result.Errors,
 		}
 		results = append(results, scrapingResult)
 
 		pageNum++
+		recordPage(&scrapingResult, "")
 
 		// Add delay between pages if configured
 		if e.config.Pagination.DelayBetweenPages > 0 {
@@ -815,9 +1479,120 @@ func (e *Engine) GetMemoryStats() interface{} {
 	return e.memManager.GetMemoryStats()
 }
 
-// GetCircuitBreakerState returns the current circuit breaker state
+// GetCircuitBreakerState returns the most severe circuit breaker state across hosts
 func (e *Engine) GetCircuitBreakerState() int32 {
-	return e.circuitBreaker.GetState()
+	return e.circuitBreakers.worstState()
+}
+
+// GetHostCircuitBreakerState returns the circuit breaker state for a single host
+func (e *Engine) GetHostCircuitBreakerState(host string) int32 {
+	return e.circuitBreakers.get(host).GetState()
+}
+
+// CircuitBreakerStatus is the state of one of the engine's circuit breakers
+type CircuitBreakerStatus struct {
+	Scope string // "host" for the per-host breakers, "operation" for error recovery's
+	Name  string // The host, or the operation such as fetch_document:example.com
+	State int32  // utils.StateClosed, utils.StateOpen or utils.StateHalfOpen
+}
+
+// GetCircuitBreakerStatuses returns the state of every circuit breaker created so far
+func (e *Engine) GetCircuitBreakerStatuses() []CircuitBreakerStatus {
+	var statuses []CircuitBreakerStatus
+	for host, state := range e.circuitBreakers.states() {
+		statuses = append(statuses, CircuitBreakerStatus{Scope: "host", Name: host, State: state})
+	}
+	if e.errorService != nil {
+		for operation, state := range e.errorService.CircuitBreakerStates() {
+			statuses = append(statuses, CircuitBreakerStatus{Scope: "operation", Name: operation, State: int32(state)})
+		}
+	}
+	sort.Slice(statuses, func(i, j int) bool {
+		if statuses[i].Scope != statuses[j].Scope {
+			return statuses[i].Scope < statuses[j].Scope
+		}
+		return statuses[i].Name < statuses[j].Name
+	})
+	return statuses
+}
+
+// hostCircuitBreakers lazily creates a circuit breaker per host
+type hostCircuitBreakers struct {
+	mu           sync.Mutex
+	breakers     map[string]*utils.CircuitBreaker
+	maxFailures  int64
+	resetTimeout time.Duration
+}
+
+// newHostCircuitBreakers creates an empty per-host breaker set
+func newHostCircuitBreakers(maxFailures int64, resetTimeout time.Duration) *hostCircuitBreakers {
+	return &hostCircuitBreakers{
+		breakers:     make(map[string]*utils.CircuitBreaker),
+		maxFailures:  maxFailures,
+		resetTimeout: resetTimeout,
+	}
+}
+
+// get returns the breaker for host, creating it on first use
+func (h *hostCircuitBreakers) get(host string) *utils.CircuitBreaker {
+	h.mu.Lock()
+	defer h.mu.Unlock()
+
+	cb, ok := h.breakers[host]
+	if !ok {
+		cb = utils.NewCircuitBreaker(h.maxFailures, h.resetTimeout)
+		h.breakers[host] = cb
+	}
+	return cb
+}
+
+// worstState reports open if any host is open, else half-open if any is, else closed
+func (h *hostCircuitBreakers) worstState() int32 {
+	h.mu.Lock()
+	defer h.mu.Unlock()
+
+	worst := int32(utils.StateClosed)
+	for _, cb := range h.breakers {
+		switch cb.GetState() {
+		case utils.StateOpen:
+			return utils.StateOpen
+		case utils.StateHalfOpen:
+			worst = utils.StateHalfOpen
+		}
+	}
+	return worst
+}
+
+// states returns every host's breaker state
+func (h *hostCircuitBreakers) states() map[string]int32 {
+	h.mu.Lock()
+	defer h.mu.Unlock()
+
+	states := make(map[string]int32, len(h.breakers))
+	for host, cb := range h.breakers {
+		states[host] = cb.GetState()
+	}
+	return states
+}
+
+// resetAll closes every host's circuit breaker and returns how many there were
+func (h *hostCircuitBreakers) resetAll() int {
+	h.mu.Lock()
+	defer h.mu.Unlock()
+
+	for _, cb := range h.breakers {
+		cb.Reset()
+	}
+	return len(h.breakers)
+}
+
+// requestHost returns the host of rawURL, or rawURL itself if it cannot be parsed
+func requestHost(rawURL string) string {
+	u, err := url.Parse(rawURL)
+	if err != nil || u.Host == "" {
+		return rawURL
+	}
+	return u.Host
 }
 
 // ScrapeMultipleOptimized performs optimized batch scraping
@@ -827,11 +1602,11 @@ func (e *Engine) ScrapeMultipleOptimized(ctx context.Context, urls []string, ext
 	}
 	
 	// Use worker pool for efficient concurrent processing
-	workerPool := utils.NewWorkerPool[string](
-		concurrency, 
+	workerPool := utils.NewIndexedWorkerPool[string](
+		concurrency,
 		len(urls),
-		func(url string) (interface{}, error) {
-			return e.Scrape(ctx, url, extractors)
+		func(workerID int, url string) (interface{}, error) {
+			return e.Scrape(WithWorkerID(ctx, workerID), url, extractors)
 		},
 	)
 	
@@ -997,11 +1772,9 @@ func (e *Engine) ScrapeWithBatching(ctx context.Context, urls []string, extracto
 // OptimizeForThroughput optimizes engine settings for maximum throughput
 func (e *Engine) OptimizeForThroughput() {
 	// Increase HTTP client connection limits
-	if transport, ok := e.httpClient.Transport.(*http.Transport); ok {
-		transport.MaxIdleConns = 200
-		transport.MaxIdleConnsPerHost = 50
-		transport.IdleConnTimeout = 120 * time.Second
-	}
+	e.transport.MaxIdleConns = 200
+	e.transport.MaxIdleConnsPerHost = 50
+	e.transport.IdleConnTimeout = 120 * time.Second
 	
 	// Reset performance counters
 	e.perfMetrics.Reset()
@@ -1010,11 +1783,9 @@ func (e *Engine) OptimizeForThroughput() {
 // OptimizeForMemory optimizes engine settings for minimal memory usage
 func (e *Engine) OptimizeForMemory() {
 	// Reduce HTTP client connection limits
-	if transport, ok := e.httpClient.Transport.(*http.Transport); ok {
-		transport.MaxIdleConns = 50
-		transport.MaxIdleConnsPerHost = 5
-		transport.IdleConnTimeout = 30 * time.Second
-	}
+	e.transport.MaxIdleConns = 50
+	e.transport.MaxIdleConnsPerHost = 5
+	e.transport.IdleConnTimeout = 30 * time.Second
 }
 
 // checkErrorThresholds checks if error thresholds are exceeded and processing should stop
@@ -1054,6 +1825,7 @@ func (e *Engine) copyResult(src *Result) *Result {
 	dst.Error = src.Error
 	dst.Timestamp = src.Timestamp
 	dst.ErrorRate = src.ErrorRate
+	dst.CanonicalURL = src.CanonicalURL
 	
 	// Efficiently copy map - simple shallow copy since scraped data is typically flat
 	if len(dst.Data) > 0 {
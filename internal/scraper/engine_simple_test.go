@@ -3,10 +3,20 @@ package scraper
 
 import (
 	"context"
+	"errors"
+	"fmt"
 	"net/http"
 	"net/http/httptest"
+	"os"
+	"path/filepath"
+	"strings"
+	"sync/atomic"
 	"testing"
 	"time"
+
+	"github.com/valpere/DataScrapexter/internal/config"
+	recovery "github.com/valpere/DataScrapexter/internal/errors"
+	"github.com/valpere/DataScrapexter/internal/utils"
 )
 
 func TestNewEngineSimple(t *testing.T) {
@@ -29,6 +39,23 @@ func TestNewEngineSimple(t *testing.T) {
 	}
 }
 
+func TestEngineOptimizeTunesTransport(t *testing.T) {
+	engine, err := NewEngine(&Config{Timeout: 10 * time.Second, Debug: &DebugConfig{}})
+	if err != nil {
+		t.Fatalf("Failed to create scraping engine: %v", err)
+	}
+
+	// The transport sits under the session and decompression wrappers
+	engine.OptimizeForThroughput()
+	if engine.transport.MaxIdleConnsPerHost != 50 {
+		t.Errorf("OptimizeForThroughput: MaxIdleConnsPerHost = %d, want 50", engine.transport.MaxIdleConnsPerHost)
+	}
+	engine.OptimizeForMemory()
+	if engine.transport.MaxIdleConnsPerHost != 5 {
+		t.Errorf("OptimizeForMemory: MaxIdleConnsPerHost = %d, want 5", engine.transport.MaxIdleConnsPerHost)
+	}
+}
+
 func TestScrapeBasic(t *testing.T) {
 	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
 		w.WriteHeader(http.StatusOK)
@@ -84,3 +111,979 @@ func TestScrapeBasic(t *testing.T) {
 		t.Errorf("Expected content 'Test content', got %v", result.Data["content"])
 	}
 }
+
+func TestScrapeReferer(t *testing.T) {
+	var gotReferer string
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		gotReferer = r.Header.Get("Referer")
+		w.Write([]byte("<html><body><h1>Title</h1></body></html>"))
+	}))
+	defer server.Close()
+
+	fields := []FieldConfig{{Name: "title", Selector: "h1", Type: "text"}}
+
+	engine, err := NewEngine(&Config{Timeout: 10 * time.Second, RateLimit: 10 * time.Millisecond, BurstSize: 1})
+	if err != nil {
+		t.Fatalf("Failed to create engine: %v", err)
+	}
+
+	if _, err := engine.Scrape(context.Background(), server.URL, fields); err != nil {
+		t.Fatalf("Scraping failed: %v", err)
+	}
+	if gotReferer != "" {
+		t.Errorf("Expected no Referer for seed URL, got %q", gotReferer)
+	}
+
+	ctx := WithReferer(context.Background(), "https://example.com/list")
+	if _, err := engine.Scrape(ctx, server.URL, fields); err != nil {
+		t.Fatalf("Scraping failed: %v", err)
+	}
+	if gotReferer != "https://example.com/list" {
+		t.Errorf("Expected Referer from navigation chain, got %q", gotReferer)
+	}
+
+	engine, err = NewEngine(&Config{
+		Timeout:   10 * time.Second,
+		RateLimit: 10 * time.Millisecond,
+		BurstSize: 1,
+		Headers:   map[string][]string{"Referer": {"https://static.example.com/"}},
+	})
+	if err != nil {
+		t.Fatalf("Failed to create engine: %v", err)
+	}
+	if _, err := engine.Scrape(ctx, server.URL, fields); err != nil {
+		t.Fatalf("Scraping failed: %v", err)
+	}
+	if gotReferer != "https://static.example.com/" {
+		t.Errorf("Expected explicit Referer header to win, got %q", gotReferer)
+	}
+}
+
+func TestScrapeResponseHeaderTimeout(t *testing.T) {
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		time.Sleep(500 * time.Millisecond)
+		w.Write([]byte("<html><body><h1>Slow</h1></body></html>"))
+	}))
+	defer server.Close()
+
+	engine, err := NewEngine(&Config{
+		Timeout:   10 * time.Second,
+		RateLimit: 10 * time.Millisecond,
+		BurstSize: 1,
+		Transport: &TransportConfig{ResponseHeaderTimeout: 50 * time.Millisecond},
+	})
+	if err != nil {
+		t.Fatalf("Failed to create engine: %v", err)
+	}
+
+	if _, err := engine.fetchDocumentWithHTTP(context.Background(), server.URL); err == nil {
+		t.Error("Expected response header timeout error")
+	}
+}
+
+func TestScrapeSavesFailedBody(t *testing.T) {
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		w.Write([]byte("<html><body><p>Layout changed</p></body></html>"))
+	}))
+	defer server.Close()
+
+	dir := t.TempDir()
+	engine, err := NewEngine(&Config{
+		Timeout:   10 * time.Second,
+		RateLimit: 10 * time.Millisecond,
+		BurstSize: 1,
+		Headers:   map[string][]string{"X-Test": {"snapshot"}, "Authorization": {"Bearer abc123"}, "Cookie": {"session=s-789"}},
+		Debug:     &DebugConfig{SaveFailedBodies: dir},
+	})
+	if err != nil {
+		t.Fatalf("Failed to create engine: %v", err)
+	}
+
+	fields := []FieldConfig{{Name: "title", Selector: "h1", Type: "text", Required: true}}
+	engine.Scrape(context.Background(), server.URL, fields)
+
+	entries, err := os.ReadDir(dir)
+	if err != nil || len(entries) != 1 {
+		t.Fatalf("Expected one snapshot file, got %d (%v)", len(entries), err)
+	}
+
+	content, err := os.ReadFile(filepath.Join(dir, entries[0].Name()))
+	if err != nil {
+		t.Fatalf("Failed to read snapshot: %v", err)
+	}
+	if !strings.Contains(string(content), "Layout changed") {
+		t.Error("Expected snapshot to contain the raw body")
+	}
+	if !strings.Contains(string(content), "X-Test: snapshot") {
+		t.Error("Expected snapshot to contain request headers")
+	}
+	if strings.Contains(string(content), "abc123") || strings.Contains(string(content), "s-789") ||
+		!strings.Contains(string(content), "Authorization: [REDACTED]") {
+		t.Errorf("Expected credentials in request headers to be redacted, got:\n%s", content)
+	}
+}
+
+func TestSaveFailedBodyRecordsMethod(t *testing.T) {
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		w.Write([]byte("<html><body><p>Layout changed</p></body></html>"))
+	}))
+	defer server.Close()
+
+	dir := t.TempDir()
+	engine, err := NewEngine(&Config{
+		Timeout:   10 * time.Second,
+		RateLimit: 10 * time.Millisecond,
+		BurstSize: 1,
+		Request:   RequestSpec{Method: "post", Body: "q=lamps", BodyType: BodyTypeForm},
+		Debug:     &DebugConfig{SaveFailedBodies: dir},
+	})
+	if err != nil {
+		t.Fatalf("Failed to create engine: %v", err)
+	}
+	engine.Scrape(context.Background(), server.URL, []FieldConfig{{Name: "title", Selector: "h1", Type: "text", Required: true}})
+
+	entries, err := os.ReadDir(dir)
+	if err != nil || len(entries) != 1 {
+		t.Fatalf("Expected one snapshot file, got %d (%v)", len(entries), err)
+	}
+	content, _ := os.ReadFile(filepath.Join(dir, entries[0].Name()))
+	if !strings.Contains(string(content), "\nPOST "+server.URL+"\n") {
+		t.Errorf("Expected snapshot to name the POST request, got:\n%s", content)
+	}
+}
+
+func TestScrapeHeaderFields(t *testing.T) {
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		w.Header().Set("X-Total-Count", "137")
+		w.Header().Set("Last-Modified", "Wed, 21 Oct 2015 07:28:00 GMT")
+		w.Write([]byte("<html><body><h1>Title</h1></body></html>"))
+	}))
+	defer server.Close()
+
+	engine, err := NewEngine(&Config{Timeout: 10 * time.Second, RateLimit: 10 * time.Millisecond, BurstSize: 1})
+	if err != nil {
+		t.Fatalf("Failed to create engine: %v", err)
+	}
+
+	fields := []FieldConfig{
+		{Name: "title", Selector: "h1", Type: "text"},
+		{Name: "total", Selector: "x-total-count", Type: "header"},
+		{Name: "modified", Selector: "Last-Modified", Type: "header"},
+		{Name: "etag", Selector: "ETag", Type: "header", Default: "none"},
+	}
+
+	result, err := engine.Scrape(context.Background(), server.URL, fields)
+	if err != nil {
+		t.Fatalf("Scraping failed: %v", err)
+	}
+
+	if result.Data["total"] != "137" {
+		t.Errorf("Expected total 137 from case-insensitive header lookup, got %v", result.Data["total"])
+	}
+	if result.Data["modified"] != "Wed, 21 Oct 2015 07:28:00 GMT" {
+		t.Errorf("Unexpected modified value: %v", result.Data["modified"])
+	}
+	if result.Data["etag"] != "none" {
+		t.Errorf("Expected default for missing header, got %v", result.Data["etag"])
+	}
+}
+
+func TestNewEngineTLSMimic(t *testing.T) {
+	_, err := NewEngine(&Config{Timeout: 10 * time.Second, TLS: &TLSFingerprintConfig{Mimic: "netscape"}})
+	if err == nil {
+		t.Fatal("Expected error for unsupported tls.mimic profile")
+	}
+
+	engine, err := NewEngine(&Config{Timeout: 10 * time.Second, TLS: &TLSFingerprintConfig{Mimic: TLSMimicChrome}})
+	if err != nil {
+		// Default builds have no uTLS and must say so rather than silently using Go's ClientHello
+		if !errors.Is(err, ErrTLSMimicUnavailable) {
+			t.Fatalf("Expected ErrTLSMimicUnavailable, got %v", err)
+		}
+		return
+	}
+	if engine.dialTLS == nil {
+		t.Error("Expected mimic TLS dialer to be installed")
+	}
+}
+
+func TestScrapeBlockAbortAbandonsHost(t *testing.T) {
+	var blockedHits int32
+	blocked := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		atomic.AddInt32(&blockedHits, 1)
+		http.Error(w, "denied", http.StatusForbidden)
+	}))
+	defer blocked.Close()
+
+	healthy := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		w.Write([]byte(`<html><body><h1>Healthy</h1></body></html>`))
+	}))
+	defer healthy.Close()
+
+	engine, err := NewEngine(&Config{
+		Timeout:    10 * time.Second,
+		RateLimit:  10 * time.Millisecond,
+		BurstSize:  1,
+		BlockAbort: &BlockAbortConfig{Threshold: 2, Window: time.Minute},
+	})
+	if err != nil {
+		t.Fatalf("Failed to create engine: %v", err)
+	}
+
+	fields := []FieldConfig{{Name: "title", Selector: "h1", Type: "text"}}
+	ctx := context.Background()
+
+	for i := 0; i < 2; i++ {
+		if _, err := engine.Scrape(ctx, blocked.URL+fmt.Sprintf("/page%d", i), fields); err == nil {
+			t.Fatal("Expected blocked host to return an error")
+		}
+	}
+	_, err = engine.Scrape(ctx, blocked.URL+"/page2", fields)
+	if !errors.Is(err, ErrHostAbandoned) {
+		t.Fatalf("Expected ErrHostAbandoned after threshold, got %v", err)
+	}
+	if hits := atomic.LoadInt32(&blockedHits); hits != 2 {
+		t.Errorf("Expected abandoned host to receive 2 requests, got %d", hits)
+	}
+	if hosts := engine.AbandonedHosts(); len(hosts) != 1 || hosts[0] != requestHost(blocked.URL) {
+		t.Errorf("Expected only the blocked host to be abandoned, got %v", hosts)
+	}
+
+	result, err := engine.Scrape(ctx, healthy.URL, fields)
+	if err != nil {
+		t.Fatalf("Healthy host was dropped with the blocked one: %v", err)
+	}
+	if result.Data["title"] != "Healthy" {
+		t.Errorf("Expected title 'Healthy', got %v", result.Data["title"])
+	}
+}
+
+func TestHostBlockTrackerWindow(t *testing.T) {
+	tracker := newHostBlockTracker(&BlockAbortConfig{Threshold: 2, Window: time.Minute})
+	now := time.Now()
+	tracker.now = func() time.Time { return now }
+
+	if tracker.record("a.example") {
+		t.Fatal("First block should not abandon the host")
+	}
+	now = now.Add(2 * time.Minute)
+	if tracker.record("a.example") {
+		t.Fatal("Blocks outside the window should not count")
+	}
+	now = now.Add(time.Second)
+	if !tracker.record("a.example") {
+		t.Fatal("Second block within the window should abandon the host")
+	}
+	if !tracker.isAbandoned("a.example") || tracker.isAbandoned("b.example") {
+		t.Error("Only a.example should be abandoned")
+	}
+
+	disabled := newHostBlockTracker(nil)
+	if disabled.record("a.example") || disabled.isAbandoned("a.example") {
+		t.Error("A nil tracker should never abandon a host")
+	}
+}
+
+func TestScrapeCircuitBreakerPerHost(t *testing.T) {
+	failing := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		http.Error(w, "gone", http.StatusNotFound)
+	}))
+	defer failing.Close()
+
+	healthy := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		w.Write([]byte(`<html><body><h1>Healthy</h1></body></html>`))
+	}))
+	defer healthy.Close()
+
+	engine, err := NewEngine(&Config{Timeout: 10 * time.Second, RateLimit: 10 * time.Millisecond, BurstSize: 1})
+	if err != nil {
+		t.Fatalf("Failed to create engine: %v", err)
+	}
+	engine.ConfigureErrorRecovery("fetch_document", &recovery.CircuitBreakerConfig{MaxFailures: 1, ResetTimeout: time.Hour}, nil)
+
+	fields := []FieldConfig{{Name: "title", Selector: "h1", Type: "text"}}
+	ctx := context.Background()
+
+	if _, err := engine.Scrape(ctx, failing.URL, fields); err == nil {
+		t.Fatal("Expected failing host to return an error")
+	}
+	if _, err := engine.Scrape(ctx, failing.URL, fields); err == nil || !strings.Contains(err.Error(), "circuit breaker is open") {
+		t.Errorf("Expected breaker for failing host to be open, got %v", err)
+	}
+
+	result, err := engine.Scrape(ctx, healthy.URL, fields)
+	if err != nil {
+		t.Fatalf("Healthy host was blocked by the failing host's breaker: %v", err)
+	}
+	if result.Data["title"] != "Healthy" {
+		t.Errorf("Expected title 'Healthy', got %v", result.Data["title"])
+	}
+	if state := engine.GetHostCircuitBreakerState(requestHost(healthy.URL)); state != utils.StateClosed {
+		t.Errorf("Expected healthy host breaker closed, got %d", state)
+	}
+
+	engine.ResetErrorRecovery()
+	if state := engine.GetHostCircuitBreakerState(requestHost(failing.URL)); state != utils.StateClosed {
+		t.Errorf("Expected ResetErrorRecovery to close the failing host breaker, got %d", state)
+	}
+}
+
+func TestWatchConfigResetsCircuitBreakers(t *testing.T) {
+	failing := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		http.Error(w, "gone", http.StatusNotFound)
+	}))
+	defer failing.Close()
+
+	engine, err := NewEngine(&Config{Timeout: 10 * time.Second, RateLimit: 10 * time.Millisecond, BurstSize: 1})
+	if err != nil {
+		t.Fatalf("Failed to create engine: %v", err)
+	}
+	engine.ConfigureErrorRecovery("fetch_document", &recovery.CircuitBreakerConfig{MaxFailures: 1, ResetTimeout: time.Hour}, nil)
+
+	fields := []FieldConfig{{Name: "title", Selector: "h1", Type: "text"}}
+	breakerOpen := func() bool {
+		_, err := engine.Scrape(context.Background(), failing.URL, fields)
+		return err != nil && strings.Contains(err.Error(), "circuit breaker is open")
+	}
+	engine.Scrape(context.Background(), failing.URL, fields)
+	if !breakerOpen() {
+		t.Fatal("Expected the failing host's breaker to open")
+	}
+
+	configFile := filepath.Join(t.TempDir(), "config.yaml")
+	writeConfig := func(name string) {
+		yaml := fmt.Sprintf("name: %s\nbase_url: %s\nfields:\n  - name: title\n    selector: h1\n    type: text\noutput:\n  format: json\n  file: out.json\n", name, failing.URL)
+		if err := os.WriteFile(configFile, []byte(yaml), 0644); err != nil {
+			t.Fatal(err)
+		}
+	}
+	writeConfig("before")
+
+	watcher := config.NewConfigWatcher(configFile, 10*time.Millisecond)
+	engine.WatchConfig(watcher)
+	if err := watcher.Start(); err != nil {
+		t.Fatalf("Failed to start watcher: %v", err)
+	}
+	defer watcher.Stop()
+
+	writeConfig("after_fix")
+	deadline := time.Now().Add(2 * time.Second)
+	for breakerOpen() {
+		if time.Now().After(deadline) {
+			t.Fatal("Expected the reload to close the open circuit breaker")
+		}
+		time.Sleep(10 * time.Millisecond)
+	}
+
+	if reset := engine.ResetCircuitBreakers(); reset == 0 {
+		t.Error("Expected ResetCircuitBreakers to count the engine's breakers")
+	}
+}
+
+func TestScrapeNormalizeText(t *testing.T) {
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		w.Write([]byte("<html><body><h1>Cafe\u0301&nbsp;au\u200blait\n\n  </h1><li>One&nbsp;two</li><li>three\r\nfour</li></body></html>"))
+	}))
+	defer server.Close()
+
+	fields := []FieldConfig{
+		{Name: "title", Selector: "h1", Type: "text"},
+		{Name: "items", Selector: "li", Type: "list"},
+	}
+
+	engine, err := NewEngine(&Config{Timeout: 10 * time.Second, RateLimit: 10 * time.Millisecond, BurstSize: 1})
+	if err != nil {
+		t.Fatalf("Failed to create engine: %v", err)
+	}
+	result, err := engine.Scrape(context.Background(), server.URL, fields)
+	if err != nil {
+		t.Fatalf("Scraping failed: %v", err)
+	}
+	if result.Data["title"] != "Caf\u00e9 au lait" {
+		t.Errorf("Expected normalized title, got %q", result.Data["title"])
+	}
+	items, _ := result.Data["items"].([]string)
+	if len(items) != 2 || items[0] != "One two" || items[1] != "three four" {
+		t.Errorf("Expected normalized list items, got %q", result.Data["items"])
+	}
+
+	// normalize_text: false keeps the raw text apart from trimming
+	disabled := false
+	engine, err = NewEngine(&Config{Timeout: 10 * time.Second, RateLimit: 10 * time.Millisecond, BurstSize: 1, NormalizeText: &disabled})
+	if err != nil {
+		t.Fatalf("Failed to create engine: %v", err)
+	}
+	result, err = engine.Scrape(context.Background(), server.URL, fields)
+	if err != nil {
+		t.Fatalf("Scraping failed: %v", err)
+	}
+	if result.Data["title"] != "Cafe\u0301\u00a0au\u200blait" {
+		t.Errorf("Expected raw title with normalization disabled, got %q", result.Data["title"])
+	}
+}
+
+func TestScrapeRetryOnEmpty(t *testing.T) {
+	var hits atomic.Int32
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		if hits.Add(1) == 1 {
+			w.Write([]byte("<html><body><p>Loading...</p></body></html>"))
+			return
+		}
+		w.Write([]byte("<html><body><h1>Warm</h1><p>Body</p></body></html>"))
+	}))
+	defer server.Close()
+
+	fields := []FieldConfig{
+		{Name: "title", Selector: "h1", Type: "text", Required: true},
+		{Name: "body", Selector: "p", Type: "text"},
+	}
+
+	engine, err := NewEngine(&Config{Timeout: 10 * time.Second, RateLimit: 10 * time.Millisecond, BurstSize: 1, RetryOnEmpty: true})
+	if err != nil {
+		t.Fatalf("Failed to create engine: %v", err)
+	}
+	result, err := engine.Scrape(context.Background(), server.URL, fields)
+	if err != nil {
+		t.Fatalf("Scraping failed: %v", err)
+	}
+	if result.Data["title"] != "Warm" || len(result.Errors) != 0 {
+		t.Errorf("Expected warm page after re-fetch, got data %v errors %v", result.Data, result.Errors)
+	}
+	if stats := engine.GetRequestStats(); stats.EmptyRetries != 1 || stats.Requests != 2 {
+		t.Errorf("Expected 1 empty retry over 2 requests, got %+v", stats)
+	}
+
+	// A page that never fills stops at the retry limit
+	engine, err = NewEngine(&Config{Timeout: 10 * time.Second, RateLimit: 10 * time.Millisecond, BurstSize: 1, MaxRetries: 1, RetryOnEmpty: true, RetryOnEmptyFields: []string{"title"}})
+	if err != nil {
+		t.Fatalf("Failed to create engine: %v", err)
+	}
+	emptyServer := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		w.Write([]byte("<html><body><p>Loading...</p></body></html>"))
+	}))
+	defer emptyServer.Close()
+
+	result, err = engine.Scrape(context.Background(), emptyServer.URL, fields)
+	if err != nil {
+		t.Fatalf("Scraping failed: %v", err)
+	}
+	if _, ok := result.Data["title"]; ok {
+		t.Errorf("Expected title to stay missing, got %v", result.Data["title"])
+	}
+	if stats := engine.GetRequestStats(); stats.EmptyRetries != 1 || stats.Requests != 2 {
+		t.Errorf("Expected retry limit of 1 to allow 2 requests, got %+v", stats)
+	}
+}
+
+func TestScrapeRepeatedHeadersAndCookies(t *testing.T) {
+	var gotFoo []string
+	var gotCookies []string
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		if r.URL.Path == "/login" {
+			http.SetCookie(w, &http.Cookie{Name: "session", Value: "s1", Path: "/"})
+			http.SetCookie(w, &http.Cookie{Name: "csrf", Value: "c1", Path: "/"})
+			w.Write([]byte("<html><body><h1>Login</h1></body></html>"))
+			return
+		}
+		gotFoo = r.Header.Values("X-Foo")
+		gotCookies = nil
+		for _, cookie := range r.Cookies() {
+			gotCookies = append(gotCookies, cookie.Name+"="+cookie.Value)
+		}
+		w.Write([]byte("<html><body><h1>Data</h1></body></html>"))
+	}))
+	defer server.Close()
+
+	engine, err := NewEngine(&Config{Timeout: 10 * time.Second, RateLimit: 10 * time.Millisecond, BurstSize: 1,
+		Headers: map[string][]string{"X-Foo": {"a", "b"}}, CookieJar: true})
+	if err != nil {
+		t.Fatalf("Failed to create engine: %v", err)
+	}
+
+	fields := []FieldConfig{{Name: "cookies", Selector: "Set-Cookie", Type: "header"}}
+	result, err := engine.Scrape(context.Background(), server.URL+"/login", fields)
+	if err != nil {
+		t.Fatalf("Scraping failed: %v", err)
+	}
+	if cookies, ok := result.Data["cookies"].([]string); !ok || len(cookies) != 2 {
+		t.Errorf("Expected both Set-Cookie values as a list, got %#v", result.Data["cookies"])
+	}
+
+	if _, err := engine.Scrape(context.Background(), server.URL+"/data", []FieldConfig{{Name: "title", Selector: "h1", Type: "text"}}); err != nil {
+		t.Fatalf("Scraping failed: %v", err)
+	}
+	if len(gotFoo) != 2 || gotFoo[0] != "a" || gotFoo[1] != "b" {
+		t.Errorf("Expected X-Foo sent as two lines [a b], got %v", gotFoo)
+	}
+	if strings.Join(gotCookies, ";") != "session=s1;csrf=c1" {
+		t.Errorf("Expected both cookies from the jar, got %v", gotCookies)
+	}
+}
+
+func TestScrapeCookieJarFile(t *testing.T) {
+	var gotCookies []string
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		switch r.URL.Path {
+		case "/login":
+			http.SetCookie(w, &http.Cookie{Name: "session", Value: "s1", Path: "/", MaxAge: 3600})
+			http.SetCookie(w, &http.Cookie{Name: "flash", Value: "welcome", Path: "/"})
+			http.SetCookie(w, &http.Cookie{Name: "cart", Value: "c1", Path: "/shop"})
+		case "/logout-flash":
+			http.SetCookie(w, &http.Cookie{Name: "flash", Value: "", Path: "/", MaxAge: -1})
+		}
+		gotCookies = nil
+		for _, cookie := range r.Cookies() {
+			gotCookies = append(gotCookies, cookie.Name+"="+cookie.Value)
+		}
+		w.Write([]byte("<html><body><h1>Page</h1></body></html>"))
+	}))
+	defer server.Close()
+
+	jarFile := filepath.Join(t.TempDir(), "state", "cookies.json")
+	fields := []FieldConfig{{Name: "title", Selector: "h1", Type: "text"}}
+	newEngine := func() *Engine {
+		engine, err := NewEngine(&Config{Timeout: 10 * time.Second, RateLimit: 10 * time.Millisecond, BurstSize: 1,
+			CookieJarFile: jarFile})
+		if err != nil {
+			t.Fatalf("Failed to create engine: %v", err)
+		}
+		return engine
+	}
+	scrape := func(engine *Engine, path string) string {
+		if _, err := engine.Scrape(context.Background(), server.URL+path, fields); err != nil {
+			t.Fatalf("Scraping %s failed: %v", path, err)
+		}
+		return strings.Join(gotCookies, ";")
+	}
+
+	// First run: cookies set on page 1 are sent on page 2, scoped by path
+	first := newEngine()
+	scrape(first, "/login")
+	if got := scrape(first, "/data"); got != "session=s1;flash=welcome" {
+		t.Errorf("Expected page 1 cookies on page 2, got %q", got)
+	}
+	if got := scrape(first, "/shop/item"); got != "cart=c1;session=s1;flash=welcome" {
+		t.Errorf("Expected the /shop cookie under /shop, got %q", got)
+	}
+	scrape(first, "/logout-flash")
+	if err := first.SaveCookies(); err != nil {
+		t.Fatalf("SaveCookies failed: %v", err)
+	}
+	if info, err := os.Stat(jarFile); err != nil || info.Mode().Perm() != 0600 {
+		t.Fatalf("Expected cookie jar file with mode 0600, got %v, %v", info, err)
+	}
+
+	// Second run loads the saved jar; the deleted cookie stays deleted
+	second := newEngine()
+	if got := scrape(second, "/data"); got != "session=s1" {
+		t.Errorf("Expected the saved session cookie in the next run, got %q", got)
+	}
+	if got := scrape(second, "/shop/item"); got != "cart=c1;session=s1" {
+		t.Errorf("Expected saved cookies keep their path scope, got %q", got)
+	}
+
+	os.WriteFile(jarFile, []byte("not json"), 0600)
+	if _, err := NewEngine(&Config{CookieJarFile: jarFile}); err == nil {
+		t.Error("Expected an error for a corrupt cookie jar file")
+	}
+}
+
+func TestScrapeWarmupURLs(t *testing.T) {
+	var warmups, pages atomic.Int32
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		switch r.URL.Path {
+		case "/":
+			warmups.Add(1)
+			http.SetCookie(w, &http.Cookie{Name: "csrf", Value: "token", Path: "/"})
+			w.Write([]byte("<html><body>home</body></html>"))
+		case "/missing":
+			warmups.Add(1)
+			http.NotFound(w, r)
+		default:
+			pages.Add(1)
+			if cookie, err := r.Cookie("csrf"); err != nil || cookie.Value != "token" {
+				http.Error(w, "forbidden", http.StatusForbidden)
+				return
+			}
+			w.Write([]byte("<html><body><h1>Data</h1></body></html>"))
+		}
+	}))
+	defer server.Close()
+
+	fields := []FieldConfig{{Name: "title", Selector: "h1", Type: "text", Required: true}}
+
+	engine, err := NewEngine(&Config{Timeout: 10 * time.Second, RateLimit: 10 * time.Millisecond, BurstSize: 1,
+		WarmupURLs: []string{server.URL + "/missing", server.URL + "/"}})
+	if err != nil {
+		t.Fatalf("Failed to create engine: %v", err)
+	}
+	for i := 0; i < 2; i++ {
+		result, err := engine.Scrape(context.Background(), server.URL+"/data", fields)
+		if err != nil {
+			t.Fatalf("Scraping failed: %v", err)
+		}
+		if result.Data["title"] != "Data" {
+			t.Errorf("Expected warmup cookie to unlock the page, got data %v errors %v", result.Data, result.Errors)
+		}
+	}
+	if got := warmups.Load(); got != 2 {
+		t.Errorf("Expected each warmup URL to be fetched once, got %d warmup requests", got)
+	}
+
+	// When every warmup URL fails the scrape aborts with an auth error
+	warmups.Store(0)
+	pages.Store(0)
+	engine, err = NewEngine(&Config{Timeout: 10 * time.Second, RateLimit: 10 * time.Millisecond, BurstSize: 1,
+		WarmupURLs: []string{server.URL + "/missing"}})
+	if err != nil {
+		t.Fatalf("Failed to create engine: %v", err)
+	}
+	_, err = engine.Scrape(context.Background(), server.URL+"/data", fields)
+	if !errors.Is(err, recovery.ErrAuth) {
+		t.Fatalf("Expected auth error, got %v", err)
+	}
+	if code := recovery.NewService().GetExitCode(err); code != 8 {
+		t.Errorf("Expected authentication exit code 8, got %d", code)
+	}
+	if pages.Load() != 0 {
+		t.Errorf("Expected no page request after failed warmup, got %d", pages.Load())
+	}
+}
+
+func TestInspectHTML(t *testing.T) {
+	tests := []struct {
+		name       string
+		body       string
+		unbalanced int
+		truncated  bool
+	}{
+		{"well formed", `<html><body><div><p>One<p>Two<br><img src="a"></div></body></html>`, 0, false},
+		{"no html element", `<div><span>Fragment</span></div>`, 0, false},
+		{"unclosed and stray", `<html><body><div><span>Open</div></em></body></html>`, 2, false},
+		{"cut off", `<html><body><div class="item"><h1>Title</h1><div class="pri`, 1, true},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			damage := inspectHTML([]byte(tt.body))
+			if damage.Unbalanced != tt.unbalanced || damage.Truncated != tt.truncated {
+				t.Errorf("inspectHTML() = %+v, want %d unbalanced, truncated %v", damage, tt.unbalanced, tt.truncated)
+			}
+		})
+	}
+}
+
+func TestScrapeMalformedHTML(t *testing.T) {
+	var hits atomic.Int32
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		if hits.Add(1) == 1 {
+			w.Write([]byte(`<html><body><div class="item"><h1>Lamp</h1><div class="pri`))
+			return
+		}
+		w.Write([]byte(`<html><body><div class="item"><h1>Lamp</h1><div class="price">25</div></div></body></html>`))
+	}))
+	defer server.Close()
+
+	fields := []FieldConfig{
+		{Name: "title", Selector: "h1", Type: "text"},
+		{Name: "price", Selector: ".price", Type: "text"},
+	}
+
+	// Without retry the truncated page is kept, with a warning naming the coverage
+	engine, err := NewEngine(&Config{Timeout: 10 * time.Second, RateLimit: 10 * time.Millisecond, BurstSize: 1,
+		MalformedHTML: &MalformedHTMLConfig{}})
+	if err != nil {
+		t.Fatalf("Failed to create engine: %v", err)
+	}
+	result, err := engine.Scrape(context.Background(), server.URL, fields)
+	if err != nil {
+		t.Fatalf("Scraping failed: %v", err)
+	}
+	if len(result.Warnings) != 1 || !strings.Contains(result.Warnings[0], "1 of 2 fields extracted") {
+		t.Errorf("Expected a malformed HTML warning with field coverage, got %v", result.Warnings)
+	}
+	if stats := engine.GetRequestStats(); stats.MalformedPages != 1 {
+		t.Errorf("Expected 1 malformed page, got %+v", stats)
+	}
+
+	// With retry the page is fetched again and the complete copy wins
+	hits.Store(0)
+	engine, err = NewEngine(&Config{Timeout: 10 * time.Second, RateLimit: 10 * time.Millisecond, BurstSize: 1,
+		MalformedHTML: &MalformedHTMLConfig{Retry: true}})
+	if err != nil {
+		t.Fatalf("Failed to create engine: %v", err)
+	}
+	result, err = engine.Scrape(context.Background(), server.URL, fields)
+	if err != nil {
+		t.Fatalf("Scraping failed: %v", err)
+	}
+	if result.Data["price"] != "25" || hits.Load() != 2 {
+		t.Errorf("Expected the re-fetched page to fill price, got %v after %d requests", result.Data, hits.Load())
+	}
+	if len(result.Warnings) != 1 || !strings.Contains(result.Warnings[0], "after malformed HTML") {
+		t.Errorf("Expected a re-fetch warning, got %v", result.Warnings)
+	}
+}
+
+func TestScrapeHiddenContent(t *testing.T) {
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		w.Write([]byte(`<html><head><style>.p{color:red}</style></head><body>` +
+			`<div class="desc">Visible<script>var x = 1;</script><template><span>Draft</span></template></div>` +
+			`<template><p class="price">9.99</p></template><p class="price">19.99</p>` +
+			`<noscript><img src="a.jpg"><span class="real">Real content</span></noscript>` +
+			`</body></html>`))
+	}))
+	defer server.Close()
+
+	fields := []FieldConfig{
+		{Name: "desc", Selector: ".desc", Type: "text"},
+		{Name: "prices", Selector: ".price", Type: "list"},
+		{Name: "real", Selector: ".real", Type: "text"},
+		{Name: "image", Selector: "noscript img", Type: "attr", Attribute: "src"},
+	}
+
+	engine, err := NewEngine(&Config{Timeout: 10 * time.Second, RateLimit: 10 * time.Millisecond, BurstSize: 1})
+	if err != nil {
+		t.Fatalf("Failed to create engine: %v", err)
+	}
+	result, err := engine.Scrape(context.Background(), server.URL, fields)
+	if err != nil {
+		t.Fatalf("Scraping failed: %v", err)
+	}
+	if result.Data["desc"] != "Visible" {
+		t.Errorf("Expected script and template text dropped, got %q", result.Data["desc"])
+	}
+	prices, _ := result.Data["prices"].([]string)
+	if len(prices) != 1 || prices[0] != "19.99" {
+		t.Errorf("Expected only the rendered price, got %q", result.Data["prices"])
+	}
+	if result.Data["real"] != "Real content" {
+		t.Errorf("Expected noscript content to be extracted, got %q", result.Data["real"])
+	}
+	if result.Data["image"] != "a.jpg" {
+		t.Errorf("Expected noscript image src, got %q", result.Data["image"])
+	}
+
+	// Flipping every option keeps script and template text and drops noscript
+	include, exclude := true, false
+	engine, err = NewEngine(&Config{Timeout: 10 * time.Second, RateLimit: 10 * time.Millisecond, BurstSize: 1,
+		HiddenContent: &HiddenContentConfig{Noscript: &exclude, Template: &include, Script: &include}})
+	if err != nil {
+		t.Fatalf("Failed to create engine: %v", err)
+	}
+	result, err = engine.Scrape(context.Background(), server.URL, fields[:2])
+	if err != nil {
+		t.Fatalf("Scraping failed: %v", err)
+	}
+	if result.Data["desc"] != "Visiblevar x = 1;Draft" {
+		t.Errorf("Expected script and template text kept, got %q", result.Data["desc"])
+	}
+	prices, _ = result.Data["prices"].([]string)
+	if len(prices) != 2 {
+		t.Errorf("Expected template price to be matched, got %q", result.Data["prices"])
+	}
+}
+
+func TestScrapeLazyImages(t *testing.T) {
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		w.Write([]byte(`<html><body>` +
+			`<img class="lazy" src="data:image/gif;base64,R0lGOD" data-src="real.jpg" data-srcset="real-2x.jpg 2x">` +
+			`<img class="custom" src="placeholder.gif" data-full="full.jpg">` +
+			`<img class="plain" src="plain.jpg" data-src="">` +
+			`</body></html>`))
+	}))
+	defer server.Close()
+
+	fields := []FieldConfig{
+		{Name: "lazy", Selector: ".lazy", Type: "attr", Attribute: "src"},
+		{Name: "srcset", Selector: ".lazy", Type: "attr", Attribute: "srcset"},
+		{Name: "custom", Selector: ".custom", Type: "attr", Attribute: "src"},
+		{Name: "plain", Selector: ".plain", Type: "attr", Attribute: "src"},
+	}
+
+	engine, err := NewEngine(&Config{Timeout: 10 * time.Second, RateLimit: 10 * time.Millisecond, BurstSize: 1})
+	if err != nil {
+		t.Fatalf("Failed to create engine: %v", err)
+	}
+	result, err := engine.Scrape(context.Background(), server.URL, fields)
+	if err != nil {
+		t.Fatalf("Scraping failed: %v", err)
+	}
+	for name, want := range map[string]string{
+		"lazy":   "real.jpg",
+		"srcset": "real-2x.jpg 2x",
+		"custom": "placeholder.gif",
+		"plain":  "plain.jpg",
+	} {
+		if result.Data[name] != want {
+			t.Errorf("%s = %q, want %q", name, result.Data[name], want)
+		}
+	}
+
+	// A custom precedence replaces the defaults; disabling reads src as written
+	engine, err = NewEngine(&Config{Timeout: 10 * time.Second, RateLimit: 10 * time.Millisecond, BurstSize: 1,
+		LazyImages: &LazyImageConfig{Src: []string{"data-full"}}})
+	if err != nil {
+		t.Fatalf("Failed to create engine: %v", err)
+	}
+	result, err = engine.Scrape(context.Background(), server.URL, fields[:3])
+	if err != nil {
+		t.Fatalf("Scraping failed: %v", err)
+	}
+	if result.Data["custom"] != "full.jpg" || result.Data["lazy"] != "data:image/gif;base64,R0lGOD" {
+		t.Errorf("Expected custom precedence, got custom=%q lazy=%q", result.Data["custom"], result.Data["lazy"])
+	}
+
+	engine, err = NewEngine(&Config{Timeout: 10 * time.Second, RateLimit: 10 * time.Millisecond, BurstSize: 1,
+		LazyImages: &LazyImageConfig{Disabled: true}})
+	if err != nil {
+		t.Fatalf("Failed to create engine: %v", err)
+	}
+	result, err = engine.Scrape(context.Background(), server.URL, fields[:1])
+	if err != nil {
+		t.Fatalf("Scraping failed: %v", err)
+	}
+	if result.Data["lazy"] != "data:image/gif;base64,R0lGOD" {
+		t.Errorf("Expected src as written when disabled, got %q", result.Data["lazy"])
+	}
+}
+
+func TestScrapeMaxPagesPerHost(t *testing.T) {
+	var bigHits int32
+	big := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		atomic.AddInt32(&bigHits, 1)
+		w.Write([]byte(`<html><body><h1>Big</h1></body></html>`))
+	}))
+	defer big.Close()
+
+	small := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		w.Write([]byte(`<html><body><h1>Small</h1></body></html>`))
+	}))
+	defer small.Close()
+
+	engine, err := NewEngine(&Config{Timeout: 10 * time.Second, RateLimit: 10 * time.Millisecond, BurstSize: 1, MaxPagesPerHost: 2})
+	if err != nil {
+		t.Fatalf("Failed to create engine: %v", err)
+	}
+
+	fields := []FieldConfig{{Name: "title", Selector: "h1", Type: "text"}}
+	ctx := context.Background()
+	for i := 0; i < 2; i++ {
+		if _, err := engine.Scrape(ctx, big.URL+fmt.Sprintf("/page%d", i), fields); err != nil {
+			t.Fatalf("Scraping within the cap failed: %v", err)
+		}
+	}
+	if _, err := engine.Scrape(ctx, big.URL+"/page2", fields); !errors.Is(err, ErrHostPageLimit) {
+		t.Fatalf("Expected ErrHostPageLimit past the cap, got %v", err)
+	}
+	if hits := atomic.LoadInt32(&bigHits); hits != 2 {
+		t.Errorf("Expected capped host to receive 2 requests, got %d", hits)
+	}
+	if _, err := engine.Scrape(ctx, small.URL, fields); err != nil {
+		t.Fatalf("Other host was capped with the big one: %v", err)
+	}
+
+	stats := engine.GetRequestStats()
+	bigHost, smallHost := requestHost(big.URL), requestHost(small.URL)
+	if stats.HostPages[bigHost] != 2 || stats.HostPages[smallHost] != 1 {
+		t.Errorf("Unexpected per-host page counts: %v", stats.HostPages)
+	}
+	if stats.HostPageSkipped[bigHost] != 1 || len(stats.HostPageSkipped) != 1 {
+		t.Errorf("Expected one skipped URL on the capped host, got %v", stats.HostPageSkipped)
+	}
+}
+
+func TestScrapeBodyMatch(t *testing.T) {
+	var hits atomic.Int32
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		n := hits.Add(1)
+		switch {
+		case r.URL.Path == "/gone":
+			w.Write([]byte(`<html><body><h1>Lamp</h1><p>Sorry, this item is out of stock</p></body></html>`))
+		case n == 1:
+			w.Write([]byte(`<html><body><p>Too busy, please try again later</p></body></html>`))
+		default:
+			w.Write([]byte(`<html><body><h1>Lamp</h1></body></html>`))
+		}
+	}))
+	defer server.Close()
+
+	engine, err := NewEngine(&Config{Timeout: 10 * time.Second, RateLimit: 10 * time.Millisecond, BurstSize: 1,
+		RetryOnBodyMatch:   []string{`(?i)try again later`},
+		NoRetryOnBodyMatch: []string{`(?i)out of stock`}})
+	if err != nil {
+		t.Fatalf("Failed to create engine: %v", err)
+	}
+	fields := []FieldConfig{{Name: "title", Selector: "h1", Type: "text"}}
+
+	// A permanent match fails the page without another request
+	if _, err := engine.Scrape(context.Background(), server.URL+"/gone", fields); err == nil || !strings.Contains(err.Error(), "no_retry_on_body_match") {
+		t.Errorf("Expected a no_retry_on_body_match error, got %v", err)
+	}
+	if got := hits.Load(); got != 1 {
+		t.Errorf("Expected 1 request for a permanent match, got %d", got)
+	}
+
+	// A transient match is fetched again, even with a 200 status
+	hits.Store(0)
+	result, err := engine.Scrape(context.Background(), server.URL+"/busy", fields)
+	if err != nil {
+		t.Fatalf("Scraping failed: %v", err)
+	}
+	if result.Data["title"] != "Lamp" || hits.Load() != 2 {
+		t.Errorf("Expected the retried page after 2 requests, got %v after %d", result.Data, hits.Load())
+	}
+
+	if _, err := NewEngine(&Config{RetryOnBodyMatch: []string{`(`}}); err == nil {
+		t.Error("Expected an invalid retry_on_body_match pattern to be rejected")
+	}
+}
+
+func TestScrapeAPIFallback(t *testing.T) {
+	var gotHeader string
+	api := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		gotHeader = r.Header.Get("X-Api-Key")
+		fmt.Fprintf(w, `{"title":"From the API for %s"}`, r.URL.Query().Get("id"))
+	}))
+	defer api.Close()
+	site := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		http.Error(w, "gone", http.StatusNotFound)
+	}))
+	defer site.Close()
+
+	engine, err := NewEngine(&Config{
+		Timeout:   10 * time.Second,
+		RateLimit: 10 * time.Millisecond,
+		BurstSize: 1,
+		Headers:   map[string][]string{"X-Api-Key": {"secret"}},
+		ErrorRecovery: &ErrorRecoveryConfig{
+			Enabled: true,
+			Fallbacks: map[string]FallbackSpec{
+				"fetch_document": {Strategy: "alternative", Alternative: "api_fallback", APIURL: api.URL + "/?id={path}"},
+			},
+		},
+	})
+	if err != nil {
+		t.Fatalf("Failed to create engine: %v", err)
+	}
+
+	fields := []FieldConfig{
+		{Name: "title", Selector: "h1", Type: "text"},
+		{Name: "price", Selector: ".price", Type: "text"},
+	}
+	result, err := engine.Scrape(context.Background(), site.URL+"/item", fields)
+	if err != nil {
+		t.Fatalf("Expected the API fallback to rescue the page, got %v", err)
+	}
+	if result.Data["title"] != "From the API for /item" {
+		t.Errorf("Expected the title from the API, got %v", result.Data["title"])
+	}
+	if _, ok := result.Data["price"]; ok || !result.Success {
+		t.Errorf("Expected a partial success without price, got %+v", result)
+	}
+	if gotHeader != "secret" {
+		t.Errorf("Expected the configured headers on the API request, got %q", gotHeader)
+	}
+	if len(result.Warnings) == 0 || !strings.Contains(result.Warnings[0], "Used fallback strategy") {
+		t.Errorf("Expected a fallback warning, got %v", result.Warnings)
+	}
+}
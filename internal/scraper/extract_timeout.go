@@ -0,0 +1,110 @@
+// internal/scraper/extract_timeout.go
+package scraper
+
+import (
+	"context"
+	"fmt"
+	"net/http"
+	"strings"
+	"time"
+
+	"github.com/PuerkitoBio/goquery"
+	"github.com/valpere/DataScrapexter/internal/utils"
+)
+
+// DefaultRegexExtractTimeout bounds fields with regex sources when extract_timeout is unset
+const DefaultRegexExtractTimeout = 5 * time.Second
+
+// extractValue extracts one field from the page or its response headers
+func (e *Engine) extractValue(doc *goquery.Document, headers http.Header, extractor FieldConfig) (interface{}, error) {
+	switch {
+	case len(extractor.Sources) > 0:
+		return e.extractFromSources(doc, headers, extractor)
+	case len(extractor.Selectors) > 0:
+		return e.extractFromSelectors(doc, headers, extractor)
+	case extractor.Type == "header":
+		return extractHeaderField(headers, extractor)
+	case extractor.Type == "jsonld":
+		return extractJSONLDPath(doc, extractor.Selector, extractor.JSONLDType)
+	case extractor.Type == "microdata":
+		return extractMicrodataPath(doc, extractor.Selector, extractor.JSONLDType)
+	default:
+		return e.extractField(doc, extractor)
+	}
+}
+
+// extractWithTimeout runs extractValue under the field's extraction timeout.
+// Go cannot interrupt a running regexp or selector match, so an extraction that
+// overruns is abandoned: its goroutine finishes in the background and the result
+// is dropped, but the page moves on to its next field.
+func (e *Engine) extractWithTimeout(ctx context.Context, doc *goquery.Document, headers http.Header, extractor FieldConfig) (interface{}, error) {
+	timeout := extractTimeout(extractor)
+	if timeout <= 0 {
+		return e.extractValue(doc, headers, extractor)
+	}
+
+	type extraction struct {
+		value interface{}
+		err   error
+	}
+	done := make(chan extraction, 1)
+	go func() {
+		value, err := e.extractValue(doc, headers, extractor)
+		done <- extraction{value, err}
+	}()
+
+	timer := time.NewTimer(timeout)
+	defer timer.Stop()
+
+	select {
+	case out := <-done:
+		return out.value, out.err
+	case <-timer.C:
+		utils.NewComponentLogger("scraper").Warnf("Extraction of field '%s' (%s) timed out after %v",
+			extractor.Name, extractionPatterns(extractor), timeout)
+		return nil, fmt.Errorf("extraction timed out after %v", timeout)
+	case <-ctx.Done():
+		return nil, ctx.Err()
+	}
+}
+
+// extractTimeout returns the field's extraction timeout, applying the regex default
+func extractTimeout(extractor FieldConfig) time.Duration {
+	if extractor.ExtractTimeout > 0 {
+		return extractor.ExtractTimeout
+	}
+	for _, source := range extractor.Sources {
+		if source.Type == SourceRegex {
+			return DefaultRegexExtractTimeout
+		}
+	}
+	return 0
+}
+
+// extractionPatterns describes the selectors and patterns a field uses, for logs
+func extractionPatterns(extractor FieldConfig) string {
+	if len(extractor.Sources) == 0 {
+		selectors := extractor.candidateSelectors()
+		if len(selectors) == 1 {
+			return fmt.Sprintf("selector %q", selectors[0])
+		}
+		quoted := make([]string, len(selectors))
+		for i, selector := range selectors {
+			quoted[i] = fmt.Sprintf("%q", selector)
+		}
+		return "selectors " + strings.Join(quoted, ", ")
+	}
+
+	parts := make([]string, 0, len(extractor.Sources))
+	for _, source := range extractor.Sources {
+		switch source.Type {
+		case SourceRegex:
+			parts = append(parts, fmt.Sprintf("regex %q", source.Pattern))
+		case SourceJSONLD:
+			parts = append(parts, fmt.Sprintf("jsonld %q", source.Path))
+		default:
+			parts = append(parts, fmt.Sprintf("%s %q", source.Type, source.Selector))
+		}
+	}
+	return strings.Join(parts, ", ")
+}
@@ -0,0 +1,71 @@
+// internal/scraper/extract_timeout_test.go
+package scraper
+
+import (
+	"context"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"testing"
+	"time"
+)
+
+func TestScrapeExtractTimeout(t *testing.T) {
+	// A large body keeps the regex scan busy far longer than the timeout below
+	page := "<html><body><h1>Title</h1><p>" + strings.Repeat("a", 4<<20) + "</p></body></html>"
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		w.Write([]byte(page))
+	}))
+	defer server.Close()
+
+	engine, err := NewEngine(&Config{Timeout: 10 * time.Second, RateLimit: 10 * time.Millisecond, BurstSize: 1})
+	if err != nil {
+		t.Fatalf("Failed to create engine: %v", err)
+	}
+
+	fields := []FieldConfig{
+		{Name: "title", Selector: "h1", Type: "text"},
+		{
+			Name:           "slow",
+			Sources:        []FieldSource{{Type: SourceRegex, Pattern: `(a+)+b`}},
+			ExtractTimeout: time.Microsecond,
+		},
+	}
+
+	result, err := engine.Scrape(context.Background(), server.URL, fields)
+	if err != nil {
+		t.Fatalf("Scraping failed: %v", err)
+	}
+	if result.Data["title"] != "Title" {
+		t.Errorf("Expected other fields to be extracted, got %v", result.Data)
+	}
+	if _, ok := result.Data["slow"]; ok {
+		t.Errorf("Expected timed out field to be missing, got %v", result.Data["slow"])
+	}
+	found := false
+	for _, msg := range result.Errors {
+		if strings.Contains(msg, "Field 'slow': extraction timed out") {
+			found = true
+		}
+	}
+	if !found {
+		t.Errorf("Expected a timeout error for field 'slow', got %v", result.Errors)
+	}
+}
+
+func TestExtractTimeoutDefaults(t *testing.T) {
+	tests := []struct {
+		name  string
+		field FieldConfig
+		want  time.Duration
+	}{
+		{"selector field", FieldConfig{Selector: "h1", Type: "text"}, 0},
+		{"regex source", FieldConfig{Sources: []FieldSource{{Type: SourceCSS, Selector: "h1"}, {Type: SourceRegex, Pattern: "x"}}}, DefaultRegexExtractTimeout},
+		{"explicit", FieldConfig{Selector: "h1", Type: "text", ExtractTimeout: time.Second}, time.Second},
+	}
+	for _, tt := range tests {
+		if got := extractTimeout(tt.field); got != tt.want {
+			t.Errorf("%s: extractTimeout = %v, want %v", tt.name, got, tt.want)
+		}
+	}
+}
@@ -82,7 +82,7 @@ func (fe *FieldExtractor) Extract(ctx context.Context) (interface{}, error) {
 	if len(fe.config.Transform) > 0 {
 		stringValue := fmt.Sprintf("%v", value)
 		transformList := pipeline.TransformList(fe.config.Transform)
-		transformedValue, err := transformList.Apply(ctx, stringValue)
+		transformedValue, err := transformList.ApplyValue(ctx, stringValue)
 		if err != nil {
 			return nil, fmt.Errorf("transformation failed: %w", err)
 		}
@@ -0,0 +1,262 @@
+// internal/scraper/field_pipeline.go
+package scraper
+
+import (
+	"context"
+	"fmt"
+	"math"
+	"regexp"
+	"strconv"
+	"strings"
+
+	"github.com/valpere/DataScrapexter/internal/pipeline"
+)
+
+// FieldValidation checks an extracted value. A value that fails is replaced by
+// the field's Default when the field is optional and has one; otherwise it is
+// dropped and the field counts as missing. Lists are validated element by element.
+type FieldValidation struct {
+	Pattern   string   `yaml:"pattern,omitempty" json:"pattern,omitempty"`       // regex the value must match
+	MinLength int      `yaml:"min_length,omitempty" json:"min_length,omitempty"` // minimum length in characters
+	MaxLength int      `yaml:"max_length,omitempty" json:"max_length,omitempty"` // maximum length in characters
+	Options   []string `yaml:"options,omitempty" json:"options,omitempty"`       // allowed values
+
+	// BeforeTransform validates the extracted (or default) value instead of the transformed one
+	BeforeTransform bool `yaml:"before_transform,omitempty" json:"before_transform,omitempty"`
+}
+
+// processField runs one field through its post-extraction pipeline, in this order:
+//
+//  1. extract: raw and extractErr come from the page
+//  2. default-if-empty: a failed or empty extraction takes the field's Default,
+//     unless the field is required
+//  3. transform: Transform rules run on the value, including a substituted default.
+//     Rules that pass unusable input through, like date, fail a required field.
+//  4. validate: Validate checks the transformed value, or the value from step 2
+//     when BeforeTransform is set. An optional field whose extracted value fails
+//     starts again from step 2 with its Default; a required field fails.
+//  5. coerce: OutputType turns the value into a JSON number or boolean; a value
+//     that does not convert is kept as it is, or fails the field in strict mode
+//
+// It returns the final value, whether the default was used, and the error that
+// made the field missing: the extraction error when no default applied, or a
+// transform, validation or strict coercion failure.
+func (e *Engine) processField(ctx context.Context, extractor FieldConfig, raw interface{}, extractErr error) (interface{}, bool, error) {
+	value := raw
+	usedDefault := false
+	if extractErr != nil || isEmptyValue(raw) {
+		if extractor.Required || extractor.Default == nil {
+			return raw, false, extractErr
+		}
+		value = extractor.Default
+		usedDefault = true
+	}
+
+	// invalid fails the field, or retries it with the default when that may apply
+	invalid := func(err error) (interface{}, bool, error) {
+		if !usedDefault && !extractor.Required && extractor.Default != nil {
+			return e.processField(ctx, extractor, nil, nil)
+		}
+		return nil, usedDefault, fmt.Errorf("validation failed: %w", err)
+	}
+
+	if v := extractor.Validate; v != nil && v.BeforeTransform {
+		if err := v.check(value); err != nil {
+			return invalid(err)
+		}
+	}
+
+	if len(extractor.Transform) > 0 {
+		transformCtx := ctx
+		if extractor.Required {
+			transformCtx = pipeline.WithRequiredField(ctx)
+		}
+		transformed, err := applyFieldTransforms(transformCtx, pipeline.TransformList(extractor.Transform), value)
+		if err != nil {
+			return nil, usedDefault, fmt.Errorf("transformation failed: %w", err)
+		}
+		value = transformed
+	}
+
+	if v := extractor.Validate; v != nil && !v.BeforeTransform {
+		if err := v.check(value); err != nil {
+			return invalid(err)
+		}
+	}
+
+	if extractor.OutputType != "" {
+		coerced, err := coerceOutputType(extractor.OutputType, value)
+		if err != nil {
+			if e.config.StrictMode {
+				return nil, usedDefault, fmt.Errorf("coercion to %s failed: %w", extractor.OutputType, err)
+			}
+		} else {
+			value = coerced
+		}
+	}
+
+	return value, usedDefault, nil
+}
+
+// Output types a field value can be coerced to
+const (
+	OutputTypeNumber  = "number"
+	OutputTypeInteger = "integer"
+	OutputTypeBoolean = "boolean"
+)
+
+// coerceOutputType converts a value, or each item of a list, to outputType:
+// float64 for number, int64 for integer and bool for boolean
+func coerceOutputType(outputType string, value interface{}) (interface{}, error) {
+	if items, ok := value.([]string); ok {
+		coerced := make([]interface{}, len(items))
+		for i, item := range items {
+			v, err := coerceOutputType(outputType, item)
+			if err != nil {
+				return nil, fmt.Errorf("item %d: %w", i, err)
+			}
+			coerced[i] = v
+		}
+		return coerced, nil
+	}
+	if items, ok := value.([]interface{}); ok {
+		coerced := make([]interface{}, len(items))
+		for i, item := range items {
+			v, err := coerceOutputType(outputType, item)
+			if err != nil {
+				return nil, fmt.Errorf("item %d: %w", i, err)
+			}
+			coerced[i] = v
+		}
+		return coerced, nil
+	}
+
+	switch outputType {
+	case OutputTypeNumber:
+		switch v := value.(type) {
+		case float64:
+			return v, nil
+		case int:
+			return float64(v), nil
+		case int64:
+			return float64(v), nil
+		}
+		s := strings.TrimSpace(fmt.Sprintf("%v", value))
+		n, err := strconv.ParseFloat(s, 64)
+		if err != nil || math.IsNaN(n) || math.IsInf(n, 0) {
+			return nil, fmt.Errorf("%q is not a number", s)
+		}
+		return n, nil
+
+	case OutputTypeInteger:
+		switch v := value.(type) {
+		case int:
+			return int64(v), nil
+		case int64:
+			return v, nil
+		case float64:
+			// float64(math.MaxInt64) rounds up to 2^63, which is already out of range
+			if v != math.Trunc(v) || v >= math.MaxInt64 || v < math.MinInt64 {
+				return nil, fmt.Errorf("%v is not an integer", v)
+			}
+			return int64(v), nil
+		}
+		s := strings.TrimSpace(fmt.Sprintf("%v", value))
+		n, err := strconv.ParseInt(s, 10, 64)
+		if err != nil {
+			return nil, fmt.Errorf("%q is not an integer", s)
+		}
+		return n, nil
+
+	case OutputTypeBoolean:
+		if b, ok := value.(bool); ok {
+			return b, nil
+		}
+		s := strings.ToLower(strings.TrimSpace(fmt.Sprintf("%v", value)))
+		switch s {
+		case "true", "yes", "y", "on", "1":
+			return true, nil
+		case "false", "no", "n", "off", "0":
+			return false, nil
+		}
+		return nil, fmt.Errorf("%q is not a boolean", s)
+
+	default:
+		return nil, fmt.Errorf("unknown output type %q", outputType)
+	}
+}
+
+// applyFieldTransforms applies rules to a string, to each item of a list, or to
+// the text form of any other value. A final json_parse rule leaves parsed data,
+// and a list of items becomes a list of parsed values.
+func applyFieldTransforms(ctx context.Context, rules pipeline.TransformList, value interface{}) (interface{}, error) {
+	switch v := value.(type) {
+	case string:
+		return rules.ApplyValue(ctx, v)
+	case []string:
+		if rules.ParsesJSON() {
+			items := make([]interface{}, len(v))
+			for i, item := range v {
+				parsed, err := rules.ApplyValue(ctx, item)
+				if err != nil {
+					return nil, fmt.Errorf("item %d: %w", i, err)
+				}
+				items[i] = parsed
+			}
+			return items, nil
+		}
+		items := make([]string, len(v))
+		for i, item := range v {
+			transformed, err := rules.Apply(ctx, item)
+			if err != nil {
+				return nil, fmt.Errorf("item %d: %w", i, err)
+			}
+			items[i] = transformed
+		}
+		return items, nil
+	default:
+		return rules.ApplyValue(ctx, fmt.Sprintf("%v", v))
+	}
+}
+
+// check validates a value, or each item of a list
+func (v *FieldValidation) check(value interface{}) error {
+	if items, ok := value.([]string); ok {
+		for i, item := range items {
+			if err := v.checkString(item); err != nil {
+				return fmt.Errorf("item %d: %w", i, err)
+			}
+		}
+		return nil
+	}
+	return v.checkString(fmt.Sprintf("%v", value))
+}
+
+// checkString validates the text form of a value
+func (v *FieldValidation) checkString(s string) error {
+	length := len([]rune(s))
+	if v.MinLength > 0 && length < v.MinLength {
+		return fmt.Errorf("value too short: %d < %d", length, v.MinLength)
+	}
+	if v.MaxLength > 0 && length > v.MaxLength {
+		return fmt.Errorf("value too long: %d > %d", length, v.MaxLength)
+	}
+	if v.Pattern != "" {
+		matched, err := regexp.MatchString(v.Pattern, s)
+		if err != nil {
+			return fmt.Errorf("invalid pattern: %w", err)
+		}
+		if !matched {
+			return fmt.Errorf("value %q does not match pattern %s", s, v.Pattern)
+		}
+	}
+	if len(v.Options) > 0 {
+		for _, option := range v.Options {
+			if s == option {
+				return nil
+			}
+		}
+		return fmt.Errorf("value %q not in allowed options: %s", s, strings.Join(v.Options, ", "))
+	}
+	return nil
+}
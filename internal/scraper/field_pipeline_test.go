@@ -0,0 +1,183 @@
+// internal/scraper/field_pipeline_test.go
+package scraper
+
+import (
+	"context"
+	"errors"
+	"math"
+	"reflect"
+	"testing"
+
+	"github.com/valpere/DataScrapexter/internal/pipeline"
+)
+
+func TestProcessFieldOrder(t *testing.T) {
+	upper := []pipeline.TransformRule{{Type: "trim"}, {Type: "uppercase"}}
+	notFound := errors.New("no elements found")
+
+	tests := []struct {
+		name        string
+		field       FieldConfig
+		raw         interface{}
+		extractErr  error
+		want        interface{}
+		wantDefault bool
+		wantErr     bool
+	}{
+		{
+			name:  "transform runs on extracted value",
+			field: FieldConfig{Transform: upper},
+			raw:   " widget ",
+			want:  "WIDGET",
+		},
+		{
+			name:        "default is substituted before transforms",
+			field:       FieldConfig{Default: "n/a", Transform: upper},
+			extractErr:  notFound,
+			want:        "N/A",
+			wantDefault: true,
+		},
+		{
+			name:        "empty value takes the default",
+			field:       FieldConfig{Default: "none"},
+			raw:         "  ",
+			want:        "none",
+			wantDefault: true,
+		},
+		{
+			name:       "required field ignores default",
+			field:      FieldConfig{Required: true, Default: "n/a"},
+			extractErr: notFound,
+			wantErr:    true,
+		},
+		{
+			name:  "validation sees the transformed value",
+			field: FieldConfig{Transform: upper, Validate: &FieldValidation{Options: []string{"IN STOCK"}}},
+			raw:   "in stock",
+			want:  "IN STOCK",
+		},
+		{
+			name:    "before_transform validates the raw value",
+			field:   FieldConfig{Transform: upper, Validate: &FieldValidation{Options: []string{"IN STOCK"}, BeforeTransform: true}},
+			raw:     "in stock",
+			wantErr: true,
+		},
+		{
+			name:        "validation applies to a substituted default",
+			field:       FieldConfig{Default: "?", Validate: &FieldValidation{Pattern: `^\d+$`}},
+			extractErr:  notFound,
+			wantDefault: true,
+			wantErr:     true,
+		},
+		{
+			name:        "optional field failing validation takes the default",
+			field:       FieldConfig{Default: "0", Transform: upper, Validate: &FieldValidation{Pattern: `^\d+$`}},
+			raw:         "call us",
+			want:        "0",
+			wantDefault: true,
+		},
+		{
+			name:    "required field failing validation fails",
+			field:   FieldConfig{Required: true, Default: "0", Validate: &FieldValidation{Pattern: `^\d+$`}},
+			raw:     "call us",
+			wantErr: true,
+		},
+		{
+			name:  "lists are transformed and validated per item",
+			field: FieldConfig{Transform: upper, Validate: &FieldValidation{MaxLength: 3}},
+			raw:   []string{" a ", "bc"},
+			want:  []string{"A", "BC"},
+		},
+		{
+			name:  "json_parse keeps an object structured",
+			field: FieldConfig{Transform: []pipeline.TransformRule{{Type: "json_parse", Params: map[string]interface{}{"path": "$.offer"}}}},
+			raw:   `{"offer": {"price": 9.5, "tags": ["new"]}}`,
+			want:  map[string]interface{}{"price": 9.5, "tags": []interface{}{"new"}},
+		},
+		{
+			name:  "json_parse parses each list item",
+			field: FieldConfig{Transform: []pipeline.TransformRule{{Type: "json_parse"}}},
+			raw:   []string{`[1, 2]`, `{"a": "b"}`},
+			want:  []interface{}{[]interface{}{float64(1), float64(2)}, map[string]interface{}{"a": "b"}},
+		},
+		{
+			name:    "json_parse failure fails a required field",
+			field:   FieldConfig{Required: true, Transform: []pipeline.TransformRule{{Type: "json_parse"}}},
+			raw:     `{"offer": `,
+			wantErr: true,
+		},
+		{
+			name:  "unparseable date passes through an optional field",
+			field: FieldConfig{Transform: []pipeline.TransformRule{{Type: "date"}}},
+			raw:   "sometime last spring",
+			want:  "sometime last spring",
+		},
+		{
+			name:    "unparseable date fails a required field",
+			field:   FieldConfig{Required: true, Transform: []pipeline.TransformRule{{Type: "date"}}},
+			raw:     "sometime last spring",
+			wantErr: true,
+		},
+		{
+			name:  "parseable date converts for a required field",
+			field: FieldConfig{Required: true, Transform: []pipeline.TransformRule{{Type: "date", Format: "DateOnly"}}},
+			raw:   "Jan 2, 2006",
+			want:  "2006-01-02",
+		},
+	}
+
+	engine := &Engine{config: &Config{}}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			got, usedDefault, err := engine.processField(context.Background(), tt.field, tt.raw, tt.extractErr)
+			if (err != nil) != tt.wantErr {
+				t.Fatalf("err = %v, wantErr %v", err, tt.wantErr)
+			}
+			if usedDefault != tt.wantDefault {
+				t.Errorf("usedDefault = %v, want %v", usedDefault, tt.wantDefault)
+			}
+			if !tt.wantErr && !reflect.DeepEqual(got, tt.want) {
+				t.Errorf("value = %#v, want %#v", got, tt.want)
+			}
+		})
+	}
+}
+
+func TestProcessFieldOutputType(t *testing.T) {
+	price := []pipeline.TransformRule{{Type: "parse_float"}}
+
+	tests := []struct {
+		name    string
+		field   FieldConfig
+		raw     interface{}
+		strict  bool
+		want    interface{}
+		wantErr bool
+	}{
+		{name: "number from text", field: FieldConfig{OutputType: "number"}, raw: " 19.99 ", want: 19.99},
+		{name: "number passes through transforms", field: FieldConfig{Transform: price, OutputType: "number"}, raw: "$1,299.50", want: 1299.5},
+		{name: "integer from text", field: FieldConfig{OutputType: "integer"}, raw: "42", want: int64(42)},
+		{name: "integer from whole float", field: FieldConfig{OutputType: "integer"}, raw: 7.0, want: int64(7)},
+		{name: "boolean words", field: FieldConfig{OutputType: "boolean"}, raw: " Yes ", want: true},
+		{name: "boolean digits", field: FieldConfig{OutputType: "boolean"}, raw: "0", want: false},
+		{name: "lists are coerced per item", field: FieldConfig{OutputType: "integer"}, raw: []string{"1", "2"}, want: []interface{}{int64(1), int64(2)}},
+		{name: "unconvertible value is kept", field: FieldConfig{OutputType: "number"}, raw: "call us", want: "call us"},
+		{name: "unconvertible value fails in strict mode", field: FieldConfig{OutputType: "number"}, raw: "call us", strict: true, wantErr: true},
+		{name: "fractional integer fails in strict mode", field: FieldConfig{OutputType: "integer"}, raw: "2.5", strict: true, wantErr: true},
+		{name: "float of 2^63 overflows integer", field: FieldConfig{OutputType: "integer"}, raw: 9223372036854775808.0, strict: true, wantErr: true},
+		{name: "float of -2^63 fits integer", field: FieldConfig{OutputType: "integer"}, raw: -9223372036854775808.0, want: int64(math.MinInt64)},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			engine := &Engine{config: &Config{StrictMode: tt.strict}}
+			got, _, err := engine.processField(context.Background(), tt.field, tt.raw, nil)
+			if (err != nil) != tt.wantErr {
+				t.Fatalf("err = %v, wantErr %v", err, tt.wantErr)
+			}
+			if !tt.wantErr && !reflect.DeepEqual(got, tt.want) {
+				t.Errorf("value = %#v, want %#v", got, tt.want)
+			}
+		})
+	}
+}
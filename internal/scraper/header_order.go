@@ -0,0 +1,257 @@
+// internal/scraper/header_order.go
+package scraper
+
+import (
+	"bytes"
+	"context"
+	"crypto/tls"
+	"fmt"
+	"net"
+	"sort"
+	"strconv"
+	"strings"
+	"time"
+)
+
+// HeaderOrderConfig sends request headers in a browser-like order and casing.
+// net/http always writes headers sorted and canonicalized, which is easy to
+// fingerprint; this rewrites the HTTP/1.1 request head on the wire instead.
+//
+// Ordering needs the engine to perform the TLS handshake itself, so HTTPS
+// requests are pinned to HTTP/1.1. Like tls.mimic it applies to direct
+// connections and plain-HTTP proxies; HTTPS through a proxy keeps Go's order.
+type HeaderOrderConfig struct {
+	// Profile selects a built-in browser order: chrome, firefox or safari.
+	// It defaults to tls.mimic so the headers match the TLS fingerprint.
+	Profile string `yaml:"profile,omitempty" json:"profile,omitempty"`
+
+	// Order lists header names in wire order, spelled with the casing to send
+	// (e.g. sec-ch-ua). It overrides Profile. Unlisted headers follow, in Go's order.
+	Order []string `yaml:"order,omitempty" json:"order,omitempty"`
+}
+
+// headerOrderProfiles are the HTTP/1.1 header orders of current desktop browsers
+var headerOrderProfiles = map[string][]string{
+	TLSMimicChrome: {
+		"Host", "Connection", "Cache-Control", "sec-ch-ua", "sec-ch-ua-mobile", "sec-ch-ua-platform",
+		"Upgrade-Insecure-Requests", "User-Agent", "Accept", "Sec-Fetch-Site", "Sec-Fetch-Mode",
+		"Sec-Fetch-User", "Sec-Fetch-Dest", "Referer", "Accept-Encoding", "Accept-Language", "Cookie",
+	},
+	TLSMimicFirefox: {
+		"Host", "User-Agent", "Accept", "Accept-Language", "Accept-Encoding", "Referer", "Connection",
+		"Cookie", "Upgrade-Insecure-Requests", "Sec-Fetch-Dest", "Sec-Fetch-Mode", "Sec-Fetch-Site",
+		"Sec-Fetch-User", "Cache-Control",
+	},
+	TLSMimicSafari: {
+		"Host", "Accept", "Sec-Fetch-Site", "Cookie", "Sec-Fetch-Dest", "Accept-Language",
+		"Sec-Fetch-Mode", "User-Agent", "Referer", "Accept-Encoding", "Connection",
+	},
+}
+
+// headerOrder holds the resolved wire order of header names
+type headerOrder struct {
+	names     []string       // names as sent, by position
+	positions map[string]int // lower-cased name -> position
+}
+
+// newHeaderOrder resolves the header order for cfg, or returns nil when ordering is off
+func newHeaderOrder(cfg *HeaderOrderConfig, tlsCfg *TLSFingerprintConfig) (*headerOrder, error) {
+	if cfg == nil {
+		return nil, nil
+	}
+
+	names := cfg.Order
+	if len(names) == 0 {
+		profile := cfg.Profile
+		if profile == "" && tlsCfg != nil {
+			profile = tlsCfg.Mimic
+		}
+		if profile == "" {
+			return nil, fmt.Errorf("header_order needs a profile, an order, or tls.mimic")
+		}
+		var ok bool
+		if names, ok = headerOrderProfiles[profile]; !ok {
+			return nil, fmt.Errorf("unsupported header_order profile: %s", profile)
+		}
+	}
+
+	order := &headerOrder{positions: make(map[string]int, len(names))}
+	for _, name := range names {
+		key := strings.ToLower(strings.TrimSpace(name))
+		if key == "" {
+			continue
+		}
+		if _, dup := order.positions[key]; dup {
+			continue
+		}
+		order.positions[key] = len(order.names)
+		order.names = append(order.names, strings.TrimSpace(name))
+	}
+	return order, nil
+}
+
+// wrap returns dial with each connection's request heads reordered
+func (h *headerOrder) wrap(dial dialFunc) dialFunc {
+	return func(ctx context.Context, network, addr string) (net.Conn, error) {
+		conn, err := dial(ctx, network, addr)
+		if err != nil {
+			return nil, err
+		}
+		return &headerOrderConn{Conn: conn, order: h}, nil
+	}
+}
+
+// newHTTP1DialTLS returns a standard TLS dialer that offers only http/1.1, so
+// header ordering also applies to HTTPS when tls.mimic is not set. The
+// transport's handshake timeout does not cover a custom TLS dialer, so it is
+// applied here.
+func newHTTP1DialTLS(tc *TransportConfig) dialTLSFunc {
+	dialer := &net.Dialer{Timeout: 30 * time.Second, KeepAlive: 30 * time.Second}
+	var handshakeTimeout time.Duration
+	if tc != nil {
+		if tc.DialTimeout > 0 {
+			dialer.Timeout = tc.DialTimeout
+		}
+		handshakeTimeout = tc.TLSHandshakeTimeout
+	}
+
+	return func(ctx context.Context, network, addr string) (net.Conn, error) {
+		host, _, err := net.SplitHostPort(addr)
+		if err != nil {
+			return nil, fmt.Errorf("invalid TLS address %s: %w", addr, err)
+		}
+
+		conn, err := dialer.DialContext(ctx, network, addr)
+		if err != nil {
+			return nil, err
+		}
+
+		handshakeCtx := ctx
+		if handshakeTimeout > 0 {
+			var cancel context.CancelFunc
+			handshakeCtx, cancel = context.WithTimeout(ctx, handshakeTimeout)
+			defer cancel()
+		}
+		tlsConn := tls.Client(conn, &tls.Config{ServerName: host, NextProtos: []string{"http/1.1"}})
+		if err := tlsConn.HandshakeContext(handshakeCtx); err != nil {
+			conn.Close()
+			return nil, fmt.Errorf("TLS handshake failed: %w", err)
+		}
+		return tlsConn, nil
+	}
+}
+
+// headerOrderConn rewrites each HTTP/1.1 request head written to it. Bodies of
+// known length pass through untouched; anything it cannot follow (a CONNECT
+// tunnel, a chunked body, non-HTTP bytes) switches it to plain pass-through.
+type headerOrderConn struct {
+	net.Conn
+	order       *headerOrder
+	head        []byte // partial request head awaiting its blank line
+	body        int64  // body bytes of the current request still to pass through
+	passthrough bool
+}
+
+// Write buffers until a request head is complete, then sends it reordered
+func (c *headerOrderConn) Write(p []byte) (int, error) {
+	if err := c.process(p); err != nil {
+		return 0, err
+	}
+	return len(p), nil
+}
+
+// process forwards p, rewriting any request heads it completes
+func (c *headerOrderConn) process(p []byte) error {
+	for len(p) > 0 {
+		switch {
+		case c.passthrough:
+			_, err := c.Conn.Write(p)
+			return err
+		case c.body > 0:
+			n := len(p)
+			if int64(n) > c.body {
+				n = int(c.body)
+			}
+			if _, err := c.Conn.Write(p[:n]); err != nil {
+				return err
+			}
+			c.body -= int64(n)
+			p = p[n:]
+		default:
+			// Request lines start with an upper-case method; anything else is not ours to touch
+			if len(c.head) == 0 && (p[0] < 'A' || p[0] > 'Z') {
+				c.passthrough = true
+				continue
+			}
+			c.head = append(c.head, p...)
+			end := bytes.Index(c.head, []byte("\r\n\r\n"))
+			if end < 0 {
+				return nil
+			}
+			head, rest := c.head[:end+4], c.head[end+4:]
+			c.head = nil
+			if _, err := c.Conn.Write(c.reorder(head)); err != nil {
+				return err
+			}
+			p = rest
+		}
+	}
+	return nil
+}
+
+// reorder returns head with its header lines in the configured order and casing,
+// and records how much body follows it
+func (c *headerOrderConn) reorder(head []byte) []byte {
+	lines := strings.Split(string(head[:len(head)-4]), "\r\n")
+	if strings.HasPrefix(lines[0], "CONNECT ") {
+		c.passthrough = true
+		return head
+	}
+
+	type headerLine struct {
+		name, rest string
+		position   int
+	}
+	fields := make([]headerLine, 0, len(lines)-1)
+	for _, line := range lines[1:] {
+		colon := strings.IndexByte(line, ':')
+		if colon <= 0 {
+			c.passthrough = true
+			return head
+		}
+		name, rest := line[:colon], line[colon:]
+		key := strings.ToLower(name)
+
+		switch key {
+		case "content-length":
+			length, err := strconv.ParseInt(strings.TrimSpace(rest[1:]), 10, 64)
+			if err != nil {
+				c.passthrough = true
+				return head
+			}
+			c.body = length
+		case "transfer-encoding":
+			c.passthrough = true
+		}
+
+		position, ok := c.order.positions[key]
+		if ok {
+			name = c.order.names[position]
+		} else {
+			position = len(c.order.names)
+		}
+		fields = append(fields, headerLine{name: name, rest: rest, position: position})
+	}
+	sort.SliceStable(fields, func(i, j int) bool { return fields[i].position < fields[j].position })
+
+	var out bytes.Buffer
+	out.WriteString(lines[0])
+	out.WriteString("\r\n")
+	for _, f := range fields {
+		out.WriteString(f.name)
+		out.WriteString(f.rest)
+		out.WriteString("\r\n")
+	}
+	out.WriteString("\r\n")
+	return out.Bytes()
+}
@@ -0,0 +1,168 @@
+// internal/scraper/header_order_test.go
+package scraper
+
+import (
+	"bufio"
+	"bytes"
+	"context"
+	"fmt"
+	"net"
+	"strings"
+	"testing"
+	"time"
+)
+
+// captureConn records everything written to it
+type captureConn struct {
+	net.Conn
+	buf bytes.Buffer
+}
+
+func (c *captureConn) Write(p []byte) (int, error) { return c.buf.Write(p) }
+
+// headerNames returns the header names of the first request head in raw, in wire order
+func headerNames(raw string) []string {
+	head := raw[:strings.Index(raw, "\r\n\r\n")]
+	var names []string
+	for _, line := range strings.Split(head, "\r\n")[1:] {
+		names = append(names, line[:strings.IndexByte(line, ':')])
+	}
+	return names
+}
+
+func TestHeaderOrderConnRewritesHeads(t *testing.T) {
+	order, err := newHeaderOrder(&HeaderOrderConfig{Order: []string{"Host", "user-agent", "X-API-key"}}, nil)
+	if err != nil {
+		t.Fatalf("newHeaderOrder failed: %v", err)
+	}
+	capture := &captureConn{}
+	conn := &headerOrderConn{Conn: capture, order: order}
+
+	// A head split across writes, a body, then a second request on the same connection
+	writes := []string{
+		"POST /a HTTP/1.1\r\nHost: example.com\r\nContent-Length: 13\r\nX-Api-Key: s",
+		"ecret\r\nUser-Agent: test\r\n\r\n{\"a\":\"\r\n\r\n\"}",
+		"GET /b HTTP/1.1\r\nHost: example.com\r\nAccept: */*\r\nUser-Agent: test\r\n\r\n",
+	}
+	for _, w := range writes {
+		if n, err := conn.Write([]byte(w)); err != nil || n != len(w) {
+			t.Fatalf("Write = %d, %v; want %d, nil", n, err, len(w))
+		}
+	}
+
+	want := "POST /a HTTP/1.1\r\nHost: example.com\r\nuser-agent: test\r\nX-API-key: secret\r\nContent-Length: 13\r\n\r\n" +
+		"{\"a\":\"\r\n\r\n\"}" +
+		"GET /b HTTP/1.1\r\nHost: example.com\r\nuser-agent: test\r\nAccept: */*\r\n\r\n"
+	if got := capture.buf.String(); got != want {
+		t.Errorf("wire bytes = %q\nwant %q", got, want)
+	}
+
+	// Tunnels are left alone once established
+	capture = &captureConn{}
+	conn = &headerOrderConn{Conn: capture, order: order}
+	tunnel := "CONNECT example.com:443 HTTP/1.1\r\nHost: example.com:443\r\nUser-Agent: test\r\n\r\n\x16\x03\x01"
+	conn.Write([]byte(tunnel))
+	if capture.buf.String() != tunnel {
+		t.Errorf("CONNECT tunnel was modified: %q", capture.buf.String())
+	}
+}
+
+func TestScrapeHeaderOrderProfile(t *testing.T) {
+	listener, err := net.Listen("tcp", "127.0.0.1:0")
+	if err != nil {
+		t.Fatalf("Failed to listen: %v", err)
+	}
+	defer listener.Close()
+
+	heads := make(chan string, 1)
+	go func() {
+		conn, err := listener.Accept()
+		if err != nil {
+			return
+		}
+		defer conn.Close()
+		reader := bufio.NewReader(conn)
+		var head strings.Builder
+		for {
+			line, err := reader.ReadString('\n')
+			if err != nil {
+				return
+			}
+			head.WriteString(line)
+			if line == "\r\n" {
+				break
+			}
+		}
+		heads <- head.String()
+		body := "<html><body><h1>ok</h1></body></html>"
+		fmt.Fprintf(conn, "HTTP/1.1 200 OK\r\nContent-Length: %d\r\nConnection: close\r\n\r\n%s", len(body), body)
+	}()
+
+	engine, err := NewEngine(&Config{
+		Timeout:     5 * time.Second,
+		RateLimit:   10 * time.Millisecond,
+		BurstSize:   1,
+		Headers:     map[string][]string{"Accept-Language": {"en-US"}, "Accept": {"text/html"}},
+		HeaderOrder: &HeaderOrderConfig{Profile: TLSMimicFirefox},
+	})
+	if err != nil {
+		t.Fatalf("Failed to create engine: %v", err)
+	}
+
+	result, err := engine.Scrape(context.Background(), "http://"+listener.Addr().String()+"/", []FieldConfig{{Name: "title", Selector: "h1", Type: "text"}})
+	if err != nil {
+		t.Fatalf("Scraping failed: %v", err)
+	}
+	if result.Data["title"] != "ok" {
+		t.Errorf("unexpected data: %v", result.Data)
+	}
+
+	got := strings.Join(headerNames(<-heads), ",")
+	if want := "Host,User-Agent,Accept,Accept-Language,Accept-Encoding"; got != want {
+		t.Errorf("header order = %s, want %s", got, want)
+	}
+
+	if _, err := NewEngine(&Config{Timeout: time.Second, HeaderOrder: &HeaderOrderConfig{}}); err == nil {
+		t.Error("expected error for header_order without a profile, order or tls.mimic")
+	}
+}
+
+// stalledTLSServer returns the address of a server that accepts connections but
+// never answers the ClientHello
+func stalledTLSServer(t *testing.T) string {
+	t.Helper()
+	listener, err := net.Listen("tcp", "127.0.0.1:0")
+	if err != nil {
+		t.Fatalf("Failed to listen: %v", err)
+	}
+	t.Cleanup(func() { listener.Close() })
+	go func() {
+		var conns []net.Conn
+		defer func() {
+			for _, conn := range conns {
+				conn.Close()
+			}
+		}()
+		for {
+			conn, err := listener.Accept()
+			if err != nil {
+				return
+			}
+			conns = append(conns, conn)
+		}
+	}()
+	return listener.Addr().String()
+}
+
+func TestHTTP1DialTLSHandshakeTimeout(t *testing.T) {
+	addr := stalledTLSServer(t)
+	dialTLS := newHTTP1DialTLS(&TransportConfig{TLSHandshakeTimeout: 100 * time.Millisecond})
+
+	start := time.Now()
+	if _, err := dialTLS(context.Background(), "tcp", addr); err == nil {
+		t.Fatal("Expected the stalled handshake to fail")
+	}
+	if elapsed := time.Since(start); elapsed > 5*time.Second {
+		t.Errorf("Expected the handshake to time out after 100ms, took %v", elapsed)
+	}
+}
@@ -0,0 +1,121 @@
+// internal/scraper/hidden_content.go
+package scraper
+
+import (
+	"strings"
+
+	"github.com/PuerkitoBio/goquery"
+	"golang.org/x/net/html"
+	"golang.org/x/net/html/atom"
+)
+
+// HiddenContentConfig chooses whether content the browser does not render counts
+// toward extracted text. Nil fields take the defaults: noscript is included,
+// template, script and style are excluded.
+type HiddenContentConfig struct {
+	Noscript *bool `yaml:"noscript,omitempty" json:"noscript,omitempty"`
+	Template *bool `yaml:"template,omitempty" json:"template,omitempty"`
+	Script   *bool `yaml:"script,omitempty" json:"script,omitempty"`
+	Style    *bool `yaml:"style,omitempty" json:"style,omitempty"`
+}
+
+// hiddenContent is HiddenContentConfig with the defaults applied
+type hiddenContent struct {
+	noscript, template, script, style bool
+}
+
+func newHiddenContent(cfg *HiddenContentConfig) hiddenContent {
+	h := hiddenContent{noscript: true}
+	if cfg == nil {
+		return h
+	}
+	for _, opt := range []struct {
+		value *bool
+		dst   *bool
+	}{
+		{cfg.Noscript, &h.noscript},
+		{cfg.Template, &h.template},
+		{cfg.Script, &h.script},
+		{cfg.Style, &h.style},
+	} {
+		if opt.value != nil {
+			*opt.dst = *opt.value
+		}
+	}
+	return h
+}
+
+// excludes reports whether the content of n is left out of extraction
+func (h hiddenContent) excludes(n *html.Node) bool {
+	if n.Type != html.ElementNode {
+		return false
+	}
+	switch n.DataAtom {
+	case atom.Noscript:
+		return !h.noscript
+	case atom.Template:
+		return !h.template
+	case atom.Script:
+		return !h.script
+	case atom.Style:
+		return !h.style
+	}
+	return false
+}
+
+// expandNoscript replaces the raw text the parser leaves inside <noscript> with
+// the elements it contains, so selectors and text extraction can see them
+func (h hiddenContent) expandNoscript(doc *goquery.Document) {
+	if !h.noscript {
+		return
+	}
+	context := &html.Node{Type: html.ElementNode, Data: "div", DataAtom: atom.Div}
+	doc.Find("noscript").Each(func(_ int, s *goquery.Selection) {
+		n := s.Get(0)
+		if n.FirstChild == nil || n.FirstChild != n.LastChild || n.FirstChild.Type != html.TextNode {
+			return
+		}
+		nodes, err := html.ParseFragment(strings.NewReader(n.FirstChild.Data), context)
+		if err != nil {
+			return
+		}
+		n.RemoveChild(n.FirstChild)
+		for _, child := range nodes {
+			n.AppendChild(child)
+		}
+	})
+}
+
+// visible drops matches that sit inside excluded content. A match that is
+// itself an excluded element is kept, since the selector asked for it.
+func (h hiddenContent) visible(sel *goquery.Selection) *goquery.Selection {
+	return sel.FilterFunction(func(_ int, s *goquery.Selection) bool {
+		for p := s.Get(0).Parent; p != nil; p = p.Parent {
+			if h.excludes(p) {
+				return false
+			}
+		}
+		return true
+	})
+}
+
+// text returns the text of sel like Selection.Text, skipping excluded elements
+func (h hiddenContent) text(sel *goquery.Selection) string {
+	var b strings.Builder
+	var walk func(n *html.Node)
+	walk = func(n *html.Node) {
+		if n.Type == html.TextNode {
+			b.WriteString(n.Data)
+			return
+		}
+		for c := n.FirstChild; c != nil; c = c.NextSibling {
+			if !h.excludes(c) {
+				walk(c)
+			}
+		}
+	}
+	for _, n := range sel.Nodes {
+		walk(n)
+	}
+	return b.String()
+}
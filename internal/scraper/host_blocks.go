@@ -0,0 +1,133 @@
+// internal/scraper/host_blocks.go
+package scraper
+
+import (
+	"fmt"
+	"sort"
+	"sync"
+	"time"
+
+	"github.com/valpere/DataScrapexter/internal/utils"
+)
+
+var blockLogger = utils.NewComponentLogger("host-blocks")
+
+// ErrHostAbandoned is returned for URLs on a host that block_abort has dropped from the run
+var ErrHostAbandoned = fmt.Errorf("host abandoned after repeated block responses")
+
+// BlockAbortConfig drops a host for the rest of the run once it has answered
+// Threshold requests with a block status (HTTP 403 or 429) within Window.
+// Unlike retries and the circuit breaker, an abandoned host is never tried
+// again, so a multi-host run stops spending its budget on a site that has
+// already banned it.
+type BlockAbortConfig struct {
+	Threshold int `yaml:"threshold" json:"threshold"`
+
+	// Window is how far back blocks are counted; zero counts the whole run
+	Window time.Duration `yaml:"window,omitempty" json:"window,omitempty"`
+}
+
+// hostBlockTracker counts block responses per host and remembers abandoned hosts.
+// A nil tracker never abandons anything.
+type hostBlockTracker struct {
+	mu        sync.Mutex
+	threshold int
+	window    time.Duration
+	blocks    map[string][]time.Time
+	abandoned map[string]bool
+	now       func() time.Time
+}
+
+// newHostBlockTracker returns nil unless cfg sets a positive threshold
+func newHostBlockTracker(cfg *BlockAbortConfig) *hostBlockTracker {
+	if cfg == nil || cfg.Threshold <= 0 {
+		return nil
+	}
+	return &hostBlockTracker{
+		threshold: cfg.Threshold,
+		window:    cfg.Window,
+		blocks:    make(map[string][]time.Time),
+		abandoned: make(map[string]bool),
+		now:       time.Now,
+	}
+}
+
+// isAbandoned reports whether host has been dropped from the run
+func (t *hostBlockTracker) isAbandoned(host string) bool {
+	if t == nil {
+		return false
+	}
+	t.mu.Lock()
+	defer t.mu.Unlock()
+	return t.abandoned[host]
+}
+
+// record counts a block response from host and reports whether it made the host abandoned
+func (t *hostBlockTracker) record(host string) bool {
+	if t == nil {
+		return false
+	}
+	t.mu.Lock()
+	defer t.mu.Unlock()
+
+	if t.abandoned[host] {
+		return false
+	}
+
+	now := t.now()
+	times := append(t.blocks[host], now)
+	if t.window > 0 {
+		cutoff := now.Add(-t.window)
+		kept := times[:0]
+		for _, at := range times {
+			if at.After(cutoff) {
+				kept = append(kept, at)
+			}
+		}
+		times = kept
+	}
+
+	if len(times) < t.threshold {
+		t.blocks[host] = times
+		return false
+	}
+
+	delete(t.blocks, host)
+	t.abandoned[host] = true
+	return true
+}
+
+// hosts returns the abandoned hosts in sorted order
+func (t *hostBlockTracker) hosts() []string {
+	if t == nil {
+		return nil
+	}
+	t.mu.Lock()
+	defer t.mu.Unlock()
+
+	hosts := make([]string, 0, len(t.abandoned))
+	for host := range t.abandoned {
+		hosts = append(hosts, host)
+	}
+	sort.Strings(hosts)
+	return hosts
+}
+
+// recordBlock counts a block response for url's host and logs when the host is dropped
+func (e *Engine) recordBlock(url string, statusCode int) {
+	host := requestHost(url)
+	if e.hostBlocks.record(host) {
+		if e.config.BlockAbort.Window > 0 {
+			blockLogger.Warnf("Abandoning host %s for the rest of the run: %d block responses within %v (last HTTP %d)",
+				host, e.config.BlockAbort.Threshold, e.config.BlockAbort.Window, statusCode)
+		} else {
+			blockLogger.Warnf("Abandoning host %s for the rest of the run: %d block responses (last HTTP %d)",
+				host, e.config.BlockAbort.Threshold, statusCode)
+		}
+	}
+}
+
+// AbandonedHosts returns the hosts block_abort has dropped from this run
+func (e *Engine) AbandonedHosts() []string {
+	return e.hostBlocks.hosts()
+}
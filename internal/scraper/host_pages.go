@@ -0,0 +1,65 @@
+// internal/scraper/host_pages.go
+package scraper
+
+import (
+	"fmt"
+	"sync"
+)
+
+// ErrHostPageLimit is returned for URLs on a host that has used up max_pages_per_host
+var ErrHostPageLimit = fmt.Errorf("host reached max_pages_per_host")
+
+// hostPageCounter counts pages scraped per host and enforces an optional cap,
+// so one large site cannot take the whole budget of a multi-host run.
+// A nil counter counts nothing and never refuses a page.
+type hostPageCounter struct {
+	mu      sync.Mutex
+	limit   int // Zero means no cap; pages are still counted for the summary
+	pages   map[string]int
+	skipped map[string]int
+}
+
+func newHostPageCounter(limit int) *hostPageCounter {
+	return &hostPageCounter{
+		limit:   limit,
+		pages:   make(map[string]int),
+		skipped: make(map[string]int),
+	}
+}
+
+// reserve counts a page for host, or reports false and counts a skip once the host is at its cap
+func (c *hostPageCounter) reserve(host string) bool {
+	if c == nil {
+		return true
+	}
+	c.mu.Lock()
+	defer c.mu.Unlock()
+
+	if c.limit > 0 && c.pages[host] >= c.limit {
+		c.skipped[host]++
+		return false
+	}
+	c.pages[host]++
+	return true
+}
+
+// snapshot copies the per-host page and skip counts
+func (c *hostPageCounter) snapshot() (pages, skipped map[string]int) {
+	if c == nil {
+		return nil, nil
+	}
+	c.mu.Lock()
+	defer c.mu.Unlock()
+
+	pages = make(map[string]int, len(c.pages))
+	for host, n := range c.pages {
+		pages[host] = n
+	}
+	if len(c.skipped) > 0 {
+		skipped = make(map[string]int, len(c.skipped))
+		for host, n := range c.skipped {
+			skipped[host] = n
+		}
+	}
+	return pages, skipped
+}
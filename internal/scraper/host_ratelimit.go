@@ -0,0 +1,152 @@
+// internal/scraper/host_ratelimit.go
+package scraper
+
+import (
+	"net"
+	"sync"
+	"time"
+)
+
+// HostRateLimit overrides the rate limit for one host
+type HostRateLimit struct {
+	RateLimit time.Duration `yaml:"rate_limit" json:"rate_limit"`                     // Least time between requests to the host
+	BurstSize int           `yaml:"burst_size,omitempty" json:"burst_size,omitempty"` // Default: the global burst size, else 1
+}
+
+// hostRateLimiters lazily creates a rate limiter per host, so every host gets
+// its own token bucket instead of sharing one across the run. Hosts without an
+// override use the default config; with no default they are not limited.
+type hostRateLimiters struct {
+	mu        sync.Mutex
+	limiters  map[string]*AdaptiveRateLimiter
+	base      *RateLimiterConfig // nil leaves hosts without an override unlimited
+	overrides map[string]HostRateLimit
+	strategy  *RateLimitStrategy // Set by setStrategy, for limiters created later
+}
+
+// newHostRateLimiters returns the per-host limiters for base and overrides, or
+// nil when neither limits anything
+func newHostRateLimiters(base *RateLimiterConfig, overrides map[string]HostRateLimit) *hostRateLimiters {
+	if base == nil && len(overrides) == 0 {
+		return nil
+	}
+	return &hostRateLimiters{
+		limiters:  make(map[string]*AdaptiveRateLimiter),
+		base:      base,
+		overrides: overrides,
+	}
+}
+
+// get returns the limiter for host, creating it on first use, or nil when the
+// host is not limited
+func (h *hostRateLimiters) get(host string) *AdaptiveRateLimiter {
+	if h == nil {
+		return nil
+	}
+	h.mu.Lock()
+	defer h.mu.Unlock()
+
+	if rl, ok := h.limiters[host]; ok {
+		return rl
+	}
+	config := h.configFor(host)
+	if config == nil {
+		return nil
+	}
+	if h.strategy != nil {
+		config.Strategy = *h.strategy
+	}
+	rl := NewAdaptiveRateLimiter(config)
+	h.limiters[host] = rl
+	return rl
+}
+
+// configFor returns a copy of the config for host: the default with the host's
+// override applied, matched by host:port first and then by hostname
+func (h *hostRateLimiters) configFor(host string) *RateLimiterConfig {
+	override, ok := h.overrides[host]
+	if !ok {
+		if hostname, _, err := net.SplitHostPort(host); err == nil {
+			override, ok = h.overrides[hostname]
+		}
+	}
+
+	if !ok {
+		if h.base == nil {
+			return nil
+		}
+		config := *h.base
+		return &config
+	}
+
+	config := RateLimiterConfig{Strategy: StrategyFixed}
+	if h.base != nil {
+		config = *h.base
+	}
+	config.BaseInterval = override.RateLimit
+	config.MaxInterval = override.RateLimit * 10
+	if override.BurstSize > 0 {
+		config.BurstSize = override.BurstSize
+	}
+	if config.BurstSize <= 0 {
+		config.BurstSize = 1
+	}
+	return &config
+}
+
+// each calls fn for every limiter created so far
+func (h *hostRateLimiters) each(fn func(host string, rl *AdaptiveRateLimiter)) {
+	if h == nil {
+		return
+	}
+	h.mu.Lock()
+	defer h.mu.Unlock()
+
+	for host, rl := range h.limiters {
+		fn(host, rl)
+	}
+}
+
+// setStrategy switches every limiter, and those created later, to strategy
+func (h *hostRateLimiters) setStrategy(strategy RateLimitStrategy) {
+	if h == nil {
+		return
+	}
+	h.mu.Lock()
+	defer h.mu.Unlock()
+
+	h.strategy = &strategy
+	for _, rl := range h.limiters {
+		rl.SetStrategy(strategy)
+	}
+}
+
+// stats combines the hosts' statistics: counts are summed, the base rate is
+// the default's and the current interval is the slowest host's. It is nil
+// before any host was limited.
+func (h *hostRateLimiters) stats() *RateLimiterStats {
+	var combined *RateLimiterStats
+	h.each(func(_ string, rl *AdaptiveRateLimiter) {
+		stats := rl.GetStats()
+		if combined == nil {
+			combined = stats
+			return
+		}
+		if stats.CurrentInterval > combined.CurrentInterval {
+			combined.CurrentInterval = stats.CurrentInterval
+			combined.CurrentBurstSize = stats.CurrentBurstSize
+		}
+		combined.SuccessCount += stats.SuccessCount
+		combined.ErrorCount += stats.ErrorCount
+		combined.ConsecutiveErrs = max(combined.ConsecutiveErrs, stats.ConsecutiveErrs)
+		combined.RecentErrors += stats.RecentErrors
+		combined.BurstTokens += stats.BurstTokens
+	})
+	if combined != nil && h.base != nil {
+		combined.BaseInterval, combined.BaseBurstSize = h.base.BaseInterval, h.base.BurstSize
+	}
+	if combined != nil && combined.SuccessCount+combined.ErrorCount > 0 {
+		combined.ErrorRate = float64(combined.ErrorCount) / float64(combined.SuccessCount+combined.ErrorCount)
+	}
+	return combined
+}
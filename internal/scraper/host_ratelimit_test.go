@@ -0,0 +1,80 @@
+// internal/scraper/host_ratelimit_test.go
+package scraper
+
+import (
+	"context"
+	"fmt"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"testing"
+	"time"
+)
+
+func TestHostRateLimitsAreIndependent(t *testing.T) {
+	handler := http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		fmt.Fprint(w, "<html><body><h1>Page</h1></body></html>")
+	})
+	slow := httptest.NewServer(handler)
+	defer slow.Close()
+	fast := httptest.NewServer(handler)
+	defer fast.Close()
+
+	slowHost := strings.TrimPrefix(slow.URL, "http://")
+	engine, err := NewEngine(&Config{
+		Timeout:        10 * time.Second,
+		RateLimit:      10 * time.Millisecond,
+		BurstSize:      1,
+		HostRateLimits: map[string]HostRateLimit{slowHost: {RateLimit: time.Second}},
+	})
+	if err != nil {
+		t.Fatalf("NewEngine failed: %v", err)
+	}
+
+	fields := []FieldConfig{{Name: "title", Selector: "h1", Type: "text"}}
+	scrape := func(url string) {
+		t.Helper()
+		if _, err := engine.Scrape(context.Background(), url, fields); err != nil {
+			t.Fatalf("Scrape(%s) failed: %v", url, err)
+		}
+	}
+
+	// Use up the slow host's token; the fast host must not wait for it
+	slowStart := time.Now()
+	scrape(slow.URL)
+	start := time.Now()
+	for i := 0; i < 3; i++ {
+		scrape(fast.URL)
+	}
+	if elapsed := time.Since(start); elapsed > 500*time.Millisecond {
+		t.Errorf("fast host took %v for 3 pages, want it paced by its own limit", elapsed)
+	}
+
+	// The slow host is still paced by its override
+	scrape(slow.URL)
+	if elapsed := time.Since(slowStart); elapsed < 900*time.Millisecond {
+		t.Errorf("two slow-host pages took %v, want the 1s host limit between them", elapsed)
+	}
+
+	if stats := engine.GetHostRateLimiterStats(slowHost); stats == nil || stats.BaseInterval != time.Second {
+		t.Errorf("slow host stats = %+v, want base interval 1s", stats)
+	}
+	if stats := engine.GetHostRateLimiterStats(strings.TrimPrefix(fast.URL, "http://")); stats == nil || stats.BaseInterval != 10*time.Millisecond {
+		t.Errorf("fast host stats = %+v, want base interval 10ms", stats)
+	}
+}
+
+func TestHostRateLimitOverrideMatchesHostname(t *testing.T) {
+	limiters := newHostRateLimiters(nil, map[string]HostRateLimit{"example.com": {RateLimit: time.Second, BurstSize: 3}})
+
+	rl := limiters.get("example.com:8080")
+	if rl == nil {
+		t.Fatal("expected a limiter for example.com:8080")
+	}
+	if stats := rl.GetStats(); stats.BaseInterval != time.Second || stats.BaseBurstSize != 3 {
+		t.Errorf("got interval %v burst %d, want 1s and 3", stats.BaseInterval, stats.BaseBurstSize)
+	}
+	if limiters.get("other.com") != nil {
+		t.Error("host without an override or default should not be limited")
+	}
+}
@@ -0,0 +1,125 @@
+// internal/scraper/infinite_scroll.go
+package scraper
+
+import (
+	"context"
+	"crypto/sha256"
+	"fmt"
+	"strings"
+	"time"
+
+	"github.com/PuerkitoBio/goquery"
+)
+
+// DefaultScrollPause is the wait after each scroll when scroll_pause is unset
+const DefaultScrollPause = time.Second
+
+// pageScroller is the browser page infinite_scroll pagination works on;
+// *browser.BrowserManager implements it
+type pageScroller interface {
+	Navigate(ctx context.Context, url string) error
+	CurrentHTML(ctx context.Context) (string, error)
+	ScrollToBottom(ctx context.Context) error
+}
+
+// scrapeInfiniteScroll loads baseURL in the browser and scrolls it to the
+// bottom up to MaxPages times, waiting ScrollPause after each scroll. The first
+// render and every scroll make one page, extracted with only the ItemSelector
+// items not seen before left in the document; items are compared by their HTML.
+// Scrolling stops at the first render without a new item.
+func (e *Engine) scrapeInfiniteScroll(ctx context.Context, baseURL string, extractors []FieldConfig) (*PaginationResult, error) {
+	if e.scroller == nil {
+		return nil, fmt.Errorf("infinite_scroll pagination requires browser automation (browser.enabled)")
+	}
+	if err := e.checkRobots(ctx, baseURL); err != nil {
+		return nil, err
+	}
+
+	startTime := time.Now()
+	if err := e.scroller.Navigate(ctx, baseURL); err != nil {
+		return nil, fmt.Errorf("failed to load %s: %w", baseURL, err)
+	}
+
+	maxScrolls := e.config.Pagination.MaxPages
+	if maxScrolls <= 0 {
+		maxScrolls = 10 // Default safety limit
+	}
+	pause := e.config.Pagination.ScrollPause
+	if pause == 0 {
+		pause = DefaultScrollPause
+	}
+
+	seen := make(map[[sha256.Size]byte]bool)
+	results := make([]ScrapingResult, 0)
+	errors := make([]string, 0)
+	for scroll := 0; scroll <= maxScrolls; scroll++ {
+		if scroll > 0 {
+			if err := e.scroller.ScrollToBottom(ctx); err != nil {
+				errors = append(errors, fmt.Sprintf("Scroll %d failed: %v", scroll, err))
+				break
+			}
+			select {
+			case <-time.After(pause):
+			case <-ctx.Done():
+				return nil, ctx.Err()
+			}
+		}
+
+		html, err := e.scroller.CurrentHTML(ctx)
+		if err != nil {
+			errors = append(errors, fmt.Sprintf("Scroll %d failed: %v", scroll, err))
+			break
+		}
+		doc, err := goquery.NewDocumentFromReader(strings.NewReader(html))
+		if err != nil {
+			errors = append(errors, fmt.Sprintf("Scroll %d failed: failed to parse HTML from browser: %v", scroll, err))
+			break
+		}
+		if keepNewItems(doc, e.config.Pagination.ItemSelector, seen) == 0 {
+			break
+		}
+
+		result := &Result{Data: make(map[string]interface{}), Timestamp: time.Now()}
+		_, successCount, _ := e.extractFields(ctx, doc, extractors, result)
+		results = append(results, ScrapingResult{
+			URL:        baseURL,
+			StatusCode: 200,
+			Data:       result.Data,
+			Success:    len(extractors) == 0 || successCount > 0,
+			Errors:     result.Errors,
+			Warnings:   result.Warnings,
+		})
+	}
+
+	return &PaginationResult{
+		Pages:          results,
+		TotalPages:     len(results),
+		ProcessedPages: len(results),
+		Success:        len(results) > 0,
+		Errors:         errors,
+		Duration:       time.Since(startTime),
+		StartTime:      startTime,
+		EndTime:        time.Now(),
+	}, nil
+}
+
+// keepNewItems removes the items matching selector that are in seen, or
+// repeat an item earlier in doc, and adds the rest to seen. It returns how many
+// items were kept.
+func keepNewItems(doc *goquery.Document, selector string, seen map[[sha256.Size]byte]bool) int {
+	kept := 0
+	doc.Find(selector).Each(func(_ int, item *goquery.Selection) {
+		html, err := goquery.OuterHtml(item)
+		if err != nil {
+			return
+		}
+		hash := sha256.Sum256([]byte(html))
+		if seen[hash] {
+			item.Remove()
+			return
+		}
+		seen[hash] = true
+		kept++
+	})
+	return kept
+}
@@ -0,0 +1,118 @@
+// internal/scraper/infinite_scroll_test.go
+package scraper
+
+import (
+	"context"
+	"fmt"
+	"strings"
+	"testing"
+	"time"
+)
+
+// mockScrollPage renders a feed that shows batch more posts on every scroll,
+// below a pinned post that is present on every render
+type mockScrollPage struct {
+	posts    int
+	batch    int
+	rendered int
+	scrolls  int
+}
+
+func (p *mockScrollPage) Navigate(ctx context.Context, url string) error {
+	p.rendered = min(p.batch, p.posts)
+	return nil
+}
+
+func (p *mockScrollPage) ScrollToBottom(ctx context.Context) error {
+	p.scrolls++
+	p.rendered = min(p.rendered+p.batch, p.posts)
+	return nil
+}
+
+func (p *mockScrollPage) CurrentHTML(ctx context.Context) (string, error) {
+	var b strings.Builder
+	b.WriteString(`<html><body><h1>Feed</h1><ul><li class="post"><h2>Pinned</h2></li>`)
+	for i := 1; i <= p.rendered; i++ {
+		fmt.Fprintf(&b, `<li class="post"><h2>Post %d</h2></li>`, i)
+	}
+	b.WriteString(`</ul></body></html>`)
+	return b.String(), nil
+}
+
+func newInfiniteScrollEngine(t *testing.T, maxPages int) *Engine {
+	t.Helper()
+	engine, err := NewEngine(&Config{
+		Timeout: 10 * time.Second,
+		Pagination: &PaginationConfig{
+			Enabled:      true,
+			Type:         PaginationTypeInfiniteScroll,
+			ItemSelector: ".post",
+			ScrollPause:  time.Millisecond,
+			MaxPages:     maxPages,
+		},
+	})
+	if err != nil {
+		t.Fatalf("Failed to create engine: %v", err)
+	}
+	return engine
+}
+
+func TestScrapeWithInfiniteScrollPagination(t *testing.T) {
+	engine := newInfiniteScrollEngine(t, 10)
+	page := &mockScrollPage{posts: 7, batch: 3}
+	engine.scroller = page
+
+	extractors := []FieldConfig{
+		{Name: "heading", Selector: "h1", Type: "text"},
+		{Name: "posts", Selector: ".post h2", Type: "list"},
+	}
+	result, err := engine.ScrapeWithPagination(context.Background(), "https://news.example.com/feed", extractors)
+	if err != nil {
+		t.Fatalf("Infinite scroll pagination failed: %v", err)
+	}
+
+	var batches []string
+	for _, p := range result.Pages {
+		if p.Data["heading"] != "Feed" {
+			t.Errorf("Expected the page around the items to be kept, got heading %v", p.Data["heading"])
+		}
+		batches = append(batches, fmt.Sprint(p.Data["posts"]))
+	}
+	// The pinned post is scraped once, and each scroll only adds the posts it rendered
+	expected := []string{"[Pinned Post 1 Post 2 Post 3]", "[Post 4 Post 5 Post 6]", "[Post 7]"}
+	if fmt.Sprint(batches) != fmt.Sprint(expected) {
+		t.Errorf("Expected batches %v, got %v", expected, batches)
+	}
+	// The scroll after the last post renders nothing new and ends pagination
+	if page.scrolls != 3 {
+		t.Errorf("Expected 3 scrolls, got %d", page.scrolls)
+	}
+	if !result.Success || result.ProcessedPages != 3 {
+		t.Errorf("Expected 3 processed pages, got %d (success %t)", result.ProcessedPages, result.Success)
+	}
+}
+
+func TestScrapeWithInfiniteScrollPaginationMaxPages(t *testing.T) {
+	engine := newInfiniteScrollEngine(t, 1)
+	page := &mockScrollPage{posts: 100, batch: 5}
+	engine.scroller = page
+
+	result, err := engine.ScrapeWithPagination(context.Background(), "https://news.example.com/feed",
+		[]FieldConfig{{Name: "posts", Selector: ".post h2", Type: "list"}})
+	if err != nil {
+		t.Fatalf("Infinite scroll pagination failed: %v", err)
+	}
+	if page.scrolls != 1 || len(result.Pages) != 2 {
+		t.Errorf("Expected max_pages to allow 1 scroll (2 pages), got %d scrolls and %d pages", page.scrolls, len(result.Pages))
+	}
+}
+
+func TestScrapeWithInfiniteScrollPaginationNeedsBrowser(t *testing.T) {
+	engine := newInfiniteScrollEngine(t, 10)
+
+	_, err := engine.ScrapeWithPagination(context.Background(), "https://news.example.com/feed",
+		[]FieldConfig{{Name: "posts", Selector: ".post h2", Type: "list"}})
+	if err == nil || !strings.Contains(err.Error(), "browser") {
+		t.Errorf("Expected an error asking for the browser, got %v", err)
+	}
+}
@@ -0,0 +1,67 @@
+// internal/scraper/lazy_images.go
+package scraper
+
+import "github.com/PuerkitoBio/goquery"
+
+// DefaultLazySrcAttributes and DefaultLazySrcsetAttributes are where lazy-loading
+// scripts commonly park the real image URL until the element scrolls into view
+var (
+	DefaultLazySrcAttributes    = []string{"data-src", "data-original", "data-lazy-src", "data-lazy"}
+	DefaultLazySrcsetAttributes = []string{"data-srcset", "data-lazy-srcset"}
+)
+
+// LazyImageConfig sets the attribute precedence for attr fields reading src or
+// srcset. The listed attributes are tried in order before the requested one, and
+// the first non-empty value wins. Empty lists take the defaults above.
+type LazyImageConfig struct {
+	Disabled bool     `yaml:"disabled,omitempty" json:"disabled,omitempty"` // Read src and srcset as written
+	Src      []string `yaml:"src,omitempty" json:"src,omitempty"`
+	Srcset   []string `yaml:"srcset,omitempty" json:"srcset,omitempty"`
+}
+
+// attributes returns the precedence used to read attr, ending with attr itself
+func (c *LazyImageConfig) attributes(attr string) []string {
+	if c != nil && c.Disabled {
+		return []string{attr}
+	}
+
+	var lazy []string
+	switch attr {
+	case "src":
+		lazy = DefaultLazySrcAttributes
+		if c != nil && len(c.Src) > 0 {
+			lazy = c.Src
+		}
+	case "srcset":
+		lazy = DefaultLazySrcsetAttributes
+		if c != nil && len(c.Srcset) > 0 {
+			lazy = c.Srcset
+		}
+	default:
+		return []string{attr}
+	}
+
+	order := make([]string, 0, len(lazy)+1)
+	for _, name := range lazy {
+		if name != attr {
+			order = append(order, name)
+		}
+	}
+	return append(order, attr)
+}
+
+// attr reads attr from sel, preferring lazy-load attributes; exists reports
+// whether any of them is present, even if empty
+func (c *LazyImageConfig) attr(sel *goquery.Selection, attr string) (value string, exists bool) {
+	for _, name := range c.attributes(attr) {
+		v, ok := sel.Attr(name)
+		if !ok {
+			continue
+		}
+		if v != "" {
+			return v, true
+		}
+		exists = true
+	}
+	return "", exists
+}
@@ -0,0 +1,141 @@
+// internal/scraper/malformed_html.go
+package scraper
+
+import (
+	"bytes"
+	"fmt"
+	"strings"
+
+	"github.com/valpere/DataScrapexter/internal/utils"
+	"golang.org/x/net/html"
+	"golang.org/x/net/html/atom"
+)
+
+var malformedLogger = utils.NewComponentLogger("malformed-html")
+
+// DefaultMaxUnbalancedTags is how many unbalanced tags malformed_html tolerates when unset
+const DefaultMaxUnbalancedTags = 50
+
+// MalformedHTMLConfig turns on checks for pages so broken, or so cut off, that
+// the parsed tree may have lost content. A detected page gets a warning naming
+// the damage and its field coverage; with Retry it is fetched again, which goes
+// through the next proxy when rotation is on, in case the response was partial.
+type MalformedHTMLConfig struct {
+	// MaxUnbalancedTags is how many unclosed or stray end tags a page may have;
+	// zero means DefaultMaxUnbalancedTags. Tags whose end tag is optional are not counted.
+	MaxUnbalancedTags int `yaml:"max_unbalanced_tags,omitempty" json:"max_unbalanced_tags,omitempty"`
+
+	// Retry re-fetches malformed pages that missed fields, up to MaxRetries times
+	// (DefaultEmptyRetries when unset), keeping the last attempt
+	Retry bool `yaml:"retry,omitempty" json:"retry,omitempty"`
+}
+
+func (c *MalformedHTMLConfig) maxUnbalanced() int {
+	if c.MaxUnbalancedTags > 0 {
+		return c.MaxUnbalancedTags
+	}
+	return DefaultMaxUnbalancedTags
+}
+
+// htmlDamage is what inspectHTML found wrong with a body
+type htmlDamage struct {
+	Unbalanced int  // Unclosed elements plus end tags with no open element
+	Truncated  bool // The body opens <html> but stops before </html>
+}
+
+func (d htmlDamage) String() string {
+	var parts []string
+	if d.Truncated {
+		parts = append(parts, "body ends before </html>")
+	}
+	if d.Unbalanced > 0 {
+		parts = append(parts, fmt.Sprintf("%d unbalanced tags", d.Unbalanced))
+	}
+	return strings.Join(parts, ", ")
+}
+
+// malformed reports whether the damage is past what cfg tolerates
+func (d htmlDamage) malformed(cfg *MalformedHTMLConfig) bool {
+	return d.Truncated || d.Unbalanced > cfg.maxUnbalanced()
+}
+
+// optionalEndTags are elements HTML lets authors leave open, plus void elements
+var optionalEndTags = map[atom.Atom]bool{
+	atom.Html: true, atom.Head: true, atom.Body: true, atom.P: true, atom.Li: true,
+	atom.Dt: true, atom.Dd: true, atom.Tr: true, atom.Td: true, atom.Th: true,
+	atom.Thead: true, atom.Tbody: true, atom.Tfoot: true, atom.Colgroup: true,
+	atom.Option: true, atom.Optgroup: true, atom.Rb: true, atom.Rt: true, atom.Rp: true,
+	atom.Area: true, atom.Base: true, atom.Br: true, atom.Col: true, atom.Embed: true,
+	atom.Hr: true, atom.Img: true, atom.Input: true, atom.Link: true, atom.Meta: true,
+	atom.Source: true, atom.Track: true, atom.Wbr: true,
+}
+
+// inspectHTML counts unbalanced tags in body with the tokenizer, which sees the
+// markup as written rather than the tree the parser repairs it into
+func inspectHTML(body []byte) htmlDamage {
+	var damage htmlDamage
+	open := make(map[string]int)
+	sawHTML, closedHTML := false, false
+
+	z := html.NewTokenizer(bytes.NewReader(body))
+	for {
+		tt := z.Next()
+		if tt == html.ErrorToken {
+			break
+		}
+		if tt != html.StartTagToken && tt != html.EndTagToken {
+			continue
+		}
+
+		name, _ := z.TagName()
+		tag := atom.Lookup(name)
+		if tag == atom.Html {
+			if tt == html.StartTagToken {
+				sawHTML = true
+			} else {
+				closedHTML = true
+			}
+		}
+		if optionalEndTags[tag] {
+			continue
+		}
+
+		key := string(name)
+		if tt == html.StartTagToken {
+			open[key]++
+		} else if open[key] > 0 {
+			open[key]--
+		} else {
+			damage.Unbalanced++
+		}
+	}
+
+	for _, n := range open {
+		damage.Unbalanced += n
+	}
+	if sawHTML && !closedHTML {
+		damage.Truncated = true
+	}
+	return damage
+}
+
+// checkMalformedHTML inspects the captured body of a page and, when it is past
+// the configured tolerance, warns with the field coverage. It reports whether
+// the page counts as malformed.
+func (e *Engine) checkMalformedHTML(snap *responseSnapshot, result *Result, extracted, total int) bool {
+	cfg := e.config.MalformedHTML
+	if cfg == nil || snap == nil || snap.Body == nil {
+		return false
+	}
+
+	damage := inspectHTML(snap.Body)
+	if !damage.malformed(cfg) {
+		return false
+	}
+
+	warning := fmt.Sprintf("Malformed HTML (%s): %d of %d fields extracted", damage, extracted, total)
+	result.Warnings = append(result.Warnings, warning)
+	malformedLogger.Warnf("%s: %s", snap.URL, warning)
+	e.requestStats.recordMalformedPage()
+	return true
+}
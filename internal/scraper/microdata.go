@@ -0,0 +1,213 @@
+// internal/scraper/microdata.go
+package scraper
+
+import (
+	"fmt"
+	"strings"
+
+	"github.com/PuerkitoBio/goquery"
+	"golang.org/x/net/html"
+)
+
+// extractMicrodataPath resolves a dot path against the page's top-level
+// microdata items, as extractJSONLDPath does for JSON-LD. An empty path or "$"
+// returns the matching items themselves, as a list.
+func extractMicrodataPath(doc *goquery.Document, path, itemType string) (interface{}, error) {
+	var nodes []interface{}
+	for _, item := range extractMicrodata(doc) {
+		nodes = append(nodes, item)
+	}
+
+	if path == "" || path == "$" {
+		var items []interface{}
+		for _, node := range nodes {
+			if itemType == "" || hasJSONLDType(node, itemType) {
+				items = append(items, node)
+			}
+		}
+		if len(items) == 0 {
+			if itemType != "" {
+				return nil, fmt.Errorf("no microdata items of type %s", itemType)
+			}
+			return nil, fmt.Errorf("no microdata items")
+		}
+		return items, nil
+	}
+	return resolveItemPath(nodes, path, itemType, "microdata")
+}
+
+// extractMicrodata returns one map per top-level item (an itemscope element
+// that is not itself an itemprop value), in page order. Each itemprop belongs
+// to its nearest enclosing itemscope, or to the item whose itemref names an
+// ancestor; nested items become nested maps. itemtype is reported as @type and
+// itemid as @id, so items filter like JSON-LD objects. A property seen more
+// than once becomes a list.
+func extractMicrodata(doc *goquery.Document) []map[string]interface{} {
+	if len(doc.Nodes) == 0 {
+		return nil
+	}
+
+	ids := make(map[string]*html.Node)
+	var tops []*html.Node
+	var index func(n *html.Node)
+	index = func(n *html.Node) {
+		if n.Type == html.ElementNode {
+			if id, ok := nodeAttr(n, "id"); ok {
+				if _, seen := ids[id]; !seen {
+					ids[id] = n
+				}
+			}
+			if _, scope := nodeAttr(n, "itemscope"); scope {
+				if _, prop := nodeAttr(n, "itemprop"); !prop {
+					tops = append(tops, n)
+				}
+			}
+		}
+		for c := n.FirstChild; c != nil; c = c.NextSibling {
+			index(c)
+		}
+	}
+	index(doc.Nodes[0])
+
+	items := make([]map[string]interface{}, 0, len(tops))
+	for _, top := range tops {
+		items = append(items, microdataItem(top, ids, map[*html.Node]bool{}))
+	}
+	return items
+}
+
+// microdataItem builds the map for the item rooted at scope. visiting holds the
+// items being built above it, so an itemref cycle cannot recurse forever.
+func microdataItem(scope *html.Node, ids map[string]*html.Node, visiting map[*html.Node]bool) map[string]interface{} {
+	item := make(map[string]interface{})
+	if itemType, ok := nodeAttr(scope, "itemtype"); ok {
+		if types := strings.Fields(itemType); len(types) == 1 {
+			item["@type"] = types[0]
+		} else if len(types) > 1 {
+			list := make([]interface{}, len(types))
+			for i, t := range types {
+				list[i] = t
+			}
+			item["@type"] = list
+		}
+	}
+	if id, ok := nodeAttr(scope, "itemid"); ok && id != "" {
+		item["@id"] = id
+	}
+
+	visiting[scope] = true
+	defer delete(visiting, scope)
+
+	// The item's own subtree first, then the elements its itemref names
+	roots := []*html.Node{scope}
+	if refs, ok := nodeAttr(scope, "itemref"); ok {
+		for _, ref := range strings.Fields(refs) {
+			if n, ok := ids[ref]; ok {
+				roots = append(roots, n)
+			}
+		}
+	}
+
+	seen := make(map[*html.Node]bool)
+	var crawl func(n *html.Node)
+	crawl = func(n *html.Node) {
+		if n.Type != html.ElementNode || seen[n] {
+			return
+		}
+		seen[n] = true
+		_, nested := nodeAttr(n, "itemscope")
+		nested = nested && n != scope
+
+		if names, ok := nodeAttr(n, "itemprop"); ok && n != scope {
+			var value interface{}
+			if nested {
+				if visiting[n] {
+					return
+				}
+				value = microdataItem(n, ids, visiting)
+			} else {
+				value = microdataValue(n)
+			}
+			for _, name := range strings.Fields(names) {
+				addMicrodataProperty(item, name, value)
+			}
+		}
+		if nested {
+			return // Properties below a nested itemscope belong to that item
+		}
+		for c := n.FirstChild; c != nil; c = c.NextSibling {
+			crawl(c)
+		}
+	}
+	for _, n := range roots {
+		crawl(n)
+	}
+	return item
+}
+
+// addMicrodataProperty adds value under name, turning a repeated property into a list
+func addMicrodataProperty(item map[string]interface{}, name string, value interface{}) {
+	existing, ok := item[name]
+	if !ok {
+		item[name] = value
+		return
+	}
+	if list, ok := existing.([]interface{}); ok {
+		item[name] = append(list, value)
+		return
+	}
+	item[name] = []interface{}{existing, value}
+}
+
+// microdataValue is an itemprop element's value, taken from the attribute the
+// HTML microdata spec assigns to its tag or else from its text
+func microdataValue(n *html.Node) interface{} {
+	attr := ""
+	switch n.Data {
+	case "meta":
+		attr = "content"
+	case "audio", "embed", "iframe", "img", "source", "track", "video":
+		attr = "src"
+	case "a", "area", "link":
+		attr = "href"
+	case "object":
+		attr = "data"
+	case "data", "meter":
+		attr = "value"
+	case "time":
+		if value, ok := nodeAttr(n, "datetime"); ok {
+			return strings.TrimSpace(value)
+		}
+	}
+	if attr != "" {
+		value, _ := nodeAttr(n, attr)
+		return strings.TrimSpace(value)
+	}
+	return strings.Join(strings.Fields(nodeText(n)), " ")
+}
+
+// nodeAttr returns the named attribute of n and whether it is present
+func nodeAttr(n *html.Node, name string) (string, bool) {
+	for _, a := range n.Attr {
+		if a.Namespace == "" && a.Key == name {
+			return a.Val, true
+		}
+	}
+	return "", false
+}
+
+// nodeText concatenates the text nodes under n
+func nodeText(n *html.Node) string {
+	var b strings.Builder
+	var walk func(n *html.Node)
+	walk = func(n *html.Node) {
+		if n.Type == html.TextNode {
+			b.WriteString(n.Data)
+		}
+		for c := n.FirstChild; c != nil; c = c.NextSibling {
+			walk(c)
+		}
+	}
+	walk(n)
+	return b.String()
+}
@@ -0,0 +1,133 @@
+// internal/scraper/microdata_test.go
+package scraper
+
+import (
+	"context"
+	"net/http"
+	"net/http/httptest"
+	"reflect"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/PuerkitoBio/goquery"
+)
+
+const microdataPage = `<html><body>
+<div itemscope itemtype="https://schema.org/Product" itemid="urn:sku:DL-100" itemref="lamp-rating">
+  <h2 itemprop="name">Desk Lamp</h2>
+  <img itemprop="image" src="https://shop.test/lamp.jpg">
+  <div itemprop="brand" itemscope itemtype="https://schema.org/Brand">
+    <span itemprop="name">Acme</span>
+  </div>
+  <div itemprop="offers" itemscope itemtype="https://schema.org/Offer">
+    <span itemprop="price">19.99</span>
+    <meta itemprop="priceCurrency" content="USD">
+    <link itemprop="availability" href="https://schema.org/InStock">
+  </div>
+  <div itemprop="offers" itemscope itemtype="https://schema.org/Offer">
+    <span itemprop="price">24.50</span>
+  </div>
+</div>
+<p id="lamp-rating">Rated <span itemprop="ratingValue">4.5</span></p>
+<div itemscope itemtype="https://schema.org/Product">
+  <h2 itemprop="name">Floor   Lamp</h2>
+  <time itemprop="releaseDate" datetime="2024-03-01">March</time>
+  <span itemprop="color material">Brass</span>
+</div>
+<div itemscope itemtype="https://schema.org/Organization"><span itemprop="name">Acme Store</span></div>
+</body></html>`
+
+func TestExtractMicrodata(t *testing.T) {
+	doc, err := goquery.NewDocumentFromReader(strings.NewReader(microdataPage))
+	if err != nil {
+		t.Fatal(err)
+	}
+	want := []map[string]interface{}{
+		{
+			"@type": "https://schema.org/Product",
+			"@id":   "urn:sku:DL-100",
+			"name":  "Desk Lamp",
+			"image": "https://shop.test/lamp.jpg",
+			"brand": map[string]interface{}{"@type": "https://schema.org/Brand", "name": "Acme"},
+			"offers": []interface{}{
+				map[string]interface{}{
+					"@type":         "https://schema.org/Offer",
+					"price":         "19.99",
+					"priceCurrency": "USD",
+					"availability":  "https://schema.org/InStock",
+				},
+				map[string]interface{}{"@type": "https://schema.org/Offer", "price": "24.50"},
+			},
+			"ratingValue": "4.5",
+		},
+		{
+			"@type":       "https://schema.org/Product",
+			"name":        "Floor Lamp",
+			"releaseDate": "2024-03-01",
+			"color":       "Brass",
+			"material":    "Brass",
+		},
+		{"@type": "https://schema.org/Organization", "name": "Acme Store"},
+	}
+	if got := extractMicrodata(doc); !reflect.DeepEqual(got, want) {
+		t.Errorf("extractMicrodata() =\n%#v\nwant\n%#v", got, want)
+	}
+}
+
+func TestExtractMicrodataItemrefCycle(t *testing.T) {
+	page := `<div itemscope id="a" itemref="b"><span itemprop="name">A</span></div>
+<div id="b"><div itemprop="self" itemscope itemref="a"><span itemprop="name">B</span></div></div>`
+	doc, err := goquery.NewDocumentFromReader(strings.NewReader(page))
+	if err != nil {
+		t.Fatal(err)
+	}
+	items := extractMicrodata(doc)
+	if len(items) != 1 || items[0]["name"] != "A" {
+		t.Fatalf("unexpected items: %#v", items)
+	}
+	if _, ok := items[0]["self"].(map[string]interface{}); !ok {
+		t.Errorf("self = %#v, want a nested item", items[0]["self"])
+	}
+}
+
+func TestScrapeMicrodataField(t *testing.T) {
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		w.Write([]byte(microdataPage))
+	}))
+	defer server.Close()
+
+	engine, err := NewEngine(&Config{Timeout: 10 * time.Second, RateLimit: 10 * time.Millisecond, BurstSize: 1})
+	if err != nil {
+		t.Fatalf("Failed to create engine: %v", err)
+	}
+	fields := []FieldConfig{
+		{Name: "names", Type: "microdata", Selector: "$[*].name", JSONLDType: "Product"},
+		{Name: "prices", Type: "microdata", Selector: "$.offers[*].price", JSONLDType: "Product", OutputType: OutputTypeNumber},
+		{Name: "brand", Type: "microdata", Selector: "$.brand.name", JSONLDType: "Product"},
+		{Name: "organization", Type: "microdata", Selector: "$.name", JSONLDType: "Organization"},
+		{Name: "organizations", Type: "microdata", Selector: "$", JSONLDType: "Organization"},
+		{Name: "gtin", Type: "microdata", Selector: "$.gtin13", JSONLDType: "Product"},
+	}
+
+	result, err := engine.Scrape(context.Background(), server.URL+"/lamps", fields)
+	if err != nil {
+		t.Fatalf("Scraping failed: %v", err)
+	}
+
+	want := map[string]interface{}{
+		"names":         []interface{}{"Desk Lamp", "Floor Lamp"},
+		"prices":        []interface{}{19.99, 24.5},
+		"brand":         "Acme",
+		"organization":  "Acme Store",
+		"organizations": []interface{}{map[string]interface{}{"@type": "https://schema.org/Organization", "name": "Acme Store"}},
+	}
+	for name, wantValue := range want {
+		if got := result.Data[name]; !reflect.DeepEqual(got, wantValue) {
+			t.Errorf("%s = %#v, want %#v", name, got, wantValue)
+		}
+	}
+	if _, ok := result.Data["gtin"]; ok {
+		t.Errorf("gtin should be missing, got %v", result.Data["gtin"])
+	}
+}
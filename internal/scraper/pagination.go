@@ -71,14 +71,20 @@ func (pm *PaginationManager) createStrategy() (PaginationStrategy, error) {
 			MaxPages:  pm.config.MaxPages,
 		}, nil
 
-	case "cursor":
+	case PaginationTypeCursor:
 		return &CursorStrategy{
 			BaseURL:        "",
-			CursorParam:    pm.config.PageParam, // Reuse page param for cursor
+			CursorParam:    pm.config.CursorParam,
 			LimitParam:     pm.config.LimitParam,
 			Limit:          pm.config.PageSize,
 			MaxPages:       pm.config.MaxPages,
-			CursorSelector: pm.config.ScrollSelector, // Reuse scroll selector for cursor
+			CursorSelector: pm.config.CursorSelector,
+			CursorAttr:     pm.config.CursorAttr,
+		}, nil
+
+	case PaginationTypeLinkHeader:
+		return &LinkHeaderStrategy{
+			MaxPages: pm.config.MaxPages,
 		}, nil
 
 	default:
@@ -146,11 +152,36 @@ func ValidatePaginationConfig(config *PaginationConfig) error {
 			config.PageParam = "page"
 		}
 
+	case PaginationTypeLinkHeader:
+		// Next URLs come from response headers; nothing to configure
+
+	case PaginationTypeSitemap:
+		u, err := url.Parse(config.SitemapURL)
+		if err != nil || (u.Scheme != "http" && u.Scheme != "https") || u.Host == "" {
+			return fmt.Errorf("sitemap_url must be an absolute http(s) URL for sitemap pagination")
+		}
+
+	case PaginationTypeCursor:
+		if config.CursorSelector == "" {
+			return fmt.Errorf("cursor_selector is required for cursor pagination")
+		}
+		if config.CursorParam == "" {
+			config.CursorParam = "cursor"
+		}
+
 	case PaginationTypeScrolling:
 		if config.ScrollSelector == "" && config.LoadMoreSelector == "" {
 			return fmt.Errorf("either scroll_selector or load_more_selector is required for scrolling pagination")
 		}
 
+	case PaginationTypeInfiniteScroll:
+		if config.ItemSelector == "" {
+			return fmt.Errorf("item_selector is required for infinite_scroll pagination")
+		}
+		if config.ScrollPause < 0 {
+			return fmt.Errorf("scroll_pause cannot be negative")
+		}
+
 	default:
 		return fmt.Errorf("unsupported pagination type: %s", config.Type)
 	}
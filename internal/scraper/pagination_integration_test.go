@@ -6,6 +6,7 @@ import (
 	"fmt"
 	"net/http"
 	"net/http/httptest"
+	"reflect"
 	"strings"
 	"testing"
 	"time"
@@ -319,6 +320,16 @@ func TestPaginationValidation(t *testing.T) {
 			expectError: true,
 			errorMsg:    "page_size must be greater than 0",
 		},
+		{
+			name: "Invalid Cursor - Missing Selector",
+			config: PaginationConfig{
+				Enabled:     true,
+				Type:        PaginationTypeCursor,
+				CursorParam: "after",
+			},
+			expectError: true,
+			errorMsg:    "cursor_selector is required",
+		},
 		{
 			name: "Invalid Next Button - Missing Selector",
 			config: PaginationConfig{
@@ -359,3 +370,195 @@ func TestPaginationValidation(t *testing.T) {
 		})
 	}
 }
+
+// TestLinkHeaderPagination tests following Link rel="next" response headers
+func TestLinkHeaderPagination(t *testing.T) {
+	var referers []string
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		page := r.URL.Query().Get("page")
+		if page == "" {
+			page = "1"
+		}
+		referers = append(referers, r.Header.Get("Referer"))
+
+		switch page {
+		case "1":
+			w.Header().Set("Link", `</items?page=2>; rel="next", </items?page=3>; rel="last"`)
+		case "2":
+			w.Header().Set("Link", `</items?page=1>; rel="prev first", </items?page=3>; rel="next"`)
+		case "3":
+			w.Header().Set("Link", `</items?page=2>; rel="prev"`)
+		}
+		fmt.Fprintf(w, `<html><body><div class="item">Item %s</div></body></html>`, page)
+	}))
+	defer server.Close()
+
+	engine, err := NewEngine(&Config{
+		Timeout:   10 * time.Second,
+		RateLimit: 10 * time.Millisecond,
+		BurstSize: 1,
+		Pagination: &PaginationConfig{
+			Enabled:  true,
+			Type:     PaginationTypeLinkHeader,
+			MaxPages: 10,
+		},
+	})
+	if err != nil {
+		t.Fatalf("Failed to create engine: %v", err)
+	}
+	defer engine.Close()
+
+	extractors := []FieldConfig{{Name: "item", Selector: ".item", Type: "text"}}
+	result, err := engine.ScrapeWithPagination(context.Background(), server.URL+"/items", extractors)
+	if err != nil {
+		t.Fatalf("Pagination scraping failed: %v", err)
+	}
+
+	if result.TotalPages != 3 {
+		t.Fatalf("Expected 3 pages, got %d (errors: %v)", result.TotalPages, result.Errors)
+	}
+	for i, page := range result.Pages {
+		expected := fmt.Sprintf("Item %d", i+1)
+		if page.Data["item"] != expected {
+			t.Errorf("Page %d: expected %q, got %v", i+1, expected, page.Data["item"])
+		}
+	}
+	// Each page is requested twice, to scrape it and to find its next link,
+	// both times with the page before it as Referer
+	expectedReferers := []string{"", "", server.URL + "/items", server.URL + "/items",
+		server.URL + "/items?page=2", server.URL + "/items?page=2"}
+	if !reflect.DeepEqual(referers, expectedReferers) {
+		t.Errorf("Expected Referers %q, got %q", expectedReferers, referers)
+	}
+}
+
+// TestCursorPagination tests feeding next_cursor from a JSON body into the next request
+func TestCursorPagination(t *testing.T) {
+	var cursors []string
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		cursor := r.URL.Query().Get("after")
+		cursors = append(cursors, cursor)
+
+		w.Header().Set("Content-Type", "application/json")
+		switch cursor {
+		case "":
+			fmt.Fprint(w, `{"items":[{"name":"Item 1"}],"page_info":{"next_cursor":"Y3Vyc29yOjE="}}`)
+		case "Y3Vyc29yOjE=":
+			fmt.Fprint(w, `{"items":[{"name":"Item 2"}],"page_info":{"next_cursor":"Y3Vyc29yOjI="}}`)
+		default:
+			fmt.Fprint(w, `{"items":[{"name":"Item 3"}],"page_info":{"next_cursor":null}}`)
+		}
+	}))
+	defer server.Close()
+
+	engine, err := NewEngine(&Config{
+		Timeout:   10 * time.Second,
+		RateLimit: 10 * time.Millisecond,
+		BurstSize: 1,
+		Pagination: &PaginationConfig{
+			Enabled:        true,
+			Type:           PaginationTypeCursor,
+			MaxPages:       10,
+			CursorSelector: "$.page_info.next_cursor",
+			CursorParam:    "after",
+		},
+	})
+	if err != nil {
+		t.Fatalf("Failed to create engine: %v", err)
+	}
+	defer engine.Close()
+
+	extractors := []FieldConfig{{Name: "item", Selector: "body", Type: "embedded_json", Path: "$.items[0].name"}}
+	result, err := engine.ScrapeWithPagination(context.Background(), server.URL+"/graphql", extractors)
+	if err != nil {
+		t.Fatalf("Pagination scraping failed: %v", err)
+	}
+
+	if result.TotalPages != 3 {
+		t.Fatalf("Expected 3 pages, got %d (errors: %v)", result.TotalPages, result.Errors)
+	}
+	for i, page := range result.Pages {
+		expected := fmt.Sprintf("Item %d", i+1)
+		if page.Data["item"] != expected {
+			t.Errorf("Page %d: expected %q, got %v", i+1, expected, page.Data["item"])
+		}
+	}
+	if result.Pages[2].URL != server.URL+"/graphql?after=Y3Vyc29yOjI%3D" {
+		t.Errorf("Expected the cursor in the query, got %s", result.Pages[2].URL)
+	}
+}
+
+func TestParseLinkHeader(t *testing.T) {
+	links := ParseLinkHeader([]string{
+		`<https://api.example.com/items?page=2>; rel="next", <https://api.example.com/items?page=9>; rel="last"`,
+		`<https://api.example.com/items?page=1>; rel="prev first"`,
+	})
+
+	expected := map[string]string{
+		"next":  "https://api.example.com/items?page=2",
+		"last":  "https://api.example.com/items?page=9",
+		"prev":  "https://api.example.com/items?page=1",
+		"first": "https://api.example.com/items?page=1",
+	}
+	for rel, target := range expected {
+		if links[rel] != target {
+			t.Errorf("rel %s: expected %s, got %s", rel, target, links[rel])
+		}
+	}
+}
+
+func TestPaginationCanonicalURL(t *testing.T) {
+	var requests []string
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		requests = append(requests, r.URL.RequestURI())
+		switch r.URL.Path {
+		case "/list":
+			fmt.Fprint(w, `<html><head><link rel="canonical" href="/list/"></head><body>`+
+				`<h1>Page 1</h1><a class="next" href="/list/2?utm_source=feed">Next</a></body></html>`)
+		default:
+			// The last page links back to the first with tracking noise
+			fmt.Fprint(w, `<html><body><h1>Page 2</h1>`+
+				`<a class="next" href="/list/?utm_campaign=loop#top">Next</a></body></html>`)
+		}
+	}))
+	defer server.Close()
+
+	engine, err := NewEngine(&Config{
+		Timeout:   10 * time.Second,
+		RateLimit: 10 * time.Millisecond,
+		BurstSize: 1,
+		Pagination: &PaginationConfig{
+			Enabled:      true,
+			Type:         PaginationTypeNextButton,
+			NextSelector: "a.next",
+			MaxPages:     10,
+		},
+		CanonicalURL: &CanonicalURLConfig{StripParams: []string{"utm_*"}},
+	})
+	if err != nil {
+		t.Fatalf("Failed to create engine: %v", err)
+	}
+	defer engine.Close()
+
+	extractors := []FieldConfig{{Name: "title", Selector: "h1", Type: "text"}}
+	result, err := engine.ScrapeWithPagination(context.Background(), server.URL+"/list", extractors)
+	if err != nil {
+		t.Fatalf("Pagination scraping failed: %v", err)
+	}
+
+	if result.TotalPages != 2 {
+		t.Fatalf("Expected the loop back to page 1 to stop pagination after 2 pages, got %d", result.TotalPages)
+	}
+	if result.Pages[0].CanonicalURL != server.URL+"/list" {
+		t.Errorf("Expected the rel=canonical link as canonical URL, got %s", result.Pages[0].CanonicalURL)
+	}
+	if result.Pages[1].CanonicalURL != server.URL+"/list/2" {
+		t.Errorf("Expected tracking params stripped from canonical URL, got %s", result.Pages[1].CanonicalURL)
+	}
+	// Each page is fetched to scrape it and to find its next link; page 1 is
+	// never fetched again under its tracking-param alias
+	expectedRequests := []string{"/list", "/list", "/list/2?utm_source=feed", "/list/2?utm_source=feed"}
+	if !reflect.DeepEqual(requests, expectedRequests) {
+		t.Errorf("Expected requests %q, got %q", expectedRequests, requests)
+	}
+}
@@ -9,6 +9,7 @@ import (
 	"strings"
 
 	"github.com/PuerkitoBio/goquery"
+	"github.com/valpere/DataScrapexter/internal/pipeline"
 )
 
 // PaginationStrategy defines the interface for pagination strategies
@@ -96,11 +97,11 @@ type CursorStrategy struct {
 	BaseURL     string `yaml:"base_url" json:"base_url"`
 	CursorParam string `yaml:"cursor_param" json:"cursor_param"` // Default: "cursor"
 	LimitParam  string `yaml:"limit_param" json:"limit_param"`   // Default: "limit"
-	Limit       int    `yaml:"limit" json:"limit"`               // Items per page
+	Limit       int    `yaml:"limit" json:"limit"`               // Items per page; zero leaves the limit parameter off
 	MaxPages    int    `yaml:"max_pages" json:"max_pages"`       // Maximum pages to prevent infinite loops
 
 	// Cursor extraction configuration
-	CursorSelector string `yaml:"cursor_selector" json:"cursor_selector"` // CSS selector to find next cursor
+	CursorSelector string `yaml:"cursor_selector" json:"cursor_selector"` // CSS selector, or a "$." JSON path into a JSON body
 	CursorAttr     string `yaml:"cursor_attr" json:"cursor_attr"`         // Attribute containing cursor value
 	CursorPattern  string `yaml:"cursor_pattern" json:"cursor_pattern"`   // Regex pattern to extract cursor
 
@@ -115,9 +116,6 @@ func (cs *CursorStrategy) GetNextURL(ctx context.Context, currentURL string, doc
 	if cs.LimitParam == "" {
 		cs.LimitParam = "limit"
 	}
-	if cs.Limit <= 0 {
-		cs.Limit = 10 // Default limit
-	}
 
 	// Check page limit
 	if cs.MaxPages > 0 && pageNum > cs.MaxPages {
@@ -156,7 +154,9 @@ func (cs *CursorStrategy) GetNextURL(ctx context.Context, currentURL string, doc
 	// Add/update query parameters
 	query := u.Query()
 	query.Set(cs.CursorParam, nextCursor)
-	query.Set(cs.LimitParam, strconv.Itoa(cs.Limit))
+	if cs.Limit > 0 {
+		query.Set(cs.LimitParam, strconv.Itoa(cs.Limit))
+	}
 	u.RawQuery = query.Encode()
 
 	return u.String(), nil
@@ -167,6 +167,9 @@ func (cs *CursorStrategy) extractCursor(doc *goquery.Document) (string, error) {
 	if cs.CursorSelector == "" {
 		return "", fmt.Errorf("cursor_selector is required for cursor strategy")
 	}
+	if strings.HasPrefix(cs.CursorSelector, "$") {
+		return cursorFromJSON(doc, cs.CursorSelector)
+	}
 
 	// Find the element containing the cursor
 	selection := doc.Find(cs.CursorSelector)
@@ -187,6 +190,30 @@ func (cs *CursorStrategy) extractCursor(doc *goquery.Document) (string, error) {
 	return cursor, nil
 }
 
+// cursorFromJSON reads the cursor at path from a JSON response body, which the
+// HTML parser leaves as the text of <body>. Missing, null and empty values end pagination.
+func cursorFromJSON(doc *goquery.Document, path string) (string, error) {
+	data, err := parseEmbeddedJSON(doc.Find("body").Text())
+	if err != nil {
+		return "", fmt.Errorf("cursor_selector %s needs a JSON response: %w", path, err)
+	}
+
+	value, ok := pipeline.LookupJSONPath(data, pipeline.SplitJSONPath(path))
+	if !ok || value == nil {
+		return "", nil
+	}
+	switch v := value.(type) {
+	case string:
+		return strings.TrimSpace(v), nil
+	case float64:
+		return strconv.FormatFloat(v, 'f', -1, 64), nil
+	case bool, map[string]interface{}, []interface{}:
+		return "", fmt.Errorf("cursor at %s is not a string or number", path)
+	default:
+		return fmt.Sprint(v), nil
+	}
+}
+
 // IsComplete checks if cursor pagination is complete
 func (cs *CursorStrategy) IsComplete(ctx context.Context, currentURL string, doc *goquery.Document, pageNum int) bool {
 	// Check page limit
@@ -374,6 +401,90 @@ func (nps *NumberedPagesStrategy) GetName() string {
 	return "numbered"
 }
 
+// LinkHeaderStrategy follows RFC 8288 `Link: <url>; rel="next"` response headers,
+// as used by many paginated APIs instead of HTML next links
+type LinkHeaderStrategy struct {
+	MaxPages int `yaml:"max_pages" json:"max_pages"` // Maximum pages
+}
+
+// GetNextURL returns the rel=next target from the current page's Link header
+func (lhs *LinkHeaderStrategy) GetNextURL(ctx context.Context, currentURL string, doc *goquery.Document, pageNum int) (string, error) {
+	if lhs.MaxPages > 0 && pageNum >= lhs.MaxPages {
+		return "", nil
+	}
+
+	headers := responseHeadersFromContext(ctx)
+	if headers == nil {
+		return "", fmt.Errorf("response headers are not available for link_header pagination")
+	}
+
+	next := ParseLinkHeader(headers.Values("Link"))["next"]
+	if next == "" {
+		return "", nil
+	}
+
+	currentU, err := url.Parse(currentURL)
+	if err != nil {
+		return "", fmt.Errorf("invalid current URL: %w", err)
+	}
+	nextU, err := currentU.Parse(next)
+	if err != nil {
+		return "", fmt.Errorf("invalid next URL: %w", err)
+	}
+
+	return nextU.String(), nil
+}
+
+// IsComplete returns true when the current page has no rel=next link
+func (lhs *LinkHeaderStrategy) IsComplete(ctx context.Context, currentURL string, doc *goquery.Document, pageNum int) bool {
+	if lhs.MaxPages > 0 && pageNum >= lhs.MaxPages {
+		return true
+	}
+
+	headers := responseHeadersFromContext(ctx)
+	if headers == nil {
+		return true
+	}
+	return ParseLinkHeader(headers.Values("Link"))["next"] == ""
+}
+
+// GetName returns the strategy name
+func (lhs *LinkHeaderStrategy) GetName() string {
+	return "link_header"
+}
+
+// ParseLinkHeader parses Link header values into a map of rel to target URL.
+// A link with several space-separated rels is registered under each of them.
+func ParseLinkHeader(values []string) map[string]string {
+	links := make(map[string]string)
+
+	for _, value := range values {
+		for _, link := range strings.Split(value, ",") {
+			parts := strings.Split(link, ";")
+			target := strings.TrimSpace(parts[0])
+			if !strings.HasPrefix(target, "<") || !strings.HasSuffix(target, ">") {
+				continue
+			}
+			target = target[1 : len(target)-1]
+
+			for _, param := range parts[1:] {
+				key, val, found := strings.Cut(strings.TrimSpace(param), "=")
+				if !found || !strings.EqualFold(strings.TrimSpace(key), "rel") {
+					continue
+				}
+				for _, rel := range strings.Fields(strings.Trim(strings.TrimSpace(val), `"`)) {
+					rel = strings.ToLower(rel)
+					if _, exists := links[rel]; !exists {
+						links[rel] = target
+					}
+				}
+			}
+		}
+	}
+
+	return links
+}
+
 // CreatePaginationStrategy creates a pagination strategy from config
 func CreatePaginationStrategy(config PaginationConfig) (PaginationStrategy, error) {
 	switch config.Type {
@@ -408,14 +519,20 @@ func CreatePaginationStrategy(config PaginationConfig) (PaginationStrategy, erro
 			MaxPages:  config.MaxPages,
 		}, nil
 
-	case "cursor":
+	case PaginationTypeCursor:
 		return &CursorStrategy{
 			BaseURL:        "",
-			CursorParam:    config.PageParam,
+			CursorParam:    config.CursorParam,
 			LimitParam:     config.LimitParam,
 			Limit:          config.PageSize,
 			MaxPages:       config.MaxPages,
-			CursorSelector: config.ScrollSelector,
+			CursorSelector: config.CursorSelector,
+			CursorAttr:     config.CursorAttr,
+		}, nil
+
+	case PaginationTypeLinkHeader:
+		return &LinkHeaderStrategy{
+			MaxPages: config.MaxPages,
 		}, nil
 
 	default:
@@ -72,6 +72,47 @@ func TestCursorStrategy_extractCursor(t *testing.T) {
 	}
 }
 
+func TestCursorStrategy_JSONCursor(t *testing.T) {
+	strategy := CursorStrategy{CursorSelector: "$.meta.next", CursorParam: "cursor"}
+
+	testCases := []struct {
+		body     string
+		expected string
+	}{
+		{`{"meta":{"next":"abc"}}`, "abc"},
+		{`{"meta":{"next":1500000}}`, "1500000"},
+		{`{"meta":{"next":null}}`, ""},
+		{`{"meta":{"next":""}}`, ""},
+		{`{"meta":{}}`, ""},
+	}
+	for _, tc := range testCases {
+		doc, err := goquery.NewDocumentFromReader(strings.NewReader(tc.body))
+		if err != nil {
+			t.Fatalf("failed to parse body: %v", err)
+		}
+		next, err := strategy.GetNextURL(context.Background(), "https://api.example.com/items?cursor=old", doc, 1)
+		if err != nil {
+			t.Errorf("%s: unexpected error: %v", tc.body, err)
+			continue
+		}
+		if tc.expected == "" {
+			if next != "" {
+				t.Errorf("%s: expected pagination to stop, got %s", tc.body, next)
+			}
+			continue
+		}
+		if want := "https://api.example.com/items?cursor=" + tc.expected; next != want {
+			t.Errorf("%s: expected %s, got %s", tc.body, want, next)
+		}
+		strategy.lastCursor = ""
+	}
+
+	doc, _ := goquery.NewDocumentFromReader(strings.NewReader(`<html><body>not json</body></html>`))
+	if _, err := strategy.extractCursor(doc); err == nil {
+		t.Error("expected an error for a non-JSON body")
+	}
+}
+
 func TestNextButtonStrategy_GetNextURL(t *testing.T) {
 	ctx := context.Background()
 
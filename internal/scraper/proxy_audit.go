@@ -0,0 +1,55 @@
+// internal/scraper/proxy_audit.go
+package scraper
+
+import (
+	"context"
+
+	"github.com/valpere/DataScrapexter/internal/proxy"
+	"github.com/valpere/DataScrapexter/internal/utils"
+)
+
+var proxyAuditLogger = utils.NewComponentLogger("proxy-audit")
+
+// MetaField is the result data key holding per-record metadata such as the proxy used
+const MetaField = "_meta"
+
+// proxyChoice identifies the proxy that served a request, without credentials
+type proxyChoice struct {
+	Name string
+	URL  string
+}
+
+// proxyChoiceKey is the context key carrying a holder that the HTTP fetch fills
+// with the proxy it used; retries overwrite it, so it names the last attempt
+type proxyChoiceKey struct{}
+
+// withProxyChoice returns a context asking the HTTP fetch to record its proxy in holder
+func withProxyChoice(ctx context.Context, holder *proxyChoice) context.Context {
+	return context.WithValue(ctx, proxyChoiceKey{}, holder)
+}
+
+// recordProxyChoice logs and stores the proxy selected for url, as configured
+func (e *Engine) recordProxyChoice(ctx context.Context, url string, instance *proxy.ProxyInstance) {
+	if e.config.Proxy == nil || instance == nil {
+		return
+	}
+
+	choice := proxyChoice{Name: instance.Provider.Name, URL: instance.RedactedURL()}
+	if e.config.Proxy.LogSelection {
+		proxyAuditLogger.Infof("Proxy %s (%s) selected for %s", choice.Name, choice.URL, url)
+	}
+	if holder, ok := ctx.Value(proxyChoiceKey{}).(*proxyChoice); ok && holder != nil {
+		*holder = choice
+	}
+}
+
+// proxyMeta returns the _meta entry describing choice, or nil when no proxy was used
+func proxyMeta(choice *proxyChoice) map[string]interface{} {
+	if choice == nil || choice.URL == "" {
+		return nil
+	}
+	return map[string]interface{}{
+		"proxy":     choice.Name,
+		"proxy_url": choice.URL,
+	}
+}
@@ -0,0 +1,117 @@
+// internal/scraper/proxy_audit_test.go
+package scraper
+
+import (
+	"context"
+	"net"
+	"net/http"
+	"net/http/httptest"
+	"strconv"
+	"strings"
+	"testing"
+	"time"
+)
+
+func TestScrapeProxyInMeta(t *testing.T) {
+	// A plain HTTP server works as a forward proxy for http:// targets
+	proxyServer := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		w.Write([]byte(`<html><body><h1>Via proxy</h1></body></html>`))
+	}))
+	defer proxyServer.Close()
+
+	host, portStr, _ := net.SplitHostPort(strings.TrimPrefix(proxyServer.URL, "http://"))
+	port, _ := strconv.Atoi(portStr)
+
+	engine, err := NewEngine(&Config{
+		Timeout:   10 * time.Second,
+		RateLimit: 10 * time.Millisecond,
+		BurstSize: 1,
+		Proxy: &ProxyConfig{
+			Enabled:          true,
+			FailureThreshold: 3,
+			LogSelection:     true,
+			IncludeInMeta:    true,
+			Providers: []ProxyProvider{{
+				Name: "egress-1", Type: "http", Host: host, Port: port,
+				Username: "user", Password: "hunter2", Enabled: true,
+			}},
+		},
+	})
+	if err != nil {
+		t.Fatalf("Failed to create engine: %v", err)
+	}
+	defer engine.Close()
+
+	fields := []FieldConfig{{Name: "title", Selector: "h1", Type: "text"}}
+	result, err := engine.Scrape(context.Background(), "http://target.example/page", fields)
+	if err != nil {
+		t.Fatalf("Scraping failed: %v", err)
+	}
+	if result.Data["title"] != "Via proxy" {
+		t.Fatalf("Expected page fetched through the proxy, got %v", result.Data)
+	}
+
+	meta, ok := result.Data[MetaField].(map[string]interface{})
+	if !ok {
+		t.Fatalf("Expected %s in result data, got %v", MetaField, result.Data)
+	}
+	if meta["proxy"] != "egress-1" {
+		t.Errorf("Expected proxy name egress-1, got %v", meta["proxy"])
+	}
+	if proxyURL, _ := meta["proxy_url"].(string); proxyURL == "" || strings.Contains(proxyURL, "hunter2") || strings.Contains(proxyURL, "user") {
+		t.Errorf("Expected redacted proxy URL, got %q", proxyURL)
+	}
+}
+
+func TestRobotsAndSitemapUseProxy(t *testing.T) {
+	var proxied []string
+	proxyServer := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		proxied = append(proxied, r.URL.String())
+		switch r.URL.Path {
+		case "/robots.txt":
+			w.Write([]byte("User-agent: *\nAllow: /\n"))
+		case "/sitemap.xml":
+			w.Write([]byte(`<urlset><url><loc>http://target.example/p/1</loc></url></urlset>`))
+		default:
+			w.Write([]byte(`<html><body><h1>Via proxy</h1></body></html>`))
+		}
+	}))
+	defer proxyServer.Close()
+
+	host, portStr, _ := net.SplitHostPort(strings.TrimPrefix(proxyServer.URL, "http://"))
+	port, _ := strconv.Atoi(portStr)
+
+	engine, err := NewEngine(&Config{
+		Timeout:          10 * time.Second,
+		RespectRobotsTxt: true,
+		Pagination:       &PaginationConfig{Enabled: true, Type: PaginationTypeSitemap, SitemapURL: "http://target.example/sitemap.xml"},
+		Proxy: &ProxyConfig{
+			Enabled:          true,
+			FailureThreshold: 3,
+			Providers:        []ProxyProvider{{Name: "egress-1", Type: "http", Host: host, Port: port, Enabled: true}},
+		},
+	})
+	if err != nil {
+		t.Fatalf("Failed to create engine: %v", err)
+	}
+	defer engine.Close()
+
+	pages, err := engine.SitemapPages(context.Background())
+	if err != nil || len(pages) != 1 {
+		t.Fatalf("SitemapPages() = %v, %v", pages, err)
+	}
+	fields := []FieldConfig{{Name: "title", Selector: "h1", Type: "text"}}
+	if _, err := engine.Scrape(context.Background(), pages[0], fields); err != nil {
+		t.Fatalf("Scraping failed: %v", err)
+	}
+
+	for _, want := range []string{"http://target.example/sitemap.xml", "http://target.example/robots.txt", "http://target.example/p/1"} {
+		found := false
+		for _, got := range proxied {
+			found = found || got == want
+		}
+		if !found {
+			t.Errorf("expected %s to go through the proxy, proxied %v", want, proxied)
+		}
+	}
+}
@@ -17,7 +17,84 @@ func ParseRotationStrategy(strategy string) (proxy.RotationStrategy, error) {
 		return proxy.RotationWeighted, nil
 	case "healthy":
 		return proxy.RotationHealthy, nil
+	case "adaptive_weighted":
+		return proxy.RotationAdaptiveWeighted, nil
 	default:
 		return "", fmt.Errorf("unsupported rotation strategy: %s", strategy)
 	}
 }
+
+// ParseAffinityMode converts string proxy affinity to proxy.AffinityMode
+func ParseAffinityMode(mode string) (proxy.AffinityMode, error) {
+	switch mode {
+	case "", "none":
+		return proxy.AffinityNone, nil
+	case "worker":
+		return proxy.AffinityWorker, nil
+	default:
+		return "", fmt.Errorf("unsupported proxy affinity: %s", mode)
+	}
+}
+
+// NewProxyManager converts the scraper proxy config and builds a proxy manager.
+// Health checks are not started; callers that scrape must call Start.
+func NewProxyManager(config *ProxyConfig) (*proxy.ProxyManager, error) {
+	rotation, err := ParseRotationStrategy(config.Rotation)
+	if err != nil {
+		return nil, fmt.Errorf("invalid rotation strategy: %w", err)
+	}
+	affinity, err := ParseAffinityMode(config.Affinity)
+	if err != nil {
+		return nil, fmt.Errorf("invalid proxy affinity: %w", err)
+	}
+
+	proxyConfig := &proxy.ProxyConfig{
+		Enabled:          config.Enabled,
+		Rotation:         rotation,
+		HealthCheck:      config.HealthCheck,
+		HealthCheckURL:   config.HealthCheckURL,
+		HealthCheckRate:  config.HealthCheckRate,
+		Timeout:          config.Timeout,
+		MaxRetries:       config.MaxRetries,
+		RetryDelay:       config.RetryDelay,
+		FailureThreshold: config.FailureThreshold,
+		RecoveryTime:     config.RecoveryTime,
+		Affinity:         affinity,
+		AffinityRequests: config.AffinityRequests,
+		HostGroups:       config.HostGroups,
+		Providers:        make([]proxy.ProxyProvider, len(config.Providers)),
+
+		HealthCheckMaxInterval: config.HealthCheckMaxInterval,
+	}
+
+	// Convert providers
+	for i, provider := range config.Providers {
+		proxyConfig.Providers[i] = proxy.ProxyProvider{
+			Name:     provider.Name,
+			Type:     proxy.ProxyType(provider.Type),
+			Host:     provider.Host,
+			Port:     provider.Port,
+			Username: provider.Username,
+			Password: provider.Password,
+			Weight:   provider.Weight,
+			Enabled:  provider.Enabled,
+			Group:    provider.Group,
+		}
+	}
+	if err := proxy.ValidateHostGroups(proxyConfig); err != nil {
+		return nil, fmt.Errorf("invalid proxy host_groups: %w", err)
+	}
+
+	// Convert TLS configuration if present
+	if config.TLS != nil {
+		proxyConfig.TLS = &proxy.TLSConfig{
+			InsecureSkipVerify: config.TLS.InsecureSkipVerify,
+			ServerName:         config.TLS.ServerName,
+			RootCAs:            config.TLS.RootCAs,
+			ClientCert:         config.TLS.ClientCert,
+			ClientKey:          config.TLS.ClientKey,
+		}
+	}
+
+	return proxy.NewProxyManager(proxyConfig), nil
+}
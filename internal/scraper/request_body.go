@@ -0,0 +1,227 @@
+// internal/scraper/request_body.go
+package scraper
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+	"io"
+	"net/http"
+	"net/url"
+	"sort"
+	"strconv"
+	"strings"
+)
+
+// PagePlaceholder in a request body is replaced by the page number being fetched
+const PagePlaceholder = "${page}"
+
+// Request body types
+const (
+	BodyTypeForm = "form" // application/x-www-form-urlencoded
+	BodyTypeJSON = "json" // application/json
+	BodyTypeRaw  = "raw"  // Sent as written; set Content-Type in headers
+)
+
+// RequestSpec is the method and body a page is requested with. Body is a map
+// of fields or an encoded string for form, any value for json (a string is
+// sent as written, so {"page": ${page}} sends a number) and a string for raw.
+// BodyType defaults to json for a map body and raw for a string.
+type RequestSpec struct {
+	Method   string      `yaml:"method,omitempty" json:"method,omitempty"` // Default GET
+	Body     interface{} `yaml:"body,omitempty" json:"body,omitempty"`
+	BodyType string      `yaml:"body_type,omitempty" json:"body_type,omitempty"`
+}
+
+// methodsWithBody are the methods a request body may be sent with
+var methodsWithBody = map[string]bool{
+	http.MethodPost: true, http.MethodPut: true, http.MethodPatch: true, http.MethodDelete: true,
+}
+
+// pageNumberKey is the context key carrying the page number for PagePlaceholder
+type pageNumberKey struct{}
+
+// WithPageNumber sets the page number PagePlaceholder is replaced with; pages
+// fetched without one use 1
+func WithPageNumber(ctx context.Context, page int) context.Context {
+	return context.WithValue(ctx, pageNumberKey{}, page)
+}
+
+// pageNumberFromContext returns the page number set by WithPageNumber, or 1
+func pageNumberFromContext(ctx context.Context) int {
+	if page, ok := ctx.Value(pageNumberKey{}).(int); ok {
+		return page
+	}
+	return 1
+}
+
+// requestSpec returns the spec for pageURL: its url_requests entry, else Request
+func (e *Engine) requestSpec(pageURL string) RequestSpec {
+	if spec, ok := e.config.URLRequests[pageURL]; ok {
+		return spec
+	}
+	return e.config.Request
+}
+
+// method returns the spec's method, upper-cased, or GET
+func (spec RequestSpec) method() string {
+	if spec.Method == "" {
+		return http.MethodGet
+	}
+	return strings.ToUpper(spec.Method)
+}
+
+// bodyType returns BodyType, or the default for the kind of Body
+func (spec RequestSpec) bodyType() string {
+	if spec.BodyType != "" {
+		return strings.ToLower(spec.BodyType)
+	}
+	if _, ok := spec.Body.(string); ok {
+		return BodyTypeRaw
+	}
+	return BodyTypeJSON
+}
+
+// validate checks the method is known, that a body is only set for methods
+// that take one, and that the body suits its type
+func (spec RequestSpec) validate() error {
+	method := spec.method()
+	switch method {
+	case http.MethodGet, http.MethodHead, http.MethodPost, http.MethodPut, http.MethodPatch, http.MethodDelete:
+	default:
+		return fmt.Errorf("unsupported method %s", spec.Method)
+	}
+	if spec.Body == nil {
+		if spec.BodyType != "" {
+			return fmt.Errorf("body_type %s is set without a body", spec.BodyType)
+		}
+		return nil
+	}
+	if !methodsWithBody[method] {
+		return fmt.Errorf("method %s does not take a body", method)
+	}
+	_, _, err := spec.encodeBody(1)
+	return err
+}
+
+// encodeBody returns the body for page, with PagePlaceholder replaced, and its
+// Content-Type; a nil Body gives no body
+func (spec RequestSpec) encodeBody(page int) (string, string, error) {
+	if spec.Body == nil {
+		return "", "", nil
+	}
+	pageNumber := strconv.Itoa(page)
+	fill := func(s string) string { return strings.ReplaceAll(s, PagePlaceholder, pageNumber) }
+
+	switch spec.bodyType() {
+	case BodyTypeJSON:
+		if s, ok := spec.Body.(string); ok {
+			body := fill(s)
+			if !json.Valid([]byte(body)) {
+				return "", "", fmt.Errorf("json body is not valid JSON")
+			}
+			return body, "application/json", nil
+		}
+		encoded, err := json.Marshal(normalizeYAML(spec.Body))
+		if err != nil {
+			return "", "", fmt.Errorf("failed to encode json body: %w", err)
+		}
+		return fill(string(encoded)), "application/json", nil
+	case BodyTypeForm:
+		switch body := spec.Body.(type) {
+		case string:
+			return fill(body), "application/x-www-form-urlencoded", nil
+		case map[string]interface{}:
+			return encodeForm(body, fill), "application/x-www-form-urlencoded", nil
+		case map[interface{}]interface{}:
+			fields := make(map[string]interface{}, len(body))
+			for k, v := range body {
+				fields[fmt.Sprint(k)] = v
+			}
+			return encodeForm(fields, fill), "application/x-www-form-urlencoded", nil
+		default:
+			return "", "", fmt.Errorf("form body must be a map of fields or an encoded string")
+		}
+	case BodyTypeRaw:
+		s, ok := spec.Body.(string)
+		if !ok {
+			return "", "", fmt.Errorf("raw body must be a string")
+		}
+		return fill(s), "", nil
+	default:
+		return "", "", fmt.Errorf("unsupported body_type %s (use form, json or raw)", spec.BodyType)
+	}
+}
+
+// encodeForm url-encodes fields in key order; a list value repeats its key
+func encodeForm(fields map[string]interface{}, fill func(string) string) string {
+	keys := make([]string, 0, len(fields))
+	for key := range fields {
+		keys = append(keys, key)
+	}
+	sort.Strings(keys)
+
+	var parts []string
+	for _, key := range keys {
+		values, ok := fields[key].([]interface{})
+		if !ok {
+			values = []interface{}{fields[key]}
+		}
+		for _, value := range values {
+			if value == nil {
+				value = ""
+			}
+			parts = append(parts, url.QueryEscape(key)+"="+url.QueryEscape(fill(fmt.Sprint(value))))
+		}
+	}
+	return strings.Join(parts, "&")
+}
+
+// normalizeYAML turns the map[interface{}]interface{} values YAML decoding can
+// produce into map[string]interface{}, which encoding/json accepts
+func normalizeYAML(value interface{}) interface{} {
+	switch v := value.(type) {
+	case map[interface{}]interface{}:
+		m := make(map[string]interface{}, len(v))
+		for key, item := range v {
+			m[fmt.Sprint(key)] = normalizeYAML(item)
+		}
+		return m
+	case map[string]interface{}:
+		m := make(map[string]interface{}, len(v))
+		for key, item := range v {
+			m[key] = normalizeYAML(item)
+		}
+		return m
+	case []interface{}:
+		list := make([]interface{}, len(v))
+		for i, item := range v {
+			list[i] = normalizeYAML(item)
+		}
+		return list
+	default:
+		return value
+	}
+}
+
+// newPageRequest builds the request for pageURL with its configured method
+// and body. A body's Content-Type is set here, so configured headers override it.
+func (e *Engine) newPageRequest(ctx context.Context, pageURL string) (*http.Request, error) {
+	spec := e.requestSpec(pageURL)
+	body, contentType, err := spec.encodeBody(pageNumberFromContext(ctx))
+	if err != nil {
+		return nil, err
+	}
+	var reader io.Reader
+	if spec.Body != nil {
+		reader = strings.NewReader(body)
+	}
+	req, err := http.NewRequestWithContext(ctx, spec.method(), pageURL, reader)
+	if err != nil {
+		return nil, err
+	}
+	if contentType != "" {
+		req.Header.Set("Content-Type", contentType)
+	}
+	return req, nil
+}
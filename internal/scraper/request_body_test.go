@@ -0,0 +1,131 @@
+// internal/scraper/request_body_test.go
+package scraper
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+	"io"
+	"net/http"
+	"net/http/httptest"
+	"net/url"
+	"testing"
+	"time"
+)
+
+// echoServer answers with the request's method, Content-Type and body in the page
+func echoServer(t *testing.T) *httptest.Server {
+	t.Helper()
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		body, _ := io.ReadAll(r.Body)
+		fmt.Fprintf(w, `<html><body><p id="method">%s</p><p id="type">%s</p><pre id="body">%s</pre></body></html>`,
+			r.Method, r.Header.Get("Content-Type"), body)
+	}))
+	t.Cleanup(server.Close)
+	return server
+}
+
+var echoFields = []FieldConfig{
+	{Name: "method", Selector: "#method", Type: "text"},
+	{Name: "type", Selector: "#type", Type: "text"},
+	{Name: "body", Selector: "#body", Type: "text"},
+}
+
+func TestScrapeJSONPost(t *testing.T) {
+	server := echoServer(t)
+	engine, err := NewEngine(&Config{
+		Timeout:   10 * time.Second,
+		RateLimit: time.Millisecond,
+		BurstSize: 1,
+		Request: RequestSpec{
+			Method: "post",
+			Body:   map[string]interface{}{"query": "laptops", "filters": []interface{}{"new"}, "page": "${page}"},
+		},
+	})
+	if err != nil {
+		t.Fatalf("Failed to create engine: %v", err)
+	}
+
+	result, err := engine.Scrape(WithPageNumber(context.Background(), 3), server.URL, echoFields)
+	if err != nil {
+		t.Fatalf("Scrape: %v", err)
+	}
+	if result.Data["method"] != "POST" || result.Data["type"] != "application/json" {
+		t.Errorf("Expected a JSON POST, got %v with %v", result.Data["method"], result.Data["type"])
+	}
+	var body map[string]interface{}
+	if err := json.Unmarshal([]byte(result.Data["body"].(string)), &body); err != nil {
+		t.Fatalf("Body is not JSON: %v", err)
+	}
+	if body["query"] != "laptops" || body["page"] != "3" {
+		t.Errorf("Expected the body with ${page} filled, got %v", body)
+	}
+}
+
+func TestScrapeFormPost(t *testing.T) {
+	server := echoServer(t)
+	engine, err := NewEngine(&Config{
+		Timeout:   10 * time.Second,
+		RateLimit: time.Millisecond,
+		BurstSize: 1,
+		URLRequests: map[string]RequestSpec{
+			server.URL + "/search": {
+				Method:   "POST",
+				BodyType: BodyTypeForm,
+				Body:     map[string]interface{}{"q": "red shoes", "page": "${page}", "size": 20},
+			},
+		},
+	})
+	if err != nil {
+		t.Fatalf("Failed to create engine: %v", err)
+	}
+
+	result, err := engine.Scrape(context.Background(), server.URL+"/search", echoFields)
+	if err != nil {
+		t.Fatalf("Scrape: %v", err)
+	}
+	if result.Data["method"] != "POST" || result.Data["type"] != "application/x-www-form-urlencoded" {
+		t.Errorf("Expected a form POST, got %v with %v", result.Data["method"], result.Data["type"])
+	}
+	form, err := url.ParseQuery(result.Data["body"].(string))
+	if err != nil {
+		t.Fatalf("Body is not a form: %v", err)
+	}
+	if form.Get("q") != "red shoes" || form.Get("page") != "1" || form.Get("size") != "20" {
+		t.Errorf("Unexpected form %v", form)
+	}
+
+	// URLs without an entry keep the default GET
+	result, err = engine.Scrape(context.Background(), server.URL+"/other", echoFields)
+	if err != nil {
+		t.Fatalf("Scrape: %v", err)
+	}
+	if result.Data["method"] != "GET" {
+		t.Errorf("Expected GET for a URL without an override, got %v", result.Data["method"])
+	}
+}
+
+func TestRequestSpecValidate(t *testing.T) {
+	tests := []struct {
+		name    string
+		spec    RequestSpec
+		wantErr bool
+	}{
+		{"default get", RequestSpec{}, false},
+		{"raw post", RequestSpec{Method: "POST", Body: "a=${page}"}, false},
+		{"json string", RequestSpec{Method: "POST", Body: `{"page": ${page}}`, BodyType: "json"}, false},
+		{"get with body", RequestSpec{Method: "GET", Body: "x"}, true},
+		{"unknown method", RequestSpec{Method: "FETCH"}, true},
+		{"invalid json", RequestSpec{Method: "POST", Body: `{"page":`, BodyType: "json"}, true},
+		{"raw map", RequestSpec{Method: "POST", Body: map[string]interface{}{"a": 1}, BodyType: "raw"}, true},
+		{"unknown body type", RequestSpec{Method: "POST", Body: "x", BodyType: "xml"}, true},
+		{"body type without body", RequestSpec{Method: "POST", BodyType: "json"}, true},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			if err := tt.spec.validate(); (err != nil) != tt.wantErr {
+				t.Errorf("validate() error = %v, wantErr %v", err, tt.wantErr)
+			}
+		})
+	}
+}
@@ -0,0 +1,131 @@
+// internal/scraper/request_stats.go
+package scraper
+
+import (
+	"sync/atomic"
+	"time"
+)
+
+// RequestStats reports the request rate and concurrency actually achieved by an engine
+type RequestStats struct {
+	Requests          int64         `json:"requests"`
+	Elapsed           time.Duration `json:"elapsed"`
+	RequestsPerSecond float64       `json:"requests_per_second"`
+	LimiterWait       time.Duration `json:"limiter_wait"`
+	PeakInFlight      int64         `json:"peak_in_flight"`
+	EmptyRetries      int64         `json:"empty_retries"`   // Re-fetches triggered by retry_on_empty
+	MalformedPages    int64         `json:"malformed_pages"` // Fetches that malformed_html flagged, retried or not
+
+	HostPages       map[string]int `json:"host_pages,omitempty"`        // Pages scraped per host
+	HostPageSkipped map[string]int `json:"host_page_skipped,omitempty"` // URLs dropped by max_pages_per_host, per host
+}
+
+// requestLatencyBuckets are the upper bounds, in seconds, of the request latency histogram
+var requestLatencyBuckets = [...]float64{0.05, 0.1, 0.25, 0.5, 1, 2.5, 5, 10, 30}
+
+// LatencyHistogram counts request durations into buckets, Prometheus style
+type LatencyHistogram struct {
+	Buckets []float64 `json:"buckets"` // Upper bounds in seconds
+	Counts  []uint64  `json:"counts"`  // Requests that took at most each bound, cumulative
+	Count   uint64    `json:"count"`
+	Sum     float64   `json:"sum"` // Seconds
+}
+
+// requestCounters tracks fetches with atomics so the request path never takes a lock
+type requestCounters struct {
+	requests     atomic.Int64
+	waitNanos    atomic.Int64
+	inFlight     atomic.Int64
+	peakInFlight atomic.Int64
+	emptyRetries atomic.Int64
+	malformed    atomic.Int64
+	firstStart   atomic.Int64 // unix nanos of the first request
+	lastEnd      atomic.Int64 // unix nanos of the latest completed request
+
+	latency      [len(requestLatencyBuckets)]atomic.Uint64 // Per bucket, not cumulative
+	latencyCount atomic.Uint64
+	latencySum   atomic.Int64 // nanos
+}
+
+// recordWait adds time spent blocked in the rate limiter
+func (rc *requestCounters) recordWait(d time.Duration) {
+	rc.waitNanos.Add(int64(d))
+}
+
+// recordEmptyRetry counts a re-fetch of a page that came back empty
+func (rc *requestCounters) recordEmptyRetry() {
+	rc.emptyRetries.Add(1)
+}
+
+// recordMalformedPage counts a fetch whose HTML looked broken or cut off
+func (rc *requestCounters) recordMalformedPage() {
+	rc.malformed.Add(1)
+}
+
+// begin marks a request as in flight and returns a func that marks it done
+func (rc *requestCounters) begin() func() {
+	now := time.Now().UnixNano()
+	rc.firstStart.CompareAndSwap(0, now)
+	rc.requests.Add(1)
+
+	current := rc.inFlight.Add(1)
+	for {
+		peak := rc.peakInFlight.Load()
+		if current <= peak || rc.peakInFlight.CompareAndSwap(peak, current) {
+			break
+		}
+	}
+
+	return func() {
+		rc.inFlight.Add(-1)
+		end := time.Now().UnixNano()
+		rc.lastEnd.Store(end)
+		rc.recordLatency(time.Duration(end - now))
+	}
+}
+
+// recordLatency counts one request duration in the latency histogram
+func (rc *requestCounters) recordLatency(d time.Duration) {
+	rc.latencyCount.Add(1)
+	rc.latencySum.Add(int64(d))
+	for i, bound := range requestLatencyBuckets {
+		if d.Seconds() <= bound {
+			rc.latency[i].Add(1)
+			return
+		}
+	}
+}
+
+// latencySnapshot returns the latency histogram with cumulative bucket counts
+func (rc *requestCounters) latencySnapshot() LatencyHistogram {
+	histogram := LatencyHistogram{
+		Buckets: requestLatencyBuckets[:],
+		Counts:  make([]uint64, len(requestLatencyBuckets)),
+		Count:   rc.latencyCount.Load(),
+		Sum:     time.Duration(rc.latencySum.Load()).Seconds(),
+	}
+	var cumulative uint64
+	for i := range rc.latency {
+		cumulative += rc.latency[i].Load()
+		histogram.Counts[i] = cumulative
+	}
+	return histogram
+}
+
+// snapshot returns the counters as RequestStats
+func (rc *requestCounters) snapshot() RequestStats {
+	stats := RequestStats{
+		Requests:       rc.requests.Load(),
+		LimiterWait:    time.Duration(rc.waitNanos.Load()),
+		PeakInFlight:   rc.peakInFlight.Load(),
+		EmptyRetries:   rc.emptyRetries.Load(),
+		MalformedPages: rc.malformed.Load(),
+	}
+
+	first, last := rc.firstStart.Load(), rc.lastEnd.Load()
+	if first > 0 && last > first {
+		stats.Elapsed = time.Duration(last - first)
+		stats.RequestsPerSecond = float64(stats.Requests) / stats.Elapsed.Seconds()
+	}
+	return stats
+}
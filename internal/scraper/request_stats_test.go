@@ -0,0 +1,56 @@
+package scraper
+
+import (
+	"context"
+	"net/http"
+	"net/http/httptest"
+	"sync"
+	"testing"
+	"time"
+)
+
+func TestEngineRequestStats(t *testing.T) {
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		time.Sleep(50 * time.Millisecond)
+		w.Write([]byte(`<html><body><h1>Title</h1></body></html>`))
+	}))
+	defer server.Close()
+
+	engine, err := NewEngine(&Config{Timeout: 10 * time.Second, RateLimit: 10 * time.Millisecond, BurstSize: 4})
+	if err != nil {
+		t.Fatalf("Failed to create engine: %v", err)
+	}
+
+	if stats := engine.GetRequestStats(); stats.Requests != 0 || stats.RequestsPerSecond != 0 {
+		t.Errorf("Expected empty stats before any request, got %+v", stats)
+	}
+
+	fields := []FieldConfig{{Name: "title", Selector: "h1", Type: "text"}}
+	const requests = 4
+
+	var wg sync.WaitGroup
+	for i := 0; i < requests; i++ {
+		wg.Add(1)
+		go func() {
+			defer wg.Done()
+			if _, err := engine.Scrape(context.Background(), server.URL, fields); err != nil {
+				t.Errorf("Scraping failed: %v", err)
+			}
+		}()
+	}
+	wg.Wait()
+
+	stats := engine.GetRequestStats()
+	if stats.Requests != requests {
+		t.Errorf("Requests = %d, want %d", stats.Requests, requests)
+	}
+	if stats.PeakInFlight < 2 || stats.PeakInFlight > requests {
+		t.Errorf("PeakInFlight = %d, want between 2 and %d", stats.PeakInFlight, requests)
+	}
+	if stats.RequestsPerSecond <= 0 || stats.Elapsed <= 0 {
+		t.Errorf("Expected a positive request rate, got %+v", stats)
+	}
+	if stats.LimiterWait < 0 {
+		t.Errorf("LimiterWait = %v, want >= 0", stats.LimiterWait)
+	}
+}
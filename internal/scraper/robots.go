@@ -0,0 +1,446 @@
+// internal/scraper/robots.go
+package scraper
+
+import (
+	"bufio"
+	"context"
+	stderrors "errors"
+	"fmt"
+	"io"
+	"net/http"
+	"net/url"
+	"strconv"
+	"strings"
+	"sync"
+	"time"
+
+	"github.com/valpere/DataScrapexter/internal/errors"
+	"github.com/valpere/DataScrapexter/internal/utils"
+)
+
+var robotsLogger = utils.NewComponentLogger("robots")
+
+// ErrRobotsDisallowed is returned for URLs robots.txt does not allow when
+// respect_robots_txt is set. It wraps errors.ErrPolicy.
+var ErrRobotsDisallowed = fmt.Errorf("%w: disallowed by robots.txt", errors.ErrPolicy)
+
+// maxRobotsSize caps how much of a robots.txt file is read (Google reads 500KiB)
+const maxRobotsSize = 500 * 1024
+
+// RobotsGroup holds the directives of one user-agent group in robots.txt
+type RobotsGroup struct {
+	UserAgents []string
+	Allow      []string
+	Disallow   []string
+	CrawlDelay time.Duration
+}
+
+// RobotsRules is a parsed robots.txt file
+type RobotsRules struct {
+	Groups []*RobotsGroup
+
+	// Unreachable is set when robots.txt could not be fetched because of a
+	// network error or a 5xx response; RFC 9309 then disallows every path
+	Unreachable bool
+}
+
+// ParseRobots parses robots.txt content. Unknown directives are ignored.
+func ParseRobots(r io.Reader) *RobotsRules {
+	rules := &RobotsRules{}
+	var current *RobotsGroup
+	inAgentLines := false
+
+	scanner := bufio.NewScanner(io.LimitReader(r, maxRobotsSize))
+	for scanner.Scan() {
+		line := scanner.Text()
+		if i := strings.IndexByte(line, '#'); i >= 0 {
+			line = line[:i]
+		}
+		key, value, found := strings.Cut(line, ":")
+		if !found {
+			continue
+		}
+		key = strings.ToLower(strings.TrimSpace(key))
+		value = strings.TrimSpace(value)
+
+		switch key {
+		case "user-agent":
+			// Consecutive user-agent lines share one group
+			if current == nil || !inAgentLines {
+				current = &RobotsGroup{}
+				rules.Groups = append(rules.Groups, current)
+			}
+			current.UserAgents = append(current.UserAgents, strings.ToLower(value))
+			inAgentLines = true
+		case "allow":
+			if current != nil {
+				current.Allow = append(current.Allow, value)
+			}
+			inAgentLines = false
+		case "disallow":
+			if current != nil && value != "" {
+				current.Disallow = append(current.Disallow, value)
+			}
+			inAgentLines = false
+		case "crawl-delay":
+			if current != nil {
+				if seconds, err := strconv.ParseFloat(value, 64); err == nil && seconds >= 0 {
+					current.CrawlDelay = time.Duration(seconds * float64(time.Second))
+				}
+			}
+			inAgentLines = false
+		default:
+			inAgentLines = false
+		}
+	}
+
+	return rules
+}
+
+// GroupFor returns the group that applies to userAgent: the group with the
+// longest user-agent token contained in it, else the "*" group, else nil
+func (r *RobotsRules) GroupFor(userAgent string) *RobotsGroup {
+	if r == nil {
+		return nil
+	}
+
+	ua := strings.ToLower(userAgent)
+	var best, wildcard *RobotsGroup
+	bestLen := 0
+
+	for _, group := range r.Groups {
+		for _, agent := range group.UserAgents {
+			if agent == "*" {
+				if wildcard == nil {
+					wildcard = group
+				}
+				continue
+			}
+			if agent != "" && strings.Contains(ua, agent) && len(agent) > bestLen {
+				best = group
+				bestLen = len(agent)
+			}
+		}
+	}
+
+	if best != nil {
+		return best
+	}
+	return wildcard
+}
+
+// CrawlDelay returns the Crawl-delay that applies to userAgent, or zero
+func (r *RobotsRules) CrawlDelay(userAgent string) time.Duration {
+	if group := r.GroupFor(userAgent); group != nil {
+		return group.CrawlDelay
+	}
+	return 0
+}
+
+// Allowed reports whether userAgent may fetch target, a URL path with optional
+// query, under RFC 9309: the longest matching Allow or Disallow rule of the
+// applicable group wins, and Allow wins a tie. Rules may use "*" for any run of
+// characters and end with "$" to anchor at the end of the path.
+func (r *RobotsRules) Allowed(userAgent, target string) bool {
+	if target == "/robots.txt" {
+		return true
+	}
+	if r == nil {
+		return true
+	}
+	if r.Unreachable {
+		return false
+	}
+	group := r.GroupFor(userAgent)
+	if group == nil {
+		return true
+	}
+
+	allowLen, disallowLen := -1, -1
+	for _, pattern := range group.Allow {
+		if pattern != "" && len(pattern) > allowLen && robotsPathMatch(pattern, target) {
+			allowLen = len(pattern)
+		}
+	}
+	for _, pattern := range group.Disallow {
+		if len(pattern) > disallowLen && robotsPathMatch(pattern, target) {
+			disallowLen = len(pattern)
+		}
+	}
+	return disallowLen < 0 || allowLen >= disallowLen
+}
+
+// robotsPathMatch reports whether pattern matches target. Patterns match a
+// prefix of target unless they end in "$"; "*" matches any run of characters.
+func robotsPathMatch(pattern, target string) bool {
+	anchored := strings.HasSuffix(pattern, "$")
+	if anchored {
+		pattern = strings.TrimSuffix(pattern, "$")
+	} else if !strings.HasSuffix(pattern, "*") {
+		pattern += "*"
+	}
+
+	// Greedy wildcard match, backtracking to the most recent "*"
+	p, t := 0, 0
+	star, mark := -1, 0
+	for t < len(target) {
+		switch {
+		case p < len(pattern) && pattern[p] == '*':
+			star, mark = p, t
+			p++
+		case p < len(pattern) && pattern[p] == target[t]:
+			p++
+			t++
+		case star >= 0:
+			p = star + 1
+			mark++
+			t = mark
+		default:
+			return false
+		}
+	}
+	for p < len(pattern) && pattern[p] == '*' {
+		p++
+	}
+	return p == len(pattern)
+}
+
+// robotsTarget returns the path and query of pageURL as robots.txt rules see it
+func robotsTarget(u *url.URL) string {
+	target := u.EscapedPath()
+	if target == "" {
+		target = "/"
+	}
+	if u.RawQuery != "" {
+		target += "?" + u.RawQuery
+	}
+	return target
+}
+
+// fetchRobotsFunc fetches the robots.txt at robotsURL. definite reports an
+// answer that holds for the rest of the run rather than a passing failure.
+type fetchRobotsFunc func(ctx context.Context, robotsURL string) (rules *RobotsRules, definite bool, err error)
+
+// robotsCache fetches and caches robots.txt rules per host for the engine's
+// lifetime. Lookups for a host share one fetch, made without holding the lock.
+type robotsCache struct {
+	mu       sync.Mutex
+	rules    map[string]*RobotsRules
+	inflight map[string]*robotsFetch
+}
+
+// robotsFetch is a robots.txt fetch in progress; rules and err are set when done is closed
+type robotsFetch struct {
+	done  chan struct{}
+	rules *RobotsRules
+	err   error
+}
+
+// newRobotsCache creates an empty robots.txt cache
+func newRobotsCache() *robotsCache {
+	return &robotsCache{
+		rules:    make(map[string]*RobotsRules),
+		inflight: make(map[string]*robotsFetch),
+	}
+}
+
+// get returns the rules for the host of pageURL, fetching robots.txt with fetch
+// unless a definite answer is cached. Callers arriving during a fetch wait for
+// it; if it was cancelled by its own caller's context they fetch again.
+func (rc *robotsCache) get(ctx context.Context, pageURL string, fetch fetchRobotsFunc) (*RobotsRules, error) {
+	u, err := url.Parse(pageURL)
+	if err != nil {
+		return nil, fmt.Errorf("invalid URL: %w", err)
+	}
+	origin := u.Scheme + "://" + u.Host
+
+	for {
+		rc.mu.Lock()
+		if rules, ok := rc.rules[origin]; ok {
+			rc.mu.Unlock()
+			return rules, nil
+		}
+		f, ok := rc.inflight[origin]
+		if !ok {
+			f = &robotsFetch{done: make(chan struct{})}
+			rc.inflight[origin] = f
+			rc.mu.Unlock()
+
+			var definite bool
+			f.rules, definite, f.err = fetch(ctx, origin+"/robots.txt")
+
+			rc.mu.Lock()
+			delete(rc.inflight, origin)
+			if definite {
+				rc.rules[origin] = f.rules
+			}
+			close(f.done)
+			rc.mu.Unlock()
+			return f.rules, f.err
+		}
+		rc.mu.Unlock()
+
+		select {
+		case <-f.done:
+		case <-ctx.Done():
+			return nil, ctx.Err()
+		}
+		if f.err != nil && ctx.Err() == nil && (stderrors.Is(f.err, context.Canceled) || stderrors.Is(f.err, context.DeadlineExceeded)) {
+			continue
+		}
+		return f.rules, f.err
+	}
+}
+
+// fetchRobots fetches robots.txt through the same client and proxy as a page
+// request to robotsURL would use. A 200 response is parsed and any 4xx means no
+// rules; both are definite. A network error or 5xx response yields rules marked
+// Unreachable for this lookup only, and a cancelled ctx an error.
+func (e *Engine) fetchRobots(ctx context.Context, robotsURL string) (*RobotsRules, bool, error) {
+	client, proxyInstance, release, err := e.requestClient(ctx, robotsURL)
+	if err != nil {
+		return nil, false, err
+	}
+	defer release()
+
+	req, err := http.NewRequestWithContext(ctx, "GET", robotsURL, nil)
+	if err != nil {
+		return nil, false, fmt.Errorf("failed to create robots.txt request: %w", err)
+	}
+	req.Header.Set("User-Agent", e.robotsUserAgent())
+
+	resp, err := client.Do(req)
+	if err != nil {
+		if ctx.Err() != nil {
+			return nil, false, ctx.Err()
+		}
+		if proxyInstance != nil {
+			e.proxyManager.ReportFailure(proxyInstance, err)
+		}
+		robotsLogger.Warnf("Failed to fetch %s: %v", robotsURL, err)
+		return &RobotsRules{Unreachable: true}, false, nil
+	}
+	defer resp.Body.Close()
+	if proxyInstance != nil {
+		e.proxyManager.ReportSuccess(proxyInstance)
+	}
+
+	switch {
+	case resp.StatusCode == http.StatusOK:
+		return ParseRobots(resp.Body), true, nil
+	case resp.StatusCode >= 500:
+		robotsLogger.Warnf("%s returned %d", robotsURL, resp.StatusCode)
+		return &RobotsRules{Unreachable: true}, false, nil
+	default:
+		return &RobotsRules{}, true, nil
+	}
+}
+
+// hostPacer enforces a minimum interval between requests to the same host
+type hostPacer struct {
+	mu     sync.Mutex
+	next   map[string]time.Time
+	logged map[string]bool
+}
+
+// newHostPacer creates an empty host pacer
+func newHostPacer() *hostPacer {
+	return &hostPacer{
+		next:   make(map[string]time.Time),
+		logged: make(map[string]bool),
+	}
+}
+
+// firstOverride reports whether this is the first time host's interval overrides the config
+func (hp *hostPacer) firstOverride(host string) bool {
+	hp.mu.Lock()
+	defer hp.mu.Unlock()
+	if hp.logged[host] {
+		return false
+	}
+	hp.logged[host] = true
+	return true
+}
+
+// wait blocks until host may be requested again, then reserves the next slot interval later
+func (hp *hostPacer) wait(ctx context.Context, host string, interval time.Duration) error {
+	hp.mu.Lock()
+	now := time.Now()
+	slot := hp.next[host]
+	if slot.Before(now) {
+		slot = now
+	}
+	hp.next[host] = slot.Add(interval)
+	hp.mu.Unlock()
+
+	delay := time.Until(slot)
+	if delay <= 0 {
+		return nil
+	}
+
+	timer := time.NewTimer(delay)
+	defer timer.Stop()
+	select {
+	case <-ctx.Done():
+		return ctx.Err()
+	case <-timer.C:
+		return nil
+	}
+}
+
+// applyCrawlDelay waits out the robots.txt Crawl-delay for the URL's host when it
+// is slower than the configured rate limit. The robots.txt fetch itself is not paced.
+func (e *Engine) applyCrawlDelay(ctx context.Context, pageURL string) error {
+	userAgent := e.robotsUserAgent()
+	rules, err := e.robots.get(ctx, pageURL, e.fetchRobots)
+	if err != nil {
+		return err
+	}
+
+	delay := rules.CrawlDelay(userAgent)
+	if delay <= e.config.RateLimit {
+		return nil
+	}
+
+	u, _ := url.Parse(pageURL)
+	if e.hostPacer.firstOverride(u.Host) {
+		robotsLogger.Infof("robots.txt Crawl-delay %v for %s overrides configured rate limit %v", delay, u.Host, e.config.RateLimit)
+	}
+
+	if err := e.hostPacer.wait(ctx, u.Host, delay); err != nil {
+		return fmt.Errorf("crawl delay wait failed: %w", err)
+	}
+	return nil
+}
+
+// checkRobots returns ErrRobotsDisallowed, marked permanent, when respect_robots_txt
+// is set and robots.txt does not allow pageURL. Rules are fetched once per host
+// and kept for the run.
+func (e *Engine) checkRobots(ctx context.Context, pageURL string) error {
+	if !e.config.RespectRobotsTxt {
+		return nil
+	}
+	userAgent := e.robotsUserAgent()
+	rules, err := e.robots.get(ctx, pageURL, e.fetchRobots)
+	if err != nil {
+		return err
+	}
+
+	u, _ := url.Parse(pageURL)
+	if rules.Allowed(userAgent, robotsTarget(u)) {
+		return nil
+	}
+	if rules.Unreachable {
+		return errors.Permanent(fmt.Errorf("%w (robots.txt unreachable for %s): %s", ErrRobotsDisallowed, u.Host, pageURL))
+	}
+	return errors.Permanent(fmt.Errorf("%w: %s", ErrRobotsDisallowed, pageURL))
+}
+
+// robotsUserAgent returns the user agent used to match robots.txt groups without
+// advancing the rotation used for page requests
+func (e *Engine) robotsUserAgent() string {
+	if len(e.userAgentPool) == 0 {
+		return "DataScrapexter/1.0"
+	}
+	return e.userAgentPool[0]
+}
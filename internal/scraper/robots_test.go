@@ -0,0 +1,270 @@
+// internal/scraper/robots_test.go
+package scraper
+
+import (
+	"context"
+	"errors"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"sync/atomic"
+	"testing"
+	"time"
+
+	recovery "github.com/valpere/DataScrapexter/internal/errors"
+)
+
+func TestParseRobotsCrawlDelay(t *testing.T) {
+	robots := `# example
+User-agent: *
+Crawl-delay: 2
+Disallow: /private
+
+User-agent: DataScrapexter
+User-agent: OtherBot
+Crawl-delay: 0.5
+Allow: /
+`
+	rules := ParseRobots(strings.NewReader(robots))
+
+	if len(rules.Groups) != 2 {
+		t.Fatalf("expected 2 groups, got %d", len(rules.Groups))
+	}
+
+	tests := []struct {
+		userAgent string
+		want      time.Duration
+	}{
+		{"DataScrapexter/1.0", 500 * time.Millisecond},
+		{"Mozilla/5.0 (compatible; OtherBot/2.1)", 500 * time.Millisecond},
+		{"Mozilla/5.0 (Windows NT 10.0; Win64; x64)", 2 * time.Second},
+	}
+	for _, tt := range tests {
+		if got := rules.CrawlDelay(tt.userAgent); got != tt.want {
+			t.Errorf("CrawlDelay(%q) = %v, want %v", tt.userAgent, got, tt.want)
+		}
+	}
+
+	if got := ParseRobots(strings.NewReader("")).CrawlDelay("any"); got != 0 {
+		t.Errorf("empty robots.txt should have no crawl delay, got %v", got)
+	}
+}
+
+func TestScrapeRespectsCrawlDelay(t *testing.T) {
+	var robotsFetches int32
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		if r.URL.Path == "/robots.txt" {
+			atomic.AddInt32(&robotsFetches, 1)
+			w.Write([]byte("User-agent: *\nCrawl-delay: 0.3\n"))
+			return
+		}
+		w.Write([]byte(`<html><body><h1>Title</h1></body></html>`))
+	}))
+	defer server.Close()
+
+	engine, err := NewEngine(&Config{
+		Timeout:           10 * time.Second,
+		RateLimit:         10 * time.Millisecond,
+		BurstSize:         1,
+		RespectCrawlDelay: true,
+	})
+	if err != nil {
+		t.Fatalf("Failed to create engine: %v", err)
+	}
+
+	fields := []FieldConfig{{Name: "title", Selector: "h1", Type: "text"}}
+	start := time.Now()
+	for i := 0; i < 3; i++ {
+		if _, err := engine.Scrape(context.Background(), server.URL+"/page", fields); err != nil {
+			t.Fatalf("Scrape failed: %v", err)
+		}
+	}
+	elapsed := time.Since(start)
+
+	// Three requests with a 300ms crawl delay need at least two full intervals
+	if elapsed < 600*time.Millisecond {
+		t.Errorf("expected crawl delay to pace requests, took only %v", elapsed)
+	}
+	if n := atomic.LoadInt32(&robotsFetches); n != 1 {
+		t.Errorf("expected robots.txt to be fetched once, got %d", n)
+	}
+}
+
+func TestRobotsAllowed(t *testing.T) {
+	robots := `User-agent: *
+Disallow: /private
+Disallow: /*.pdf$
+Disallow: /search*q=
+Allow: /private/public
+Disallow: /tmp/
+Allow: /tmp/
+
+User-agent: DataScrapexter
+Disallow: /
+Allow: /$
+Allow: /docs/
+`
+	rules := ParseRobots(strings.NewReader(robots))
+	other := "Mozilla/5.0"
+
+	tests := []struct {
+		userAgent, target string
+		want              bool
+	}{
+		{other, "/", true},
+		{other, "/private", false},
+		{other, "/private/data", false},
+		{other, "/privateer", false},
+		{other, "/private/public/page", true},
+		{other, "/files/report.pdf", false},
+		{other, "/files/report.pdf?download=1", true},
+		{other, "/files/report.pdfx", true},
+		{other, "/search?q=shoes", false},
+		{other, "/search/advanced?page=2&q=shoes", false},
+		{other, "/search?page=2", true},
+		{other, "/tmp/file", true}, // Allow wins a tie
+		{other, "/robots.txt", true},
+		{"DataScrapexter/1.0", "/", true},
+		{"DataScrapexter/1.0", "/products", false},
+		{"DataScrapexter/1.0", "/docs/intro", true},
+	}
+	for _, tt := range tests {
+		if got := rules.Allowed(tt.userAgent, tt.target); got != tt.want {
+			t.Errorf("Allowed(%q, %q) = %v, want %v", tt.userAgent, tt.target, got, tt.want)
+		}
+	}
+
+	if !(&RobotsRules{}).Allowed(other, "/anything") {
+		t.Error("empty rules should allow everything")
+	}
+	if (&RobotsRules{Unreachable: true}).Allowed(other, "/anything") {
+		t.Error("unreachable robots.txt should disallow everything")
+	}
+}
+
+func TestScrapeRespectsRobotsTxt(t *testing.T) {
+	var robotsFetches, pageFetches int32
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		if r.URL.Path == "/robots.txt" {
+			atomic.AddInt32(&robotsFetches, 1)
+			w.Write([]byte("User-agent: *\nDisallow: /admin\nDisallow: /*?sort=\nCrawl-delay: 0.2\n"))
+			return
+		}
+		atomic.AddInt32(&pageFetches, 1)
+		w.Write([]byte(`<html><body><h1>Title</h1></body></html>`))
+	}))
+	defer server.Close()
+
+	engine, err := NewEngine(&Config{
+		Timeout:          10 * time.Second,
+		RateLimit:        10 * time.Millisecond,
+		BurstSize:        1,
+		RespectRobotsTxt: true,
+	})
+	if err != nil {
+		t.Fatalf("Failed to create engine: %v", err)
+	}
+
+	fields := []FieldConfig{{Name: "title", Selector: "h1", Type: "text"}}
+	for _, path := range []string{"/admin/users", "/list?sort=price"} {
+		_, err := engine.Scrape(context.Background(), server.URL+path, fields)
+		if !errors.Is(err, ErrRobotsDisallowed) || !errors.Is(err, recovery.ErrPolicy) {
+			t.Errorf("Scrape(%s) error = %v, want ErrRobotsDisallowed", path, err)
+		}
+	}
+	if n := atomic.LoadInt32(&pageFetches); n != 0 {
+		t.Errorf("disallowed URLs should not be requested, got %d page fetches", n)
+	}
+
+	start := time.Now()
+	for i := 0; i < 2; i++ {
+		if _, err := engine.Scrape(context.Background(), server.URL+"/list?page=2", fields); err != nil {
+			t.Fatalf("Scrape of an allowed URL failed: %v", err)
+		}
+	}
+	if elapsed := time.Since(start); elapsed < 200*time.Millisecond {
+		t.Errorf("expected the crawl delay to pace allowed requests, took only %v", elapsed)
+	}
+	if n := atomic.LoadInt32(&robotsFetches); n != 1 {
+		t.Errorf("expected robots.txt to be fetched once, got %d", n)
+	}
+}
+
+func TestRobotsCacheSharesFetches(t *testing.T) {
+	cache := newRobotsCache()
+	release := make(chan struct{})
+	var fetches int32
+	slow := func(ctx context.Context, robotsURL string) (*RobotsRules, bool, error) {
+		atomic.AddInt32(&fetches, 1)
+		<-release
+		return &RobotsRules{}, true, nil
+	}
+
+	done := make(chan error, 5)
+	for i := 0; i < 5; i++ {
+		go func() {
+			_, err := cache.get(context.Background(), "https://slow.example/page", slow)
+			done <- err
+		}()
+	}
+
+	for atomic.LoadInt32(&fetches) == 0 {
+		time.Sleep(time.Millisecond)
+	}
+	// Another host is looked up while the slow fetch is still running
+	fast := func(ctx context.Context, robotsURL string) (*RobotsRules, bool, error) {
+		return &RobotsRules{}, true, nil
+	}
+	if _, err := cache.get(context.Background(), "https://fast.example/page", fast); err != nil {
+		t.Fatalf("get() error = %v", err)
+	}
+
+	close(release)
+	for i := 0; i < 5; i++ {
+		if err := <-done; err != nil {
+			t.Errorf("get() error = %v", err)
+		}
+	}
+	if n := atomic.LoadInt32(&fetches); n != 1 {
+		t.Errorf("expected concurrent lookups to share one fetch, got %d", n)
+	}
+}
+
+func TestRobotsCacheKeepsOnlyDefiniteAnswers(t *testing.T) {
+	var status int32 = http.StatusServiceUnavailable
+	var fetches int32
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		atomic.AddInt32(&fetches, 1)
+		w.WriteHeader(int(atomic.LoadInt32(&status)))
+		w.Write([]byte("User-agent: *\nDisallow: /admin\n"))
+	}))
+	defer server.Close()
+
+	engine, err := NewEngine(&Config{Timeout: 10 * time.Second, RespectRobotsTxt: true})
+	if err != nil {
+		t.Fatalf("Failed to create engine: %v", err)
+	}
+
+	// A cancelled lookup is an error, not a disallow-everything answer
+	ctx, cancel := context.WithCancel(context.Background())
+	cancel()
+	if _, err := engine.robots.get(ctx, server.URL+"/page", engine.fetchRobots); !errors.Is(err, context.Canceled) {
+		t.Errorf("expected a cancelled lookup to fail with context.Canceled, got %v", err)
+	}
+
+	rules, err := engine.robots.get(context.Background(), server.URL+"/page", engine.fetchRobots)
+	if err != nil || !rules.Unreachable {
+		t.Fatalf("expected unreachable rules for a 503, got %+v, %v", rules, err)
+	}
+
+	atomic.StoreInt32(&status, http.StatusOK)
+	for i := 0; i < 2; i++ {
+		rules, err = engine.robots.get(context.Background(), server.URL+"/page", engine.fetchRobots)
+		if err != nil || rules.Unreachable || rules.Allowed("bot", "/admin") {
+			t.Fatalf("expected the rules fetched once the server recovered, got %+v, %v", rules, err)
+		}
+	}
+	if n := atomic.LoadInt32(&fetches); n != 2 {
+		t.Errorf("expected the 503 to be retried and the 200 cached (2 fetches), got %d", n)
+	}
+}
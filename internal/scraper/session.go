@@ -0,0 +1,272 @@
+// internal/scraper/session.go
+package scraper
+
+import (
+	"bytes"
+	"crypto/sha256"
+	"encoding/hex"
+	"encoding/json"
+	"fmt"
+	"io"
+	"net/http"
+	"net/url"
+	"os"
+	"path/filepath"
+	"sort"
+	"strings"
+	"sync"
+	"sync/atomic"
+	"time"
+
+	"github.com/valpere/DataScrapexter/internal/config"
+)
+
+// sessionExchange is one recorded HTTP round trip, stored as NNNNNN.json with the
+// response body beside it in NNNNNN.body. Redirect hops are recorded one by one.
+type sessionExchange struct {
+	Method          string      `json:"method"`
+	URL             string      `json:"url"`
+	BodySHA256      string      `json:"body_sha256,omitempty"` // Hash of the request body, if it had one
+	RequestHeaders  http.Header `json:"request_headers"`       // Credentials redacted
+	Proxy           string      `json:"proxy,omitempty"`       // Password redacted
+	Time            time.Time   `json:"time"`
+	Error           string      `json:"error,omitempty"` // Set when the request failed without a response
+	StatusCode      int         `json:"status_code,omitempty"`
+	ResponseHeaders http.Header `json:"response_headers,omitempty"`
+	BodyFile        string      `json:"body_file,omitempty"`
+}
+
+func (x *sessionExchange) key() string {
+	return exchangeKey(x.Method, x.URL, x.BodySHA256)
+}
+
+// exchangeKey identifies the exchanges replayed for a request: requests with
+// the same method and URL but different bodies, such as paged POSTs, are
+// answered separately
+func exchangeKey(method, url, bodySHA256 string) string {
+	if bodySHA256 == "" {
+		return method + " " + url
+	}
+	return method + " " + url + " " + bodySHA256
+}
+
+// requestBodySHA256 returns the hex SHA-256 of req's body, or "" without one.
+// The body is read through GetBody when set, else read and put back, so the
+// request can still be sent.
+func requestBodySHA256(req *http.Request) (string, error) {
+	if req.Body == nil || req.Body == http.NoBody {
+		return "", nil
+	}
+	var body []byte
+	if req.GetBody != nil {
+		rc, err := req.GetBody()
+		if err != nil {
+			return "", err
+		}
+		body, err = io.ReadAll(rc)
+		rc.Close()
+		if err != nil {
+			return "", err
+		}
+	} else {
+		var err error
+		body, err = io.ReadAll(req.Body)
+		req.Body.Close()
+		if err != nil {
+			return "", err
+		}
+		req.Body = io.NopCloser(bytes.NewReader(body))
+	}
+	if len(body) == 0 {
+		return "", nil
+	}
+	sum := sha256.Sum256(body)
+	return hex.EncodeToString(sum[:]), nil
+}
+
+// httpSession records every HTTP exchange of a run to a directory, or replays a
+// recorded directory instead of using the network, so extraction can be re-run
+// against the exact bytes a run saw. A nil session passes requests through.
+type httpSession struct {
+	recordDir string
+	seq       atomic.Int64
+
+	replayDir string
+	replay    map[string][]*sessionExchange // Recorded exchanges per exchangeKey, in order
+	cursor    map[string]int
+	mu        sync.Mutex
+}
+
+// newHTTPSession sets up recording or replay from debug, or returns nil when neither is set
+func newHTTPSession(debug *DebugConfig) (*httpSession, error) {
+	switch {
+	case debug == nil || (debug.RecordSession == "" && debug.ReplaySession == ""):
+		return nil, nil
+	case debug.RecordSession != "" && debug.ReplaySession != "":
+		return nil, fmt.Errorf("record_session and replay_session cannot be combined")
+	case debug.RecordSession != "":
+		if err := os.MkdirAll(debug.RecordSession, 0755); err != nil {
+			return nil, fmt.Errorf("failed to create session directory: %w", err)
+		}
+		return &httpSession{recordDir: debug.RecordSession}, nil
+	default:
+		return loadHTTPSession(debug.ReplaySession)
+	}
+}
+
+// loadHTTPSession reads the exchanges recorded in dir for replay
+func loadHTTPSession(dir string) (*httpSession, error) {
+	paths, err := filepath.Glob(filepath.Join(dir, "*.json"))
+	if err != nil {
+		return nil, fmt.Errorf("failed to list session directory: %w", err)
+	}
+	if len(paths) == 0 {
+		return nil, fmt.Errorf("no recorded exchanges in %s", dir)
+	}
+	sort.Strings(paths)
+
+	s := &httpSession{replayDir: dir, replay: make(map[string][]*sessionExchange), cursor: make(map[string]int)}
+	for _, path := range paths {
+		data, err := os.ReadFile(path)
+		if err != nil {
+			return nil, fmt.Errorf("failed to read recorded exchange: %w", err)
+		}
+		var exchange sessionExchange
+		if err := json.Unmarshal(data, &exchange); err != nil {
+			return nil, fmt.Errorf("invalid recorded exchange %s: %w", path, err)
+		}
+		// Bodies must live in the session directory, not be pulled in from elsewhere
+		if exchange.BodyFile != "" && !filepath.IsLocal(exchange.BodyFile) {
+			return nil, fmt.Errorf("invalid recorded exchange %s: body_file %q is outside the session directory", path, exchange.BodyFile)
+		}
+		s.replay[exchange.key()] = append(s.replay[exchange.key()], &exchange)
+	}
+	return s, nil
+}
+
+// wrap returns the round tripper requests should use: next itself without a
+// session, next with recording, or the replay with next never called.
+// proxyURL names the proxy next goes through, if any, for the recording.
+func (s *httpSession) wrap(next http.RoundTripper, proxyURL *url.URL) http.RoundTripper {
+	if s == nil {
+		return next
+	}
+	proxy := ""
+	if proxyURL != nil {
+		proxy = proxyURL.Redacted()
+	}
+	return &sessionTransport{session: s, next: next, proxy: proxy}
+}
+
+type sessionTransport struct {
+	session *httpSession
+	next    http.RoundTripper
+	proxy   string
+}
+
+func (t *sessionTransport) RoundTrip(req *http.Request) (*http.Response, error) {
+	bodySHA256, err := requestBodySHA256(req)
+	if err != nil {
+		return nil, fmt.Errorf("failed to read request body for the session: %w", err)
+	}
+	if t.session.replay != nil {
+		return t.session.replayExchange(req, bodySHA256)
+	}
+
+	resp, err := t.next.RoundTrip(req)
+	exchange := &sessionExchange{
+		Method:         req.Method,
+		URL:            req.URL.String(),
+		BodySHA256:     bodySHA256,
+		RequestHeaders: config.RedactedHeaders(req.Header),
+		Proxy:          t.proxy,
+		Time:           time.Now(),
+	}
+	if err != nil {
+		exchange.Error = err.Error()
+		t.session.save(exchange, nil)
+		return nil, err
+	}
+
+	body, err := io.ReadAll(resp.Body)
+	resp.Body.Close()
+	if err != nil {
+		// A broken download fails the request as it would without recording
+		exchange.Error = fmt.Sprintf("failed to read response body: %v", err)
+		t.session.save(exchange, nil)
+		return nil, fmt.Errorf("failed to read response body: %w", err)
+	}
+	resp.Body = io.NopCloser(bytes.NewReader(body))
+	exchange.StatusCode = resp.StatusCode
+	exchange.ResponseHeaders = resp.Header.Clone()
+	t.session.save(exchange, body)
+	return resp, nil
+}
+
+// save writes exchange and body under the next sequence number; failures are
+// logged rather than failing the request being recorded
+func (s *httpSession) save(exchange *sessionExchange, body []byte) {
+	name := fmt.Sprintf("%06d", s.seq.Add(1))
+	if body != nil {
+		exchange.BodyFile = name + ".body"
+		if err := os.WriteFile(filepath.Join(s.recordDir, exchange.BodyFile), body, 0644); err != nil {
+			debugLogger.Warnf("Failed to record response body of %s: %v", exchange.URL, err)
+			return
+		}
+	}
+	data, err := json.MarshalIndent(exchange, "", "  ")
+	if err == nil {
+		err = os.WriteFile(filepath.Join(s.recordDir, name+".json"), data, 0644)
+	}
+	if err != nil {
+		debugLogger.Warnf("Failed to record exchange for %s: %v", exchange.URL, err)
+	}
+}
+
+// replayExchange answers req with the next recorded exchange for its method,
+// URL and body; once they are used up, the last one is served again
+func (s *httpSession) replayExchange(req *http.Request, bodySHA256 string) (*http.Response, error) {
+	key := exchangeKey(req.Method, req.URL.String(), bodySHA256)
+
+	s.mu.Lock()
+	recorded := s.replay[key]
+	i := s.cursor[key]
+	if i < len(recorded)-1 {
+		s.cursor[key] = i + 1
+	}
+	s.mu.Unlock()
+
+	if len(recorded) == 0 {
+		return nil, fmt.Errorf("no recorded response for %s in %s", key, s.replayDir)
+	}
+	exchange := recorded[i]
+	if exchange.Error != "" {
+		return nil, fmt.Errorf("replayed error: %s", exchange.Error)
+	}
+
+	var body []byte
+	if exchange.BodyFile != "" {
+		var err error
+		if body, err = os.ReadFile(filepath.Join(s.replayDir, exchange.BodyFile)); err != nil {
+			return nil, fmt.Errorf("failed to read recorded body for %s: %w", key, err)
+		}
+	}
+
+	header := exchange.ResponseHeaders.Clone()
+	if header == nil {
+		header = make(http.Header)
+	}
+	// The body may have been decoded on the way in, so its stored length is what counts
+	header.Del("Content-Length")
+	return &http.Response{
+		Status:        strings.TrimSpace(fmt.Sprintf("%d %s", exchange.StatusCode, http.StatusText(exchange.StatusCode))),
+		StatusCode:    exchange.StatusCode,
+		Proto:         "HTTP/1.1",
+		ProtoMajor:    1,
+		ProtoMinor:    1,
+		Header:        header,
+		Body:          io.NopCloser(bytes.NewReader(body)),
+		ContentLength: int64(len(body)),
+		Request:       req,
+	}, nil
+}
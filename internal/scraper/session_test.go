@@ -0,0 +1,160 @@
+// internal/scraper/session_test.go
+package scraper
+
+import (
+	"context"
+	"errors"
+	"io"
+	"net/http"
+	"net/http/httptest"
+	"os"
+	"path/filepath"
+	"strconv"
+	"strings"
+	"sync/atomic"
+	"testing"
+	"time"
+)
+
+func TestSessionRecordAndReplay(t *testing.T) {
+	var hits atomic.Int32
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		hits.Add(1)
+		if r.URL.Path == "/old" {
+			http.Redirect(w, r, "/product", http.StatusFound)
+			return
+		}
+		w.Header().Set("X-Price", "25")
+		w.Write([]byte(`<html><body><h1>Lamp</h1></body></html>`))
+	}))
+	dir := t.TempDir()
+	fields := []FieldConfig{
+		{Name: "title", Selector: "h1", Type: "text"},
+		{Name: "price", Selector: "X-Price", Type: "header"},
+	}
+
+	engine, err := NewEngine(&Config{Timeout: 10 * time.Second, RateLimit: 10 * time.Millisecond, BurstSize: 1,
+		Headers: map[string][]string{"Authorization": {"Bearer abc123"}},
+		Debug:   &DebugConfig{RecordSession: dir}})
+	if err != nil {
+		t.Fatalf("Failed to create engine: %v", err)
+	}
+	if _, err := engine.Scrape(context.Background(), server.URL+"/old", fields); err != nil {
+		t.Fatalf("Scraping failed: %v", err)
+	}
+
+	// The redirect and its target are recorded as separate exchanges
+	recorded, _ := filepath.Glob(filepath.Join(dir, "*.json"))
+	if len(recorded) != 2 {
+		t.Fatalf("Expected 2 recorded exchanges, got %d", len(recorded))
+	}
+	data, _ := os.ReadFile(recorded[1])
+	if !strings.Contains(string(data), `"status_code": 200`) || !strings.Contains(string(data), "/product") {
+		t.Errorf("Unexpected recorded exchange: %s", data)
+	}
+	if strings.Contains(string(data), "abc123") || !strings.Contains(string(data), `"[REDACTED]"`) {
+		t.Errorf("Expected the Authorization header to be redacted: %s", data)
+	}
+
+	// With the server gone, replay serves the recorded bytes
+	server.Close()
+	replay, err := NewEngine(&Config{Timeout: 10 * time.Second, RateLimit: 10 * time.Millisecond, BurstSize: 1,
+		Debug: &DebugConfig{ReplaySession: dir}})
+	if err != nil {
+		t.Fatalf("Failed to create replay engine: %v", err)
+	}
+	result, err := replay.Scrape(context.Background(), server.URL+"/old", fields)
+	if err != nil {
+		t.Fatalf("Replay failed: %v", err)
+	}
+	if result.Data["title"] != "Lamp" || result.Data["price"] != "25" {
+		t.Errorf("Expected replayed fields, got %v", result.Data)
+	}
+	if hits.Load() != 2 {
+		t.Errorf("Replay should not reach the server, got %d requests", hits.Load())
+	}
+
+	if _, err := NewEngine(&Config{Debug: &DebugConfig{ReplaySession: t.TempDir()}}); err == nil {
+		t.Error("Expected replaying an empty directory to fail")
+	}
+}
+
+func TestSessionReplaysRequestBodiesSeparately(t *testing.T) {
+	server := echoServer(t)
+	dir := t.TempDir()
+	request := RequestSpec{Method: "post", Body: map[string]interface{}{"page": "${page}"}}
+
+	engine, err := NewEngine(&Config{Timeout: 10 * time.Second, RateLimit: time.Millisecond, BurstSize: 1,
+		Request: request, Debug: &DebugConfig{RecordSession: dir}})
+	if err != nil {
+		t.Fatalf("Failed to create engine: %v", err)
+	}
+	for page := 1; page <= 2; page++ {
+		if _, err := engine.Scrape(WithPageNumber(context.Background(), page), server.URL, echoFields); err != nil {
+			t.Fatalf("Scraping page %d failed: %v", page, err)
+		}
+	}
+	server.Close()
+
+	replay, err := NewEngine(&Config{Timeout: 10 * time.Second, RateLimit: time.Millisecond, BurstSize: 1,
+		Request: request, Debug: &DebugConfig{ReplaySession: dir}})
+	if err != nil {
+		t.Fatalf("Failed to create replay engine: %v", err)
+	}
+	// Asked in reverse order, each POST still gets the response to its own body
+	for _, page := range []int{2, 1} {
+		result, err := replay.Scrape(WithPageNumber(context.Background(), page), server.URL, echoFields)
+		if err != nil {
+			t.Fatalf("Replaying page %d failed: %v", page, err)
+		}
+		if want := `{"page":"` + strconv.Itoa(page) + `"}`; result.Data["body"] != want {
+			t.Errorf("Expected page %d to replay body %s, got %v", page, want, result.Data["body"])
+		}
+	}
+	if _, err := replay.Scrape(WithPageNumber(context.Background(), 3), server.URL, echoFields); err == nil {
+		t.Error("Expected a body that was never recorded not to be replayed")
+	}
+}
+
+type roundTripFunc func(*http.Request) (*http.Response, error)
+
+func (f roundTripFunc) RoundTrip(req *http.Request) (*http.Response, error) { return f(req) }
+
+type failingBody struct{}
+
+func (failingBody) Read([]byte) (int, error) { return 0, io.ErrUnexpectedEOF }
+func (failingBody) Close() error             { return nil }
+
+func TestSessionRecordingReturnsBodyReadErrors(t *testing.T) {
+	dir := t.TempDir()
+	transport := (&httpSession{recordDir: dir}).wrap(roundTripFunc(func(req *http.Request) (*http.Response, error) {
+		return &http.Response{StatusCode: http.StatusOK, Header: make(http.Header), Body: failingBody{}, Request: req}, nil
+	}), nil)
+
+	req, _ := http.NewRequest(http.MethodGet, "http://example.com/broken", nil)
+	if resp, err := transport.RoundTrip(req); err == nil || !errors.Is(err, io.ErrUnexpectedEOF) {
+		t.Fatalf("Expected the body read error, got %v, %v", resp, err)
+	}
+	// The failure is recorded so a replay fails the same way
+	recorded, _ := filepath.Glob(filepath.Join(dir, "*.json"))
+	if len(recorded) != 1 {
+		t.Fatalf("Expected 1 recorded exchange, got %d", len(recorded))
+	}
+	data, _ := os.ReadFile(recorded[0])
+	if !strings.Contains(string(data), "failed to read response body") {
+		t.Errorf("Expected the read error to be recorded: %s", data)
+	}
+}
+
+func TestSessionReplayRejectsBodyFilesOutsideDirectory(t *testing.T) {
+	for _, bodyFile := range []string{"../secret.body", "/etc/passwd", "sub/../../secret.body"} {
+		dir := t.TempDir()
+		exchange := `{"method":"GET","url":"http://example.com/","status_code":200,"body_file":"` + bodyFile + `"}`
+		if err := os.WriteFile(filepath.Join(dir, "000001.json"), []byte(exchange), 0644); err != nil {
+			t.Fatal(err)
+		}
+		if _, err := loadHTTPSession(dir); err == nil {
+			t.Errorf("Expected body_file %q to be rejected", bodyFile)
+		}
+	}
+}
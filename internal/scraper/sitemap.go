@@ -0,0 +1,255 @@
+// internal/scraper/sitemap.go
+package scraper
+
+import (
+	"bufio"
+	"bytes"
+	"compress/gzip"
+	"context"
+	"encoding/xml"
+	"fmt"
+	"io"
+	"net/http"
+	"strings"
+	"time"
+
+	"github.com/valpere/DataScrapexter/internal/utils"
+)
+
+var sitemapLogger = utils.NewComponentLogger("sitemap")
+
+// maxSitemapDepth limits how deeply nested sitemap indexes are followed
+const maxSitemapDepth = 3
+
+// maxSitemapSize caps the decompressed size of a single sitemap (the sitemap
+// protocol limit is 50MB uncompressed)
+const maxSitemapSize = 50 * 1024 * 1024
+
+// sitemapDocument covers both <urlset> and <sitemapindex> documents
+type sitemapDocument struct {
+	XMLName  xml.Name     `xml:""`
+	URLs     []sitemapLoc `xml:"url"`
+	Sitemaps []sitemapLoc `xml:"sitemap"`
+}
+
+// sitemapLoc is a <url> or <sitemap> entry
+type sitemapLoc struct {
+	Loc string `xml:"loc"`
+}
+
+// ParseSitemap parses a sitemap or sitemap index, transparently decompressing
+// gzip content. It returns page URLs and nested sitemap URLs separately.
+func ParseSitemap(r io.Reader) (pages []string, sitemaps []string, err error) {
+	reader, err := maybeGunzip(r)
+	if err != nil {
+		return nil, nil, err
+	}
+
+	var doc sitemapDocument
+	if err := xml.NewDecoder(io.LimitReader(reader, maxSitemapSize)).Decode(&doc); err != nil {
+		return nil, nil, fmt.Errorf("failed to parse sitemap: %w", err)
+	}
+
+	for _, u := range doc.URLs {
+		if loc := strings.TrimSpace(u.Loc); loc != "" {
+			pages = append(pages, loc)
+		}
+	}
+	for _, s := range doc.Sitemaps {
+		if loc := strings.TrimSpace(s.Loc); loc != "" {
+			sitemaps = append(sitemaps, loc)
+		}
+	}
+
+	return pages, sitemaps, nil
+}
+
+// maybeGunzip wraps r in a gzip reader when the content starts with the gzip
+// magic bytes. Servers often deliver .xml.gz files without Content-Encoding,
+// so the HTTP transport does not decompress them for us.
+func maybeGunzip(r io.Reader) (io.Reader, error) {
+	br := bufio.NewReader(r)
+	magic, err := br.Peek(2)
+	if err != nil && err != io.EOF {
+		return nil, fmt.Errorf("failed to read sitemap: %w", err)
+	}
+
+	if bytes.Equal(magic, []byte{0x1f, 0x8b}) {
+		gz, err := gzip.NewReader(br)
+		if err != nil {
+			return nil, fmt.Errorf("failed to decompress sitemap: %w", err)
+		}
+		return gz, nil
+	}
+
+	return br, nil
+}
+
+// SitemapPages returns the page URLs of the configured sitemap pagination:
+// every URL its sitemap lists, once each, that matches URLFilter, up to MaxPages
+func (e *Engine) SitemapPages(ctx context.Context) ([]string, error) {
+	pagination := e.config.Pagination
+	if pagination == nil || pagination.Type != PaginationTypeSitemap {
+		return nil, fmt.Errorf("sitemap pagination is not configured")
+	}
+
+	discovered, err := e.DiscoverSitemapURLs(ctx, pagination.SitemapURL)
+	if err != nil && len(discovered) == 0 {
+		return nil, err
+	}
+	if err != nil {
+		sitemapLogger.Warnf("Sitemap %s only partly read: %v", pagination.SitemapURL, err)
+	}
+
+	visited := newVisitedURLs(e.config.CanonicalURL)
+	var pages []string
+	for _, page := range discovered {
+		if pagination.MaxPages > 0 && len(pages) >= pagination.MaxPages {
+			break
+		}
+		if pagination.URLFilter != "" && !globMatch(pagination.URLFilter, page) {
+			continue
+		}
+		if visited.visit(page) {
+			pages = append(pages, page)
+		}
+	}
+	return pages, nil
+}
+
+// scrapeSitemapPages scrapes the pages SitemapPages lists, the sitemap pagination
+// counterpart of the next-page loop in ScrapeWithPagination
+func (e *Engine) scrapeSitemapPages(ctx context.Context, extractors []FieldConfig) (*PaginationResult, error) {
+	startTime := time.Now()
+	pages, err := e.SitemapPages(ctx)
+	if err != nil {
+		return nil, fmt.Errorf("failed to read sitemap: %w", err)
+	}
+
+	results := make([]ScrapingResult, 0, len(pages))
+	errors := make([]string, 0)
+	for i, page := range pages {
+		result, err := e.Scrape(ctx, page, extractors)
+		if err != nil {
+			errors = append(errors, fmt.Sprintf("Page %d failed: %v", i+1, err))
+			if !e.config.Pagination.ContinueOnError {
+				break
+			}
+			continue
+		}
+
+		results = append(results, ScrapingResult{
+			URL:          page,
+			CanonicalURL: result.CanonicalURL,
+			StatusCode:   200,
+			Data:         result.Data,
+			Success:      result.Success,
+			Errors:       result.Errors,
+		})
+
+		if e.config.Pagination.DelayBetweenPages > 0 && i < len(pages)-1 {
+			time.Sleep(e.config.Pagination.DelayBetweenPages)
+		}
+	}
+
+	return &PaginationResult{
+		Pages:          results,
+		TotalPages:     len(pages),
+		ProcessedPages: len(results),
+		Success:        len(results) > 0,
+		Errors:         errors,
+		Duration:       time.Since(startTime),
+		StartTime:      startTime,
+		EndTime:        time.Now(),
+	}, nil
+}
+
+// globMatch reports whether s matches pattern in full, where * matches any run
+// of characters, slashes included
+func globMatch(pattern, s string) bool {
+	parts := strings.Split(pattern, "*")
+	if len(parts) == 1 {
+		return s == pattern
+	}
+	if !strings.HasPrefix(s, parts[0]) {
+		return false
+	}
+	s = s[len(parts[0]):]
+	last := parts[len(parts)-1]
+	for _, part := range parts[1 : len(parts)-1] {
+		i := strings.Index(s, part)
+		if i < 0 {
+			return false
+		}
+		s = s[i+len(part):]
+	}
+	return len(s) >= len(last) && strings.HasSuffix(s, last)
+}
+
+// DiscoverSitemapURLs fetches a sitemap (plain or .gz) and returns every page URL
+// it lists, following nested sitemap indexes up to maxSitemapDepth levels
+func (e *Engine) DiscoverSitemapURLs(ctx context.Context, sitemapURL string) ([]string, error) {
+	seen := make(map[string]bool)
+	return e.discoverSitemapURLs(ctx, sitemapURL, 0, seen)
+}
+
+// discoverSitemapURLs recursively walks sitemap indexes
+func (e *Engine) discoverSitemapURLs(ctx context.Context, sitemapURL string, depth int, seen map[string]bool) ([]string, error) {
+	if depth > maxSitemapDepth {
+		return nil, fmt.Errorf("sitemap index nesting exceeds %d levels at %s", maxSitemapDepth, sitemapURL)
+	}
+	if seen[sitemapURL] {
+		return nil, nil
+	}
+	seen[sitemapURL] = true
+
+	if limiter := e.rateLimiters.get(requestHost(sitemapURL)); limiter != nil {
+		if err := limiter.Wait(ctx); err != nil {
+			return nil, fmt.Errorf("rate limiting failed: %w", err)
+		}
+	}
+
+	client, proxyInstance, release, err := e.requestClient(ctx, sitemapURL)
+	if err != nil {
+		return nil, err
+	}
+	defer release()
+
+	req, err := http.NewRequestWithContext(ctx, "GET", sitemapURL, nil)
+	if err != nil {
+		return nil, fmt.Errorf("failed to create sitemap request: %w", err)
+	}
+	req.Header.Set("User-Agent", e.getUserAgent())
+	setConfiguredHeaders(req.Header, e.config.Headers)
+
+	resp, err := client.Do(req)
+	if err != nil {
+		if proxyInstance != nil {
+			e.proxyManager.ReportFailure(proxyInstance, err)
+		}
+		return nil, fmt.Errorf("sitemap request failed: %w", err)
+	}
+	defer resp.Body.Close()
+	if proxyInstance != nil {
+		e.proxyManager.ReportSuccess(proxyInstance)
+	}
+
+	if resp.StatusCode >= 400 {
+		return nil, fmt.Errorf("sitemap request failed with HTTP %d: %s", resp.StatusCode, sitemapURL)
+	}
+
+	pages, nested, err := ParseSitemap(resp.Body)
+	if err != nil {
+		return nil, fmt.Errorf("%s: %w", sitemapURL, err)
+	}
+
+	for _, child := range nested {
+		childPages, err := e.discoverSitemapURLs(ctx, child, depth+1, seen)
+		if err != nil {
+			return pages, err
+		}
+		pages = append(pages, childPages...)
+	}
+
+	return pages, nil
+}
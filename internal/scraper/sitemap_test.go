@@ -0,0 +1,150 @@
+// internal/scraper/sitemap_test.go
+package scraper
+
+import (
+	"bytes"
+	"compress/gzip"
+	"context"
+	"fmt"
+	"net/http"
+	"net/http/httptest"
+	"sort"
+	"testing"
+	"time"
+)
+
+func gzipBytes(t *testing.T, data string) []byte {
+	t.Helper()
+	var buf bytes.Buffer
+	gz := gzip.NewWriter(&buf)
+	if _, err := gz.Write([]byte(data)); err != nil {
+		t.Fatalf("gzip write failed: %v", err)
+	}
+	if err := gz.Close(); err != nil {
+		t.Fatalf("gzip close failed: %v", err)
+	}
+	return buf.Bytes()
+}
+
+func TestDiscoverSitemapURLs(t *testing.T) {
+	var serverURL string
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		switch r.URL.Path {
+		case "/sitemap_index.xml":
+			fmt.Fprintf(w, `<?xml version="1.0" encoding="UTF-8"?>
+<sitemapindex xmlns="http://www.sitemaps.org/schemas/sitemap/0.9">
+  <sitemap><loc>%s/products.xml.gz</loc></sitemap>
+  <sitemap><loc>%s/pages.xml</loc></sitemap>
+</sitemapindex>`, serverURL, serverURL)
+		case "/products.xml.gz":
+			// Served as a gzip file, not with Content-Encoding, as static hosts commonly do
+			w.Header().Set("Content-Type", "application/x-gzip")
+			w.Write(gzipBytes(t, fmt.Sprintf(`<?xml version="1.0" encoding="UTF-8"?>
+<urlset xmlns="http://www.sitemaps.org/schemas/sitemap/0.9">
+  <url><loc>%s/product/1</loc></url>
+  <url><loc>%s/product/2</loc></url>
+</urlset>`, serverURL, serverURL)))
+		case "/pages.xml":
+			fmt.Fprintf(w, `<urlset xmlns="http://www.sitemaps.org/schemas/sitemap/0.9"><url><loc>%s/about</loc></url></urlset>`, serverURL)
+		default:
+			http.NotFound(w, r)
+		}
+	}))
+	defer server.Close()
+	serverURL = server.URL
+
+	engine, err := NewEngine(&Config{Timeout: 10 * time.Second, RateLimit: 10 * time.Millisecond, BurstSize: 1})
+	if err != nil {
+		t.Fatalf("Failed to create engine: %v", err)
+	}
+
+	urls, err := engine.DiscoverSitemapURLs(context.Background(), server.URL+"/sitemap_index.xml")
+	if err != nil {
+		t.Fatalf("Sitemap discovery failed: %v", err)
+	}
+
+	sort.Strings(urls)
+	expected := []string{server.URL + "/about", server.URL + "/product/1", server.URL + "/product/2"}
+	if fmt.Sprint(urls) != fmt.Sprint(expected) {
+		t.Errorf("Expected %v, got %v", expected, urls)
+	}
+}
+
+func TestParseSitemapInvalid(t *testing.T) {
+	if _, _, err := ParseSitemap(bytes.NewReader(gzipBytes(t, "not xml"))); err == nil {
+		t.Error("Expected error for invalid sitemap")
+	}
+}
+
+func TestScrapeWithSitemapPagination(t *testing.T) {
+	var serverURL string
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		switch r.URL.Path {
+		case "/sitemap_index.xml":
+			fmt.Fprintf(w, `<sitemapindex xmlns="http://www.sitemaps.org/schemas/sitemap/0.9">
+  <sitemap><loc>%s/products.xml.gz</loc></sitemap>
+</sitemapindex>`, serverURL)
+		case "/products.xml.gz":
+			w.Write(gzipBytes(t, fmt.Sprintf(`<urlset xmlns="http://www.sitemaps.org/schemas/sitemap/0.9">
+  <url><loc>%[1]s/about</loc></url>
+  <url><loc>%[1]s/product/1</loc></url>
+  <url><loc>%[1]s/product/1</loc></url>
+  <url><loc>%[1]s/product/2</loc></url>
+  <url><loc>%[1]s/product/3</loc></url>
+</urlset>`, serverURL)))
+		default:
+			fmt.Fprintf(w, `<html><body><h1>%s</h1></body></html>`, r.URL.Path)
+		}
+	}))
+	defer server.Close()
+	serverURL = server.URL
+
+	engine, err := NewEngine(&Config{
+		Timeout:   10 * time.Second,
+		RateLimit: 10 * time.Millisecond,
+		BurstSize: 1,
+		Pagination: &PaginationConfig{
+			Enabled:    true,
+			Type:       PaginationTypeSitemap,
+			SitemapURL: server.URL + "/sitemap_index.xml",
+			URLFilter:  server.URL + "/product/*",
+			MaxPages:   2,
+		},
+	})
+	if err != nil {
+		t.Fatalf("Failed to create engine: %v", err)
+	}
+
+	result, err := engine.ScrapeWithPagination(context.Background(), server.URL, []FieldConfig{{Name: "title", Selector: "h1", Type: "text"}})
+	if err != nil {
+		t.Fatalf("Sitemap pagination failed: %v", err)
+	}
+
+	var titles []string
+	for _, page := range result.Pages {
+		titles = append(titles, fmt.Sprint(page.Data["title"]))
+	}
+	// Filtered to products, the duplicate dropped, and capped at max_pages
+	if expected := []string{"/product/1", "/product/2"}; fmt.Sprint(titles) != fmt.Sprint(expected) {
+		t.Errorf("Expected pages %v, got %v", expected, titles)
+	}
+}
+
+func TestGlobMatch(t *testing.T) {
+	tests := []struct {
+		pattern, s string
+		want       bool
+	}{
+		{"https://shop.com/p/*", "https://shop.com/p/a/b", true},
+		{"https://shop.com/p/*", "https://shop.com/about", false},
+		{"*/item-*.html", "https://shop.com/c/item-7.html", true},
+		{"*/item-*.html", "https://shop.com/c/item-7.htm", false},
+		{"https://shop.com/", "https://shop.com/", true},
+		{"a*a", "a", false},
+	}
+	for _, tt := range tests {
+		if got := globMatch(tt.pattern, tt.s); got != tt.want {
+			t.Errorf("globMatch(%q, %q) = %v, want %v", tt.pattern, tt.s, got, tt.want)
+		}
+	}
+}
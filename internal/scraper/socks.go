@@ -0,0 +1,73 @@
+// internal/scraper/socks.go
+package scraper
+
+import (
+	"context"
+	"fmt"
+	"net"
+	"net/url"
+
+	xproxy "golang.org/x/net/proxy"
+)
+
+// isSOCKSProxy reports whether proxyURL is a SOCKS5 proxy rather than an HTTP one
+func isSOCKSProxy(proxyURL *url.URL) bool {
+	return proxyURL.Scheme == "socks5" || proxyURL.Scheme == "socks5h"
+}
+
+// socksDial returns a dial function that tunnels through the SOCKS5 proxy at
+// proxyURL, reaching the proxy over forward and authenticating with the URL's
+// username and password. With socks5 the target host is resolved locally and the
+// proxy is sent an IP; with socks5h the proxy resolves the name itself.
+func socksDial(proxyURL *url.URL, forward dialFunc) dialFunc {
+	var auth *xproxy.Auth
+	if proxyURL.User != nil {
+		password, _ := proxyURL.User.Password()
+		auth = &xproxy.Auth{User: proxyURL.User.Username(), Password: password}
+	}
+	// SOCKS5 only builds the dialer; it does not return an error
+	dialer, _ := xproxy.SOCKS5("tcp", proxyURL.Host, auth, forwardDialer(forward))
+	socks := dialer.(xproxy.ContextDialer)
+	remoteDNS := proxyURL.Scheme == "socks5h"
+
+	return func(ctx context.Context, network, addr string) (net.Conn, error) {
+		if !remoteDNS {
+			resolved, err := resolveAddr(ctx, addr)
+			if err != nil {
+				return nil, err
+			}
+			addr = resolved
+		}
+		return socks.DialContext(ctx, network, addr)
+	}
+}
+
+// resolveAddr replaces the host of a host:port address with its first IP
+func resolveAddr(ctx context.Context, addr string) (string, error) {
+	host, port, err := net.SplitHostPort(addr)
+	if err != nil {
+		return "", err
+	}
+	if net.ParseIP(host) != nil {
+		return addr, nil
+	}
+	ips, err := net.DefaultResolver.LookupIPAddr(ctx, host)
+	if err != nil {
+		return "", err
+	}
+	if len(ips) == 0 {
+		return "", fmt.Errorf("no addresses found for %s", host)
+	}
+	return net.JoinHostPort(ips[0].IP.String(), port), nil
+}
+
+// forwardDialer adapts a dialFunc to the x/net/proxy Dialer interfaces
+type forwardDialer dialFunc
+
+func (d forwardDialer) Dial(network, addr string) (net.Conn, error) {
+	return d(context.Background(), network, addr)
+}
+
+func (d forwardDialer) DialContext(ctx context.Context, network, addr string) (net.Conn, error) {
+	return d(ctx, network, addr)
+}
@@ -0,0 +1,154 @@
+// internal/scraper/socks_test.go
+package scraper
+
+import (
+	"encoding/binary"
+	"io"
+	"net"
+	"net/http"
+	"net/http/httptest"
+	"net/url"
+	"strconv"
+	"testing"
+)
+
+// socksRequest is what a client sent to fakeSOCKSServer
+type socksRequest struct {
+	user, password string
+	target         string // host:port from the CONNECT request
+	domain         bool   // The target host was sent as a name, not an IP
+}
+
+// fakeSOCKSServer accepts SOCKS5 connections with username/password auth,
+// records each CONNECT and tunnels it to backend whatever the requested target
+func fakeSOCKSServer(t *testing.T, backend string) (string, <-chan socksRequest) {
+	t.Helper()
+	listener, err := net.Listen("tcp", "127.0.0.1:0")
+	if err != nil {
+		t.Fatal(err)
+	}
+	t.Cleanup(func() { listener.Close() })
+
+	requests := make(chan socksRequest, 10)
+	go func() {
+		for {
+			conn, err := listener.Accept()
+			if err != nil {
+				return
+			}
+			go func() {
+				defer conn.Close()
+				req, ok := readSOCKSHandshake(conn)
+				if !ok {
+					return
+				}
+				requests <- req
+				upstream, err := net.Dial("tcp", backend)
+				if err != nil {
+					return
+				}
+				defer upstream.Close()
+				conn.Write([]byte{5, 0, 0, 1, 0, 0, 0, 0, 0, 0})
+				go io.Copy(upstream, conn)
+				io.Copy(conn, upstream)
+			}()
+		}
+	}()
+	return listener.Addr().String(), requests
+}
+
+// readSOCKSHandshake reads the greeting, RFC 1929 auth and CONNECT request
+func readSOCKSHandshake(conn net.Conn) (socksRequest, bool) {
+	var req socksRequest
+	read := func(n int) []byte {
+		buf := make([]byte, n)
+		if _, err := io.ReadFull(conn, buf); err != nil {
+			return nil
+		}
+		return buf
+	}
+
+	greeting := read(2)
+	if greeting == nil || read(int(greeting[1])) == nil {
+		return req, false
+	}
+	conn.Write([]byte{5, 2}) // Username/password required
+
+	if read(1) == nil { // Auth version
+		return req, false
+	}
+	req.user = string(read(int(read(1)[0])))
+	req.password = string(read(int(read(1)[0])))
+	conn.Write([]byte{1, 0})
+
+	header := read(4)
+	if header == nil {
+		return req, false
+	}
+	var host string
+	switch header[3] {
+	case 1:
+		host = net.IP(read(4)).String()
+	case 4:
+		host = net.IP(read(16)).String()
+	case 3:
+		host = string(read(int(read(1)[0])))
+		req.domain = true
+	}
+	port := binary.BigEndian.Uint16(read(2))
+	req.target = net.JoinHostPort(host, strconv.Itoa(int(port)))
+	return req, true
+}
+
+func TestNewTransportSOCKS5(t *testing.T) {
+	backend := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		w.Write([]byte("via socks"))
+	}))
+	defer backend.Close()
+	_, backendPort, _ := net.SplitHostPort(backend.Listener.Addr().String())
+	proxyAddr, requests := fakeSOCKSServer(t, backend.Listener.Addr().String())
+
+	tests := []struct {
+		scheme     string
+		host       string
+		wantTarget string
+		wantDomain bool
+	}{
+		// socks5 resolves the name here and sends the proxy an IP
+		{"socks5", "localhost", "127.0.0.1:" + backendPort, false},
+		// socks5h leaves resolution to the proxy, so the name need not resolve locally
+		{"socks5h", "catalog.invalid", "catalog.invalid:" + backendPort, true},
+	}
+	for _, tt := range tests {
+		t.Run(tt.scheme, func(t *testing.T) {
+			proxyURL := &url.URL{Scheme: tt.scheme, Host: proxyAddr, User: url.UserPassword("user", "secret")}
+			transport := newTransport(nil, nil, proxyURL, nil)
+			defer transport.CloseIdleConnections()
+			if transport.Proxy != nil {
+				t.Error("SOCKS5 proxy should be dialed, not set as the transport proxy")
+			}
+
+			client := &http.Client{Transport: transport}
+			resp, err := client.Get("http://" + net.JoinHostPort(tt.host, backendPort) + "/")
+			if err != nil {
+				t.Fatalf("GET through %s proxy: %v", tt.scheme, err)
+			}
+			body, _ := io.ReadAll(resp.Body)
+			resp.Body.Close()
+			if string(body) != "via socks" {
+				t.Errorf("body = %q, want %q", body, "via socks")
+			}
+
+			req := <-requests
+			if req.user != "user" || req.password != "secret" {
+				t.Errorf("auth = %s/%s, want user/secret", req.user, req.password)
+			}
+			if req.target != tt.wantTarget && !(tt.scheme == "socks5" && req.target == "[::1]:"+backendPort) {
+				t.Errorf("CONNECT target = %s, want %s", req.target, tt.wantTarget)
+			}
+			if req.domain != tt.wantDomain {
+				t.Errorf("target sent as domain = %v, want %v", req.domain, tt.wantDomain)
+			}
+		})
+	}
+}
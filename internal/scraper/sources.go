@@ -0,0 +1,282 @@
+// internal/scraper/sources.go
+package scraper
+
+import (
+	"encoding/json"
+	"fmt"
+	"net/http"
+	"reflect"
+	"regexp"
+	"strconv"
+	"strings"
+
+	"github.com/PuerkitoBio/goquery"
+	"github.com/valpere/DataScrapexter/internal/pipeline"
+	"github.com/valpere/DataScrapexter/internal/utils"
+)
+
+// Source types for FieldConfig.Sources
+const (
+	SourceCSS    = "css"
+	SourceAttr   = "attr"
+	SourceHTML   = "html"
+	SourceHeader = "header"
+	SourceJSONLD = "jsonld"
+	SourceRegex  = "regex"
+)
+
+// FieldSource is one way of extracting a field. A field with Sources tries them
+// in order and keeps the first non-empty result.
+type FieldSource struct {
+	Type       string `yaml:"type" json:"type"`                                   // css, attr, html, header, jsonld, regex
+	Selector   string `yaml:"selector,omitempty" json:"selector,omitempty"`       // CSS selector, or header name for header
+	Attribute  string `yaml:"attribute,omitempty" json:"attribute,omitempty"`     // attribute name for attr
+	Path       string `yaml:"path,omitempty" json:"path,omitempty"`               // dot path into JSON-LD, e.g. offers.price or offers[0].price
+	Pattern    string `yaml:"pattern,omitempty" json:"pattern,omitempty"`         // regex over the page HTML; first group if any
+	JSONLDType string `yaml:"jsonld_type,omitempty" json:"jsonld_type,omitempty"` // only resolve path in JSON-LD objects of this @type, e.g. Product
+}
+
+// extractFromSources returns the first non-empty value produced by the field's sources
+func (e *Engine) extractFromSources(doc *goquery.Document, headers http.Header, extractor FieldConfig) (interface{}, error) {
+	var failures []string
+
+	for i, source := range extractor.Sources {
+		value, err := e.extractSource(doc, headers, source)
+		if err == nil && !isEmptyValue(value) {
+			return value, nil
+		}
+		if err == nil {
+			err = fmt.Errorf("empty result")
+		}
+		failures = append(failures, fmt.Sprintf("source %d (%s): %v", i, source.Type, err))
+	}
+
+	return nil, fmt.Errorf("no source produced a value: %s", strings.Join(failures, "; "))
+}
+
+// candidateSelectors returns Selector, when set, followed by the fallback Selectors
+func (f FieldConfig) candidateSelectors() []string {
+	if f.Selector == "" {
+		return f.Selectors
+	}
+	return append([]string{f.Selector}, f.Selectors...)
+}
+
+// extractFromSelectors extracts the field with each of its candidate selectors
+// in turn and returns the first non-empty value, logging at debug level which
+// selector matched
+func (e *Engine) extractFromSelectors(doc *goquery.Document, headers http.Header, extractor FieldConfig) (interface{}, error) {
+	var failures []string
+
+	for i, selector := range extractor.candidateSelectors() {
+		attempt := extractor
+		attempt.Selector, attempt.Selectors = selector, nil
+		value, err := e.extractValue(doc, headers, attempt)
+		if err == nil && !isEmptyValue(value) {
+			utils.NewComponentLogger("scraper").Debugf("Field '%s' matched selector %d: %s", extractor.Name, i, selector)
+			return value, nil
+		}
+		if err == nil {
+			err = fmt.Errorf("empty result")
+		}
+		failures = append(failures, fmt.Sprintf("selector %d (%s): %v", i, selector, err))
+	}
+
+	return nil, fmt.Errorf("no selector produced a value: %s", strings.Join(failures, "; "))
+}
+
+// extractSource extracts a value using a single source
+func (e *Engine) extractSource(doc *goquery.Document, headers http.Header, source FieldSource) (interface{}, error) {
+	switch source.Type {
+	case SourceCSS, "text":
+		return e.extractField(doc, FieldConfig{Selector: source.Selector, Type: "text"})
+	case SourceAttr:
+		return e.extractField(doc, FieldConfig{Selector: source.Selector, Type: "attr", Attribute: source.Attribute})
+	case SourceHTML:
+		return e.extractField(doc, FieldConfig{Selector: source.Selector, Type: "html"})
+	case SourceHeader:
+		return extractHeaderField(headers, FieldConfig{Selector: source.Selector})
+	case SourceJSONLD:
+		return extractJSONLDPath(doc, source.Path, source.JSONLDType)
+	case SourceRegex:
+		return extractRegex(doc, source.Pattern)
+	default:
+		return nil, fmt.Errorf("unsupported source type: %s", source.Type)
+	}
+}
+
+// extractJSONLDPath resolves a dot path against every JSON-LD block on the page
+// (including @graph members). A path with [*], such as offers[*].price, returns
+// every match as a list; any other path returns the first match. A non-empty
+// ldType limits the search to objects whose @type matches it.
+func extractJSONLDPath(doc *goquery.Document, path, ldType string) (interface{}, error) {
+	if path == "" {
+		return nil, fmt.Errorf("jsonld source requires a path")
+	}
+	return resolveItemPath(jsonLDDocumentNodes(doc), path, ldType, "JSON-LD")
+}
+
+// jsonLDDocumentNodes parses the page's JSON-LD blocks into their objects, in
+// page order; blocks that are not valid JSON are skipped
+func jsonLDDocumentNodes(doc *goquery.Document) []interface{} {
+	var nodes []interface{}
+	doc.Find(`script[type="application/ld+json"]`).Each(func(i int, s *goquery.Selection) {
+		var data interface{}
+		if err := json.Unmarshal([]byte(strings.TrimSpace(s.Text())), &data); err != nil {
+			return
+		}
+		nodes = append(nodes, jsonLDNodes(data)...)
+	})
+	return nodes
+}
+
+// resolveItemPath resolves path against structured data objects (JSON-LD nodes
+// or microdata items) whose @type matches ldType, or all of them when it is
+// empty: every match as a list when the path has [*], else the first match.
+// kind names the data in errors.
+func resolveItemPath(nodes []interface{}, path, ldType, kind string) (interface{}, error) {
+	collect := strings.Contains(path, "[*]")
+	keys := pipeline.SplitJSONPath(strings.ReplaceAll(path, "[*]", ".*"))
+
+	var found []interface{}
+	for _, node := range nodes {
+		if ldType != "" && !hasJSONLDType(node, ldType) {
+			continue
+		}
+		if collect {
+			found = append(found, collectPath(node, keys)...)
+		} else if value, ok := pipeline.LookupJSONPath(node, keys); ok && !isEmptyValue(value) {
+			return value, nil
+		}
+	}
+
+	if len(found) == 0 {
+		if ldType != "" {
+			return nil, fmt.Errorf("%s path not found in %s objects: %s", kind, ldType, path)
+		}
+		return nil, fmt.Errorf("%s path not found: %s", kind, path)
+	}
+	return found, nil
+}
+
+// collectPath is lookupPath returning every match: "*" expands an array into its
+// elements (a lone object counts as a one-element array), and other keys applied
+// to an array resolve against each element rather than the first that matches
+func collectPath(value interface{}, keys []string) []interface{} {
+	if len(keys) == 0 {
+		if isEmptyValue(value) {
+			return nil
+		}
+		return []interface{}{value}
+	}
+
+	switch v := value.(type) {
+	case map[string]interface{}:
+		if keys[0] == "*" {
+			return collectPath(v, keys[1:])
+		}
+		if next, ok := v[keys[0]]; ok {
+			return collectPath(next, keys[1:])
+		}
+	case []interface{}:
+		rest := keys
+		if keys[0] == "*" {
+			rest = keys[1:]
+		} else if index, err := strconv.Atoi(keys[0]); err == nil {
+			if index < 0 || index >= len(v) {
+				return nil
+			}
+			return collectPath(v[index], keys[1:])
+		}
+		var found []interface{}
+		for _, item := range v {
+			found = append(found, collectPath(item, rest)...)
+		}
+		return found
+	}
+	return nil
+}
+
+// hasJSONLDType reports whether a JSON-LD object's @type (a string or a list)
+// names ldType, either bare or as a vocabulary URL such as https://schema.org/Product
+func hasJSONLDType(node interface{}, ldType string) bool {
+	object, ok := node.(map[string]interface{})
+	if !ok {
+		return false
+	}
+
+	var types []interface{}
+	switch v := object["@type"].(type) {
+	case string:
+		types = []interface{}{v}
+	case []interface{}:
+		types = v
+	}
+
+	for _, t := range types {
+		name, ok := t.(string)
+		if !ok {
+			continue
+		}
+		if name == ldType || strings.HasSuffix(name, "/"+ldType) {
+			return true
+		}
+	}
+	return false
+}
+
+// jsonLDNodes flattens a JSON-LD document into its top-level objects and @graph members
+func jsonLDNodes(data interface{}) []interface{} {
+	var nodes []interface{}
+	switch v := data.(type) {
+	case []interface{}:
+		for _, item := range v {
+			nodes = append(nodes, jsonLDNodes(item)...)
+		}
+	case map[string]interface{}:
+		nodes = append(nodes, v)
+		if graph, ok := v["@graph"]; ok {
+			nodes = append(nodes, jsonLDNodes(graph)...)
+		}
+	}
+	return nodes
+}
+
+// extractRegex matches pattern against the page HTML, returning the first
+// capture group if the pattern has one, else the whole match
+func extractRegex(doc *goquery.Document, pattern string) (interface{}, error) {
+	re, err := regexp.Compile(pattern)
+	if err != nil {
+		return nil, fmt.Errorf("invalid regex pattern: %w", err)
+	}
+
+	html, err := doc.Html()
+	if err != nil {
+		return nil, fmt.Errorf("failed to read page HTML: %w", err)
+	}
+
+	match := re.FindStringSubmatch(html)
+	if match == nil {
+		return nil, fmt.Errorf("pattern did not match")
+	}
+	if len(match) > 1 {
+		return match[1], nil
+	}
+	return match[0], nil
+}
+
+// isEmptyValue reports whether an extracted value should fall through to the next source
+func isEmptyValue(value interface{}) bool {
+	if value == nil {
+		return true
+	}
+	if s, ok := value.(string); ok {
+		return strings.TrimSpace(s) == ""
+	}
+	rv := reflect.ValueOf(value)
+	switch rv.Kind() {
+	case reflect.Slice, reflect.Map:
+		return rv.Len() == 0
+	}
+	return false
+}
@@ -0,0 +1,228 @@
+// internal/scraper/sources_test.go
+package scraper
+
+import (
+	"context"
+	"net/http"
+	"net/http/httptest"
+	"reflect"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/PuerkitoBio/goquery"
+)
+
+func TestExtractFromSources(t *testing.T) {
+	pages := map[string]string{
+		"/jsonld": `<html><head><script type="application/ld+json">
+{"@context": "https://schema.org", "@graph": [
+  {"@type": "BreadcrumbList"},
+  {"@type": "Product", "name": "Widget", "offers": [{"@type": "Offer", "price": "19.99"}]}
+]}</script></head><body><span class="price">$21.00</span></body></html>`,
+		"/css":   `<html><body><span class="price">$21.00</span></body></html>`,
+		"/regex": `<html><body><script>var product = {"price": "17.50"};</script></body></html>`,
+		"/none":  `<html><body><p>Out of stock</p></body></html>`,
+	}
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		w.Write([]byte(pages[r.URL.Path]))
+	}))
+	defer server.Close()
+
+	engine, err := NewEngine(&Config{Timeout: 10 * time.Second, RateLimit: 10 * time.Millisecond, BurstSize: 1})
+	if err != nil {
+		t.Fatalf("Failed to create engine: %v", err)
+	}
+
+	fields := []FieldConfig{{
+		Name: "price",
+		Sources: []FieldSource{
+			{Type: SourceJSONLD, Path: "offers.price"},
+			{Type: SourceCSS, Selector: ".price"},
+			{Type: SourceRegex, Pattern: `"price":\s*"([\d.]+)"`},
+		},
+	}}
+
+	tests := []struct {
+		path string
+		want interface{}
+	}{
+		{"/jsonld", "19.99"},
+		{"/css", "$21.00"},
+		{"/regex", "17.50"},
+		{"/none", nil},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.path, func(t *testing.T) {
+			result, err := engine.Scrape(context.Background(), server.URL+tt.path, fields)
+			if err != nil {
+				t.Fatalf("Scraping failed: %v", err)
+			}
+			if got := result.Data["price"]; got != tt.want {
+				t.Errorf("price = %v, want %v", got, tt.want)
+			}
+			if tt.want == nil && len(result.Errors) == 0 {
+				t.Error("Expected an error when no source matches")
+			}
+		})
+	}
+}
+
+func TestExtractFromSelectors(t *testing.T) {
+	pages := map[string]string{
+		// Variant A of an A/B test renders the old markup, variant B the new
+		"/a":    `<html><body><span class="price">$21.00</span><ul class="tags"><li>red</li><li>large</li></ul></body></html>`,
+		"/b":    `<html><body><span class="price-now"> </span><div data-price="19.99">$19.99</div><p class="tag">blue</p></body></html>`,
+		"/none": `<html><body><p>Out of stock</p></body></html>`,
+	}
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		w.Write([]byte(pages[r.URL.Path]))
+	}))
+	defer server.Close()
+
+	engine, err := NewEngine(&Config{Timeout: 10 * time.Second, RateLimit: 10 * time.Millisecond, BurstSize: 1})
+	if err != nil {
+		t.Fatalf("Failed to create engine: %v", err)
+	}
+
+	fields := []FieldConfig{
+		{Name: "price", Selector: ".price", Selectors: []string{".price-now", "[data-price]"}, Type: "text"},
+		{Name: "price_attr", Selectors: []string{".price-now", "[data-price]"}, Type: "attr", Attribute: "data-price"},
+		{Name: "tags", Selectors: []string{".tags li", ".tag"}, Type: "list"},
+	}
+
+	tests := []struct {
+		path string
+		want map[string]interface{}
+	}{
+		{"/a", map[string]interface{}{"price": "$21.00", "tags": []string{"red", "large"}}},
+		// .price misses and .price-now is blank, so [data-price] is used
+		{"/b", map[string]interface{}{"price": "$19.99", "price_attr": "19.99", "tags": []string{"blue"}}},
+		{"/none", map[string]interface{}{}},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.path, func(t *testing.T) {
+			result, _ := engine.Scrape(context.Background(), server.URL+tt.path, fields)
+			for _, field := range fields {
+				got, want := result.Data[field.Name], tt.want[field.Name]
+				if !reflect.DeepEqual(got, want) {
+					t.Errorf("%s = %#v, want %#v", field.Name, got, want)
+				}
+			}
+		})
+	}
+
+	t.Run("errors name every selector", func(t *testing.T) {
+		result, _ := engine.Scrape(context.Background(), server.URL+"/none", fields[:1])
+		if len(result.Errors) == 0 || !strings.Contains(result.Errors[0], "[data-price]") {
+			t.Errorf("Expected the error to list the selectors tried, got %v", result.Errors)
+		}
+	})
+}
+
+func TestExtractJSONLDPathTypeFilter(t *testing.T) {
+	html := `<html><head>
+<script type="application/ld+json">{"@type": "Organization", "name": "Acme"}</script>
+<script type="application/ld+json">{"@context": "https://schema.org", "@graph": [
+  {"@type": ["Product", "IndividualProduct"], "name": "Widget", "offers": [{"@type": "Offer", "price": "19.99"}]},
+  {"@type": "https://schema.org/Article", "name": "Review of Widget"}
+]}</script></head></html>`
+	doc, err := goquery.NewDocumentFromReader(strings.NewReader(html))
+	if err != nil {
+		t.Fatalf("Failed to parse HTML: %v", err)
+	}
+
+	tests := []struct {
+		path, ldType string
+		want         interface{}
+	}{
+		{"name", "", "Acme"},
+		{"name", "Product", "Widget"},
+		{"name", "Article", "Review of Widget"},
+		{"offers[0].price", "Product", "19.99"},
+		{"$.offers.price", "Product", "19.99"},
+		{"name", "Event", nil},
+	}
+
+	for _, tt := range tests {
+		got, err := extractJSONLDPath(doc, tt.path, tt.ldType)
+		if tt.want == nil {
+			if err == nil {
+				t.Errorf("extractJSONLDPath(%q, %q) = %v, want error", tt.path, tt.ldType, got)
+			}
+			continue
+		}
+		if err != nil || got != tt.want {
+			t.Errorf("extractJSONLDPath(%q, %q) = %v, %v; want %v", tt.path, tt.ldType, got, err, tt.want)
+		}
+	}
+}
+
+func TestScrapeJSONLDField(t *testing.T) {
+	page := `<html><head>
+<script type="application/ld+json">{"@context": "https://schema.org", "@type": "BreadcrumbList", "itemListElement": [
+  {"@type": "ListItem", "position": 1, "name": "Home"},
+  {"@type": "ListItem", "position": 2, "name": "Lamps"}
+]}</script>
+<script type="application/ld+json">{"@context": "https://schema.org", "@graph": [
+  {"@type": "WebSite", "name": "Acme Store"},
+  {"@type": "Product", "name": "Desk Lamp", "sku": "DL-100",
+   "image": ["https://shop.test/lamp-1.jpg", "https://shop.test/lamp-2.jpg"],
+   "brand": {"@type": "Brand", "name": "Acme"},
+   "offers": [
+     {"@type": "Offer", "price": "19.99", "priceCurrency": "USD", "availability": "https://schema.org/InStock"},
+     {"@type": "Offer", "price": "24.50", "priceCurrency": "USD", "availability": "https://schema.org/OutOfStock"}
+   ]}
+]}</script>
+<script type="application/ld+json">{not json</script>
+</head><body><h1>Desk Lamp</h1></body></html>`
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		w.Write([]byte(page))
+	}))
+	defer server.Close()
+
+	engine, err := NewEngine(&Config{Timeout: 10 * time.Second, RateLimit: 10 * time.Millisecond, BurstSize: 1})
+	if err != nil {
+		t.Fatalf("Failed to create engine: %v", err)
+	}
+	fields := []FieldConfig{
+		{Name: "name", Type: "jsonld", Selector: "$.name", JSONLDType: "Product"},
+		{Name: "site", Type: "jsonld", Selector: "$.name"},
+		{Name: "price", Type: "jsonld", Selector: "$.offers.price", JSONLDType: "Product", OutputType: OutputTypeNumber},
+		{Name: "prices", Type: "jsonld", Selector: "$.offers[*].price", OutputType: OutputTypeNumber},
+		{Name: "second_price", Type: "jsonld", Selector: "$.offers[1].price"},
+		{Name: "brand", Type: "jsonld", Selector: "$.brand.name", JSONLDType: "Product"},
+		{Name: "images", Type: "jsonld", Selector: "$.image[*]", JSONLDType: "Product"},
+		{Name: "breadcrumbs", Type: "jsonld", Selector: "$.itemListElement[*].name", JSONLDType: "BreadcrumbList"},
+		{Name: "gtin", Type: "jsonld", Selector: "$.gtin13", JSONLDType: "Product"},
+	}
+
+	result, err := engine.Scrape(context.Background(), server.URL+"/lamp", fields)
+	if err != nil {
+		t.Fatalf("Scraping failed: %v", err)
+	}
+
+	want := map[string]interface{}{
+		"name":         "Desk Lamp",
+		"site":         "Acme Store",
+		"price":        19.99,
+		"prices":       []interface{}{19.99, 24.5},
+		"second_price": "24.50",
+		"brand":        "Acme",
+		"images":       []interface{}{"https://shop.test/lamp-1.jpg", "https://shop.test/lamp-2.jpg"},
+		"breadcrumbs":  []interface{}{"Home", "Lamps"},
+	}
+	for name, wantValue := range want {
+		if got := result.Data[name]; !reflect.DeepEqual(got, wantValue) {
+			t.Errorf("%s = %#v, want %#v", name, got, wantValue)
+		}
+	}
+	if _, ok := result.Data["gtin"]; ok {
+		t.Errorf("gtin should be missing, got %v", result.Data["gtin"])
+	}
+	if len(result.Errors) != 1 || !strings.Contains(result.Errors[0], "gtin") {
+		t.Errorf("Expected one error for the missing gtin path, got %v", result.Errors)
+	}
+}
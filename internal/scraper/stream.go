@@ -0,0 +1,43 @@
+// internal/scraper/stream.go
+package scraper
+
+import (
+	"context"
+	"fmt"
+)
+
+// ScrapeStream scrapes urls in order and sends each page's result as soon as it
+// is extracted, so callers can process records without holding them all.
+// Pages that fail are reported on the error channel, with their URL, and the
+// stream moves on. Both channels are closed once every URL is done or ctx is
+// cancelled; cancellation is reported as ctx.Err(). The error channel is
+// buffered for every URL, so a caller may drain it after the results.
+func (e *Engine) ScrapeStream(ctx context.Context, urls []string, extractors []FieldConfig) (<-chan *Result, <-chan error) {
+	results := make(chan *Result)
+	errs := make(chan error, len(urls)+1)
+
+	go func() {
+		defer close(results)
+		defer close(errs)
+
+		for _, url := range urls {
+			if err := ctx.Err(); err != nil {
+				errs <- err
+				return
+			}
+			result, err := e.Scrape(ctx, url, extractors)
+			if err != nil {
+				errs <- fmt.Errorf("%s: %w", url, err)
+				continue
+			}
+			select {
+			case results <- result:
+			case <-ctx.Done():
+				errs <- ctx.Err()
+				return
+			}
+		}
+	}()
+
+	return results, errs
+}
@@ -0,0 +1,94 @@
+// internal/scraper/stream_test.go
+package scraper
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+	"time"
+)
+
+func TestScrapeStream(t *testing.T) {
+	served := make(chan string, 10)
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		if r.URL.Path == "/missing" {
+			http.Error(w, "gone", http.StatusNotFound)
+			return
+		}
+		served <- r.URL.Path
+		fmt.Fprintf(w, `<html><body><h1>Page %s</h1></body></html>`, r.URL.Path)
+	}))
+	defer server.Close()
+
+	engine, err := NewEngine(&Config{Timeout: 10 * time.Second, RateLimit: time.Millisecond, BurstSize: 1})
+	if err != nil {
+		t.Fatalf("Failed to create engine: %v", err)
+	}
+	fields := []FieldConfig{{Name: "title", Selector: "h1", Type: "text"}}
+	urls := []string{server.URL + "/one", server.URL + "/missing", server.URL + "/two", server.URL + "/three"}
+
+	results, errs := engine.ScrapeStream(context.Background(), urls, fields)
+
+	result := <-results
+	if result.Data["title"] != "Page /one" {
+		t.Errorf("Expected the first page first, got %v", result.Data["title"])
+	}
+	// The stream runs at most one page ahead of the consumer
+	time.Sleep(100 * time.Millisecond)
+	if len(served) != 2 {
+		t.Errorf("Expected /three to wait until /two is consumed, got %d pages fetched", len(served))
+	}
+
+	var titles []interface{}
+	for result := range results {
+		titles = append(titles, result.Data["title"])
+	}
+	if len(titles) != 2 || titles[0] != "Page /two" || titles[1] != "Page /three" {
+		t.Errorf("Expected the remaining pages in order, got %v", titles)
+	}
+
+	var pageErrs []error
+	for err := range errs {
+		pageErrs = append(pageErrs, err)
+	}
+	if len(pageErrs) != 1 {
+		t.Errorf("Expected one error for the missing page, got %v", pageErrs)
+	}
+}
+
+func TestScrapeStreamCancel(t *testing.T) {
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		w.Write([]byte(`<html><body><h1>Page</h1></body></html>`))
+	}))
+	defer server.Close()
+
+	engine, err := NewEngine(&Config{Timeout: 10 * time.Second, RateLimit: time.Millisecond, BurstSize: 1})
+	if err != nil {
+		t.Fatalf("Failed to create engine: %v", err)
+	}
+	fields := []FieldConfig{{Name: "title", Selector: "h1", Type: "text"}}
+	urls := []string{server.URL + "/a", server.URL + "/b", server.URL + "/c"}
+
+	ctx, cancel := context.WithCancel(context.Background())
+	results, errs := engine.ScrapeStream(ctx, urls, fields)
+	<-results
+	cancel()
+
+	count := 0
+	for range results {
+		count++
+	}
+	if count > 1 {
+		t.Errorf("Expected the stream to stop after cancellation, got %d more records", count)
+	}
+	var last error
+	for err := range errs {
+		last = err
+	}
+	if !errors.Is(last, context.Canceled) {
+		t.Errorf("Expected cancellation to be reported, got %v", last)
+	}
+}
@@ -0,0 +1,168 @@
+// internal/scraper/table.go
+package scraper
+
+import (
+	"fmt"
+	"strconv"
+	"strings"
+
+	"github.com/PuerkitoBio/goquery"
+)
+
+// maxTableSpan bounds colspan and rowspan so one bad attribute cannot blow up the grid
+const maxTableSpan = 1000
+
+// tableCell is one slot of the expanded table grid
+type tableCell struct {
+	text   string
+	filled bool // Set by a cell, as opposed to padding in a short row
+}
+
+// extractTable parses the first table in sel, or sel itself when it is a table,
+// into one map per body row. Keys are the header cells, joined per column when
+// there are several header rows, or columns when given; cells spanning several
+// columns or rows repeat their text in each slot they cover.
+func extractTable(sel *goquery.Selection, columns []string, clean func(*goquery.Selection) string) ([]map[string]interface{}, error) {
+	table := sel.First()
+	if goquery.NodeName(table) != "table" {
+		table = sel.Find("table").First()
+	}
+	if table.Length() == 0 {
+		return nil, fmt.Errorf("no table found in selection")
+	}
+
+	grid, headerRows := tableGrid(table, clean)
+	if len(columns) == 0 {
+		columns = tableHeader(grid[:headerRows])
+	}
+
+	var rows []map[string]interface{}
+	for _, cells := range grid[headerRows:] {
+		row := make(map[string]interface{}, len(cells))
+		empty := true
+		for c, cell := range cells {
+			if cell.text != "" {
+				empty = false
+			}
+			row[columnKey(columns, c)] = cell.text
+		}
+		if !empty {
+			rows = append(rows, row)
+		}
+	}
+	if len(rows) == 0 {
+		return nil, fmt.Errorf("table has no data rows")
+	}
+	return rows, nil
+}
+
+// tableGrid expands the rows of table, not those of tables nested in its cells,
+// into a grid with spans filled in. It also returns how many leading rows are
+// headers: rows inside <thead>, or without one, rows made only of <th> cells.
+func tableGrid(table *goquery.Selection, clean func(*goquery.Selection) string) ([][]tableCell, int) {
+	// Own rows only, in document order even when bare rows and row groups are mixed
+	own := table.ChildrenFiltered("tr").AddSelection(table.ChildrenFiltered("thead, tbody, tfoot").ChildrenFiltered("tr"))
+	trs := table.Find("tr").FilterSelection(own)
+
+	var grid [][]tableCell
+	pending := map[int]map[int]tableCell{} // Row index to the columns filled by rowspans from above
+	headerRows, inHeader := 0, true
+
+	trs.Each(func(r int, tr *goquery.Selection) {
+		row := []tableCell{}
+		for c, cell := range pending[r] {
+			for len(row) <= c {
+				row = append(row, tableCell{})
+			}
+			row[c] = cell
+		}
+		delete(pending, r)
+
+		inThead := goquery.NodeName(tr.Parent()) == "thead"
+		allTH := true
+		col := 0
+		tr.ChildrenFiltered("th, td").Each(func(_ int, td *goquery.Selection) {
+			for col < len(row) && row[col].filled {
+				col++
+			}
+			if goquery.NodeName(td) != "th" {
+				allTH = false
+			}
+			cell := tableCell{text: clean(td), filled: true}
+			colspan, rowspan := tableSpan(td, "colspan"), tableSpan(td, "rowspan")
+			for i := 0; i < colspan; i++ {
+				for len(row) <= col+i {
+					row = append(row, tableCell{})
+				}
+				row[col+i] = cell
+				for j := 1; j < rowspan; j++ {
+					if pending[r+j] == nil {
+						pending[r+j] = map[int]tableCell{}
+					}
+					pending[r+j][col+i] = cell
+				}
+			}
+			col += colspan
+		})
+
+		if inHeader && (inThead || (allTH && tr.ChildrenFiltered("th").Length() > 0)) {
+			headerRows++
+		} else {
+			inHeader = false
+		}
+		grid = append(grid, row)
+	})
+	return grid, headerRows
+}
+
+// tableSpan reads a colspan or rowspan attribute, treating missing or bad values as 1
+func tableSpan(td *goquery.Selection, attr string) int {
+	n, err := strconv.Atoi(strings.TrimSpace(td.AttrOr(attr, "1")))
+	if err != nil || n < 1 {
+		return 1
+	}
+	if n > maxTableSpan {
+		return maxTableSpan
+	}
+	return n
+}
+
+// tableHeader names each column by its header cells, top to bottom, skipping
+// repeats from spans; empty and duplicate names get made unique by columnKey
+func tableHeader(header [][]tableCell) []string {
+	width := 0
+	for _, row := range header {
+		if len(row) > width {
+			width = len(row)
+		}
+	}
+
+	names := make([]string, width)
+	seen := make(map[string]int)
+	for c := range names {
+		var parts []string
+		for _, row := range header {
+			if c < len(row) && row[c].text != "" && (len(parts) == 0 || parts[len(parts)-1] != row[c].text) {
+				parts = append(parts, row[c].text)
+			}
+		}
+		name := strings.Join(parts, " ")
+		if name != "" {
+			seen[name]++
+			if seen[name] > 1 {
+				name = fmt.Sprintf("%s_%d", name, seen[name])
+			}
+		}
+		names[c] = name
+	}
+	return names
+}
+
+// columnKey is the key of column c: its name, or column_N (1-based) past the
+// named columns or for a column without a header
+func columnKey(columns []string, c int) string {
+	if c < len(columns) && columns[c] != "" {
+		return columns[c]
+	}
+	return fmt.Sprintf("column_%d", c+1)
+}
@@ -0,0 +1,78 @@
+// internal/scraper/table_test.go
+package scraper
+
+import (
+	"reflect"
+	"strings"
+	"testing"
+
+	"github.com/PuerkitoBio/goquery"
+)
+
+func TestExtractTableField(t *testing.T) {
+	tests := []struct {
+		name    string
+		html    string
+		columns []string
+		want    []map[string]interface{}
+		wantErr bool
+	}{
+		{
+			name: "header cells become keys",
+			html: `<table><thead><tr><th>Year</th><th>Revenue</th></tr></thead>
+				<tbody><tr><td>2023</td><td>1.2M</td></tr><tr><td>2024</td><td>1.5M</td></tr></tbody></table>`,
+			want: []map[string]interface{}{
+				{"Year": "2023", "Revenue": "1.2M"},
+				{"Year": "2024", "Revenue": "1.5M"},
+			},
+		},
+		{
+			name: "grouped headers and spans",
+			html: `<div class="stats"><table>
+				<tr><th rowspan="2">Region</th><th colspan="2">Price</th></tr>
+				<tr><th>Low</th><th>High</th></tr>
+				<tr><td rowspan="2">North</td><td>10</td><td>12</td></tr>
+				<tr><td>11</td><td>14</td></tr>
+				<tr><td>South</td><td colspan="2">n/a</td></tr>
+				</table></div>`,
+			want: []map[string]interface{}{
+				{"Region": "North", "Price Low": "10", "Price High": "12"},
+				{"Region": "North", "Price Low": "11", "Price High": "14"},
+				{"Region": "South", "Price Low": "n/a", "Price High": "n/a"},
+			},
+		},
+		{
+			name:    "configured columns replace the header",
+			html:    `<table><tr><th>A</th><th>B</th></tr><tr><td>1</td><td>2</td><td>3</td></tr></table>`,
+			columns: []string{"first", "second"},
+			want:    []map[string]interface{}{{"first": "1", "second": "2", "column_3": "3"}},
+		},
+		{
+			name: "nested tables and empty rows are skipped",
+			html: `<table><tr><td>x</td><td><table><tr><td>inner</td></tr></table></td></tr><tr><td></td><td> </td></tr></table>`,
+			want: []map[string]interface{}{{"column_1": "x", "column_2": "inner"}},
+		},
+		{
+			name:    "header only",
+			html:    `<table><tr><th>A</th></tr></table>`,
+			wantErr: true,
+		},
+	}
+
+	clean := func(s *goquery.Selection) string { return strings.TrimSpace(s.Text()) }
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			doc, err := goquery.NewDocumentFromReader(strings.NewReader(tt.html))
+			if err != nil {
+				t.Fatalf("Failed to parse HTML: %v", err)
+			}
+			got, err := extractTable(doc.Find("body > *"), tt.columns, clean)
+			if (err != nil) != tt.wantErr {
+				t.Fatalf("err = %v, wantErr %v", err, tt.wantErr)
+			}
+			if !tt.wantErr && !reflect.DeepEqual(got, tt.want) {
+				t.Errorf("rows = %v, want %v", got, tt.want)
+			}
+		})
+	}
+}
@@ -0,0 +1,63 @@
+// internal/scraper/tls_mimic.go
+package scraper
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"net"
+	"time"
+)
+
+// Browser profiles accepted by tls.mimic
+const (
+	TLSMimicChrome  = "chrome"
+	TLSMimicFirefox = "firefox"
+	TLSMimicSafari  = "safari"
+)
+
+// ErrTLSMimicUnavailable is returned when tls.mimic is configured but the binary
+// was built without uTLS support (build with -tags utls)
+var ErrTLSMimicUnavailable = errors.New("TLS fingerprint mimicry requires a build with -tags utls")
+
+// TLSFingerprintConfig controls the TLS ClientHello presented to servers
+type TLSFingerprintConfig struct {
+	// Mimic presents a browser-like ClientHello (chrome, firefox, safari) instead of
+	// Go's default. Applies to direct connections; requests through an HTTP proxy
+	// keep the standard TLS stack.
+	Mimic string `yaml:"mimic,omitempty" json:"mimic,omitempty"`
+}
+
+// dialTLSFunc matches http.Transport.DialTLSContext
+type dialTLSFunc func(ctx context.Context, network, addr string) (net.Conn, error)
+
+// isValidTLSMimic reports whether profile is a supported tls.mimic value
+func isValidTLSMimic(profile string) bool {
+	switch profile {
+	case TLSMimicChrome, TLSMimicFirefox, TLSMimicSafari:
+		return true
+	}
+	return false
+}
+
+// newTLSMimicDialer returns the TLS dialer for the configured profile, or nil when
+// mimicry is not configured
+func newTLSMimicDialer(tlsCfg *TLSFingerprintConfig, tc *TransportConfig) (dialTLSFunc, error) {
+	if tlsCfg == nil || tlsCfg.Mimic == "" {
+		return nil, nil
+	}
+	if !isValidTLSMimic(tlsCfg.Mimic) {
+		return nil, fmt.Errorf("unsupported tls.mimic profile: %s", tlsCfg.Mimic)
+	}
+
+	dialer := &net.Dialer{Timeout: 30 * time.Second, KeepAlive: 30 * time.Second}
+	var handshakeTimeout time.Duration // The transport's own timeout does not cover a custom TLS dialer
+	if tc != nil {
+		if tc.DialTimeout > 0 {
+			dialer.Timeout = tc.DialTimeout
+		}
+		handshakeTimeout = tc.TLSHandshakeTimeout
+	}
+
+	return mimicDialTLS(tlsCfg.Mimic, dialer, handshakeTimeout)
+}
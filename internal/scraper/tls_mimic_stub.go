@@ -0,0 +1,14 @@
+//go:build !utls
+
+// internal/scraper/tls_mimic_stub.go
+package scraper
+
+import (
+	"net"
+	"time"
+)
+
+// mimicDialTLS is unavailable without the utls build tag
+func mimicDialTLS(profile string, dialer *net.Dialer, handshakeTimeout time.Duration) (dialTLSFunc, error) {
+	return nil, ErrTLSMimicUnavailable
+}
@@ -0,0 +1,73 @@
+//go:build utls
+
+// internal/scraper/tls_mimic_utls.go
+//
+// Built only with -tags utls, which compiles in github.com/refraction-networking/utls.
+package scraper
+
+import (
+	"context"
+	"fmt"
+	"net"
+	"time"
+
+	utls "github.com/refraction-networking/utls"
+)
+
+// mimicHelloIDs maps tls.mimic profiles to uTLS ClientHello presets
+var mimicHelloIDs = map[string]utls.ClientHelloID{
+	TLSMimicChrome:  utls.HelloChrome_Auto,
+	TLSMimicFirefox: utls.HelloFirefox_Auto,
+	TLSMimicSafari:  utls.HelloSafari_Auto,
+}
+
+// mimicDialTLS returns a dialer performing the TLS handshake with a browser ClientHello.
+// ALPN is pinned to http/1.1 because net/http cannot take over an h2 connection
+// negotiated by a custom dialer. A positive handshakeTimeout bounds the handshake.
+func mimicDialTLS(profile string, dialer *net.Dialer, handshakeTimeout time.Duration) (dialTLSFunc, error) {
+	helloID, ok := mimicHelloIDs[profile]
+	if !ok {
+		return nil, fmt.Errorf("unsupported tls.mimic profile: %s", profile)
+	}
+
+	return func(ctx context.Context, network, addr string) (net.Conn, error) {
+		host, _, err := net.SplitHostPort(addr)
+		if err != nil {
+			return nil, fmt.Errorf("invalid TLS address %s: %w", addr, err)
+		}
+
+		conn, err := dialer.DialContext(ctx, network, addr)
+		if err != nil {
+			return nil, err
+		}
+
+		spec, err := utls.UTLSIdToSpec(helloID)
+		if err != nil {
+			conn.Close()
+			return nil, fmt.Errorf("failed to build %s ClientHello: %w", profile, err)
+		}
+		for _, ext := range spec.Extensions {
+			if alpn, ok := ext.(*utls.ALPNExtension); ok {
+				alpn.AlpnProtocols = []string{"http/1.1"}
+			}
+		}
+
+		uconn := utls.UClient(conn, &utls.Config{ServerName: host}, utls.HelloCustom)
+		if err := uconn.ApplyPreset(&spec); err != nil {
+			conn.Close()
+			return nil, fmt.Errorf("failed to apply %s ClientHello: %w", profile, err)
+		}
+		handshakeCtx := ctx
+		if handshakeTimeout > 0 {
+			var cancel context.CancelFunc
+			handshakeCtx, cancel = context.WithTimeout(ctx, handshakeTimeout)
+			defer cancel()
+		}
+		if err := uconn.HandshakeContext(handshakeCtx); err != nil {
+			conn.Close()
+			return nil, fmt.Errorf("TLS handshake failed: %w", err)
+		}
+
+		return uconn, nil
+	}, nil
+}
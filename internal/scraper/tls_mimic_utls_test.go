@@ -0,0 +1,27 @@
+//go:build utls
+
+// internal/scraper/tls_mimic_utls_test.go
+package scraper
+
+import (
+	"context"
+	"testing"
+	"time"
+)
+
+func TestTLSMimicHandshakeTimeout(t *testing.T) {
+	addr := stalledTLSServer(t)
+	dialTLS, err := newTLSMimicDialer(&TLSFingerprintConfig{Mimic: TLSMimicChrome},
+		&TransportConfig{TLSHandshakeTimeout: 100 * time.Millisecond})
+	if err != nil {
+		t.Fatalf("Failed to create mimic dialer: %v", err)
+	}
+
+	start := time.Now()
+	if _, err := dialTLS(context.Background(), "tcp", addr); err == nil {
+		t.Fatal("Expected the stalled handshake to fail")
+	}
+	if elapsed := time.Since(start); elapsed > 5*time.Second {
+		t.Errorf("Expected the handshake to time out after 100ms, took %v", elapsed)
+	}
+}
@@ -3,6 +3,7 @@ package scraper
 
 import (
 	"fmt"
+	"net/http"
 	"time"
 
 	"github.com/valpere/DataScrapexter/internal/config"
@@ -22,12 +23,37 @@ var (
 // FieldConfig defines extraction configuration for a single field
 type FieldConfig struct {
 	Name      string                   `yaml:"name" json:"name"`
-	Selector  string                   `yaml:"selector" json:"selector"`
+	Selector  string                   `yaml:"selector" json:"selector"` // CSS selector, the header name for type "header" (case-insensitive), or the item path for types "jsonld" and "microdata"
 	Type      string                   `yaml:"type" json:"type"`
 	Required  bool                     `yaml:"required,omitempty" json:"required,omitempty"`
 	Transform []pipeline.TransformRule `yaml:"transform,omitempty" json:"transform,omitempty"`
 	Default   interface{}              `yaml:"default,omitempty" json:"default,omitempty"`
 	Attribute string                   `yaml:"attribute,omitempty" json:"attribute,omitempty"`
+	Sources   []FieldSource            `yaml:"sources,omitempty" json:"sources,omitempty"` // Ordered fallbacks; first non-empty wins (Selector/Type unused)
+	Path      string                   `yaml:"path,omitempty" json:"path,omitempty"`       // JSON path into the script blob for embedded_json fields
+	Columns   []string                 `yaml:"columns,omitempty" json:"columns,omitempty"` // Row keys for table fields, in column order; default: the header cells
+
+	// JSONLDType limits jsonld and microdata fields to items of this @type, e.g. Product
+	JSONLDType string `yaml:"jsonld_type,omitempty" json:"jsonld_type,omitempty"`
+
+	// SelectorType says how Selector is read: SelectorCSS (the default) or SelectorXPath
+	SelectorType string `yaml:"selector_type,omitempty" json:"selector_type,omitempty"`
+
+	// Selectors are fallbacks for markup that varies between page versions,
+	// tried in order after Selector (which may be empty); the first to yield a
+	// non-empty value is used
+	Selectors []string `yaml:"selectors,omitempty" json:"selectors,omitempty"`
+
+	// ExtractTimeout bounds how long extracting this field may take; a field that
+	// runs over is treated as missing. Zero means DefaultRegexExtractTimeout for
+	// fields with regex sources and no limit otherwise.
+	ExtractTimeout time.Duration `yaml:"extract_timeout,omitempty" json:"extract_timeout,omitempty"`
+
+	// Validate checks the value after Default and Transform; see processField for the order
+	Validate *FieldValidation `yaml:"validate,omitempty" json:"validate,omitempty"`
+
+	// OutputType coerces the final value to a JSON number, integer or boolean
+	OutputType string `yaml:"output_type,omitempty" json:"output_type,omitempty"`
 }
 
 // ExtractionConfig defines configuration for the extraction engine
@@ -105,13 +131,14 @@ type ScrapingMetadata struct {
 
 // ScrapingResult represents the result of a scraping operation
 type ScrapingResult struct {
-	URL        string                 `json:"url"`
-	StatusCode int                    `json:"status_code"`
-	Data       map[string]interface{} `json:"data"`
-	Metadata   ScrapingMetadata       `json:"metadata"`
-	Success    bool                   `json:"success"`
-	Errors     []string               `json:"errors,omitempty"`
-	Warnings   []string               `json:"warnings,omitempty"`
+	URL          string                 `json:"url"`
+	CanonicalURL string                 `json:"canonical_url,omitempty"`
+	StatusCode   int                    `json:"status_code"`
+	Data         map[string]interface{} `json:"data"`
+	Metadata     ScrapingMetadata       `json:"metadata"`
+	Success      bool                   `json:"success"`
+	Errors       []string               `json:"errors,omitempty"`
+	Warnings     []string               `json:"warnings,omitempty"`
 }
 
 // FieldError represents an error during field extraction
@@ -175,20 +202,97 @@ func (s *Selector) ValidateSelector(expression string) error {
 // Config represents the scraper engine configuration
 type Config struct {
 	MaxRetries      int                  `yaml:"max_retries" json:"max_retries"`
+	DNSRetries      int                  `yaml:"dns_retries,omitempty" json:"dns_retries,omitempty"` // Retries for hosts that do not resolve; connection errors keep the normal budget
 	RetryDelay      time.Duration        `yaml:"retry_delay" json:"retry_delay"`
+	RetryJitter     float64              `yaml:"retry_jitter,omitempty" json:"retry_jitter,omitempty"` // Randomizes retry delays by up to this fraction (0-1) either way
 	Timeout         time.Duration        `yaml:"timeout" json:"timeout"`
 	FollowRedirects bool                 `yaml:"follow_redirects" json:"follow_redirects"`
 	MaxRedirects    int                  `yaml:"max_redirects" json:"max_redirects"`
 	RateLimit       time.Duration        `yaml:"rate_limit" json:"rate_limit"`
 	BurstSize       int                  `yaml:"burst_size" json:"burst_size"`
-	Headers         map[string]string    `yaml:"headers" json:"headers"`
+	Headers         map[string][]string  `yaml:"headers" json:"headers"` // Each value is sent as its own header line
 	UserAgents      []string             `yaml:"user_agents" json:"user_agents"`
 	Browser         *BrowserConfig       `yaml:"browser" json:"browser"`
 	Proxy           *ProxyConfig         `yaml:"proxy" json:"proxy"`
+	Transport       *TransportConfig     `yaml:"transport,omitempty" json:"transport,omitempty"`
+	Debug           *DebugConfig         `yaml:"debug,omitempty" json:"debug,omitempty"`
+	TLS             *TLSFingerprintConfig `yaml:"tls,omitempty" json:"tls,omitempty"`
+	HeaderOrder     *HeaderOrderConfig    `yaml:"header_order,omitempty" json:"header_order,omitempty"`
 	Pagination      *PaginationConfig    `yaml:"pagination" json:"pagination"`
 	RateLimiter     *RateLimiterConfig   `yaml:"rate_limiter" json:"rate_limiter"`
+	HostRateLimits  map[string]HostRateLimit `yaml:"host_rate_limits,omitempty" json:"host_rate_limits,omitempty"` // Per-host overrides of RateLimit/BurstSize, by host or host:port
 	ErrorRecovery   *ErrorRecoveryConfig `yaml:"error_recovery" json:"error_recovery"`
 	MaxConcurrency  int                  `yaml:"max_concurrency" json:"max_concurrency"` // Maximum concurrent operations
+
+	// RespectCrawlDelay reads robots.txt Crawl-delay per host and uses it when slower than RateLimit
+	RespectCrawlDelay bool `yaml:"respect_crawl_delay" json:"respect_crawl_delay"`
+
+	// RespectRobotsTxt skips URLs robots.txt disallows with ErrRobotsDisallowed and
+	// honors its Crawl-delay like RespectCrawlDelay
+	RespectRobotsTxt bool `yaml:"respect_robots_txt" json:"respect_robots_txt"`
+
+	// NormalizeText applies pipeline.NormalizeText to text and list fields; nil means enabled
+	NormalizeText *bool `yaml:"normalize_text,omitempty" json:"normalize_text,omitempty"`
+
+	// HiddenContent picks which of noscript, template, script and style content
+	// extraction sees; nil keeps noscript and drops the rest
+	HiddenContent *HiddenContentConfig `yaml:"hidden_content,omitempty" json:"hidden_content,omitempty"`
+
+	// LazyImages makes attr fields reading src or srcset prefer lazy-load
+	// attributes such as data-src; nil uses the default attribute lists
+	LazyImages *LazyImageConfig `yaml:"lazy_images,omitempty" json:"lazy_images,omitempty"`
+
+	// StrictMode fails a field whose value output_type cannot coerce; otherwise
+	// the value is kept as extracted
+	StrictMode bool `yaml:"strict_mode,omitempty" json:"strict_mode,omitempty"`
+
+	// MalformedHTML warns about, and optionally re-fetches, pages whose markup
+	// is badly unbalanced or cut off; nil skips the check
+	MalformedHTML *MalformedHTMLConfig `yaml:"malformed_html,omitempty" json:"malformed_html,omitempty"`
+
+	// CanonicalURL keys pages by canonical URL in the pagination visited set and
+	// fills Result.CanonicalURL; nil keys pages by their exact URL
+	CanonicalURL *CanonicalURLConfig `yaml:"canonical_url,omitempty" json:"canonical_url,omitempty"`
+
+	// RetryOnEmpty re-fetches a page, up to MaxRetries times, when all of
+	// RetryOnEmptyFields (default: the required fields) missed
+	RetryOnEmpty       bool     `yaml:"retry_on_empty,omitempty" json:"retry_on_empty,omitempty"`
+	RetryOnEmptyFields []string `yaml:"retry_on_empty_fields,omitempty" json:"retry_on_empty_fields,omitempty"`
+
+	// RetryOnBodyMatch and NoRetryOnBodyMatch are regular expressions checked
+	// against the body of successful responses. A retry match fails the fetch
+	// with a retryable error, a no-retry match with a permanent one; no-retry wins.
+	RetryOnBodyMatch   []string `yaml:"retry_on_body_match,omitempty" json:"retry_on_body_match,omitempty"`
+	NoRetryOnBodyMatch []string `yaml:"no_retry_on_body_match,omitempty" json:"no_retry_on_body_match,omitempty"`
+
+	// WarmupURLs are fetched in order before the first scrape, sharing the cookie
+	// jar with later requests; their bodies are discarded
+	WarmupURLs []string `yaml:"warmup_urls,omitempty" json:"warmup_urls,omitempty"`
+
+	// CookieJar keeps every Set-Cookie from responses and sends the cookies back
+	// on later requests; warmup_urls turns it on as well
+	CookieJar bool `yaml:"cookie_jar,omitempty" json:"cookie_jar,omitempty"`
+
+	// CookieJarFile loads the cookie jar from this file when it exists and lets
+	// SaveCookies write it back, so sessions carry over between runs; it turns
+	// the cookie jar on as well
+	CookieJarFile string `yaml:"cookie_jar_file,omitempty" json:"cookie_jar_file,omitempty"`
+
+	// DisableCompression sends no Accept-Encoding of our own and leaves response
+	// bodies as sent; by default gzip, deflate and brotli are requested and decoded
+	DisableCompression bool `yaml:"disable_compression,omitempty" json:"disable_compression,omitempty"`
+
+	// MaxPagesPerHost caps the pages scraped from any one host in a run; URLs
+	// beyond it fail with ErrHostPageLimit without a request. Zero means no cap.
+	MaxPagesPerHost int `yaml:"max_pages_per_host,omitempty" json:"max_pages_per_host,omitempty"`
+
+	// BlockAbort stops requesting a host once it keeps answering with block statuses
+	BlockAbort *BlockAbortConfig `yaml:"block_abort,omitempty" json:"block_abort,omitempty"`
+
+	// Request is the method and body sent for every page, GET without a body
+	// when empty; URLRequests replaces it for the URLs it names
+	Request     RequestSpec            `yaml:"request,omitempty" json:"request,omitempty"`
+	URLRequests map[string]RequestSpec `yaml:"url_requests,omitempty" json:"url_requests,omitempty"`
 }
 
 // Validate validates the scraper configuration
@@ -205,6 +309,29 @@ func (c *Config) Validate() error {
 	if c.MaxRetries < 0 {
 		return fmt.Errorf("max_retries must be non-negative, got %d", c.MaxRetries)
 	}
+	if c.MaxPagesPerHost < 0 {
+		return fmt.Errorf("max_pages_per_host must be non-negative, got %d", c.MaxPagesPerHost)
+	}
+	if c.DNSRetries < 0 {
+		return fmt.Errorf("dns_retries must be non-negative, got %d", c.DNSRetries)
+	}
+	if c.RetryJitter < 0 || c.RetryJitter > 1 {
+		return fmt.Errorf("retry_jitter must be between 0 and 1, got %g", c.RetryJitter)
+	}
+	if c.MalformedHTML != nil && c.MalformedHTML.MaxUnbalancedTags < 0 {
+		return fmt.Errorf("malformed_html.max_unbalanced_tags must be non-negative, got %d", c.MalformedHTML.MaxUnbalancedTags)
+	}
+	if err := c.Request.validate(); err != nil {
+		return fmt.Errorf("request: %w", err)
+	}
+	if c.Browser != nil && c.Browser.Enabled && c.Request.method() != http.MethodGet {
+		return fmt.Errorf("request: browser fetching only sends GET, not %s", c.Request.method())
+	}
+	for pageURL, spec := range c.URLRequests {
+		if err := spec.validate(); err != nil {
+			return fmt.Errorf("url_requests[%s]: %w", pageURL, err)
+		}
+	}
 	if c.Timeout < 0 {
 		return fmt.Errorf("timeout must be non-negative, got %v", c.Timeout)
 	}
@@ -214,10 +341,55 @@ func (c *Config) Validate() error {
 	if c.BurstSize < 0 {
 		return fmt.Errorf("burst_size must be non-negative, got %d", c.BurstSize)
 	}
+	for host, limit := range c.HostRateLimits {
+		if limit.RateLimit <= 0 {
+			return fmt.Errorf("host_rate_limits[%s].rate_limit must be positive, got %v", host, limit.RateLimit)
+		}
+		if limit.BurstSize < 0 {
+			return fmt.Errorf("host_rate_limits[%s].burst_size must be non-negative, got %d", host, limit.BurstSize)
+		}
+	}
+	if c.Transport != nil {
+		if c.Transport.DialTimeout < 0 {
+			return fmt.Errorf("transport.dial_timeout must be non-negative, got %v", c.Transport.DialTimeout)
+		}
+		if c.Transport.TLSHandshakeTimeout < 0 {
+			return fmt.Errorf("transport.tls_handshake_timeout must be non-negative, got %v", c.Transport.TLSHandshakeTimeout)
+		}
+		if c.Transport.ResponseHeaderTimeout < 0 {
+			return fmt.Errorf("transport.response_header_timeout must be non-negative, got %v", c.Transport.ResponseHeaderTimeout)
+		}
+	}
+	if c.BlockAbort != nil {
+		if c.BlockAbort.Threshold < 0 {
+			return fmt.Errorf("block_abort.threshold must be non-negative, got %d", c.BlockAbort.Threshold)
+		}
+		if c.BlockAbort.Window < 0 {
+			return fmt.Errorf("block_abort.window must be non-negative, got %v", c.BlockAbort.Window)
+		}
+	}
+	if c.TLS != nil && c.TLS.Mimic != "" && !isValidTLSMimic(c.TLS.Mimic) {
+		return fmt.Errorf("tls.mimic must be one of chrome, firefox, safari, got %q", c.TLS.Mimic)
+	}
 	
 	return nil
 }
 
+// TransportConfig holds per-phase connection deadlines. Zero values leave the
+// corresponding http.Transport / net.Dialer field at its default, so only the
+// overall Timeout applies.
+type TransportConfig struct {
+	DialTimeout           time.Duration `yaml:"dial_timeout,omitempty" json:"dial_timeout,omitempty"`
+	TLSHandshakeTimeout   time.Duration `yaml:"tls_handshake_timeout,omitempty" json:"tls_handshake_timeout,omitempty"`
+	ResponseHeaderTimeout time.Duration `yaml:"response_header_timeout,omitempty" json:"response_header_timeout,omitempty"`
+
+	// MaxTotalConnections caps open connections across all hosts and proxies,
+	// including idle keep-alive ones. Dials past the cap queue instead of failing.
+	// It sits below MaxConcurrency and per-host pacing: those decide how many
+	// requests run, this bounds the sockets they may hold. Zero means no cap.
+	MaxTotalConnections int `yaml:"max_total_connections,omitempty" json:"max_total_connections,omitempty"`
+}
+
 // ProxyConfig represents proxy configuration for the scraper
 type ProxyConfig struct {
 	Enabled          bool            `yaml:"enabled" json:"enabled"`
@@ -232,6 +404,20 @@ type ProxyConfig struct {
 	FailureThreshold int             `yaml:"failure_threshold" json:"failure_threshold"`
 	RecoveryTime     time.Duration   `yaml:"recovery_time" json:"recovery_time"`
 	TLS              *ProxyTLSConfig `yaml:"tls,omitempty" json:"tls,omitempty"`
+	Affinity         string          `yaml:"proxy_affinity,omitempty" json:"proxy_affinity,omitempty"`
+	AffinityRequests int             `yaml:"affinity_requests,omitempty" json:"affinity_requests,omitempty"`
+
+	// LogSelection logs which proxy served each request; IncludeInMeta also records
+	// it under _meta in the result data. Proxy credentials are never included.
+	LogSelection  bool `yaml:"log_selection,omitempty" json:"log_selection,omitempty"`
+	IncludeInMeta bool `yaml:"include_in_meta,omitempty" json:"include_in_meta,omitempty"`
+
+	// HostGroups sends requests for matching hosts (exact or "*.example.com")
+	// through the providers of one group only
+	HostGroups map[string]string `yaml:"host_groups,omitempty" json:"host_groups,omitempty"`
+
+	// HealthCheckMaxInterval caps the backoff of proxies failing health checks
+	HealthCheckMaxInterval time.Duration `yaml:"health_check_max_interval,omitempty" json:"health_check_max_interval,omitempty"`
 }
 
 // ProxyProvider represents a proxy provider configuration
@@ -244,6 +430,7 @@ type ProxyProvider struct {
 	Password string `yaml:"password,omitempty" json:"password,omitempty"`
 	Weight   int    `yaml:"weight,omitempty" json:"weight,omitempty"`
 	Enabled  bool   `yaml:"enabled" json:"enabled"`
+	Group    string `yaml:"group,omitempty" json:"group,omitempty"`
 }
 
 // ProxyTLSConfig represents TLS configuration for proxy connections
@@ -278,11 +465,15 @@ type BrowserConfig struct {
 type PaginationType string
 
 const (
-	PaginationTypeNextButton PaginationType = "next_button" // Click next button
-	PaginationTypePages      PaginationType = "pages"       // Navigate through numbered pages
-	PaginationTypeURLPattern PaginationType = "url_pattern" // URL pattern with page number
-	PaginationTypeScrolling  PaginationType = "scrolling"   // Infinite scroll or load more
-	PaginationTypeOffset     PaginationType = "offset"      // URL offset/limit parameters
+	PaginationTypeNextButton     PaginationType = "next_button"     // Click next button
+	PaginationTypePages          PaginationType = "pages"           // Navigate through numbered pages
+	PaginationTypeURLPattern     PaginationType = "url_pattern"     // URL pattern with page number
+	PaginationTypeScrolling      PaginationType = "scrolling"       // Infinite scroll or load more
+	PaginationTypeOffset         PaginationType = "offset"          // URL offset/limit parameters
+	PaginationTypeLinkHeader     PaginationType = "link_header"     // Follow Link: rel="next" response headers
+	PaginationTypeCursor         PaginationType = "cursor"          // Feed a token from each response into the next request
+	PaginationTypeSitemap        PaginationType = "sitemap"         // Scrape the page URLs a sitemap lists
+	PaginationTypeInfiniteScroll PaginationType = "infinite_scroll" // Scroll a browser page to the bottom for more items
 )
 
 // PaginationConfig represents pagination configuration
@@ -310,6 +501,23 @@ type PaginationConfig struct {
 	LoadMoreSelector string        `yaml:"load_more_selector,omitempty" json:"load_more_selector,omitempty"`
 	ScrollPause      time.Duration `yaml:"scroll_pause,omitempty" json:"scroll_pause,omitempty"`
 
+	// Infinite scroll pagination: ItemSelector matches the items the scrolls
+	// load, which are told apart by their HTML so each is scraped once
+	ItemSelector string `yaml:"item_selector,omitempty" json:"item_selector,omitempty"`
+
+	// Cursor pagination: CursorSelector is a CSS selector (text, or CursorAttr) or,
+	// when it starts with "$", a JSON path into a JSON response body. The token is
+	// sent as the CursorParam query parameter; an empty token ends pagination.
+	CursorSelector string `yaml:"cursor_selector,omitempty" json:"cursor_selector,omitempty"`
+	CursorAttr     string `yaml:"cursor_attr,omitempty" json:"cursor_attr,omitempty"`
+	CursorParam    string `yaml:"cursor_param,omitempty" json:"cursor_param,omitempty"`
+
+	// Sitemap pagination: pages come from SitemapURL, a <urlset> or <sitemapindex>
+	// (plain or gzip), instead of links on the pages. URLFilter is a glob over page
+	// URLs in which * matches any run of characters, e.g. https://shop.com/p/*.
+	SitemapURL string `yaml:"sitemap_url,omitempty" json:"sitemap_url,omitempty"`
+	URLFilter  string `yaml:"url_filter,omitempty" json:"url_filter,omitempty"`
+
 	// Offset pagination
 	OffsetParam string `yaml:"offset_param,omitempty" json:"offset_param,omitempty"`
 	LimitParam  string `yaml:"limit_param,omitempty" json:"limit_param,omitempty"`
@@ -353,6 +561,13 @@ type FallbackSpec struct {
 	DefaultValue interface{}            `yaml:"default_value,omitempty" json:"default_value,omitempty"`
 	Alternative  string                 `yaml:"alternative,omitempty" json:"alternative,omitempty"`
 	Degraded     map[string]interface{} `yaml:"degraded,omitempty" json:"degraded,omitempty"`
+
+	// APIURL is fetched by the "api_fallback" alternative; {url}, {host} and {path} are filled from the page URL
+	APIURL     string        `yaml:"api_url,omitempty" json:"api_url,omitempty"`
+	APITimeout time.Duration `yaml:"api_timeout,omitempty" json:"api_timeout,omitempty"`
+
+	// Chain tries each fallback in order until one succeeds; the fields above are then unused
+	Chain []FallbackSpec `yaml:"chain,omitempty" json:"chain,omitempty"`
 }
 
 // Note: FieldExtractor is defined in extractor.go as a struct that processes fields
@@ -0,0 +1,58 @@
+// internal/scraper/warmup.go
+package scraper
+
+import (
+	"context"
+	"fmt"
+
+	"github.com/valpere/DataScrapexter/internal/errors"
+	"github.com/valpere/DataScrapexter/internal/utils"
+)
+
+// warmup fetches the configured warmup URLs once per engine, in order, so the
+// cookie jar holds any session or anti-CSRF cookies before the first real page
+func (e *Engine) warmup(ctx context.Context) error {
+	if len(e.config.WarmupURLs) == 0 {
+		return nil
+	}
+	e.warmupOnce.Do(func() {
+		e.warmupErr = e.runWarmup(ctx)
+	})
+	return e.warmupErr
+}
+
+// runWarmup requests each warmup URL with retries, discarding the bodies.
+// It fails with errors.ErrAuth only when every warmup URL failed.
+func (e *Engine) runWarmup(ctx context.Context) error {
+	logger := utils.NewComponentLogger("scraper-warmup")
+
+	opts := []errors.ExecuteOption{errors.WithJitter(e.config.RetryJitter)}
+	if e.config.MaxRetries > 0 {
+		opts = append(opts, errors.WithMaxRetries(e.config.MaxRetries))
+	}
+
+	var lastErr error
+	succeeded := 0
+	for _, warmupURL := range e.config.WarmupURLs {
+		if err := e.checkRobots(ctx, warmupURL); err != nil {
+			logger.Warnf("Skipping warmup request to %s: %v", warmupURL, err)
+			lastErr = err
+			continue
+		}
+		err := e.errorService.ExecuteWithRetry(ctx, func() error {
+			_, err := e.fetchDocument(ctx, warmupURL)
+			return err
+		}, errors.ScopedOperation("warmup", requestHost(warmupURL)), opts...)
+		if err != nil {
+			logger.Warnf("Warmup request to %s failed: %v", warmupURL, err)
+			lastErr = err
+			continue
+		}
+		succeeded++
+	}
+
+	if succeeded == 0 {
+		return fmt.Errorf("%w: all %d warmup request(s) failed: %v", errors.ErrAuth, len(e.config.WarmupURLs), lastErr)
+	}
+	return nil
+}
@@ -0,0 +1,56 @@
+// internal/scraper/xpath.go
+package scraper
+
+import (
+	"fmt"
+	"sync"
+
+	"github.com/PuerkitoBio/goquery"
+	"github.com/antchfx/htmlquery"
+	"github.com/antchfx/xpath"
+	"golang.org/x/net/html"
+)
+
+// Selector types for FieldConfig.SelectorType
+const (
+	SelectorCSS   = "css"
+	SelectorXPath = "xpath"
+)
+
+// xpathCache holds compiled XPath expressions, which every page of a run reuses
+var xpathCache sync.Map // expression -> *xpath.Expr
+
+// compileXPath compiles expr, or returns the cached compilation
+func compileXPath(expr string) (*xpath.Expr, error) {
+	if cached, ok := xpathCache.Load(expr); ok {
+		return cached.(*xpath.Expr), nil
+	}
+	compiled, err := xpath.Compile(expr)
+	if err != nil {
+		return nil, fmt.Errorf("%w: xpath %q: %v", ErrInvalidSelector, expr, err)
+	}
+	xpathCache.Store(expr, compiled)
+	return compiled, nil
+}
+
+// selectNodes returns what selector matches in doc, read as CSS or as XPath per
+// selectorType. XPath may select attributes (//a/@href); each comes back as a
+// detached element whose text is the attribute value.
+func selectNodes(doc *goquery.Document, selector, selectorType string) (*goquery.Selection, error) {
+	if selectorType != SelectorXPath {
+		return doc.Find(selector), nil
+	}
+
+	expr, err := compileXPath(selector)
+	if err != nil {
+		return nil, err
+	}
+	var nodes []*html.Node
+	for _, root := range doc.Nodes {
+		nodes = append(nodes, htmlquery.QuerySelectorAll(root, expr)...)
+	}
+	// A fresh slice, so the document's own node list is never appended to
+	selection := doc.Selection.Slice(0, 0)
+	selection.Nodes = nodes
+	return selection, nil
+}
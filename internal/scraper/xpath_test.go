@@ -0,0 +1,64 @@
+package scraper
+
+import (
+	"context"
+	"net/http"
+	"net/http/httptest"
+	"reflect"
+	"testing"
+	"time"
+)
+
+const xpathPage = `<!DOCTYPE html><html><body>
+<div id="price">Price</div><span>$19.99</span>
+<ul class="tags"><li>new</li><li>sale</li></ul>
+<a class="next" href="/page/2">Next</a>
+</body></html>`
+
+func TestScrapeXPathFields(t *testing.T) {
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		w.Write([]byte(xpathPage))
+	}))
+	defer server.Close()
+
+	engine, err := NewEngine(&Config{Timeout: 10 * time.Second, RateLimit: 10 * time.Millisecond, BurstSize: 1})
+	if err != nil {
+		t.Fatalf("Failed to create engine: %v", err)
+	}
+
+	fields := []FieldConfig{
+		{Name: "price", Type: "text", SelectorType: SelectorXPath, Selector: "//div[@id='price']/following-sibling::span"},
+		{Name: "tags", Type: "list", SelectorType: SelectorXPath, Selector: "//ul[contains(@class, 'tags')]/li"},
+		{Name: "next", Type: "attr", SelectorType: SelectorXPath, Selector: "//a[text()='Next']", Attribute: "href"},
+		{Name: "next_text", Type: "text", SelectorType: SelectorXPath, Selector: "//a[@class='next']/@href"},
+		{Name: "css", Type: "text", Selector: "#price + span"},
+		{Name: "missing", Type: "text", SelectorType: SelectorXPath, Selector: "//table"},
+		{Name: "invalid", Type: "text", SelectorType: SelectorXPath, Selector: "//div[@id="},
+	}
+
+	result, err := engine.Scrape(context.Background(), server.URL, fields)
+	if err != nil {
+		t.Fatalf("Scraping failed: %v", err)
+	}
+
+	expected := map[string]interface{}{
+		"price":     "$19.99",
+		"tags":      []string{"new", "sale"},
+		"next":      "/page/2",
+		"next_text": "/page/2",
+		"css":       "$19.99",
+	}
+	for name, want := range expected {
+		if got := result.Data[name]; !reflect.DeepEqual(got, want) {
+			t.Errorf("%s = %v (%T), want %v", name, got, got, want)
+		}
+	}
+	for _, name := range []string{"missing", "invalid"} {
+		if _, ok := result.Data[name]; ok {
+			t.Errorf("Expected no value for %s", name)
+		}
+	}
+	if len(result.Errors) != 2 {
+		t.Errorf("Expected errors for the missing and invalid selectors, got %v", result.Errors)
+	}
+}
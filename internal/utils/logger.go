@@ -2,34 +2,50 @@
 package utils
 
 import (
-	"bytes"
+	"encoding/json"
 	"fmt"
+	"io"
 	"log"
 	"os"
+	"strings"
+	"sync"
+	"time"
 )
 
 // ComponentLogger represents a component-specific logger
 type ComponentLogger struct {
 	component string
 	logger    *log.Logger
+	fields    map[string]interface{} // Written by the JSON format only
 }
 
 // NewComponentLogger creates a new component logger
 func NewComponentLogger(component string) *ComponentLogger {
 	return &ComponentLogger{
 		component: component,
-		logger:    log.New(os.Stdout, fmt.Sprintf("[%s] ", component), log.LstdFlags),
+		logger:    log.New(globalLogWriter{}, fmt.Sprintf("[%s] ", component), log.LstdFlags),
 	}
 }
 
-// WithField adds a field to the log context (simplified implementation)
+// WithField returns a logger that adds a field to each entry. Fields appear in
+// the JSON log format; the text format leaves them out.
 func (cl *ComponentLogger) WithField(key string, value interface{}) *ComponentLogger {
-	return cl
+	return cl.WithFields(map[string]interface{}{key: value})
 }
 
-// WithFields adds multiple fields to the log context (simplified implementation)
+// WithFields returns a logger that adds fields to each entry, as WithField does
 func (cl *ComponentLogger) WithFields(fields map[string]interface{}) *ComponentLogger {
-	return cl
+	merged := make(map[string]interface{}, len(cl.fields)+len(fields))
+	for key, value := range cl.fields {
+		merged[key] = value
+	}
+	for key, value := range fields {
+		if err, ok := value.(error); ok {
+			value = err.Error() // An error encodes as {} otherwise
+		}
+		merged[key] = value
+	}
+	return &ComponentLogger{component: cl.component, logger: cl.logger, fields: merged}
 }
 
 // LogLevel represents logging levels
@@ -49,85 +65,170 @@ func SetGlobalLogLevel(level LogLevel) {
 	globalLogLevel = level
 }
 
-// SetGlobalLogOutput sets the global log output (for testing)
-func SetGlobalLogOutput(output *bytes.Buffer) {
-	// Simplified implementation for now
+// LogFormat selects how component loggers write entries
+type LogFormat string
+
+const (
+	LogFormatText LogFormat = "text" // "[component] date time LEVEL: message" (default)
+	LogFormatJSON LogFormat = "json" // One JSON object per line: time, level, component, message, fields
+)
+
+// LogFormatEnv is the environment variable that selects the log format when
+// no --log-format flag is given
+const LogFormatEnv = "DATASCRAPEXTER_LOG_FORMAT"
+
+// ParseLogFormat reads a log format name; "" is the text format
+func ParseLogFormat(name string) (LogFormat, error) {
+	switch LogFormat(strings.ToLower(strings.TrimSpace(name))) {
+	case "", LogFormatText:
+		return LogFormatText, nil
+	case LogFormatJSON:
+		return LogFormatJSON, nil
+	}
+	return "", fmt.Errorf("invalid log format %q: want text or json", name)
+}
+
+var (
+	logOutputMu sync.RWMutex
+	logOutput   io.Writer = os.Stdout
+	logFormat             = LogFormatText
+)
+
+// globalLogWriter forwards to the current global log output, so loggers created
+// at package init follow a later SetGlobalLogOutput
+type globalLogWriter struct{}
+
+func (globalLogWriter) Write(p []byte) (int, error) {
+	logOutputMu.RLock()
+	defer logOutputMu.RUnlock()
+	return logOutput.Write(p)
+}
+
+// SetGlobalLogOutput redirects every component logger, e.g. to os.Stderr when
+// stdout carries data
+func SetGlobalLogOutput(output io.Writer) {
+	logOutputMu.Lock()
+	defer logOutputMu.Unlock()
+	logOutput = output
+}
+
+// SetGlobalLogFormat switches every component logger to format
+func SetGlobalLogFormat(format LogFormat) {
+	logOutputMu.Lock()
+	defer logOutputMu.Unlock()
+	logFormat = format
+}
+
+// jsonLogEntry is one line of the JSON log format
+type jsonLogEntry struct {
+	Time      string                 `json:"time"`
+	Level     string                 `json:"level"`
+	Component string                 `json:"component"`
+	Message   string                 `json:"message"`
+	Fields    map[string]interface{} `json:"fields,omitempty"`
+}
+
+// output writes one entry at level (DEBUG, INFO and so on) in the global format
+func (cl *ComponentLogger) output(level, msg string) {
+	logOutputMu.RLock()
+	format := logFormat
+	logOutputMu.RUnlock()
+
+	if format != LogFormatJSON {
+		cl.logger.Printf("%s: %s", level, msg)
+		return
+	}
+
+	entry := jsonLogEntry{
+		Time:      time.Now().Format(time.RFC3339Nano),
+		Level:     strings.ToLower(level),
+		Component: cl.component,
+		Message:   msg,
+		Fields:    cl.fields,
+	}
+	line, err := json.Marshal(entry)
+	if err != nil {
+		// A field JSON cannot encode, such as a channel; keep the message
+		entry.Fields = map[string]interface{}{"fields_error": err.Error()}
+		line, _ = json.Marshal(entry)
+	}
+	globalLogWriter{}.Write(append(line, '\n'))
 }
 
 // Debug logs a debug message
 func (cl *ComponentLogger) Debug(msg string) {
 	if globalLogLevel <= LevelDebug {
-		cl.logger.Printf("DEBUG: %s", msg)
+		cl.output("DEBUG", msg)
 	}
 }
 
 // Debugf logs a formatted debug message
 func (cl *ComponentLogger) Debugf(format string, args ...interface{}) {
 	if globalLogLevel <= LevelDebug {
-		cl.logger.Printf("DEBUG: "+format, args...)
+		cl.output("DEBUG", fmt.Sprintf(format, args...))
 	}
 }
 
 // Info logs an info message
 func (cl *ComponentLogger) Info(msg string) {
 	if globalLogLevel <= LevelInfo {
-		cl.logger.Printf("INFO: %s", msg)
+		cl.output("INFO", msg)
 	}
 }
 
 // Infof logs a formatted info message
 func (cl *ComponentLogger) Infof(format string, args ...interface{}) {
 	if globalLogLevel <= LevelInfo {
-		cl.logger.Printf("INFO: "+format, args...)
+		cl.output("INFO", fmt.Sprintf(format, args...))
 	}
 }
 
 // Warn logs a warning message
 func (cl *ComponentLogger) Warn(msg string) {
 	if globalLogLevel <= LevelWarn {
-		cl.logger.Printf("WARN: %s", msg)
+		cl.output("WARN", msg)
 	}
 }
 
 // Warnf logs a formatted warning message
 func (cl *ComponentLogger) Warnf(format string, args ...interface{}) {
 	if globalLogLevel <= LevelWarn {
-		cl.logger.Printf("WARN: "+format, args...)
+		cl.output("WARN", fmt.Sprintf(format, args...))
 	}
 }
 
 // Error logs an error message
 func (cl *ComponentLogger) Error(msg string) {
 	if globalLogLevel <= LevelError {
-		cl.logger.Printf("ERROR: %s", msg)
+		cl.output("ERROR", msg)
 	}
 }
 
 // Errorf logs a formatted error message
 func (cl *ComponentLogger) Errorf(format string, args ...interface{}) {
 	if globalLogLevel <= LevelError {
-		cl.logger.Printf("ERROR: "+format, args...)
+		cl.output("ERROR", fmt.Sprintf(format, args...))
 	}
 }
 
 // Security logs a security-related message (always visible regardless of log level)
 func (cl *ComponentLogger) Security(msg string) {
-	cl.logger.Printf("SECURITY: %s", msg)
+	cl.output("SECURITY", msg)
 }
 
 // Securityf logs a formatted security-related message (always visible)
 func (cl *ComponentLogger) Securityf(format string, args ...interface{}) {
-	cl.logger.Printf("SECURITY: "+format, args...)
+	cl.output("SECURITY", fmt.Sprintf(format, args...))
 }
 
 // Panic logs a panic recovery message (always visible)
 func (cl *ComponentLogger) Panic(msg string) {
-	cl.logger.Printf("PANIC_RECOVERED: %s", msg)
+	cl.output("PANIC_RECOVERED", msg)
 }
 
 // Panicf logs a formatted panic recovery message (always visible)
 func (cl *ComponentLogger) Panicf(format string, args ...interface{}) {
-	cl.logger.Printf("PANIC_RECOVERED: "+format, args...)
+	cl.output("PANIC_RECOVERED", fmt.Sprintf(format, args...))
 }
 
 // GetLogger returns a component logger for the specified component
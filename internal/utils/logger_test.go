@@ -0,0 +1,81 @@
+// internal/utils/logger_test.go
+package utils
+
+import (
+	"bytes"
+	"encoding/json"
+	"errors"
+	"os"
+	"strings"
+	"testing"
+	"time"
+)
+
+func TestComponentLoggerJSONFormat(t *testing.T) {
+	var buf bytes.Buffer
+	SetGlobalLogOutput(&buf)
+	SetGlobalLogFormat(LogFormatJSON)
+	defer func() {
+		SetGlobalLogOutput(os.Stdout)
+		SetGlobalLogFormat(LogFormatText)
+	}()
+
+	logger := NewComponentLogger("proxy-manager")
+	logger.WithField("proxy", "p1").WithFields(map[string]interface{}{"attempt": 2, "error": errors.New("refused")}).Warnf("proxy %s failed", "p1")
+	logger.Info("plain")
+
+	lines := strings.Split(strings.TrimSpace(buf.String()), "\n")
+	if len(lines) != 2 {
+		t.Fatalf("got %d lines, want 2: %q", len(lines), buf.String())
+	}
+
+	var entry struct {
+		Time      string                 `json:"time"`
+		Level     string                 `json:"level"`
+		Component string                 `json:"component"`
+		Message   string                 `json:"message"`
+		Fields    map[string]interface{} `json:"fields"`
+	}
+	if err := json.Unmarshal([]byte(lines[0]), &entry); err != nil {
+		t.Fatalf("line is not JSON: %v: %s", err, lines[0])
+	}
+	if _, err := time.Parse(time.RFC3339Nano, entry.Time); err != nil {
+		t.Errorf("time %q is not RFC 3339: %v", entry.Time, err)
+	}
+	if entry.Level != "warn" || entry.Component != "proxy-manager" || entry.Message != "proxy p1 failed" {
+		t.Errorf("got level %q component %q message %q", entry.Level, entry.Component, entry.Message)
+	}
+	want := map[string]interface{}{"proxy": "p1", "attempt": float64(2), "error": "refused"}
+	for key, value := range want {
+		if entry.Fields[key] != value {
+			t.Errorf("field %s = %v, want %v", key, entry.Fields[key], value)
+		}
+	}
+
+	// Fields belong to the derived logger only
+	if strings.Contains(lines[1], "fields") {
+		t.Errorf("plain entry has fields: %s", lines[1])
+	}
+}
+
+func TestComponentLoggerTextFormat(t *testing.T) {
+	var buf bytes.Buffer
+	SetGlobalLogOutput(&buf)
+	defer SetGlobalLogOutput(os.Stdout)
+
+	NewComponentLogger("config").WithField("file", "a.yaml").Infof("loaded %d fields", 3)
+	if got := buf.String(); !strings.HasPrefix(got, "[config] ") || !strings.HasSuffix(got, "INFO: loaded 3 fields\n") {
+		t.Errorf("unexpected text entry: %q", got)
+	}
+}
+
+func TestParseLogFormat(t *testing.T) {
+	for name, want := range map[string]LogFormat{"": LogFormatText, "text": LogFormatText, "JSON": LogFormatJSON} {
+		if got, err := ParseLogFormat(name); err != nil || got != want {
+			t.Errorf("ParseLogFormat(%q) = %q, %v; want %q", name, got, err, want)
+		}
+	}
+	if _, err := ParseLogFormat("xml"); err == nil {
+		t.Error("expected error for unknown format")
+	}
+}
@@ -189,7 +189,7 @@ type WorkerPool[T any] struct {
 	inputChan   chan T
 	outputChan  chan interface{}
 	errorChan   chan error
-	workerFunc  func(T) (interface{}, error)
+	workerFunc  func(int, T) (interface{}, error)
 	ctx         context.Context
 	cancel      context.CancelFunc
 	wg          sync.WaitGroup
@@ -198,6 +198,14 @@ type WorkerPool[T any] struct {
 
 // NewWorkerPool creates a new worker pool
 func NewWorkerPool[T any](workerCount int, bufferSize int, workerFunc func(T) (interface{}, error)) *WorkerPool[T] {
+	return NewIndexedWorkerPool[T](workerCount, bufferSize, func(_ int, input T) (interface{}, error) {
+		return workerFunc(input)
+	})
+}
+
+// NewIndexedWorkerPool creates a worker pool whose function also receives the
+// stable ID (0..workerCount-1) of the worker goroutine processing the input
+func NewIndexedWorkerPool[T any](workerCount int, bufferSize int, workerFunc func(workerID int, input T) (interface{}, error)) *WorkerPool[T] {
 	ctx, cancel := context.WithCancel(context.Background())
 	return &WorkerPool[T]{
 		workerCount: workerCount,
@@ -215,12 +223,12 @@ func NewWorkerPool[T any](workerCount int, bufferSize int, workerFunc func(T) (i
 func (wp *WorkerPool[T]) Start() {
 	for i := 0; i < wp.workerCount; i++ {
 		wp.wg.Add(1)
-		go wp.worker()
+		go wp.worker(i)
 	}
 }
 
 // worker is the worker goroutine function
-func (wp *WorkerPool[T]) worker() {
+func (wp *WorkerPool[T]) worker(id int) {
 	defer wp.wg.Done()
 	
 	for {
@@ -233,7 +241,7 @@ func (wp *WorkerPool[T]) worker() {
 			}
 			
 			timer := NewTimer("worker_operation")
-			result, err := wp.workerFunc(input)
+			result, err := wp.workerFunc(id, input)
 			duration := timer.Stop()
 			
 			if err != nil {
@@ -511,6 +519,16 @@ func (cb *CircuitBreaker) recordSuccess() {
 	atomic.StoreInt32(&cb.state, StateClosed)
 }
 
+// Reset closes the circuit breaker and clears its failure count and timestamp
+func (cb *CircuitBreaker) Reset() {
+	cb.mutex.Lock()
+	defer cb.mutex.Unlock()
+
+	atomic.StoreInt64(&cb.failureCount, 0)
+	atomic.StoreInt64(&cb.lastFailureTime, 0)
+	atomic.StoreInt32(&cb.state, StateClosed)
+}
+
 // GetState returns the current circuit breaker state
 func (cb *CircuitBreaker) GetState() int32 {
 	return atomic.LoadInt32(&cb.state)
@@ -239,6 +239,13 @@ func IsValidURL(s string) bool {
 	return true
 }
 
+// DefaultStripParams are the tracking parameters NormalizeURL removes. A trailing
+// "*" matches any parameter with that prefix.
+var DefaultStripParams = []string{
+	"utm_source", "utm_medium", "utm_campaign", "utm_term", "utm_content",
+	"fbclid", "gclid", "ref", "source",
+}
+
 // NormalizeURL normalizes a URL for consistent comparison and deduplication.
 // It performs the following normalizations:
 // - Converts scheme and host to lowercase
@@ -254,6 +261,12 @@ func IsValidURL(s string) bool {
 //	normalized1 := utils.NormalizeURL(url1) // Same as normalized2
 //	normalized2 := utils.NormalizeURL(url2)
 func NormalizeURL(rawURL string) string {
+	return CanonicalizeURL(rawURL, DefaultStripParams)
+}
+
+// CanonicalizeURL is NormalizeURL with the query parameters to remove given by
+// stripParams instead of DefaultStripParams. Parameter names match case-insensitively.
+func CanonicalizeURL(rawURL string, stripParams []string) string {
 	u, err := url.Parse(rawURL)
 	if err != nil {
 		return rawURL
@@ -271,41 +284,33 @@ func NormalizeURL(rawURL string) string {
 		}
 	}
 
-	// Remove trailing slash from path
+	// Remove trailing slash from path; a bare host gets the root path
 	if u.Path != "/" {
 		u.Path = strings.TrimSuffix(u.Path, "/")
+		u.RawPath = strings.TrimSuffix(u.RawPath, "/")
+	}
+	if u.Path == "" && u.Host != "" && u.Opaque == "" {
+		u.Path = "/"
 	}
 
 	// Clean and sort query parameters
 	if u.RawQuery != "" {
-		u.RawQuery = cleanQueryParams(u.Query())
+		u.RawQuery = cleanQueryParams(u.Query(), stripParams)
 	}
+	u.ForceQuery = false
 
 	// Remove fragment
 	u.Fragment = ""
+	u.RawFragment = ""
 
 	return u.String()
 }
 
-// cleanQueryParams removes tracking parameters and sorts the remaining ones.
-func cleanQueryParams(params url.Values) string {
-	// Common tracking parameters to remove
-	trackingParams := map[string]bool{
-		"utm_source":   true,
-		"utm_medium":   true,
-		"utm_campaign": true,
-		"utm_term":     true,
-		"utm_content":  true,
-		"fbclid":       true,
-		"gclid":        true,
-		"ref":          true,
-		"source":       true,
-	}
-
-	// Filter out tracking parameters
+// cleanQueryParams removes the strip parameters and sorts the remaining ones.
+func cleanQueryParams(params url.Values, stripParams []string) string {
 	cleaned := url.Values{}
 	for key, values := range params {
-		if !trackingParams[strings.ToLower(key)] {
+		if !matchesParam(key, stripParams) {
 			cleaned[key] = values
 		}
 	}
@@ -313,6 +318,22 @@ func cleanQueryParams(params url.Values) string {
 	return cleaned.Encode()
 }
 
+// matchesParam reports whether key is one of patterns, where a trailing "*" matches a prefix
+func matchesParam(key string, patterns []string) bool {
+	key = strings.ToLower(key)
+	for _, pattern := range patterns {
+		pattern = strings.ToLower(pattern)
+		if prefix, ok := strings.CutSuffix(pattern, "*"); ok {
+			if strings.HasPrefix(key, prefix) {
+				return true
+			}
+		} else if key == pattern {
+			return true
+		}
+	}
+	return false
+}
+
 // ExtractDomain extracts the domain (host without port) from a URL.
 // Returns empty string if the URL is invalid.
 //
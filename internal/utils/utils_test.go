@@ -0,0 +1,34 @@
+// internal/utils/utils_test.go
+package utils
+
+import "testing"
+
+func TestCanonicalizeURL(t *testing.T) {
+	tests := []struct {
+		name        string
+		url         string
+		stripParams []string
+		want        string
+	}{
+		{name: "tracking params are stripped", url: "https://example.com/list?utm_source=feed&page=2&utm_medium=email", stripParams: []string{"utm_*"}, want: "https://example.com/list?page=2"},
+		{name: "param names match case-insensitively", url: "https://example.com/list?UTM_Campaign=x&id=1", stripParams: []string{"utm_*"}, want: "https://example.com/list?id=1"},
+		{name: "exact params are stripped", url: "https://example.com/p?ref=home&refresh=1", stripParams: []string{"ref"}, want: "https://example.com/p?refresh=1"},
+		{name: "remaining params are sorted", url: "https://example.com/p?b=2&a=1", want: "https://example.com/p?a=1&b=2"},
+		{name: "fragment is removed", url: "https://example.com/list#top", want: "https://example.com/list"},
+		{name: "trailing slash is removed", url: "https://example.com/list/", want: "https://example.com/list"},
+		{name: "root path is kept", url: "https://example.com", want: "https://example.com/"},
+		{name: "scheme and host are lowercased", url: "HTTPS://Example.COM/List", want: "https://example.com/List"},
+		{name: "default port is removed", url: "http://example.com:80/list", want: "http://example.com/list"},
+		{name: "other ports are kept", url: "http://example.com:8080/list", want: "http://example.com:8080/list"},
+		{name: "empty query is dropped", url: "https://example.com/list?utm_source=feed", stripParams: []string{"utm_*"}, want: "https://example.com/list"},
+		{name: "all at once", url: "https://Shop.Example.com/list/?utm_campaign=loop#top", stripParams: []string{"utm_*"}, want: "https://shop.example.com/list"},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			if got := CanonicalizeURL(tt.url, tt.stripParams); got != tt.want {
+				t.Errorf("CanonicalizeURL(%q) = %q, want %q", tt.url, got, tt.want)
+			}
+		})
+	}
+}
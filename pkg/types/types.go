@@ -185,11 +185,13 @@ const (
 	TransformLowercase       TransformType = "lowercase"
 	TransformUppercase       TransformType = "uppercase"
 	TransformNormalizeSpaces TransformType = "normalize_spaces"
+	TransformNormalizeText   TransformType = "normalize_text"
 	TransformRemoveHTML      TransformType = "remove_html"
 	TransformRegex           TransformType = "regex"
 	TransformParseFloat      TransformType = "parse_float"
 	TransformParseInt        TransformType = "parse_int"
 	TransformParseDate       TransformType = "parse_date"
+	TransformDate            TransformType = "date"
 	TransformExtractNumber   TransformType = "extract_number"
 	TransformPrefix          TransformType = "prefix"
 	TransformSuffix          TransformType = "suffix"
@@ -202,8 +204,8 @@ const (
 func ValidTransformTypes() []TransformType {
 	return []TransformType{
 		TransformTrim, TransformLowercase, TransformUppercase,
-		TransformNormalizeSpaces, TransformRemoveHTML, TransformRegex,
-		TransformParseFloat, TransformParseInt, TransformParseDate,
+		TransformNormalizeSpaces, TransformNormalizeText, TransformRemoveHTML, TransformRegex,
+		TransformParseFloat, TransformParseInt, TransformParseDate, TransformDate,
 		TransformExtractNumber, TransformPrefix, TransformSuffix,
 		TransformReplace, TransformSplit, TransformJoin,
 	}
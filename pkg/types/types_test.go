@@ -72,6 +72,28 @@ func TestJobPriority(t *testing.T) {
 	}
 }
 
+func TestTransformType(t *testing.T) {
+	tests := []struct {
+		name      string
+		transform TransformType
+		isValid   bool
+	}{
+		{"trim", TransformTrim, true},
+		{"normalize text", TransformNormalizeText, true},
+		{"date", TransformDate, true},
+		{"parse date", TransformParseDate, true},
+		{"unknown", TransformType("reticulate"), false},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			if got := tt.transform.IsValid(); got != tt.isValid {
+				t.Errorf("TransformType.IsValid() = %v, want %v", got, tt.isValid)
+			}
+		})
+	}
+}
+
 func TestDuration(t *testing.T) {
 	tests := []struct {
 		name     string